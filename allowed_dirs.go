@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- Allowed Directories ---
+//
+// The AllowedDirectories setting is a hard boundary on what the app may operate on, and so on
+// what can reach an LLM: when it is not empty, selecting, opening, listing, reading and
+// generating context refuse every project outside the listed directories. Paths are compared
+// after resolving symlinks, so a link inside an allowed directory cannot point the app
+// elsewhere. Mounted projects (archives, SSH, Docker) are checked by their virtual root. An
+// empty list allows everything.
+//
+// Every bound method and job that reads project content (listing, generation, previews, stats,
+// summaries, reviews, the tool agent) checks its root with validateContentRoot. Paths inside a
+// project are resolved with resolveProjectPath, which also refuses symlinks leading out of it.
+
+// canonicalPath returns path as an absolute, clean path with symlinks resolved
+// A path that does not exist (yet) is resolved up to its deepest existing ancestor.
+func canonicalPath(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		abs = filepath.Clean(path)
+	}
+	if isMountedPath(abs) {
+		return abs
+	}
+	return resolveExistingPath(abs)
+}
+
+// resolveExistingPath resolves the symlinks of the deepest existing ancestor of an absolute path
+func resolveExistingPath(path string) string {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	parent := filepath.Dir(path)
+	if parent == path {
+		return path
+	}
+	return filepath.Join(resolveExistingPath(parent), filepath.Base(path))
+}
+
+// pathWithin reports whether path is dir or below it
+func pathWithin(path, dir string) bool {
+	if path == dir {
+		return true
+	}
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(os.PathSeparator)) && !filepath.IsAbs(rel)
+}
+
+// checkAllowedPath returns an error if the allowlist does not cover path
+func (a *App) checkAllowedPath(path string) error {
+	if len(a.settings.AllowedDirectories) == 0 {
+		return nil
+	}
+	if strings.TrimSpace(path) == "" {
+		return fmt.Errorf("no path specified")
+	}
+	canonical := canonicalPath(path)
+	for _, dir := range a.settings.AllowedDirectories {
+		if pathWithin(canonical, canonicalPath(dir)) {
+			return nil
+		}
+	}
+	runtime.LogWarningf(a.ctx, "Refused %s: outside the allowed directories", path)
+	return fmt.Errorf("%s is outside the allowed directories", path)
+}
+
+// validateContentRoot returns an error if the content of a project may not be read: the root
+// must be an existing folder covered by the allowlist
+// Every bound method and job that reads project content checks its root with it.
+func (a *App) validateContentRoot(rootDir string) error {
+	if strings.TrimSpace(rootDir) == "" {
+		return fmt.Errorf("no project folder specified")
+	}
+	if err := a.checkAllowedPath(rootDir); err != nil {
+		return err
+	}
+	info, err := a.projectFS.Stat(rootDir)
+	if err != nil {
+		return fmt.Errorf("project folder does not exist: %s", rootDir)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("project path is not a folder: %s", rootDir)
+	}
+	return nil
+}
+
+// ============================================================================
+// Allowed Directories Methods (Wails-bound)
+// ============================================================================
+
+// GetAllowedDirectories returns the directories the app may operate on (empty allows all)
+func (a *App) GetAllowedDirectories() []string {
+	if a.settings.AllowedDirectories == nil {
+		return []string{}
+	}
+	return a.settings.AllowedDirectories
+}
+
+// SetAllowedDirectories replaces the allowlist and saves the setting
+//
+// Parameters:
+//   - dirs: Absolute directory paths (empty to allow every directory)
+//
+// Returns:
+//   - error: Error if a path is not absolute or the settings cannot be saved
+func (a *App) SetAllowedDirectories(dirs []string) error {
+	cleaned := []string{}
+	seen := make(map[string]bool)
+	for _, dir := range dirs {
+		dir = strings.TrimSpace(dir)
+		if dir == "" {
+			continue
+		}
+		if !filepath.IsAbs(dir) {
+			return fmt.Errorf("allowed directory must be an absolute path: %s", dir)
+		}
+		dir = filepath.Clean(dir)
+		if !seen[dir] {
+			seen[dir] = true
+			cleaned = append(cleaned, dir)
+		}
+	}
+	a.settings.AllowedDirectories = cleaned
+	if err := a.saveSettings(); err != nil {
+		return fmt.Errorf("failed to save allowed directories: %w", err)
+	}
+	runtime.LogInfof(a.ctx, "Allowed directories set to %v", cleaned)
+	return nil
+}
+
+// IsPathAllowed reports whether the allowlist covers a path
+func (a *App) IsPathAllowed(path string) bool {
+	return a.checkAllowedPath(path) == nil
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- Local Usage Analytics ---
+//
+// Opt-in, entirely offline usage store. Events are appended to <config dir>/usage.jsonl
+// and summarized on demand by GetAnalytics; nothing ever leaves the machine.
+
+// UsageEvent is one recorded action
+type UsageEvent struct {
+	Type      string    `json:"type"`      // generation, prompt, or llm_call
+	Timestamp time.Time `json:"timestamp"` // When the action finished
+	Project   string    `json:"project"`   // Project root directory (empty if unknown)
+
+	ContextBytes  int `json:"contextBytes,omitempty"`  // Generated context size (generation)
+	ContextTokens int `json:"contextTokens,omitempty"` // Estimated context tokens (generation)
+
+	Mode string `json:"mode,omitempty"` // Prompt mode (prompt)
+
+	Provider   string  `json:"provider,omitempty"`   // LLM provider (llm_call)
+	Model      string  `json:"model,omitempty"`      // LLM model (llm_call)
+	TokensUsed int     `json:"tokensUsed,omitempty"` // Tokens used (llm_call)
+	Cost       float64 `json:"cost,omitempty"`       // Estimated cost in USD (llm_call)
+
+	OutputTokens    int     `json:"outputTokens,omitempty"`    // Generated tokens (llm_call)
+	FirstByteMs     int64   `json:"firstByteMs,omitempty"`     // Time to first byte in milliseconds (llm_call)
+	LatencyMs       int64   `json:"latencyMs,omitempty"`       // Total latency in milliseconds (llm_call)
+	TokensPerSecond float64 `json:"tokensPerSecond,omitempty"` // Output tokens per second (llm_call)
+}
+
+// AnalyticsSummary aggregates the usage store
+type AnalyticsSummary struct {
+	Enabled               bool               `json:"enabled"`               // Whether analytics are being recorded
+	TotalGenerations      int                `json:"totalGenerations"`      // Number of context generations
+	GenerationsPerProject map[string]int     `json:"generationsPerProject"` // Generations by project root
+	AverageContextBytes   int                `json:"averageContextBytes"`   // Average generated context size
+	AverageContextTokens  int                `json:"averageContextTokens"`  // Average estimated context tokens
+	ModeUsage             map[string]int     `json:"modeUsage"`             // Prompts composed per mode
+	ModelUsage            map[string]int     `json:"modelUsage"`            // LLM calls per provider/model
+	TotalLLMCalls         int                `json:"totalLLMCalls"`         // Number of LLM calls
+	TotalTokensUsed       int                `json:"totalTokensUsed"`       // Tokens used by LLM calls
+	TotalCost             float64            `json:"totalCost"`             // Estimated LLM spend in USD
+	CostPerProject        map[string]float64 `json:"costPerProject"`        // Estimated LLM spend by project root (see GetProjectCosts)
+	FirstEventAt          *time.Time         `json:"firstEventAt"`          // Oldest recorded event
+	LastEventAt           *time.Time         `json:"lastEventAt"`           // Newest recorded event
+}
+
+// usageStorePath returns the path of the usage store
+func (a *App) usageStorePath() string {
+	return filepath.Join(filepath.Dir(a.configPath), "usage.jsonl")
+}
+
+// recordUsage appends an event to the usage store if analytics are enabled
+func (a *App) recordUsage(event UsageEvent) {
+	if !a.settings.AnalyticsEnabled || a.configPath == "" {
+		return
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+	line, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+
+	a.analyticsMu.Lock()
+	defer a.analyticsMu.Unlock()
+	if err := appendToFile(a.usageStorePath(), string(line)+"\n"); err != nil {
+		runtime.LogWarningf(a.ctx, "Failed to record usage event: %v", err)
+	}
+}
+
+// llmCallEvent returns the usage event of a finished LLM call
+func llmCallEvent(project string, resp *LLMResponse) UsageEvent {
+	return UsageEvent{
+		Type:            "llm_call",
+		Project:         project,
+		Provider:        resp.Provider,
+		Model:           resp.Model,
+		TokensUsed:      resp.TokensUsed,
+		Cost:            resp.Cost,
+		OutputTokens:    resp.OutputTokens,
+		FirstByteMs:     resp.FirstByteMs,
+		LatencyMs:       resp.LatencyMs,
+		TokensPerSecond: resp.TokensPerSecond,
+	}
+}
+
+// readUsageEvents loads all events of the usage store, skipping malformed lines
+func (a *App) readUsageEvents() ([]UsageEvent, error) {
+	a.analyticsMu.Lock()
+	defer a.analyticsMu.Unlock()
+
+	f, err := os.Open(a.usageStorePath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open usage store: %w", err)
+	}
+	defer f.Close()
+
+	var events []UsageEvent
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var event UsageEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err == nil {
+			events = append(events, event)
+		}
+	}
+	return events, scanner.Err()
+}
+
+// ============================================================================
+// Analytics Methods (Wails-bound)
+// ============================================================================
+
+// GetAnalytics summarizes the local usage store
+//
+// Returns:
+//   - AnalyticsSummary: Generations per project, average context size, most-used modes and models, spend
+//   - error: Error if the usage store cannot be read
+func (a *App) GetAnalytics() (AnalyticsSummary, error) {
+	summary := AnalyticsSummary{
+		Enabled:               a.settings.AnalyticsEnabled,
+		GenerationsPerProject: make(map[string]int),
+		ModeUsage:             make(map[string]int),
+		ModelUsage:            make(map[string]int),
+		CostPerProject:        make(map[string]float64),
+	}
+	if a.configPath == "" {
+		return summary, nil
+	}
+
+	events, err := a.readUsageEvents()
+	if err != nil {
+		return summary, err
+	}
+
+	var totalBytes, totalTokens int
+	for i := range events {
+		event := events[i]
+		if summary.FirstEventAt == nil || event.Timestamp.Before(*summary.FirstEventAt) {
+			summary.FirstEventAt = &events[i].Timestamp
+		}
+		if summary.LastEventAt == nil || event.Timestamp.After(*summary.LastEventAt) {
+			summary.LastEventAt = &events[i].Timestamp
+		}
+
+		switch event.Type {
+		case "generation":
+			summary.TotalGenerations++
+			summary.GenerationsPerProject[event.Project]++
+			totalBytes += event.ContextBytes
+			totalTokens += event.ContextTokens
+		case "prompt":
+			summary.ModeUsage[event.Mode]++
+		case "llm_call":
+			summary.TotalLLMCalls++
+			summary.ModelUsage[event.Provider+"/"+event.Model]++
+			summary.TotalTokensUsed += event.TokensUsed
+			summary.TotalCost += event.Cost
+			if event.Project != "" {
+				summary.CostPerProject[projectSettingsKey(event.Project)] += event.Cost
+			}
+		}
+	}
+	if summary.TotalGenerations > 0 {
+		summary.AverageContextBytes = totalBytes / summary.TotalGenerations
+		summary.AverageContextTokens = totalTokens / summary.TotalGenerations
+	}
+	return summary, nil
+}
+
+// GetAnalyticsEnabled returns whether local usage analytics are recorded
+func (a *App) GetAnalyticsEnabled() bool {
+	return a.settings.AnalyticsEnabled
+}
+
+// SetAnalyticsEnabled opts in to (or out of) local usage analytics and saves the setting.
+// Existing data is kept when disabling; use ClearAnalytics to delete it.
+func (a *App) SetAnalyticsEnabled(enabled bool) error {
+	a.settings.AnalyticsEnabled = enabled
+	if err := a.saveSettings(); err != nil {
+		return fmt.Errorf("failed to save analytics setting: %w", err)
+	}
+	runtime.LogInfof(a.ctx, "Local analytics enabled: %v", enabled)
+	return nil
+}
+
+// ClearAnalytics deletes all recorded usage events
+func (a *App) ClearAnalytics() error {
+	if a.configPath == "" {
+		return nil
+	}
+	a.analyticsMu.Lock()
+	defer a.analyticsMu.Unlock()
+	if err := os.Remove(a.usageStorePath()); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to clear analytics: %w", err)
+	}
+	runtime.LogInfo(a.ctx, "Local analytics cleared")
+	return nil
+}
@@ -30,6 +30,7 @@ import (
 	"fmt"
 	"io"
 	"io/fs"
+	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -59,29 +60,181 @@ const defaultCustomPromptRulesContent = "no additional rules"
 // AppSettings represents the persistent application settings
 // These are stored in the user's config directory (XDG_CONFIG_HOME/shotgun-code/settings.json)
 type AppSettings struct {
-	CustomIgnoreRules string `json:"customIgnoreRules"` // User-defined file ignore patterns (glob format)
-	CustomPromptRules string `json:"customPromptRules"` // User-defined prompt customization rules
+	CustomIgnoreRules string            `json:"customIgnoreRules"`         // User-defined file ignore patterns (glob format)
+	CustomPromptRules string            `json:"customPromptRules"`         // User-defined prompt customization rules
+	ToolPermissions   map[string]bool   `json:"toolPermissions,omitempty"` // Per-tool permission overrides for LLM tool calling
+	TestCommands      map[string]string `json:"testCommands,omitempty"`    // Per-project command of the run_tests tool, keyed by project root (empty uses the detected one)
+
+	MaxAutoContinuations int                   `json:"maxAutoContinuations"` // Automatic continuation requests for truncated LLM responses
+	PostProcessing       PostProcessingOptions `json:"postProcessing"`       // Cleanup applied to responses before diff extraction and apply
+	ResponseFormat       string                `json:"responseFormat"`       // Code change format requested in prompts: diff or whole_file
+
+	ModeDefaults    map[string]ModeModelDefaults `json:"modeDefaults,omitempty"`    // Default provider, model, temperature and max tokens per prompt mode
+	PromptTemplates map[string]PromptTemplate    `json:"promptTemplates,omitempty"` // Shareable prompt modes with their own instructions, by name
+
+	ForceIncludePaths map[string][]string         `json:"forceIncludePaths,omitempty"` // Per-project paths included despite ignore rules, keyed by project root
+	SummaryOnlyDirs   map[string][]SummaryOnlyDir `json:"summaryOnlyDirs,omitempty"`   // Per-project directories generated as a summary instead of their files, keyed by project root
+	ShowDotfiles      bool                        `json:"showDotfiles"`                // Show and include all dotfiles and dot-directories
+	VisibleDotfiles   []string                    `json:"visibleDotfiles"`             // Dotfile name patterns shown even when ShowDotfiles is off
+	MaxTreeDepth      int                         `json:"maxTreeDepth"`                // Maximum directory depth listed in the file tree (0 = unlimited)
+	MaxEntriesPerDir  int                         `json:"maxEntriesPerDir"`            // Maximum entries listed per directory (0 = unlimited)
+
+	ConfirmFileThreshold  int `json:"confirmFileThreshold"`  // Ask before generating context for more files than this (0 = never)
+	ConfirmTokenThreshold int `json:"confirmTokenThreshold"` // Ask before generating context estimated above this many tokens (0 = never)
+
+	AnalyticsEnabled bool `json:"analyticsEnabled"` // Record local, offline usage analytics (opt-in)
+
+	WatcherExcludeDirs    []string `json:"watcherExcludeDirs"`    // Directory names the file watcher never descends into, regardless of ignore toggles
+	IncludeTechStack      bool     `json:"includeTechStack"`      // Start generated context with a one-line language summary
+	IncludeEnvironment    bool     `json:"includeEnvironment"`    // End generated context with the OS, runtime and dependency versions
+	ChurnInFileHeaders    bool     `json:"churnInFileHeaders"`    // Add last-commit date and commit count to file headers in generated context
+	LanguageInFileHeaders bool     `json:"languageInFileHeaders"` // Add the detected language to file headers in generated context
+
+	SpillThresholdMB int `json:"spillThresholdMB"` // Generation output and LLM responses above this size are kept on disk (0 = never)
+	HeapLimitMB      int `json:"heapLimitMB"`      // Above this heap size they move to disk early (0 = no watchdog)
+	ContentCacheMB   int `json:"contentCacheMB"`   // Memory for rendered file blocks reused by regenerations (0 = no cache)
+
+	Notifications NotificationOptions `json:"notifications"` // Desktop notifications when long work finishes
+
+	RateLimits map[string]ProviderRateLimit `json:"rateLimits,omitempty"` // Client-side request and token limits per minute, keyed by provider
+
+	ReadOnly           bool     `json:"readOnly"`                     // Refuse every change to project files (patch apply, undo, mutating tools)
+	OfflineMode        bool     `json:"offlineMode"`                  // Refuse all outbound network traffic except to localhost (see offline_mode.go)
+	AllowedDirectories []string `json:"allowedDirectories,omitempty"` // Directories the app may operate on (empty allows all)
+
+	ContentFilters []ContentFilter `json:"contentFilters,omitempty"` // Block, redact or warn on matching content during generation
+	RedactSecrets  bool            `json:"redactSecrets"`            // Mask likely secrets (keys, tokens, .env values) before content reaches the context or an LLM
+	DedupeContent  bool            `json:"dedupeContent"`            // Include identical files once, later copies reference the first
+	OutputFormat   string          `json:"outputFormat"`             // Format of generated context: xml or markdown
+	FileOrder      string          `json:"fileOrder"`                // Order of file blocks in generated context: path or dependencies (see file_order.go)
+
+	FileBlockEscaping     string                 `json:"fileBlockEscaping"`               // When file contents are wrapped in CDATA: auto, always or never
+	GenerationTokenBudget int                    `json:"generationTokenBudget"`           // Fit generated context to this many estimated tokens (0 = unlimited)
+	ContextChunkTokens    int                    `json:"contextChunkTokens"`              // Also emit generated context in chunks of this many estimated tokens (0 = off)
+	ContextSectionOrder   []string               `json:"contextSectionOrder,omitempty"`   // Order of the sections of generated context (empty for the default, see context_sections.go)
+	CustomContextSections []CustomContextSection `json:"customContextSections,omitempty"` // Static text sections injected into generated context
+	Attachments           map[string][]string    `json:"attachments,omitempty"`           // Per-project external files rendered in generated context, keyed by project root
+	LineNumbers           bool                   `json:"lineNumbers"`                     // Prefix each line of included file content with its line number
+	MaxFileBlockBytes     int                    `json:"maxFileBlockBytes"`               // Leave out files whose rendered block exceeds this many bytes (0 = no limit)
+	FileSummaries         FileSummarySettings    `json:"fileSummaries"`                   // Replace files over a size by an LLM summary (see file_summaries.go)
+	MaxFileBytes          int                    `json:"maxFileBytes"`                    // Truncate file content larger than this many bytes (0 = no cap)
+	FileCapKeepTail       bool                   `json:"fileCapKeepTail"`                 // Keep the head and tail of capped files instead of the head only
+
+	CriticalPathPatterns []string `json:"criticalPathPatterns"` // Gitignore-style patterns of paths that raise the risk of a patch
+
+	BackgroundWork BackgroundWorkSettings `json:"backgroundWork"` // Worker counts, CPU share and priority of background indexing
+	JobQueue       JobQueueLimits         `json:"jobQueue"`       // Backpressure thresholds of the job queue (see job_backpressure.go)
 }
 
 // App is the main application struct that coordinates all components
 // It serves as the central hub for the Wails application
 type App struct {
 	ctx                         context.Context      // Application context for lifecycle management
+	projectFS                   ProjectFS            // Reads project files (see project_fs.go)
 	contextGenerator            *ContextGenerator    // Handles context generation operations
 	fileWatcher                 *Watchman            // File system watcher for real-time updates
 	jobQueue                    *JobQueue            // Background job queue for async operations
 	settings                    AppSettings          // User settings loaded from config file
+	settingsMu                  sync.RWMutex         // Guards the settings maps read by background jobs (ToolPermissions, TestCommands, RateLimits)
 	currentCustomIgnorePatterns *gitignore.GitIgnore // Compiled custom ignore patterns
 	configPath                  string               // Path to the settings.json config file
 	useGitignore                bool                 // Whether to respect .gitignore files
 	useCustomIgnore             bool                 // Whether to apply custom ignore patterns
-	projectGitignore            *gitignore.GitIgnore // Compiled .gitignore for the current project
+	projectGitignore            *gitignore.GitIgnore // Compiled .gitignore rules (root and nested) for the current project
+	projectGitignoreRoot        string               // Project root projectGitignore was compiled for
+	toolRegistry                *ToolRegistry        // Tools exposed to LLMs via function calling
+	detached                    bool                 // Copy regenerating a snapshot: emits no generation events (see context_snapshot.go)
+
+	truncatedMu    sync.Mutex                   // Protects truncatedCalls
+	truncatedCalls map[string]*truncatedLLMCall // Truncated LLM responses that can be continued, keyed by job ID
+
+	sessionMu        sync.Mutex    // Protects session and recoveredSession
+	session          SessionState  // In-flight state persisted for crash recovery
+	recoveredSession *SessionState // In-flight state of a previous session that crashed (nil if none)
+
+	analyticsMu sync.Mutex // Serializes access to the usage store
+
+	workflowMu sync.Mutex // Serializes access to the workflow store
+
+	indexMu sync.Mutex    // Protects index
+	index   *projectIndex // Background index of the open project (nil before one is opened)
+
+	openMu        sync.Mutex   // Protects frontendReady and pendingOpen
+	frontendReady bool         // True once the frontend can receive projectOpened events
+	pendingOpen   *openRequest // Project requested at launch, opened when the frontend is ready
+	launchArgs    []string     // Command-line arguments of this instance
+
+	operationsMu sync.Mutex   // Protects operations
+	operations   []*Operation // Undo stack of backend changes to project files, oldest first
+
+	contentCacheMu    sync.Mutex             // Protects contentCache and contentCacheBytes
+	contentCache      map[string]cachedBlock // Rendered file blocks, by absolute path
+	contentCacheBytes int64                  // Total size of the cached blocks
+
+	lastGenerationMu sync.Mutex        // Protects lastGeneration
+	lastGeneration   *generatedContext // Most recent successful context generation (nil before one)
+
+	changeHistoryMu sync.Mutex        // Protects changeHistory
+	changeHistory   []FileChangeEvent // File changes seen by the watcher, oldest first
+
+	egressMu sync.Mutex // Serializes access to the egress audit log
+
+	rateLimitersMu sync.Mutex                  // Protects rateLimiters
+	rateLimiters   map[string]*providerLimiter // Sliding request windows, keyed by provider
+
+	profilingMu     sync.Mutex   // Protects profilingServer and profilingAddr
+	profilingServer *http.Server // pprof endpoint in debug mode (nil when off)
+	profilingAddr   string       // Address the pprof endpoint listens on
+}
+
+// truncatedLLMCall keeps what is needed to continue a truncated LLM response
+type truncatedLLMCall struct {
+	request  LLMRequest   // Original request (including API key, never sent to the frontend)
+	response *LLMResponse // Response so far, with stitched content
+}
+
+// generatedContext records a successful context generation
+type generatedContext struct {
+	rootDir       string    // Project root directory
+	excludedPaths []string  // Paths excluded from the generation
+	content       string    // Generated context
+	generatedAt   time.Time // When the generation finished
+}
+
+// recordGeneration remembers the most recent successful context generation
+func (a *App) recordGeneration(rootDir string, excludedPaths []string, content string) {
+	a.lastGenerationMu.Lock()
+	defer a.lastGenerationMu.Unlock()
+	a.lastGeneration = &generatedContext{
+		rootDir:       rootDir,
+		excludedPaths: append([]string(nil), excludedPaths...),
+		content:       content,
+		generatedAt:   time.Now(),
+	}
+}
+
+// latestGeneration returns the most recent successful context generation (nil before one)
+func (a *App) latestGeneration() *generatedContext {
+	a.lastGenerationMu.Lock()
+	defer a.lastGenerationMu.Unlock()
+	return a.lastGeneration
 }
 
 // NewApp creates a new App instance
 // This is called by Wails during application initialization
 func NewApp() *App {
-	return &App{}
+	return NewAppWithFS(hostProjectFS{})
+}
+
+// NewAppWithFS creates a new App instance reading project files through projectFS
+//
+// Parameters:
+//   - projectFS: Filesystem of the projects (see project_fs.go)
+//
+// Returns:
+//   - *App: App that has not been started yet
+func NewAppWithFS(projectFS ProjectFS) *App {
+	return &App{projectFS: projectFS}
 }
 
 // startup is called by Wails when the application starts
@@ -97,6 +250,8 @@ func (a *App) startup(ctx context.Context) {
 	a.contextGenerator = NewContextGenerator(a) // Handles context generation
 	a.fileWatcher = NewWatchman(a)              // Watches for file system changes
 	a.jobQueue = NewJobQueue(a)                 // Manages background jobs
+	a.toolRegistry = NewToolRegistry(a)         // Tools exposed to LLMs via function calling
+	a.registerBuiltinJobHandlers()              // Job types the queue can run
 
 	// Set default ignore behavior (can be toggled by user in UI)
 	a.useGitignore = true    // Respect .gitignore files by default
@@ -116,6 +271,23 @@ func (a *App) startup(ctx context.Context) {
 
 	// Load user settings from disk (or use defaults if file doesn't exist)
 	a.loadSettings()
+	offlineMode.Store(a.settings.OfflineMode)
+
+	// Detect an unclean shutdown of the previous session and start tracking this one
+	a.initSessionState()
+
+	// Hidden debug mode: serve pprof when SHOTGUN_PPROF is set
+	a.startProfilingFromEnv()
+
+	// Folders dropped onto the window open as the project
+	runtime.OnFileDrop(a.ctx, a.handleFileDrop)
+
+	// A project path or shotgun:// link on the command line is opened once the frontend is ready
+	if workingDir, err := os.Getwd(); err == nil {
+		a.handleLaunchArgs(a.launchArgs, workingDir)
+	} else {
+		a.handleLaunchArgs(a.launchArgs, "")
+	}
 
 	// Ensure CustomPromptRules has a default value if it's empty after loading
 	// This prevents the UI from showing an empty state
@@ -154,6 +326,16 @@ type FileNode struct {
 	IsCustomIgnored bool        `json:"isCustomIgnored"`    // True if this path matches a custom ignore pattern
 	Size            int64       `json:"size"`               // File size in bytes (0 for directories)
 	IsBinary        bool        `json:"isBinary"`           // True if this is a binary file (detected by content analysis)
+	IsForceIncluded bool        `json:"isForceIncluded"`    // True if the user included this path despite ignore rules
+	IsTruncated     bool        `json:"isTruncated"`        // True if this directory's children were not listed (max depth reached)
+	IsSummary       bool        `json:"isSummary"`          // True for the "N more entries" placeholder of a capped directory
+	MoreCount       int         `json:"moreCount"`          // Number of entries not listed (summary nodes only)
+	LastCommit      int64       `json:"lastCommit"`         // Unix time of the last commit touching this file (0 if unknown)
+	Commits         int         `json:"commits"`            // Commits touching this file in the last year (0 if unknown)
+	IsSummaryOnly   bool        `json:"isSummaryOnly"`      // True if generation emits a summary of this directory instead of its files
+	Tokens          int         `json:"tokens"`             // Estimated tokens (directories: sum of their files; 0 until indexed)
+	TokenWeight     float64     `json:"tokenWeight"`        // Share of the tree's total tokens, 0-1 (for heatmap coloring)
+	Language        string      `json:"language"`           // Detected language of a text file (see language_detect.go; empty for directories and binaries)
 }
 
 // FileContentResult represents the result of reading a file's content
@@ -236,7 +418,12 @@ var binaryFilenames = map[string]bool{
 // Returns:
 //   - bool: true if file is binary, false if text
 //   - error: Error if file cannot be read
-func isBinaryFile(filePath string) (bool, error) {
+func (a *App) isBinaryFile(filePath string) (bool, error) {
+	return isBinaryFileIn(a.projectFS, filePath)
+}
+
+// isBinaryFileIn is isBinaryFile for a file of fsys
+func isBinaryFileIn(fsys ProjectFS, filePath string) (bool, error) {
 	// Validate input
 	if filePath == "" {
 		return false, fmt.Errorf("file path is empty")
@@ -255,7 +442,7 @@ func isBinaryFile(filePath string) (bool, error) {
 	}
 
 	// Open file for content analysis
-	file, err := os.Open(filePath)
+	file, err := fsys.Open(filePath)
 	if err != nil {
 		return false, fmt.Errorf("failed to open file: %w", err)
 	}
@@ -323,9 +510,16 @@ func isBinaryFile(filePath string) (bool, error) {
 //   - string: The selected directory path, or empty string if cancelled
 //   - error: Error if dialog fails to open
 func (a *App) SelectDirectory() (string, error) {
-	return runtime.OpenDirectoryDialog(a.ctx, runtime.OpenDialogOptions{
+	dir, err := runtime.OpenDirectoryDialog(a.ctx, runtime.OpenDialogOptions{
 		Title: "Select Project Folder",
 	})
+	if err != nil || dir == "" {
+		return dir, err
+	}
+	if err := a.checkAllowedPath(dir); err != nil {
+		return "", err
+	}
+	return dir, nil
 }
 
 // ReadFileContents reads the contents of multiple files with validation
@@ -341,22 +535,11 @@ func (a *App) SelectDirectory() (string, error) {
 //   - error: Error if rootDir is invalid or operation fails
 func (a *App) ReadFileContents(rootDir string, relativePaths []string) ([]FileContentResult, error) {
 	// Validate inputs
-	if rootDir == "" {
-		return nil, fmt.Errorf("root directory is empty")
-	}
-
 	if relativePaths == nil {
 		return nil, fmt.Errorf("relative paths array is nil")
 	}
-
-	// Check if root directory exists
-	rootInfo, err := os.Stat(rootDir)
-	if err != nil {
-		return nil, fmt.Errorf("root directory does not exist: %w", err)
-	}
-
-	if !rootInfo.IsDir() {
-		return nil, fmt.Errorf("root path is not a directory: %s", rootDir)
+	if err := a.validateContentRoot(rootDir); err != nil {
+		return nil, err
 	}
 
 	runtime.LogInfof(a.ctx, "ReadFileContents: Reading %d files from %s", len(relativePaths), rootDir)
@@ -366,127 +549,145 @@ func (a *App) ReadFileContents(rootDir string, relativePaths []string) ([]FileCo
 
 	// Process each file
 	for _, relPath := range relativePaths {
-		result := FileContentResult{
-			Path: relPath,
-		}
+		results = append(results, a.readFileContent(rootDir, relPath))
+	}
 
-		// Validate relative path
-		if relPath == "" {
-			result.Error = "empty file path"
-			results = append(results, result)
-			continue
-		}
+	runtime.LogInfof(a.ctx, "ReadFileContents: Successfully processed %d files", len(results))
+	return results, nil
+}
 
-		// Construct absolute path
-		absPath := filepath.Join(rootDir, relPath)
+// readFileContent reads one file for ReadFileContents and StreamFileContents
+// Problems are reported in the result's Error field instead of failing the whole read.
+//
+// Parameters:
+//   - rootDir: Root directory path (for resolving the relative path)
+//   - relPath: Relative path of the file
+//
+// Returns:
+//   - FileContentResult: Content, size, and error info of the file
+func (a *App) readFileContent(rootDir, relPath string) FileContentResult {
+	result := FileContentResult{
+		Path: relPath,
+	}
 
-		// Security check: ensure path is within root directory
-		cleanPath := filepath.Clean(absPath)
-		cleanRoot := filepath.Clean(rootDir)
-		if !strings.HasPrefix(cleanPath, cleanRoot) {
-			result.Error = "path is outside root directory (security violation)"
-			runtime.LogWarningf(a.ctx, "Security violation: attempted to read %s outside root %s", cleanPath, cleanRoot)
-			results = append(results, result)
-			continue
-		}
+	// Validate relative path
+	if relPath == "" {
+		result.Error = "empty file path"
+		return result
+	}
 
-		// Check if file exists
-		fileInfo, err := os.Stat(absPath)
-		if err != nil {
-			if os.IsNotExist(err) {
-				result.Error = "file not found"
-			} else {
-				result.Error = fmt.Sprintf("stat error: %v", err)
-			}
-			results = append(results, result)
-			continue
-		}
+	// Construct absolute path
+	absPath := filepath.Join(rootDir, relPath)
 
-		// Skip directories
-		if fileInfo.IsDir() {
-			result.Error = "path is a directory, not a file"
-			results = append(results, result)
-			continue
+	// Security check: ensure path is within root directory
+	cleanPath := filepath.Clean(absPath)
+	cleanRoot := filepath.Clean(rootDir)
+	if !strings.HasPrefix(cleanPath, cleanRoot) {
+		result.Error = "path is outside root directory (security violation)"
+		runtime.LogWarningf(a.ctx, "Security violation: attempted to read %s outside root %s", cleanPath, cleanRoot)
+		return result
+	}
+
+	// Check if file exists
+	fileInfo, err := a.projectFS.Stat(absPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			result.Error = "file not found"
+		} else {
+			result.Error = fmt.Sprintf("stat error: %v", err)
 		}
+		return result
+	}
 
-		// Get file size
-		result.Size = fileInfo.Size()
+	// Skip directories
+	if fileInfo.IsDir() {
+		result.Error = "path is a directory, not a file"
+		return result
+	}
 
-		// Check for excessively large files (>100MB warning threshold)
-		const maxRecommendedSize = 100 * 1024 * 1024 // 100MB
-		if result.Size > maxRecommendedSize {
-			runtime.LogWarningf(a.ctx, "Large file detected: %s (%d bytes)", relPath, result.Size)
-		}
+	// Get file size
+	result.Size = fileInfo.Size()
 
-		// Detect if file is binary
-		isBinary, err := isBinaryFile(absPath)
-		if err != nil {
-			result.Error = fmt.Sprintf("binary detection failed: %v", err)
-			results = append(results, result)
-			continue
-		}
+	// Check for excessively large files (>100MB warning threshold)
+	const maxRecommendedSize = 100 * 1024 * 1024 // 100MB
+	if result.Size > maxRecommendedSize {
+		runtime.LogWarningf(a.ctx, "Large file detected: %s (%d bytes)", relPath, result.Size)
+	}
 
-		result.IsBinary = isBinary
+	// Detect if file is binary
+	isBinary, err := a.isBinaryFile(absPath)
+	if err != nil {
+		result.Error = fmt.Sprintf("binary detection failed: %v", err)
+		return result
+	}
 
-		// Skip reading content for binary files
-		if isBinary {
-			result.Content = ""
-			runtime.LogDebugf(a.ctx, "Skipping binary file: %s", relPath)
-			results = append(results, result)
-			continue
-		}
+	result.IsBinary = isBinary
 
-		// Read file content
-		content, err := os.ReadFile(absPath)
-		if err != nil {
-			result.Error = fmt.Sprintf("read error: %v", err)
-			results = append(results, result)
-			continue
-		}
+	// Skip reading content for binary files
+	if isBinary {
+		result.Content = ""
+		runtime.LogDebugf(a.ctx, "Skipping binary file: %s", relPath)
+		return result
+	}
 
-		// Validate UTF-8 encoding
-		if !utf8.Valid(content) {
-			result.Error = "file contains invalid UTF-8 (possibly binary)"
-			result.IsBinary = true
-			runtime.LogWarningf(a.ctx, "Invalid UTF-8 in file: %s", relPath)
-			results = append(results, result)
-			continue
-		}
+	// Read file content
+	content, err := a.projectFS.ReadFile(absPath)
+	if err != nil {
+		result.Error = fmt.Sprintf("read error: %v", err)
+		return result
+	}
 
-		// Success - store content
-		result.Content = string(content)
-		results = append(results, result)
+	// Validate UTF-8 encoding
+	if !utf8.Valid(content) {
+		result.Error = "file contains invalid UTF-8 (possibly binary)"
+		result.IsBinary = true
+		runtime.LogWarningf(a.ctx, "Invalid UTF-8 in file: %s", relPath)
+		return result
 	}
 
-	runtime.LogInfof(a.ctx, "ReadFileContents: Successfully processed %d files", len(results))
-	return results, nil
+	// Success - store content
+	result.Content = string(content)
+	return result
 }
 
 // ListFiles lists files and folders in a directory, parsing .gitignore if present
 func (a *App) ListFiles(dirPath string) ([]*FileNode, error) {
-	runtime.LogDebugf(a.ctx, "ListFiles called for directory: %s", dirPath)
+	return a.ListFilesWithOptions(dirPath, ListFilesOptions{})
+}
 
-	a.projectGitignore = nil        // Reset for the new directory
-	var gitIgn *gitignore.GitIgnore // For .gitignore in the project directory
-	gitignorePath := filepath.Join(dirPath, ".gitignore")
-	runtime.LogDebugf(a.ctx, "Attempting to find .gitignore at: %s", gitignorePath)
-	if _, err := os.Stat(gitignorePath); err == nil {
-		runtime.LogDebugf(a.ctx, ".gitignore found at: %s", gitignorePath)
-		gitIgn, err = gitignore.CompileIgnoreFile(gitignorePath)
-		if err != nil {
-			runtime.LogWarningf(a.ctx, "Error compiling .gitignore file at %s: %v", gitignorePath, err)
-			gitIgn = nil
-		} else {
-			a.projectGitignore = gitIgn // Store the compiled project-specific gitignore
-			runtime.LogDebug(a.ctx, ".gitignore compiled successfully.")
-		}
-	} else {
-		runtime.LogDebugf(a.ctx, ".gitignore not found at %s (os.Stat error: %v)", gitignorePath, err)
-		gitIgn = nil
+// ListFilesWithOptions lists files and folders in a directory like ListFiles,
+// with per-call overrides of the tree settings (zero values use the saved settings)
+func (a *App) ListFilesWithOptions(dirPath string, listOpts ListFilesOptions) ([]*FileNode, error) {
+	runtime.LogDebugf(a.ctx, "ListFiles called for directory: %s", dirPath)
+	if err := a.validateContentRoot(dirPath); err != nil {
+		return nil, err
 	}
 
+	// Listing re-parses the project's .gitignore files (root and nested)
+	gitIgn := a.reloadProjectGitignore(dirPath)
+
 	// App-level custom ignore patterns are in a.currentCustomIgnorePatterns
 
+	// The default tree of the open project is served from the index while it is current
+	defaultOpts := listOpts.ShowDotfiles == nil && listOpts.MaxDepth == 0 && listOpts.MaxEntriesPerDir == 0
+	if defaultOpts {
+		if cached := a.cachedTree(dirPath); cached != nil {
+			runtime.LogDebugf(a.ctx, "ListFiles served from project index: %s", dirPath)
+			return cached, nil
+		}
+		// First listing in this session: serve the saved index while it is reindexed
+		if a.currentIndex(dirPath) == nil {
+			if restored, ok := a.restoreIndexCache(dirPath); ok {
+				if _, err := a.startProjectIndex(dirPath); err != nil {
+					runtime.LogWarningf(a.ctx, "Failed to start indexing %s: %v", dirPath, err)
+				}
+				if restored != nil {
+					return restored, nil
+				}
+			}
+		}
+	}
+
 	rootNode := &FileNode{
 		Name:         filepath.Base(dirPath),
 		Path:         dirPath,
@@ -494,34 +695,69 @@ func (a *App) ListFiles(dirPath string) ([]*FileNode, error) {
 		IsDir:        true,
 		IsGitignored: false, // Root itself is not gitignored by default
 		// IsCustomIgnored for root is also false by default, specific patterns would be needed
-		IsCustomIgnored: a.currentCustomIgnorePatterns != nil && a.currentCustomIgnorePatterns.MatchesPath("."),
+		IsCustomIgnored: a.useCustomIgnore && a.currentCustomIgnorePatterns != nil && a.currentCustomIgnorePatterns.MatchesPath("."),
 	}
 
 	// No timeout for tree building - allow unlimited time for huge codebases
 	// Previous 30-second timeout was causing failures on large projects
 	ctx := a.ctx
 
-	children, err := buildTreeRecursive(ctx, dirPath, dirPath, gitIgn, a.currentCustomIgnorePatterns, 0)
+	// Only the enabled rule sets flag nodes; the project .gitignore is still kept for the watcher
+	opts := a.newTreeBuildOptions(dirPath, gitIgn)
+	if listOpts.ShowDotfiles != nil {
+		opts.showDotfiles = *listOpts.ShowDotfiles
+	}
+	if listOpts.MaxDepth != 0 {
+		opts.maxDepth = listOpts.MaxDepth
+	}
+	if listOpts.MaxEntriesPerDir != 0 {
+		opts.maxEntriesPerDir = listOpts.MaxEntriesPerDir
+	}
+
+	children, err := a.buildTreeRecursive(ctx, dirPath, dirPath, opts, 0)
 	if err != nil {
 		return []*FileNode{rootNode}, fmt.Errorf("error building children tree for %s: %w", dirPath, err)
 	}
 	rootNode.Children = children
+	a.annotateTreeChurn(dirPath, children)
+	a.annotateTreeTokens(dirPath, children)
+
+	if defaultOpts {
+		// Opening a different project starts its background index
+		if a.currentIndex(dirPath) == nil {
+			if _, err := a.startProjectIndex(dirPath); err != nil {
+				runtime.LogWarningf(a.ctx, "Failed to start indexing %s: %v", dirPath, err)
+			}
+		}
+		a.storeTree(dirPath, []*FileNode{rootNode})
+	}
 
 	return []*FileNode{rootNode}, nil
 }
 
-func buildTreeRecursive(ctx context.Context, currentPath, rootPath string, gitIgn *gitignore.GitIgnore, customIgn *gitignore.GitIgnore, depth int) ([]*FileNode, error) {
+func (a *App) buildTreeRecursive(ctx context.Context, currentPath, rootPath string, opts *treeBuildOptions, depth int) ([]*FileNode, error) {
 	select {
 	case <-ctx.Done():
 		return nil, ctx.Err()
 	default:
 	}
 
-	entries, err := os.ReadDir(currentPath)
+	entries, err := a.projectFS.ReadDir(currentPath)
 	if err != nil {
 		return nil, err
 	}
 
+	// Apply the per-directory entry cap before scanning, so capped entries cost nothing
+	moreEntries := 0
+	if opts.maxEntriesPerDir > 0 {
+		entries, moreEntries = opts.capDirEntries(entries, currentPath, rootPath)
+	}
+
+	// An ignored or hidden directory is only walked to reach the force-included paths inside
+	// it, so its other entries inherit its flags
+	relDir, _ := filepath.Rel(rootPath, currentPath)
+	parentGitignored, parentCustomIgnored, parentHidden := opts.ancestorIgnoreFlags(relDir)
+
 	var nodes []*FileNode
 	for _, entry := range entries {
 		nodePath := filepath.Join(currentPath, entry.Name())
@@ -529,24 +765,21 @@ func buildTreeRecursive(ctx context.Context, currentPath, rootPath string, gitIg
 		// For gitignore matching, paths should generally be relative to the .gitignore file (rootPath)
 		// and use OS-specific separators. go-gitignore handles this.
 
-		isGitignored := false
-		isCustomIgnored := false
-		pathToMatch := relPath
-		if entry.IsDir() {
-			if !strings.HasSuffix(pathToMatch, string(os.PathSeparator)) {
-				pathToMatch += string(os.PathSeparator)
-			}
+		// Hidden dotfiles are left out of the tree entirely (unless force-included)
+		if (parentHidden || opts.hidesDotfile(entry.Name())) && !opts.overridesIgnore(relPath, entry.IsDir()) {
+			continue
 		}
 
-		if gitIgn != nil {
-			isGitignored = gitIgn.MatchesPath(pathToMatch)
-		}
-		if customIgn != nil {
-			isCustomIgnored = customIgn.MatchesPath(pathToMatch)
-		}
+		isGitignored, isCustomIgnored := opts.ignoreFlags(relPath, entry.IsDir())
+		isGitignored = isGitignored || parentGitignored
+		isCustomIgnored = isCustomIgnored || parentCustomIgnored
+		// Force-included paths keep their ignore flags but are scanned like regular paths, and
+		// so are the ignored directories leading to them
+		isForceIncluded := (isGitignored || isCustomIgnored) && isForceIncluded(opts.forceIncludes, relPath)
+		skipScan := (isGitignored || isCustomIgnored) && !opts.overridesIgnore(relPath, entry.IsDir())
 
 		if depth < 2 || strings.Contains(relPath, "node_modules") || strings.HasSuffix(relPath, ".log") {
-			fmt.Printf("Checking path: '%s' (original relPath: '%s'), IsDir: %v, Gitignored: %v, CustomIgnored: %v\n", pathToMatch, relPath, entry.IsDir(), isGitignored, isCustomIgnored)
+			fmt.Printf("Checking path: '%s', IsDir: %v, Gitignored: %v, CustomIgnored: %v\n", relPath, entry.IsDir(), isGitignored, isCustomIgnored)
 		}
 
 		// Initialize node with basic information
@@ -559,13 +792,17 @@ func buildTreeRecursive(ctx context.Context, currentPath, rootPath string, gitIg
 			IsCustomIgnored: isCustomIgnored,
 			Size:            0,
 			IsBinary:        false,
+			IsForceIncluded: isForceIncluded,
+			IsSummaryOnly:   entry.IsDir() && opts.summaryOnly[relPath],
 		}
 
 		if entry.IsDir() {
 			// If it's a directory, recursively call buildTree
-			// Only recurse if not ignored
-			if !isGitignored && !isCustomIgnored {
-				children, err := buildTreeRecursive(ctx, nodePath, rootPath, gitIgn, customIgn, depth+1)
+			// Only recurse if not ignored (or force-included) and within the depth limit
+			if !skipScan && opts.maxDepth > 0 && depth+1 >= opts.maxDepth {
+				node.IsTruncated = true
+			} else if !skipScan {
+				children, err := a.buildTreeRecursive(ctx, nodePath, rootPath, opts, depth+1)
 				if err != nil {
 					if errors.Is(err, context.Canceled) {
 						return nil, err // Propagate cancellation
@@ -588,8 +825,8 @@ func buildTreeRecursive(ctx context.Context, currentPath, rootPath string, gitIg
 
 				// Detect if file is binary (only if not already ignored)
 				// Skip binary detection for ignored files to save time
-				if !isGitignored && !isCustomIgnored {
-					isBinary, err := isBinaryFile(nodePath)
+				if !skipScan {
+					isBinary, err := a.isBinaryFile(nodePath)
 					if err != nil {
 						runtime.LogWarningf(context.Background(), "Error detecting binary for %s: %v", nodePath, err)
 						// On error, assume it's binary to be safe
@@ -597,6 +834,9 @@ func buildTreeRecursive(ctx context.Context, currentPath, rootPath string, gitIg
 					} else {
 						node.IsBinary = isBinary
 					}
+					if !node.IsBinary {
+						node.Language = a.detectFileLanguage(nodePath, relPath)
+					}
 				}
 			}
 		}
@@ -612,9 +852,28 @@ func buildTreeRecursive(ctx context.Context, currentPath, rootPath string, gitIg
 		}
 		return strings.ToLower(nodes[i].Name) < strings.ToLower(nodes[j].Name)
 	})
+	if moreEntries > 0 {
+		nodes = append(nodes, newMoreEntriesNode(currentPath, rootPath, moreEntries))
+	}
 	return nodes, nil
 }
 
+// isIgnoredRelPath reports whether a project-relative path matches the active ignore rules
+// It respects the useGitignore and useCustomIgnore toggles
+func (a *App) isIgnoredRelPath(relPath string, isDir bool) bool {
+	pathToMatch := relPath
+	if isDir && !strings.HasSuffix(pathToMatch, string(os.PathSeparator)) {
+		pathToMatch += string(os.PathSeparator)
+	}
+	if a.useGitignore && a.projectGitignore != nil && a.projectGitignore.MatchesPath(pathToMatch) {
+		return true
+	}
+	if a.useCustomIgnore && a.currentCustomIgnorePatterns != nil && a.currentCustomIgnorePatterns.MatchesPath(pathToMatch) {
+		return true
+	}
+	return false
+}
+
 // ContextGenerator manages the asynchronous generation of shotgun context
 // It handles background generation with cancellation support and progress tracking
 //
@@ -651,7 +910,9 @@ func NewContextGenerator(app *App) *ContextGenerator {
 // Parameters:
 //   - rootDir: Root directory to generate context from
 //   - excludedPaths: List of paths to exclude from the context
-func (cg *ContextGenerator) requestShotgunContextGenerationInternal(rootDir string, excludedPaths []string) {
+//   - resume: Continue from the project's generation checkpoint instead of starting over
+//   - opts: Options of this generation
+func (cg *ContextGenerator) requestShotgunContextGenerationInternal(rootDir string, excludedPaths []string, resume bool, opts GenerationOptions) {
 	cg.mu.Lock()
 
 	// Cancel any previous generation job that might still be running
@@ -693,7 +954,11 @@ func (cg *ContextGenerator) requestShotgunContextGenerationInternal(rootDir stri
 			return
 		}
 
-		output, err := cg.app.generateShotgunOutputWithProgress(genCtx, rootDir, excludedPaths)
+		// Track the generation for crash recovery until it finishes (successfully or not)
+		cg.app.trackGeneration(rootDir, excludedPaths, opts)
+		defer cg.app.trackGeneration("", nil, GenerationOptions{})
+
+		output, err := cg.app.generateShotgunOutputWithProgress(genCtx, rootDir, excludedPaths, resume, opts)
 
 		select {
 		case <-genCtx.Done():
@@ -705,19 +970,45 @@ func (cg *ContextGenerator) requestShotgunContextGenerationInternal(rootDir stri
 				errMsg := fmt.Sprintf("Error generating shotgun output for %s: %v", rootDir, err)
 				runtime.LogError(cg.app.ctx, errMsg)
 				runtime.EventsEmit(cg.app.ctx, "shotgunContextError", errMsg)
+				cg.app.notify(notifyContextGenerated, "Context generation failed", fmt.Sprintf("%s: %v", projectLabel(rootDir), err))
 			} else {
 				// Context generation successful - no size limit enforced
 				finalSize := len(output)
 				successMsg := fmt.Sprintf("Shotgun context generated successfully for %s. Size: %d bytes.", rootDir, finalSize)
 				runtime.LogInfo(cg.app.ctx, successMsg)
+				cg.app.recordUsage(UsageEvent{
+					Type:          "generation",
+					Project:       rootDir,
+					ContextBytes:  finalSize,
+					ContextTokens: cg.app.EstimateTokens(output),
+				})
+				cg.app.recordGeneration(rootDir, excludedPaths, output)
 				runtime.EventsEmit(cg.app.ctx, "shotgunContextGenerated", output)
+				cg.app.emitContextChunks(rootDir, output)
+				cg.app.notify(notifyContextGenerated, "Context generated",
+					fmt.Sprintf("%s: ~%d tokens", projectLabel(rootDir), cg.app.EstimateTokens(output)))
 			}
 		}
 	}(myToken) // Pass the token to the goroutine
 }
 
+// GenerationOptions are the per-generation switches of a context generation
+type GenerationOptions struct {
+	StripComments bool   `json:"stripComments"`           // Strip comments and blank lines of supported languages (see comment_strip.go)
+	ChangedSince  string `json:"changedSince,omitempty"`  // Git ref: only files changed since it get content, the rest is tree-only (see changed_only.go)
+	SummaryAPIKey string `json:"summaryApiKey,omitempty"` // API key for the LLM summaries of large files (see file_summaries.go; never saved)
+	RepoMap       bool   `json:"repoMap"`                 // Files get only their declarations, without bodies (see repo_map.go)
+}
+
 // RequestShotgunContextGeneration is the method bound to Wails.
+// Large selections emit "shotgunContextConfirmationRequired" instead of starting right away.
 func (a *App) RequestShotgunContextGeneration(rootDir string, excludedPaths []string) {
+	a.requestShotgunContextGeneration(rootDir, excludedPaths, true, GenerationOptions{})
+}
+
+// requestShotgunContextGeneration validates a generation request and starts it,
+// optionally after the large-project confirmation check
+func (a *App) requestShotgunContextGeneration(rootDir string, excludedPaths []string, checkSize bool, opts GenerationOptions) {
 	// Validate context generator
 	if a.contextGenerator == nil {
 		// This should not happen if startup initializes it correctly
@@ -734,18 +1025,27 @@ func (a *App) RequestShotgunContextGeneration(rootDir string, excludedPaths []st
 	}
 
 	// Check if directory exists
-	if _, err := os.Stat(rootDir); os.IsNotExist(err) {
+	if _, err := a.projectFS.Stat(rootDir); os.IsNotExist(err) {
 		runtime.LogErrorf(a.ctx, "RequestShotgunContextGeneration: directory does not exist: %s", rootDir)
 		runtime.EventsEmit(a.ctx, "shotgunContextError", fmt.Sprintf("Directory does not exist: %s", rootDir))
 		return
 	}
 
+	if err := a.validateContentRoot(rootDir); err != nil {
+		runtime.EventsEmit(a.ctx, "shotgunContextError", err.Error())
+		return
+	}
+
 	// Validate excludedPaths (ensure it's not nil)
 	if excludedPaths == nil {
 		excludedPaths = []string{}
 	}
 
-	a.contextGenerator.requestShotgunContextGenerationInternal(rootDir, excludedPaths)
+	if checkSize && a.needsGenerationConfirmation(rootDir, excludedPaths, opts) {
+		return
+	}
+
+	a.contextGenerator.requestShotgunContextGenerationInternal(rootDir, excludedPaths, false, opts)
 }
 
 // CancelShotgunContextGeneration cancels the currently running context generation
@@ -797,6 +1097,63 @@ func (a *App) CancelJob(jobID string) error {
 	return a.jobQueue.CancelJob(jobID)
 }
 
+// ForceKillJob abandons a job whose task ignores cancellation, marking it zombie
+// This method is exposed to the frontend via Wails binding
+//
+// Parameters:
+//   - jobID: Unique identifier of the job to abandon
+//
+// Returns:
+//   - error: Error if job not found or its task already returned
+func (a *App) ForceKillJob(jobID string) error {
+	if a.jobQueue == nil {
+		return fmt.Errorf("job queue not initialized")
+	}
+	return a.jobQueue.ForceKillJob(jobID)
+}
+
+// CancelAllJobs cancels every queued or running job
+// This method is exposed to the frontend via Wails binding
+//
+// Returns:
+//   - int: Number of jobs cancelled
+//   - error: Error if the job queue is not initialized
+func (a *App) CancelAllJobs() (int, error) {
+	if a.jobQueue == nil {
+		return 0, fmt.Errorf("job queue not initialized")
+	}
+	return a.jobQueue.CancelAllJobs(), nil
+}
+
+// ClearCompletedJobs removes all finished (completed, failed, cancelled) jobs from the queue
+// This method is exposed to the frontend via Wails binding
+//
+// Returns:
+//   - int: Number of jobs removed
+//   - error: Error if the job queue is not initialized
+func (a *App) ClearCompletedJobs() (int, error) {
+	if a.jobQueue == nil {
+		return 0, fmt.Errorf("job queue not initialized")
+	}
+	return a.jobQueue.ClearCompletedJobs(), nil
+}
+
+// RetryJob re-runs a failed, cancelled or zombie job as a new job
+// This method is exposed to the frontend via Wails binding
+//
+// Parameters:
+//   - jobID: Unique identifier of the job to retry
+//
+// Returns:
+//   - string: ID of the new job
+//   - error: Error if job not found or cannot be retried
+func (a *App) RetryJob(jobID string) (string, error) {
+	if a.jobQueue == nil {
+		return "", fmt.Errorf("job queue not initialized")
+	}
+	return a.jobQueue.RetryJob(jobID)
+}
+
 // GetJobStatuses returns the current status of all jobs
 // This method is exposed to the frontend via Wails binding
 //
@@ -815,50 +1172,126 @@ func (a *App) GetJobStatuses() []Job {
 
 // CallLLMAPI calls an LLM API (Google AI Studio, OpenAI, or Anthropic)
 // This method runs the LLM call as a background job and returns the job ID
+// It names no prompt mode; CallLLMAPIForMode applies the defaults of one.
 //
 // Parameters:
 //   - provider: LLM provider (google, openai, anthropic)
 //   - apiKey: API key for the provider
 //   - prompt: The prompt to send to the LLM
 //   - model: Model name (e.g., gemini-1.5-pro, gpt-4, claude-3-5-sonnet-20241022)
-//   - temperature: Temperature for generation (0.0-1.0)
-//   - maxTokens: Maximum tokens to generate
+//   - temperature: Temperature for generation (0.0-1.0, 0 for deterministic output)
+//   - maxTokens: Maximum tokens to generate (0 uses the default)
 //
 // Returns:
 //   - string: Job ID for tracking the LLM call
 //   - error: Error if job creation fails
 func (a *App) CallLLMAPI(provider, apiKey, prompt, model string, temperature float64, maxTokens int) (string, error) {
+	req := LLMRequest{
+		Provider:    provider,
+		APIKey:      apiKey,
+		Prompt:      prompt,
+		Model:       model,
+		Temperature: &temperature, // Explicit values (including 0) are always honored
+	}
+	if maxTokens > 0 {
+		req.MaxTokens = &maxTokens
+	}
+	return a.CallLLMAPIWithRequest(req)
+}
+
+// CallLLMAPIWithRequest calls an LLM API with a full request, including the extended
+// sampling parameters (stop sequences, topP, topK, penalties, seed) and the custom base URL.
+// With req.Mode set, fields left unset take the defaults of that prompt mode (SetModeDefaults).
+// This method runs the LLM call as a background job and returns the job ID
+//
+// Parameters:
+//   - req: LLM request (provider, API key, prompt, model, and optional parameters)
+//
+// Returns:
+//   - string: Job ID for tracking the LLM call
+//   - error: Error if job creation fails
+func (a *App) CallLLMAPIWithRequest(req LLMRequest) (string, error) {
 	if a.jobQueue == nil {
 		return "", fmt.Errorf("job queue not initialized")
 	}
 
-	// Create LLM client
-	client := NewLLMClient(a)
+	// Continuations are controlled by the user setting (applied by the llm_call handler)
+	return a.jobQueue.Enqueue("llm_call", req)
+}
 
-	// Add LLM call as a background job
-	jobID := a.jobQueue.AddJob("llm_call", func(ctx context.Context) error {
-		// Create LLM request
-		req := LLMRequest{
-			Provider:    provider,
-			APIKey:      apiKey,
-			Prompt:      prompt,
-			Model:       model,
-			Temperature: temperature,
-			MaxTokens:   maxTokens,
+// emitLLMResponse emits a finished LLM response to the frontend
+// Truncated responses are remembered so the user can continue them with ContinueResponse
+//
+// Parameters:
+//   - jobID: Job that produced the response
+//   - req: Request of the response
+//   - resp: Response to emit (stitched for a continuation)
+//   - usage: Usage not recorded yet: resp itself for a new call, the continuation request alone
+//     for a stitched response, whose earlier part was recorded by its own call
+func (a *App) emitLLMResponse(jobID string, req LLMRequest, resp, usage *LLMResponse) {
+	// Diffs in the response are kept for crash recovery until marked as applied
+	a.trackUnappliedDiff(jobID, resp.Content)
+	a.recordWorkflowResponse(jobID, resp)
+	a.recordUsage(llmCallEvent(req.RootDir, usage))
+
+	if resp.Truncated {
+		a.truncatedMu.Lock()
+		if a.truncatedCalls == nil {
+			a.truncatedCalls = make(map[string]*truncatedLLMCall)
 		}
+		a.truncatedCalls[jobID] = &truncatedLLMCall{request: req, response: resp}
+		a.truncatedMu.Unlock()
 
-		// Call LLM API
-		resp, err := client.CallLLM(ctx, req)
-		if err != nil {
-			return err
-		}
+		runtime.LogWarningf(a.ctx, "LLM response for job %s was truncated (%s)", jobID, resp.FinishReason)
+		runtime.EventsEmit(a.ctx, "llmResponseTruncated", map[string]interface{}{
+			"jobId":    jobID,
+			"response": resp,
+		})
+	}
 
-		// Emit response to frontend
-		runtime.EventsEmit(a.ctx, "llmResponseReceived", resp)
-		return nil
-	})
+	runtime.EventsEmit(a.ctx, "llmResponseReceived", resp)
+	a.notify(notifyLLMResponse, "LLM response received", fmt.Sprintf("%s: %d tokens", resp.Model, resp.TokensUsed))
+}
+
+// ContinueResponse requests the continuation of a truncated LLM response
+// This method runs the continuation as a background job and returns the new job ID.
+// The stitched response (previous content + continuation) is emitted as "llmResponseReceived".
+//
+// Parameters:
+//   - jobID: ID of the job whose response was truncated
+//
+// Returns:
+//   - string: Job ID for tracking the continuation
+//   - error: Error if the job has no truncated response
+func (a *App) ContinueResponse(jobID string) (string, error) {
+	if a.jobQueue == nil {
+		return "", fmt.Errorf("job queue not initialized")
+	}
+
+	a.truncatedMu.Lock()
+	_, ok := a.truncatedCalls[jobID]
+	a.truncatedMu.Unlock()
 
-	return jobID, nil
+	if !ok {
+		return "", fmt.Errorf("no truncated response found for job: %s", jobID)
+	}
+
+	return a.jobQueue.Enqueue("llm_continuation", continuationParams{JobID: jobID})
+}
+
+// GetDefaultMaxTokens returns the output token cap used when a request does not set maxTokens
+//
+// Parameters:
+//   - provider: LLM provider (google, openai, anthropic, custom)
+//   - model: Model name (empty for the provider default model)
+//
+// Returns:
+//   - int: Default maximum output tokens for the model
+func (a *App) GetDefaultMaxTokens(provider, model string) int {
+	if strings.TrimSpace(model) == "" {
+		model = NewLLMClient(a).getDefaultModel(provider)
+	}
+	return defaultMaxTokensForModel(model)
 }
 
 // GeneratePrompt generates a complete prompt from context, mode, and task description
@@ -875,23 +1308,54 @@ func (a *App) CallLLMAPI(provider, apiKey, prompt, model string, temperature flo
 // Returns:
 //   - string: The complete formatted prompt
 func (a *App) GeneratePrompt(context, mode, taskDescription, customRules string) string {
+	mode, sections := a.promptSections(context, mode, taskDescription, customRules)
+	a.recordUsage(UsageEvent{Type: "prompt", Mode: mode})
+
+	var prompt strings.Builder
+	for _, section := range sections {
+		prompt.WriteString(section.Text)
+	}
+	return prompt.String()
+}
+
+// promptSections builds the sections of a prompt in order; their texts concatenated are the prompt
+// Empty inputs are replaced by placeholders (and logged).
+//
+// Returns:
+//   - string: Mode used (dev if empty)
+//   - []PromptSection: Instructions, context, task, optional rules, and output instructions
+func (a *App) promptSections(context, mode, taskDescription, customRules string) (string, []PromptSection) {
 	// Validate inputs
 	if strings.TrimSpace(context) == "" {
-		runtime.LogWarning(a.ctx, "GeneratePrompt called with empty context")
+		runtime.LogWarning(a.ctx, "Prompt composed with empty context")
 		context = "[No codebase context available]"
 	}
 
 	if strings.TrimSpace(mode) == "" {
-		runtime.LogWarning(a.ctx, "GeneratePrompt called with empty mode, defaulting to 'dev'")
+		runtime.LogWarning(a.ctx, "Prompt composed with empty mode, defaulting to 'dev'")
 		mode = "dev"
 	}
 
 	if strings.TrimSpace(taskDescription) == "" {
-		runtime.LogWarning(a.ctx, "GeneratePrompt called with empty task description")
+		runtime.LogWarning(a.ctx, "Prompt composed with empty task description")
 		taskDescription = "[No task description provided]"
 	}
 
+	// Code changes are requested as diffs unless whole files are configured
+	changeFormat := "Generate a git diff format output that can be applied directly"
+	fixFormat := "Provide git diff format for fixes"
+	changeInstructions := "If you're generating code changes, provide them in git diff format so they can be applied directly to the codebase."
+	if a.responseFormat() == responseFormatWholeFile {
+		changeFormat = "Return complete updated files in the format described under Instructions"
+		fixFormat = "Return fixed files in full in the format described under Instructions"
+		changeInstructions = "If you're generating code changes, follow this format strictly.\n\n" + wholeFileInstructions
+	}
+	if a.settings.LineNumbers {
+		changeInstructions += "\n\n" + lineNumberInstructions
+	}
+
 	var modeInstructions string
+	templateText, templateRules := a.templateInstructions(mode, changeFormat)
 
 	switch mode {
 	case "dev":
@@ -899,7 +1363,7 @@ func (a *App) GeneratePrompt(context, mode, taskDescription, customRules string)
 - Provide complete, working code
 - Follow the existing code style and patterns
 - Include necessary imports and dependencies
-- Generate a git diff format output that can be applied directly`
+- ` + changeFormat
 
 	case "architect":
 		modeInstructions = `You are a software architect. Your task is to design system architecture and plan refactoring.
@@ -913,7 +1377,7 @@ func (a *App) GeneratePrompt(context, mode, taskDescription, customRules string)
 - Analyze the code for potential issues
 - Identify security vulnerabilities
 - Suggest fixes with explanations
-- Provide git diff format for fixes`
+- ` + fixFormat
 
 	case "tasks":
 		modeInstructions = `You are a project manager. Your task is to generate task lists and update documentation.
@@ -923,35 +1387,33 @@ func (a *App) GeneratePrompt(context, mode, taskDescription, customRules string)
 - Provide clear acceptance criteria`
 
 	default:
-		runtime.LogWarningf(a.ctx, "Unknown mode '%s', using default instructions", mode)
+		if templateText == "" {
+			runtime.LogWarningf(a.ctx, "Unknown mode '%s', using default instructions", mode)
+		}
 		modeInstructions = "You are an AI assistant helping with software development tasks."
 	}
+	if templateText != "" {
+		modeInstructions = templateText // Saved prompt templates replace the built-in instructions
+	}
+	if (strings.TrimSpace(customRules) == "" || customRules == "no additional rules") && templateRules != "" {
+		customRules = templateRules
+	}
 
-	prompt := fmt.Sprintf(`%s
-
-# Codebase Context
-
-%s
-
-# Task
-
-%s`, modeInstructions, context, taskDescription)
+	sections := []PromptSection{
+		{Label: "instructions", Title: "Mode Instructions", Text: modeInstructions},
+		{Label: "context", Title: "Codebase Context", Text: "\n\n# Codebase Context\n\n" + context},
+		{Label: "task", Title: "Task", Text: "\n\n# Task\n\n" + taskDescription},
+	}
 
 	if strings.TrimSpace(customRules) != "" && customRules != "no additional rules" {
-		prompt += fmt.Sprintf(`
-
-# Additional Rules and Constraints
-
-%s`, customRules)
+		sections = append(sections, PromptSection{Label: "rules", Title: "Additional Rules and Constraints",
+			Text: "\n\n# Additional Rules and Constraints\n\n" + customRules})
 	}
 
-	prompt += `
-
-# Instructions
+	sections = append(sections, PromptSection{Label: "instructions", Title: "Instructions",
+		Text: "\n\n# Instructions\n\nPlease analyze the codebase context and complete the requested task. " + changeInstructions})
 
-Please analyze the codebase context and complete the requested task. If you're generating code changes, provide them in git diff format so they can be applied directly to the codebase.`
-
-	return prompt
+	return mode, sections
 }
 
 // EstimateTokens estimates the number of tokens in a text string
@@ -1044,8 +1506,14 @@ func (a *App) EstimateCost(provider, model string, inputTokens, outputTokens int
 		}
 
 	case "anthropic":
-		inputCostPer1M = 3.0
-		outputCostPer1M = 15.0
+		if strings.Contains(model, "haiku") {
+			inputCostPer1M = 1.0
+			outputCostPer1M = 5.0
+		} else {
+			// Sonnet
+			inputCostPer1M = 3.0
+			outputCostPer1M = 15.0
+		}
 
 	case "custom":
 		// Unknown pricing for custom providers
@@ -1070,7 +1538,7 @@ func (a *App) EstimateCost(provider, model string, inputTokens, outputTokens int
 
 // countProcessableItems estimates the total number of operations for progress tracking.
 // Operations: 1 for root dir line, 1 for each dir/file entry in tree, 1 for each file content read.
-func (a *App) countProcessableItems(jobCtx context.Context, rootDir string, excludedMap map[string]bool) (int, error) {
+func (a *App) countProcessableItems(jobCtx context.Context, rootDir string, excludedMap map[string]bool, opts *treeBuildOptions) (int, error) {
 	count := 1 // For the root directory line itself
 
 	var counterHelper func(currentPath string) error
@@ -1081,7 +1549,7 @@ func (a *App) countProcessableItems(jobCtx context.Context, rootDir string, excl
 		default:
 		}
 
-		entries, err := os.ReadDir(currentPath)
+		entries, err := a.projectFS.ReadDir(currentPath)
 		if err != nil {
 			runtime.LogWarningf(a.ctx, "countProcessableItems: error reading dir %s: %v", currentPath, err)
 			return nil // Continue counting other parts if a subdir is inaccessible
@@ -1091,13 +1559,15 @@ func (a *App) countProcessableItems(jobCtx context.Context, rootDir string, excl
 			path := filepath.Join(currentPath, entry.Name())
 			relPath, _ := filepath.Rel(rootDir, path)
 
-			if excludedMap[relPath] {
+			if excludedMap[relPath] || opts.excludes(relPath, entry.IsDir()) {
 				continue
 			}
 
 			count++ // For the tree entry (dir or file)
 
-			if entry.IsDir() {
+			if entry.IsDir() && opts.summaryOnly[relPath] {
+				count++ // For the directory summary
+			} else if entry.IsDir() {
 				err := counterHelper(path)
 				if err != nil { // Propagate cancellation or critical errors
 					return err
@@ -1122,24 +1592,58 @@ type generationProgressState struct {
 }
 
 func (a *App) emitProgress(state *generationProgressState) {
-	runtime.EventsEmit(a.ctx, "shotgunContextGenerationProgress", map[string]int{
+	a.emitGenerationEvent("shotgunContextGenerationProgress", map[string]int{
 		"current": state.processedItems,
 		"total":   state.totalItems,
 	})
 }
 
+// emitGenerationEvent emits an event of a context generation (none from a detached copy)
+func (a *App) emitGenerationEvent(name string, data interface{}) {
+	if a.detached {
+		return
+	}
+	runtime.EventsEmit(a.ctx, name, data)
+}
+
+// sortTreeEntries sorts directory entries like ListFiles: directories first, then by name
+// (case-insensitive)
+func sortTreeEntries(entries []fs.DirEntry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		entryI := entries[i]
+		entryJ := entries[j]
+		isDirI := entryI.IsDir()
+		isDirJ := entryJ.IsDir()
+		if isDirI && !isDirJ {
+			return true
+		}
+		if !isDirI && isDirJ {
+			return false
+		}
+		return strings.ToLower(entryI.Name()) < strings.ToLower(entryJ.Name())
+	})
+}
+
 // generateShotgunOutputWithProgress generates the TXT output with progress reporting and size limits
-func (a *App) generateShotgunOutputWithProgress(jobCtx context.Context, rootDir string, excludedPaths []string) (string, error) {
+// Large generations are checkpointed so they can be resumed (resume=true) after a cancel or crash
+func (a *App) generateShotgunOutputWithProgress(jobCtx context.Context, rootDir string, excludedPaths []string, resume bool, opts GenerationOptions) (string, error) {
 	if err := jobCtx.Err(); err != nil { // Check for cancellation at the beginning
 		return "", err
 	}
+	if err := a.validateContentRoot(rootDir); err != nil {
+		return "", err
+	}
 
 	excludedMap := make(map[string]bool)
 	for _, p := range excludedPaths {
 		excludedMap[p] = true
 	}
 
-	totalItems, err := a.countProcessableItems(jobCtx, rootDir, excludedMap)
+	// Ignore rules are applied here too (per the useGitignore/useCustomIgnore toggles),
+	// so ignored paths are dropped even if the frontend did not exclude them
+	ignoreOpts := a.newTreeBuildOptions(rootDir, a.compileProjectGitignore(rootDir))
+
+	totalItems, err := a.countProcessableItems(jobCtx, rootDir, excludedMap, ignoreOpts)
 	if err != nil {
 		return "", fmt.Errorf("failed to count processable items: %w", err)
 	}
@@ -1147,13 +1651,139 @@ func (a *App) generateShotgunOutputWithProgress(jobCtx context.Context, rootDir
 	a.emitProgress(progressState) // Initial progress (0 / total)
 
 	var output strings.Builder
-	var fileContents strings.Builder
+	// File blocks are the bulk of the output; the memory guard moves them to disk if needed
+	fileContents := a.newSpillBuffer()
+	defer fileContents.Close()
+
+	// Checkpointing: resume from saved blocks, or start a new checkpoint for large generations
+	var checkpoint *checkpointWriter
+	var processedFiles map[string]bool
+	if resume {
+		cp, savedContent, savedPaths, cpErr := a.loadGenerationCheckpoint(rootDir)
+		switch {
+		case cpErr != nil:
+			runtime.LogWarningf(a.ctx, "Cannot resume generation for %s, starting over: %v", rootDir, cpErr)
+		case cp == nil || !sameExclusions(cp.ExcludedPaths, excludedPaths) || cp.outputFormat() != a.outputFormat() || cp.LineNumbers != a.settings.LineNumbers || cp.StripComments != opts.StripComments || cp.ChangedSince != opts.ChangedSince || cp.RepoMap != opts.RepoMap || cp.fileOrder() != a.fileOrder() ||
+			cp.MaxFileBlockBytes != a.settings.MaxFileBlockBytes || cp.fileSizeCap() != a.GetFileSizeCap() || !a.sameContentRules(cp):
+			runtime.LogWarningf(a.ctx, "No matching generation checkpoint for %s, starting over", rootDir)
+		default:
+			if checkpoint, cpErr = a.newCheckpointWriter(rootDir, excludedPaths, cp, opts); cpErr == nil {
+				fileContents.WriteString(savedContent)
+				processedFiles = savedPaths
+				runtime.LogInfof(a.ctx, "Resuming generation for %s: %d files already done", rootDir, len(savedPaths))
+			} else {
+				runtime.LogWarningf(a.ctx, "Cannot resume generation for %s, starting over: %v", rootDir, cpErr)
+			}
+		}
+	}
+	if checkpoint == nil && a.configPath != "" {
+		os.RemoveAll(a.checkpointDir(rootDir)) // A new generation replaces any previous checkpoint
+		if totalItems >= checkpointMinItems {
+			if checkpoint, err = a.newCheckpointWriter(rootDir, excludedPaths, nil, opts); err != nil {
+				runtime.LogWarningf(a.ctx, "Generation checkpoints disabled for %s: %v", rootDir, err)
+				checkpoint = nil
+			}
+		}
+	}
+	filters := a.newContentFilterRun()   // nil without enabled content filters
+	secrets := a.newSecretRedactionRun() // nil unless secrets are redacted
+	duplicates := a.newDuplicateTracker()
+	strip := newCommentStripRun(opts)           // nil unless the generation strips comments
+	repoMap := newRepoMapRun(opts)              // nil unless files get only their declarations
+	fileOrder := a.newFileOrderRun(rootDir)     // nil unless files are ordered by dependency
+	oversized := a.newOversizedFileRun(rootDir) // nil without a block size limit
+	cache := &contentCacheRun{}
+	changedOnly, err := a.newChangedOnlyRun(jobCtx, rootDir, opts) // nil unless only changed files get content
+	if err != nil {
+		return "", err
+	}
+	var budget *budgetPlan // nil without a token budget
+	if a.settings.GenerationTokenBudget > 0 && repoMap == nil {
+		if budget, err = a.planTokenBudget(rootDir, excludedMap, ignoreOpts.summaryOnly, changedOnly, a.settings.GenerationTokenBudget); err != nil {
+			return "", fmt.Errorf("failed to plan token budget: %w", err)
+		}
+	}
+	summaries := a.newFileSummaryRun(jobCtx, rootDir, excludedPaths, opts, changedOnly) // nil unless large files are summarized
+	defer summaries.stop()
+	generationDone := false
+	defer func() {
+		if checkpoint == nil {
+			return
+		}
+		if generationDone {
+			checkpoint.discard()
+		} else if flushErr := checkpoint.flush(); flushErr != nil {
+			runtime.LogWarningf(a.ctx, "Failed to save generation checkpoint for %s: %v", rootDir, flushErr)
+		}
+	}()
+
+	// Optional preamble summarizing the languages of the selection
+	if a.settings.IncludeTechStack {
+		if langStats, langErr := a.computeLanguageStats(jobCtx, rootDir, excludedPaths, false); langErr == nil {
+			if summary := techStackSummary(langStats); summary != "" {
+				output.WriteString(summary + "\n\n")
+			}
+		}
+	}
+
+	output.WriteString(changedOnly.preamble())
+	output.WriteString(repoMap.preamble())
+	output.WriteString(fileOrder.preamble())
 
 	// Root directory line - no size limit enforced
+	treeStart := output.Len()
 	output.WriteString(filepath.Base(rootDir) + string(os.PathSeparator) + "\n")
 	progressState.processedItems++
 	a.emitProgress(progressState)
 
+	// processFile writes the block of one file
+	processFile := func(pCtx context.Context, path, relPath string) error {
+		select { // Check before heavy I/O
+		case <-pCtx.Done():
+			return pCtx.Err()
+		default:
+		}
+
+		// Files already in the resumed checkpoint keep their saved block; unchanged files
+		// of a changed-only generation are listed in the tree only
+		if processedFiles[relPath] || changedOnly.skips(relPath) {
+			progressState.processedItems++
+			a.emitProgress(progressState)
+			return nil
+		}
+
+		var builder strings.Builder
+		if budget.drops(relPath) {
+			builder.WriteString(fmt.Sprintf("<!-- File omitted (token budget): %s -->\n", filepath.ToSlash(relPath)))
+		} else if repoMap != nil {
+			a.appendRepoMapBlock(&builder, path, relPath, repoMap)
+		} else if summary := summaries.wait(pCtx, relPath); summary != nil {
+			builder.WriteString(a.fileSummaryBlock(summary))
+		} else {
+			a.appendCachedFileContent(&builder, path, relPath, budget.contentLimit(relPath), strip, cache)
+		}
+		secrets.record(filepath.ToSlash(relPath), builder.String())
+		block, filterErr := filters.applyToBlock(filepath.ToSlash(relPath), builder.String())
+		if filterErr != nil {
+			return filterErr
+		}
+		block = oversized.apply(path, relPath, block)
+		block = a.formatBlock(duplicates.apply(filepath.ToSlash(relPath), block))
+		if _, writeErr := fileContents.WriteString(block); writeErr != nil {
+			return writeErr
+		}
+		if checkpoint != nil {
+			if cpErr := checkpoint.addFile(relPath, block); cpErr != nil {
+				runtime.LogWarningf(a.ctx, "Generation checkpoints disabled for %s: %v", rootDir, cpErr)
+				checkpoint = nil
+			}
+		}
+
+		progressState.processedItems++ // For file content
+		a.emitProgress(progressState)
+		return nil
+	}
+
 	// buildShotgunTreeRecursive is a recursive helper for generating the tree string and file contents
 	var buildShotgunTreeRecursive func(pCtx context.Context, currentPath, prefix string) error
 	buildShotgunTreeRecursive = func(pCtx context.Context, currentPath, prefix string) error {
@@ -1163,7 +1793,7 @@ func (a *App) generateShotgunOutputWithProgress(jobCtx context.Context, rootDir
 		default:
 		}
 
-		entries, err := os.ReadDir(currentPath)
+		entries, err := a.projectFS.ReadDir(currentPath)
 		if err != nil {
 			runtime.LogWarningf(a.ctx, "buildShotgunTreeRecursive: error reading dir %s: %v", currentPath, err)
 			// Decide if this error should halt the entire process or just skip this directory
@@ -1172,26 +1802,14 @@ func (a *App) generateShotgunOutputWithProgress(jobCtx context.Context, rootDir
 		}
 
 		// Sort entries like in ListFiles for consistent tree
-		sort.SliceStable(entries, func(i, j int) bool {
-			entryI := entries[i]
-			entryJ := entries[j]
-			isDirI := entryI.IsDir()
-			isDirJ := entryJ.IsDir()
-			if isDirI && !isDirJ {
-				return true
-			}
-			if !isDirI && isDirJ {
-				return false
-			}
-			return strings.ToLower(entryI.Name()) < strings.ToLower(entryJ.Name())
-		})
+		sortTreeEntries(entries)
 
 		// Create a temporary slice to hold non-excluded entries for correct prefixing
 		var visibleEntries []fs.DirEntry
 		for _, entry := range entries {
 			path := filepath.Join(currentPath, entry.Name())
 			relPath, _ := filepath.Rel(rootDir, path)
-			if !excludedMap[relPath] {
+			if !excludedMap[relPath] && !ignoreOpts.excludes(relPath, entry.IsDir()) {
 				visibleEntries = append(visibleEntries, entry)
 			}
 		}
@@ -1214,102 +1832,212 @@ func (a *App) generateShotgunOutputWithProgress(jobCtx context.Context, rootDir
 				branch = "`-- "
 				nextPrefix = prefix + "    "
 			}
-			output.WriteString(prefix + branch + entry.Name() + "\n")
+			output.WriteString(prefix + branch + entry.Name() + changedOnly.treeMark(relPath, entry.IsDir()) + "\n")
 
 			progressState.processedItems++ // For tree entry
 			a.emitProgress(progressState)
 
 			// No size limit check - allow unlimited context generation
 
-			if entry.IsDir() {
-				err := buildShotgunTreeRecursive(pCtx, path, nextPrefix)
-				if err != nil {
-					if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
-						return err
-					}
-					fmt.Printf("Error processing subdirectory %s: %v\n", path, err)
-				}
-			} else {
-				select { // Check before heavy I/O
-				case <-pCtx.Done():
-					return pCtx.Err()
-				default:
-				}
-
-				// Detect if file is binary before reading
-				isBinary, err := isBinaryFile(path)
-				if err != nil {
-					runtime.LogWarningf(a.ctx, "Error detecting binary for %s: %v (skipping)", path, err)
-					progressState.processedItems++ // Count as processed
-					a.emitProgress(progressState)
-					continue // Skip this file
-				}
-
-				// Skip binary files in context generation
-				if isBinary {
-					runtime.LogDebugf(a.ctx, "Skipping binary file in context: %s", relPath)
-					// Add a placeholder comment in the file contents section
-					relPathForwardSlash := filepath.ToSlash(relPath)
-					fileContents.WriteString(fmt.Sprintf("<!-- Binary file skipped: %s -->\n", relPathForwardSlash))
-					progressState.processedItems++ // Count as processed
-					a.emitProgress(progressState)
-					continue // Skip to next file
-				}
-
-				// Read file content
-				content, err := os.ReadFile(path)
-				if err != nil {
-					runtime.LogWarningf(a.ctx, "Error reading file %s: %v", path, err)
-					// Include error message in output for debugging
-					relPathForwardSlash := filepath.ToSlash(relPath)
-					fileContents.WriteString(fmt.Sprintf("<file path=\"%s\">\n", relPathForwardSlash))
-					fileContents.WriteString(fmt.Sprintf("Error reading file: %v", err))
-					fileContents.WriteString("\n</file>\n")
+			// A summary-only directory gets one paragraph instead of its subtree and files
+			if entry.IsDir() && ignoreOpts.summaryOnly[relPath] {
+				if processedFiles[relPath] {
 					progressState.processedItems++
 					a.emitProgress(progressState)
 					continue
 				}
-
-				// Validate UTF-8 encoding
-				if !utf8.Valid(content) {
-					runtime.LogWarningf(a.ctx, "File contains invalid UTF-8 (skipping): %s", relPath)
-					relPathForwardSlash := filepath.ToSlash(relPath)
-					fileContents.WriteString(fmt.Sprintf("<!-- File skipped (invalid UTF-8): %s -->\n", relPathForwardSlash))
-					progressState.processedItems++
-					a.emitProgress(progressState)
-					continue
+				summary, _, err := a.summarizeDirectory(pCtx, rootDir, relPath, func(p string) bool { return excludedMap[p] }, ignoreOpts)
+				if err != nil {
+					return err
 				}
-
-				// Ensure forward slashes for the name attribute, consistent with documentation.
-				relPathForwardSlash := filepath.ToSlash(relPath)
-
-				fileContents.WriteString(fmt.Sprintf("<file path=\"%s\">\n", relPathForwardSlash))
-				fileContents.WriteString(string(content))
-				fileContents.WriteString("\n</file>\n") // Each file block ends with a newline
-
-				progressState.processedItems++ // For file content
+				block := a.formatBlock(directorySummaryBlock(summary))
+				if _, writeErr := fileContents.WriteString(block); writeErr != nil {
+					return writeErr
+				}
+				if checkpoint != nil {
+					if cpErr := checkpoint.addFile(relPath, block); cpErr != nil {
+						runtime.LogWarningf(a.ctx, "Generation checkpoints disabled for %s: %v", rootDir, cpErr)
+						checkpoint = nil
+					}
+				}
+				progressState.processedItems++
 				a.emitProgress(progressState)
-
-				// No size limit check - allow unlimited context generation
+			} else if entry.IsDir() {
+				err := buildShotgunTreeRecursive(pCtx, path, nextPrefix)
+				if err != nil {
+					if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, errContentBlocked) {
+						return err
+					}
+					fmt.Printf("Error processing subdirectory %s: %v\n", path, err)
+				}
+			} else if fileOrder != nil {
+				fileOrder.add(path, relPath) // Written after the walk, in dependency order
+			} else if err := processFile(pCtx, path, relPath); err != nil {
+				return err
 			}
 		}
 		return nil
 	}
 
 	err = buildShotgunTreeRecursive(jobCtx, rootDir, "")
+	for _, file := range a.orderedFiles(fileOrder) {
+		if err != nil {
+			break
+		}
+		err = processFile(jobCtx, file.path, file.relPath)
+	}
+	var attachments string
+	if err == nil {
+		attachments, err = a.attachmentsSection(rootDir, secrets, filters)
+	}
+	a.emitSecretsRedacted(rootDir, secrets)
+	a.emitFileSummaries(rootDir, summaries)
+	if filters != nil && len(filters.findings) > 0 {
+		runtime.LogInfof(a.ctx, "Content filters matched in %d places in %s", len(filters.findings), rootDir)
+		a.emitGenerationEvent("contentFilterFindings", map[string]interface{}{
+			"rootDir":  rootDir,
+			"findings": filters.findings,
+		})
+	}
+	if errors.Is(err, errContentBlocked) {
+		return "", err
+	}
+	if groups := duplicates.report(); err == nil && len(groups) > 0 {
+		bytesSaved, tokensSaved := duplicates.saved()
+		if duplicates.dedupe {
+			runtime.LogInfof(a.ctx, "Included %d groups of identical files in %s once, saving %s (~%d tokens)",
+				len(groups), rootDir, formatByteSize(bytesSaved), tokensSaved)
+		} else {
+			runtime.LogInfof(a.ctx, "Found %d groups of identical files in %s (deduplicating would save ~%d tokens)",
+				len(groups), rootDir, tokensSaved)
+		}
+		a.emitGenerationEvent("duplicateContentDetected", map[string]interface{}{
+			"rootDir":      rootDir,
+			"groups":       groups,
+			"deduplicated": duplicates.dedupe,
+			"bytesSaved":   bytesSaved,
+			"tokensSaved":  tokensSaved,
+		})
+	}
+	if report := budget.reportIfApplied(); err == nil && report != nil {
+		runtime.LogInfof(a.ctx, "Token budget of %d applied to %s: %d files cut, %d dropped",
+			report.TokenBudget, rootDir, len(report.Truncated), len(report.Dropped))
+		a.emitGenerationEvent("tokenBudgetApplied", report)
+	}
 	if err != nil {
 		return "", fmt.Errorf("failed to build tree for shotgun: %w", err)
 	}
+	if report := oversized.reportIfApplied(); err == nil && report != nil {
+		runtime.LogWarningf(a.ctx, "Left %d oversized files out of the context of %s (over %d bytes each)",
+			len(report.Files), rootDir, report.MaxFileBlockBytes)
+		a.emitGenerationEvent("oversizedFilesExcluded", report)
+	}
+	a.emitCommentsStripped(rootDir, strip)
+	a.emitRepoMap(rootDir, repoMap)
+	a.logContentCache(rootDir, cache)
 
 	if err := jobCtx.Err(); err != nil { // Check for cancellation before final string operations
 		return "", err
 	}
+	generationDone = true
 
 	// The final output is the tree, a newline, then all concatenated file contents.
 	// If fileContents is empty, we still want the newline after the tree.
 	// If fileContents is not empty, it already ends with a newline, so an extra one might not be desired
 	// depending on how it's structured. Given each <file> block ends with \n, this should be fine.
-	return output.String() + "\n" + strings.TrimRight(fileContents.String(), "\n"), nil
+	if fileContents.Spilled() {
+		runtime.LogInfof(a.ctx, "Generation output for %s was kept on disk (%d bytes)", rootDir, fileContents.Len())
+	}
+	contents, err := fileContents.String()
+	if err != nil {
+		return "", err
+	}
+	header := output.String()
+	sections := map[string]string{
+		sectionPreamble:    header[:treeStart],
+		sectionTree:        header[treeStart:],
+		sectionFiles:       contents,
+		sectionAttachments: attachments,
+	}
+	if a.outputFormat() == outputFormatMarkdown {
+		sections[sectionTree] = markdownFence(strings.TrimRight(header[treeStart:], "\n"), "text")
+	}
+	if a.settings.IncludeEnvironment {
+		environment := environmentBlock(a.collectEnvironment(rootDir))
+		if a.outputFormat() == outputFormatMarkdown {
+			environment = markdownEnvironment(environment)
+		}
+		sections[sectionEnvironment] = environment
+	}
+	return a.assembleContextSections(jobCtx, rootDir, sections), nil
+}
+
+// appendFileContent writes the context block of one file to fileContents
+// Binary, unreadable, and non-UTF-8 files get a placeholder instead of their content
+//
+// Parameters:
+//   - fileContents: Builder receiving the block
+//   - path: Absolute path of the file
+//   - relPath: Path relative to the project root
+//   - maxBytes: Content bytes to keep at most (0 for the whole file, or the MaxFileBytes cap; cut blocks are marked truncated)
+//   - strip: Comment stripping of the generation (nil to keep comments)
+//
+// Returns:
+//   - bool: False if the file could not be checked or read (its block must not be cached)
+func (a *App) appendFileContent(fileContents *strings.Builder, path, relPath string, maxBytes int, strip *commentStripRun) bool {
+	// Ensure forward slashes for the name attribute, consistent with documentation.
+	relPathForwardSlash := filepath.ToSlash(relPath)
+
+	// Detect if file is binary before reading
+	isBinary, err := a.isBinaryFileCached(path)
+	if err != nil {
+		runtime.LogWarningf(a.ctx, "Error detecting binary for %s: %v (skipping)", path, err)
+		return false // Skip this file
+	}
+
+	// Skip binary files in context generation
+	if isBinary {
+		runtime.LogDebugf(a.ctx, "Skipping binary file in context: %s", relPath)
+		// Add a placeholder comment in the file contents section
+		fileContents.WriteString(fmt.Sprintf("<!-- Binary file skipped: %s -->\n", relPathForwardSlash))
+		return true
+	}
+
+	// Read file content
+	content, err := a.projectFS.ReadFile(path)
+	if err != nil {
+		runtime.LogWarningf(a.ctx, "Error reading file %s: %v", path, err)
+		// Include error message in output for debugging
+		fileContents.WriteString(fileBlock(relPathForwardSlash, "", fmt.Sprintf("Error reading file: %v", err), a.fileBlockEscaping()))
+		return false
+	}
+
+	// Validate UTF-8 encoding
+	if !utf8.Valid(content) {
+		runtime.LogWarningf(a.ctx, "File contains invalid UTF-8 (skipping): %s", relPath)
+		fileContents.WriteString(fmt.Sprintf("<!-- File skipped (invalid UTF-8): %s -->\n", relPathForwardSlash))
+		return true
+	}
+
+	attrs := a.churnHeaderAttrs(path) + a.languageHeaderAttr(relPathForwardSlash, content) // Before stripping drops a shebang
+	content = []byte(strip.apply(relPathForwardSlash, a.redactSecretsIfEnabled(relPathForwardSlash, string(content))))
+	// The tighter of the size cap and the budget limit applies; budget cuts keep only the head
+	limit, keepTail := a.settings.MaxFileBytes, a.settings.FileCapKeepTail
+	if maxBytes > 0 && (limit <= 0 || maxBytes < limit) {
+		limit, keepTail = maxBytes, false
+	}
+	if cut, ok := cutFileContent(string(content), limit, keepTail); ok {
+		content = []byte(cut.render(a.settings.LineNumbers))
+		attrs += fmt.Sprintf(` truncated="true" omitted-lines="%d"`, cut.omittedLines)
+	} else if a.settings.LineNumbers {
+		content = []byte(numberLines(string(content)))
+	}
+	if a.settings.LineNumbers {
+		attrs += ` line-numbers="true"`
+	}
+	// Each file block ends with a newline
+	fileContents.WriteString(fileBlock(relPathForwardSlash, attrs, string(content), a.fileBlockEscaping()))
+	return true
 }
 
 // ============================================================================
@@ -1335,6 +2063,8 @@ type Watchman struct {
 	watchedDirs map[string]bool    // Tracks directories explicitly added to fsnotify
 	mu          sync.Mutex         // Protects concurrent access to watcher state
 	cancelFunc  context.CancelFunc // Function to cancel the watcher goroutine
+	selection   map[string]bool    // Relative paths watched in selection-only mode (nil watches the whole project)
+	hardExclude map[string]bool    // Directory names never watched (snapshot of the WatcherExcludeDirs setting)
 
 	// Ignore patterns used during file scanning
 	currentProjectGitignore *gitignore.GitIgnore // Compiled .gitignore patterns for the project
@@ -1374,11 +2104,64 @@ func (a *App) StopFileWatcher() error {
 	return nil
 }
 
+// StartSelectionWatcher is called by JavaScript to watch only the selected files of a project.
+// Only the selected paths and their parent directories are watched, which keeps the watch count
+// and event noise low on large monorepos.
+//
+// Parameters:
+//   - rootDirPath: Project root directory
+//   - relativePaths: Selected files or directories, relative to the root
+//
+// Returns:
+//   - error: Error if the watcher cannot be started
+func (a *App) StartSelectionWatcher(rootDirPath string, relativePaths []string) error {
+	runtime.LogInfof(a.ctx, "StartSelectionWatcher called for: %s (%d paths)", rootDirPath, len(relativePaths))
+	if a.fileWatcher == nil {
+		return fmt.Errorf("file watcher not initialized")
+	}
+	return a.fileWatcher.StartSelection(rootDirPath, relativePaths)
+}
+
+// GetWatchedDirCount returns the number of directories currently registered with the watcher.
+func (a *App) GetWatchedDirCount() int {
+	if a.fileWatcher == nil {
+		return 0
+	}
+	a.fileWatcher.mu.Lock()
+	defer a.fileWatcher.mu.Unlock()
+	return len(a.fileWatcher.watchedDirs)
+}
+
+// Start watches the whole project under newRootDir.
 func (w *Watchman) Start(newRootDir string) error {
+	return w.start(newRootDir, nil)
+}
+
+// StartSelection watches only the given paths (relative to newRootDir) and their parent directories.
+// Selected directories are watched recursively, with the same ignore rules as a full watch.
+func (w *Watchman) StartSelection(newRootDir string, relativePaths []string) error {
+	selection := make(map[string]bool, len(relativePaths))
+	for _, relPath := range relativePaths {
+		if strings.TrimSpace(relPath) == "" {
+			continue
+		}
+		selection[filepath.Clean(filepath.FromSlash(relPath))] = true
+	}
+	return w.start(newRootDir, selection)
+}
+
+func (w *Watchman) start(newRootDir string, selection map[string]bool) error {
 	w.Stop() // Stop any existing watcher
 
+	if !w.app.projectFS.OnDisk(newRootDir) {
+		runtime.LogInfof(w.app.ctx, "Watchman: %s is a read-only project, not watching.", newRootDir)
+		return nil
+	}
+
 	w.mu.Lock()
 	w.rootDir = newRootDir
+	w.selection = selection
+	w.hardExclude = watcherExcludeSet(w.app.settings.WatcherExcludeDirs)
 	if w.rootDir == "" {
 		w.mu.Unlock()
 		runtime.LogInfo(w.app.ctx, "Watchman: Root directory is empty, not starting.")
@@ -1386,6 +2169,11 @@ func (w *Watchman) Start(newRootDir string) error {
 	}
 	w.mu.Unlock()
 
+	// A different project root needs its own .gitignore rules
+	if newRootDir != w.app.projectGitignoreRoot {
+		w.app.reloadProjectGitignore(newRootDir)
+	}
+
 	// Initialize patterns based on App's current state
 	if w.app.useGitignore {
 		w.currentProjectGitignore = w.app.projectGitignore
@@ -1413,10 +2201,15 @@ func (w *Watchman) Start(newRootDir string) error {
 	}
 	w.watchedDirs = make(map[string]bool) // Initialize/clear
 
-	runtime.LogInfof(w.app.ctx, "Watchman: Starting for directory %s", newRootDir)
-	w.addPathsToWatcherRecursive(newRootDir) // Add initial paths
+	if selection != nil {
+		runtime.LogInfof(w.app.ctx, "Watchman: Starting for %d selected paths in %s", len(selection), newRootDir)
+		w.addSelectionToWatcher(newRootDir, selection)
+	} else {
+		runtime.LogInfof(w.app.ctx, "Watchman: Starting for directory %s", newRootDir)
+		w.addPathsToWatcherRecursive(newRootDir) // Add initial paths
+	}
 
-	go w.run(ctx)
+	go w.run(ctx, w.fsWatcher)
 	return nil
 }
 
@@ -1437,15 +2230,16 @@ func (w *Watchman) Stop() {
 		w.fsWatcher = nil
 	}
 	w.rootDir = ""
+	w.selection = nil
 	w.watchedDirs = make(map[string]bool) // Clear watched directories
 }
 
-func (w *Watchman) run(ctx context.Context) {
+// run processes events of fsW until ctx is cancelled
+// The watcher is passed in so a restarted Watchman never has its new watcher closed by an old goroutine.
+func (w *Watchman) run(ctx context.Context, fsW *fsnotify.Watcher) {
 	defer func() {
-		if w.fsWatcher != nil {
-			// This close is a safeguard; Stop() should ideally be called.
-			w.fsWatcher.Close()
-		}
+		// This close is a safeguard; Stop() should ideally be called.
+		fsW.Close()
 		runtime.LogInfo(w.app.ctx, "Watchman: Goroutine stopped.")
 	}()
 
@@ -1463,7 +2257,7 @@ func (w *Watchman) run(ctx context.Context) {
 			runtime.LogInfof(w.app.ctx, "Watchman: Context cancelled, shutting down watcher for %s.", shutdownRootDir)
 			return
 
-		case event, ok := <-w.fsWatcher.Events:
+		case event, ok := <-fsW.Events:
 			if !ok {
 				runtime.LogInfo(w.app.ctx, "Watchman: fsnotify events channel closed.")
 				return
@@ -1475,6 +2269,8 @@ func (w *Watchman) run(ctx context.Context) {
 			// Safely copy ignore patterns
 			projIgn := w.currentProjectGitignore
 			custIgn := w.currentCustomPatterns
+			selection := w.selection
+			hardExclude := w.hardExclude
 			w.mu.Unlock()
 
 			if currentRootDir == "" { // Watcher might have been stopped
@@ -1487,11 +2283,24 @@ func (w *Watchman) run(ctx context.Context) {
 				continue
 			}
 
+			// In selection-only mode, parent directories are watched but only selected paths matter
+			if selection != nil && !selectionContains(selection, relEventPath) {
+				continue
+			}
+
+			// An edited .gitignore changes the rules for the events that follow
+			if filepath.Base(event.Name) == ".gitignore" && event.Op&fsnotify.Chmod == 0 {
+				w.app.reloadProjectGitignore(currentRootDir)
+				w.mu.Lock()
+				projIgn = w.currentProjectGitignore
+				w.mu.Unlock()
+			}
+
 			// Check if the event path is ignored
 			isIgnoredByGit := projIgn != nil && projIgn.MatchesPath(relEventPath)
 			isIgnoredByCustom := custIgn != nil && custIgn.MatchesPath(relEventPath)
 
-			if isIgnoredByGit || isIgnoredByCustom {
+			if isIgnoredByGit || isIgnoredByCustom || pathInExcludedDir(hardExclude, relEventPath) {
 				runtime.LogDebugf(w.app.ctx, "Watchman: Ignoring event for %s as it's an ignored path.", event.Name)
 				continue
 			}
@@ -1499,12 +2308,13 @@ func (w *Watchman) run(ctx context.Context) {
 			// Handle relevant events (excluding Chmod)
 			if event.Op&fsnotify.Chmod == 0 {
 				runtime.LogInfof(w.app.ctx, "Watchman: Relevant change detected for %s in %s", event.Name, currentRootDir)
+				w.app.recordFileChange(currentRootDir, relEventPath, event.Op)
 				w.app.notifyFileChange(currentRootDir)
 			}
 
-			// Dynamic directory watching
+			// Dynamic directory watching (in selection-only mode, events outside the selection were skipped above)
 			if event.Op&fsnotify.Create != 0 {
-				info, statErr := os.Stat(event.Name)
+				info, statErr := w.app.projectFS.Stat(event.Name)
 				if statErr == nil && info.IsDir() {
 					// Check if this new directory itself is ignored before adding
 					isNewDirIgnoredByGit := projIgn != nil && projIgn.MatchesPath(relEventPath)
@@ -1534,7 +2344,7 @@ func (w *Watchman) run(ctx context.Context) {
 				w.mu.Unlock()
 			}
 
-		case err, ok := <-w.fsWatcher.Errors:
+		case err, ok := <-fsW.Errors:
 			if !ok {
 				runtime.LogInfo(w.app.ctx, "Watchman: fsnotify errors channel closed.")
 				return
@@ -1549,6 +2359,7 @@ func (w *Watchman) addPathsToWatcherRecursive(baseDirToAdd string) {
 	fsW := w.fsWatcher
 	projIgn := w.currentProjectGitignore
 	custIgn := w.currentCustomPatterns
+	hardExclude := w.hardExclude
 	overallRoot := w.rootDir
 	w.mu.Unlock()
 
@@ -1557,7 +2368,7 @@ func (w *Watchman) addPathsToWatcherRecursive(baseDirToAdd string) {
 		return
 	}
 
-	filepath.WalkDir(baseDirToAdd, func(path string, d fs.DirEntry, walkErr error) error {
+	w.app.projectFS.WalkDir(baseDirToAdd, func(path string, d fs.DirEntry, walkErr error) error {
 		if walkErr != nil {
 			runtime.LogWarningf(w.app.ctx, "Watchman scan error accessing %s: %v", path, walkErr)
 			if d != nil && d.IsDir() && path != overallRoot { // Changed scanRootDir to overallRoot for clarity
@@ -1585,6 +2396,12 @@ func (w *Watchman) addPathsToWatcherRecursive(baseDirToAdd string) {
 			}
 		}
 
+		// Pathological directories are skipped even when ignore rules are disabled
+		if path != overallRoot && hardExclude[d.Name()] {
+			runtime.LogDebugf(w.app.ctx, "Watchman.addPathsToWatcherRecursive: Skipping hard-excluded directory: %s", path)
+			return filepath.SkipDir
+		}
+
 		isIgnoredByGit := projIgn != nil && projIgn.MatchesPath(relPath)
 		isIgnoredByCustom := custIgn != nil && custIgn.MatchesPath(relPath)
 
@@ -1606,55 +2423,82 @@ func (w *Watchman) addPathsToWatcherRecursive(baseDirToAdd string) {
 	})
 }
 
+// addSelectionToWatcher registers the selected paths with fsnotify
+// Selected directories are watched recursively (skipping ignored and hard-excluded directories);
+// selected paths are also watched through their parent directory.
+func (w *Watchman) addSelectionToWatcher(rootDir string, selection map[string]bool) {
+	w.mu.Lock()
+	fsW := w.fsWatcher
+	w.mu.Unlock()
+
+	if fsW == nil {
+		return
+	}
+
+	dirs := make(map[string]bool)
+	for relPath := range selection {
+		absPath := filepath.Join(rootDir, relPath)
+		if info, err := w.app.projectFS.Stat(absPath); err == nil && info.IsDir() {
+			w.addPathsToWatcherRecursive(absPath)
+		}
+		dirs[filepath.Dir(absPath)] = true
+	}
+
+	for dir := range dirs {
+		if err := fsW.Add(dir); err != nil {
+			runtime.LogWarningf(w.app.ctx, "Watchman.addSelectionToWatcher: Error adding path %s to fsnotify: %v", dir, err)
+			continue
+		}
+		w.mu.Lock()
+		w.watchedDirs[dir] = true
+		w.mu.Unlock()
+	}
+	w.mu.Lock()
+	watched := len(w.watchedDirs)
+	w.mu.Unlock()
+	runtime.LogInfof(w.app.ctx, "Watchman: Watching %d directories for %d selected paths", watched, len(selection))
+}
+
+// selectionContains reports whether relPath is a selected path or lies inside a selected directory
+func selectionContains(selection map[string]bool, relPath string) bool {
+	for p := filepath.Clean(relPath); ; p = filepath.Dir(p) {
+		if selection[p] {
+			return true
+		}
+		if p == "." || p == string(filepath.Separator) || filepath.Dir(p) == p {
+			return false
+		}
+	}
+}
+
 // notifyFileChange is an internal method for the App to emit a Wails event.
 func (a *App) notifyFileChange(rootDir string) {
+	a.invalidateIndexedTree(rootDir)
 	runtime.EventsEmit(a.ctx, "projectFilesChanged", rootDir)
 }
 
 // RefreshIgnoresAndRescan is called when ignore settings change in the App.
+// The watcher is restarted (same root and selection) so the new ignore patterns apply to
+// both the watched directories and incoming events.
 func (w *Watchman) RefreshIgnoresAndRescan() error {
 	w.mu.Lock()
-	if w.rootDir == "" {
-		w.mu.Unlock()
+	currentRootDir := w.rootDir
+	selection := w.selection
+	w.mu.Unlock()
+
+	if currentRootDir == "" {
 		runtime.LogInfo(w.app.ctx, "Watchman.RefreshIgnoresAndRescan: No rootDir, skipping.")
 		return nil
 	}
 	runtime.LogInfo(w.app.ctx, "Watchman.RefreshIgnoresAndRescan: Refreshing ignore patterns and re-scanning.")
 
-	// Update patterns based on App's current state
-	if w.app.useGitignore {
-		w.currentProjectGitignore = w.app.projectGitignore
-	} else {
-		w.currentProjectGitignore = nil
-	}
-	if w.app.useCustomIgnore {
-		w.currentCustomPatterns = w.app.currentCustomIgnorePatterns
-	} else {
-		w.currentCustomPatterns = nil
-	}
-	currentRootDir := w.rootDir
-	defer w.mu.Unlock()
-
-	// Stop existing watcher (closes, clears watchedDirs)
-	if w.cancelFunc != nil {
-		w.cancelFunc()
-	}
-	if w.fsWatcher != nil {
-		w.fsWatcher.Close()
-	}
-	w.watchedDirs = make(map[string]bool)
-
-	// Create new watcher
-	var err error
-	w.fsWatcher, err = fsnotify.NewWatcher()
-	if err != nil {
-		runtime.LogErrorf(w.app.ctx, "Watchman.RefreshIgnoresAndRescan: Error creating new fsnotify watcher: %v", err)
-		return fmt.Errorf("failed to create new fsnotify watcher: %w", err)
+	// start stops the existing watcher and picks up the App's current ignore patterns
+	if err := w.start(currentRootDir, selection); err != nil {
+		runtime.LogErrorf(w.app.ctx, "Watchman.RefreshIgnoresAndRescan: Error restarting watcher: %v", err)
+		return err
 	}
 
-	w.addPathsToWatcherRecursive(currentRootDir) // Add paths with new rules
-	w.app.notifyFileChange(currentRootDir)       // Notify frontend to refresh its view
-
+	w.app.notifyFileChange(currentRootDir) // Notify frontend to refresh its view
 	return nil
 }
 
@@ -1691,6 +2535,17 @@ func (a *App) compileCustomIgnorePatterns() error {
 func (a *App) loadSettings() {
 	// Start with default embedded rules as fallback
 	a.settings.CustomIgnoreRules = defaultCustomIgnoreRulesContent
+	a.settings.PostProcessing = defaultPostProcessingOptions()
+	a.settings.VisibleDotfiles = append([]string{}, defaultVisibleDotfiles...)
+	a.settings.ConfirmFileThreshold = defaultConfirmFileThreshold
+	a.settings.ConfirmTokenThreshold = defaultConfirmTokenThreshold
+	a.settings.WatcherExcludeDirs = append([]string{}, defaultWatcherExcludeDirs...)
+	a.settings.SpillThresholdMB = defaultSpillThresholdMB
+	a.settings.HeapLimitMB = defaultHeapLimitMB
+	a.settings.ContentCacheMB = defaultContentCacheMB
+	a.settings.RedactSecrets = true
+	a.settings.Notifications = defaultNotificationOptions()
+	a.settings.CriticalPathPatterns = append([]string{}, defaultCriticalPathPatterns...)
 
 	// If config path is not set, we can't load from disk
 	if a.configPath == "" {
@@ -1760,7 +2615,9 @@ func (a *App) saveSettings() error {
 	}
 
 	// Convert settings to JSON with pretty formatting
+	a.settingsMu.RLock()
 	data, err := json.MarshalIndent(a.settings, "", "  ")
+	a.settingsMu.RUnlock()
 	if err != nil {
 		runtime.LogErrorf(a.ctx, "Error marshalling settings: %v", err)
 		return err
@@ -1830,14 +2687,39 @@ func (a *App) SetCustomPromptRules(rules string) error {
 	return nil
 }
 
+// GetMaxAutoContinuations returns how many continuation requests are sent automatically
+// when an LLM response is truncated by the output token limit.
+func (a *App) GetMaxAutoContinuations() int {
+	return a.settings.MaxAutoContinuations
+}
+
+// SetMaxAutoContinuations updates the automatic continuation limit and saves it.
+// 0 disables automatic continuation (truncated responses can still be continued manually).
+func (a *App) SetMaxAutoContinuations(limit int) error {
+	if limit < 0 || limit > 10 {
+		return fmt.Errorf("continuation limit must be between 0 and 10, got %d", limit)
+	}
+	a.settings.MaxAutoContinuations = limit
+	if err := a.saveSettings(); err != nil {
+		return fmt.Errorf("failed to save continuation limit: %w", err)
+	}
+	runtime.LogInfof(a.ctx, "Max auto continuations set to: %d", limit)
+	return nil
+}
+
 // SetUseGitignore updates the app's setting for using .gitignore and informs the watcher.
 func (a *App) SetUseGitignore(enabled bool) error {
 	a.useGitignore = enabled
 	runtime.LogInfof(a.ctx, "App setting useGitignore changed to: %v", enabled)
+	// Re-parse the rules so the watcher and generation see the current .gitignore files
 	if a.fileWatcher != nil && a.fileWatcher.rootDir != "" {
+		a.reloadProjectGitignore(a.fileWatcher.rootDir)
 		// Assuming watcher is for the current project if active.
 		return a.fileWatcher.RefreshIgnoresAndRescan()
 	}
+	if a.projectGitignoreRoot != "" {
+		a.reloadProjectGitignore(a.projectGitignoreRoot)
+	}
 	return nil
 }
 
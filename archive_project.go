@@ -0,0 +1,247 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- Archives as Projects ---
+//
+// A .zip, .tar, .tar.gz or .tgz file can be opened as a read-only project without extracting
+// it. The archive is mounted at its own path (see project_fs.go), so tree building, search,
+// statistics and generation read it like a folder. Zip archives are read lazily; tar archives
+// are loaded into memory. Archives that wrap everything in one top-level folder (as GitHub
+// source downloads do) are mounted at that folder.
+
+const maxTarArchiveBytes = 1 << 30 // Uncompressed size limit for tar archives held in memory
+
+// archiveExtensions are the archive types that can be opened as projects
+var archiveExtensions = []string{".zip", ".tar.gz", ".tgz", ".tar"}
+
+// isArchivePath reports whether a file name has a supported archive extension
+func isArchivePath(name string) bool {
+	lower := strings.ToLower(name)
+	for _, ext := range archiveExtensions {
+		if strings.HasSuffix(lower, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// openArchiveFS opens an archive as a filesystem
+//
+// Returns:
+//   - fs.FS: Files of the archive
+//   - io.Closer: Releases the archive (nil if nothing to release)
+//   - error: Error if the archive cannot be read
+func openArchiveFS(archivePath string) (fs.FS, io.Closer, error) {
+	lower := strings.ToLower(archivePath)
+	if strings.HasSuffix(lower, ".zip") {
+		zr, err := zip.OpenReader(archivePath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open zip archive: %w", err)
+		}
+		return zr, zr, nil
+	}
+
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(lower, ".gz") || strings.HasSuffix(lower, ".tgz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to decompress archive: %w", err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+	mfs, err := readTarFS(r, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return mfs, nil, nil
+}
+
+// readTarFS loads the regular files and directories of a tar stream into memory
+// Links, devices, entries escaping the archive root and directories matched by skipDir
+// (when not nil) are skipped.
+func readTarFS(r io.Reader, skipDir func(name string) bool) (*memFS, error) {
+	mfs := newMemFS()
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return mfs, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tar archive: %w", err)
+		}
+
+		name := path.Clean(strings.TrimPrefix(strings.ReplaceAll(header.Name, "\\", "/"), "/"))
+		if name == "." || !fs.ValidPath(name) {
+			continue
+		}
+		if skipDir != nil && tarPathSkipped(name, header.Typeflag == tar.TypeDir, skipDir) {
+			continue
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			mfs.mkdirAll(name, header.ModTime)
+		case tar.TypeReg:
+			if mfs.size+header.Size > maxTarArchiveBytes {
+				return nil, fmt.Errorf("archive is larger than %d MB uncompressed; extract it instead", maxTarArchiveBytes>>20)
+			}
+			data, err := io.ReadAll(tr)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s from archive: %w", name, err)
+			}
+			mfs.addFile(name, data, header.ModTime)
+		}
+	}
+}
+
+// tarPathSkipped reports whether an entry is, or lies inside, a directory matched by skipDir
+func tarPathSkipped(name string, isDir bool, skipDir func(name string) bool) bool {
+	parts := splitSlashPath(name)
+	if !isDir {
+		parts = parts[:len(parts)-1]
+	}
+	for _, part := range parts {
+		if skipDir(part) {
+			return true
+		}
+	}
+	return false
+}
+
+// singleTopLevelDir returns the only entry of an archive root if it is a directory
+func singleTopLevelDir(fsys fs.FS) (string, bool) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil || len(entries) != 1 || !entries[0].IsDir() {
+		return "", false
+	}
+	return entries[0].Name(), true
+}
+
+// mountArchive mounts an archive at its own path as a read-only project
+func mountArchive(archivePath string) error {
+	fsys, closer, err := openArchiveFS(archivePath)
+	if err != nil {
+		return err
+	}
+	source := archivePath
+	if dir, ok := singleTopLevelDir(fsys); ok {
+		sub, err := fs.Sub(fsys, dir)
+		if err == nil {
+			fsys = sub
+			source = archivePath + "!/" + dir
+		}
+	}
+	mountProject(&mountedProject{root: archivePath, fsys: fsys, kind: "archive", source: source, closer: closer})
+	return nil
+}
+
+// resolveArchiveProject validates and mounts an archive, returning its project root
+// An archive that is already mounted is not reloaded.
+func resolveArchiveProject(archivePath string, reload bool) (string, error) {
+	absPath, err := filepath.Abs(archivePath)
+	if err != nil {
+		return "", fmt.Errorf("invalid path %s: %w", archivePath, err)
+	}
+	if resolved, err := filepath.EvalSymlinks(absPath); err == nil {
+		absPath = resolved
+	}
+	if !isArchivePath(absPath) {
+		return "", fmt.Errorf("%s is not a supported archive (%s)", filepath.Base(absPath), strings.Join(archiveExtensions, ", "))
+	}
+	if m, name := mountFor(absPath); !reload && m != nil && name == "." {
+		return absPath, nil
+	}
+	info, err := os.Stat(absPath)
+	if err != nil {
+		return "", fmt.Errorf("cannot access %s: %w", archivePath, err)
+	}
+	if info.IsDir() {
+		return "", fmt.Errorf("%s is a folder, not an archive", filepath.Base(absPath))
+	}
+	if err := mountArchive(absPath); err != nil {
+		return "", err
+	}
+	return absPath, nil
+}
+
+// ============================================================================
+// Archive Project Methods (Wails-bound)
+// ============================================================================
+
+// OpenArchiveProject mounts an archive as a read-only project
+//
+// Parameters:
+//   - archivePath: Path to a .zip, .tar, .tar.gz or .tgz file
+//
+// Returns:
+//   - string: Project root to use with ListFiles and generation (the archive path)
+//   - error: Error if the file is outside the allowed directories or not a readable archive
+func (a *App) OpenArchiveProject(archivePath string) (string, error) {
+	// Checked before mounting, so a refused archive is never read
+	if err := a.checkAllowedPath(archivePath); err != nil {
+		return "", err
+	}
+	rootDir, err := resolveArchiveProject(archivePath, true)
+	if err != nil {
+		return "", fmt.Errorf("failed to open archive: %w", err)
+	}
+	runtime.LogInfof(a.ctx, "Mounted archive %s as a read-only project", rootDir)
+	return rootDir, nil
+}
+
+// SelectArchiveProject opens a file dialog to choose an archive and mounts it as a project
+//
+// Returns:
+//   - string: Project root, or empty string if the dialog was cancelled
+//   - error: Error if the dialog fails, or the archive is not allowed or cannot be read
+func (a *App) SelectArchiveProject() (string, error) {
+	archivePath, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "Select Project Archive",
+		Filters: []runtime.FileFilter{{
+			DisplayName: "Archives (*.zip, *.tar, *.tar.gz, *.tgz)",
+			Pattern:     "*.zip;*.tar;*.tar.gz;*.tgz",
+		}},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to open file dialog: %w", err)
+	}
+	if archivePath == "" {
+		return "", nil
+	}
+	return a.OpenArchiveProject(archivePath)
+}
+
+// CloseArchiveProject unmounts an archive project and releases its memory
+func (a *App) CloseArchiveProject(rootDir string) error {
+	if !a.closeMountedProject(rootDir) {
+		return fmt.Errorf("no archive is open at %s", rootDir)
+	}
+	runtime.LogInfof(a.ctx, "Closed archive project %s", rootDir)
+	return nil
+}
+
+// IsReadOnlyProject reports whether a project root is a mounted (read-only) source
+func (a *App) IsReadOnlyProject(rootDir string) bool {
+	return !a.projectFS.OnDisk(rootDir)
+}
@@ -0,0 +1,25 @@
+//go:build linux
+
+package main
+
+import "syscall"
+
+// ioprio_set constants (linux/ioprio.h)
+const (
+	ioprioWhoProcess = 1
+	ioprioClassIdle  = 3
+	ioprioClassShift = 13
+)
+
+// lowerThreadPriority gives the calling OS thread the lowest CPU priority and the idle I/O
+// class, so it only gets disk time no other process wants
+func lowerThreadPriority() error {
+	tid := syscall.Gettid()
+	if err := syscall.Setpriority(syscall.PRIO_PROCESS, tid, 19); err != nil {
+		return err
+	}
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOPRIO_SET, ioprioWhoProcess, uintptr(tid), ioprioClassIdle<<ioprioClassShift); errno != 0 {
+		return errno
+	}
+	return nil
+}
@@ -0,0 +1,11 @@
+//go:build !linux
+
+package main
+
+import "errors"
+
+// lowerThreadPriority is only implemented on Linux, where thread priorities can be set
+// without affecting the rest of the process
+func lowerThreadPriority() error {
+	return errors.New("thread priorities are not supported on this platform")
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	goruntime "runtime"
+	"sync"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- Background Work Limits ---
+//
+// Indexing a large repository reads every file once. These settings keep that from taking
+// over a laptop:
+//   - WalkWorkers: directories walked in parallel (top-level directories are split up)
+//   - ReadWorkers: files read and counted in parallel
+//   - IndexCPUPercent: share of time each worker works; it sleeps the rest (100 = no pause)
+//   - LowPriority: worker threads run at the lowest CPU priority and, on Linux, in the idle
+//     I/O class, so foreground reads always go first (see background_priority_*.go)
+//
+// Zero values pick defaults from the number of CPUs. Low-priority workers lock their OS
+// thread and never unlock it, so the thread is discarded when the worker ends instead of
+// returning to the scheduler with its lowered priority.
+
+const maxBackgroundWorkers = 64
+
+// BackgroundWorkSettings limits the resources used by background indexing
+type BackgroundWorkSettings struct {
+	WalkWorkers     int  `json:"walkWorkers"`     // Directories walked in parallel (0 = auto)
+	ReadWorkers     int  `json:"readWorkers"`     // Files read in parallel (0 = auto)
+	IndexCPUPercent int  `json:"indexCpuPercent"` // Share of time a worker works, 1-100 (0 = short pauses only)
+	LowPriority     bool `json:"lowPriority"`     // Lowest CPU and I/O priority for worker threads
+}
+
+// effectiveWorkers returns the worker counts to use, applying the defaults
+func (s BackgroundWorkSettings) effectiveWorkers() (walkers, readers int) {
+	walkers, readers = s.WalkWorkers, s.ReadWorkers
+	if walkers <= 0 {
+		walkers = max(1, goruntime.NumCPU()/4)
+	}
+	if readers <= 0 {
+		readers = max(1, goruntime.NumCPU()/2)
+	}
+	return min(walkers, maxBackgroundWorkers), min(readers, maxBackgroundWorkers)
+}
+
+// backgroundWorker paces the work of one background goroutine
+type backgroundWorker struct {
+	cpuPercent int
+	done       int
+}
+
+// startBackgroundWorker prepares the calling goroutine for background work, lowering the
+// priority of its thread if the settings ask for it
+func (a *App) startBackgroundWorker() *backgroundWorker {
+	settings := a.settings.BackgroundWork
+	if settings.LowPriority {
+		goruntime.LockOSThread() // Never unlocked: the thread is discarded with the goroutine
+		if err := lowerThreadPriority(); err != nil {
+			runtime.LogDebugf(a.ctx, "Cannot lower background thread priority: %v", err)
+		}
+	}
+	return &backgroundWorker{cpuPercent: settings.IndexCPUPercent}
+}
+
+// pace pauses after a unit of work that started at start, according to the CPU share
+func (w *backgroundWorker) pace(ctx context.Context, start time.Time) {
+	w.done++
+	var pause time.Duration
+	switch {
+	case w.cpuPercent <= 0 || w.cpuPercent > 100:
+		if w.done%indexYieldEvery == 0 {
+			pause = 5 * time.Millisecond // Leave the disk to foreground work
+		}
+	case w.cpuPercent < 100:
+		pause = time.Since(start) * time.Duration(100-w.cpuPercent) / time.Duration(w.cpuPercent)
+	}
+	if pause <= 0 {
+		return
+	}
+	timer := time.NewTimer(pause)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
+
+// walkProjectFilesParallel sends the files of rootDir that opts does not exclude to files,
+// walking the top-level directories with the configured number of walkers
+// The channel is closed when the walk ends.
+func (a *App) walkProjectFilesParallel(ctx context.Context, rootDir string, opts *treeBuildOptions, files chan<- string) error {
+	defer close(files)
+	walkers, _ := a.settings.BackgroundWork.effectiveWorkers()
+
+	entries, err := a.projectFS.ReadDir(rootDir)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", rootDir, err)
+	}
+	dirs := make(chan string, len(entries))
+	for _, entry := range entries {
+		path := filepath.Join(rootDir, entry.Name())
+		if opts.excludes(entry.Name(), entry.IsDir()) {
+			continue
+		}
+		if entry.IsDir() {
+			dirs <- path
+			continue
+		}
+		select {
+		case files <- path:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	close(dirs)
+
+	var wg sync.WaitGroup
+	var errMu sync.Mutex
+	var walkErr error
+	for i := 0; i < walkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			worker := a.startBackgroundWorker()
+			for dir := range dirs {
+				start := time.Now()
+				err := a.projectFS.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+					if ctxErr := ctx.Err(); ctxErr != nil {
+						return ctxErr
+					}
+					if err != nil {
+						if d != nil && d.IsDir() {
+							return filepath.SkipDir
+						}
+						return nil
+					}
+					relPath, _ := filepath.Rel(rootDir, path)
+					if path != dir && opts.excludes(relPath, d.IsDir()) {
+						if d.IsDir() {
+							return filepath.SkipDir
+						}
+						return nil
+					}
+					if d.IsDir() {
+						return nil
+					}
+					select {
+					case files <- path:
+					case <-ctx.Done():
+						return ctx.Err()
+					}
+					worker.pace(ctx, start)
+					start = time.Now()
+					return nil
+				})
+				if err != nil {
+					errMu.Lock()
+					if walkErr == nil {
+						walkErr = err
+					}
+					errMu.Unlock()
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return walkErr
+}
+
+// ============================================================================
+// Background Work Methods (Wails-bound)
+// ============================================================================
+
+// GetBackgroundWorkSettings returns the limits of background indexing
+func (a *App) GetBackgroundWorkSettings() BackgroundWorkSettings {
+	return a.settings.BackgroundWork
+}
+
+// SetBackgroundWorkSettings updates and saves the limits of background indexing
+// They apply from the next indexing job.
+//
+// Parameters:
+//   - settings: Worker counts (0 = auto), CPU share (0 or 1-100) and low priority
+//
+// Returns:
+//   - error: Error if a value is out of range or the settings cannot be saved
+func (a *App) SetBackgroundWorkSettings(settings BackgroundWorkSettings) error {
+	if settings.WalkWorkers < 0 || settings.WalkWorkers > maxBackgroundWorkers ||
+		settings.ReadWorkers < 0 || settings.ReadWorkers > maxBackgroundWorkers {
+		return fmt.Errorf("worker counts must be between 0 and %d", maxBackgroundWorkers)
+	}
+	if settings.IndexCPUPercent < 0 || settings.IndexCPUPercent > 100 {
+		return fmt.Errorf("CPU percent must be between 0 and 100, got %d", settings.IndexCPUPercent)
+	}
+	a.settings.BackgroundWork = settings
+	if err := a.saveSettings(); err != nil {
+		return fmt.Errorf("failed to save background work settings: %w", err)
+	}
+	walkers, readers := settings.effectiveWorkers()
+	runtime.LogInfof(a.ctx, "Background work set to %d walkers, %d readers, CPU %d%%, low priority %v",
+		walkers, readers, settings.IndexCPUPercent, settings.LowPriority)
+	return nil
+}
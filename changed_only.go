@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// --- Changed-Files-Only Generation ---
+//
+// Iterating with an LLM on a branch usually needs the code that changed, and only the shape of
+// the rest. A generation started with GenerationOptions.ChangedSince set to a git ref (main,
+// HEAD~5, a tag or a commit) includes full content only for files that differ from that ref in
+// the working tree (committed, staged or not, plus untracked files); every other selected file
+// is still listed in the tree but gets no block. Changed files are marked "(changed)" in the
+// tree, and a line before the tree tells the model how the context was cut. Unchanged files
+// take no token budget. GetFilesChangedSince previews the files that would get content.
+
+const changedTreeMark = " (changed)"
+
+// changedOnlyRun restricts one generation to the files changed since a git ref
+type changedOnlyRun struct {
+	ref     string
+	changed map[string]bool // Changed files, by relative OS path
+}
+
+// newChangedOnlyRun returns the changed-files restriction of a generation (nil without a ref)
+func (a *App) newChangedOnlyRun(ctx context.Context, rootDir string, opts GenerationOptions) (*changedOnlyRun, error) {
+	ref := strings.TrimSpace(opts.ChangedSince)
+	if ref == "" {
+		return nil, nil
+	}
+	files, err := a.gitFilesChangedSince(ctx, rootDir, ref)
+	if err != nil {
+		return nil, err
+	}
+	r := &changedOnlyRun{ref: ref, changed: make(map[string]bool, len(files))}
+	for _, f := range files {
+		r.changed[f] = true
+	}
+	return r, nil
+}
+
+// gitFilesChangedSince lists the files of rootDir that differ from a git ref, plus untracked files
+//
+// Parameters:
+//   - ctx: Context for the git commands
+//   - rootDir: Project root directory (inside a git repository)
+//   - ref: Any git revision (branch, tag, commit, HEAD~n)
+//
+// Returns:
+//   - []string: Changed files, as paths relative to rootDir (OS separators)
+//   - error: Error if rootDir is not in a git repository or the ref is unknown
+func (a *App) gitFilesChangedSince(ctx context.Context, rootDir, ref string) ([]string, error) {
+	if strings.HasPrefix(ref, "-") {
+		return nil, fmt.Errorf("invalid git ref: %s", ref)
+	}
+	verify := exec.CommandContext(ctx, "git", "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	verify.Dir = rootDir
+	if err := verify.Run(); err != nil {
+		return nil, fmt.Errorf("unknown git ref %q in %s", ref, rootDir)
+	}
+	return a.gitChangedFiles(ctx, rootDir, ref, time.Time{}), nil
+}
+
+// skips reports whether a file gets no content block (false without a restriction)
+func (r *changedOnlyRun) skips(relPath string) bool {
+	return r != nil && !r.changed[relPath]
+}
+
+// treeMark returns the mark appended to a file's tree line
+func (r *changedOnlyRun) treeMark(relPath string, isDir bool) string {
+	if r == nil || isDir || !r.changed[relPath] {
+		return ""
+	}
+	return changedTreeMark
+}
+
+// preamble returns the line explaining the restriction, written before the tree
+func (r *changedOnlyRun) preamble() string {
+	if r == nil {
+		return ""
+	}
+	return fmt.Sprintf("Only files changed since %s (marked %q in the tree) are included with their content; the other files are listed in the tree only.\n\n", r.ref, strings.TrimSpace(changedTreeMark))
+}
+
+// ============================================================================
+// Changed-Files-Only Methods (Wails-bound)
+// ============================================================================
+
+// GetFilesChangedSince lists the files a generation with ChangedSince set to ref would include
+// with their content (before the selection and ignore rules are applied)
+//
+// Parameters:
+//   - rootDir: Project root directory
+//   - ref: Git ref to compare the working tree with (e.g. main or HEAD~5)
+//
+// Returns:
+//   - []string: Changed files relative to rootDir (forward slashes), sorted
+//   - error: Error if the project is not in a git repository or the ref is unknown
+func (a *App) GetFilesChangedSince(rootDir, ref string) ([]string, error) {
+	if err := a.validateContentRoot(rootDir); err != nil {
+		return nil, err
+	}
+	files, err := a.gitFilesChangedSince(a.ctx, rootDir, strings.TrimSpace(ref))
+	if err != nil {
+		return nil, err
+	}
+	result := make([]string, 0, len(files))
+	for _, f := range files {
+		result = append(result, filepath.ToSlash(f))
+	}
+	sort.Strings(result)
+	return result, nil
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- Changed Files Selection ---
+//
+// After files were edited, the context a user works with may miss them. The selection delta
+// lists the files that changed since the baseline (the newest snapshot or context generation
+// of the project) and are not in the current selection. Changes come from the watcher history
+// and from git: the working tree is compared with the snapshot's revision when it is known,
+// otherwise uncommitted and untracked files count if they were modified after the baseline.
+//
+// The selection is expressed as exclusions, like RequestShotgunContextGeneration. Applying a
+// delta removes the exclusions that hide its files; an excluded directory is replaced by
+// exclusions of its other entries, so only the changed files are added.
+
+// SelectionDelta is the set of changed files missing from a selection
+type SelectionDelta struct {
+	RootDir      string    `json:"rootDir"`      // Project root directory
+	Baseline     string    `json:"baseline"`     // snapshot or generation
+	BaselineName string    `json:"baselineName"` // Name of the snapshot (empty for a generation)
+	Since        time.Time `json:"since"`        // When the baseline was taken
+	Sources      []string  `json:"sources"`      // Where changes were found (watcher, git)
+	Changed      []string  `json:"changed"`      // Files changed since the baseline
+	NotSelected  []string  `json:"notSelected"`  // Changed files the selection excludes
+	Message      string    `json:"message"`      // Summary shown to the user
+}
+
+// gitChangedFiles lists the files of rootDir that differ from a revision, plus untracked files
+// Without a revision, files that differ from HEAD count only if modified after since, as do
+// untracked files. Paths are relative to rootDir. Returns nil outside git.
+func (a *App) gitChangedFiles(ctx context.Context, rootDir, revision string, since time.Time) []string {
+	base := revision
+	if base == "" {
+		base = "HEAD"
+	}
+	diff := exec.CommandContext(ctx, "git", "diff", "--name-only", "--relative", "-z", base)
+	diff.Dir = rootDir
+	diffOut, err := diff.Output()
+	if err != nil {
+		return nil
+	}
+	untracked := exec.CommandContext(ctx, "git", "ls-files", "--others", "--exclude-standard", "-z")
+	untracked.Dir = rootDir
+	untrackedOut, _ := untracked.Output()
+
+	modifiedAfter := func(relPath string) bool {
+		info, err := a.projectFS.Stat(filepath.Join(rootDir, relPath))
+		return err == nil && info.ModTime().After(since)
+	}
+	var files []string
+	for _, p := range strings.Split(string(diffOut), "\x00") {
+		if p != "" && (revision != "" || modifiedAfter(p)) {
+			files = append(files, filepath.FromSlash(p))
+		}
+	}
+	for _, p := range strings.Split(string(untrackedOut), "\x00") {
+		if p != "" && modifiedAfter(p) {
+			files = append(files, filepath.FromSlash(p))
+		}
+	}
+	return files
+}
+
+// changedFilesSince lists the files of rootDir the watcher or git saw change after since
+// (see gitChangedFiles for revision), as relative OS paths, and the sources that found any
+func (a *App) changedFilesSince(rootDir, revision string, since time.Time) ([]string, []string) {
+	seen := make(map[string]bool)
+	var changed []string
+	sources := []string{}
+	add := func(source string, paths []string) {
+		if len(paths) > 0 {
+			sources = append(sources, source)
+		}
+		for _, p := range paths {
+			if !seen[p] {
+				seen[p] = true
+				changed = append(changed, p)
+			}
+		}
+	}
+	var watched []string
+	for _, event := range a.fileChangesSince(rootDir, since) {
+		watched = append(watched, filepath.FromSlash(event.Path))
+	}
+	add("watcher", watched)
+	add("git", a.gitChangedFiles(a.ctx, rootDir, revision, since))
+	return changed, sources
+}
+
+// excludedBySelection reports whether a path or one of its parent directories is excluded
+func excludedBySelection(excluded map[string]bool, relPath string) bool {
+	for p := relPath; p != "." && p != string(filepath.Separator) && p != ""; p = filepath.Dir(p) {
+		if excluded[p] {
+			return true
+		}
+	}
+	return false
+}
+
+// includeInSelection removes the exclusions hiding relPath from excluded
+// An excluded parent directory is replaced by exclusions of its entries not on the path.
+func (a *App) includeInSelection(rootDir string, excluded map[string]bool, relPath string) {
+	parts := strings.Split(relPath, string(filepath.Separator))
+	for i := 1; i <= len(parts); i++ {
+		prefix := filepath.Join(parts[:i]...)
+		if !excluded[prefix] {
+			continue
+		}
+		delete(excluded, prefix)
+		if i == len(parts) {
+			break
+		}
+		entries, err := a.projectFS.ReadDir(filepath.Join(rootDir, prefix))
+		if err != nil {
+			continue
+		}
+		next := filepath.Join(parts[:i+1]...)
+		for _, entry := range entries {
+			if child := filepath.Join(prefix, entry.Name()); child != next {
+				excluded[child] = true
+			}
+		}
+	}
+}
+
+// ============================================================================
+// Changed Files Selection Methods (Wails-bound)
+// ============================================================================
+
+// GetChangedSelectionDelta lists the files changed since the project's newest snapshot or
+// context generation that the current selection excludes
+//
+// Parameters:
+//   - rootDir: Project root directory
+//   - excludedPaths: The current selection, expressed as exclusions
+//
+// Returns:
+//   - SelectionDelta: Changed files and those missing from the selection
+//   - error: Error if the project has no snapshot and no generation to compare with
+func (a *App) GetChangedSelectionDelta(rootDir string, excludedPaths []string) (SelectionDelta, error) {
+	if err := a.validateContentRoot(rootDir); err != nil {
+		return SelectionDelta{}, err
+	}
+	delta := SelectionDelta{RootDir: rootDir, Sources: []string{}, Changed: []string{}, NotSelected: []string{}}
+	revision := ""
+	if snapshots := a.ListContextSnapshots(rootDir); len(snapshots) > 0 {
+		delta.Baseline = "snapshot"
+		delta.BaselineName = snapshots[0].Name
+		delta.Since = snapshots[0].CreatedAt
+		revision = snapshots[0].GitRevision
+	}
+	if last := a.latestGeneration(); last != nil && last.rootDir == rootDir && last.generatedAt.After(delta.Since) {
+		delta.Baseline = "generation"
+		delta.BaselineName = ""
+		delta.Since = last.generatedAt
+		revision = ""
+	}
+	if delta.Baseline == "" {
+		return delta, fmt.Errorf("no snapshot or generated context to compare with")
+	}
+
+	candidates, sources := a.changedFilesSince(rootDir, revision, delta.Since)
+	delta.Sources = append(delta.Sources, sources...)
+
+	excluded := make(map[string]bool, len(excludedPaths))
+	for _, p := range excludedPaths {
+		excluded[p] = true
+	}
+	ignoreOpts := a.newTreeBuildOptions(rootDir, a.compileProjectGitignore(rootDir))
+	for _, relPath := range candidates {
+		// Deleted files and directories cannot be selected
+		info, err := a.projectFS.Stat(filepath.Join(rootDir, relPath))
+		if err != nil || info.IsDir() || ignoreOpts.excludes(relPath, false) {
+			continue
+		}
+		delta.Changed = append(delta.Changed, relPath)
+		if excludedBySelection(excluded, relPath) {
+			delta.NotSelected = append(delta.NotSelected, relPath)
+		}
+	}
+	sort.Strings(delta.Changed)
+	sort.Strings(delta.NotSelected)
+
+	switch len(delta.NotSelected) {
+	case 0:
+		delta.Message = fmt.Sprintf("All %d changed files are in your current selection", len(delta.Changed))
+	case 1:
+		delta.Message = "1 changed file not in your current selection"
+	default:
+		delta.Message = fmt.Sprintf("%d changed files not in your current selection", len(delta.NotSelected))
+	}
+	return delta, nil
+}
+
+// ApplySelectionDelta adds files to a selection
+//
+// Parameters:
+//   - rootDir: Project root directory
+//   - excludedPaths: The current selection, expressed as exclusions
+//   - paths: Files to add (typically SelectionDelta.NotSelected)
+//
+// Returns:
+//   - []string: The new exclusions, sorted
+//   - error: Error if a path is outside the project
+func (a *App) ApplySelectionDelta(rootDir string, excludedPaths []string, paths []string) ([]string, error) {
+	if err := a.validateContentRoot(rootDir); err != nil {
+		return nil, err
+	}
+	excluded := make(map[string]bool, len(excludedPaths))
+	for _, p := range excludedPaths {
+		excluded[p] = true
+	}
+	for _, p := range paths {
+		if _, err := resolveProjectPath(rootDir, filepath.ToSlash(p)); err != nil {
+			return nil, err
+		}
+		a.includeInSelection(rootDir, excluded, filepath.Clean(filepath.FromSlash(p)))
+	}
+
+	result := make([]string, 0, len(excluded))
+	for p := range excluded {
+		result = append(result, p)
+	}
+	sort.Strings(result)
+	runtime.LogInfof(a.ctx, "Added %d changed files to the selection of %s", len(paths), rootDir)
+	return result, nil
+}
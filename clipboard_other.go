@@ -0,0 +1,11 @@
+//go:build !windows
+
+package main
+
+import "errors"
+
+// setNativeClipboardText is only implemented on Windows, where the Win32 clipboard API is used
+// directly; other platforms use the Wails and browser clipboards
+func setNativeClipboardText(text string) error {
+	return errors.New("native clipboard is only available on Windows")
+}
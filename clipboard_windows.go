@@ -0,0 +1,82 @@
+//go:build windows
+
+package main
+
+import (
+	"fmt"
+	goruntime "runtime"
+	"syscall"
+	"time"
+	"unicode/utf16"
+	"unsafe"
+)
+
+// Win32 clipboard constants (winuser.h, winbase.h)
+const (
+	cfUnicodeText = 13
+	gmemMoveable  = 0x0002
+)
+
+var (
+	user32               = syscall.NewLazyDLL("user32.dll")
+	kernel32             = syscall.NewLazyDLL("kernel32.dll")
+	procOpenClipboard    = user32.NewProc("OpenClipboard")
+	procCloseClipboard   = user32.NewProc("CloseClipboard")
+	procEmptyClipboard   = user32.NewProc("EmptyClipboard")
+	procSetClipboardData = user32.NewProc("SetClipboardData")
+	procGlobalAlloc      = kernel32.NewProc("GlobalAlloc")
+	procGlobalFree       = kernel32.NewProc("GlobalFree")
+	procGlobalLock       = kernel32.NewProc("GlobalLock")
+	procGlobalUnlock     = kernel32.NewProc("GlobalUnlock")
+	procRtlMoveMemory    = kernel32.NewProc("RtlMoveMemory")
+)
+
+// openClipboardAttempts is how often opening the clipboard is tried while another app holds it
+const openClipboardAttempts = 20
+
+// setNativeClipboardText puts text on the Windows clipboard as CF_UNICODETEXT
+func setNativeClipboardText(text string) error {
+	data := utf16.Encode([]rune(toCRLF(text)))
+	data = append(data, 0) // CF_UNICODETEXT is NUL-terminated
+	size := uintptr(len(data)) * 2
+
+	// The clipboard is opened by the calling thread and must be closed by it
+	goruntime.LockOSThread()
+	defer goruntime.UnlockOSThread()
+
+	var err error
+	for attempt := 0; attempt < openClipboardAttempts; attempt++ {
+		var ok uintptr
+		if ok, _, err = procOpenClipboard.Call(0); ok != 0 {
+			err = nil
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open clipboard: %w", err)
+	}
+	defer procCloseClipboard.Call()
+
+	if ok, _, err := procEmptyClipboard.Call(); ok == 0 {
+		return fmt.Errorf("failed to empty clipboard: %w", err)
+	}
+	handle, _, err := procGlobalAlloc.Call(gmemMoveable, size)
+	if handle == 0 {
+		return fmt.Errorf("failed to allocate %d bytes for clipboard: %w", size, err)
+	}
+	ptr, _, err := procGlobalLock.Call(handle)
+	if ptr == 0 {
+		procGlobalFree.Call(handle)
+		return fmt.Errorf("failed to lock clipboard memory: %w", err)
+	}
+	procRtlMoveMemory.Call(ptr, uintptr(unsafe.Pointer(&data[0])), size)
+	procGlobalUnlock.Call(handle)
+
+	// On success the clipboard owns the memory
+	if ok, _, err := procSetClipboardData.Call(cfUnicodeText, handle); ok == 0 {
+		procGlobalFree.Call(handle)
+		return fmt.Errorf("failed to set clipboard data: %w", err)
+	}
+	return nil
+}
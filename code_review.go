@@ -0,0 +1,405 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- Per-File Code Review ---
+//
+// A code_review job reviews each selected file, or each hunk of a diff, in its own LLM request
+// with a review template asking for a JSON array of comments. The requests run as one
+// prompt_batch child job (see prompt_batch.go), so they share its rate limiting and cost
+// reporting. The answers are parsed into {path, line, severity, message} comments and merged
+// into one ReviewReport, the job's result.
+
+const maxReviewFileBytes = 200 * 1024 // Files above this size are not sent for review
+
+// reviewSeverities are the comment severities, most severe first
+var reviewSeverities = []string{"critical", "error", "warning", "info"}
+
+// reviewSeverityAliases maps other severity words models use to reviewSeverities
+var reviewSeverityAliases = map[string]string{
+	"blocker": "critical", "high": "error", "major": "error", "bug": "error",
+	"medium": "warning", "minor": "warning", "low": "info", "nit": "info", "suggestion": "info",
+}
+
+// reviewHunkHeaderRegex parses the line ranges of a unified diff hunk header
+var reviewHunkHeaderRegex = regexp.MustCompile(`^@@ -\d+(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// reviewPromptTemplate is the instruction sent with every reviewed file or hunk
+const reviewPromptTemplate = `You are reviewing one %s of a code base. Report only real problems: bugs, security issues, missing error handling, race conditions, performance problems and clear maintainability issues. Do not comment on formatting.
+%s
+Answer with a JSON array only, without any other text. Each element is {"line": <line number in the new version of the file>, "severity": "critical" | "error" | "warning" | "info", "message": "<one or two sentences>"}. Answer [] if there is nothing to report.
+
+File: %s
+%s`
+
+// ReviewOptions configures StartCodeReview
+type ReviewOptions struct {
+	Provider          string   `json:"provider"`          // LLM provider (google, openai, anthropic, custom)
+	APIKey            string   `json:"apiKey"`            // API key for the provider
+	Model             string   `json:"model"`             // Model name (empty for provider default)
+	BaseURL           string   `json:"baseURL"`           // Base URL for the custom provider
+	RootDir           string   `json:"rootDir"`           // Project root the files are relative to
+	Files             []string `json:"files"`             // Files to review, relative to RootDir (ignored when Diff is set)
+	Diff              string   `json:"diff"`              // Unified diff whose hunks are reviewed one by one
+	Instructions      string   `json:"instructions"`      // Extra review focus added to the template (optional)
+	Concurrency       int      `json:"concurrency"`       // Reviews in flight at once (0 for the batch default)
+	RequestsPerMinute int      `json:"requestsPerMinute"` // Maximum requests started per minute (0 = unlimited)
+}
+
+// ReviewComment is one finding of a review
+type ReviewComment struct {
+	Path     string `json:"path"`     // File path relative to the project root (forward slashes)
+	Line     int    `json:"line"`     // Line in the new version of the file (0 if not tied to a line)
+	Severity string `json:"severity"` // critical, error, warning or info
+	Message  string `json:"message"`  // What is wrong and why
+}
+
+// ReviewFailure is a file or hunk that could not be reviewed
+type ReviewFailure struct {
+	Unit  string `json:"unit"`  // File path, or path and hunk range
+	Error string `json:"error"` // Why it was not reviewed
+}
+
+// ReviewReport is the consolidated result of a code_review job
+type ReviewReport struct {
+	Units      int             `json:"units"`      // Files or hunks to review
+	Reviewed   int             `json:"reviewed"`   // Files or hunks whose review was parsed
+	Comments   []ReviewComment `json:"comments"`   // Findings, most severe first, then by path and line
+	Counts     map[string]int  `json:"counts"`     // Number of comments per severity
+	Failures   []ReviewFailure `json:"failures"`   // Files or hunks not reviewed
+	TokensUsed int             `json:"tokensUsed"` // Tokens used by all review requests
+	Cost       float64         `json:"cost"`       // Estimated cost of all review requests in USD
+}
+
+// reviewUnit is one file or hunk sent for review
+type reviewUnit struct {
+	path  string // Relative path (forward slashes)
+	label string // Path, or path and hunk range, for failures
+	kind  string // "file" or "diff hunk", for the template
+	body  string // Numbered file content or hunk
+}
+
+// buildFileReviewUnits reads the files to review, numbering their lines
+func (a *App) buildFileReviewUnits(rootDir string, files []string) ([]reviewUnit, []ReviewFailure) {
+	var units []reviewUnit
+	var failures []ReviewFailure
+	for _, relPath := range files {
+		relPath = filepath.ToSlash(relPath)
+		unit, err := a.fileReviewUnit(rootDir, relPath)
+		if err != nil {
+			failures = append(failures, ReviewFailure{Unit: relPath, Error: err.Error()})
+			continue
+		}
+		units = append(units, unit)
+	}
+	return units, failures
+}
+
+// fileReviewUnit reads one text file to review
+func (a *App) fileReviewUnit(rootDir, relPath string) (reviewUnit, error) {
+	absPath, err := resolveProjectPath(rootDir, relPath)
+	if err != nil {
+		return reviewUnit{}, err
+	}
+	if binary, err := a.isBinaryFile(absPath); err != nil {
+		return reviewUnit{}, err
+	} else if binary {
+		return reviewUnit{}, fmt.Errorf("binary file")
+	}
+	content, err := a.projectFS.ReadFile(absPath)
+	if err != nil {
+		return reviewUnit{}, err
+	}
+	if len(content) > maxReviewFileBytes {
+		return reviewUnit{}, fmt.Errorf("file is larger than %d KB", maxReviewFileBytes/1024)
+	}
+	return reviewUnit{path: relPath, label: relPath, kind: "file", body: numberLines(strings.TrimRight(string(content), "\n") + "\n")}, nil
+}
+
+// buildDiffReviewUnits splits a unified diff into one unit per hunk
+// Hunks end when their line counts are used up, so removed lines starting with "--" are not
+// mistaken for file headers.
+func buildDiffReviewUnits(diff string) []reviewUnit {
+	var units []reviewUnit
+	path := ""
+	var hunk *strings.Builder
+	var hunkLabel string
+	oldLeft, newLeft := 0, 0
+	flush := func() {
+		if hunk != nil {
+			units = append(units, reviewUnit{path: path, label: hunkLabel, kind: "diff hunk", body: hunk.String()})
+			hunk = nil
+		}
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(diff, "\r\n", "\n"), "\n") {
+		if hunk != nil && (oldLeft > 0 || newLeft > 0) {
+			hunk.WriteString(line + "\n")
+			switch {
+			case strings.HasPrefix(line, "-"):
+				oldLeft--
+			case strings.HasPrefix(line, "+"):
+				newLeft--
+			case strings.HasPrefix(line, "\\"): // "\ No newline at end of file"
+			default:
+				oldLeft--
+				newLeft--
+			}
+			continue
+		}
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			flush()
+			path = strings.TrimPrefix(getPathFromDiffHeader(line), "a/")
+		case strings.HasPrefix(line, "+++ "):
+			flush()
+			if target := strings.SplitN(line[4:], "\t", 2)[0]; target != "/dev/null" {
+				path = strings.TrimPrefix(target, "b/")
+			}
+		case strings.HasPrefix(line, "@@"):
+			flush()
+			m := reviewHunkHeaderRegex.FindStringSubmatch(line)
+			if m == nil {
+				continue
+			}
+			oldLeft, newLeft = 1, 1
+			if m[1] != "" {
+				oldLeft, _ = strconv.Atoi(m[1])
+			}
+			if m[3] != "" {
+				newLeft, _ = strconv.Atoi(m[3])
+			}
+			start, _ := strconv.Atoi(m[2])
+			hunkLabel = fmt.Sprintf("%s:%d", path, start)
+			if newLeft > 1 {
+				hunkLabel += fmt.Sprintf("-%d", start+newLeft-1)
+			}
+			hunk = &strings.Builder{}
+			hunk.WriteString(line + "\n")
+		case hunk != nil && strings.HasPrefix(line, "\\"):
+			hunk.WriteString(line + "\n")
+		}
+	}
+	flush()
+	return units
+}
+
+// reviewPrompt fills the review template for one unit
+func reviewPrompt(unit reviewUnit, instructions string) string {
+	if instructions = strings.TrimSpace(instructions); instructions != "" {
+		instructions = "Focus: " + instructions + "\n"
+	}
+	fence := "```"
+	if unit.kind == "diff hunk" {
+		fence += "diff"
+	}
+	return fmt.Sprintf(reviewPromptTemplate, unit.kind, instructions, unit.path, fence+"\n"+unit.body+"```")
+}
+
+// parseReviewComments extracts the JSON array of comments from a review answer
+func parseReviewComments(answer, path string) ([]ReviewComment, error) {
+	start, end := strings.Index(answer, "["), strings.LastIndex(answer, "]")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("answer contains no JSON array")
+	}
+	var raw []struct {
+		Line     interface{} `json:"line"`
+		Severity string      `json:"severity"`
+		Message  string      `json:"message"`
+	}
+	if err := json.Unmarshal([]byte(answer[start:end+1]), &raw); err != nil {
+		return nil, fmt.Errorf("invalid comment array: %w", err)
+	}
+
+	comments := make([]ReviewComment, 0, len(raw))
+	for _, r := range raw {
+		message := strings.TrimSpace(r.Message)
+		if message == "" {
+			continue
+		}
+		line := 0
+		switch v := r.Line.(type) {
+		case float64:
+			line = int(v)
+		case string:
+			line, _ = strconv.Atoi(strings.TrimSpace(v))
+		}
+		if line < 0 {
+			line = 0
+		}
+		comments = append(comments, ReviewComment{Path: path, Line: line, Severity: normalizeSeverity(r.Severity), Message: message})
+	}
+	return comments, nil
+}
+
+// normalizeSeverity maps a severity word to one of reviewSeverities ("info" if unknown)
+func normalizeSeverity(severity string) string {
+	severity = strings.ToLower(strings.TrimSpace(severity))
+	for _, known := range reviewSeverities {
+		if severity == known {
+			return known
+		}
+	}
+	if alias, ok := reviewSeverityAliases[severity]; ok {
+		return alias
+	}
+	return "info"
+}
+
+// severityRank orders severities, most severe first
+func severityRank(severity string) int {
+	for i, known := range reviewSeverities {
+		if severity == known {
+			return i
+		}
+	}
+	return len(reviewSeverities)
+}
+
+// executeCodeReviewJob implements the code_review job type
+func (a *App) executeCodeReviewJob(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var opts ReviewOptions
+	if err := json.Unmarshal(params, &opts); err != nil {
+		return nil, fmt.Errorf("invalid code_review parameters: %w", err)
+	}
+
+	var units []reviewUnit
+	report := &ReviewReport{Comments: []ReviewComment{}, Counts: map[string]int{}, Failures: []ReviewFailure{}}
+	if strings.TrimSpace(opts.Diff) != "" {
+		units = buildDiffReviewUnits(a.postProcess(opts.Diff))
+	} else {
+		if err := a.validateContentRoot(opts.RootDir); err != nil {
+			return nil, err
+		}
+		var failures []ReviewFailure
+		units, failures = a.buildFileReviewUnits(opts.RootDir, opts.Files)
+		report.Failures = append(report.Failures, failures...)
+	}
+	report.Units = len(units) + len(report.Failures)
+	if len(units) == 0 {
+		return report, nil
+	}
+
+	prompts := make([]string, len(units))
+	for i, unit := range units {
+		prompts[i] = reviewPrompt(unit, opts.Instructions)
+	}
+	batchID, err := a.jobQueue.Enqueue("prompt_batch", PromptBatchOptions{
+		Provider: opts.Provider, APIKey: opts.APIKey, Model: opts.Model, BaseURL: opts.BaseURL,
+		Prompts: prompts, Concurrency: opts.Concurrency, RequestsPerMinute: opts.RequestsPerMinute,
+	})
+	if err != nil {
+		return nil, err
+	}
+	a.jobQueue.setJobParent(batchID, jobIDFromContext(ctx))
+	batchJob, err := a.jobQueue.waitForJob(ctx, batchID)
+	if err != nil {
+		a.jobQueue.CancelJob(batchID)
+		return nil, err
+	}
+	batch, ok := batchJob.Result.(*PromptBatchResult)
+	if !ok {
+		return nil, fmt.Errorf("review batch %s: %s", batchJob.Status, batchJob.Error)
+	}
+
+	report.TokensUsed, report.Cost = batch.TokensUsed, batch.Cost
+	for _, item := range batch.Items {
+		unit := units[item.Index]
+		if item.Status != "completed" {
+			report.Failures = append(report.Failures, ReviewFailure{Unit: unit.label, Error: item.Error})
+			continue
+		}
+		comments, err := parseReviewComments(item.Content, unit.path)
+		if err != nil {
+			report.Failures = append(report.Failures, ReviewFailure{Unit: unit.label, Error: err.Error()})
+			continue
+		}
+		report.Reviewed++
+		report.Comments = append(report.Comments, comments...)
+	}
+	sort.SliceStable(report.Comments, func(i, j int) bool {
+		ci, cj := report.Comments[i], report.Comments[j]
+		if ri, rj := severityRank(ci.Severity), severityRank(cj.Severity); ri != rj {
+			return ri < rj
+		}
+		if ci.Path != cj.Path {
+			return ci.Path < cj.Path
+		}
+		return ci.Line < cj.Line
+	})
+	for _, comment := range report.Comments {
+		report.Counts[comment.Severity]++
+	}
+
+	runtime.LogInfof(a.ctx, "Code review: %d of %d units reviewed, %d comments", report.Reviewed, report.Units, len(report.Comments))
+	runtime.EventsEmit(a.ctx, "codeReviewCompleted", map[string]interface{}{
+		"jobId":  jobIDFromContext(ctx),
+		"report": report,
+	})
+	return report, nil
+}
+
+// ============================================================================
+// Code Review Methods (Wails-bound)
+// ============================================================================
+
+// StartCodeReview reviews files or diff hunks one by one in the background
+// The consolidated report is emitted as "codeReviewCompleted" and returned by GetReviewReport.
+//
+// Parameters:
+//   - opts: Provider settings, and either files of a project or a unified diff
+//
+// Returns:
+//   - string: Job ID of the code_review job
+//   - error: Error if there is nothing to review or the job cannot be enqueued
+func (a *App) StartCodeReview(opts ReviewOptions) (string, error) {
+	if a.jobQueue == nil {
+		return "", fmt.Errorf("job queue not initialized")
+	}
+	if strings.TrimSpace(opts.Diff) == "" {
+		if len(opts.Files) == 0 {
+			return "", fmt.Errorf("no files or diff to review")
+		}
+		if err := a.validateContentRoot(opts.RootDir); err != nil {
+			return "", err
+		}
+	}
+	return a.jobQueue.Enqueue("code_review", opts)
+}
+
+// GetReviewReport returns the report of a finished code_review job
+//
+// Parameters:
+//   - jobID: ID returned by StartCodeReview
+//
+// Returns:
+//   - *ReviewReport: Consolidated comments, counts and failures
+//   - error: Error if the job is unknown, not a review, or has not completed
+func (a *App) GetReviewReport(jobID string) (*ReviewReport, error) {
+	if a.jobQueue == nil {
+		return nil, fmt.Errorf("job queue not initialized")
+	}
+	job, ok := a.jobQueue.getJob(jobID)
+	if !ok {
+		return nil, fmt.Errorf("job not found: %s", jobID)
+	}
+	if job.Type != "code_review" {
+		return nil, fmt.Errorf("job %s is not a code review", jobID)
+	}
+	report, ok := job.Result.(*ReviewReport)
+	if !ok {
+		return nil, fmt.Errorf("review %s has not completed (status: %s)", jobID, job.Status)
+	}
+	return report, nil
+}
@@ -0,0 +1,291 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- Comment Stripping ---
+//
+// A generation started with GenerationOptions.StripComments removes comments and blank lines
+// from the files of supported languages before they are included, to shrink token usage:
+//   - C-style languages (Go, JavaScript, TypeScript, Java, Kotlin, Swift, Scala, Dart, Rust,
+//     C, C++, C#, Objective-C): // line comments and /* */ block comments
+//   - Python: # comments (docstrings are kept; removing one can leave an empty body)
+//
+// A small scanner tracks string literals so comment markers inside strings stay, and lines
+// inside multi-line strings are never dropped. Directives that only look like comments are
+// kept (//go:build, //go:embed, // +build, /// <reference>, a shebang, a coding line). Files
+// in other languages pass unchanged. The tokens saved are emitted as "commentsStripped";
+// EstimateCommentStripping computes them for a selection up front.
+
+// commentSyntax describes the comments and string literals of a language
+type commentSyntax struct {
+	line      string // Line comment marker
+	block     bool   // True if /* */ block comments exist
+	quotes    string // Quote characters of single-line strings (backslash escapes)
+	multiline string // Quote characters of strings that may span lines (backslash escapes)
+	raw       string // Quote characters of strings that may span lines, without escapes
+	triple    string // Quote characters that open triple-quoted multi-line strings
+}
+
+var (
+	cStyleComments          = commentSyntax{line: "//", block: true, quotes: `"'`}
+	tripleQuoteStyle        = commentSyntax{line: "//", block: true, quotes: `"'`, triple: `"`}
+	commentSyntaxByLanguage = map[string]commentSyntax{
+		"Go":          {line: "//", block: true, quotes: `"'`, raw: "`"},
+		"JavaScript":  {line: "//", block: true, quotes: `"'`, multiline: "`"},
+		"TypeScript":  {line: "//", block: true, quotes: `"'`, multiline: "`"},
+		"Rust":        {line: "//", block: true, multiline: `"`},
+		"Python":      {line: "#", quotes: `"'`, triple: `"'`},
+		"Dart":        {line: "//", block: true, quotes: `"'`, triple: `"'`},
+		"Java":        tripleQuoteStyle,
+		"Kotlin":      tripleQuoteStyle,
+		"Swift":       tripleQuoteStyle,
+		"Scala":       tripleQuoteStyle,
+		"C":           cStyleComments,
+		"C++":         cStyleComments,
+		"C#":          cStyleComments,
+		"Objective-C": cStyleComments,
+	}
+)
+
+// keptCommentPrefixes are comments that carry meaning for tools and stay in stripped files
+var keptCommentPrefixes = []string{"//go:", "// +build", "/// <reference", "# -*-", "# vim:"}
+
+// keepComment reports whether a comment (from its marker to the end of the line) is kept
+func keepComment(comment string, lineIndex int) bool {
+	if lineIndex == 0 && strings.HasPrefix(comment, "#!") {
+		return true
+	}
+	for _, prefix := range keptCommentPrefixes {
+		if strings.HasPrefix(comment, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// stripComments removes the comments, blank lines and trailing whitespace of source code
+func stripComments(content string, syntax commentSyntax) string {
+	lines := strings.Split(content, "\n")
+	kept := make([]string, 0, len(lines))
+	inBlock := false
+	quote, escapes, multiline := "", false, false
+
+	for n, line := range lines {
+		cr := strings.HasSuffix(line, "\r")
+		line = strings.TrimSuffix(line, "\r")
+		startedInString := quote != ""
+		var out strings.Builder
+		for i := 0; i < len(line); {
+			rest := line[i:]
+			switch {
+			case inBlock:
+				end := strings.Index(rest, "*/")
+				if end < 0 {
+					i = len(line)
+				} else {
+					i, inBlock = i+end+2, false
+				}
+			case quote != "":
+				switch {
+				case escapes && rest[0] == '\\' && len(rest) > 1:
+					out.WriteString(rest[:2])
+					i += 2
+				case strings.HasPrefix(rest, quote):
+					out.WriteString(quote)
+					i, quote = i+len(quote), ""
+				default:
+					out.WriteByte(rest[0])
+					i++
+				}
+			case strings.HasPrefix(rest, syntax.line):
+				if keepComment(rest, n) {
+					out.WriteString(rest)
+				}
+				i = len(line)
+			case syntax.block && strings.HasPrefix(rest, "/*"):
+				i, inBlock = i+2, true
+			default:
+				c := rest[0]
+				width := 1
+				switch {
+				case c == '\\' && syntax.line == "//" && len(rest) > 1:
+					width = 2 // Escapes outside strings (regular expression literals)
+				case strings.IndexByte(syntax.triple, c) >= 0 && strings.HasPrefix(rest, strings.Repeat(rest[:1], 3)):
+					width = 3
+					quote, escapes, multiline = rest[:3], true, true
+				case strings.IndexByte(syntax.raw, c) >= 0:
+					quote, escapes, multiline = rest[:1], false, true
+				case strings.IndexByte(syntax.multiline, c) >= 0:
+					quote, escapes, multiline = rest[:1], true, true
+				case strings.IndexByte(syntax.quotes, c) >= 0:
+					quote, escapes, multiline = rest[:1], true, false
+				}
+				out.WriteString(rest[:width])
+				i += width
+			}
+		}
+		if quote != "" && !multiline {
+			quote = "" // Unterminated single-line string
+		}
+
+		text := out.String()
+		if quote == "" {
+			text = strings.TrimRight(text, " \t")
+		}
+		if strings.TrimSpace(text) == "" && !startedInString && quote == "" {
+			continue
+		}
+		if cr {
+			text += "\r"
+		}
+		kept = append(kept, text)
+	}
+
+	stripped := strings.Join(kept, "\n")
+	if stripped != "" && strings.HasSuffix(content, "\n") {
+		stripped += "\n"
+	}
+	return stripped
+}
+
+// commentStripRun strips comments during one generation and counts what it saved
+type commentStripRun struct {
+	files      int // Files that got shorter
+	savedBytes int // Bytes removed from all files
+}
+
+// newCommentStripRun returns the stripping state of a generation (nil if it does not strip)
+func newCommentStripRun(opts GenerationOptions) *commentStripRun {
+	if !opts.StripComments {
+		return nil
+	}
+	return &commentStripRun{}
+}
+
+// apply strips the comments of a file if its language is supported
+func (r *commentStripRun) apply(relPath, content string) string {
+	if r == nil {
+		return content
+	}
+	syntax, ok := commentSyntaxByLanguage[detectContentLanguage(relPath, []byte(content[:min(len(content), languageSniffBytes)]))]
+	if !ok {
+		return content
+	}
+	stripped := stripComments(content, syntax)
+	r.count(len(content) - len(stripped))
+	return stripped
+}
+
+// count records the bytes stripping removed from one file (also for cached blocks)
+func (r *commentStripRun) count(savedBytes int) {
+	if r != nil && savedBytes > 0 {
+		r.files++
+		r.savedBytes += savedBytes
+	}
+}
+
+// saved returns the bytes removed so far (0 without stripping)
+func (r *commentStripRun) saved() int {
+	if r == nil {
+		return 0
+	}
+	return r.savedBytes
+}
+
+// emitCommentsStripped reports the savings of a generation as "commentsStripped"
+func (a *App) emitCommentsStripped(rootDir string, r *commentStripRun) {
+	if r == nil {
+		return
+	}
+	runtime.LogInfof(a.ctx, "Stripped comments from %d files in %s: ~%d tokens saved", r.files, rootDir, r.savedBytes/4)
+	a.emitGenerationEvent("commentsStripped", map[string]interface{}{
+		"rootDir":     rootDir,
+		"files":       r.files,
+		"tokensSaved": r.savedBytes / 4, // EstimateTokens counts 4 bytes per token
+	})
+}
+
+// CommentStripEstimate is the effect comment stripping would have on a selection
+type CommentStripEstimate struct {
+	RootDir      string `json:"rootDir"`      // Project root directory
+	Files        int    `json:"files"`        // Selected files in a supported language
+	TokensBefore int    `json:"tokensBefore"` // Estimated tokens of those files
+	TokensAfter  int    `json:"tokensAfter"`  // Estimated tokens after stripping
+	TokensSaved  int    `json:"tokensSaved"`  // Difference
+}
+
+// ============================================================================
+// Comment Stripping Methods (Wails-bound)
+// ============================================================================
+
+// EstimateCommentStripping computes how many tokens stripping comments would save on a selection
+//
+// Parameters:
+//   - rootDir: Project root directory
+//   - excludedPaths: The selection, expressed as exclusions
+//
+// Returns:
+//   - CommentStripEstimate: Files affected and tokens before and after stripping
+//   - error: Error if the project cannot be walked
+func (a *App) EstimateCommentStripping(rootDir string, excludedPaths []string) (CommentStripEstimate, error) {
+	if err := a.validateContentRoot(rootDir); err != nil {
+		return CommentStripEstimate{}, err
+	}
+	entries, _, err := a.walkSelectionEntries(rootDir)
+	if err != nil {
+		return CommentStripEstimate{}, err
+	}
+	excluded := make(map[string]bool, len(excludedPaths))
+	for _, p := range excludedPaths {
+		excluded[p] = true
+	}
+
+	estimate := CommentStripEstimate{RootDir: rootDir}
+	before, after := 0, 0
+	for _, entry := range entries {
+		if entry.isDir || excludedBySelection(excluded, filepath.FromSlash(entry.relPath)) {
+			continue
+		}
+		syntax, ok := commentSyntaxByLanguage[detectLanguage(entry.relPath)]
+		if !ok {
+			continue
+		}
+		if isBinary, err := a.isBinaryFileCached(entry.absPath); err != nil || isBinary {
+			continue
+		}
+		content, err := a.projectFS.ReadFile(entry.absPath)
+		if err != nil || !utf8.Valid(content) {
+			continue
+		}
+		estimate.Files++
+		before += len(content)
+		after += len(stripComments(string(content), syntax))
+	}
+	estimate.TokensBefore = before / 4 // EstimateTokens counts 4 bytes per token
+	estimate.TokensAfter = after / 4
+	estimate.TokensSaved = estimate.TokensBefore - estimate.TokensAfter
+	return estimate, nil
+}
+
+// RequestShotgunContextGenerationWithOptions starts a generation with per-generation options
+// Results and the large-project confirmation are emitted like RequestShotgunContextGeneration.
+//
+// Parameters:
+//   - rootDir: Project root directory
+//   - excludedPaths: The selection, expressed as exclusions
+//   - opts: Options of this generation (e.g. StripComments)
+func (a *App) RequestShotgunContextGenerationWithOptions(rootDir string, excludedPaths []string, opts GenerationOptions) {
+	a.requestShotgunContextGeneration(rootDir, excludedPaths, true, opts)
+}
+
+// ConfirmShotgunContextGenerationWithOptions starts a confirmed generation with the options
+// of its "shotgunContextConfirmationRequired" event, skipping the large-project check
+func (a *App) ConfirmShotgunContextGenerationWithOptions(rootDir string, excludedPaths []string, opts GenerationOptions) {
+	a.requestShotgunContextGeneration(rootDir, excludedPaths, false, opts)
+}
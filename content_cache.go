@@ -0,0 +1,179 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- Incremental Generation Cache ---
+//
+// Rendering a file block means detecting binary content, reading the file, validating UTF-8,
+// stripping comments, numbering lines and escaping, and in a large monorepo that is most of the
+// time a regeneration takes. Rendered blocks are therefore kept in memory, keyed by the file's
+// absolute path and checked against its size and modification time: regenerating after a few
+// edits only re-reads the changed files and reuses the blocks of all others.
+//
+// A cached block is only reused when it was rendered the same way (same content limit, size
+// cap, comment stripping, secrets redaction, line numbers, escaping, language and churn
+// attributes). Files modified in the last couple of seconds are not cached, since a second edit
+// within the file system's time granularity would keep their modification time. The cache holds
+// at most ContentCacheMB megabytes of blocks (0 turns it off); when full, arbitrary blocks are
+// evicted. It is not saved to disk.
+
+const (
+	defaultContentCacheMB = 128
+	contentCacheMinAge    = 2 * time.Second // Files modified more recently are not cached
+)
+
+// cachedBlock is the rendered block of a file
+type cachedBlock struct {
+	size       int64
+	modTime    int64  // Unix nanoseconds
+	stamp      string // How the block was rendered (see blockStamp)
+	block      string
+	savedBytes int // Bytes removed by comment stripping
+}
+
+// contentCacheRun counts the cache hits of one generation
+type contentCacheRun struct {
+	hits   int
+	misses int
+}
+
+// blockStamp describes how a file block is rendered; cached blocks with another stamp are stale
+func (a *App) blockStamp(path string, maxBytes int, strip *commentStripRun) string {
+	return fmt.Sprintf("%d|%d|%v|%v|%v|%v|%v|%s|%s", maxBytes, a.settings.MaxFileBytes, a.settings.FileCapKeepTail,
+		strip != nil, a.settings.RedactSecrets, a.settings.LineNumbers, a.settings.LanguageInFileHeaders, a.fileBlockEscaping(), a.churnHeaderAttrs(path))
+}
+
+// appendCachedFileContent writes the block of a file like appendFileContent, reusing the
+// cached block if the file did not change since it was rendered
+//
+// Parameters:
+//   - fileContents: Builder receiving the block
+//   - path: Absolute path of the file
+//   - relPath: Path relative to the project root
+//   - maxBytes: Content bytes to keep at most (0 for the whole file)
+//   - strip: Comment stripping of the generation (nil to keep comments)
+//   - run: Hit counters of the generation
+func (a *App) appendCachedFileContent(fileContents *strings.Builder, path, relPath string, maxBytes int, strip *commentStripRun, run *contentCacheRun) {
+	limit := int64(a.settings.ContentCacheMB) << 20
+	info, err := a.projectFS.Stat(path)
+	if limit <= 0 || err != nil {
+		a.appendFileContent(fileContents, path, relPath, maxBytes, strip)
+		return
+	}
+	stamp := a.blockStamp(path, maxBytes, strip)
+
+	a.contentCacheMu.Lock()
+	entry, ok := a.contentCache[path]
+	a.contentCacheMu.Unlock()
+	if ok && entry.size == info.Size() && entry.modTime == info.ModTime().UnixNano() && entry.stamp == stamp {
+		fileContents.WriteString(entry.block)
+		strip.count(entry.savedBytes)
+		run.hits++
+		return
+	}
+	run.misses++
+
+	var block strings.Builder
+	savedBefore := strip.saved()
+	if !a.appendFileContent(&block, path, relPath, maxBytes, strip) || time.Since(info.ModTime()) < contentCacheMinAge {
+		fileContents.WriteString(block.String())
+		return
+	}
+	entry = cachedBlock{
+		size:       info.Size(),
+		modTime:    info.ModTime().UnixNano(),
+		stamp:      stamp,
+		block:      block.String(),
+		savedBytes: strip.saved() - savedBefore,
+	}
+	fileContents.WriteString(entry.block)
+	a.storeCachedBlock(path, entry, limit)
+}
+
+// storeCachedBlock adds a block to the cache, evicting others to stay within limit bytes
+func (a *App) storeCachedBlock(path string, entry cachedBlock, limit int64) {
+	size := int64(len(entry.block))
+	if size > limit {
+		return
+	}
+	a.contentCacheMu.Lock()
+	defer a.contentCacheMu.Unlock()
+	if a.contentCache == nil {
+		a.contentCache = make(map[string]cachedBlock)
+	}
+	if previous, ok := a.contentCache[path]; ok {
+		a.contentCacheBytes -= int64(len(previous.block))
+		delete(a.contentCache, path)
+	}
+	for evictPath, evicted := range a.contentCache {
+		if a.contentCacheBytes+size <= limit {
+			break
+		}
+		a.contentCacheBytes -= int64(len(evicted.block))
+		delete(a.contentCache, evictPath)
+	}
+	a.contentCache[path] = entry
+	a.contentCacheBytes += size
+}
+
+// logContentCache logs how many blocks a generation reused
+func (a *App) logContentCache(rootDir string, run *contentCacheRun) {
+	if run.hits > 0 {
+		runtime.LogInfof(a.ctx, "Reused %d cached file blocks for %s (%d rendered)", run.hits, rootDir, run.misses)
+	}
+}
+
+// ============================================================================
+// Content Cache Methods (Wails-bound)
+// ============================================================================
+
+// GetContentCacheStats returns the size of the generation cache
+//
+// Returns:
+//   - map[string]int: "entries" (cached file blocks), "bytes" (their size) and "limitMB" (ContentCacheMB)
+func (a *App) GetContentCacheStats() map[string]int {
+	a.contentCacheMu.Lock()
+	defer a.contentCacheMu.Unlock()
+	return map[string]int{
+		"entries": len(a.contentCache),
+		"bytes":   int(a.contentCacheBytes),
+		"limitMB": a.settings.ContentCacheMB,
+	}
+}
+
+// SetContentCacheMB sets how much memory the generation cache may use and saves the setting
+//
+// Parameters:
+//   - limitMB: Megabytes of cached file blocks (0 turns the cache off and empties it)
+//
+// Returns:
+//   - error: Error if the limit is negative or the setting cannot be saved
+func (a *App) SetContentCacheMB(limitMB int) error {
+	if limitMB < 0 {
+		return fmt.Errorf("content cache size must not be negative, got %d", limitMB)
+	}
+	a.settings.ContentCacheMB = limitMB
+	if err := a.saveSettings(); err != nil {
+		return fmt.Errorf("failed to save content cache setting: %w", err)
+	}
+	if limitMB == 0 {
+		a.ClearContentCache()
+	}
+	runtime.LogInfof(a.ctx, "Content cache size: %d MB", limitMB)
+	return nil
+}
+
+// ClearContentCache drops all cached file blocks, so the next generation reads every file
+func (a *App) ClearContentCache() {
+	a.contentCacheMu.Lock()
+	a.contentCache = nil
+	a.contentCacheBytes = 0
+	a.contentCacheMu.Unlock()
+	runtime.LogInfof(a.ctx, "Content cache cleared")
+}
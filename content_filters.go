@@ -0,0 +1,259 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- Content Filters ---
+//
+// Content filters check file contents during context generation for data that must not
+// reach an LLM: personal data, customer identifiers, internal hostnames, or anything a
+// regular expression describes. Each filter has a policy:
+//   - block: generation fails, naming the file and the filter
+//   - redact: matches are replaced by [REDACTED:<filter>]
+//   - warn: content is kept, the match is reported
+//
+// A filter either uses a built-in detector (email, customer_id, internal_host) or its own
+// pattern; a pattern on a detector filter replaces the detector's default pattern. Findings
+// of a generation are emitted with "contentFilterFindings". Redaction markers are counted
+// again when content leaves the machine, for the egress audit log.
+
+// Content filter policies
+const (
+	contentPolicyBlock  = "block"
+	contentPolicyRedact = "redact"
+	contentPolicyWarn   = "warn"
+)
+
+// contentDetectors are the default patterns of the built-in detectors
+var contentDetectors = map[string]string{
+	"email":         `[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}`,
+	"customer_id":   `(?i)\b(?:cust(?:omer)?|acct|account|client)[-_ ]?(?:id|no|number)?[-_:#= ]*\d{5,}\b`,
+	"internal_host": `(?i)\b[a-z0-9](?:[a-z0-9-]*[a-z0-9])?(?:\.[a-z0-9](?:[a-z0-9-]*[a-z0-9])?)*\.(?:internal|intranet|corp|lan|local|localdomain|private)\b`,
+}
+
+// contentFilterNameRegex restricts filter names to what fits in a redaction marker
+var contentFilterNameRegex = regexp.MustCompile(`^[a-z0-9_-]+$`)
+
+// redactionMarkerRegex finds the redaction markers in content
+var redactionMarkerRegex = regexp.MustCompile(`\[REDACTED:([a-z0-9_-]+)\]`)
+
+// errContentBlocked is wrapped by the error of a generation stopped by a block filter
+var errContentBlocked = errors.New("blocked by content filter")
+
+// ContentFilter is a configured content filter
+type ContentFilter struct {
+	Name     string `json:"name"`     // Name shown in findings and redaction markers (a-z, 0-9, _ and -)
+	Detector string `json:"detector"` // Built-in detector (email, customer_id, internal_host); empty for a custom pattern
+	Pattern  string `json:"pattern"`  // Regular expression (overrides the detector's default pattern)
+	Policy   string `json:"policy"`   // block, redact or warn
+	Enabled  bool   `json:"enabled"`  // Disabled filters are kept but not evaluated
+}
+
+// ContentFilterFinding reports the matches of a filter in one file
+type ContentFilterFinding struct {
+	File    string `json:"file"`    // Path relative to the project root
+	Filter  string `json:"filter"`  // Filter name
+	Policy  string `json:"policy"`  // Policy applied
+	Matches int    `json:"matches"` // Number of matches
+}
+
+// compiledContentFilter is a filter ready to be evaluated
+type compiledContentFilter struct {
+	filter ContentFilter
+	regex  *regexp.Regexp
+}
+
+// contentFilterRun evaluates the enabled filters over the files of one generation
+type contentFilterRun struct {
+	filters  []compiledContentFilter
+	findings []ContentFilterFinding
+}
+
+// compileContentFilter validates a filter and compiles its pattern
+func compileContentFilter(filter ContentFilter) (*regexp.Regexp, error) {
+	if !contentFilterNameRegex.MatchString(filter.Name) {
+		return nil, fmt.Errorf("invalid filter name %q (use a-z, 0-9, _ and -)", filter.Name)
+	}
+	switch filter.Policy {
+	case contentPolicyBlock, contentPolicyRedact, contentPolicyWarn:
+	default:
+		return nil, fmt.Errorf("filter %s: unknown policy %q (use block, redact or warn)", filter.Name, filter.Policy)
+	}
+	pattern := filter.Pattern
+	if pattern == "" {
+		if filter.Detector == "" {
+			return nil, fmt.Errorf("filter %s needs a detector or a pattern", filter.Name)
+		}
+		var ok bool
+		if pattern, ok = contentDetectors[filter.Detector]; !ok {
+			return nil, fmt.Errorf("filter %s: unknown detector %q", filter.Name, filter.Detector)
+		}
+	}
+	regex, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("filter %s: invalid pattern: %w", filter.Name, err)
+	}
+	return regex, nil
+}
+
+// enabledContentFilters returns the filters a generation evaluates
+func (a *App) enabledContentFilters() []ContentFilter {
+	var enabled []ContentFilter
+	for _, filter := range a.settings.ContentFilters {
+		if filter.Enabled {
+			enabled = append(enabled, filter)
+		}
+	}
+	return enabled
+}
+
+// newContentFilterRun compiles the enabled filters (nil if there are none)
+// Filters that do not compile are skipped; SetContentFilters rejects them anyway.
+func (a *App) newContentFilterRun() *contentFilterRun {
+	run := &contentFilterRun{}
+	for _, filter := range a.settings.ContentFilters {
+		if !filter.Enabled {
+			continue
+		}
+		regex, err := compileContentFilter(filter)
+		if err != nil {
+			runtime.LogWarningf(a.ctx, "Skipping content filter: %v", err)
+			continue
+		}
+		run.filters = append(run.filters, compiledContentFilter{filter: filter, regex: regex})
+	}
+	if len(run.filters) == 0 {
+		return nil
+	}
+	return run
+}
+
+// apply evaluates the filters over the content of a file, recording findings
+//
+// Returns:
+//   - string: The content with redact matches replaced
+//   - error: Error wrapping errContentBlocked if a block filter matches
+func (r *contentFilterRun) apply(relPath, content string) (string, error) {
+	if r == nil {
+		return content, nil
+	}
+	for _, f := range r.filters {
+		matches := len(f.regex.FindAllStringIndex(content, -1))
+		if matches == 0 {
+			continue
+		}
+		r.findings = append(r.findings, ContentFilterFinding{File: relPath, Filter: f.filter.Name, Policy: f.filter.Policy, Matches: matches})
+		switch f.filter.Policy {
+		case contentPolicyBlock:
+			return "", fmt.Errorf("%w %q: %d matches in %s", errContentBlocked, f.filter.Name, matches, relPath)
+		case contentPolicyRedact:
+			content = f.regex.ReplaceAllLiteralString(content, "[REDACTED:"+f.filter.Name+"]")
+		}
+	}
+	return content, nil
+}
+
+// applyToBlock evaluates the filters over a generated file block, leaving its header line alone
+func (r *contentFilterRun) applyToBlock(relPath, block string) (string, error) {
+	if r == nil {
+		return block, nil
+	}
+	header, body, found := strings.Cut(block, "\n")
+	if !found {
+		return block, nil // Placeholder for a skipped file
+	}
+	body, err := r.apply(relPath, body)
+	if err != nil {
+		return "", err
+	}
+	return header + "\n" + body, nil
+}
+
+// redactionCounts counts the redaction markers in content by filter (nil if there are none)
+func redactionCounts(content string) map[string]int {
+	var counts map[string]int
+	for _, m := range redactionMarkerRegex.FindAllStringSubmatch(content, -1) {
+		if counts == nil {
+			counts = make(map[string]int)
+		}
+		counts[m[1]]++
+	}
+	return counts
+}
+
+// ============================================================================
+// Content Filter Methods (Wails-bound)
+// ============================================================================
+
+// GetContentFilters returns the configured content filters
+func (a *App) GetContentFilters() []ContentFilter {
+	if a.settings.ContentFilters == nil {
+		return []ContentFilter{}
+	}
+	return a.settings.ContentFilters
+}
+
+// GetContentDetectors returns the built-in detectors and their default patterns
+func (a *App) GetContentDetectors() map[string]string {
+	detectors := make(map[string]string, len(contentDetectors))
+	for name, pattern := range contentDetectors {
+		detectors[name] = pattern
+	}
+	return detectors
+}
+
+// SetContentFilters replaces the content filters and saves the setting
+//
+// Parameters:
+//   - filters: Filters in evaluation order (names must be unique)
+//
+// Returns:
+//   - error: Error if a filter is invalid or the settings cannot be saved
+func (a *App) SetContentFilters(filters []ContentFilter) error {
+	names := make(map[string]bool)
+	for _, filter := range filters {
+		if _, err := compileContentFilter(filter); err != nil {
+			return err
+		}
+		if names[filter.Name] {
+			return fmt.Errorf("duplicate filter name: %s", filter.Name)
+		}
+		names[filter.Name] = true
+	}
+	a.settings.ContentFilters = filters
+	if err := a.saveSettings(); err != nil {
+		return fmt.Errorf("failed to save content filters: %w", err)
+	}
+	runtime.LogInfof(a.ctx, "Content filters set (%d filters)", len(filters))
+	return nil
+}
+
+// TestContentFilters evaluates the enabled filters over a text, for trying them out
+//
+// Parameters:
+//   - text: Sample content
+//
+// Returns:
+//   - string: The text as generation would include it (redactions applied)
+//   - []ContentFilterFinding: Matches by filter (file is empty), in filter order
+func (a *App) TestContentFilters(text string) (string, []ContentFilterFinding) {
+	run := a.newContentFilterRun()
+	if run == nil {
+		return text, []ContentFilterFinding{}
+	}
+	// A block filter stops the evaluation, like in generation
+	result, err := run.apply("", text)
+	if err != nil {
+		result = ""
+	}
+	if run.findings == nil {
+		return result, []ContentFilterFinding{}
+	}
+	return result, run.findings
+}
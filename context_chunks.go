@@ -0,0 +1,159 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- Context Chunking ---
+//
+// Chat UIs limit how much can be pasted at once. With a chunk size set, every generated context
+// is also split into chunks of at most that many estimated tokens, each emitted as a separate
+// "shotgunContextChunk" event after "shotgunContextGenerated". Chunks end at the same boundaries
+// as PreviewPromptSplit (after a file block when possible) and start with a header line naming
+// the part and the files it covers:
+//
+//	--- Part 2/5, files src/api.go..src/util.go ---
+//
+// Room for the header is kept free, so a chunk with its header stays under the limit. File
+// names come from the <file> blocks; context in the markdown output format gets headers
+// without them.
+
+const chunkHeaderFormat = "--- Part %d/%d%s ---\n"
+
+// ContextChunk is one chunk of generated context
+type ContextChunk struct {
+	Index   int      `json:"index"`   // Position of the chunk (1-based)
+	Total   int      `json:"total"`   // Number of chunks
+	Tokens  int      `json:"tokens"`  // Estimated tokens of the chunk, header included
+	Files   []string `json:"files"`   // Files with content in the chunk, in order
+	Header  string   `json:"header"`  // Header line of the chunk
+	Content string   `json:"content"` // The chunk, starting with its header
+}
+
+// chunkContext splits context into chunks of at most maxTokens, each with a header
+//
+// Parameters:
+//   - context: Generated context to split
+//   - maxTokens: Token limit per chunk, header included
+//
+// Returns:
+//   - []ContextChunk: Chunks in order
+//   - error: Error if the limit leaves no room after the header
+func (a *App) chunkContext(context string, maxTokens int) ([]ContextChunk, error) {
+	// The longest possible header: two of the longest paths and a three-digit part count
+	longestPath := 0
+	for _, m := range splitFileOpenRegex.FindAllStringSubmatch(context, -1) {
+		longestPath = max(longestPath, len(fileBlockPath(m[1])))
+	}
+	headerTokens := a.EstimateTokens(fmt.Sprintf(chunkHeaderFormat, 999, 999, ", files ..")) + 2*longestPath/4 + 1
+	if maxTokens <= 2*headerTokens {
+		return nil, fmt.Errorf("chunk size of %d tokens is too small (at least %d needed)", maxTokens, 2*headerTokens+1)
+	}
+
+	parts := a.splitPromptParts(context, (maxTokens-headerTokens)*4) // EstimateTokens counts 4 bytes per token
+	chunks := make([]ContextChunk, 0, len(parts))
+	continued := ""
+	for i, part := range parts {
+		// A file cut at the end of the previous part continues at the start of this one
+		files := []string{}
+		if continued != "" {
+			files = append(files, continued)
+		}
+		files = append(files, part.Files...)
+		if part.SplitsFile != "" && (len(files) == 0 || files[len(files)-1] != part.SplitsFile) {
+			files = append(files, part.SplitsFile)
+		}
+		continued = part.SplitsFile
+
+		label := ""
+		switch {
+		case len(files) == 1:
+			label = ", file " + files[0]
+		case len(files) > 1:
+			label = ", files " + files[0] + ".." + files[len(files)-1]
+		}
+		header := fmt.Sprintf(chunkHeaderFormat, i+1, len(parts), label)
+		content := header + context[part.Start:part.End]
+		chunks = append(chunks, ContextChunk{
+			Index:   i + 1,
+			Total:   len(parts),
+			Tokens:  a.EstimateTokens(content),
+			Files:   files,
+			Header:  header,
+			Content: content,
+		})
+	}
+	return chunks, nil
+}
+
+// emitContextChunks emits the chunks of a generated context when a chunk size is set
+func (a *App) emitContextChunks(rootDir, context string) {
+	maxTokens := a.settings.ContextChunkTokens
+	if maxTokens <= 0 || context == "" {
+		return
+	}
+	chunks, err := a.chunkContext(context, maxTokens)
+	if err != nil {
+		runtime.LogWarningf(a.ctx, "Context not chunked: %v", err)
+		return
+	}
+	for _, chunk := range chunks {
+		runtime.EventsEmit(a.ctx, "shotgunContextChunk", map[string]interface{}{
+			"rootDir": rootDir,
+			"chunk":   chunk,
+		})
+	}
+	runtime.LogInfof(a.ctx, "Split context for %s into %d chunks of at most %d tokens", rootDir, len(chunks), maxTokens)
+}
+
+// ============================================================================
+// Context Chunking Methods (Wails-bound)
+// ============================================================================
+
+// GetContextChunkTokens returns the chunk size of generated context (0 = not chunked)
+func (a *App) GetContextChunkTokens() int {
+	return a.settings.ContextChunkTokens
+}
+
+// SetContextChunkTokens sets the chunk size of generated context and saves it
+//
+// Parameters:
+//   - tokens: Maximum estimated tokens per chunk (0 = not chunked)
+//
+// Returns:
+//   - error: Error if the size is negative or the setting cannot be saved
+func (a *App) SetContextChunkTokens(tokens int) error {
+	if tokens < 0 {
+		return fmt.Errorf("chunk size must not be negative, got %d", tokens)
+	}
+	a.settings.ContextChunkTokens = tokens
+	if err := a.saveSettings(); err != nil {
+		return fmt.Errorf("failed to save context chunk size setting: %w", err)
+	}
+	runtime.LogInfof(a.ctx, "Context chunk size: %d tokens", tokens)
+	return nil
+}
+
+// ChunkContext splits a context into chunks with headers, as emitted after generation
+//
+// Parameters:
+//   - context: Generated context (or any prompt) to split
+//   - maxTokens: Token limit per chunk (0 for the saved chunk size)
+//
+// Returns:
+//   - []ContextChunk: Chunks in order
+//   - error: Error if no chunk size is set or it is too small
+func (a *App) ChunkContext(context string, maxTokens int) ([]ContextChunk, error) {
+	if maxTokens <= 0 {
+		maxTokens = a.settings.ContextChunkTokens
+	}
+	if maxTokens <= 0 {
+		return nil, fmt.Errorf("no chunk size set")
+	}
+	if context == "" {
+		return []ContextChunk{}, nil
+	}
+	return a.chunkContext(context, maxTokens)
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- JSON Context Output ---
+//
+// GenerateContextJSON produces the same context as a generation, as data for downstream
+// tooling instead of a text blob: the tree as text, then one entry per file with its path,
+// size, language, token estimate and content. Files are walked in tree order with the same
+// selection, ignore rules, summary-only directories, content filters and dedupe setting as
+// text generation; binary and non-UTF-8 files get an entry without content.
+
+// ContextFile is a file of JSON context
+type ContextFile struct {
+	Path        string `json:"path"`                  // Path relative to the project root (forward slashes)
+	Size        int64  `json:"size"`                  // Size in bytes
+	Language    string `json:"language"`              // Language detected from the file name
+	Tokens      int    `json:"tokens"`                // Estimated tokens of the content
+	Content     string `json:"content"`               // File content (empty if skipped or a duplicate)
+	IsBinary    bool   `json:"isBinary,omitempty"`    // True if the file is binary (content skipped)
+	DuplicateOf string `json:"duplicateOf,omitempty"` // Earlier file with the same content (with DedupeContent)
+	Error       string `json:"error,omitempty"`       // Why the content is missing, if it is not binary
+}
+
+// ContextJSON is generated context as structured data
+type ContextJSON struct {
+	RootDir     string             `json:"rootDir"`               // Project root directory
+	GeneratedAt time.Time          `json:"generatedAt"`           // When the context was generated
+	TechStack   string             `json:"techStack,omitempty"`   // Language summary (with IncludeTechStack)
+	Tree        string             `json:"tree"`                  // The tree, as in text context
+	Files       []ContextFile      `json:"files"`                 // Files in tree order
+	Summaries   []DirectorySummary `json:"summaries"`             // Summary-only directories in tree order
+	Environment *EnvironmentInfo   `json:"environment,omitempty"` // OS and runtime versions (with IncludeEnvironment)
+	TotalTokens int                `json:"totalTokens"`           // Estimated tokens of all contents
+}
+
+// contextJSONFile reads the entry of one file
+func (a *App) contextJSONFile(absPath, relPath string) ContextFile {
+	file := ContextFile{Path: relPath, Language: detectLanguage(relPath)}
+	if info, err := a.projectFS.Stat(absPath); err == nil {
+		file.Size = info.Size()
+	}
+	isBinary, err := a.isBinaryFileCached(absPath)
+	if err != nil {
+		file.Error = fmt.Sprintf("binary detection failed: %v", err)
+		return file
+	}
+	if isBinary {
+		file.IsBinary = true
+		return file
+	}
+	content, err := a.projectFS.ReadFile(absPath)
+	if err != nil {
+		file.Error = fmt.Sprintf("read error: %v", err)
+		return file
+	}
+	if !utf8.Valid(content) {
+		file.Error = "file contains invalid UTF-8"
+		return file
+	}
+	file.Language = detectContentLanguage(relPath, content)
+	file.Content = string(content)
+	return file
+}
+
+// ============================================================================
+// JSON Context Methods (Wails-bound)
+// ============================================================================
+
+// GenerateContextJSON generates the context of a selection as structured data
+//
+// Parameters:
+//   - rootDir: Project root directory
+//   - excludedPaths: The selection, expressed as exclusions
+//
+// Returns:
+//   - ContextJSON: Tree, files with their contents, and summaries
+//   - error: Error if the project cannot be read or a content filter blocks a file
+func (a *App) GenerateContextJSON(rootDir string, excludedPaths []string) (ContextJSON, error) {
+	if err := a.validateContentRoot(rootDir); err != nil {
+		return ContextJSON{}, err
+	}
+	if !a.projectIsDir(rootDir) {
+		return ContextJSON{}, fmt.Errorf("project folder does not exist: %s", rootDir)
+	}
+	ctx := a.ctx
+	excluded := make(map[string]bool, len(excludedPaths))
+	for _, p := range excludedPaths {
+		excluded[p] = true
+	}
+	opts := a.newTreeBuildOptions(rootDir, a.compileProjectGitignore(rootDir))
+	filters := a.newContentFilterRun()
+	secrets := a.newSecretRedactionRun()
+	duplicates := a.newDuplicateTracker()
+
+	result := ContextJSON{
+		RootDir:     rootDir,
+		GeneratedAt: time.Now(),
+		Files:       []ContextFile{},
+		Summaries:   []DirectorySummary{},
+	}
+	if a.settings.IncludeTechStack {
+		if langStats, err := a.computeLanguageStats(ctx, rootDir, excludedPaths, false); err == nil {
+			result.TechStack = techStackSummary(langStats)
+		}
+	}
+
+	var tree strings.Builder
+	tree.WriteString(filepath.Base(rootDir) + string(os.PathSeparator) + "\n")
+	var walk func(dir, prefix string) error
+	walk = func(dir, prefix string) error {
+		entries, err := a.projectFS.ReadDir(dir)
+		if err != nil {
+			runtime.LogWarningf(a.ctx, "GenerateContextJSON: error reading dir %s: %v", dir, err)
+			return nil
+		}
+		sortTreeEntries(entries)
+		var visible []os.DirEntry
+		for _, entry := range entries {
+			relPath, _ := filepath.Rel(rootDir, filepath.Join(dir, entry.Name()))
+			if !excluded[relPath] && !opts.excludes(relPath, entry.IsDir()) {
+				visible = append(visible, entry)
+			}
+		}
+		for i, entry := range visible {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			path := filepath.Join(dir, entry.Name())
+			relPath, _ := filepath.Rel(rootDir, path)
+			branch, nextPrefix := "|-- ", prefix+"|   "
+			if i == len(visible)-1 {
+				branch, nextPrefix = "`-- ", prefix+"    "
+			}
+			tree.WriteString(prefix + branch + entry.Name() + "\n")
+
+			switch {
+			case entry.IsDir() && opts.summaryOnly[relPath]:
+				summary, _, err := a.summarizeDirectory(ctx, rootDir, relPath, func(p string) bool { return excluded[p] }, opts)
+				if err != nil {
+					return err
+				}
+				result.Summaries = append(result.Summaries, summary)
+			case entry.IsDir():
+				if err := walk(path, nextPrefix); err != nil {
+					return err
+				}
+			default:
+				file := a.contextJSONFile(path, filepath.ToSlash(relPath))
+				if file.Content != "" {
+					if file.Content, err = filters.apply(file.Path, secrets.redact(file.Path, file.Content)); err != nil {
+						return err
+					}
+					if canonical := duplicates.record(file.Path, file.Content); canonical != "" && duplicates.dedupe {
+						file.DuplicateOf, file.Content = canonical, ""
+					}
+					file.Tokens = a.EstimateTokens(file.Content)
+					result.TotalTokens += file.Tokens
+				}
+				result.Files = append(result.Files, file)
+			}
+		}
+		return nil
+	}
+	err := walk(rootDir, "")
+	a.emitSecretsRedacted(rootDir, secrets)
+	if filters != nil && len(filters.findings) > 0 {
+		runtime.EventsEmit(a.ctx, "contentFilterFindings", map[string]interface{}{
+			"rootDir":  rootDir,
+			"findings": filters.findings,
+		})
+	}
+	if err != nil {
+		if errors.Is(err, errContentBlocked) || errors.Is(err, context.Canceled) {
+			return ContextJSON{}, err
+		}
+		return ContextJSON{}, fmt.Errorf("failed to build JSON context: %w", err)
+	}
+	result.Tree = tree.String()
+	if a.settings.IncludeEnvironment {
+		environment := a.collectEnvironment(rootDir)
+		result.Environment = &environment
+	}
+
+	runtime.LogInfof(a.ctx, "Generated JSON context for %s: %d files, ~%d tokens", rootDir, len(result.Files), result.TotalTokens)
+	return result, nil
+}
@@ -0,0 +1,364 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	goruntime "runtime"
+	"strings"
+
+	"github.com/adrg/xdg"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- "Open with Shotgun Code" Context Menu ---
+//
+// Registers a context-menu entry for folders in the system file manager. The entry launches
+// the app with the folder as its argument; when the app is already running, the single-instance
+// lock forwards the folder to it (see project_open.go).
+//   - Windows: Explorer verbs under HKCU\Software\Classes\Directory (no elevation needed)
+//   - macOS: a Finder Quick Action in ~/Library/Services
+//   - Linux: a desktop entry for folders and shotgun:// links, and a Files (Nautilus) script
+//
+// Installers call the executable with --register-context-menu / --unregister-context-menu.
+
+const contextMenuLabel = "Open with Shotgun Code"
+
+const (
+	registerContextMenuFlag   = "--register-context-menu"
+	unregisterContextMenuFlag = "--unregister-context-menu"
+)
+
+// windowsContextMenuKeys are the Explorer verbs for a folder and for the background of an open folder
+var windowsContextMenuKeys = map[string]string{
+	`HKCU\Software\Classes\Directory\shell\ShotgunCode`:            "%1",
+	`HKCU\Software\Classes\Directory\Background\shell\ShotgunCode`: "%V",
+}
+
+// ContextMenuStatus describes the context-menu registration on this system
+type ContextMenuStatus struct {
+	Supported  bool     `json:"supported"`  // Whether the OS is supported
+	Registered bool     `json:"registered"` // Whether the entry is currently installed
+	Locations  []string `json:"locations"`  // Registry keys or files that make up the entry
+}
+
+// contextMenuLocations returns the registry keys or files making up the entry on this OS
+func contextMenuLocations() []string {
+	switch goruntime.GOOS {
+	case "windows":
+		keys := make([]string, 0, len(windowsContextMenuKeys))
+		for key := range windowsContextMenuKeys {
+			keys = append(keys, key)
+		}
+		return keys
+	case "darwin":
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil
+		}
+		return []string{filepath.Join(home, "Library", "Services", contextMenuLabel+".workflow")}
+	case "linux":
+		return []string{
+			filepath.Join(xdg.DataHome, "applications", "shotgun-code.desktop"),
+			filepath.Join(xdg.DataHome, "nautilus", "scripts", contextMenuLabel),
+		}
+	}
+	return nil
+}
+
+// contextMenuStatus checks whether the entry is installed
+func contextMenuStatus() ContextMenuStatus {
+	locations := contextMenuLocations()
+	status := ContextMenuStatus{Supported: len(locations) > 0, Locations: locations}
+	if !status.Supported {
+		return status
+	}
+	status.Registered = true
+	for _, location := range locations {
+		var present bool
+		if goruntime.GOOS == "windows" {
+			present = exec.Command("reg", "query", location).Run() == nil
+		} else {
+			info, err := os.Stat(location)
+			present = err == nil && info.Mode().IsRegular()
+		}
+		if !present {
+			status.Registered = false
+		}
+	}
+	return status
+}
+
+// contextMenuExecutable returns the path of the running executable
+func contextMenuExecutable() (string, error) {
+	exePath, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("cannot locate the executable: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(exePath); err == nil {
+		exePath = resolved
+	}
+	return exePath, nil
+}
+
+// registerContextMenu installs the entry, pointing it at the running executable
+func registerContextMenu() error {
+	exePath, err := contextMenuExecutable()
+	if err != nil {
+		return err
+	}
+
+	switch goruntime.GOOS {
+	case "windows":
+		for key, placeholder := range windowsContextMenuKeys {
+			command := fmt.Sprintf(`"%s" "%s"`, exePath, placeholder)
+			for _, args := range [][]string{
+				{"add", key, "/ve", "/d", contextMenuLabel, "/f"},
+				{"add", key, "/v", "Icon", "/d", exePath, "/f"},
+				{"add", key + `\command`, "/ve", "/d", command, "/f"},
+			} {
+				if out, err := exec.Command("reg", args...).CombinedOutput(); err != nil {
+					return fmt.Errorf("failed to write %s: %v: %s", key, err, strings.TrimSpace(string(out)))
+				}
+			}
+		}
+		return nil
+
+	case "darwin":
+		workflowDir := contextMenuLocations()[0]
+		contentsDir := filepath.Join(workflowDir, "Contents")
+		if err := os.MkdirAll(contentsDir, 0755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", workflowDir, err)
+		}
+		var script strings.Builder
+		xml.EscapeText(&script, []byte(fmt.Sprintf("for f in \"$@\"; do %s \"$f\" & done", shellQuote(exePath))))
+		files := map[string]string{
+			"Info.plist":     fmt.Sprintf(quickActionInfoPlist, contextMenuLabel),
+			"document.wflow": fmt.Sprintf(quickActionWorkflow, script.String()),
+		}
+		for name, content := range files {
+			if err := os.WriteFile(filepath.Join(contentsDir, name), []byte(content), 0644); err != nil {
+				return fmt.Errorf("failed to write %s: %w", name, err)
+			}
+		}
+		exec.Command("/System/Library/CoreServices/pbs", "-update").Run() // Refresh the Services menu
+		return nil
+
+	case "linux":
+		locations := contextMenuLocations()
+		desktopEntry := fmt.Sprintf(linuxDesktopEntry, contextMenuLabel, exePath)
+		nautilusScript := fmt.Sprintf(linuxNautilusScript, shellQuote(exePath))
+		for i, content := range []string{desktopEntry, nautilusScript} {
+			if err := os.MkdirAll(filepath.Dir(locations[i]), 0755); err != nil {
+				return fmt.Errorf("failed to create %s: %w", filepath.Dir(locations[i]), err)
+			}
+			if err := os.WriteFile(locations[i], []byte(content), 0755); err != nil {
+				return fmt.Errorf("failed to write %s: %w", locations[i], err)
+			}
+		}
+		exec.Command("update-desktop-database", filepath.Dir(locations[0])).Run() // Optional, best effort
+		return nil
+	}
+	return fmt.Errorf("context menu registration is not supported on %s", goruntime.GOOS)
+}
+
+// unregisterContextMenu removes the entry; missing parts are not an error
+func unregisterContextMenu() error {
+	switch goruntime.GOOS {
+	case "windows":
+		for key := range windowsContextMenuKeys {
+			if exec.Command("reg", "query", key).Run() != nil {
+				continue
+			}
+			if out, err := exec.Command("reg", "delete", key, "/f").CombinedOutput(); err != nil {
+				return fmt.Errorf("failed to delete %s: %v: %s", key, err, strings.TrimSpace(string(out)))
+			}
+		}
+		return nil
+	case "darwin", "linux":
+		for _, location := range contextMenuLocations() {
+			if err := os.RemoveAll(location); err != nil {
+				return fmt.Errorf("failed to remove %s: %w", location, err)
+			}
+		}
+		if goruntime.GOOS == "darwin" {
+			exec.Command("/System/Library/CoreServices/pbs", "-update").Run()
+		}
+		return nil
+	}
+	return fmt.Errorf("context menu registration is not supported on %s", goruntime.GOOS)
+}
+
+// runContextMenuCommand handles the installer flags before the window is created
+//
+// Returns:
+//   - bool: True if a flag was handled and the process should exit
+//   - error: Error from the registration
+func runContextMenuCommand(args []string) (bool, error) {
+	for _, arg := range args {
+		switch arg {
+		case registerContextMenuFlag:
+			return true, registerContextMenu()
+		case unregisterContextMenuFlag:
+			return true, unregisterContextMenu()
+		}
+	}
+	return false, nil
+}
+
+// shellQuote quotes a string for POSIX shells
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// linuxDesktopEntry makes the app an "Open With" choice for folders and the handler of shotgun:// links
+const linuxDesktopEntry = `[Desktop Entry]
+Type=Application
+Name=Shotgun Code
+Comment=%s
+Exec="%s" %%u
+Terminal=false
+Categories=Development;
+MimeType=inode/directory;x-scheme-handler/shotgun;
+`
+
+// linuxNautilusScript appears under Scripts in the Files context menu
+const linuxNautilusScript = `#!/bin/sh
+# Installed by Shotgun Code
+printf '%%s\n' "$NAUTILUS_SCRIPT_SELECTED_FILE_PATHS" | while IFS= read -r f; do
+	[ -d "$f" ] && exec %s "$f"
+done
+`
+
+// quickActionInfoPlist declares the Finder Quick Action service for folders
+const quickActionInfoPlist = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>NSServices</key>
+	<array>
+		<dict>
+			<key>NSMenuItem</key>
+			<dict>
+				<key>default</key>
+				<string>%s</string>
+			</dict>
+			<key>NSMessage</key>
+			<string>runWorkflowAsService</string>
+			<key>NSRequiredContext</key>
+			<dict>
+				<key>NSApplicationIdentifier</key>
+				<string>com.apple.finder</string>
+			</dict>
+			<key>NSSendFileTypes</key>
+			<array>
+				<string>public.folder</string>
+			</array>
+		</dict>
+	</array>
+</dict>
+</plist>
+`
+
+// quickActionWorkflow is a one-step Automator workflow running a shell script with the folders as arguments
+const quickActionWorkflow = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>AMApplicationBuild</key>
+	<string>523</string>
+	<key>AMApplicationVersion</key>
+	<string>2.10</string>
+	<key>AMDocumentVersion</key>
+	<string>2</string>
+	<key>actions</key>
+	<array>
+		<dict>
+			<key>action</key>
+			<dict>
+				<key>AMAccepts</key>
+				<dict>
+					<key>Container</key>
+					<string>List</string>
+					<key>Optional</key>
+					<true/>
+					<key>Types</key>
+					<array>
+						<string>com.apple.cocoa.path</string>
+					</array>
+				</dict>
+				<key>ActionBundlePath</key>
+				<string>/System/Library/Automator/Run Shell Script.action</string>
+				<key>ActionName</key>
+				<string>Run Shell Script</string>
+				<key>ActionParameters</key>
+				<dict>
+					<key>COMMAND_STRING</key>
+					<string>%s</string>
+					<key>CheckedForUserDefaultShell</key>
+					<true/>
+					<key>inputMethod</key>
+					<integer>1</integer>
+					<key>shell</key>
+					<string>/bin/sh</string>
+					<key>source</key>
+					<string></string>
+				</dict>
+				<key>BundleIdentifier</key>
+				<string>com.apple.RunShellScript</string>
+				<key>CFBundleVersion</key>
+				<string>2.0.3</string>
+				<key>Class Name</key>
+				<string>RunShellScriptAction</string>
+			</dict>
+		</dict>
+	</array>
+	<key>workflowMetaData</key>
+	<dict>
+		<key>serviceInputTypeIdentifier</key>
+		<string>com.apple.Automator.fileSystemObject.folder</string>
+		<key>serviceOutputTypeIdentifier</key>
+		<string>com.apple.Automator.nothing</string>
+		<key>serviceProcessesInput</key>
+		<integer>0</integer>
+		<key>workflowTypeIdentifier</key>
+		<string>com.apple.Automator.servicesMenu</string>
+	</dict>
+</dict>
+</plist>
+`
+
+// ============================================================================
+// Context Menu Methods (Wails-bound)
+// ============================================================================
+
+// GetContextMenuStatus reports whether the "Open with Shotgun Code" entry is installed
+func (a *App) GetContextMenuStatus() ContextMenuStatus {
+	return contextMenuStatus()
+}
+
+// RegisterContextMenu installs the "Open with Shotgun Code" entry for folders
+//
+// Returns:
+//   - error: Error if the OS is not supported or the entry cannot be written
+func (a *App) RegisterContextMenu() error {
+	if err := registerContextMenu(); err != nil {
+		return fmt.Errorf("failed to register context menu: %w", err)
+	}
+	runtime.LogInfof(a.ctx, "Registered context menu entry: %v", contextMenuLocations())
+	return nil
+}
+
+// UnregisterContextMenu removes the "Open with Shotgun Code" entry
+//
+// Returns:
+//   - error: Error if the entry cannot be removed
+func (a *App) UnregisterContextMenu() error {
+	if err := unregisterContextMenu(); err != nil {
+		return fmt.Errorf("failed to unregister context menu: %w", err)
+	}
+	runtime.LogInfof(a.ctx, "Unregistered context menu entry")
+	return nil
+}
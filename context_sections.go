@@ -0,0 +1,280 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- Context Sections ---
+//
+// Generated context is a sequence of sections separated by blank lines. The built-in ones are
+// "preamble" (the tech stack and changed-only lines), "attachments" (external documents, see
+// external_attachments.go), "tree", "files" (the file blocks), "git" (branch, commit and
+// uncommitted changes) and "environment". The ContextSectionOrder
+// setting lists section names in the order they are written; built-in sections it leaves out
+// keep their default order after the listed ones, except "git", which is only written when
+// listed. Sections without content (no preamble, environment summary off) are skipped.
+//
+// Custom sections inject static text, e.g. a team's coding standards kept outside the
+// repository. Each has a name, a title and either inline text or a file read at every
+// generation; listing its name in the order places it, and unlisted custom sections come last:
+//
+//	<section name="coding-standards" title="Coding Standards">
+//	...
+//	</section>
+
+const maxCustomSectionBytes = 1 << 20
+
+// Built-in section names
+const (
+	sectionPreamble    = "preamble"
+	sectionAttachments = "attachments"
+	sectionTree        = "tree"
+	sectionFiles       = "files"
+	sectionGit         = "git"
+	sectionEnvironment = "environment"
+)
+
+// defaultContextSectionOrder is the section order without a ContextSectionOrder setting
+var defaultContextSectionOrder = []string{sectionPreamble, sectionAttachments, sectionTree, sectionFiles, sectionEnvironment}
+
+var customSectionNameRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// CustomContextSection is static text injected into generated context
+type CustomContextSection struct {
+	Name  string `json:"name"`           // Section name used in ContextSectionOrder (lowercase letters, digits, - and _)
+	Title string `json:"title"`          // Title shown in the section header (defaults to the name)
+	Path  string `json:"path,omitempty"` // File read at every generation (takes precedence over Text)
+	Text  string `json:"text,omitempty"` // Inline text
+}
+
+// ContextSections is the section layout of generated context
+type ContextSections struct {
+	Order    []string               `json:"order"`    // Section names in the order they are written (empty for the default)
+	Custom   []CustomContextSection `json:"custom"`   // Custom sections
+	Builtins []string               `json:"builtins"` // Built-in section names (read-only)
+}
+
+// isBuiltinSection reports whether name is a built-in section
+func isBuiltinSection(name string) bool {
+	return name == sectionGit || slices.Contains(defaultContextSectionOrder, name)
+}
+
+// contextSectionOrder resolves the order sections are written in
+func (a *App) contextSectionOrder() []string {
+	order := []string{}
+	seen := make(map[string]bool)
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			order = append(order, name)
+		}
+	}
+	custom := make(map[string]bool)
+	for _, section := range a.settings.CustomContextSections {
+		custom[section.Name] = true
+	}
+	for _, name := range a.settings.ContextSectionOrder {
+		if isBuiltinSection(name) || custom[name] {
+			add(name)
+		}
+	}
+	for _, name := range defaultContextSectionOrder {
+		add(name)
+	}
+	for _, section := range a.settings.CustomContextSections {
+		add(section.Name)
+	}
+	return order
+}
+
+// customSectionText returns the text of a custom section, reading its file if it has one
+func customSectionText(section CustomContextSection) (string, error) {
+	if section.Path == "" {
+		return section.Text, nil
+	}
+	info, err := os.Stat(section.Path)
+	if err != nil {
+		return "", err
+	}
+	if info.Size() > maxCustomSectionBytes {
+		return "", fmt.Errorf("file is larger than %d KB", maxCustomSectionBytes>>10)
+	}
+	data, err := os.ReadFile(section.Path)
+	if err != nil {
+		return "", err
+	}
+	if !utf8.Valid(data) {
+		return "", fmt.Errorf("file is not valid UTF-8")
+	}
+	return string(data), nil
+}
+
+// customSectionBlock formats a custom section in the output format
+func customSectionBlock(section CustomContextSection, text, format string) string {
+	title := section.Title
+	if title == "" {
+		title = section.Name
+	}
+	text = strings.TrimRight(text, "\n")
+	if format == outputFormatMarkdown {
+		return "## " + title + "\n\n" + text
+	}
+	return fmt.Sprintf("<section name=\"%s\" title=\"%s\">\n%s\n</section>", section.Name, fileBlockAttr(title), text)
+}
+
+// gitInfoBlock formats the branch, commit and uncommitted changes of a project (empty outside git)
+func gitInfoBlock(ctx context.Context, rootDir, format string) string {
+	commit := readGitRevision(ctx, rootDir)
+	if commit == "" {
+		return ""
+	}
+	branch := "(detached)"
+	cmd := exec.CommandContext(ctx, "git", "symbolic-ref", "--short", "-q", "HEAD")
+	cmd.Dir = rootDir
+	if out, err := cmd.Output(); err == nil && strings.TrimSpace(string(out)) != "" {
+		branch = strings.TrimSpace(string(out))
+	}
+	changes := 0
+	cmd = exec.CommandContext(ctx, "git", "status", "--porcelain", "-z")
+	cmd.Dir = rootDir
+	if out, err := cmd.Output(); err == nil {
+		entries := strings.Split(strings.TrimSuffix(string(out), "\x00"), "\x00")
+		for i := 0; i < len(entries); i++ {
+			if len(entries[i]) < 4 {
+				continue
+			}
+			changes++
+			if entries[i][0] == 'R' || entries[i][0] == 'C' {
+				i++ // Renames and copies are followed by their source path
+			}
+		}
+	}
+	inner := fmt.Sprintf("Branch: %s\nCommit: %s\nUncommitted changes: %d files\n", branch, commit, changes)
+	if format == outputFormatMarkdown {
+		return "## Git\n\n" + strings.TrimRight(markdownFence(inner, "text"), "\n")
+	}
+	return "<git>\n" + inner + "</git>"
+}
+
+// assembleContextSections joins the sections of generated context in the configured order
+//
+// Parameters:
+//   - ctx: Context of the generation (for git commands)
+//   - rootDir: Project root directory
+//   - builtins: Rendered preamble, attachments, tree, files and environment sections by name
+//
+// Returns:
+//   - string: The generated context
+func (a *App) assembleContextSections(ctx context.Context, rootDir string, builtins map[string]string) string {
+	custom := make(map[string]CustomContextSection)
+	for _, section := range a.settings.CustomContextSections {
+		custom[section.Name] = section
+	}
+	format := a.outputFormat()
+	var parts []string
+	for _, name := range a.contextSectionOrder() {
+		var text string
+		switch section, ok := custom[name]; {
+		case name == sectionGit:
+			text = gitInfoBlock(ctx, rootDir, format)
+		case isBuiltinSection(name):
+			text = builtins[name]
+		case ok:
+			body, err := customSectionText(section)
+			if err != nil {
+				runtime.LogWarningf(a.ctx, "Skipping context section %s: %v", name, err)
+				continue
+			}
+			if strings.TrimSpace(body) != "" {
+				text = customSectionBlock(section, body, format)
+			}
+		}
+		if strings.TrimSpace(text) != "" {
+			parts = append(parts, strings.TrimRight(text, "\n"))
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// ============================================================================
+// Context Section Methods (Wails-bound)
+// ============================================================================
+
+// GetContextSections returns the section order and custom sections of generated context
+func (a *App) GetContextSections() ContextSections {
+	builtins := append(append([]string{}, defaultContextSectionOrder...), sectionGit)
+	sections := ContextSections{
+		Order:    a.settings.ContextSectionOrder,
+		Custom:   a.settings.CustomContextSections,
+		Builtins: builtins,
+	}
+	if sections.Order == nil {
+		sections.Order = []string{}
+	}
+	if sections.Custom == nil {
+		sections.Custom = []CustomContextSection{}
+	}
+	return sections
+}
+
+// SetContextSections sets the section order and custom sections of generated context and saves them
+//
+// Parameters:
+//   - order: Section names in the order they are written (empty for the default order)
+//   - custom: Custom sections (each with a unique name and either a path or text)
+//
+// Returns:
+//   - error: Error if a name is unknown, invalid or duplicated, a custom section has no
+//     content, or the setting cannot be saved
+func (a *App) SetContextSections(order []string, custom []CustomContextSection) error {
+	names := make(map[string]bool)
+	for i, section := range custom {
+		section.Name = strings.TrimSpace(section.Name)
+		section.Title = strings.TrimSpace(section.Title)
+		section.Path = strings.TrimSpace(section.Path)
+		switch {
+		case !customSectionNameRegex.MatchString(section.Name):
+			return fmt.Errorf("invalid section name %q (use lowercase letters, digits, - and _)", section.Name)
+		case isBuiltinSection(section.Name):
+			return fmt.Errorf("section name %q is reserved for a built-in section", section.Name)
+		case names[section.Name]:
+			return fmt.Errorf("duplicate section name: %s", section.Name)
+		case section.Path == "" && strings.TrimSpace(section.Text) == "":
+			return fmt.Errorf("section %s needs a file path or text", section.Name)
+		}
+		if section.Path != "" {
+			if _, err := customSectionText(section); err != nil {
+				return fmt.Errorf("cannot read section %s from %s: %w", section.Name, section.Path, err)
+			}
+		}
+		names[section.Name] = true
+		custom[i] = section
+	}
+	seen := make(map[string]bool)
+	for _, name := range order {
+		if !isBuiltinSection(name) && !names[name] {
+			return fmt.Errorf("unknown context section: %s", name)
+		}
+		if seen[name] {
+			return fmt.Errorf("section %s is listed twice", name)
+		}
+		seen[name] = true
+	}
+
+	a.settings.ContextSectionOrder = order
+	a.settings.CustomContextSections = custom
+	if err := a.saveSettings(); err != nil {
+		return fmt.Errorf("failed to save context sections setting: %w", err)
+	}
+	runtime.LogInfof(a.ctx, "Context sections: %s", strings.Join(a.contextSectionOrder(), ", "))
+	return nil
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- Sharing Context Through the Web ---
+//
+// Uploads generated context to a GitHub Gist or a paste service and returns its URL, for
+// machines where the clipboard cannot carry large contexts (remote desktops, WSL, VMs).
+
+const (
+	gistAPIURL         = "https://api.github.com/gists"
+	gistFilename       = "shotgun-context.md"
+	shareUploadTimeout = 5 * time.Minute // Contexts can be tens of megabytes
+	shareMaxErrorBytes = 4096            // Error bodies kept for messages
+)
+
+// shareHTTPClient uploads shared contexts
+var shareHTTPClient = &http.Client{Timeout: shareUploadTimeout, Transport: outboundTransport}
+
+// gistRequest is the body of a gist creation request
+type gistRequest struct {
+	Description string                       `json:"description"`
+	Public      bool                         `json:"public"`
+	Files       map[string]map[string]string `json:"files"`
+}
+
+// readShareError returns a short description of a failed upload response
+func readShareError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, shareMaxErrorBytes))
+	return fmt.Errorf("upload failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+}
+
+// ============================================================================
+// Sharing Methods (Wails-bound)
+// ============================================================================
+
+// ExportToGist uploads content as a GitHub Gist
+//
+// Parameters:
+//   - content: Text to upload (usually the generated context)
+//   - visibility: "secret" (unlisted, the default) or "public"
+//   - token: GitHub token with the gist scope (never stored)
+//
+// Returns:
+//   - string: URL of the created gist
+//   - error: Error if the content is empty, the token is missing or the upload fails
+func (a *App) ExportToGist(content, visibility, token string) (string, error) {
+	if strings.TrimSpace(content) == "" {
+		return "", fmt.Errorf("content is empty, nothing to export")
+	}
+	if strings.TrimSpace(token) == "" {
+		return "", fmt.Errorf("a GitHub token with the gist scope is required")
+	}
+	var public bool
+	switch visibility {
+	case "", "secret":
+	case "public":
+		public = true
+	default:
+		return "", fmt.Errorf("unknown gist visibility: %s (use secret or public)", visibility)
+	}
+
+	body, err := json.Marshal(gistRequest{
+		Description: "Context generated by Shotgun Code",
+		Public:      public,
+		Files:       map[string]map[string]string{gistFilename: {"content": content}},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode gist: %w", err)
+	}
+	req, err := http.NewRequestWithContext(a.ctx, "POST", gistAPIURL, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(token))
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := shareHTTPClient.Do(req)
+	a.recordShareEgress("gist", gistAPIURL, content, err == nil && resp.StatusCode == http.StatusCreated)
+	if err != nil {
+		return "", fmt.Errorf("failed to upload gist: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return "", readShareError(resp)
+	}
+
+	var created struct {
+		HTMLURL string `json:"html_url"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil || created.HTMLURL == "" {
+		return "", fmt.Errorf("unexpected gist response: %v", err)
+	}
+	runtime.LogInfof(a.ctx, "Exported %d bytes to %s gist %s", len(content), visibility, created.HTMLURL)
+	return created.HTMLURL, nil
+}
+
+// ExportToPaste uploads content to a paste service that accepts the text as the raw POST body
+// and answers with the paste URL (in the body or a Location header), such as paste.rs
+//
+// Parameters:
+//   - content: Text to upload
+//   - endpoint: http(s) URL of the paste service
+//
+// Returns:
+//   - string: URL of the created paste
+//   - error: Error if the endpoint is invalid or the upload fails
+func (a *App) ExportToPaste(content, endpoint string) (string, error) {
+	if strings.TrimSpace(content) == "" {
+		return "", fmt.Errorf("content is empty, nothing to export")
+	}
+	target, err := url.Parse(strings.TrimSpace(endpoint))
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return "", fmt.Errorf("invalid paste endpoint: %s", endpoint)
+	}
+
+	req, err := http.NewRequestWithContext(a.ctx, "POST", target.String(), strings.NewReader(content))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+
+	resp, err := shareHTTPClient.Do(req)
+	a.recordShareEgress("paste", target.String(), content, err == nil && resp.StatusCode >= 200 && resp.StatusCode <= 299)
+	if err != nil {
+		return "", fmt.Errorf("failed to upload paste: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return "", readShareError(resp)
+	}
+
+	pasteURL := resp.Header.Get("Location")
+	if pasteURL == "" {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, shareMaxErrorBytes))
+		pasteURL = strings.TrimSpace(string(body))
+	}
+	if location, err := target.Parse(pasteURL); err == nil && pasteURL != "" {
+		pasteURL = location.String() // Relative locations are resolved against the endpoint
+	}
+	if !strings.HasPrefix(pasteURL, "http://") && !strings.HasPrefix(pasteURL, "https://") {
+		return "", fmt.Errorf("paste service did not return a URL: %q", pasteURL)
+	}
+	runtime.LogInfof(a.ctx, "Exported %d bytes to paste %s", len(content), pasteURL)
+	return pasteURL, nil
+}
@@ -0,0 +1,496 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- Context Snapshots ---
+//
+// A snapshot keeps a generated context together with what produced it: the excluded paths,
+// the settings that shape the output and the git revision of the project. Snapshots live in
+// <config dir>/snapshots/<project key>/<id>/ (snapshot.json and context.txt).
+//
+// Regenerating a snapshot runs generation again with its exclusions and settings, on a detached
+// copy of the app: the user's settings, generation checkpoint and progress events are left
+// alone. Comparing a snapshot reports which file blocks were added, removed or changed since
+// (XML and Markdown blocks), and whether the revision differs or the current settings no longer
+// match the snapshot's, so a user can tell exactly what an earlier LLM answer was based on.
+// Snapshots saved before a setting was recorded regenerate with its default.
+
+// snapshotFileBlockRegex finds the XML file blocks of generated context
+// A CDATA body (see file_block.go) may contain </file>; it ends at the first ]]> before </file>.
+var snapshotFileBlockRegex = regexp.MustCompile(`(?s)<file path="([^"]*)"[^>]*>\n(<!\[CDATA\[.*?\]\]>|.*?)\n</file>(?:\n|$)`)
+
+// snapshotMarkdownHeadingRegex matches the heading of a Markdown file block (see output_format.go)
+var snapshotMarkdownHeadingRegex = regexp.MustCompile("^## `([^`]+)`$")
+
+// SnapshotSettings are the settings a generated context depends on
+type SnapshotSettings struct {
+	UseGitignore       bool     `json:"useGitignore"`              // .gitignore rules applied
+	UseCustomIgnore    bool     `json:"useCustomIgnore"`           // Custom ignore rules applied
+	CustomIgnoreRules  string   `json:"customIgnoreRules"`         // Custom ignore patterns
+	ShowDotfiles       bool     `json:"showDotfiles"`              // All dotfiles included
+	VisibleDotfiles    []string `json:"visibleDotfiles"`           // Dotfile patterns included when ShowDotfiles is off
+	ForceIncludePaths  []string `json:"forceIncludePaths"`         // Paths included despite ignore rules
+	SummaryOnlyDirs    []string `json:"summaryOnlyDirs"`           // Directories generated as a summary
+	IncludeTechStack   bool     `json:"includeTechStack"`          // Language summary at the start
+	IncludeEnvironment bool     `json:"includeEnvironment"`        // Environment summary at the end
+	ChurnInFileHeaders bool     `json:"churnInFileHeaders"`        // Churn attributes in file headers
+	ContextSections    []string `json:"contextSections,omitempty"` // Section order, with custom sections (empty for the default)
+
+	CustomSections        []CustomContextSection `json:"customSections,omitempty"` // Static text sections injected into the context
+	Attachments           []string               `json:"attachments,omitempty"`    // External files rendered in the context
+	LanguageInFileHeaders bool                   `json:"languageInFileHeaders"`    // Language attributes in file headers
+	OutputFormat          string                 `json:"outputFormat"`             // xml or markdown
+	FileOrder             string                 `json:"fileOrder"`                // Order of file blocks
+	FileBlockEscaping     string                 `json:"fileBlockEscaping"`        // CDATA mode of file blocks
+	LineNumbers           bool                   `json:"lineNumbers"`              // Line numbers in file content
+	MaxFileBytes          int                    `json:"maxFileBytes"`             // Per-file content cap (0 = no cap)
+	FileCapKeepTail       bool                   `json:"fileCapKeepTail"`          // Capped files keep their tail
+	MaxFileBlockBytes     int                    `json:"maxFileBlockBytes"`        // Blocks over this size left out (0 = no limit)
+	GenerationTokenBudget int                    `json:"generationTokenBudget"`    // Token budget (0 = unlimited)
+	MaxTreeDepth          int                    `json:"maxTreeDepth"`             // Tree depth limit (0 = unlimited)
+	MaxEntriesPerDir      int                    `json:"maxEntriesPerDir"`         // Entries per directory limit (0 = unlimited)
+	RedactSecrets         bool                   `json:"redactSecrets"`            // Likely secrets masked
+	DedupeContent         bool                   `json:"dedupeContent"`            // Identical files included once
+}
+
+// ContextSnapshot is a saved generated context and what produced it
+type ContextSnapshot struct {
+	ID            string           `json:"id"`                // Snapshot ID (also its directory name)
+	Name          string           `json:"name"`              // Name given by the user
+	RootDir       string           `json:"rootDir"`           // Project root directory
+	CreatedAt     time.Time        `json:"createdAt"`         // When the snapshot was saved
+	ExcludedPaths []string         `json:"excludedPaths"`     // Paths excluded from the generation
+	Settings      SnapshotSettings `json:"settings"`          // Settings at the time of the snapshot
+	GitRevision   string           `json:"gitRevision"`       // HEAD commit (empty outside git)
+	GitDirty      bool             `json:"gitDirty"`          // True if the working tree had uncommitted changes
+	Files         int              `json:"files"`             // Number of file blocks in the content
+	Tokens        int              `json:"tokens"`            // Estimated tokens of the content
+	Content       string           `json:"content,omitempty"` // Generated context (only returned by GetContextSnapshot)
+}
+
+// SnapshotComparison compares a snapshot with a regeneration of it
+type SnapshotComparison struct {
+	SnapshotID      string   `json:"snapshotId"`      // Snapshot compared
+	Identical       bool     `json:"identical"`       // True if the regenerated content is exactly the same
+	GitRevision     string   `json:"gitRevision"`     // Current HEAD commit (empty outside git)
+	GitDirty        bool     `json:"gitDirty"`        // True if the working tree has uncommitted changes
+	RevisionChanged bool     `json:"revisionChanged"` // True if HEAD moved since the snapshot
+	SettingsChanged []string `json:"settingsChanged"` // Settings that differ from the snapshot (JSON names)
+	TreeChanged     bool     `json:"treeChanged"`     // True if the directory tree part differs
+	AddedFiles      []string `json:"addedFiles"`      // Files only in the regenerated content
+	RemovedFiles    []string `json:"removedFiles"`    // Files only in the snapshot
+	ChangedFiles    []string `json:"changedFiles"`    // Files whose content differs
+	SnapshotTokens  int      `json:"snapshotTokens"`  // Estimated tokens of the snapshot
+	CurrentTokens   int      `json:"currentTokens"`   // Estimated tokens of the regenerated content
+}
+
+// snapshotsDir returns the directory holding the snapshots of a project
+func (a *App) snapshotsDir(rootDir string) string {
+	sum := sha256.Sum256([]byte(projectSettingsKey(rootDir)))
+	return filepath.Join(filepath.Dir(a.configPath), "snapshots", hex.EncodeToString(sum[:8]))
+}
+
+// currentSnapshotSettings returns the settings that shape a generation of rootDir
+func (a *App) currentSnapshotSettings(rootDir string) SnapshotSettings {
+	summaryDirs := []string{}
+	for _, dir := range a.settings.SummaryOnlyDirs[projectSettingsKey(rootDir)] {
+		summaryDirs = append(summaryDirs, dir.Path)
+	}
+	sort.Strings(summaryDirs)
+	var sections []string
+	if len(a.settings.ContextSectionOrder) > 0 || len(a.settings.CustomContextSections) > 0 {
+		sections = a.contextSectionOrder()
+	}
+	return SnapshotSettings{
+		UseGitignore:       a.useGitignore,
+		UseCustomIgnore:    a.useCustomIgnore,
+		CustomIgnoreRules:  a.settings.CustomIgnoreRules,
+		ShowDotfiles:       a.settings.ShowDotfiles,
+		VisibleDotfiles:    append([]string{}, a.settings.VisibleDotfiles...),
+		ForceIncludePaths:  a.GetForceIncludedPaths(rootDir),
+		SummaryOnlyDirs:    summaryDirs,
+		IncludeTechStack:   a.settings.IncludeTechStack,
+		IncludeEnvironment: a.settings.IncludeEnvironment,
+		ChurnInFileHeaders: a.settings.ChurnInFileHeaders,
+		ContextSections:    sections,
+
+		CustomSections:        append([]CustomContextSection{}, a.settings.CustomContextSections...),
+		Attachments:           append([]string{}, a.settings.Attachments[projectSettingsKey(rootDir)]...),
+		LanguageInFileHeaders: a.settings.LanguageInFileHeaders,
+		OutputFormat:          a.outputFormat(),
+		FileOrder:             a.fileOrder(),
+		FileBlockEscaping:     a.fileBlockEscaping(),
+		LineNumbers:           a.settings.LineNumbers,
+		MaxFileBytes:          a.settings.MaxFileBytes,
+		FileCapKeepTail:       a.settings.FileCapKeepTail,
+		MaxFileBlockBytes:     a.settings.MaxFileBlockBytes,
+		GenerationTokenBudget: a.settings.GenerationTokenBudget,
+		MaxTreeDepth:          a.settings.MaxTreeDepth,
+		MaxEntriesPerDir:      a.settings.MaxEntriesPerDir,
+		RedactSecrets:         a.settings.RedactSecrets,
+		DedupeContent:         a.settings.DedupeContent,
+	}
+}
+
+// snapshotGenerator returns a detached copy of the app that generates rootDir with the
+// settings of a snapshot
+// The copy has no config path, so it neither saves settings nor writes or removes a generation
+// checkpoint, and it emits no generation events.
+func (a *App) snapshotGenerator(rootDir string, s SnapshotSettings) *App {
+	a.settingsMu.RLock()
+	settings := a.settings
+	a.settingsMu.RUnlock()
+
+	key := projectSettingsKey(rootDir)
+	summaryDirs := []SummaryOnlyDir{}
+	for _, path := range s.SummaryOnlyDirs {
+		dir := SummaryOnlyDir{Path: path}
+		for _, current := range settings.SummaryOnlyDirs[key] {
+			if current.Path == path {
+				dir.LLMSummary = current.LLMSummary // Descriptions are not part of the snapshot
+			}
+		}
+		summaryDirs = append(summaryDirs, dir)
+	}
+	settings.CustomIgnoreRules = s.CustomIgnoreRules
+	settings.ShowDotfiles = s.ShowDotfiles
+	settings.VisibleDotfiles = s.VisibleDotfiles
+	settings.ForceIncludePaths = map[string][]string{key: s.ForceIncludePaths}
+	settings.SummaryOnlyDirs = map[string][]SummaryOnlyDir{key: summaryDirs}
+	settings.IncludeTechStack = s.IncludeTechStack
+	settings.IncludeEnvironment = s.IncludeEnvironment
+	settings.ChurnInFileHeaders = s.ChurnInFileHeaders
+	settings.ContextSectionOrder = s.ContextSections
+	settings.CustomContextSections = s.CustomSections
+	settings.Attachments = map[string][]string{key: s.Attachments}
+	settings.LanguageInFileHeaders = s.LanguageInFileHeaders
+	settings.OutputFormat = s.OutputFormat
+	settings.FileOrder = s.FileOrder
+	settings.FileBlockEscaping = s.FileBlockEscaping
+	settings.LineNumbers = s.LineNumbers
+	settings.MaxFileBytes = s.MaxFileBytes
+	settings.FileCapKeepTail = s.FileCapKeepTail
+	settings.MaxFileBlockBytes = s.MaxFileBlockBytes
+	settings.GenerationTokenBudget = s.GenerationTokenBudget
+	settings.MaxTreeDepth = s.MaxTreeDepth
+	settings.MaxEntriesPerDir = s.MaxEntriesPerDir
+	settings.RedactSecrets = s.RedactSecrets
+	settings.DedupeContent = s.DedupeContent
+
+	gen := NewAppWithFS(a.projectFS)
+	gen.ctx = a.ctx
+	gen.settings = settings
+	gen.useGitignore = s.UseGitignore
+	gen.useCustomIgnore = s.UseCustomIgnore
+	gen.detached = true
+	gen.compileCustomIgnorePatterns()
+	return gen
+}
+
+// regenerateSnapshot generates the context of a snapshot again from the current files
+func (a *App) regenerateSnapshot(rootDir string, snapshot *ContextSnapshot) (string, error) {
+	gen := a.snapshotGenerator(rootDir, snapshot.Settings)
+	return gen.generateShotgunOutputWithProgress(a.ctx, rootDir, snapshot.ExcludedPaths, false, GenerationOptions{})
+}
+
+// changedSnapshotSettings returns the JSON names of the settings that differ
+func changedSnapshotSettings(before, after SnapshotSettings) []string {
+	changed := []string{}
+	bv, av := reflect.ValueOf(before), reflect.ValueOf(after)
+	for i := 0; i < bv.NumField(); i++ {
+		bf, af := bv.Field(i), av.Field(i)
+		if bf.Kind() == reflect.Slice && bf.Len() == 0 && af.Len() == 0 {
+			continue // Omitted and empty lists are the same setting
+		}
+		if !reflect.DeepEqual(bf.Interface(), af.Interface()) {
+			name := strings.Split(bv.Type().Field(i).Tag.Get("json"), ",")[0]
+			changed = append(changed, name)
+		}
+	}
+	return changed
+}
+
+// readGitRevision returns the HEAD commit of a project (empty outside git)
+func readGitRevision(ctx context.Context, rootDir string) string {
+	cmd := exec.CommandContext(ctx, "git", "rev-parse", "HEAD")
+	cmd.Dir = rootDir
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// snapshotFileBlocks returns the file blocks of generated context by path, and the text
+// before the first block (the tree)
+// XML blocks are looked for first; context without any is read as Markdown.
+func snapshotFileBlocks(content string) (map[string]string, string) {
+	blocks := make(map[string]string)
+	tree := content
+	matches := snapshotFileBlockRegex.FindAllStringSubmatchIndex(content, -1)
+	if len(matches) == 0 {
+		return markdownFileBlocks(content)
+	}
+	tree = content[:matches[0][0]]
+	for _, m := range matches {
+		blocks[fileBlockPath(content[m[2]:m[3]])] = fileBlockContent(content[m[4]:m[5]])
+	}
+	return blocks, tree
+}
+
+// markdownFileBlocks returns the Markdown file blocks of generated context by path, and the
+// text before the first block
+// A block is a "## `path`" heading followed by an optional attribute line and a code fence;
+// its content ends at the line closing that fence.
+func markdownFileBlocks(content string) (map[string]string, string) {
+	blocks := make(map[string]string)
+	tree := content
+	lines := strings.SplitAfter(content, "\n")
+	starts := make([]int, len(lines))
+	for i := 1; i < len(lines); i++ {
+		starts[i] = starts[i-1] + len(lines[i-1])
+	}
+	for i := 0; i < len(lines); i++ {
+		m := snapshotMarkdownHeadingRegex.FindStringSubmatch(strings.TrimSuffix(lines[i], "\n"))
+		if m == nil {
+			continue
+		}
+		j := i + 1
+		for j < len(lines) && (strings.TrimSpace(lines[j]) == "" || strings.HasPrefix(lines[j], "_")) {
+			j++
+		}
+		if j == len(lines) || !strings.HasPrefix(lines[j], "```") {
+			continue // Duplicate or placeholder block without content
+		}
+		opening := strings.TrimSuffix(lines[j], "\n")
+		fence := opening[:len(opening)-len(strings.TrimLeft(opening, "`"))]
+		k := j + 1
+		for k < len(lines) && strings.TrimSuffix(lines[k], "\n") != fence {
+			k++
+		}
+		if k == len(lines) {
+			continue // Unclosed fence
+		}
+		if len(blocks) == 0 {
+			tree = content[:starts[i]]
+		}
+		blocks[m[1]] = strings.Join(lines[j+1:k], "")
+		i = k
+	}
+	return blocks, tree
+}
+
+// readSnapshot loads a snapshot, with its content if withContent is set
+func (a *App) readSnapshot(rootDir, id string, withContent bool) (*ContextSnapshot, error) {
+	if id == "" || strings.ContainsAny(id, `/\.`) {
+		return nil, fmt.Errorf("invalid snapshot ID: %s", id)
+	}
+	dir := filepath.Join(a.snapshotsDir(rootDir), id)
+	data, err := os.ReadFile(filepath.Join(dir, "snapshot.json"))
+	if err != nil {
+		return nil, fmt.Errorf("snapshot not found: %s", id)
+	}
+	var snapshot ContextSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("invalid snapshot %s: %w", id, err)
+	}
+	if withContent {
+		content, err := os.ReadFile(filepath.Join(dir, "context.txt"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read snapshot content: %w", err)
+		}
+		snapshot.Content = string(content)
+	}
+	return &snapshot, nil
+}
+
+// ============================================================================
+// Context Snapshot Methods (Wails-bound)
+// ============================================================================
+
+// SaveContextSnapshot saves a generated context with its exclusions, settings and git revision
+//
+// Parameters:
+//   - rootDir: Project root directory
+//   - name: Name of the snapshot (defaults to the date)
+//   - content: Generated context
+//   - excludedPaths: Paths excluded from the generation
+//
+// Returns:
+//   - ContextSnapshot: The saved snapshot (without content)
+//   - error: Error if the content is empty or the snapshot cannot be written
+func (a *App) SaveContextSnapshot(rootDir, name, content string, excludedPaths []string) (ContextSnapshot, error) {
+	if strings.TrimSpace(content) == "" {
+		return ContextSnapshot{}, fmt.Errorf("no context to save")
+	}
+	if a.configPath == "" {
+		return ContextSnapshot{}, fmt.Errorf("config directory not available")
+	}
+	now := time.Now()
+	if strings.TrimSpace(name) == "" {
+		name = now.Format("2006-01-02 15:04")
+	}
+	if excludedPaths == nil {
+		excludedPaths = []string{}
+	}
+	blocks, _ := snapshotFileBlocks(content)
+	snapshot := ContextSnapshot{
+		ID:            fmt.Sprintf("%d", now.UnixNano()),
+		Name:          strings.TrimSpace(name),
+		RootDir:       rootDir,
+		CreatedAt:     now,
+		ExcludedPaths: excludedPaths,
+		Settings:      a.currentSnapshotSettings(rootDir),
+		GitRevision:   readGitRevision(a.ctx, rootDir),
+		GitDirty:      len(readGitStatus(a.ctx, rootDir)) > 0,
+		Files:         len(blocks),
+		Tokens:        a.EstimateTokens(content),
+	}
+
+	dir := filepath.Join(a.snapshotsDir(rootDir), snapshot.ID)
+	meta, err := json.MarshalIndent(snapshot, "", "  ")
+	if err == nil {
+		err = os.MkdirAll(dir, 0755)
+	}
+	if err == nil {
+		err = os.WriteFile(filepath.Join(dir, "context.txt"), []byte(content), 0644)
+	}
+	if err == nil {
+		// Metadata last, so a listed snapshot always has its content
+		err = os.WriteFile(filepath.Join(dir, "snapshot.json"), meta, 0644)
+	}
+	if err != nil {
+		os.RemoveAll(dir)
+		return ContextSnapshot{}, fmt.Errorf("failed to save snapshot: %w", err)
+	}
+	runtime.LogInfof(a.ctx, "Saved context snapshot %q for %s (%d files, ~%d tokens)", snapshot.Name, rootDir, snapshot.Files, snapshot.Tokens)
+	return snapshot, nil
+}
+
+// ListContextSnapshots returns the snapshots of a project, newest first (without content)
+func (a *App) ListContextSnapshots(rootDir string) []ContextSnapshot {
+	snapshots := []ContextSnapshot{}
+	entries, err := os.ReadDir(a.snapshotsDir(rootDir))
+	if err != nil {
+		return snapshots
+	}
+	for _, entry := range entries {
+		if snapshot, err := a.readSnapshot(rootDir, entry.Name(), false); err == nil {
+			snapshots = append(snapshots, *snapshot)
+		}
+	}
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].CreatedAt.After(snapshots[j].CreatedAt) })
+	return snapshots
+}
+
+// GetContextSnapshot returns a snapshot with its content
+func (a *App) GetContextSnapshot(rootDir, id string) (ContextSnapshot, error) {
+	snapshot, err := a.readSnapshot(rootDir, id, true)
+	if err != nil {
+		return ContextSnapshot{}, err
+	}
+	return *snapshot, nil
+}
+
+// DeleteContextSnapshot deletes a snapshot
+func (a *App) DeleteContextSnapshot(rootDir, id string) error {
+	if _, err := a.readSnapshot(rootDir, id, false); err != nil {
+		return err
+	}
+	if err := os.RemoveAll(filepath.Join(a.snapshotsDir(rootDir), id)); err != nil {
+		return fmt.Errorf("failed to delete snapshot: %w", err)
+	}
+	return nil
+}
+
+// RegenerateContextSnapshot generates the context of a snapshot again from the current files
+// The snapshot's exclusions and settings are used; the current settings are left unchanged.
+//
+// Parameters:
+//   - rootDir: Project root directory
+//   - id: Snapshot ID
+//
+// Returns:
+//   - string: Regenerated context
+//   - error: Error if the snapshot does not exist or generation fails
+func (a *App) RegenerateContextSnapshot(rootDir, id string) (string, error) {
+	snapshot, err := a.readSnapshot(rootDir, id, false)
+	if err != nil {
+		return "", err
+	}
+	return a.regenerateSnapshot(rootDir, snapshot)
+}
+
+// CompareContextSnapshot regenerates a snapshot and reports what changed since it was saved
+//
+// Parameters:
+//   - rootDir: Project root directory
+//   - id: Snapshot ID
+//
+// Returns:
+//   - SnapshotComparison: Changed files, revision, and current settings that differ from the snapshot's
+//   - error: Error if the snapshot does not exist or generation fails
+func (a *App) CompareContextSnapshot(rootDir, id string) (SnapshotComparison, error) {
+	snapshot, err := a.readSnapshot(rootDir, id, true)
+	if err != nil {
+		return SnapshotComparison{}, err
+	}
+	current, err := a.regenerateSnapshot(rootDir, snapshot)
+	if err != nil {
+		return SnapshotComparison{}, fmt.Errorf("failed to regenerate context: %w", err)
+	}
+
+	cmp := SnapshotComparison{
+		SnapshotID:      snapshot.ID,
+		Identical:       current == snapshot.Content,
+		GitRevision:     readGitRevision(a.ctx, rootDir),
+		GitDirty:        len(readGitStatus(a.ctx, rootDir)) > 0,
+		SettingsChanged: changedSnapshotSettings(snapshot.Settings, a.currentSnapshotSettings(rootDir)),
+		AddedFiles:      []string{},
+		RemovedFiles:    []string{},
+		ChangedFiles:    []string{},
+		SnapshotTokens:  snapshot.Tokens,
+		CurrentTokens:   a.EstimateTokens(current),
+	}
+	cmp.RevisionChanged = cmp.GitRevision != snapshot.GitRevision
+
+	before, beforeTree := snapshotFileBlocks(snapshot.Content)
+	after, afterTree := snapshotFileBlocks(current)
+	cmp.TreeChanged = beforeTree != afterTree
+	for path, content := range after {
+		old, ok := before[path]
+		if !ok {
+			cmp.AddedFiles = append(cmp.AddedFiles, path)
+		} else if old != content {
+			cmp.ChangedFiles = append(cmp.ChangedFiles, path)
+		}
+	}
+	for path := range before {
+		if _, ok := after[path]; !ok {
+			cmp.RemovedFiles = append(cmp.RemovedFiles, path)
+		}
+	}
+	sort.Strings(cmp.AddedFiles)
+	sort.Strings(cmp.RemovedFiles)
+	sort.Strings(cmp.ChangedFiles)
+
+	runtime.LogInfof(a.ctx, "Compared snapshot %s: %d added, %d removed, %d changed files",
+		snapshot.ID, len(cmp.AddedFiles), len(cmp.RemovedFiles), len(cmp.ChangedFiles))
+	return cmp, nil
+}
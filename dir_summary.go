@@ -0,0 +1,416 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- Summary-Only Directories ---
+//
+// A directory marked "summary only" stays in the tree (and in the tree part of generated
+// context), but instead of the contents of its files, generation emits one paragraph about
+// it: file count, estimated tokens, languages and key file names. This keeps large areas of
+// a project (vendored code, generated clients, fixtures) visible to the model without their
+// token cost. A directory_summary job can add a short LLM-written description, which is
+// saved with the mark and included in the paragraph.
+
+const (
+	maxSummaryKeyFiles  = 8   // Key file names listed in a directory summary
+	maxSummaryLanguages = 4   // Languages listed in a directory summary
+	maxSummaryListing   = 200 // File paths sent to the LLM when describing a directory
+	summaryExcerptLines = 40  // Lines of each key file sent to the LLM
+)
+
+// summaryKeyFileNames are file names that tell the most about a directory, best first
+var summaryKeyFileNames = []string{
+	"README.md", "README", "README.rst", "README.txt", "package.json", "go.mod", "Cargo.toml",
+	"pyproject.toml", "setup.py", "pom.xml", "build.gradle", "Makefile", "Dockerfile",
+	"CMakeLists.txt", "main.go", "main.py", "main.rs", "index.js", "index.ts", "__init__.py",
+}
+
+// directorySummaryPromptTemplate asks for the description added to a summary-only directory
+const directorySummaryPromptTemplate = `Describe in 2-4 sentences what the directory %s of a software project contains and what it is for, so a developer knows when they would need to look inside it. Answer with the description only.
+
+%s
+
+Files:
+%s
+%s`
+
+// SummaryOnlyDir is a directory generated as a summary instead of its file contents
+type SummaryOnlyDir struct {
+	Path       string `json:"path"`                 // Directory path relative to the project root (forward slashes)
+	LLMSummary string `json:"llmSummary,omitempty"` // Description written by a directory_summary job (empty if none)
+}
+
+// DirectorySummary describes a summary-only directory
+type DirectorySummary struct {
+	Path            string   `json:"path"`            // Directory path relative to the project root (forward slashes)
+	Files           int      `json:"files"`           // Files included by the ignore rules
+	EstimatedTokens int      `json:"estimatedTokens"` // Approximate tokens of the files (bytes / 4)
+	Languages       []string `json:"languages"`       // Main languages with their share, e.g. "Go 80%"
+	KeyFiles        []string `json:"keyFiles"`        // Most telling files, relative to the directory
+	LLMSummary      string   `json:"llmSummary"`      // Saved LLM description (empty if none)
+}
+
+// DirectorySummaryOptions are the parameters of a directory_summary job
+type DirectorySummaryOptions struct {
+	Provider string `json:"provider"` // LLM provider (google, openai, anthropic, custom)
+	APIKey   string `json:"apiKey"`   // API key for the provider
+	Model    string `json:"model"`    // Model name (empty for provider default)
+	BaseURL  string `json:"baseURL"`  // Base URL for the custom provider
+	RootDir  string `json:"rootDir"`  // Project root directory
+	Path     string `json:"path"`     // Summary-only directory, relative to the root
+}
+
+// summaryOnlySet returns the summary-only directories of a project as a set of
+// normalized relative paths
+func (a *App) summaryOnlySet(rootDir string) map[string]bool {
+	set := make(map[string]bool)
+	for _, dir := range a.settings.SummaryOnlyDirs[projectSettingsKey(rootDir)] {
+		set[normalizeRelPath(dir.Path)] = true
+	}
+	return set
+}
+
+// savedDirSummary returns the saved LLM description of a summary-only directory
+func (a *App) savedDirSummary(rootDir, relPath string) string {
+	normalized := normalizeRelPath(relPath)
+	for _, dir := range a.settings.SummaryOnlyDirs[projectSettingsKey(rootDir)] {
+		if normalizeRelPath(dir.Path) == normalized {
+			return dir.LLMSummary
+		}
+	}
+	return ""
+}
+
+// summarizeDirectory collects the numbers of a summary-only directory
+// Files excluded by the user or the ignore rules are not counted.
+//
+// Parameters:
+//   - ctx: Context for cancellation
+//   - rootDir: Project root directory
+//   - relPath: Directory relative to the root (OS separators)
+//   - excluded: Reports whether a relative path was excluded by the user (nil for none)
+//   - opts: Ignore rules of the project
+//
+// Returns:
+//   - DirectorySummary: Counts, languages and key files
+//   - map[string]int64: Sizes of the counted files, keyed by path relative to the directory
+//   - error: Error if the walk was cancelled
+func (a *App) summarizeDirectory(ctx context.Context, rootDir, relPath string, excluded func(string) bool, opts *treeBuildOptions) (DirectorySummary, map[string]int64, error) {
+	summary := DirectorySummary{
+		Path:       filepath.ToSlash(relPath),
+		Languages:  []string{},
+		KeyFiles:   []string{},
+		LLMSummary: a.savedDirSummary(rootDir, relPath),
+	}
+	dirPath := filepath.Join(rootDir, relPath)
+	sizes := make(map[string]int64)
+	langBytes := make(map[string]int64)
+	var totalBytes int64
+
+	err := a.projectFS.WalkDir(dirPath, func(path string, d fs.DirEntry, walkErr error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if walkErr != nil || path == dirPath {
+			if walkErr != nil && d != nil && d.IsDir() && path != dirPath {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, _ := filepath.Rel(rootDir, path)
+		if (excluded != nil && excluded(rel)) || opts.excludes(rel, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		var size int64
+		if info, err := d.Info(); err == nil {
+			size = info.Size()
+		}
+		inDir, _ := filepath.Rel(dirPath, path)
+		sizes[filepath.ToSlash(inDir)] = size
+		langBytes[detectLanguage(rel)] += size
+		totalBytes += size
+		return nil
+	})
+	if err != nil {
+		return summary, nil, err
+	}
+
+	summary.Files = len(sizes)
+	summary.EstimatedTokens = int(totalBytes / 4)
+
+	langs := make([]string, 0, len(langBytes))
+	for lang := range langBytes {
+		langs = append(langs, lang)
+	}
+	sort.Slice(langs, func(i, j int) bool { return langBytes[langs[i]] > langBytes[langs[j]] })
+	for _, lang := range langs {
+		if len(summary.Languages) == maxSummaryLanguages || totalBytes == 0 {
+			break
+		}
+		if share := langBytes[lang] * 100 / totalBytes; share > 0 {
+			summary.Languages = append(summary.Languages, fmt.Sprintf("%s %d%%", lang, share))
+		}
+	}
+
+	summary.KeyFiles = summaryKeyFiles(sizes)
+	return summary, sizes, nil
+}
+
+// summaryKeyFiles picks the most telling files of a directory: well-known names closest to
+// the top first, then the largest files
+func summaryKeyFiles(sizes map[string]int64) []string {
+	rank := make(map[string]int, len(summaryKeyFileNames))
+	for i, name := range summaryKeyFileNames {
+		rank[name] = i
+	}
+	paths := make([]string, 0, len(sizes))
+	for p := range sizes {
+		paths = append(paths, p)
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		ri, iKnown := rank[filepath.Base(paths[i])]
+		rj, jKnown := rank[filepath.Base(paths[j])]
+		if iKnown != jKnown {
+			return iKnown
+		}
+		if iKnown {
+			di, dj := strings.Count(paths[i], "/"), strings.Count(paths[j], "/")
+			if di != dj {
+				return di < dj
+			}
+			if ri != rj {
+				return ri < rj
+			}
+		} else if sizes[paths[i]] != sizes[paths[j]] {
+			return sizes[paths[i]] > sizes[paths[j]]
+		}
+		return paths[i] < paths[j]
+	})
+	if len(paths) > maxSummaryKeyFiles {
+		paths = paths[:maxSummaryKeyFiles]
+	}
+	return paths
+}
+
+// directorySummaryText renders the paragraph of a summary
+func directorySummaryText(summary DirectorySummary) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Contents omitted (summary only): %d files, ~%d tokens.", summary.Files, summary.EstimatedTokens)
+	if len(summary.Languages) > 0 {
+		fmt.Fprintf(&b, " Languages: %s.", strings.Join(summary.Languages, ", "))
+	}
+	if len(summary.KeyFiles) > 0 {
+		fmt.Fprintf(&b, " Key files: %s.", strings.Join(summary.KeyFiles, ", "))
+	}
+	if text := strings.TrimSpace(summary.LLMSummary); text != "" {
+		b.WriteString(" " + text)
+	}
+	return b.String()
+}
+
+// directorySummaryBlock renders the context block that replaces the files of a summary-only directory
+func directorySummaryBlock(summary DirectorySummary) string {
+	return fmt.Sprintf("<directory path=\"%s\" summary-only=\"true\">\n%s\n</directory>\n", fileBlockAttr(summary.Path), directorySummaryText(summary))
+}
+
+// executeDirectorySummaryJob implements the directory_summary job type
+func (a *App) executeDirectorySummaryJob(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var p DirectorySummaryOptions
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, fmt.Errorf("invalid directory_summary parameters: %w", err)
+	}
+	if err := a.validateContentRoot(p.RootDir); err != nil {
+		return nil, err
+	}
+	relPath := normalizeRelPath(p.Path)
+	opts := a.newTreeBuildOptions(p.RootDir, a.compileProjectGitignore(p.RootDir))
+	summary, sizes, err := a.summarizeDirectory(ctx, p.RootDir, relPath, nil, opts)
+	if err != nil {
+		return nil, err
+	}
+	summary.LLMSummary = "" // Describe the directory from scratch
+
+	listing := make([]string, 0, len(sizes))
+	for path := range sizes {
+		listing = append(listing, path)
+	}
+	sort.Strings(listing)
+	if len(listing) > maxSummaryListing {
+		listing = append(listing[:maxSummaryListing], fmt.Sprintf("... and %d more", len(sizes)-maxSummaryListing))
+	}
+	var excerpts strings.Builder
+	for _, keyFile := range summary.KeyFiles {
+		path := filepath.Join(p.RootDir, relPath, filepath.FromSlash(keyFile))
+		if isBinary, err := a.isBinaryFile(path); err != nil || isBinary {
+			continue
+		}
+		content, err := a.projectFS.ReadFile(path)
+		if err != nil || !utf8.Valid(content) {
+			continue
+		}
+		lines := strings.SplitN(string(content), "\n", summaryExcerptLines+1)
+		if len(lines) > summaryExcerptLines {
+			lines = lines[:summaryExcerptLines]
+		}
+		excerpt := a.redactSecretsIfEnabled(keyFile, strings.Join(lines, "\n"))
+		excerpts.WriteString("\n" + fileBlock(keyFile, "", excerpt, a.fileBlockEscaping()))
+	}
+
+	prompt := fmt.Sprintf(directorySummaryPromptTemplate, summary.Path, directorySummaryText(summary),
+		strings.Join(listing, "\n"), excerpts.String())
+	a.tagJobProject(ctx, p.RootDir)
+	resp, err := NewLLMClient(a).CallLLM(ctx, LLMRequest{Provider: p.Provider, APIKey: p.APIKey, Model: p.Model, BaseURL: p.BaseURL, Prompt: prompt})
+	if err != nil {
+		return nil, err
+	}
+	a.recordUsage(llmCallEvent(p.RootDir, resp))
+
+	summary.LLMSummary = strings.TrimSpace(a.postProcess(resp.Content))
+	if err := a.saveDirSummary(p.RootDir, relPath, summary.LLMSummary); err != nil {
+		return nil, err
+	}
+	runtime.EventsEmit(a.ctx, "directorySummaryReady", summary)
+	return summary, nil
+}
+
+// saveDirSummary stores the LLM description of a summary-only directory
+func (a *App) saveDirSummary(rootDir, relPath, text string) error {
+	key := projectSettingsKey(rootDir)
+	normalized := normalizeRelPath(relPath)
+	dirs := a.settings.SummaryOnlyDirs[key]
+	for i := range dirs {
+		if normalizeRelPath(dirs[i].Path) == normalized {
+			dirs[i].LLMSummary = text
+			if err := a.saveSettings(); err != nil {
+				return fmt.Errorf("failed to save directory summary: %w", err)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("directory is not summary-only: %s", filepath.ToSlash(normalized))
+}
+
+// ============================================================================
+// Summary-Only Directory Methods (Wails-bound)
+// ============================================================================
+
+// GetSummaryOnlyDirs returns the summary-only directories of a project
+//
+// Parameters:
+//   - rootDir: Project root directory
+//
+// Returns:
+//   - []SummaryOnlyDir: Directories with their saved LLM descriptions, sorted by path
+func (a *App) GetSummaryOnlyDirs(rootDir string) []SummaryOnlyDir {
+	dirs := append([]SummaryOnlyDir{}, a.settings.SummaryOnlyDirs[projectSettingsKey(rootDir)]...)
+	sort.Slice(dirs, func(i, j int) bool { return dirs[i].Path < dirs[j].Path })
+	return dirs
+}
+
+// SetSummaryOnly marks or unmarks a directory as summary only
+// Unmarking a directory discards its saved LLM description.
+//
+// Parameters:
+//   - rootDir: Project root directory
+//   - relPath: Directory path relative to the root
+//   - enabled: True to generate the directory as a summary, false to include its files again
+//
+// Returns:
+//   - error: Error if the path is not a directory of the project or settings cannot be saved
+func (a *App) SetSummaryOnly(rootDir, relPath string, enabled bool) error {
+	if strings.TrimSpace(rootDir) == "" {
+		return fmt.Errorf("no project folder specified")
+	}
+	normalized := normalizeRelPath(relPath)
+	if normalized == "." || filepath.IsAbs(normalized) || strings.HasPrefix(normalized, "..") {
+		return fmt.Errorf("invalid project-relative path: %s", relPath)
+	}
+	if enabled && !a.projectIsDir(filepath.Join(rootDir, normalized)) {
+		return fmt.Errorf("not a directory: %s", relPath)
+	}
+
+	key := projectSettingsKey(rootDir)
+	dirs := []SummaryOnlyDir{}
+	for _, dir := range a.settings.SummaryOnlyDirs[key] {
+		if normalizeRelPath(dir.Path) != normalized {
+			dirs = append(dirs, dir)
+		}
+	}
+	if enabled {
+		dirs = append(dirs, SummaryOnlyDir{Path: filepath.ToSlash(normalized), LLMSummary: a.savedDirSummary(rootDir, normalized)})
+	}
+
+	if a.settings.SummaryOnlyDirs == nil {
+		a.settings.SummaryOnlyDirs = make(map[string][]SummaryOnlyDir)
+	}
+	if len(dirs) == 0 {
+		delete(a.settings.SummaryOnlyDirs, key)
+	} else {
+		a.settings.SummaryOnlyDirs[key] = dirs
+	}
+
+	if err := a.saveSettings(); err != nil {
+		return fmt.Errorf("failed to save summary-only directories: %w", err)
+	}
+	runtime.LogInfof(a.ctx, "Summary only for %s in %s set to %v", filepath.ToSlash(normalized), key, enabled)
+	a.notifyFileChange(rootDir)
+	return nil
+}
+
+// GetDirectorySummary returns the paragraph generation would emit for a directory
+//
+// Parameters:
+//   - rootDir: Project root directory
+//   - relPath: Directory path relative to the root
+//
+// Returns:
+//   - DirectorySummary: Counts, languages, key files and saved LLM description
+//   - error: Error if the path is not a directory of the project
+func (a *App) GetDirectorySummary(rootDir, relPath string) (DirectorySummary, error) {
+	if err := a.validateContentRoot(rootDir); err != nil {
+		return DirectorySummary{}, err
+	}
+	normalized := normalizeRelPath(relPath)
+	if normalized == "." || filepath.IsAbs(normalized) || strings.HasPrefix(normalized, "..") || !a.projectIsDir(filepath.Join(rootDir, normalized)) {
+		return DirectorySummary{}, fmt.Errorf("not a directory of the project: %s", relPath)
+	}
+	opts := a.newTreeBuildOptions(rootDir, a.compileProjectGitignore(rootDir))
+	summary, _, err := a.summarizeDirectory(a.ctx, rootDir, normalized, nil, opts)
+	return summary, err
+}
+
+// StartDirectorySummary asks an LLM to describe a summary-only directory in the background
+// The description is saved with the mark and emitted as a "directorySummaryReady" event.
+//
+// Parameters:
+//   - opts: Provider settings, project root and directory
+//
+// Returns:
+//   - string: Job ID of the directory_summary job
+//   - error: Error if the directory is not summary-only or the job cannot be enqueued
+func (a *App) StartDirectorySummary(opts DirectorySummaryOptions) (string, error) {
+	if a.jobQueue == nil {
+		return "", fmt.Errorf("job queue not initialized")
+	}
+	if !a.summaryOnlySet(opts.RootDir)[normalizeRelPath(opts.Path)] {
+		return "", fmt.Errorf("directory is not summary-only: %s", opts.Path)
+	}
+	return a.jobQueue.Enqueue("directory_summary", opts)
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"os/exec"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- Docker Containers as Projects ---
+//
+// A directory inside a container can be opened as a read-only project, e.g. to look at the
+// code baked into an image whose source isn't checked out locally. In a running container
+// with a shell and GNU find, the tree is listed with docker exec and files are fetched with
+// cat when first read (the same lazy loading as SSH projects, see remote_ssh.go). Otherwise
+// (stopped, distroless or busybox-based containers) the directory is copied out once with
+// docker cp, which streams a tar archive that is held in memory like an archive project.
+
+// containerNameRegex matches docker container names and IDs
+var containerNameRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+// dockerRun runs a docker command and returns its standard output
+func dockerRun(ctx context.Context, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "docker", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("docker %s: %v: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+// listContainerDir lists a container directory with docker exec and find
+func listContainerDir(container, dir string, pruneNames []string) (*memFS, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), remoteListTimeout)
+	defer cancel()
+	out, err := dockerRun(ctx, "exec", container, "sh", "-c", remoteFindCommand(dir, pruneNames))
+	if err != nil {
+		return nil, err
+	}
+	return parseRemoteListing(out, func(name string) ([]byte, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), remoteReadTimeout)
+		defer cancel()
+		return dockerRun(ctx, "exec", container, "cat", "--", path.Join(dir, name))
+	})
+}
+
+// copyContainerDir copies a container directory into memory with docker cp
+func copyContainerDir(container, dir string, pruneNames []string) (fs.FS, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), remoteListTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "docker", "cp", container+":"+dir, "-")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to run docker: %w", err)
+	}
+	pruned := make(map[string]bool, len(pruneNames))
+	for _, name := range pruneNames {
+		pruned[name] = true
+	}
+	mfs, readErr := readTarFS(stdout, func(name string) bool { return pruned[name] })
+	if readErr != nil {
+		cmd.Process.Kill()
+	}
+	if err := cmd.Wait(); err != nil && readErr == nil {
+		return nil, fmt.Errorf("docker cp: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	if readErr != nil {
+		return nil, readErr
+	}
+	// docker cp wraps the contents in a folder named after the directory
+	if top, ok := singleTopLevelDir(mfs); ok {
+		return fs.Sub(mfs, top)
+	}
+	return mfs, nil
+}
+
+// ============================================================================
+// Docker Project Methods (Wails-bound)
+// ============================================================================
+
+// ListDockerContainers returns the names of the running containers
+func (a *App) ListDockerContainers() ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), remoteReadTimeout)
+	defer cancel()
+	out, err := dockerRun(ctx, "ps", "--format", "{{.Names}}")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+	names := []string{}
+	for _, line := range strings.Split(string(out), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			names = append(names, line)
+		}
+	}
+	return names, nil
+}
+
+// OpenDockerProject mounts a directory of a container as a read-only project
+//
+// Parameters:
+//   - container: Container name or ID (running or stopped)
+//   - dir: Absolute directory inside the container (e.g. /app)
+//
+// Returns:
+//   - string: Project root to use with ListFiles and generation (e.g. docker:/api/app)
+//   - error: Error if the container or directory cannot be read
+func (a *App) OpenDockerProject(container string, dir string) (string, error) {
+	container = strings.TrimSpace(container)
+	if !containerNameRegex.MatchString(container) {
+		return "", fmt.Errorf("invalid container name: %q", container)
+	}
+	dir = path.Clean("/" + strings.TrimSpace(dir))
+	if _, err := exec.LookPath("docker"); err != nil {
+		return "", fmt.Errorf("docker is not installed or not in PATH")
+	}
+
+	pruneNames := a.remotePruneNames()
+	var fsys fs.FS
+	mode := "exec"
+	mfs, err := listContainerDir(container, dir, pruneNames)
+	if err == nil {
+		fsys = mfs
+	} else {
+		runtime.LogInfof(a.ctx, "Listing %s:%s with docker exec failed, copying it instead: %v", container, dir, err)
+		mode = "cp"
+		fsys, err = copyContainerDir(container, dir, pruneNames)
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s:%s: %w", container, dir, err)
+		}
+	}
+
+	rootDir := remoteProjectRoot("docker", container, dir)
+	mountProject(&mountedProject{root: rootDir, fsys: fsys, kind: "docker", source: container + ":" + dir})
+	runtime.LogInfof(a.ctx, "Mounted %s:%s as a read-only project (docker %s)", container, dir, mode)
+	return rootDir, nil
+}
+
+// CloseDockerProject unmounts a container project
+func (a *App) CloseDockerProject(rootDir string) error {
+	if !a.closeMountedProject(rootDir) {
+		return fmt.Errorf("no container project is open at %s", rootDir)
+	}
+	runtime.LogInfof(a.ctx, "Closed container project %s", rootDir)
+	return nil
+}
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- Duplicate Content Detection ---
+//
+// Repositories often carry the same file several times: vendored copies, generated code
+// checked in per target, copied fixtures. Generation hashes the content of each file and
+// reports identical files with "duplicateContentDetected". With the DedupeContent setting on,
+// only the first copy (in tree order) is included; later copies become a one-line reference:
+//   <file path="b/util.js" duplicate-of="a/util.js" />
+// The event also carries the bytes and estimated tokens the references save (or would save
+// with DedupeContent off), totaled over all groups.
+//
+// FindDuplicateFiles also finds near-identical files of a selection (same extension, similar
+// lines) so they can be excluded by hand. Files under minDuplicateSize are ignored: empty
+// __init__.py files and the like are not worth a reference.
+
+const (
+	minDuplicateSize           = 256  // Files smaller than this are never reported as duplicates
+	defaultSimilarityThreshold = 0.9  // Share of common lines for near-identical files
+	maxSimilarityFiles         = 5000 // Files compared for near-identical content at most
+)
+
+// DuplicateGroup is a set of files with the same or nearly the same content
+type DuplicateGroup struct {
+	Canonical   string   `json:"canonical"`   // File kept (first in tree order, forward slashes)
+	Duplicates  []string `json:"duplicates"`  // Other files with the same content (forward slashes)
+	Identical   bool     `json:"identical"`   // False for near-identical files
+	Similarity  float64  `json:"similarity"`  // Lowest similarity to the canonical file, 0-1 (1 if identical)
+	TokensSaved int      `json:"tokensSaved"` // Estimated tokens saved by keeping only the canonical file
+	BytesSaved  int      `json:"bytesSaved"`  // Content bytes saved by keeping only the canonical file
+}
+
+// duplicateTracker finds identical file blocks during one generation
+type duplicateTracker struct {
+	dedupe   bool                       // Replace duplicate blocks by a reference
+	byHash   map[[32]byte]string        // Canonical file by content hash
+	groups   map[string]*DuplicateGroup // Groups by canonical file
+	canonSeq []string                   // Canonical files in the order their first duplicate was found
+}
+
+// newDuplicateTracker creates the tracker of a generation
+func (a *App) newDuplicateTracker() *duplicateTracker {
+	return &duplicateTracker{
+		dedupe: a.settings.DedupeContent,
+		byHash: make(map[[32]byte]string),
+		groups: make(map[string]*DuplicateGroup),
+	}
+}
+
+// record records the content of a file, returning the earlier file with the same content
+// ("" if there is none or the file is too small to count)
+func (t *duplicateTracker) record(relPath, content string) string {
+	if len(content) < minDuplicateSize {
+		return ""
+	}
+	hash := sha256.Sum256([]byte(content))
+	canonical, seen := t.byHash[hash]
+	if !seen {
+		t.byHash[hash] = relPath
+		return ""
+	}
+	group, ok := t.groups[canonical]
+	if !ok {
+		group = &DuplicateGroup{Canonical: canonical, Identical: true, Similarity: 1}
+		t.groups[canonical] = group
+		t.canonSeq = append(t.canonSeq, canonical)
+	}
+	group.Duplicates = append(group.Duplicates, relPath)
+	group.TokensSaved += len(content) / 4
+	group.BytesSaved += len(content)
+	return canonical
+}
+
+// apply records a generated file block, returning the block to include
+func (t *duplicateTracker) apply(relPath, block string) string {
+	_, body, found := strings.Cut(block, "\n")
+	if !found {
+		return block // Placeholder for a skipped file
+	}
+	if canonical := t.record(relPath, body); canonical != "" && t.dedupe {
+		return fmt.Sprintf("<file path=\"%s\" duplicate-of=\"%s\" />\n", fileBlockAttr(relPath), fileBlockAttr(canonical))
+	}
+	return block
+}
+
+// report returns the duplicate groups found, in order of discovery
+func (t *duplicateTracker) report() []DuplicateGroup {
+	groups := make([]DuplicateGroup, 0, len(t.canonSeq))
+	for _, canonical := range t.canonSeq {
+		groups = append(groups, *t.groups[canonical])
+	}
+	return groups
+}
+
+// saved returns the content bytes and estimated tokens all duplicate groups save
+func (t *duplicateTracker) saved() (int, int) {
+	bytes, tokens := 0, 0
+	for _, group := range t.groups {
+		bytes += group.BytesSaved
+		tokens += group.TokensSaved
+	}
+	return bytes, tokens
+}
+
+// lineSet returns the distinct non-blank lines of content, trimmed
+func lineSet(content string) map[string]bool {
+	lines := make(map[string]bool)
+	for _, line := range strings.Split(content, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines[line] = true
+		}
+	}
+	return lines
+}
+
+// lineSimilarity returns the Jaccard similarity of two line sets
+func lineSimilarity(a, b map[string]bool) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1
+	}
+	common := 0
+	for line := range a {
+		if b[line] {
+			common++
+		}
+	}
+	return float64(common) / float64(len(a)+len(b)-common)
+}
+
+// ============================================================================
+// Duplicate Content Methods (Wails-bound)
+// ============================================================================
+
+// GetDedupeContent returns whether generation includes identical files only once
+func (a *App) GetDedupeContent() bool {
+	return a.settings.DedupeContent
+}
+
+// SetDedupeContent sets whether generation includes identical files only once and saves it
+func (a *App) SetDedupeContent(enabled bool) error {
+	a.settings.DedupeContent = enabled
+	if err := a.saveSettings(); err != nil {
+		return fmt.Errorf("failed to save dedupe content setting: %w", err)
+	}
+	runtime.LogInfof(a.ctx, "Dedupe content: %v", enabled)
+	return nil
+}
+
+// FindDuplicateFiles finds identical and near-identical files in a selection
+//
+// Parameters:
+//   - rootDir: Project root directory
+//   - excludedPaths: The selection, expressed as exclusions
+//   - threshold: Similarity from which files count as near-identical, 0-1 (0 for the default)
+//
+// Returns:
+//   - []DuplicateGroup: Identical groups first, then near-identical ones, by tokens saved
+//   - error: Error if the threshold is out of range or the project cannot be walked
+func (a *App) FindDuplicateFiles(rootDir string, excludedPaths []string, threshold float64) ([]DuplicateGroup, error) {
+	if threshold == 0 {
+		threshold = defaultSimilarityThreshold
+	}
+	if threshold < 0 || threshold > 1 {
+		return nil, fmt.Errorf("threshold must be between 0 and 1, got %v", threshold)
+	}
+	if err := a.validateContentRoot(rootDir); err != nil {
+		return nil, err
+	}
+	entries, _, err := a.walkSelectionEntries(rootDir)
+	if err != nil {
+		return nil, err
+	}
+	excluded := make(map[string]bool, len(excludedPaths))
+	for _, p := range excludedPaths {
+		excluded[p] = true
+	}
+
+	// Exact duplicates by hash, in tree order
+	tracker := a.newDuplicateTracker()
+	type candidate struct {
+		relPath string
+		lines   map[string]bool
+		size    int
+	}
+	var candidates []candidate
+	for _, entry := range entries {
+		if entry.isDir || entry.size < minDuplicateSize || entry.size > maxImportScanSize ||
+			excludedBySelection(excluded, filepath.FromSlash(entry.relPath)) {
+			continue
+		}
+		if isBinary, err := a.isBinaryFileCached(entry.absPath); err != nil || isBinary {
+			continue
+		}
+		data, err := a.projectFS.ReadFile(entry.absPath)
+		if err != nil {
+			continue
+		}
+		content := string(data)
+		if tracker.record(entry.relPath, content) == "" && len(candidates) < maxSimilarityFiles {
+			candidates = append(candidates, candidate{entry.relPath, lineSet(content), len(content)})
+		}
+	}
+	groups := tracker.report()
+
+	// Near-identical files among the remaining ones, compared within each extension
+	grouped := make(map[string]bool)
+	var similar []DuplicateGroup
+	for i, c := range candidates {
+		if grouped[c.relPath] {
+			continue
+		}
+		group := DuplicateGroup{Canonical: c.relPath, Similarity: 1}
+		for _, other := range candidates[i+1:] {
+			if grouped[other.relPath] || path.Ext(other.relPath) != path.Ext(c.relPath) {
+				continue
+			}
+			// Line sets cannot reach the threshold if the sizes differ too much
+			small, large := c.size, other.size
+			if small > large {
+				small, large = large, small
+			}
+			if float64(small) < threshold*float64(large)*0.5 {
+				continue
+			}
+			if similarity := lineSimilarity(c.lines, other.lines); similarity >= threshold {
+				grouped[other.relPath] = true
+				group.Duplicates = append(group.Duplicates, other.relPath)
+				group.TokensSaved += other.size / 4
+				group.BytesSaved += other.size
+				if similarity < group.Similarity {
+					group.Similarity = similarity
+				}
+			}
+		}
+		if len(group.Duplicates) > 0 {
+			similar = append(similar, group)
+		}
+	}
+	sort.SliceStable(groups, func(i, j int) bool { return groups[i].TokensSaved > groups[j].TokensSaved })
+	sort.SliceStable(similar, func(i, j int) bool { return similar[i].TokensSaved > similar[j].TokensSaved })
+	groups = append(groups, similar...)
+
+	runtime.LogInfof(a.ctx, "Found %d duplicate groups in %s", len(groups), rootDir)
+	return groups, nil
+}
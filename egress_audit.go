@@ -0,0 +1,257 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- Egress Audit Log ---
+//
+// Every request that sends project data off the machine (LLM calls, continuations and tool
+// loop steps, gist and paste uploads) appends one entry to <config dir>/egress_audit.jsonl:
+// where the data went, how much of it, for which project, and what was redacted from it. The
+// content itself is never recorded. The log is append-only: the app has no binding to edit
+// or clear it, so organizations can review what code context has been shared externally.
+
+// EgressAuditEntry records one request that sent data off the machine
+type EgressAuditEntry struct {
+	Timestamp   time.Time      `json:"timestamp"`            // When the request finished
+	Destination string         `json:"destination"`          // llm, gist or paste
+	Provider    string         `json:"provider,omitempty"`   // LLM provider (llm)
+	Model       string         `json:"model,omitempty"`      // LLM model (llm)
+	Host        string         `json:"host"`                 // Host the data was sent to
+	Project     string         `json:"project"`              // Project root the data came from (empty if unknown)
+	Bytes       int            `json:"bytes"`                // Bytes of content sent
+	Tokens      int            `json:"tokens"`               // Estimated tokens of content sent
+	Status      string         `json:"status"`               // sent or failed
+	Redactions  map[string]int `json:"redactions,omitempty"` // Matches redacted from the content before sending, by filter
+}
+
+// egressAuditPath returns the path of the egress audit log
+func (a *App) egressAuditPath() string {
+	return filepath.Join(filepath.Dir(a.configPath), "egress_audit.jsonl")
+}
+
+// urlHost returns the host of a URL (the URL itself if it cannot be parsed)
+func urlHost(rawURL string) string {
+	if u, err := url.Parse(strings.TrimSpace(rawURL)); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return rawURL
+}
+
+// llmHost returns the host an LLM request is sent to
+func llmHost(req LLMRequest) string {
+	if req.BaseURL != "" {
+		return urlHost(req.BaseURL)
+	}
+	if modelsURL, ok := providerModelsURLs[req.Provider]; ok {
+		return urlHost(modelsURL)
+	}
+	return req.Provider
+}
+
+// currentProjectRoot returns the project being worked on: the watched project, else the
+// project of the last generation (empty if neither)
+func (a *App) currentProjectRoot() string {
+	if w := a.fileWatcher; w != nil {
+		w.mu.Lock()
+		rootDir := w.rootDir
+		w.mu.Unlock()
+		if rootDir != "" {
+			return rootDir
+		}
+	}
+	if last := a.latestGeneration(); last != nil {
+		return last.rootDir
+	}
+	return ""
+}
+
+// recordEgress appends an entry to the egress audit log
+func (a *App) recordEgress(entry EgressAuditEntry) {
+	if a.configPath == "" {
+		return
+	}
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now()
+	}
+	if entry.Project == "" {
+		entry.Project = a.currentProjectRoot()
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+
+	a.egressMu.Lock()
+	defer a.egressMu.Unlock()
+	if err := appendToFile(a.egressAuditPath(), string(line)+"\n"); err != nil {
+		runtime.LogWarningf(a.ctx, "Failed to record egress audit entry: %v", err)
+	}
+}
+
+// recordLLMEgress records an LLM request that sent content
+func (a *App) recordLLMEgress(req LLMRequest, content string, err error) {
+	status := "sent"
+	if err != nil {
+		status = "failed"
+	}
+	a.recordEgress(EgressAuditEntry{
+		Destination: "llm",
+		Provider:    req.Provider,
+		Model:       req.Model,
+		Host:        llmHost(req),
+		Bytes:       len(content),
+		Tokens:      a.EstimateTokens(content),
+		Status:      status,
+		Redactions:  redactionCounts(content),
+	})
+}
+
+// recordShareEgress records an upload of content to a sharing service
+func (a *App) recordShareEgress(destination, endpoint, content string, ok bool) {
+	status := "sent"
+	if !ok {
+		status = "failed"
+	}
+	a.recordEgress(EgressAuditEntry{
+		Destination: destination,
+		Host:        urlHost(endpoint),
+		Bytes:       len(content),
+		Tokens:      a.EstimateTokens(content),
+		Status:      status,
+		Redactions:  redactionCounts(content),
+	})
+}
+
+// readEgressAudit loads the egress audit log, skipping malformed lines
+func (a *App) readEgressAudit() ([]EgressAuditEntry, error) {
+	a.egressMu.Lock()
+	defer a.egressMu.Unlock()
+
+	f, err := os.Open(a.egressAuditPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open egress audit log: %w", err)
+	}
+	defer f.Close()
+
+	var entries []EgressAuditEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry EgressAuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err == nil {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, scanner.Err()
+}
+
+// egressAuditCSV renders audit entries as CSV (redactions as filter=count pairs)
+func egressAuditCSV(entries []EgressAuditEntry) (string, error) {
+	var b strings.Builder
+	w := csv.NewWriter(&b)
+	w.Write([]string{"timestamp", "destination", "provider", "model", "host", "project", "bytes", "tokens", "status", "redactions"})
+	for _, e := range entries {
+		var redactions []string
+		for filter, count := range e.Redactions {
+			redactions = append(redactions, fmt.Sprintf("%s=%d", filter, count))
+		}
+		sort.Strings(redactions)
+		w.Write([]string{
+			e.Timestamp.Format(time.RFC3339), e.Destination, e.Provider, e.Model, e.Host, e.Project,
+			strconv.Itoa(e.Bytes), strconv.Itoa(e.Tokens), e.Status, strings.Join(redactions, ";"),
+		})
+	}
+	w.Flush()
+	return b.String(), w.Error()
+}
+
+// ============================================================================
+// Egress Audit Methods (Wails-bound)
+// ============================================================================
+
+// GetEgressAudit returns the most recent entries of the egress audit log, newest first
+//
+// Parameters:
+//   - limit: Maximum entries returned (0 for all)
+//
+// Returns:
+//   - []EgressAuditEntry: Audit entries
+//   - error: Error if the log cannot be read
+func (a *App) GetEgressAudit(limit int) ([]EgressAuditEntry, error) {
+	entries, err := a.readEgressAudit()
+	if err != nil {
+		return nil, err
+	}
+	result := []EgressAuditEntry{}
+	for i := len(entries) - 1; i >= 0 && (limit <= 0 || len(result) < limit); i-- {
+		result = append(result, entries[i])
+	}
+	return result, nil
+}
+
+// ExportEgressAudit writes the egress audit log to a file chosen with a native save dialog
+//
+// Parameters:
+//   - format: jsonl (the log as recorded) or csv
+//
+// Returns:
+//   - string: Path the log was written to, or empty string if the dialog was cancelled
+//   - error: Error if the format is unknown or the file cannot be written
+func (a *App) ExportEgressAudit(format string) (string, error) {
+	if format != "jsonl" && format != "csv" {
+		return "", fmt.Errorf("unknown export format: %s (use jsonl or csv)", format)
+	}
+	entries, err := a.readEgressAudit()
+	if err != nil {
+		return "", err
+	}
+
+	var data string
+	if format == "csv" {
+		if data, err = egressAuditCSV(entries); err != nil {
+			return "", fmt.Errorf("failed to encode audit log: %w", err)
+		}
+	} else {
+		var b strings.Builder
+		for _, entry := range entries {
+			line, _ := json.Marshal(entry)
+			b.Write(line)
+			b.WriteByte('\n')
+		}
+		data = b.String()
+	}
+
+	path, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:                "Export Egress Audit Log",
+		DefaultFilename:      "egress_audit." + format,
+		CanCreateDirectories: true,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to open save dialog: %w", err)
+	}
+	if path == "" {
+		return "", nil
+	}
+	if err := writeResponseFile(path, data); err != nil {
+		return "", err
+	}
+	runtime.LogInfof(a.ctx, "Exported %d egress audit entries to %s", len(entries), path)
+	return path, nil
+}
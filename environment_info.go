@@ -0,0 +1,300 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	goruntime "runtime"
+	"sort"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- Environment Summary ---
+//
+// Optional <environment> block appended to generated context with the runtime facts LLMs
+// usually ask for when debugging: the OS, the language versions a project declares (go.mod,
+// package.json engines, .nvmrc, requires-python, rust-version, ...) and the versions of its
+// direct dependencies. Everything except the OS comes from the project's manifests, searched
+// in the same places as DetectProjectType (see project_detect.go); nothing is executed.
+
+const maxEnvironmentDeps = 20 // Dependencies listed per manifest
+
+// RuntimeVersion is a language or tool version declared by a project
+type RuntimeVersion struct {
+	Name    string `json:"name"`    // Runtime name (Go, Node.js, Python, Rust, npm, ...)
+	Version string `json:"version"` // Declared version or constraint
+	Source  string `json:"source"`  // File it was read from, relative to the project root
+}
+
+// DependencyVersion is a direct dependency declared in a manifest
+type DependencyVersion struct {
+	Name     string `json:"name"`     // Package or module name
+	Version  string `json:"version"`  // Declared version or constraint (empty if none)
+	Manifest string `json:"manifest"` // Manifest it was read from, relative to the project root
+}
+
+// EnvironmentInfo is the environment summary of a project
+type EnvironmentInfo struct {
+	OS           string              `json:"os"`           // Operating system and architecture of this machine
+	Runtimes     []RuntimeVersion    `json:"runtimes"`     // Declared runtime versions
+	Dependencies []DependencyVersion `json:"dependencies"` // Direct dependencies, per manifest in file order
+}
+
+var (
+	goDirectiveRegex       = regexp.MustCompile(`(?m)^go\s+(\S+)`)
+	goToolchainRegex       = regexp.MustCompile(`(?m)^toolchain\s+go(\S+)`)
+	goRequireLineRegex     = regexp.MustCompile(`^(?:require\s+)?([^\s()]+)\s+(v\S+)`)
+	requiresPythonRegex    = regexp.MustCompile(`(?m)^requires-python\s*=\s*"([^"]+)"`)
+	pyprojectDepsRegex     = regexp.MustCompile(`(?ms)^dependencies\s*=\s*\[(.*?)\]`)
+	quotedStringRegex      = regexp.MustCompile(`"([^"]+)"`)
+	pythonRequirementRegex = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9._\-\[\]]*)\s*(.*)$`)
+	rustVersionRegex       = regexp.MustCompile(`(?m)^rust-version\s*=\s*"([^"]+)"`)
+	rustToolchainRegex     = regexp.MustCompile(`(?m)^channel\s*=\s*"([^"]+)"`)
+	cargoDependencyRegex   = regexp.MustCompile(`^([A-Za-z0-9_\-]+)\s*=\s*(?:"([^"]*)"|\{.*?version\s*=\s*"([^"]*)")?`)
+)
+
+// versionFiles are single-line version files and the runtime they pin
+var versionFiles = []struct{ file, runtime string }{
+	{".nvmrc", "Node.js"}, {".node-version", "Node.js"}, {".python-version", "Python"}, {"rust-toolchain", "Rust"},
+}
+
+// collectEnvironment reads the declared runtimes and dependencies of a project
+func (a *App) collectEnvironment(rootDir string) EnvironmentInfo {
+	info := EnvironmentInfo{
+		OS:           goruntime.GOOS + "/" + goruntime.GOARCH,
+		Runtimes:     []RuntimeVersion{},
+		Dependencies: []DependencyVersion{},
+	}
+	seen := make(map[string]bool)
+	addRuntime := func(name, version, source string) {
+		version = strings.TrimSpace(version)
+		if version == "" || seen[name+"\x00"+version] {
+			return
+		}
+		seen[name+"\x00"+version] = true
+		info.Runtimes = append(info.Runtimes, RuntimeVersion{Name: name, Version: version, Source: source})
+	}
+
+	for _, dir := range a.manifestDirs(rootDir) {
+		read := func(name string) ([]byte, string, bool) {
+			data, err := a.projectFS.ReadFile(filepath.Join(rootDir, dir, name))
+			return data, filepath.ToSlash(filepath.Join(dir, name)), err == nil
+		}
+
+		if data, rel, ok := read("go.mod"); ok {
+			if m := goDirectiveRegex.FindSubmatch(data); m != nil {
+				addRuntime("Go", string(m[1]), rel)
+			}
+			if m := goToolchainRegex.FindSubmatch(data); m != nil {
+				addRuntime("Go toolchain", string(m[1]), rel)
+			}
+			info.Dependencies = append(info.Dependencies, goModDependencies(data, rel)...)
+		}
+		if data, rel, ok := read("package.json"); ok {
+			var manifest struct {
+				Engines         map[string]string `json:"engines"`
+				PackageManager  string            `json:"packageManager"`
+				Dependencies    map[string]string `json:"dependencies"`
+				DevDependencies map[string]string `json:"devDependencies"`
+			}
+			if json.Unmarshal(data, &manifest) == nil {
+				if v := manifest.Engines["node"]; v != "" {
+					addRuntime("Node.js", v, rel)
+				}
+				for _, engine := range []string{"npm", "pnpm", "yarn"} {
+					addRuntime(engine, manifest.Engines[engine], rel)
+				}
+				if name, version, ok := strings.Cut(manifest.PackageManager, "@"); ok {
+					addRuntime(name, strings.SplitN(version, "+", 2)[0], rel)
+				}
+				deps := mapDependencies(manifest.Dependencies, rel)
+				deps = append(deps, mapDependencies(manifest.DevDependencies, rel)...)
+				info.Dependencies = append(info.Dependencies, limitDependencies(deps)...)
+			}
+		}
+		if data, rel, ok := read("pyproject.toml"); ok {
+			if m := requiresPythonRegex.FindSubmatch(data); m != nil {
+				addRuntime("Python", string(m[1]), rel)
+			}
+			if m := pyprojectDepsRegex.FindSubmatch(data); m != nil {
+				var lines []string
+				for _, q := range quotedStringRegex.FindAllSubmatch(m[1], -1) {
+					lines = append(lines, string(q[1]))
+				}
+				info.Dependencies = append(info.Dependencies, pythonDependencies(lines, rel)...)
+			}
+		}
+		if data, rel, ok := read("requirements.txt"); ok {
+			info.Dependencies = append(info.Dependencies, pythonDependencies(strings.Split(string(data), "\n"), rel)...)
+		}
+		if data, rel, ok := read("Cargo.toml"); ok {
+			if m := rustVersionRegex.FindSubmatch(data); m != nil {
+				addRuntime("Rust", string(m[1]), rel)
+			}
+			info.Dependencies = append(info.Dependencies, cargoDependencies(data, rel)...)
+		}
+		if data, rel, ok := read("rust-toolchain.toml"); ok {
+			if m := rustToolchainRegex.FindSubmatch(data); m != nil {
+				addRuntime("Rust", string(m[1]), rel)
+			}
+		}
+		for _, vf := range versionFiles {
+			if data, rel, ok := read(vf.file); ok {
+				addRuntime(vf.runtime, strings.SplitN(strings.TrimSpace(string(data)), "\n", 2)[0], rel)
+			}
+		}
+		if data, rel, ok := read(".tool-versions"); ok {
+			for _, line := range strings.Split(string(data), "\n") {
+				if fields := strings.Fields(line); len(fields) >= 2 && !strings.HasPrefix(fields[0], "#") {
+					addRuntime(fields[0], fields[1], rel)
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(info.Runtimes, func(i, j int) bool { return info.Runtimes[i].Name < info.Runtimes[j].Name })
+	return info
+}
+
+// goModDependencies returns the direct requirements of a go.mod
+func goModDependencies(data []byte, manifest string) []DependencyVersion {
+	var deps []DependencyVersion
+	inBlock := false
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case strings.HasPrefix(line, "require ("):
+			inBlock = true
+			continue
+		case inBlock && line == ")":
+			inBlock = false
+			continue
+		case !inBlock && !strings.HasPrefix(line, "require "):
+			continue
+		}
+		if strings.Contains(line, "// indirect") {
+			continue
+		}
+		if m := goRequireLineRegex.FindStringSubmatch(line); m != nil {
+			deps = append(deps, DependencyVersion{Name: m[1], Version: m[2], Manifest: manifest})
+		}
+	}
+	return limitDependencies(deps)
+}
+
+// mapDependencies turns a name-to-version map into dependencies sorted by name
+func mapDependencies(versions map[string]string, manifest string) []DependencyVersion {
+	deps := make([]DependencyVersion, 0, len(versions))
+	for name, version := range versions {
+		deps = append(deps, DependencyVersion{Name: name, Version: version, Manifest: manifest})
+	}
+	sort.Slice(deps, func(i, j int) bool { return deps[i].Name < deps[j].Name })
+	return deps
+}
+
+// pythonDependencies parses PEP 508 requirement lines (requirements.txt or pyproject.toml)
+func pythonDependencies(lines []string, manifest string) []DependencyVersion {
+	var deps []DependencyVersion
+	for _, line := range lines {
+		line = strings.TrimSpace(strings.SplitN(line, "#", 2)[0])
+		if line == "" || strings.HasPrefix(line, "-") {
+			continue
+		}
+		if m := pythonRequirementRegex.FindStringSubmatch(line); m != nil {
+			version := strings.TrimSpace(strings.SplitN(m[2], ";", 2)[0])
+			deps = append(deps, DependencyVersion{Name: m[1], Version: version, Manifest: manifest})
+		}
+	}
+	return limitDependencies(deps)
+}
+
+// cargoDependencies returns the [dependencies] of a Cargo.toml
+func cargoDependencies(data []byte, manifest string) []DependencyVersion {
+	var deps []DependencyVersion
+	inDeps := false
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "[") {
+			inDeps = line == "[dependencies]"
+			continue
+		}
+		if !inDeps {
+			continue
+		}
+		if m := cargoDependencyRegex.FindStringSubmatch(line); m != nil {
+			deps = append(deps, DependencyVersion{Name: m[1], Version: m[2] + m[3], Manifest: manifest})
+		}
+	}
+	return limitDependencies(deps)
+}
+
+// limitDependencies keeps the first maxEnvironmentDeps dependencies
+func limitDependencies(deps []DependencyVersion) []DependencyVersion {
+	if len(deps) > maxEnvironmentDeps {
+		return deps[:maxEnvironmentDeps]
+	}
+	return deps
+}
+
+// environmentBlock formats the environment summary appended to generated context
+func environmentBlock(info EnvironmentInfo) string {
+	var b strings.Builder
+	b.WriteString("<environment>\n")
+	b.WriteString("OS: " + info.OS + "\n")
+	for _, rt := range info.Runtimes {
+		fmt.Fprintf(&b, "%s: %s (%s)\n", rt.Name, rt.Version, rt.Source)
+	}
+	manifest := ""
+	for _, dep := range info.Dependencies {
+		if dep.Manifest != manifest {
+			manifest = dep.Manifest
+			b.WriteString("Dependencies (" + manifest + "):\n")
+		}
+		if dep.Version != "" {
+			fmt.Fprintf(&b, "  %s %s\n", dep.Name, dep.Version)
+		} else {
+			b.WriteString("  " + dep.Name + "\n")
+		}
+	}
+	b.WriteString("</environment>")
+	return b.String()
+}
+
+// ============================================================================
+// Environment Summary Methods (Wails-bound)
+// ============================================================================
+
+// GetEnvironmentInfo returns the environment summary of a project
+//
+// Parameters:
+//   - rootDir: Project root directory
+//
+// Returns:
+//   - EnvironmentInfo: OS, declared runtime versions and direct dependencies
+//   - error: Error if the directory does not exist
+func (a *App) GetEnvironmentInfo(rootDir string) (EnvironmentInfo, error) {
+	if err := a.validateContentRoot(rootDir); err != nil {
+		return EnvironmentInfo{}, err
+	}
+	return a.collectEnvironment(rootDir), nil
+}
+
+// GetIncludeEnvironment returns whether generated context ends with an environment summary
+func (a *App) GetIncludeEnvironment() bool {
+	return a.settings.IncludeEnvironment
+}
+
+// SetIncludeEnvironment enables or disables the environment summary in generated context and saves the setting
+func (a *App) SetIncludeEnvironment(enabled bool) error {
+	a.settings.IncludeEnvironment = enabled
+	if err := a.saveSettings(); err != nil {
+		return fmt.Errorf("failed to save environment setting: %w", err)
+	}
+	runtime.LogInfof(a.ctx, "Environment summary in context: %v", enabled)
+	return nil
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- External Attachments ---
+//
+// Design documents and API specs often live outside the project (another repository, a shared
+// drive). They can be attached to a project's generations and are then rendered in the
+// "attachments" context section (see context_sections.go), after the preamble by default:
+//
+//	<attachments>
+//	<document path="/mnt/d/specs/billing-api.yaml">
+//	...content...
+//	</document>
+//	</attachments>
+//
+// Attachments are only added through a native file dialog, so every file outside the project
+// was picked by the user; this is what lets them bypass the AllowedDirectories boundary, which
+// still applies to the project itself. They are kept per project in the settings, read at every
+// generation and go through secrets redaction and the content filters like project files. Files that are missing,
+// binary, not UTF-8 or larger than maxAttachmentBytes are skipped with a placeholder.
+
+const maxAttachmentBytes = 2 << 20
+
+// Attachment is an external file attached to a project's generations
+type Attachment struct {
+	Path   string `json:"path"`            // Absolute path of the file
+	Size   int64  `json:"size"`            // Size in bytes (0 if it cannot be read)
+	Tokens int    `json:"tokens"`          // Estimated tokens of the content
+	Error  string `json:"error,omitempty"` // Why the file would be skipped (empty if it is included)
+}
+
+// readAttachment reads an attached file, checking that it can be included
+func readAttachment(path string) (string, error) {
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return "", fmt.Errorf("file not found")
+	} else if err != nil {
+		return "", err
+	}
+	if !info.Mode().IsRegular() {
+		return "", fmt.Errorf("not a regular file")
+	}
+	if info.Size() > maxAttachmentBytes {
+		return "", fmt.Errorf("larger than %s", formatByteSize(maxAttachmentBytes))
+	}
+	if isBinary, err := isBinaryFileIn(hostProjectFS{}, path); err != nil {
+		return "", err
+	} else if isBinary {
+		return "", fmt.Errorf("binary file")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	if !utf8.Valid(data) {
+		return "", fmt.Errorf("invalid UTF-8")
+	}
+	return string(data), nil
+}
+
+// attachmentBlock formats one attached document in the output format
+func (a *App) attachmentBlock(path, content string) string {
+	if a.outputFormat() == outputFormatMarkdown {
+		return fmt.Sprintf("### `%s`\n\n%s\n", path, markdownFence(content, fenceLanguage(path)))
+	}
+	body := strings.TrimSuffix(content, "\n")
+	escaping := a.fileBlockEscaping()
+	if escaping == fileBlockEscapingAlways || escaping == fileBlockEscapingAuto && strings.Contains(body, "</document>") {
+		body = cdataSection(body)
+	}
+	return fmt.Sprintf("<document path=\"%s\">\n%s\n</document>\n", fileBlockAttr(path), body)
+}
+
+// attachmentsSection renders the attachments of a project for generated context
+//
+// Parameters:
+//   - rootDir: Project root directory
+//   - secrets: Secrets redaction of the generation (nil for none)
+//   - filters: Content filters of the generation (nil for none)
+//
+// Returns:
+//   - string: The attachments section (empty without attachments)
+//   - error: Error wrapping errContentBlocked if a block filter matches an attachment
+func (a *App) attachmentsSection(rootDir string, secrets *secretRedactionRun, filters *contentFilterRun) (string, error) {
+	paths := a.settings.Attachments[projectSettingsKey(rootDir)]
+	if len(paths) == 0 {
+		return "", nil
+	}
+	var b strings.Builder
+	if a.outputFormat() == outputFormatMarkdown {
+		b.WriteString("## Attached Documents\n\n")
+	} else {
+		b.WriteString("<attachments>\n")
+	}
+	for _, path := range paths {
+		content, err := readAttachment(path)
+		if err != nil {
+			runtime.LogWarningf(a.ctx, "Skipping attachment %s: %v", path, err)
+			fmt.Fprintf(&b, "<!-- Attachment skipped (%v): %s -->\n", err, path)
+			continue
+		}
+		if content, err = filters.apply(path, secrets.redact(path, content)); err != nil {
+			return "", err
+		}
+		b.WriteString(a.attachmentBlock(path, content))
+	}
+	if a.outputFormat() != outputFormatMarkdown {
+		b.WriteString("</attachments>")
+	}
+	return b.String(), nil
+}
+
+// saveAttachments stores the attachments of a project and saves the settings
+func (a *App) saveAttachments(rootDir string, paths []string) error {
+	key := projectSettingsKey(rootDir)
+	if len(paths) == 0 {
+		delete(a.settings.Attachments, key)
+	} else {
+		if a.settings.Attachments == nil {
+			a.settings.Attachments = make(map[string][]string)
+		}
+		a.settings.Attachments[key] = paths
+	}
+	if err := a.saveSettings(); err != nil {
+		return fmt.Errorf("failed to save attachments setting: %w", err)
+	}
+	return nil
+}
+
+// ============================================================================
+// External Attachment Methods (Wails-bound)
+// ============================================================================
+
+// GetAttachments returns the external files attached to a project's generations
+//
+// Parameters:
+//   - rootDir: Project root directory
+//
+// Returns:
+//   - []Attachment: Attached files in the order they are rendered, with their size and tokens
+func (a *App) GetAttachments(rootDir string) []Attachment {
+	paths := a.settings.Attachments[projectSettingsKey(rootDir)]
+	attachments := make([]Attachment, 0, len(paths))
+	for _, path := range paths {
+		attachment := Attachment{Path: path}
+		if content, err := readAttachment(path); err != nil {
+			attachment.Error = err.Error()
+		} else {
+			attachment.Size = int64(len(content))
+			attachment.Tokens = a.EstimateTokens(content)
+		}
+		attachments = append(attachments, attachment)
+	}
+	return attachments
+}
+
+// AttachExternalFiles lets the user pick files to attach to a project's generations
+// Files are only attached through this dialog, never by path, so each one is an explicit choice.
+//
+// Parameters:
+//   - rootDir: Project root directory
+//
+// Returns:
+//   - []Attachment: All attachments of the project after the change (unchanged if the dialog was cancelled)
+//   - error: Error if the project is not allowed, a picked file cannot be included, or saving fails
+func (a *App) AttachExternalFiles(rootDir string) ([]Attachment, error) {
+	if err := a.validateContentRoot(rootDir); err != nil {
+		return nil, err
+	}
+	picked, err := runtime.OpenMultipleFilesDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "Attach Documents",
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(picked) == 0 {
+		return a.GetAttachments(rootDir), nil
+	}
+
+	paths := append([]string{}, a.settings.Attachments[projectSettingsKey(rootDir)]...)
+	for _, path := range picked {
+		path = filepath.Clean(path)
+		if _, err := readAttachment(path); err != nil {
+			return nil, fmt.Errorf("cannot attach %s: %w", path, err)
+		}
+		if !slices.Contains(paths, path) {
+			paths = append(paths, path)
+		}
+	}
+	if err := a.saveAttachments(rootDir, paths); err != nil {
+		return nil, err
+	}
+	runtime.LogInfof(a.ctx, "Attached %d files to %s", len(picked), rootDir)
+	return a.GetAttachments(rootDir), nil
+}
+
+// RemoveAttachment detaches an external file from a project's generations
+//
+// Parameters:
+//   - rootDir: Project root directory
+//   - path: Path of the attached file, as returned by GetAttachments
+//
+// Returns:
+//   - error: Error if the file is not attached or the setting cannot be saved
+func (a *App) RemoveAttachment(rootDir, path string) error {
+	paths := a.settings.Attachments[projectSettingsKey(rootDir)]
+	kept := make([]string, 0, len(paths))
+	for _, existing := range paths {
+		if existing != path {
+			kept = append(kept, existing)
+		}
+	}
+	if len(kept) == len(paths) {
+		return fmt.Errorf("%s is not attached to %s", path, rootDir)
+	}
+	if err := a.saveAttachments(rootDir, kept); err != nil {
+		return err
+	}
+	runtime.LogInfof(a.ctx, "Detached %s from %s", path, rootDir)
+	return nil
+}
+
+// ClearAttachments detaches all external files from a project's generations
+func (a *App) ClearAttachments(rootDir string) error {
+	return a.saveAttachments(rootDir, nil)
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- File Backups ---
+//
+// Before the backend overwrites or deletes project files, it copies the current versions into
+// a backup in the config directory (backups/<id>/, with a manifest.json and the copies under
+// files/). Restoring a backup puts the saved files back and deletes the files that did not
+// exist when it was taken. Only the most recent backups are kept.
+
+const maxFileBackups = 50 // Backups kept; older ones are deleted
+
+// FileBackup describes the saved state of some project files
+type FileBackup struct {
+	ID        string         `json:"id"`        // Backup ID (also its directory name)
+	RootDir   string         `json:"rootDir"`   // Project root the paths are relative to
+	Label     string         `json:"label"`     // What the backup was taken for
+	CreatedAt time.Time      `json:"createdAt"` // When the backup was taken
+	Files     []BackedUpFile `json:"files"`     // Files in the backup
+}
+
+// BackedUpFile is one file of a backup
+type BackedUpFile struct {
+	RelPath string `json:"relPath"` // Path relative to the project root (forward slashes)
+	Existed bool   `json:"existed"` // False if the file did not exist (restoring deletes it)
+}
+
+// backupsDir returns the directory holding the backups
+func (a *App) backupsDir() string {
+	return filepath.Join(filepath.Dir(a.configPath), "backups")
+}
+
+// createBackup saves the current state of files of a project
+//
+// Parameters:
+//   - rootDir: Project root
+//   - label: What the backup is for (shown in ListBackups)
+//   - relPaths: Files to save, relative to rootDir
+//
+// Returns:
+//   - *FileBackup: The backup
+//   - error: Error if a path escapes the root or a file cannot be copied
+func (a *App) createBackup(rootDir, label string, relPaths []string) (*FileBackup, error) {
+	if a.configPath == "" {
+		return nil, fmt.Errorf("config directory not available")
+	}
+	backup := &FileBackup{
+		ID:        fmt.Sprintf("%d", time.Now().UnixNano()),
+		RootDir:   rootDir,
+		Label:     label,
+		CreatedAt: time.Now(),
+		Files:     []BackedUpFile{},
+	}
+	dir := filepath.Join(a.backupsDir(), backup.ID)
+	seen := make(map[string]bool)
+	for _, relPath := range relPaths {
+		relPath = filepath.ToSlash(relPath)
+		if seen[relPath] {
+			continue
+		}
+		seen[relPath] = true
+		absPath, err := resolveProjectPath(rootDir, relPath)
+		if err != nil {
+			os.RemoveAll(dir)
+			return nil, err
+		}
+		data, err := os.ReadFile(absPath)
+		if os.IsNotExist(err) {
+			backup.Files = append(backup.Files, BackedUpFile{RelPath: relPath})
+			continue
+		}
+		if err == nil {
+			err = writeProjectFile(filepath.Join(dir, "files", filepath.FromSlash(relPath)), data)
+		}
+		if err != nil {
+			os.RemoveAll(dir)
+			return nil, fmt.Errorf("failed to back up %s: %w", relPath, err)
+		}
+		backup.Files = append(backup.Files, BackedUpFile{RelPath: relPath, Existed: true})
+	}
+
+	manifest, err := json.MarshalIndent(backup, "", "  ")
+	if err == nil {
+		err = writeProjectFile(filepath.Join(dir, "manifest.json"), manifest)
+	}
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("failed to save backup manifest: %w", err)
+	}
+	a.pruneBackups()
+	return backup, nil
+}
+
+// readBackup loads the manifest of a backup
+func (a *App) readBackup(id string) (*FileBackup, error) {
+	if id == "" || strings.ContainsAny(id, `/\.`) {
+		return nil, fmt.Errorf("invalid backup ID: %s", id)
+	}
+	data, err := os.ReadFile(filepath.Join(a.backupsDir(), id, "manifest.json"))
+	if err != nil {
+		return nil, fmt.Errorf("backup not found: %s", id)
+	}
+	var backup FileBackup
+	if err := json.Unmarshal(data, &backup); err != nil {
+		return nil, fmt.Errorf("invalid backup manifest %s: %w", id, err)
+	}
+	return &backup, nil
+}
+
+// restoreBackup puts the files of a backup back in place
+// Every file is attempted; the first error is returned.
+func (a *App) restoreBackup(backup *FileBackup) error {
+	var firstErr error
+	for _, file := range backup.Files {
+		absPath, err := resolveProjectPath(backup.RootDir, file.RelPath)
+		if err == nil {
+			if file.Existed {
+				var data []byte
+				if data, err = os.ReadFile(filepath.Join(a.backupsDir(), backup.ID, "files", filepath.FromSlash(file.RelPath))); err == nil {
+					err = writeProjectFile(absPath, data)
+				}
+			} else if err = os.Remove(absPath); os.IsNotExist(err) {
+				err = nil
+			}
+		}
+		if err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("failed to restore %s: %w", file.RelPath, err)
+		}
+	}
+	return firstErr
+}
+
+// listBackups returns the backups, newest first (all projects if rootDir is empty)
+func (a *App) listBackups(rootDir string) []FileBackup {
+	entries, err := os.ReadDir(a.backupsDir())
+	if err != nil {
+		return []FileBackup{}
+	}
+	backups := []FileBackup{}
+	for _, entry := range entries {
+		backup, err := a.readBackup(entry.Name())
+		if err != nil || (rootDir != "" && backup.RootDir != rootDir) {
+			continue
+		}
+		backups = append(backups, *backup)
+	}
+	sort.Slice(backups, func(i, j int) bool { return backups[i].CreatedAt.After(backups[j].CreatedAt) })
+	return backups
+}
+
+// pruneBackups deletes the oldest backups beyond maxFileBackups
+func (a *App) pruneBackups() {
+	backups := a.listBackups("")
+	for i := maxFileBackups; i < len(backups); i++ {
+		os.RemoveAll(filepath.Join(a.backupsDir(), backups[i].ID))
+	}
+}
+
+// writeProjectFile writes a project file, creating its directory and keeping the mode of an
+// existing file
+func writeProjectFile(absPath string, data []byte) error {
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(absPath); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := os.MkdirAll(filepath.Dir(absPath), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(absPath, data, mode)
+}
+
+// ============================================================================
+// Backup Methods (Wails-bound)
+// ============================================================================
+
+// ListBackups returns the file backups of a project, newest first
+//
+// Parameters:
+//   - rootDir: Project root (empty for all projects)
+//
+// Returns:
+//   - []FileBackup: Backups with the files they contain
+func (a *App) ListBackups(rootDir string) []FileBackup {
+	return a.listBackups(rootDir)
+}
+
+// RestoreBackup puts the files of a backup back in place
+//
+// Parameters:
+//   - backupID: ID of the backup (see ListBackups)
+//
+// Returns:
+//   - error: Error if the backup does not exist or a file cannot be restored
+func (a *App) RestoreBackup(backupID string) error {
+	if err := a.checkWritable("restoring a backup"); err != nil {
+		return err
+	}
+	backup, err := a.readBackup(backupID)
+	if err != nil {
+		return err
+	}
+	if !a.projectFS.OnDisk(backup.RootDir) {
+		return mountedPathError(backup.RootDir)
+	}
+	if err := a.restoreBackup(backup); err != nil {
+		return err
+	}
+	runtime.LogInfof(a.ctx, "Restored backup %s (%s): %d files", backup.ID, backup.Label, len(backup.Files))
+	return nil
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- File Block Serialization ---
+//
+// Generated context wraps each file in a pseudo-XML block:
+//
+//	<file path="src/main.go">
+//	...content...
+//	</file>
+//
+// Attribute values are always escaped (&amp; &quot; &lt; &gt;), so a path with quotes cannot
+// end its attribute early. Content that contains "</file>" (a test fixture, this very
+// format's documentation) would end its block early too; it is wrapped in a CDATA section,
+// with any "]]>" inside split across two sections as XML does:
+//
+//	<file path="docs/format.md">
+//	<![CDATA[...content with </file>...]]>
+//	</file>
+//
+// The FileBlockEscaping setting picks when CDATA is used: auto (only when needed, the
+// default), always (every file, for strict XML consumers) or never (the raw format of earlier
+// versions). Auto also wraps content that starts with "<![CDATA[" or contains "]]>", which a
+// parser would otherwise mistake for a wrapper. Parsers of generated context use fileBlockPath
+// and fileBlockContent to undo both. The delimiters themselves are fixed: every parser of
+// generated context (snapshots, splitting, Markdown conversion) expects <file> blocks, so only
+// the escaping is configurable.
+
+const (
+	fileBlockEscapingAuto   = "auto"   // CDATA only for content containing </file> or CDATA markers (default)
+	fileBlockEscapingAlways = "always" // CDATA for every file
+	fileBlockEscapingNever  = "never"  // Raw content
+)
+
+const (
+	cdataStart = "<![CDATA["
+	cdataEnd   = "]]>"
+)
+
+// fileBlockAttrEscaper escapes attribute values of file blocks
+var fileBlockAttrEscaper = strings.NewReplacer(`&`, "&amp;", `"`, "&quot;", `<`, "&lt;", `>`, "&gt;")
+
+// fileBlockAttr escapes an attribute value of a file block
+func fileBlockAttr(value string) string {
+	return fileBlockAttrEscaper.Replace(value)
+}
+
+// fileBlockPath returns the path of a parsed path attribute, unescaped
+func fileBlockPath(attr string) string {
+	return html.UnescapeString(attr)
+}
+
+// fileBlockEscaping returns the CDATA mode of file blocks (auto, always or never)
+func (a *App) fileBlockEscaping() string {
+	switch a.settings.FileBlockEscaping {
+	case fileBlockEscapingAlways, fileBlockEscapingNever:
+		return a.settings.FileBlockEscaping
+	}
+	return fileBlockEscapingAuto
+}
+
+// fileBlockBody returns content as it goes between the tags of a file block
+func fileBlockBody(content, escaping string) string {
+	if escaping == fileBlockEscapingNever || (escaping == fileBlockEscapingAuto && !needsCDATA(content)) {
+		return content
+	}
+	return cdataSection(content)
+}
+
+// needsCDATA reports whether raw content would be ambiguous in a file block: it could end
+// the block early or be read back as a CDATA wrapper by fileBlockContent
+func needsCDATA(content string) bool {
+	return strings.Contains(content, "</file>") || strings.HasPrefix(content, cdataStart) || strings.Contains(content, cdataEnd)
+}
+
+// cdataSection wraps content in CDATA, splitting any "]]>" inside across two sections
+func cdataSection(content string) string {
+	return cdataStart + strings.ReplaceAll(content, cdataEnd, "]]"+cdataEnd+cdataStart+">") + cdataEnd
+}
+
+// fileBlockContent returns the content of a parsed file block body, undoing CDATA wrapping
+func fileBlockContent(body string) string {
+	if !strings.HasPrefix(body, cdataStart) || !strings.HasSuffix(body, cdataEnd) {
+		return body
+	}
+	inner := body[len(cdataStart) : len(body)-len(cdataEnd)]
+	return strings.ReplaceAll(inner, "]]"+cdataEnd+cdataStart+">", cdataEnd)
+}
+
+// fileBlock serializes a complete file block
+//
+// Parameters:
+//   - relPath: Path of the file (forward slashes)
+//   - attrs: Extra attributes, already escaped, each with a leading space
+//   - content: Content of the file
+//   - escaping: CDATA mode (auto, always or never)
+//
+// Returns:
+//   - string: The block, ending with a newline
+func fileBlock(relPath, attrs, content, escaping string) string {
+	return fmt.Sprintf("<file path=\"%s\"%s>\n%s\n</file>\n", fileBlockAttr(relPath), attrs, fileBlockBody(content, escaping))
+}
+
+// ============================================================================
+// File Block Methods (Wails-bound)
+// ============================================================================
+
+// GetFileBlockEscaping returns when file contents are wrapped in CDATA (auto, always or never)
+func (a *App) GetFileBlockEscaping() string {
+	return a.fileBlockEscaping()
+}
+
+// SetFileBlockEscaping sets when file contents are wrapped in CDATA and saves the setting
+//
+// Parameters:
+//   - mode: auto (only content containing </file> or CDATA markers), always, or never
+//
+// Returns:
+//   - error: Error if the mode is unknown or the setting cannot be saved
+func (a *App) SetFileBlockEscaping(mode string) error {
+	if mode != fileBlockEscapingAuto && mode != fileBlockEscapingAlways && mode != fileBlockEscapingNever {
+		return fmt.Errorf("unknown file block escaping: %s", mode)
+	}
+	a.settings.FileBlockEscaping = mode
+	if err := a.saveSettings(); err != nil {
+		return fmt.Errorf("failed to save file block escaping setting: %w", err)
+	}
+	runtime.LogInfof(a.ctx, "File block escaping: %s", mode)
+	return nil
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- File Recency and Churn ---
+//
+// git log --numstat tells when each file was last committed and how often it changed. The
+// project index job reads it once per project (see project_index.go); tree nodes then carry
+// the date of their last commit and their commit count, and file headers in generated context
+// can optionally carry them too, so the LLM can tell hot, recently-touched code from code
+// nobody has changed in years. Only the last year of history is read.
+
+const (
+	churnWindow     = 365 * 24 * time.Hour // History read for churn
+	churnMaxCommits = 5000                 // Commits read at most, for very active repositories
+)
+
+// FileChurn is the commit history summary of one file
+type FileChurn struct {
+	LastCommit   time.Time `json:"lastCommit"`   // Date of the last commit touching the file
+	Commits      int       `json:"commits"`      // Commits touching the file within the window
+	LinesChanged int       `json:"linesChanged"` // Lines added plus deleted within the window (0 for binary files)
+}
+
+// HotFile is a file ranked by GetHotFiles
+type HotFile struct {
+	RelPath string `json:"relPath"` // Path relative to the project root (forward slashes)
+	FileChurn
+}
+
+// readGitChurn summarizes the recent history of the files below rootDir (nil outside git)
+// Paths are relative to rootDir, with forward slashes.
+func readGitChurn(ctx context.Context, rootDir string) map[string]FileChurn {
+	since := time.Now().Add(-churnWindow).Format("2006-01-02")
+	cmd := exec.CommandContext(ctx, "git", "log", "--numstat", "--no-merges", "--no-renames", "--relative",
+		"--format=%x00%ct", "--since="+since, "-n", strconv.Itoa(churnMaxCommits), "--", ".")
+	cmd.Dir = rootDir
+	out, err := cmd.Output()
+	if err != nil {
+		return nil
+	}
+
+	churn := make(map[string]FileChurn)
+	for _, commit := range strings.Split(string(out), "\x00") {
+		scanner := bufio.NewScanner(strings.NewReader(commit))
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		if !scanner.Scan() {
+			continue
+		}
+		seconds, err := strconv.ParseInt(strings.TrimSpace(scanner.Text()), 10, 64)
+		if err != nil {
+			continue
+		}
+		committed := time.Unix(seconds, 0)
+		for scanner.Scan() {
+			fields := strings.SplitN(scanner.Text(), "\t", 3)
+			if len(fields) != 3 {
+				continue
+			}
+			added, _ := strconv.Atoi(fields[0]) // "-" for binary files
+			deleted, _ := strconv.Atoi(fields[1])
+			entry := churn[fields[2]]
+			entry.Commits++
+			entry.LinesChanged += added + deleted
+			if committed.After(entry.LastCommit) {
+				entry.LastCommit = committed
+			}
+			churn[fields[2]] = entry
+		}
+	}
+	return churn
+}
+
+// indexedChurn returns the churn of the indexed project containing absPath and the path
+// relative to its root (nil if no churn is indexed for it)
+func (a *App) indexedChurn(absPath string) (map[string]FileChurn, string) {
+	a.indexMu.Lock()
+	idx := a.index
+	a.indexMu.Unlock()
+	if idx == nil {
+		return nil, ""
+	}
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	relPath, err := filepath.Rel(idx.rootDir, absPath)
+	if err != nil || strings.HasPrefix(relPath, "..") {
+		return nil, ""
+	}
+	return idx.churn, filepath.ToSlash(relPath)
+}
+
+// annotateChurn sets LastCommit and Commits on the file nodes of a tree
+func annotateChurn(nodes []*FileNode, churn map[string]FileChurn) {
+	for _, node := range nodes {
+		if node.IsDir {
+			annotateChurn(node.Children, churn)
+			continue
+		}
+		if entry, ok := churn[filepath.ToSlash(node.RelPath)]; ok {
+			node.LastCommit = entry.LastCommit.Unix()
+			node.Commits = entry.Commits
+		}
+	}
+}
+
+// annotateTreeChurn annotates a tree of rootDir with the churn of the index, if any
+func (a *App) annotateTreeChurn(rootDir string, nodes []*FileNode) {
+	if idx := a.currentIndex(rootDir); idx != nil {
+		idx.mu.RLock()
+		churn := idx.churn
+		idx.mu.RUnlock()
+		if churn != nil {
+			annotateChurn(nodes, churn)
+		}
+	}
+}
+
+// churnHeaderAttrs returns the churn attributes of a file header (empty if off or unknown)
+func (a *App) churnHeaderAttrs(absPath string) string {
+	if !a.settings.ChurnInFileHeaders {
+		return ""
+	}
+	churn, relPath := a.indexedChurn(absPath)
+	entry, ok := churn[relPath]
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf(" last-commit=\"%s\" commits=\"%d\"", entry.LastCommit.Format("2006-01-02"), entry.Commits)
+}
+
+// projectChurn returns the churn of a project, from the index when available
+func (a *App) projectChurn(rootDir string) map[string]FileChurn {
+	if idx := a.currentIndex(rootDir); idx != nil {
+		idx.mu.RLock()
+		churn := idx.churn
+		idx.mu.RUnlock()
+		if churn != nil {
+			return churn
+		}
+	}
+	return readGitChurn(a.ctx, rootDir)
+}
+
+// ============================================================================
+// File Churn Methods (Wails-bound)
+// ============================================================================
+
+// GetFileChurn returns the recent commit history summary of the files of a project
+//
+// Parameters:
+//   - rootDir: Project root directory
+//
+// Returns:
+//   - map[string]FileChurn: Churn by path relative to the root (forward slashes), empty outside git
+//   - error: Error if the directory does not exist
+func (a *App) GetFileChurn(rootDir string) (map[string]FileChurn, error) {
+	if err := a.validateContentRoot(rootDir); err != nil {
+		return nil, err
+	}
+	churn := a.projectChurn(rootDir)
+	if churn == nil {
+		churn = map[string]FileChurn{}
+	}
+	return churn, nil
+}
+
+// GetHotFiles returns the most frequently changed files of a project, most recent first on ties
+//
+// Parameters:
+//   - rootDir: Project root directory
+//   - limit: Maximum number of files (0 for all)
+//
+// Returns:
+//   - []HotFile: Files ordered by commit count, then last commit date
+//   - error: Error if the directory does not exist
+func (a *App) GetHotFiles(rootDir string, limit int) ([]HotFile, error) {
+	churn, err := a.GetFileChurn(rootDir)
+	if err != nil {
+		return nil, err
+	}
+	hot := make([]HotFile, 0, len(churn))
+	for relPath, entry := range churn {
+		if a.fileExists(filepath.Join(rootDir, filepath.FromSlash(relPath))) {
+			hot = append(hot, HotFile{RelPath: relPath, FileChurn: entry})
+		}
+	}
+	sort.Slice(hot, func(i, j int) bool {
+		if hot[i].Commits != hot[j].Commits {
+			return hot[i].Commits > hot[j].Commits
+		}
+		return hot[i].LastCommit.After(hot[j].LastCommit)
+	})
+	if limit > 0 && len(hot) > limit {
+		hot = hot[:limit]
+	}
+	return hot, nil
+}
+
+// GetChurnInFileHeaders returns whether file headers in generated context carry churn attributes
+func (a *App) GetChurnInFileHeaders() bool {
+	return a.settings.ChurnInFileHeaders
+}
+
+// SetChurnInFileHeaders enables or disables churn attributes in file headers and saves the setting
+func (a *App) SetChurnInFileHeaders(enabled bool) error {
+	a.settings.ChurnInFileHeaders = enabled
+	if err := a.saveSettings(); err != nil {
+		return fmt.Errorf("failed to save churn header setting: %w", err)
+	}
+	runtime.LogInfof(a.ctx, "Churn attributes in file headers: %v", enabled)
+	return nil
+}
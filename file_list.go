@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	goruntime "runtime"
+	"strconv"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- File List Copy ---
+//
+// The selection UI is also handy to drive other CLI tools (aider, a shell loop, a linter run),
+// which only need the paths. CopyFileList copies the selected files' relative paths instead of
+// their content: the same files a generation would include, so ignore rules, dotfile settings
+// and force-included paths apply, in tree order. Formats:
+//
+//	newline   src/app.go             (one path per line, the default)
+//	json      ["src/app.go", ...]
+//	at        @src/app.go @"docs/my notes.md"   (for tools that take @-prefixed file mentions)
+
+// File list formats
+const (
+	fileListNewline = "newline"
+	fileListJSON    = "json"
+	fileListAt      = "at"
+)
+
+// selectedFilePaths lists the files of a selection, as a generation would include them
+//
+// Parameters:
+//   - rootDir: Project root directory
+//   - excludedPaths: The selection, expressed as exclusions
+//
+// Returns:
+//   - []string: Relative paths (forward slashes), in tree order
+//   - error: Error if the project cannot be walked
+func (a *App) selectedFilePaths(rootDir string, excludedPaths []string) ([]string, error) {
+	entries, _, err := a.walkSelectionEntries(rootDir)
+	if err != nil {
+		return nil, err
+	}
+	excluded := make(map[string]bool, len(excludedPaths))
+	for _, p := range excludedPaths {
+		excluded[p] = true
+	}
+	paths := []string{}
+	for _, entry := range entries {
+		if !entry.isDir && !excludedBySelection(excluded, filepath.FromSlash(entry.relPath)) {
+			paths = append(paths, entry.relPath)
+		}
+	}
+	return paths, nil
+}
+
+// formatFileList formats a list of paths for the clipboard
+func formatFileList(paths []string, format string) (string, error) {
+	switch format {
+	case "", fileListNewline:
+		return strings.Join(paths, "\n"), nil
+	case fileListJSON:
+		data, err := json.MarshalIndent(paths, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	case fileListAt:
+		mentions := make([]string, len(paths))
+		for i, p := range paths {
+			if strings.ContainsAny(p, " \t\"") {
+				p = strconv.Quote(p)
+			}
+			mentions[i] = "@" + p
+		}
+		return strings.Join(mentions, " "), nil
+	}
+	return "", fmt.Errorf("unknown file list format: %s (use newline, json or at)", format)
+}
+
+// copyToClipboard copies text with the first clipboard tier of the platform: PowerShell inside
+// WSL, the Win32 API on native Windows, the Wails clipboard elsewhere
+func (a *App) copyToClipboard(text string) error {
+	switch {
+	case isWSL():
+		return a.WSLClipboardSetText(text)
+	case goruntime.GOOS == "windows":
+		return setNativeClipboardText(text)
+	}
+	return runtime.ClipboardSetText(a.ctx, text)
+}
+
+// ============================================================================
+// File List Methods (Wails-bound)
+// ============================================================================
+
+// CopyFileList copies the relative paths of the selected files to the clipboard
+//
+// Parameters:
+//   - rootDir: Project root directory
+//   - selection: The selection, expressed as exclusions (like context generation)
+//   - format: newline (default), json, or at (@-prefixed, for aider and similar tools)
+//
+// Returns:
+//   - int: Number of paths copied
+//   - error: Error if the format is unknown, the project is not allowed or cannot be walked,
+//     or the clipboard cannot be written
+func (a *App) CopyFileList(rootDir string, selection []string, format string) (int, error) {
+	if err := a.validateContentRoot(rootDir); err != nil {
+		return 0, err
+	}
+	paths, err := a.selectedFilePaths(rootDir, selection)
+	if err != nil {
+		return 0, err
+	}
+	text, err := formatFileList(paths, format)
+	if err != nil {
+		return 0, err
+	}
+	if err := a.copyToClipboard(text); err != nil {
+		return 0, fmt.Errorf("failed to copy file list: %w", err)
+	}
+	runtime.LogInfof(a.ctx, "Copied a list of %d files of %s", len(paths), rootDir)
+	return len(paths), nil
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- Dependency File Order ---
+//
+// File blocks of generated context follow the tree (by path) by default. With the
+// "dependencies" file order, they follow the import graph (see import_graph.go) instead: a
+// file comes after the project files it imports, so the model reads definitions before their
+// usages, and entry points come last. Go, JavaScript/TypeScript and Python imports are
+// followed; files without imports between them keep their path order, and import cycles are
+// broken at the file reached first. The tree itself and directory summaries keep the path
+// order.
+
+const (
+	fileOrderPath         = "path"         // Tree order (default)
+	fileOrderDependencies = "dependencies" // Imported files first
+)
+
+// orderedFile is a file whose block a generation writes after the walk
+type orderedFile struct {
+	path    string // Absolute path
+	relPath string // Path relative to the project root
+}
+
+// fileOrderRun collects the files of a generation to write them in dependency order
+type fileOrderRun struct {
+	rootDir string
+	files   []orderedFile // In tree order
+}
+
+// fileOrder returns the order of file blocks in generated context (path or dependencies)
+func (a *App) fileOrder() string {
+	if a.settings.FileOrder == fileOrderDependencies {
+		return fileOrderDependencies
+	}
+	return fileOrderPath
+}
+
+// newFileOrderRun returns the file order state of a generation (nil for the tree order)
+func (a *App) newFileOrderRun(rootDir string) *fileOrderRun {
+	if a.fileOrder() != fileOrderDependencies {
+		return nil
+	}
+	return &fileOrderRun{rootDir: rootDir}
+}
+
+// add defers the block of a file until the walk is done
+func (r *fileOrderRun) add(path, relPath string) {
+	r.files = append(r.files, orderedFile{path: path, relPath: relPath})
+}
+
+// preamble tells the model how files are ordered ("" for the tree order)
+func (r *fileOrderRun) preamble() string {
+	if r == nil {
+		return ""
+	}
+	return "Files are ordered by dependency: each file comes after the project files it imports.\n\n"
+}
+
+// orderedFiles returns the deferred files of a generation in dependency order
+func (a *App) orderedFiles(r *fileOrderRun) []orderedFile {
+	if r == nil || len(r.files) == 0 {
+		return nil
+	}
+	relPaths := make([]string, len(r.files))
+	byRelPath := make(map[string]orderedFile, len(r.files))
+	for i, file := range r.files {
+		relPaths[i] = filepath.ToSlash(file.relPath)
+		byRelPath[relPaths[i]] = file
+	}
+	order, cycles, err := a.dependencyOrder(r.rootDir, relPaths)
+	if err != nil {
+		runtime.LogWarningf(a.ctx, "Keeping the tree order of %s: %v", r.rootDir, err)
+		return r.files
+	}
+	runtime.LogInfof(a.ctx, "Ordered %d files of %s by dependency (%d import cycles broken)", len(order), r.rootDir, cycles)
+	files := make([]orderedFile, len(order))
+	for i, relPath := range order {
+		files[i] = byRelPath[relPath]
+	}
+	return files
+}
+
+// dependencyOrder sorts files so that each one comes after the files it imports
+//
+// Parameters:
+//   - rootDir: Project root directory
+//   - relPaths: Files to sort, in their fallback order (forward slashes)
+//
+// Returns:
+//   - []string: The same files, imported files first
+//   - int: Import cycles that had to be broken
+//   - error: Error if the project cannot be walked
+func (a *App) dependencyOrder(rootDir string, relPaths []string) ([]string, int, error) {
+	_, files, err := a.walkSelectionEntries(rootDir)
+	if err != nil {
+		return nil, 0, err
+	}
+	graph := a.buildImportGraph(rootDir, files)
+
+	included := make(map[string]bool, len(relPaths))
+	for _, relPath := range relPaths {
+		included[relPath] = true
+	}
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := make(map[string]int, len(relPaths))
+	order := make([]string, 0, len(relPaths))
+	cycles := 0
+	var visit func(relPath string)
+	visit = func(relPath string) {
+		state[relPath] = visiting
+		imports := slices.Clone(graph.imports[relPath])
+		slices.Sort(imports)
+		for _, imported := range imports {
+			switch {
+			case !included[imported]:
+			case state[imported] == 0:
+				visit(imported)
+			case state[imported] == visiting:
+				cycles++
+			}
+		}
+		state[relPath] = visited
+		order = append(order, relPath)
+	}
+	for _, relPath := range relPaths {
+		if state[relPath] == 0 {
+			visit(relPath)
+		}
+	}
+	return order, cycles, nil
+}
+
+// ============================================================================
+// File Order Methods (Wails-bound)
+// ============================================================================
+
+// GetFileOrder returns the order of file blocks in generated context (path or dependencies)
+func (a *App) GetFileOrder() string {
+	return a.fileOrder()
+}
+
+// SetFileOrder sets the order of file blocks in generated context and saves the setting
+//
+// Parameters:
+//   - order: path (tree order) or dependencies (imported files first)
+//
+// Returns:
+//   - error: Error if the order is unknown or the setting cannot be saved
+func (a *App) SetFileOrder(order string) error {
+	if order != fileOrderPath && order != fileOrderDependencies {
+		return fmt.Errorf("unknown file order: %s (use path or dependencies)", order)
+	}
+	a.settings.FileOrder = order
+	if err := a.saveSettings(); err != nil {
+		return fmt.Errorf("failed to save file order setting: %w", err)
+	}
+	runtime.LogInfof(a.ctx, "File order: %s", order)
+	return nil
+}
+
+// PreviewDependencyOrder returns the selected files in the order the "dependencies" file order
+// would write them
+//
+// Parameters:
+//   - rootDir: Project root directory
+//   - excludedPaths: The selection, expressed as exclusions
+//
+// Returns:
+//   - []string: Selected files, imported files first (forward slashes)
+//   - error: Error if the project cannot be walked
+func (a *App) PreviewDependencyOrder(rootDir string, excludedPaths []string) ([]string, error) {
+	if err := a.validateContentRoot(rootDir); err != nil {
+		return nil, err
+	}
+	paths, err := a.selectedFilePaths(rootDir, excludedPaths)
+	if err != nil {
+		return nil, err
+	}
+	order, _, err := a.dependencyOrder(rootDir, paths)
+	return order, err
+}
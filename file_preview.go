@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- File Preview ---
+//
+// PreviewFile serves hover previews: only the head of the file is read and sent over the
+// bridge. A truncated head ends on a line boundary, preferably after a blank line in its
+// second half (the end of a function or paragraph), so the preview does not stop mid-token
+// or mid-statement. Heads of a single long line (minified files) are cut on a rune boundary.
+
+const (
+	defaultPreviewBytes = 8 * 1024
+	maxPreviewBytes     = 256 * 1024
+)
+
+// FilePreview is the head of a file
+type FilePreview struct {
+	RelPath   string `json:"relPath"`   // Path relative to the project root (forward slashes)
+	Language  string `json:"language"`  // Language detected from the file name
+	Size      int64  `json:"size"`      // Size of the whole file in bytes
+	Tokens    int    `json:"tokens"`    // Estimated tokens of the whole file
+	Content   string `json:"content"`   // Head of the file (empty for binary files)
+	Truncated bool   `json:"truncated"` // True if Content is not the whole file
+	IsBinary  bool   `json:"isBinary"`  // True if the file is binary
+}
+
+// previewCut returns where to cut text, read one byte past the limit, to at most limit bytes
+func previewCut(text string, limit int) int {
+	if text[limit] == '\n' {
+		return limit // The head ends on a whole line
+	}
+	head := text[:limit]
+	lastNewline := strings.LastIndexByte(head, '\n')
+	if lastNewline < 0 {
+		return len(truncateUTF8(text, limit))
+	}
+	if blank := strings.LastIndex(head[:lastNewline+1], "\n\n"); blank >= limit/2 {
+		return blank + 1
+	}
+	return lastNewline + 1
+}
+
+// ============================================================================
+// File Preview Methods (Wails-bound)
+// ============================================================================
+
+// PreviewFile returns the head of a project file for a preview
+//
+// Parameters:
+//   - rootDir: Project root directory
+//   - relPath: File path relative to the root
+//   - maxBytes: Bytes of content to return at most (0 for the default, capped at 256 KB)
+//
+// Returns:
+//   - FilePreview: Head, language, size and token estimate of the file
+//   - error: Error if the path is outside the project or cannot be read
+func (a *App) PreviewFile(rootDir, relPath string, maxBytes int) (FilePreview, error) {
+	if maxBytes <= 0 {
+		maxBytes = defaultPreviewBytes
+	}
+	maxBytes = min(maxBytes, maxPreviewBytes)
+	if err := a.validateContentRoot(rootDir); err != nil {
+		return FilePreview{}, err
+	}
+	absPath, err := resolveProjectPath(rootDir, relPath)
+	if err != nil {
+		return FilePreview{}, err
+	}
+	info, err := a.projectFS.Stat(absPath)
+	if err != nil {
+		return FilePreview{}, fmt.Errorf("failed to stat %s: %w", relPath, err)
+	}
+	if info.IsDir() {
+		return FilePreview{}, fmt.Errorf("not a file: %s", relPath)
+	}
+
+	preview := FilePreview{
+		RelPath:  strings.TrimPrefix(strings.ReplaceAll(relPath, "\\", "/"), "./"),
+		Language: a.detectFileLanguage(absPath, relPath),
+		Size:     info.Size(),
+		Tokens:   int(info.Size() / 4),
+	}
+	if entry, ok := a.lookupIndexedFile(absPath); ok {
+		preview.Tokens = entry.tokens
+	}
+	isBinary, err := a.isBinaryFileCached(absPath)
+	if err != nil {
+		return FilePreview{}, fmt.Errorf("failed to read %s: %w", relPath, err)
+	}
+	if isBinary {
+		preview.IsBinary, preview.Tokens = true, 0
+		return preview, nil
+	}
+
+	f, err := a.projectFS.Open(absPath)
+	if err != nil {
+		return FilePreview{}, fmt.Errorf("failed to open %s: %w", relPath, err)
+	}
+	defer f.Close()
+	buf := make([]byte, maxBytes+1) // One byte more tells whether the file continues
+	n, err := io.ReadFull(f, buf)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return FilePreview{}, fmt.Errorf("failed to read %s: %w", relPath, err)
+	}
+	head := string(buf[:n])
+	if n > maxBytes {
+		head = head[:previewCut(head, maxBytes)]
+		preview.Truncated = true
+	}
+	if !utf8.ValidString(head) {
+		head = strings.ToValidUTF8(head, "�")
+	}
+	preview.Content = head
+
+	runtime.LogDebugf(a.ctx, "Previewed %s (%d of %d bytes)", relPath, len(head), preview.Size)
+	return preview, nil
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- Per-File Size Cap ---
+//
+// A giant generated file (a lock file, a bundle, a fixture) can take up most of a context.
+// With the MaxFileBytes setting on, files whose content is larger are truncated: by default
+// only the head is kept; with FileCapKeepTail, the first and the last half of the cap are kept,
+// so trailing definitions and exports stay visible. Cuts end and start on line boundaries, and
+// a marker line tells how much was left out:
+//
+//	<file path="data/fixtures.json" truncated="true" omitted-lines="48210">
+//	...
+//	[... 48210 lines omitted (2.9 MB) ...]
+//	...
+//	</file>
+//
+// Token budget cuts (see token_budget.go) get the same marker; they keep only the head. With
+// line numbers on, the tail keeps its real line numbers.
+
+// fileCut is file content with a part left out
+type fileCut struct {
+	head          string // Kept start of the content
+	tail          string // Kept end of the content (empty if only the head is kept)
+	tailFirstLine int    // Line number of the first line of tail
+	totalLines    int    // Lines of the whole content
+	omittedLines  int    // Lines left out
+	omittedBytes  int    // Bytes left out
+}
+
+// countLines returns the number of lines of text (a last line without newline counts)
+func countLines(text string) int {
+	lines := strings.Count(text, "\n")
+	if text != "" && !strings.HasSuffix(text, "\n") {
+		lines++
+	}
+	return lines
+}
+
+// keepHeadNewline moves the newline ending the last kept line into head, so the omitted part
+// starts on a line of its own and is not counted one line too many
+func keepHeadNewline(content, head string) string {
+	if len(head) < len(content) && content[len(head)] == '\n' {
+		return content[:len(head)+1]
+	}
+	return head
+}
+
+// cutFileContent cuts content to at most maxBytes, keeping the head or the head and tail
+//
+// Parameters:
+//   - content: File content
+//   - maxBytes: Bytes to keep at most (0 for no limit)
+//   - keepTail: True to keep the first and last half of maxBytes instead of the head only
+//
+// Returns:
+//   - fileCut: The kept parts
+//   - bool: False if the content fits and was not cut
+func cutFileContent(content string, maxBytes int, keepTail bool) (fileCut, bool) {
+	if maxBytes <= 0 || len(content) <= maxBytes {
+		return fileCut{}, false
+	}
+	cut := fileCut{totalLines: countLines(content)}
+	if !keepTail {
+		cut.head = keepHeadNewline(content, truncateFileContent(content, maxBytes))
+		omitted := content[len(cut.head):]
+		cut.omittedLines, cut.omittedBytes = countLines(omitted), len(omitted)
+		return cut, true
+	}
+
+	cut.head = keepHeadNewline(content, truncateFileContent(content, maxBytes/2))
+	start := len(content) - (maxBytes - len(cut.head))
+	if newline := strings.IndexByte(content[start:], '\n'); newline >= 0 && start+newline+1 < len(content) {
+		start += newline + 1 // The tail starts on a whole line
+	} else {
+		for start < len(content) && !isRuneStart(content[start]) {
+			start++
+		}
+	}
+	cut.tail = content[start:]
+	cut.tailFirstLine = strings.Count(content[:start], "\n") + 1
+	omitted := content[len(cut.head):start]
+	cut.omittedLines, cut.omittedBytes = countLines(omitted), len(omitted)
+	return cut, true
+}
+
+// render joins the kept parts around the marker line, numbering lines if asked
+func (c fileCut) render(lineNumbers bool) string {
+	head, tail := c.head, c.tail
+	if lineNumbers {
+		width := len(fmt.Sprint(c.totalLines))
+		head = numberLinesFrom(head, 1, width)
+		tail = numberLinesFrom(tail, c.tailFirstLine, width)
+	}
+	var b strings.Builder
+	b.WriteString(head)
+	if head != "" && !strings.HasSuffix(head, "\n") {
+		b.WriteByte('\n')
+	}
+	unit := "lines"
+	if c.omittedLines == 1 {
+		unit = "line"
+	}
+	fmt.Fprintf(&b, "[... %d %s omitted (%s) ...]\n", c.omittedLines, unit, formatByteSize(c.omittedBytes))
+	b.WriteString(tail)
+	return b.String()
+}
+
+// formatByteSize formats a byte count for the omission marker
+func formatByteSize(n int) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%d KB", n>>10)
+	}
+	return fmt.Sprintf("%d bytes", n)
+}
+
+// FileSizeCap is the per-file size cap of generated context
+type FileSizeCap struct {
+	MaxBytes int  `json:"maxBytes"` // Content bytes kept per file at most (0 = no cap)
+	KeepTail bool `json:"keepTail"` // True to keep the head and tail of capped files, false for the head only
+}
+
+// ============================================================================
+// File Size Cap Methods (Wails-bound)
+// ============================================================================
+
+// GetFileSizeCap returns the per-file size cap of generated context
+func (a *App) GetFileSizeCap() FileSizeCap {
+	return FileSizeCap{MaxBytes: a.settings.MaxFileBytes, KeepTail: a.settings.FileCapKeepTail}
+}
+
+// SetFileSizeCap sets the per-file size cap of generated context and saves it
+//
+// Parameters:
+//   - maxBytes: Content bytes kept per file at most (e.g. 204800; 0 = no cap)
+//   - keepTail: True to keep the head and tail of capped files, false for the head only
+//
+// Returns:
+//   - error: Error if the size is negative or the setting cannot be saved
+func (a *App) SetFileSizeCap(maxBytes int, keepTail bool) error {
+	if maxBytes < 0 {
+		return fmt.Errorf("file size cap must not be negative, got %d", maxBytes)
+	}
+	a.settings.MaxFileBytes = maxBytes
+	a.settings.FileCapKeepTail = keepTail
+	if err := a.saveSettings(); err != nil {
+		return fmt.Errorf("failed to save file size cap setting: %w", err)
+	}
+	runtime.LogInfof(a.ctx, "File size cap: %d bytes (keep tail: %v)", maxBytes, keepTail)
+	return nil
+}
@@ -0,0 +1,26 @@
+package main
+
+import "testing"
+
+func TestCutFileContentOmittedLines(t *testing.T) {
+	cut, ok := cutFileContent("aaa\nbbb\n", 3, false)
+	if !ok {
+		t.Fatal("content larger than the cap was not cut")
+	}
+	if cut.head != "aaa\n" || cut.omittedLines != 1 || cut.omittedBytes != 4 {
+		t.Fatalf("head %q, %d lines / %d bytes omitted; want \"aaa\\n\", 1 line / 4 bytes", cut.head, cut.omittedLines, cut.omittedBytes)
+	}
+	if got, want := cut.render(false), "aaa\n[... 1 line omitted (4 bytes) ...]\n"; got != want {
+		t.Fatalf("render = %q, want %q", got, want)
+	}
+}
+
+func TestCutFileContentKeepTailOmittedLines(t *testing.T) {
+	cut, ok := cutFileContent("aaa\nbbb\nccc\nddd\n", 8, true)
+	if !ok {
+		t.Fatal("content larger than the cap was not cut")
+	}
+	if cut.head != "aaa\n" || cut.tail != "ddd\n" || cut.omittedLines != 2 || cut.tailFirstLine != 4 {
+		t.Fatalf("head %q, tail %q (line %d), %d lines omitted; want \"aaa\\n\", \"ddd\\n\" (line 4), 2 lines", cut.head, cut.tail, cut.tailFirstLine, cut.omittedLines)
+	}
+}
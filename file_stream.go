@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- Streamed File Contents ---
+//
+// ReadFileContents returns every file in one response, which stalls the Wails bridge for
+// big selections. StreamFileContents reads the same files in a read_file_contents job and
+// emits them in batches as "fileContentsBatch" events, so the UI can render as files arrive
+// and stop the transfer with CancelJob. A batch is sent when it holds BatchSize files or
+// MaxBatchBytes of content, whichever comes first; a file larger than MaxBatchBytes goes
+// alone in its batch. Each result is the same FileContentResult ReadFileContents returns.
+
+const (
+	defaultStreamBatchSize  = 20
+	defaultStreamBatchBytes = 1024 * 1024
+)
+
+// FileContentStreamOptions are the parameters of a read_file_contents job
+type FileContentStreamOptions struct {
+	RootDir       string   `json:"rootDir"`       // Root directory path (for resolving relative paths)
+	Paths         []string `json:"paths"`         // Relative file paths to read, in order
+	BatchSize     int      `json:"batchSize"`     // Files per batch at most (0 for the default)
+	MaxBatchBytes int      `json:"maxBatchBytes"` // Content bytes per batch at most (0 for the default)
+}
+
+// FileContentBatch is a batch of streamed file contents
+type FileContentBatch struct {
+	JobID   string              `json:"jobId"`   // ID of the read_file_contents job
+	Index   int                 `json:"index"`   // Batch number (0-based)
+	Results []FileContentResult `json:"results"` // Files of the batch, in request order
+	Done    int                 `json:"done"`    // Files sent so far, this batch included
+	Total   int                 `json:"total"`   // Files requested
+	Last    bool                `json:"last"`    // True for the final batch
+}
+
+// FileContentStreamResult is the result of a read_file_contents job
+type FileContentStreamResult struct {
+	Files   int   `json:"files"`   // Files sent
+	Bytes   int64 `json:"bytes"`   // Content bytes sent
+	Batches int   `json:"batches"` // Batches emitted
+	Errors  int   `json:"errors"`  // Files with an error
+}
+
+// executeReadFileContentsJob implements the read_file_contents job type
+func (a *App) executeReadFileContentsJob(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var opts FileContentStreamOptions
+	if err := json.Unmarshal(params, &opts); err != nil {
+		return nil, fmt.Errorf("invalid read_file_contents parameters: %w", err)
+	}
+	if err := a.validateContentRoot(opts.RootDir); err != nil {
+		return nil, err
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultStreamBatchSize
+	}
+	if opts.MaxBatchBytes <= 0 {
+		opts.MaxBatchBytes = defaultStreamBatchBytes
+	}
+
+	jobID := jobIDFromContext(ctx)
+	var result FileContentStreamResult
+	batch := FileContentBatch{JobID: jobID, Total: len(opts.Paths)}
+	batchBytes := 0
+	flush := func(last bool) {
+		batch.Done, batch.Last = result.Files, last
+		runtime.EventsEmit(a.ctx, "fileContentsBatch", batch)
+		result.Batches++
+		batch = FileContentBatch{JobID: jobID, Index: batch.Index + 1, Total: batch.Total}
+		batchBytes = 0
+	}
+	for i, relPath := range opts.Paths {
+		if err := ctx.Err(); err != nil {
+			runtime.LogInfof(a.ctx, "Streaming file contents from %s cancelled after %d of %d files", opts.RootDir, result.Files, len(opts.Paths))
+			return result, err
+		}
+		content := a.readFileContent(opts.RootDir, relPath)
+		if len(batch.Results) > 0 && batchBytes+len(content.Content) > opts.MaxBatchBytes {
+			flush(false)
+		}
+		batch.Results = append(batch.Results, content)
+		batchBytes += len(content.Content)
+		result.Files++
+		result.Bytes += int64(len(content.Content))
+		if content.Error != "" {
+			result.Errors++
+		}
+		if len(batch.Results) >= opts.BatchSize && i < len(opts.Paths)-1 {
+			flush(false)
+		}
+	}
+	flush(true) // Sent even without paths, so the UI always learns the stream ended
+
+	runtime.LogInfof(a.ctx, "Streamed %d files (%d bytes) from %s in %d batches", result.Files, result.Bytes, opts.RootDir, result.Batches)
+	return result, nil
+}
+
+// ============================================================================
+// Streamed File Contents Methods (Wails-bound)
+// ============================================================================
+
+// StreamFileContents reads files in the background and emits them in batches
+// Batches arrive as "fileContentsBatch" events carrying the returned job ID; the last one has
+// Last set. CancelJob stops the transfer between two files.
+//
+// Parameters:
+//   - rootDir: Root directory path (for resolving relative paths)
+//   - relativePaths: Relative file paths to read, in order
+//   - batchSize: Files per batch at most (0 for the default)
+//
+// Returns:
+//   - string: Job ID of the read_file_contents job
+//   - error: Error if rootDir is invalid or the job cannot be enqueued
+func (a *App) StreamFileContents(rootDir string, relativePaths []string, batchSize int) (string, error) {
+	if a.jobQueue == nil {
+		return "", fmt.Errorf("job queue not initialized")
+	}
+	if relativePaths == nil {
+		return "", fmt.Errorf("relative paths array is nil")
+	}
+	if err := a.validateContentRoot(rootDir); err != nil {
+		return "", err
+	}
+	return a.jobQueue.Enqueue("read_file_contents", FileContentStreamOptions{
+		RootDir:   rootDir,
+		Paths:     relativePaths,
+		BatchSize: batchSize,
+	})
+}
@@ -0,0 +1,404 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- LLM File Summaries ---
+//
+// A huge file (a generated schema, a long data module, a single-file library) can take most of
+// a token budget when the model only needs to know what it contains. With FileSummaries set
+// (a size threshold and a provider), every selected file larger than the threshold is replaced
+// in generated context by a summary written by that provider:
+//
+//	<file path="db/schema.sql" summary="true" original-tokens="231000">
+//	...summary...
+//	</file>
+//
+// When a generation starts, the files over the threshold are summarized in the background by
+// file_summary jobs, at most fileSummaryConcurrency at a time, while the tree is walked; each
+// file's block waits for its job. Summaries are cached in <config dir>/file_summaries/, keyed by
+// the file's content, provider and model, so regenerating only calls the LLM for files that
+// changed. The API key is passed with the generation (GenerationOptions.SummaryAPIKey) and never
+// saved: a generation without one (a recovered or resumed one) uses cached summaries and
+// includes the other files in full. A file whose summary fails is included in full too.
+
+const (
+	fileSummaryConcurrency   = 4       // file_summary jobs a generation runs at once
+	maxFileSummaryInputBytes = 400_000 // Content sent to the LLM at most (~100k tokens)
+)
+
+// fileSummaryPromptTemplate asks for the summary that replaces a large file
+const fileSummaryPromptTemplate = `Summarize the file %s of a software project%s so a developer or another model can work with the rest of the project without reading it. Describe its purpose and structure, and list the main types, functions, exports, configuration keys or data shapes it defines, with their names exactly as written. Answer with the summary only, in at most 300 words.
+
+%s`
+
+// FileSummarySettings configures the LLM summaries of large files
+type FileSummarySettings struct {
+	MinBytes int    `json:"minBytes"` // Files larger than this are summarized (0 = off)
+	Provider string `json:"provider"` // LLM provider (google, openai, anthropic, custom)
+	Model    string `json:"model"`    // Model name (empty for the provider default)
+	BaseURL  string `json:"baseURL"`  // Base URL for the custom provider
+}
+
+// FileSummaryOptions are the parameters of a file_summary job
+type FileSummaryOptions struct {
+	Provider string `json:"provider"` // LLM provider (google, openai, anthropic, custom)
+	APIKey   string `json:"apiKey"`   // API key for the provider
+	Model    string `json:"model"`    // Model name (empty for provider default)
+	BaseURL  string `json:"baseURL"`  // Base URL for the custom provider
+	RootDir  string `json:"rootDir"`  // Project root directory
+	Path     string `json:"path"`     // File to summarize, relative to the root
+}
+
+// FileSummary is the LLM summary of a file
+type FileSummary struct {
+	Path           string `json:"path"`           // Path relative to the project root (forward slashes)
+	Summary        string `json:"summary"`        // Summary text
+	OriginalTokens int    `json:"originalTokens"` // Estimated tokens of the file's content
+	SummaryTokens  int    `json:"summaryTokens"`  // Estimated tokens of the summary
+	Cached         bool   `json:"cached"`         // True if the summary came from the cache
+}
+
+// fileSummaryTask is the summary of one file during a generation
+type fileSummaryTask struct {
+	done    chan struct{} // Closed when the summary is ready or will not come
+	summary *FileSummary  // nil if the file is included in full
+}
+
+// fileSummaryRun summarizes the large files of one generation
+type fileSummaryRun struct {
+	tasks   map[string]*fileSummaryTask // By relative path (forward slashes)
+	applied []FileSummary               // Summaries used, in generation order
+	cancel  context.CancelFunc          // Cancels the summaries still running
+}
+
+// fileSummaryCacheKey identifies the summary of a content written by a provider and model
+func fileSummaryCacheKey(provider, model, content string) string {
+	sum := sha256.Sum256([]byte(provider + "\x00" + model + "\x00" + content))
+	return hex.EncodeToString(sum[:])
+}
+
+// fileSummaryCachePath returns where the summary with a cache key is saved
+func (a *App) fileSummaryCachePath(key string) string {
+	return filepath.Join(filepath.Dir(a.configPath), "file_summaries", key+".txt")
+}
+
+// loadFileSummary returns a cached summary ("" and false if there is none)
+func (a *App) loadFileSummary(key string) (string, bool) {
+	if a.configPath == "" {
+		return "", false
+	}
+	data, err := os.ReadFile(a.fileSummaryCachePath(key))
+	if err != nil || len(data) == 0 {
+		return "", false
+	}
+	return string(data), true
+}
+
+// saveFileSummary caches a summary
+func (a *App) saveFileSummary(key, summary string) error {
+	if a.configPath == "" {
+		return nil
+	}
+	path := a.fileSummaryCachePath(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(summary), 0644)
+}
+
+// readSummarizableFile reads a project file to summarize
+func (a *App) readSummarizableFile(rootDir, relPath string) (string, error) {
+	absPath, err := resolveProjectPath(rootDir, relPath)
+	if err != nil {
+		return "", err
+	}
+	if isBinary, err := a.isBinaryFile(absPath); err != nil {
+		return "", err
+	} else if isBinary {
+		return "", fmt.Errorf("binary file")
+	}
+	data, err := a.projectFS.ReadFile(absPath)
+	if err != nil {
+		return "", err
+	}
+	if !utf8.Valid(data) {
+		return "", fmt.Errorf("invalid UTF-8")
+	}
+	return string(data), nil
+}
+
+// summarizeFile returns the summary of a file, from the cache or written by the LLM
+//
+// Parameters:
+//   - ctx: Context of the job
+//   - p: Provider settings, project root and file
+//   - cacheOnly: Return an error instead of calling the LLM if no summary is cached
+//
+// Returns:
+//   - *FileSummary: The summary
+//   - error: Error if the file cannot be read or the LLM call fails
+func (a *App) summarizeFile(ctx context.Context, p FileSummaryOptions, cacheOnly bool) (*FileSummary, error) {
+	relPath := filepath.ToSlash(normalizeRelPath(p.Path))
+	content, err := a.readSummarizableFile(p.RootDir, relPath)
+	if err != nil {
+		return nil, err
+	}
+	summary := &FileSummary{Path: relPath, OriginalTokens: a.EstimateTokens(content)}
+	key := fileSummaryCacheKey(p.Provider, p.Model, content)
+	if text, ok := a.loadFileSummary(key); ok {
+		summary.Summary, summary.SummaryTokens, summary.Cached = text, a.EstimateTokens(text), true
+		return summary, nil
+	}
+	if cacheOnly {
+		return nil, fmt.Errorf("no cached summary")
+	}
+
+	input, note := a.redactSecretsIfEnabled(relPath, content), ""
+	if len(input) > maxFileSummaryInputBytes {
+		note = fmt.Sprintf(" (only its first %s of %s are shown)", formatByteSize(maxFileSummaryInputBytes), formatByteSize(len(input)))
+		input = truncateUTF8(input, maxFileSummaryInputBytes)
+	}
+	prompt := fmt.Sprintf(fileSummaryPromptTemplate, relPath, note, fileBlock(relPath, "", input, a.fileBlockEscaping()))
+	a.tagJobProject(ctx, p.RootDir)
+	resp, err := NewLLMClient(a).CallLLM(ctx, LLMRequest{Provider: p.Provider, APIKey: p.APIKey, Model: p.Model, BaseURL: p.BaseURL, Prompt: prompt, RootDir: p.RootDir})
+	if err != nil {
+		return nil, err
+	}
+	a.recordUsage(llmCallEvent(p.RootDir, resp))
+
+	summary.Summary = strings.TrimSpace(a.postProcess(resp.Content))
+	if summary.Summary == "" {
+		return nil, fmt.Errorf("the model returned an empty summary")
+	}
+	summary.SummaryTokens = a.EstimateTokens(summary.Summary)
+	if err := a.saveFileSummary(key, summary.Summary); err != nil {
+		runtime.LogWarningf(a.ctx, "Cannot cache the summary of %s: %v", relPath, err)
+	}
+	return summary, nil
+}
+
+// executeFileSummaryJob implements the file_summary job type
+func (a *App) executeFileSummaryJob(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var p FileSummaryOptions
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, fmt.Errorf("invalid file_summary parameters: %w", err)
+	}
+	if err := a.validateContentRoot(p.RootDir); err != nil {
+		return nil, err
+	}
+	summary, err := a.summarizeFile(ctx, p, false)
+	if err != nil {
+		return nil, err
+	}
+	runtime.EventsEmit(a.ctx, "fileSummaryReady", summary)
+	return summary, nil
+}
+
+// fileSummaryBlock renders the context block that replaces a summarized file
+func (a *App) fileSummaryBlock(summary *FileSummary) string {
+	return fileBlock(summary.Path, fmt.Sprintf(` summary="true" original-tokens="%d"`, summary.OriginalTokens), summary.Summary, a.fileBlockEscaping())
+}
+
+// newFileSummaryRun starts summarizing the selected files over the threshold (nil if
+// FileSummaries is off or no file is large enough)
+//
+// Parameters:
+//   - ctx: Context of the generation (cancels the file_summary jobs)
+//   - rootDir: Project root directory
+//   - excludedPaths: The selection, expressed as exclusions
+//   - opts: Options of the generation (SummaryAPIKey)
+//   - changedOnly: Changed-only state of the generation (files it skips are not summarized)
+func (a *App) newFileSummaryRun(ctx context.Context, rootDir string, excludedPaths []string, opts GenerationOptions, changedOnly *changedOnlyRun) *fileSummaryRun {
+	settings := a.settings.FileSummaries
+	if settings.MinBytes <= 0 || settings.Provider == "" || a.jobQueue == nil || opts.RepoMap {
+		return nil
+	}
+	paths, err := a.selectedFilePaths(rootDir, excludedPaths)
+	if err != nil {
+		runtime.LogWarningf(a.ctx, "File summaries disabled for %s: %v", rootDir, err)
+		return nil
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	run := &fileSummaryRun{tasks: make(map[string]*fileSummaryTask), cancel: cancel}
+	cacheOnly := opts.SummaryAPIKey == ""
+	slots := make(chan struct{}, fileSummaryConcurrency)
+	for _, relPath := range paths {
+		if changedOnly.skips(filepath.FromSlash(relPath)) {
+			continue
+		}
+		info, err := a.projectFS.Stat(filepath.Join(rootDir, filepath.FromSlash(relPath)))
+		if err != nil || info.Size() <= int64(settings.MinBytes) {
+			continue
+		}
+		task := &fileSummaryTask{done: make(chan struct{})}
+		run.tasks[relPath] = task
+		go a.runFileSummaryTask(ctx, task, slots, cacheOnly, FileSummaryOptions{
+			Provider: settings.Provider, APIKey: opts.SummaryAPIKey, Model: settings.Model, BaseURL: settings.BaseURL,
+			RootDir: rootDir, Path: relPath,
+		})
+	}
+	if len(run.tasks) == 0 {
+		cancel()
+		return nil
+	}
+	runtime.LogInfof(a.ctx, "Summarizing %d files over %s in %s", len(run.tasks), formatByteSize(settings.MinBytes), rootDir)
+	return run
+}
+
+// runFileSummaryTask gets the summary of one file: from the cache, or from a file_summary job
+func (a *App) runFileSummaryTask(ctx context.Context, task *fileSummaryTask, slots chan struct{}, cacheOnly bool, p FileSummaryOptions) {
+	defer close(task.done)
+	select {
+	case slots <- struct{}{}:
+	case <-ctx.Done():
+		return
+	}
+	defer func() { <-slots }()
+	if summary, err := a.summarizeFile(ctx, p, true); err == nil {
+		task.summary = summary
+		return
+	}
+	if cacheOnly {
+		return
+	}
+
+	jobID, err := a.jobQueue.Enqueue("file_summary", p)
+	if err != nil {
+		runtime.LogWarningf(a.ctx, "Cannot summarize %s: %v", p.Path, err)
+		return
+	}
+	job, err := a.jobQueue.waitForJob(ctx, jobID)
+	if err != nil {
+		a.jobQueue.CancelJob(jobID) // The generation was cancelled
+		return
+	}
+	if summary, ok := job.Result.(*FileSummary); ok && job.Status == "completed" {
+		task.summary = summary
+	} else {
+		runtime.LogWarningf(a.ctx, "Including %s in full, its summary %s: %s", p.Path, job.Status, job.Error)
+	}
+}
+
+// wait returns the summary of a file once it is ready (nil if the file is included in full)
+func (r *fileSummaryRun) wait(ctx context.Context, relPath string) *FileSummary {
+	if r == nil {
+		return nil
+	}
+	task, ok := r.tasks[filepath.ToSlash(relPath)]
+	if !ok {
+		return nil
+	}
+	select {
+	case <-task.done:
+	case <-ctx.Done():
+		return nil
+	}
+	if task.summary != nil {
+		r.applied = append(r.applied, *task.summary)
+	}
+	return task.summary
+}
+
+// stop cancels the summaries a generation that ended will not use
+func (r *fileSummaryRun) stop() {
+	if r != nil {
+		r.cancel()
+	}
+}
+
+// emitFileSummaries logs and emits the summaries a generation used
+func (a *App) emitFileSummaries(rootDir string, r *fileSummaryRun) {
+	if r == nil {
+		return
+	}
+	tokensSaved, missing := 0, len(r.tasks)-len(r.applied)
+	for _, summary := range r.applied {
+		tokensSaved += summary.OriginalTokens - summary.SummaryTokens
+	}
+	runtime.LogInfof(a.ctx, "Summarized %d large files of %s (~%d tokens saved); %d included in full",
+		len(r.applied), rootDir, tokensSaved, missing)
+	a.emitGenerationEvent("fileSummariesApplied", map[string]interface{}{
+		"rootDir":     rootDir,
+		"files":       r.applied,
+		"notApplied":  missing,
+		"tokensSaved": tokensSaved,
+	})
+}
+
+// ============================================================================
+// File Summary Methods (Wails-bound)
+// ============================================================================
+
+// GetFileSummarySettings returns the size threshold and provider of large file summaries
+func (a *App) GetFileSummarySettings() FileSummarySettings {
+	return a.settings.FileSummaries
+}
+
+// SetFileSummarySettings sets the size threshold and provider of large file summaries and saves them
+//
+// Parameters:
+//   - settings: Threshold (0 turns summaries off), provider, model and custom base URL
+//
+// Returns:
+//   - error: Error if the threshold is negative, the provider is unknown, or saving fails
+func (a *App) SetFileSummarySettings(settings FileSummarySettings) error {
+	if settings.MinBytes < 0 {
+		return fmt.Errorf("file summary threshold must not be negative, got %d", settings.MinBytes)
+	}
+	if settings.MinBytes > 0 && !slices.Contains([]string{"google", "openai", "anthropic", "custom"}, settings.Provider) {
+		return fmt.Errorf("unknown provider for file summaries: %q", settings.Provider)
+	}
+	if settings.Provider == "custom" && settings.MinBytes > 0 && (settings.BaseURL == "" || settings.Model == "") {
+		return fmt.Errorf("baseURL and model are required for custom provider")
+	}
+	a.settings.FileSummaries = settings
+	if err := a.saveSettings(); err != nil {
+		return fmt.Errorf("failed to save file summary setting: %w", err)
+	}
+	runtime.LogInfof(a.ctx, "File summaries: files over %d bytes with %s", settings.MinBytes, settings.Provider)
+	return nil
+}
+
+// StartFileSummary summarizes one file in the background, e.g. to check a summary before generating
+// The summary is cached for generations and emitted as a "fileSummaryReady" event.
+//
+// Parameters:
+//   - opts: Provider settings, project root and file
+//
+// Returns:
+//   - string: Job ID of the file_summary job
+//   - error: Error if the project is not allowed or the job cannot be enqueued
+func (a *App) StartFileSummary(opts FileSummaryOptions) (string, error) {
+	if a.jobQueue == nil {
+		return "", fmt.Errorf("job queue not initialized")
+	}
+	if err := a.validateContentRoot(opts.RootDir); err != nil {
+		return "", err
+	}
+	return a.jobQueue.Enqueue("file_summary", opts)
+}
+
+// ClearFileSummaryCache deletes all cached file summaries
+func (a *App) ClearFileSummaryCache() error {
+	if a.configPath == "" {
+		return nil
+	}
+	if err := os.RemoveAll(filepath.Join(filepath.Dir(a.configPath), "file_summaries")); err != nil {
+		return fmt.Errorf("failed to clear file summary cache: %w", err)
+	}
+	runtime.LogInfo(a.ctx, "File summary cache cleared")
+	return nil
+}
@@ -96,6 +96,12 @@ export const useAppStore = defineStore('app', () => {
   /** Max tokens to generate */
   const maxTokens = ref(4096);
   
+  /** True once the user set the temperature (otherwise the mode's default applies) */
+  const temperatureSet = ref(false);
+  
+  /** True once the user set max tokens (otherwise the mode's default applies) */
+  const maxTokensSet = ref(false);
+  
   /** LLM response (generated diff) */
   const llmResponse = ref('');
   
@@ -424,6 +430,7 @@ export const useAppStore = defineStore('app', () => {
     const numTemp = Number(temp);
     if (!isNaN(numTemp) && numTemp >= 0 && numTemp <= 2) {
       temperature.value = numTemp;
+      temperatureSet.value = true;
     } else {
       console.error('Invalid temperature (must be 0-2):', temp);
       temperature.value = 0.7;
@@ -437,6 +444,7 @@ export const useAppStore = defineStore('app', () => {
     const numTokens = Number(tokens);
     if (!isNaN(numTokens) && numTokens > 0) {
       maxTokens.value = Math.floor(numTokens);
+      maxTokensSet.value = true;
     } else {
       console.error('Invalid max tokens (must be positive number):', tokens);
       maxTokens.value = 4096;
@@ -557,6 +565,8 @@ export const useAppStore = defineStore('app', () => {
       customBaseURL.value = '';
       temperature.value = 0.7;
       maxTokens.value = 4096;
+      temperatureSet.value = false;
+      maxTokensSet.value = false;
       llmResponse.value = '';
       linesPerSplit.value = 500;
       splitDiffs.value = [];
@@ -592,6 +602,8 @@ export const useAppStore = defineStore('app', () => {
     customBaseURL,
     temperature,
     maxTokens,
+    temperatureSet,
+    maxTokensSet,
     llmResponse,
     linesPerSplit,
     splitDiffs,
@@ -0,0 +1,351 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- Checkpointed Context Generation ---
+//
+// Generations of large projects periodically save their progress to
+// <config dir>/checkpoints/<project key>/:
+//   - checkpoint.json: metadata, written last so it always describes consistent data
+//   - contents.partial: file blocks generated so far
+//   - processed.txt: relative paths whose blocks are in contents.partial, one per line
+//
+// A cancelled or crashed generation can then be resumed with ResumeShotgunContextGeneration,
+// which reuses the saved blocks and only reads the remaining files.
+
+const (
+	checkpointMinItems      = 2000 // Generations with fewer items than this are not checkpointed
+	checkpointFileInterval  = 250  // Save a checkpoint after this many files
+	checkpointMaxAgeOfFlush = 10 * time.Second
+)
+
+// GenerationCheckpoint describes the saved progress of an unfinished context generation
+type GenerationCheckpoint struct {
+	RootDir           string          `json:"rootDir"`                     // Project root directory
+	ExcludedPaths     []string        `json:"excludedPaths"`               // Exclusions of the generation (resume uses the same ones)
+	Format            string          `json:"format"`                      // Output format of the saved blocks (xml or markdown)
+	LineNumbers       bool            `json:"lineNumbers"`                 // True if the saved blocks have line-numbered content
+	StripComments     bool            `json:"stripComments"`               // True if the saved blocks have comments stripped
+	ChangedSince      string          `json:"changedSince,omitempty"`      // Git ref only changed files got content for (empty for all files)
+	RepoMap           bool            `json:"repoMap,omitempty"`           // True if the saved blocks are repo maps
+	FileOrder         string          `json:"fileOrder,omitempty"`         // Order the blocks were saved in (empty for path)
+	MaxFileBlockBytes int             `json:"maxFileBlockBytes"`           // Block size limit the saved blocks were cut with (0 = none)
+	MaxFileBytes      int             `json:"maxFileBytes"`                // Per-file size cap of the saved blocks (0 = none)
+	FileCapKeepTail   bool            `json:"fileCapKeepTail"`             // True if capped files kept their tail
+	RedactSecrets     bool            `json:"redactSecrets,omitempty"`     // True if the saved blocks have secrets redacted
+	ContentFilters    []ContentFilter `json:"contentFilters,omitempty"`    // Enabled content filters the saved blocks went through
+	FileBlockEscaping string          `json:"fileBlockEscaping,omitempty"` // Escaping mode of the saved blocks (empty for auto)
+	LastPath          string          `json:"lastPath"`                    // Last file whose block was saved
+	FilesWritten      int             `json:"filesWritten"`                // Number of lines of processed.txt that are valid
+	PartialBytes      int64           `json:"partialBytes"`                // Number of bytes of contents.partial that are valid
+	CreatedAt         time.Time       `json:"createdAt"`                   // When the generation started
+	UpdatedAt         time.Time       `json:"updatedAt"`                   // When the checkpoint was last saved
+}
+
+// checkpointWriter saves generation progress in batches
+type checkpointWriter struct {
+	dir            string
+	checkpoint     GenerationCheckpoint
+	pendingContent strings.Builder
+	pendingPaths   []string
+	lastFlush      time.Time
+}
+
+// checkpointDir returns the directory holding the checkpoint of a project
+func (a *App) checkpointDir(rootDir string) string {
+	sum := sha256.Sum256([]byte(projectSettingsKey(rootDir)))
+	return filepath.Join(filepath.Dir(a.configPath), "checkpoints", hex.EncodeToString(sum[:8]))
+}
+
+// loadGenerationCheckpoint reads the checkpoint of a project
+//
+// Returns:
+//   - *GenerationCheckpoint: Checkpoint metadata, nil if there is none
+//   - string: Saved file blocks
+//   - map[string]bool: Relative paths whose blocks were saved
+//   - error: Error if the checkpoint exists but cannot be read
+func (a *App) loadGenerationCheckpoint(rootDir string) (*GenerationCheckpoint, string, map[string]bool, error) {
+	if a.configPath == "" {
+		return nil, "", nil, nil
+	}
+	dir := a.checkpointDir(rootDir)
+
+	data, err := os.ReadFile(filepath.Join(dir, "checkpoint.json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, "", nil, nil
+		}
+		return nil, "", nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+	var cp GenerationCheckpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, "", nil, fmt.Errorf("failed to parse checkpoint: %w", err)
+	}
+
+	// Only the prefix described by checkpoint.json is valid; anything after it was written by an interrupted flush
+	content, err := os.ReadFile(filepath.Join(dir, "contents.partial"))
+	if err != nil || int64(len(content)) < cp.PartialBytes {
+		return nil, "", nil, fmt.Errorf("checkpoint contents are missing or incomplete")
+	}
+	content = content[:cp.PartialBytes]
+
+	processed := make(map[string]bool, cp.FilesWritten)
+	f, err := os.Open(filepath.Join(dir, "processed.txt"))
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("failed to read checkpoint paths: %w", err)
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for len(processed) < cp.FilesWritten && scanner.Scan() {
+		processed[scanner.Text()] = true
+	}
+	if len(processed) < cp.FilesWritten {
+		return nil, "", nil, fmt.Errorf("checkpoint paths are incomplete")
+	}
+
+	return &cp, string(content), processed, nil
+}
+
+// newCheckpointWriter starts a checkpoint for a generation, continuing from resumed if non-nil
+func (a *App) newCheckpointWriter(rootDir string, excludedPaths []string, resumed *GenerationCheckpoint, opts GenerationOptions) (*checkpointWriter, error) {
+	if a.configPath == "" {
+		return nil, fmt.Errorf("config path is not set, cannot save checkpoints")
+	}
+	dir := a.checkpointDir(rootDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create checkpoint directory: %w", err)
+	}
+
+	w := &checkpointWriter{dir: dir, lastFlush: time.Now()}
+	if resumed != nil {
+		w.checkpoint = *resumed
+		// Drop any bytes/lines written after the last valid checkpoint
+		if err := os.Truncate(filepath.Join(dir, "contents.partial"), resumed.PartialBytes); err != nil {
+			return nil, fmt.Errorf("failed to truncate checkpoint contents: %w", err)
+		}
+		if err := truncateLines(filepath.Join(dir, "processed.txt"), resumed.FilesWritten); err != nil {
+			return nil, err
+		}
+		return w, nil
+	}
+
+	w.checkpoint = GenerationCheckpoint{
+		RootDir:           rootDir,
+		ExcludedPaths:     append([]string{}, excludedPaths...),
+		Format:            a.outputFormat(),
+		LineNumbers:       a.settings.LineNumbers,
+		StripComments:     opts.StripComments,
+		ChangedSince:      opts.ChangedSince,
+		RepoMap:           opts.RepoMap,
+		FileOrder:         a.fileOrder(),
+		MaxFileBlockBytes: a.settings.MaxFileBlockBytes,
+		MaxFileBytes:      a.settings.MaxFileBytes,
+		FileCapKeepTail:   a.settings.FileCapKeepTail,
+		RedactSecrets:     a.settings.RedactSecrets,
+		ContentFilters:    a.enabledContentFilters(),
+		FileBlockEscaping: a.fileBlockEscaping(),
+		CreatedAt:         time.Now(),
+	}
+	for _, name := range []string{"contents.partial", "processed.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			return nil, fmt.Errorf("failed to initialize checkpoint: %w", err)
+		}
+	}
+	return w, w.writeMetadata()
+}
+
+// addFile records the block generated for a file and saves a checkpoint when a batch is full
+func (w *checkpointWriter) addFile(relPath, block string) error {
+	w.pendingContent.WriteString(block)
+	w.pendingPaths = append(w.pendingPaths, relPath)
+	w.checkpoint.LastPath = relPath
+	if len(w.pendingPaths) >= checkpointFileInterval || time.Since(w.lastFlush) >= checkpointMaxAgeOfFlush {
+		return w.flush()
+	}
+	return nil
+}
+
+// flush appends the pending blocks and paths, then updates checkpoint.json
+func (w *checkpointWriter) flush() error {
+	if len(w.pendingPaths) == 0 {
+		return nil
+	}
+	if err := appendToFile(filepath.Join(w.dir, "contents.partial"), w.pendingContent.String()); err != nil {
+		return err
+	}
+	if err := appendToFile(filepath.Join(w.dir, "processed.txt"), strings.Join(w.pendingPaths, "\n")+"\n"); err != nil {
+		return err
+	}
+
+	w.checkpoint.PartialBytes += int64(w.pendingContent.Len())
+	w.checkpoint.FilesWritten += len(w.pendingPaths)
+	w.pendingContent.Reset()
+	w.pendingPaths = nil
+	w.lastFlush = time.Now()
+	return w.writeMetadata()
+}
+
+// writeMetadata atomically replaces checkpoint.json
+func (w *checkpointWriter) writeMetadata() error {
+	w.checkpoint.UpdatedAt = time.Now()
+	data, err := json.MarshalIndent(w.checkpoint, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode checkpoint: %w", err)
+	}
+	tmpPath := filepath.Join(w.dir, "checkpoint.json.tmp")
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	return os.Rename(tmpPath, filepath.Join(w.dir, "checkpoint.json"))
+}
+
+// discard removes the checkpoint (after a successful generation)
+func (w *checkpointWriter) discard() {
+	os.RemoveAll(w.dir)
+}
+
+// appendToFile appends data to a file
+func appendToFile(path, data string) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	if _, err := f.WriteString(data); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to append to %s: %w", path, err)
+	}
+	return f.Close()
+}
+
+// truncateLines keeps only the first n lines of a file
+func truncateLines(path string, n int) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	offset := 0
+	for i := 0; i < n; i++ {
+		next := strings.IndexByte(string(data[offset:]), '\n')
+		if next < 0 {
+			return fmt.Errorf("%s has fewer than %d lines", path, n)
+		}
+		offset += next + 1
+	}
+	return os.Truncate(path, int64(offset))
+}
+
+// sameExclusions reports whether two exclusion lists contain the same paths
+func sameExclusions(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	sortedA := append([]string{}, a...)
+	sortedB := append([]string{}, b...)
+	sort.Strings(sortedA)
+	sort.Strings(sortedB)
+	for i := range sortedA {
+		if sortedA[i] != sortedB[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// outputFormat returns the output format of the saved blocks (checkpoints without one are xml)
+func (cp *GenerationCheckpoint) outputFormat() string {
+	if cp.Format == "" {
+		return outputFormatXML
+	}
+	return cp.Format
+}
+
+// fileOrder returns the order the saved blocks were generated in
+func (cp *GenerationCheckpoint) fileOrder() string {
+	if cp.FileOrder == "" {
+		return fileOrderPath
+	}
+	return cp.FileOrder
+}
+
+// fileSizeCap returns the per-file size cap the saved blocks were generated with
+func (cp *GenerationCheckpoint) fileSizeCap() FileSizeCap {
+	return FileSizeCap{MaxBytes: cp.MaxFileBytes, KeepTail: cp.FileCapKeepTail}
+}
+
+// fileBlockEscaping returns the escaping mode the saved blocks were generated with
+func (cp *GenerationCheckpoint) fileBlockEscaping() string {
+	if cp.FileBlockEscaping == "" {
+		return fileBlockEscapingAuto
+	}
+	return cp.FileBlockEscaping
+}
+
+// sameContentRules reports whether the saved blocks were redacted, filtered and escaped as
+// the next blocks would be
+func (a *App) sameContentRules(cp *GenerationCheckpoint) bool {
+	return cp.RedactSecrets == a.settings.RedactSecrets && slices.Equal(cp.ContentFilters, a.enabledContentFilters()) &&
+		cp.fileBlockEscaping() == a.fileBlockEscaping()
+}
+
+// ============================================================================
+// Checkpoint Methods (Wails-bound)
+// ============================================================================
+
+// GetGenerationCheckpoint returns the saved progress of an unfinished generation of a project
+//
+// Returns:
+//   - *GenerationCheckpoint: Checkpoint, or nil if the project has none (or it is unusable)
+func (a *App) GetGenerationCheckpoint(rootDir string) *GenerationCheckpoint {
+	cp, _, _, err := a.loadGenerationCheckpoint(rootDir)
+	if err != nil {
+		runtime.LogWarningf(a.ctx, "Ignoring unusable generation checkpoint for %s: %v", rootDir, err)
+		return nil
+	}
+	return cp
+}
+
+// ResumeShotgunContextGeneration resumes an unfinished generation of a project from its checkpoint,
+// with the exclusions it was started with. Results are emitted like RequestShotgunContextGeneration.
+func (a *App) ResumeShotgunContextGeneration(rootDir string) error {
+	if a.contextGenerator == nil {
+		return fmt.Errorf("context generator not initialized")
+	}
+	cp, _, _, err := a.loadGenerationCheckpoint(rootDir)
+	if err != nil {
+		return fmt.Errorf("cannot resume generation: %w", err)
+	}
+	if cp == nil {
+		return fmt.Errorf("no generation checkpoint found for %s", rootDir)
+	}
+
+	runtime.LogInfof(a.ctx, "Resuming context generation for %s after %d files", rootDir, cp.FilesWritten)
+	a.contextGenerator.requestShotgunContextGenerationInternal(rootDir, cp.ExcludedPaths, true, GenerationOptions{StripComments: cp.StripComments, ChangedSince: cp.ChangedSince, RepoMap: cp.RepoMap})
+	return nil
+}
+
+// DiscardGenerationCheckpoint deletes the saved progress of an unfinished generation of a project
+func (a *App) DiscardGenerationCheckpoint(rootDir string) error {
+	if a.configPath == "" {
+		return nil
+	}
+	if err := os.RemoveAll(a.checkpointDir(rootDir)); err != nil {
+		return fmt.Errorf("failed to discard generation checkpoint: %w", err)
+	}
+	runtime.LogInfof(a.ctx, "Discarded generation checkpoint for %s", rootDir)
+	return nil
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- Selection Estimates and Large-Project Confirmation Gate ---
+
+// Default thresholds above which generation asks for confirmation first
+const (
+	defaultConfirmFileThreshold  = 5000
+	defaultConfirmTokenThreshold = 1000000
+)
+
+// SelectionStats summarizes the files a context generation would include
+type SelectionStats struct {
+	RootDir         string `json:"rootDir"`         // Project root directory
+	FileCount       int    `json:"fileCount"`       // Number of files that would be included
+	DirCount        int    `json:"dirCount"`        // Number of directories that would be listed
+	TotalBytes      int64  `json:"totalBytes"`      // Total size of the included files
+	EstimatedTokens int    `json:"estimatedTokens"` // Approximate tokens (bytes / 4, like EstimateTokens)
+}
+
+// DirectoryEstimate holds the totals of the included files under a directory (recursively)
+type DirectoryEstimate struct {
+	Path            string `json:"path"`            // Relative directory path ("." for the project root)
+	FileCount       int    `json:"fileCount"`       // Number of included files under the directory
+	TotalBytes      int64  `json:"totalBytes"`      // Total size of those files
+	EstimatedTokens int    `json:"estimatedTokens"` // Approximate tokens (bytes / 4)
+}
+
+// ContextEstimate is the result of a dry-run generation
+type ContextEstimate struct {
+	SelectionStats
+	Directories []DirectoryEstimate `json:"directories"` // Per-directory totals, largest first
+	DurationMs  int64               `json:"durationMs"`  // Time the estimate took
+}
+
+// scanSelection counts the files and bytes a generation of rootDir would include,
+// applying the same exclusions and ignore rules as generateShotgunOutputWithProgress
+//
+// Parameters:
+//   - ctx: Context for cancellation
+//   - rootDir: Project root directory
+//   - excludedPaths: Relative paths excluded by the user
+//   - visitFile: Optional callback receiving the relative path and size of every included file
+//
+// Returns:
+//   - SelectionStats: Counts for the selection
+//   - error: Error if the scan was cancelled
+func (a *App) scanSelection(ctx context.Context, rootDir string, excludedPaths []string, visitFile func(relPath string, size int64)) (SelectionStats, error) {
+	stats := SelectionStats{RootDir: rootDir}
+
+	excludedMap := make(map[string]bool, len(excludedPaths))
+	for _, p := range excludedPaths {
+		excludedMap[p] = true
+	}
+	ignoreOpts := a.newTreeBuildOptions(rootDir, a.compileProjectGitignore(rootDir))
+
+	err := a.projectFS.WalkDir(rootDir, func(path string, d fs.DirEntry, walkErr error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if walkErr != nil {
+			// Unreadable entries are skipped, as in generation
+			if d != nil && d.IsDir() && path != rootDir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if path == rootDir {
+			return nil
+		}
+
+		relPath, _ := filepath.Rel(rootDir, path)
+		if excludedMap[relPath] || ignoreOpts.excludes(relPath, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if d.IsDir() {
+			stats.DirCount++
+			if ignoreOpts.summaryOnly[relPath] {
+				return filepath.SkipDir // Generated as a short summary, not its files
+			}
+			return nil
+		}
+		stats.FileCount++
+		var size int64
+		if info, err := d.Info(); err == nil {
+			size = info.Size()
+		}
+		stats.TotalBytes += size
+		if visitFile != nil {
+			visitFile(relPath, size)
+		}
+		return nil
+	})
+	if err != nil {
+		return stats, err
+	}
+
+	stats.EstimatedTokens = int(stats.TotalBytes / 4)
+	return stats, nil
+}
+
+// exceedsConfirmThresholds reports whether a selection is large enough to require confirmation
+// A threshold of 0 disables that check
+func (a *App) exceedsConfirmThresholds(stats SelectionStats) bool {
+	fileThreshold := a.settings.ConfirmFileThreshold
+	tokenThreshold := a.settings.ConfirmTokenThreshold
+	return (fileThreshold > 0 && stats.FileCount > fileThreshold) ||
+		(tokenThreshold > 0 && stats.EstimatedTokens > tokenThreshold)
+}
+
+// needsGenerationConfirmation scans the selection and, if it exceeds the configured thresholds,
+// emits "shotgunContextConfirmationRequired" with the numbers instead of starting generation.
+// The frontend then calls ConfirmShotgunContextGeneration (or its WithOptions variant) to proceed.
+//
+// Returns:
+//   - bool: True if generation must wait for confirmation
+func (a *App) needsGenerationConfirmation(rootDir string, excludedPaths []string, opts GenerationOptions) bool {
+	if a.settings.ConfirmFileThreshold <= 0 && a.settings.ConfirmTokenThreshold <= 0 {
+		return false
+	}
+
+	stats, err := a.scanSelection(a.ctx, rootDir, excludedPaths, nil)
+	if err != nil {
+		runtime.LogWarningf(a.ctx, "Large-project check failed for %s: %v (proceeding)", rootDir, err)
+		return false
+	}
+	if !a.exceedsConfirmThresholds(stats) {
+		return false
+	}
+
+	runtime.LogInfof(a.ctx, "Selection in %s is large (%d files, ~%d tokens); asking for confirmation", rootDir, stats.FileCount, stats.EstimatedTokens)
+	runtime.EventsEmit(a.ctx, "shotgunContextConfirmationRequired", map[string]interface{}{
+		"stats":         stats,
+		"excludedPaths": excludedPaths,
+		"options":       opts,
+	})
+	return true
+}
+
+// EstimateContextGeneration performs a dry run of context generation: it walks the selection
+// with the same exclusions and ignore rules but reads no file contents, so it returns in a
+// fraction of the time of a full generation.
+//
+// Parameters:
+//   - rootDir: Project root directory
+//   - excludedPaths: Relative paths excluded by the user
+//
+// Returns:
+//   - ContextEstimate: Overall and per-directory file counts, sizes and token estimates
+//   - error: Error if the directory does not exist or the walk fails
+func (a *App) EstimateContextGeneration(rootDir string, excludedPaths []string) (ContextEstimate, error) {
+	if err := a.validateContentRoot(rootDir); err != nil {
+		return ContextEstimate{}, err
+	}
+
+	start := time.Now()
+	dirs := make(map[string]*DirectoryEstimate)
+	stats, err := a.scanSelection(a.ctx, rootDir, excludedPaths, func(relPath string, size int64) {
+		// Add the file to every ancestor directory, up to the root
+		dir := filepath.Dir(relPath)
+		for {
+			est, ok := dirs[dir]
+			if !ok {
+				est = &DirectoryEstimate{Path: filepath.ToSlash(dir)}
+				dirs[dir] = est
+			}
+			est.FileCount++
+			est.TotalBytes += size
+			if dir == "." {
+				break
+			}
+			dir = filepath.Dir(dir)
+		}
+	})
+	if err != nil {
+		return ContextEstimate{}, fmt.Errorf("failed to estimate context: %w", err)
+	}
+
+	estimate := ContextEstimate{
+		SelectionStats: stats,
+		Directories:    make([]DirectoryEstimate, 0, len(dirs)),
+		DurationMs:     time.Since(start).Milliseconds(),
+	}
+	for _, est := range dirs {
+		est.EstimatedTokens = int(est.TotalBytes / 4)
+		estimate.Directories = append(estimate.Directories, *est)
+	}
+	sort.Slice(estimate.Directories, func(i, j int) bool {
+		di, dj := estimate.Directories[i], estimate.Directories[j]
+		if di.TotalBytes != dj.TotalBytes {
+			return di.TotalBytes > dj.TotalBytes
+		}
+		return di.Path < dj.Path
+	})
+
+	runtime.LogInfof(a.ctx, "Estimated context for %s: %d files, ~%d tokens (%d ms)", rootDir, stats.FileCount, stats.EstimatedTokens, estimate.DurationMs)
+	return estimate, nil
+}
+
+// ConfirmShotgunContextGeneration starts a generation the user confirmed after a
+// "shotgunContextConfirmationRequired" event, skipping the large-project check.
+func (a *App) ConfirmShotgunContextGeneration(rootDir string, excludedPaths []string) {
+	a.requestShotgunContextGeneration(rootDir, excludedPaths, false, GenerationOptions{})
+}
+
+// GetConfirmThresholds returns the file and token counts above which generation asks for confirmation
+func (a *App) GetConfirmThresholds() map[string]int {
+	return map[string]int{
+		"fileThreshold":  a.settings.ConfirmFileThreshold,
+		"tokenThreshold": a.settings.ConfirmTokenThreshold,
+	}
+}
+
+// SetConfirmThresholds updates the large-project confirmation thresholds and saves them.
+// 0 disables a threshold; negative values are treated as 0.
+func (a *App) SetConfirmThresholds(fileThreshold, tokenThreshold int) error {
+	if fileThreshold < 0 {
+		fileThreshold = 0
+	}
+	if tokenThreshold < 0 {
+		tokenThreshold = 0
+	}
+	a.settings.ConfirmFileThreshold = fileThreshold
+	a.settings.ConfirmTokenThreshold = tokenThreshold
+	if err := a.saveSettings(); err != nil {
+		return fmt.Errorf("failed to save confirmation thresholds: %w", err)
+	}
+	runtime.LogInfof(a.ctx, "Confirmation thresholds set to: %d files, %d tokens", fileThreshold, tokenThreshold)
+	return nil
+}
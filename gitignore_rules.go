@@ -0,0 +1,122 @@
+package main
+
+import (
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+
+	gitignore "github.com/sabhiram/go-gitignore"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- Project .gitignore Rules ---
+//
+// A project's .gitignore rules combine the root .gitignore with the .gitignore files of its
+// subdirectories. The patterns of a nested file are rewritten relative to the project root, so
+// one compiled matcher covers the whole tree. Like git, the search does not descend into
+// directories already ignored by the rules found so far.
+//
+// The rules of the open project are kept in a.projectGitignore. They are re-parsed when the
+// project is listed, the project root changes, the useGitignore toggle changes, or the
+// watcher sees a .gitignore file change.
+
+// rebaseGitignoreLine rewrites a pattern of the .gitignore in dir (slash-separated, relative
+// to the project root) so it matches the same paths relative to the root
+// Returns "" for blank lines and comments.
+func rebaseGitignoreLine(dir, line string) string {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ""
+	}
+	negate := strings.HasPrefix(line, "!")
+	pattern := strings.TrimPrefix(line, "!")
+	// A slash at the start or in the middle anchors the pattern to the .gitignore's directory
+	anchored := strings.Contains(strings.TrimSuffix(pattern, "/"), "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+	if anchored || strings.HasPrefix(pattern, "**/") {
+		pattern = "/" + dir + "/" + pattern
+	} else {
+		pattern = "/" + dir + "/**/" + pattern
+	}
+	if negate {
+		pattern = "!" + pattern
+	}
+	return pattern
+}
+
+// readGitignoreLines returns the lines of a .gitignore file (nil if it cannot be read)
+func (a *App) readGitignoreLines(path string) []string {
+	content, err := a.projectFS.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	return strings.Split(strings.ReplaceAll(string(content), "\r\n", "\n"), "\n")
+}
+
+// compileProjectGitignore compiles the .gitignore rules of a project, including the
+// .gitignore files of its subdirectories
+// Returns nil if the project has no .gitignore files.
+func (a *App) compileProjectGitignore(rootDir string) *gitignore.GitIgnore {
+	lines := a.readGitignoreLines(filepath.Join(rootDir, ".gitignore"))
+	found := lines != nil
+	var ign *gitignore.GitIgnore
+	if found {
+		ign = gitignore.CompileIgnoreLines(lines...)
+	}
+
+	a.projectFS.WalkDir(rootDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() || p == rootDir {
+			return nil
+		}
+		if d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		rel, relErr := filepath.Rel(rootDir, p)
+		if relErr != nil {
+			return nil
+		}
+		if ign != nil && ign.MatchesPath(rel+string(filepath.Separator)) {
+			return filepath.SkipDir
+		}
+		nested := a.readGitignoreLines(filepath.Join(p, ".gitignore"))
+		if nested == nil {
+			return nil
+		}
+		dir := path.Clean(filepath.ToSlash(rel))
+		for _, line := range nested {
+			if rebased := rebaseGitignoreLine(dir, line); rebased != "" {
+				lines = append(lines, rebased)
+			}
+		}
+		found = true
+		ign = gitignore.CompileIgnoreLines(lines...)
+		return nil
+	})
+
+	if !found {
+		return nil
+	}
+	return ign
+}
+
+// reloadProjectGitignore re-parses the .gitignore rules of the open project
+// The watcher picks up the new rules if it watches rootDir.
+func (a *App) reloadProjectGitignore(rootDir string) *gitignore.GitIgnore {
+	a.projectGitignore = a.compileProjectGitignore(rootDir)
+	a.projectGitignoreRoot = rootDir
+	runtime.LogDebugf(a.ctx, "Reloaded .gitignore rules for %s (found: %v)", rootDir, a.projectGitignore != nil)
+
+	if w := a.fileWatcher; w != nil {
+		w.mu.Lock()
+		if w.rootDir == rootDir {
+			if a.useGitignore {
+				w.currentProjectGitignore = a.projectGitignore
+			} else {
+				w.currentProjectGitignore = nil
+			}
+		}
+		w.mu.Unlock()
+	}
+	return a.projectGitignore
+}
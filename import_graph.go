@@ -0,0 +1,335 @@
+package main
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- Import Graph and Dependency Expansion ---
+//
+// The import graph links the source files of a project to the project files they import:
+//   - Go: imports of packages of the project's modules (every non-test file of the package)
+//   - JavaScript/TypeScript: relative imports and requires, and imports of workspace packages
+//     (see workspace_packages.go), resolved like bundlers do (extensions, index files)
+//   - Python: absolute imports of project modules (from the root or src/) and relative imports
+//
+// ExpandSelectionByDependencies follows the graph from the selected files, a number of hops
+// deep, so the LLM sees the code the selection calls and, optionally, the code calling it.
+// Imports of third-party code are ignored. The graph is built on demand from the files that
+// ignore rules do not hide; files over maxImportScanSize are not parsed.
+
+const maxImportScanSize = 1024 * 1024 // Files larger than this are not scanned for imports
+
+// jsImportRegex extracts the specifiers of import/export ... from, import() and require()
+var jsImportRegex = regexp.MustCompile(`(?m)(?:\bfrom\s*|\bimport\s*\(?\s*|\brequire\s*\(\s*)["']([^"'\n]+)["']`)
+
+// pyImportRegex extracts the modules of import and from ... import statements
+var pyImportRegex = regexp.MustCompile(`(?m)^\s*(?:from\s+(\.*[\w.]*)\s+import\s+([\w., ]+|\()|import\s+([\w., ]+))`)
+
+// jsExtensions are tried, in order, to resolve an extensionless JavaScript import
+var jsExtensions = []string{".ts", ".tsx", ".js", ".jsx", ".mjs", ".cjs", ".vue", ".svelte"}
+
+// ExpandedFile is a file added to a selection by dependency expansion
+type ExpandedFile struct {
+	RelPath   string `json:"relPath"`   // Path relative to the project root (forward slashes)
+	Via       string `json:"via"`       // Selected or added file it was reached from
+	Depth     int    `json:"depth"`     // Hops from the selection (1 = direct)
+	Direction string `json:"direction"` // imports (Via imports it) or importedBy (it imports Via)
+}
+
+// DependencyExpansion is the result of ExpandSelectionByDependencies
+type DependencyExpansion struct {
+	Added         []ExpandedFile `json:"added"`         // Files added, by depth then path
+	ExcludedPaths []string       `json:"excludedPaths"` // The new selection expressed as exclusions, sorted
+}
+
+// importGraph holds the resolved imports between project files (forward slash paths)
+type importGraph struct {
+	imports    map[string][]string // Files imported by each file
+	importedBy map[string][]string // Files importing each file
+}
+
+// goModule is a Go module of the project
+type goModule struct {
+	path string // Module path
+	dir  string // Module directory relative to the root ("." for the root)
+}
+
+// resolveJSImport resolves a relative JavaScript import to a project file ("" if none)
+func resolveJSImport(files map[string]selectionEntry, target string) string {
+	target = path.Clean(target)
+	candidates := []string{target}
+	for _, ext := range jsExtensions {
+		candidates = append(candidates, target+ext)
+	}
+	for _, ext := range jsExtensions {
+		candidates = append(candidates, path.Join(target, "index"+ext))
+	}
+	if strings.HasSuffix(target, ".js") {
+		// TypeScript sources imported with their compiled extension
+		base := strings.TrimSuffix(target, ".js")
+		candidates = append(candidates, base+".ts", base+".tsx")
+	}
+	for _, candidate := range candidates {
+		if entry, ok := files[candidate]; ok && !entry.isDir {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// resolvePyModule resolves a dotted Python module to a project file ("" if none)
+func resolvePyModule(files map[string]selectionEntry, baseDirs []string, module string) string {
+	rel := strings.ReplaceAll(module, ".", "/")
+	for _, base := range baseDirs {
+		for _, candidate := range []string{path.Join(base, rel+".py"), path.Join(base, rel, "__init__.py")} {
+			if _, ok := files[candidate]; ok {
+				return candidate
+			}
+		}
+	}
+	return ""
+}
+
+// fileImports returns the project files a source file imports
+func fileImports(relPath string, content []byte, files map[string]selectionEntry, goFiles map[string][]string, modules []goModule, workspace map[string]string) []string {
+	var targets []string
+	dir := path.Dir(relPath)
+	switch ext := path.Ext(relPath); {
+	case ext == ".go":
+		parsed, err := parser.ParseFile(token.NewFileSet(), relPath, content, parser.ImportsOnly)
+		if err != nil {
+			return nil
+		}
+		for _, spec := range parsed.Imports {
+			importPath, _ := strconv.Unquote(spec.Path.Value)
+			for _, mod := range modules {
+				if importPath != mod.path && !strings.HasPrefix(importPath, mod.path+"/") {
+					continue
+				}
+				pkgDir := path.Join(mod.dir, strings.TrimPrefix(importPath, mod.path))
+				targets = append(targets, goFiles[pkgDir]...)
+				break
+			}
+		}
+	case ext == ".py":
+		for _, m := range pyImportRegex.FindAllStringSubmatch(string(content), -1) {
+			var modules []string
+			baseDirs := []string{".", "src"}
+			switch {
+			case m[3] != "":
+				for _, name := range strings.Split(m[3], ",") {
+					if fields := strings.Fields(name); len(fields) > 0 {
+						modules = append(modules, fields[0])
+					}
+				}
+			case strings.HasPrefix(m[1], "."):
+				// Relative import: one dot is the file's package, each further dot a parent
+				level := len(m[1]) - len(strings.TrimLeft(m[1], "."))
+				base := dir
+				for i := 1; i < level; i++ {
+					base = path.Dir(base)
+				}
+				baseDirs = []string{base}
+				module := strings.TrimLeft(m[1], ".")
+				if module != "" {
+					modules = append(modules, module)
+				}
+				if m[2] != "(" {
+					// from . import a, b may name submodules
+					for _, name := range strings.Split(m[2], ",") {
+						if fields := strings.Fields(name); len(fields) > 0 {
+							modules = append(modules, strings.TrimPrefix(module+"."+fields[0], "."))
+						}
+					}
+				}
+			default:
+				modules = append(modules, m[1])
+			}
+			for _, module := range modules {
+				if target := resolvePyModule(files, baseDirs, module); target != "" {
+					targets = append(targets, target)
+				}
+			}
+		}
+	default:
+		isJS := false
+		for _, jsExt := range jsExtensions {
+			isJS = isJS || ext == jsExt
+		}
+		if !isJS {
+			return nil
+		}
+		for _, m := range jsImportRegex.FindAllStringSubmatch(string(content), -1) {
+			spec := m[1]
+			target := ""
+			if strings.HasPrefix(spec, ".") {
+				target = resolveJSImport(files, path.Join(dir, spec))
+			} else {
+				// Workspace package, with an optional subpath
+				for name, pkgDir := range workspace {
+					if spec == name || strings.HasPrefix(spec, name+"/") {
+						sub := strings.TrimPrefix(spec, name)
+						if target = resolveJSImport(files, path.Join(pkgDir, sub)); target == "" && sub == "" {
+							target = resolveJSImport(files, path.Join(pkgDir, "src", "index"))
+						}
+						break
+					}
+				}
+			}
+			if target != "" {
+				targets = append(targets, target)
+			}
+		}
+	}
+	return targets
+}
+
+// buildImportGraph resolves the imports between the files of a project
+func (a *App) buildImportGraph(rootDir string, files map[string]selectionEntry) *importGraph {
+	graph := &importGraph{imports: make(map[string][]string), importedBy: make(map[string][]string)}
+
+	// Go modules and packages
+	var modules []goModule
+	goFiles := make(map[string][]string)
+	for relPath := range files {
+		switch {
+		case path.Base(relPath) == "go.mod":
+			if data, err := a.projectFS.ReadFile(files[relPath].absPath); err == nil {
+				if m := goModuleRegex.FindSubmatch(data); m != nil {
+					modules = append(modules, goModule{path: string(m[1]), dir: path.Dir(relPath)})
+				}
+			}
+		case strings.HasSuffix(relPath, ".go") && !strings.HasSuffix(relPath, "_test.go"):
+			goFiles[path.Dir(relPath)] = append(goFiles[path.Dir(relPath)], relPath)
+		}
+	}
+	// Longest module paths first, so nested modules win over their parents
+	sort.Slice(modules, func(i, j int) bool { return len(modules[i].path) > len(modules[j].path) })
+
+	// JavaScript workspace packages by name
+	workspace := make(map[string]string)
+	for _, pkg := range a.detectWorkspace(rootDir).Packages {
+		if pkg.Ecosystem == "Node.js" {
+			workspace[pkg.Name] = pkg.Path
+		}
+	}
+
+	for relPath, entry := range files {
+		if entry.size > maxImportScanSize {
+			continue
+		}
+		switch ext := path.Ext(relPath); ext {
+		case ".go", ".py", ".ts", ".tsx", ".js", ".jsx", ".mjs", ".cjs", ".vue", ".svelte":
+		default:
+			continue
+		}
+		content, err := a.projectFS.ReadFile(entry.absPath)
+		if err != nil {
+			continue
+		}
+		seen := map[string]bool{relPath: true}
+		for _, target := range fileImports(relPath, content, files, goFiles, modules, workspace) {
+			if !seen[target] {
+				seen[target] = true
+				graph.imports[relPath] = append(graph.imports[relPath], target)
+				graph.importedBy[target] = append(graph.importedBy[target], relPath)
+			}
+		}
+	}
+	return graph
+}
+
+// ============================================================================
+// Dependency Expansion Methods (Wails-bound)
+// ============================================================================
+
+// ExpandSelectionByDependencies adds the project files a selection imports, following the
+// import graph up to depth hops
+//
+// Parameters:
+//   - rootDir: Project root directory
+//   - excludedPaths: The current selection, expressed as exclusions
+//   - depth: Hops to follow (1 = direct imports only)
+//   - includeImporters: Also add the files importing the selection (its callers)
+//
+// Returns:
+//   - DependencyExpansion: Files added and the new exclusions
+//   - error: Error if depth is not positive or the project cannot be walked
+func (a *App) ExpandSelectionByDependencies(rootDir string, excludedPaths []string, depth int, includeImporters bool) (DependencyExpansion, error) {
+	if depth <= 0 {
+		return DependencyExpansion{}, fmt.Errorf("depth must be at least 1, got %d", depth)
+	}
+	if err := a.validateContentRoot(rootDir); err != nil {
+		return DependencyExpansion{}, err
+	}
+	_, files, err := a.walkSelectionEntries(rootDir)
+	if err != nil {
+		return DependencyExpansion{}, err
+	}
+
+	excluded := make(map[string]bool, len(excludedPaths))
+	for _, p := range excludedPaths {
+		excluded[p] = true
+	}
+	reached := make(map[string]bool)
+	var frontier []string
+	for relPath := range files {
+		if !excludedBySelection(excluded, filepath.FromSlash(relPath)) {
+			reached[relPath] = true
+			frontier = append(frontier, relPath)
+		}
+	}
+	sort.Strings(frontier)
+
+	graph := a.buildImportGraph(rootDir, files)
+	result := DependencyExpansion{Added: []ExpandedFile{}}
+	for level := 1; level <= depth && len(frontier) > 0; level++ {
+		var next []string
+		visit := func(from string, targets []string, direction string) {
+			for _, target := range targets {
+				if reached[target] {
+					continue
+				}
+				reached[target] = true
+				next = append(next, target)
+				result.Added = append(result.Added, ExpandedFile{RelPath: target, Via: from, Depth: level, Direction: direction})
+			}
+		}
+		for _, relPath := range frontier {
+			visit(relPath, graph.imports[relPath], "imports")
+			if includeImporters {
+				visit(relPath, graph.importedBy[relPath], "importedBy")
+			}
+		}
+		sort.Strings(next)
+		frontier = next
+	}
+	sort.SliceStable(result.Added, func(i, j int) bool {
+		if result.Added[i].Depth != result.Added[j].Depth {
+			return result.Added[i].Depth < result.Added[j].Depth
+		}
+		return result.Added[i].RelPath < result.Added[j].RelPath
+	})
+
+	for _, added := range result.Added {
+		a.includeInSelection(rootDir, excluded, filepath.FromSlash(added.RelPath))
+	}
+	result.ExcludedPaths = make([]string, 0, len(excluded))
+	for p := range excluded {
+		result.ExcludedPaths = append(result.ExcludedPaths, p)
+	}
+	sort.Strings(result.ExcludedPaths)
+
+	runtime.LogInfof(a.ctx, "Expanded selection of %s by %d files (depth %d)", rootDir, len(result.Added), depth)
+	return result, nil
+}
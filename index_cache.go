@@ -0,0 +1,212 @@
+package main
+
+import (
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- Persistent Index Cache ---
+//
+// When an indexing job finishes, the index (the default tree, and the size, modification time,
+// binary flag and token count of every file) is saved to <config dir>/index_cache/, one
+// gzipped JSON file per project path. When a project is listed for the first time in a
+// session, the saved tree is served at once if it is still valid: the tree settings are
+// unchanged and every listed directory and its .gitignore have the modification time they
+// had when saved (adding, removing or renaming entries changes a directory's time). The file
+// entries come back too; each is checked against the file's size and modification time before
+// use, so the index job only re-reads files that changed. Mounted projects are not cached.
+
+const indexCacheVersion = 1
+
+// cachedIndexFile is a file entry of a saved index
+type cachedIndexFile struct {
+	Size     int64 `json:"size"`
+	ModTime  int64 `json:"modTime"` // Unix nanoseconds
+	IsBinary bool  `json:"isBinary"`
+	Tokens   int   `json:"tokens"`
+}
+
+// indexCacheFile is the saved index of a project
+type indexCacheFile struct {
+	Version   int                        `json:"version"`
+	RootDir   string                     `json:"rootDir"`
+	SavedAt   time.Time                  `json:"savedAt"`
+	TreeStamp string                     `json:"treeStamp"` // Settings the tree was built with
+	Tree      []*FileNode                `json:"tree"`      // Default tree (nil if none was cached)
+	DirStamps map[string]string          `json:"dirStamps"` // Modification stamps of the tree's directories, by absolute path
+	Files     map[string]cachedIndexFile `json:"files"`     // File entries by absolute path
+}
+
+// indexCachePath returns the path of the saved index of a project
+func (a *App) indexCachePath(rootDir string) string {
+	sum := sha256.Sum256([]byte(projectSettingsKey(rootDir)))
+	return filepath.Join(filepath.Dir(a.configPath), "index_cache", hex.EncodeToString(sum[:8])+".json.gz")
+}
+
+// dirStamp returns the modification stamp of a directory: its own time and its .gitignore's
+func dirStamp(dir string) string {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return ""
+	}
+	stamp := fmt.Sprint(info.ModTime().UnixNano())
+	if ignore, err := os.Stat(filepath.Join(dir, ".gitignore")); err == nil {
+		stamp += fmt.Sprintf("/%d", ignore.ModTime().UnixNano())
+	}
+	return stamp
+}
+
+// collectDirStamps records the stamps of the listed directories of a tree
+func collectDirStamps(nodes []*FileNode, stamps map[string]string) {
+	for _, node := range nodes {
+		if node.IsDir && !node.IsTruncated {
+			stamps[node.Path] = dirStamp(node.Path)
+			collectDirStamps(node.Children, stamps)
+		}
+	}
+}
+
+// saveIndexCache writes the index of a project to disk
+func (a *App) saveIndexCache(idx *projectIndex) error {
+	if a.configPath == "" || !a.projectFS.OnDisk(idx.rootDir) {
+		return nil
+	}
+	idx.mu.RLock()
+	cache := indexCacheFile{
+		Version:   indexCacheVersion,
+		RootDir:   idx.rootDir,
+		SavedAt:   time.Now(),
+		TreeStamp: idx.treeStamp,
+		Tree:      idx.tree,
+		DirStamps: make(map[string]string),
+		Files:     make(map[string]cachedIndexFile, len(idx.files)),
+	}
+	for path, entry := range idx.files {
+		cache.Files[path] = cachedIndexFile{Size: entry.size, ModTime: entry.modTime.UnixNano(), IsBinary: entry.isBinary, Tokens: entry.tokens}
+	}
+	if cache.Tree != nil {
+		collectDirStamps(cache.Tree, cache.DirStamps)
+	}
+	data, err := json.Marshal(cache)
+	idx.mu.RUnlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode index cache: %w", err)
+	}
+
+	path := a.indexCachePath(idx.rootDir)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create index cache directory: %w", err)
+	}
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("failed to write index cache: %w", err)
+	}
+	gz := gzip.NewWriter(f)
+	_, err = gz.Write(data)
+	if closeErr := gz.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write index cache: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// loadIndexCache reads the saved index of a project (nil if there is none or it is unusable)
+func (a *App) loadIndexCache(rootDir string) *indexCacheFile {
+	if a.configPath == "" || !a.projectFS.OnDisk(rootDir) {
+		return nil
+	}
+	f, err := os.Open(a.indexCachePath(rootDir))
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil
+	}
+	defer gz.Close()
+	var cache indexCacheFile
+	if err := json.NewDecoder(gz).Decode(&cache); err != nil {
+		runtime.LogWarningf(a.ctx, "Ignoring unreadable index cache of %s: %v", rootDir, err)
+		return nil
+	}
+	if cache.Version != indexCacheVersion || projectSettingsKey(cache.RootDir) != projectSettingsKey(rootDir) {
+		return nil
+	}
+	return &cache
+}
+
+// restoreIndexCache installs the saved index of a project that has no index yet
+//
+// Returns:
+//   - []*FileNode: The saved tree if it is still valid (nil otherwise)
+//   - bool: True if a saved index was installed (its file entries, and its tree if valid)
+func (a *App) restoreIndexCache(rootDir string) ([]*FileNode, bool) {
+	cache := a.loadIndexCache(rootDir)
+	if cache == nil {
+		return nil, false
+	}
+	tree := cache.Tree
+	if tree != nil && cache.TreeStamp != a.treeCacheStamp(rootDir) {
+		tree = nil
+	}
+	for dir, stamp := range cache.DirStamps {
+		if tree == nil {
+			break
+		}
+		if dirStamp(dir) != stamp {
+			tree = nil
+		}
+	}
+
+	idx := &projectIndex{rootDir: rootDir, files: make(map[string]indexedFile, len(cache.Files))}
+	for path, entry := range cache.Files {
+		idx.files[path] = indexedFile{size: entry.Size, modTime: time.Unix(0, entry.ModTime), isBinary: entry.IsBinary, tokens: entry.Tokens}
+	}
+	if tree != nil {
+		idx.tree, idx.treeStamp = tree, cache.TreeStamp
+	}
+
+	a.indexMu.Lock()
+	if a.index != nil && a.index.rootDir == rootDir {
+		a.indexMu.Unlock()
+		return nil, false // Indexed meanwhile
+	}
+	a.index = idx
+	a.indexMu.Unlock()
+	runtime.LogInfof(a.ctx, "Restored index cache of %s from %s (%d files, tree %v)",
+		rootDir, cache.SavedAt.Format(time.RFC3339), len(idx.files), tree != nil)
+	return tree, true
+}
+
+// ============================================================================
+// Index Cache Methods (Wails-bound)
+// ============================================================================
+
+// ClearIndexCache deletes the saved indexes of all projects
+// Open projects keep their in-memory index; it is saved again after its next indexing.
+func (a *App) ClearIndexCache() error {
+	if a.configPath == "" {
+		return nil
+	}
+	if err := os.RemoveAll(filepath.Join(filepath.Dir(a.configPath), "index_cache")); err != nil {
+		return fmt.Errorf("failed to clear index cache: %w", err)
+	}
+	runtime.LogInfo(a.ctx, "Index cache cleared")
+	return nil
+}
@@ -0,0 +1,290 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- Initial Selection Suggestion ---
+//
+// A freshly opened project starts with everything selected, which rarely fits a prompt.
+// SuggestInitialSelection recommends a starting point under a token budget, in order of
+// priority:
+//   - pinned files: README and manifests (see DetectProjectType)
+//   - entrypoints: main.go, index.ts, app.py and the like, at the root and next to manifests
+//   - important files of the detected ecosystems (router, config, lib.rs...)
+//   - files changed recently: uncommitted first, then by date of their last commit
+//
+// Files are added in that order while they fit the budget. Token counts come from the project
+// index once it is ready and are estimated from file sizes before. The selection is returned
+// both as a file list and as exclusions, like RequestShotgunContextGeneration expects.
+
+const (
+	defaultSelectionTokenBudget = 50000               // Tokens suggested when no budget is given
+	recentChangeWindow          = 14 * 24 * time.Hour // Changes considered recent
+)
+
+// entrypointPatterns match entrypoint files, relative to the root or a manifest directory
+var entrypointPatterns = []string{
+	"main.*", "index.*", "app.*", "server.*", "__main__.py",
+	"src/main.*", "src/index.*", "src/app.*", "cmd/*/main.go",
+}
+
+// SuggestedFile is a file of a suggested selection
+type SuggestedFile struct {
+	RelPath string `json:"relPath"` // Path relative to the project root (forward slashes)
+	Reason  string `json:"reason"`  // pinned, entrypoint, ecosystem or recent
+	Tokens  int    `json:"tokens"`  // Estimated tokens
+}
+
+// InitialSelection is the result of SuggestInitialSelection
+type InitialSelection struct {
+	RootDir       string          `json:"rootDir"`       // Project root directory
+	TokenBudget   int             `json:"tokenBudget"`   // Budget the selection was fitted to
+	TotalTokens   int             `json:"totalTokens"`   // Estimated tokens of the suggested files
+	IndexReady    bool            `json:"indexReady"`    // False if token counts were estimated from file sizes
+	Files         []SuggestedFile `json:"files"`         // Suggested files, in order of priority
+	Skipped       int             `json:"skipped"`       // Candidates left out because they did not fit the budget
+	ExcludedPaths []string        `json:"excludedPaths"` // The selection expressed as exclusions, sorted
+}
+
+// selectionEntry is a file or directory found while walking the project
+type selectionEntry struct {
+	relPath string // Relative path (forward slashes)
+	isDir   bool
+	size    int64
+	absPath string
+}
+
+// matchesAnyPattern reports whether relPath, taken relative to dir, matches one of patterns
+func matchesAnyPattern(relPath, dir string, patterns []string) bool {
+	if dir != "." {
+		if !strings.HasPrefix(relPath, dir+"/") {
+			return false
+		}
+		relPath = strings.TrimPrefix(relPath, dir+"/")
+	}
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, relPath); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// recentChanges returns the files of rootDir changed recently, uncommitted ones first
+func (a *App) recentChanges(rootDir string) []string {
+	since := time.Now().Add(-recentChangeWindow)
+	var files []string
+	for _, p := range a.gitChangedFiles(a.ctx, rootDir, "", since) {
+		files = append(files, filepath.ToSlash(p))
+	}
+	for _, event := range a.fileChangesSince(rootDir, since) {
+		files = append(files, event.Path)
+	}
+
+	type committed struct {
+		relPath string
+		at      time.Time
+	}
+	var byDate []committed
+	for relPath, entry := range a.projectChurn(rootDir) {
+		if entry.LastCommit.After(since) {
+			byDate = append(byDate, committed{relPath, entry.LastCommit})
+		}
+	}
+	sort.Slice(byDate, func(i, j int) bool {
+		if !byDate[i].at.Equal(byDate[j].at) {
+			return byDate[i].at.After(byDate[j].at)
+		}
+		return byDate[i].relPath < byDate[j].relPath
+	})
+	for _, c := range byDate {
+		files = append(files, c.relPath)
+	}
+	return files
+}
+
+// walkSelectionEntries lists the entries of a project that ignore rules do not hide
+//
+// Returns:
+//   - []selectionEntry: Files and directories, in walk order
+//   - map[string]selectionEntry: The files, by relative path (forward slashes)
+//   - error: Error if the project cannot be walked
+func (a *App) walkSelectionEntries(rootDir string) ([]selectionEntry, map[string]selectionEntry, error) {
+	opts := a.newTreeBuildOptions(rootDir, a.compileProjectGitignore(rootDir))
+	var entries []selectionEntry
+	files := make(map[string]selectionEntry)
+	err := a.projectFS.WalkDir(rootDir, func(p string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil || p == rootDir {
+			if walkErr != nil && d != nil && d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		relPath, _ := filepath.Rel(rootDir, p)
+		if opts.excludes(relPath, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		entry := selectionEntry{relPath: filepath.ToSlash(relPath), isDir: d.IsDir(), absPath: p}
+		if !d.IsDir() {
+			if info, err := d.Info(); err == nil {
+				entry.size = info.Size()
+			}
+			files[entry.relPath] = entry
+		}
+		entries = append(entries, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to walk project: %w", err)
+	}
+	return entries, files, nil
+}
+
+// selectionExclusions expresses a set of selected files as exclusions: every directory
+// without a selected file, and every other file next to selected ones
+func selectionExclusions(entries []selectionEntry, selected map[string]bool) []string {
+	needed := map[string]bool{".": true}
+	for relPath := range selected {
+		for dir := path.Dir(relPath); dir != "."; dir = path.Dir(dir) {
+			needed[dir] = true
+		}
+	}
+	excluded := []string{}
+	for _, entry := range entries {
+		if !needed[path.Dir(entry.relPath)] {
+			continue // Covered by the exclusion of a parent directory
+		}
+		if (entry.isDir && !needed[entry.relPath]) || (!entry.isDir && !selected[entry.relPath]) {
+			excluded = append(excluded, filepath.FromSlash(entry.relPath))
+		}
+	}
+	sort.Strings(excluded)
+	return excluded
+}
+
+// prioritizedFile is a file worth including first, with the reason why
+type prioritizedFile struct {
+	relPath string // Relative path (forward slashes)
+	reason  string // pinned, entrypoint, ecosystem or recent
+}
+
+// priorityFiles returns the files of a project worth including first, in order of priority
+// (see the top of this file); files must hold the walked files by relative path
+func (a *App) priorityFiles(rootDir string, entries []selectionEntry, files map[string]selectionEntry) []prioritizedFile {
+	var candidates []prioritizedFile
+	seen := make(map[string]bool)
+	add := func(relPath, reason string) {
+		if _, ok := files[relPath]; ok && !seen[relPath] {
+			seen[relPath] = true
+			candidates = append(candidates, prioritizedFile{relPath, reason})
+		}
+	}
+	projectType, _ := a.DetectProjectType(rootDir)
+	for _, pinned := range projectType.PinnedFiles {
+		add(pinned, "pinned")
+	}
+	dirs := []string{"."}
+	for _, eco := range projectType.Ecosystems {
+		if dir := path.Dir(eco.Manifest); dir != "." {
+			dirs = append(dirs, dir)
+		}
+	}
+	for _, dir := range dirs {
+		for _, entry := range entries {
+			if !entry.isDir && matchesAnyPattern(entry.relPath, dir, entrypointPatterns) {
+				add(entry.relPath, "entrypoint")
+			}
+		}
+	}
+	for _, eco := range projectType.Ecosystems {
+		for _, detector := range ecosystemDetectors {
+			if detector.name != eco.Name {
+				continue
+			}
+			for _, entry := range entries {
+				if !entry.isDir && matchesAnyPattern(entry.relPath, path.Dir(eco.Manifest), detector.important) {
+					add(entry.relPath, "ecosystem")
+				}
+			}
+		}
+	}
+	for _, relPath := range a.recentChanges(rootDir) {
+		add(relPath, "recent")
+	}
+	return candidates
+}
+
+// ============================================================================
+// Initial Selection Methods (Wails-bound)
+// ============================================================================
+
+// SuggestInitialSelection recommends the files to select first in a project
+//
+// Parameters:
+//   - rootDir: Project root directory
+//   - tokenBudget: Maximum estimated tokens of the selection (0 for the default)
+//
+// Returns:
+//   - InitialSelection: Suggested files and the matching exclusions
+//   - error: Error if the directory does not exist, is not allowed or cannot be walked
+func (a *App) SuggestInitialSelection(rootDir string, tokenBudget int) (InitialSelection, error) {
+	if !a.projectIsDir(rootDir) {
+		return InitialSelection{}, fmt.Errorf("project folder does not exist: %s", rootDir)
+	}
+	if err := a.validateContentRoot(rootDir); err != nil {
+		return InitialSelection{}, err
+	}
+	if tokenBudget <= 0 {
+		tokenBudget = defaultSelectionTokenBudget
+	}
+
+	entries, files, err := a.walkSelectionEntries(rootDir)
+	if err != nil {
+		return InitialSelection{}, err
+	}
+
+	candidates := a.priorityFiles(rootDir, entries, files)
+
+	result := InitialSelection{RootDir: rootDir, TokenBudget: tokenBudget, Files: []SuggestedFile{}}
+	if idx := a.currentIndex(rootDir); idx != nil {
+		idx.mu.RLock()
+		result.IndexReady = !idx.indexedAt.IsZero()
+		idx.mu.RUnlock()
+	}
+	selected := make(map[string]bool)
+	for _, c := range candidates {
+		entry := files[c.relPath]
+		tokens := int(entry.size / 4)
+		if indexed, ok := a.lookupIndexedFile(entry.absPath); ok {
+			if indexed.isBinary {
+				continue
+			}
+			tokens = indexed.tokens
+		} else if isBinary, err := a.isBinaryFile(entry.absPath); err != nil || isBinary {
+			continue
+		}
+		if result.TotalTokens+tokens > tokenBudget {
+			result.Skipped++
+			continue // A smaller file further down may still fit
+		}
+		result.TotalTokens += tokens
+		selected[c.relPath] = true
+		result.Files = append(result.Files, SuggestedFile{RelPath: c.relPath, Reason: c.reason, Tokens: tokens})
+	}
+	result.ExcludedPaths = selectionExclusions(entries, selected)
+
+	runtime.LogInfof(a.ctx, "Suggested %d files (~%d tokens) for %s", len(result.Files), result.TotalTokens, rootDir)
+	return result, nil
+}
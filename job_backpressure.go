@@ -0,0 +1,194 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- Job Queue Backpressure ---
+//
+// Jobs start as soon as they are added, and finished jobs keep their results until they are
+// cleaned up, so a flood of work holds ever more goroutines and memory. The queue watches two
+// thresholds:
+//   - MaxActiveJobs: queued and running jobs
+//   - MaxResultMB: results held by the jobs in the queue, estimated from their JSON size
+//
+// Crossing either threshold, and going back under both, emits "jobQueueBackpressure" with the
+// current JobQueuePressure. While the queue is under pressure and RejectLowPriority is on,
+// Enqueue and RetryJob refuse low-priority job types with an error wrapping errJobQueueBusy:
+// the project index is rebuilt the next time the project is opened, and a generation includes
+// a file in full when its summary job is refused. Other jobs are always accepted, since the
+// user is waiting for them. Zero thresholds pick the defaults.
+
+const (
+	defaultMaxActiveJobs = 64
+	defaultMaxResultMB   = 256
+)
+
+// errJobQueueBusy is wrapped by the error of every job refused under backpressure
+var errJobQueueBusy = errors.New("job queue is under pressure")
+
+// lowPriorityJobTypes are the job types refused under backpressure
+var lowPriorityJobTypes = map[string]bool{
+	"project_index": true, // Background index of an opened project
+	"file_summary":  true, // Generations fall back to the full file
+}
+
+// JobQueueLimits sets when the job queue is under pressure
+type JobQueueLimits struct {
+	MaxActiveJobs     int  `json:"maxActiveJobs"`     // Queued and running jobs allowed before pressure (0 = 64)
+	MaxResultMB       int  `json:"maxResultMb"`       // Megabytes of held job results allowed before pressure (0 = 256)
+	RejectLowPriority bool `json:"rejectLowPriority"` // Refuse new low-priority jobs while under pressure
+}
+
+// effective returns the thresholds to use, applying the defaults
+func (l JobQueueLimits) effective() (maxActive int, maxResultBytes int64) {
+	maxActive, maxResultMB := l.MaxActiveJobs, l.MaxResultMB
+	if maxActive <= 0 {
+		maxActive = defaultMaxActiveJobs
+	}
+	if maxResultMB <= 0 {
+		maxResultMB = defaultMaxResultMB
+	}
+	return maxActive, int64(maxResultMB) << 20
+}
+
+// JobQueuePressure describes the load of the job queue
+type JobQueuePressure struct {
+	ActiveJobs     int      `json:"activeJobs"`     // Queued and running jobs
+	MaxActiveJobs  int      `json:"maxActiveJobs"`  // Threshold on active jobs
+	ResultBytes    int64    `json:"resultBytes"`    // Estimated size of the results held by the queue
+	MaxResultBytes int64    `json:"maxResultBytes"` // Threshold on held results
+	UnderPressure  bool     `json:"underPressure"`  // True while a threshold is exceeded
+	Reasons        []string `json:"reasons"`        // Thresholds exceeded (empty if none)
+	RejectedJobs   int      `json:"rejectedJobs"`   // Low-priority jobs refused since the app started
+}
+
+// resultSize estimates the memory held by a job result from its JSON size
+func resultSize(result interface{}) int64 {
+	switch r := result.(type) {
+	case nil:
+		return 0
+	case string:
+		return int64(len(r))
+	case []byte:
+		return int64(len(r))
+	}
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		return 0
+	}
+	return int64(len(encoded))
+}
+
+// pressureUnsafe measures the load of the queue (the mutex must be held)
+func (jq *JobQueue) pressureUnsafe() JobQueuePressure {
+	maxActive, maxResultBytes := jq.app.settings.JobQueue.effective()
+	p := JobQueuePressure{
+		MaxActiveJobs:  maxActive,
+		MaxResultBytes: maxResultBytes,
+		Reasons:        []string{},
+		RejectedJobs:   jq.rejectedJobs,
+	}
+	for _, job := range jq.jobs {
+		if job.Status == "queued" || job.Status == "running" {
+			p.ActiveJobs++
+		}
+		p.ResultBytes += job.ResultBytes
+	}
+	if p.ActiveJobs > maxActive {
+		p.Reasons = append(p.Reasons, fmt.Sprintf("%d active jobs, limit %d", p.ActiveJobs, maxActive))
+	}
+	if p.ResultBytes > maxResultBytes {
+		p.Reasons = append(p.Reasons, fmt.Sprintf("%.1f MB of job results, limit %d MB",
+			float64(p.ResultBytes)/(1<<20), maxResultBytes>>20))
+	}
+	p.UnderPressure = len(p.Reasons) > 0
+	return p
+}
+
+// checkPressureUnsafe emits "jobQueueBackpressure" when the queue goes under or out of
+// pressure (the mutex must be held)
+func (jq *JobQueue) checkPressureUnsafe() {
+	p := jq.pressureUnsafe()
+	if p.UnderPressure == jq.underPressure {
+		return
+	}
+	jq.underPressure = p.UnderPressure
+	if p.UnderPressure {
+		runtime.LogWarningf(jq.app.ctx, "Job queue under pressure: %s", strings.Join(p.Reasons, "; "))
+	} else {
+		runtime.LogInfof(jq.app.ctx, "Job queue pressure relieved (%d active jobs, %.1f MB of results)",
+			p.ActiveJobs, float64(p.ResultBytes)/(1<<20))
+	}
+	runtime.EventsEmit(jq.app.ctx, "jobQueueBackpressure", p)
+}
+
+// admitUnsafe refuses a low-priority job while the queue is under pressure (the mutex must
+// be held)
+//
+// Returns:
+//   - error: Error wrapping errJobQueueBusy if the job is refused
+func (jq *JobQueue) admitUnsafe(jobType string) error {
+	if !lowPriorityJobTypes[jobType] || !jq.app.settings.JobQueue.RejectLowPriority {
+		return nil
+	}
+	p := jq.pressureUnsafe()
+	if !p.UnderPressure {
+		return nil
+	}
+	jq.rejectedJobs++
+	runtime.LogWarningf(jq.app.ctx, "Refused low-priority %s job: %s", jobType, strings.Join(p.Reasons, "; "))
+	return fmt.Errorf("%w (%s): low-priority %s job refused, try again once jobs finish or are cleared",
+		errJobQueueBusy, strings.Join(p.Reasons, "; "), jobType)
+}
+
+// ============================================================================
+// Job Queue Backpressure Methods (Wails-bound)
+// ============================================================================
+
+// GetJobQueuePressure returns the current load of the job queue
+func (a *App) GetJobQueuePressure() (JobQueuePressure, error) {
+	if a.jobQueue == nil {
+		return JobQueuePressure{}, fmt.Errorf("job queue not initialized")
+	}
+	a.jobQueue.mu.Lock()
+	defer a.jobQueue.mu.Unlock()
+	return a.jobQueue.pressureUnsafe(), nil
+}
+
+// GetJobQueueLimits returns the thresholds of job queue backpressure
+func (a *App) GetJobQueueLimits() JobQueueLimits {
+	return a.settings.JobQueue
+}
+
+// SetJobQueueLimits updates and saves the thresholds of job queue backpressure
+// The queue is checked against the new thresholds right away.
+//
+// Parameters:
+//   - limits: Active job and result size thresholds (0 = default) and low-priority rejection
+//
+// Returns:
+//   - error: Error if a threshold is negative or the settings cannot be saved
+func (a *App) SetJobQueueLimits(limits JobQueueLimits) error {
+	if limits.MaxActiveJobs < 0 || limits.MaxResultMB < 0 {
+		return fmt.Errorf("job queue limits cannot be negative")
+	}
+	a.settings.JobQueue = limits
+	if err := a.saveSettings(); err != nil {
+		return fmt.Errorf("failed to save job queue limits: %w", err)
+	}
+	maxActive, maxResultBytes := limits.effective()
+	runtime.LogInfof(a.ctx, "Job queue limits set to %d active jobs, %d MB of results, reject low priority %v",
+		maxActive, maxResultBytes>>20, limits.RejectLowPriority)
+	if a.jobQueue != nil {
+		a.jobQueue.mu.Lock()
+		a.jobQueue.checkPressureUnsafe()
+		a.jobQueue.mu.Unlock()
+	}
+	return nil
+}
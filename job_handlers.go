@@ -0,0 +1,441 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+/**
+ * Built-in Job Handlers for Shotgun Code
+ *
+ * Every job type the app runs in the background is registered here as a JobHandler
+ * (name → executor + parameter/result schema). Wails bindings, and any other surface
+ * (CLI, HTTP, MCP), enqueue jobs through JobQueue.Enqueue with JSON parameters
+ * instead of building closures ad hoc.
+ *
+ * Registered Types:
+ * - llm_call: Single LLM request (params: LLMRequest, result: LLMResponse)
+ * - llm_continuation: Continue a truncated LLM response (params: {jobId}, result: LLMResponse)
+ * - tool_agent: Multi-step tool-calling conversation (params: toolAgentParams, result: LLMResponse)
+ * - project_index: Background index of an opened project (params: {rootDir}, result: ProjectIndexStatus)
+ * - prompt_batch: Many independent prompts as child jobs (params: PromptBatchOptions, result: PromptBatchResult)
+ * - llm_batch_item: One prompt of a batch (params: batchItemParams, result: LLMResponse)
+ * - code_review: Per-file or per-hunk review (params: ReviewOptions, result: ReviewReport)
+ * - directory_summary: LLM description of a summary-only directory (params: DirectorySummaryOptions, result: DirectorySummary)
+ * - file_summary: LLM summary replacing a large file in generated context (params: FileSummaryOptions, result: FileSummary)
+ * - read_file_contents: File contents emitted in batches (params: FileContentStreamOptions, result: FileContentStreamResult)
+ */
+
+// toolAgentParams are the parameters of a tool_agent job
+type toolAgentParams struct {
+	Provider string `json:"provider"` // LLM provider (google, openai, anthropic, custom)
+	APIKey   string `json:"apiKey"`   // API key for the provider
+	Model    string `json:"model"`    // Model name (empty for provider default)
+	BaseURL  string `json:"baseURL"`  // Base URL for the custom provider
+	Prompt   string `json:"prompt"`   // The task for the agent
+	RootDir  string `json:"rootDir"`  // Project root the tools are sandboxed to
+	MaxSteps int    `json:"maxSteps"` // Maximum number of model turns (defaults to 10)
+}
+
+// continuationParams are the parameters of an llm_continuation job
+type continuationParams struct {
+	JobID string `json:"jobId"` // ID of the job whose response was truncated
+}
+
+// llmResponseSchema describes the LLMResponse result of LLM job types
+var llmResponseSchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"content":       map[string]interface{}{"type": "string"},
+		"tokensUsed":    map[string]interface{}{"type": "integer"},
+		"cost":          map[string]interface{}{"type": "number"},
+		"model":         map[string]interface{}{"type": "string"},
+		"provider":      map[string]interface{}{"type": "string"},
+		"finishReason":  map[string]interface{}{"type": "string"},
+		"truncated":     map[string]interface{}{"type": "boolean"},
+		"continuations": map[string]interface{}{"type": "integer"},
+	},
+}
+
+// registerBuiltinJobHandlers registers the job types implemented by the app
+func (a *App) registerBuiltinJobHandlers() {
+	handlers := []JobHandler{
+		{
+			Type:        "llm_call",
+			Description: "Send a prompt to an LLM provider and emit the response",
+			ParamsSchema: map[string]interface{}{
+				"type":     "object",
+				"required": []string{"provider", "prompt"},
+				"properties": map[string]interface{}{
+					"provider":    map[string]interface{}{"type": "string", "enum": []string{"google", "openai", "anthropic", "custom"}},
+					"apiKey":      map[string]interface{}{"type": "string"},
+					"prompt":      map[string]interface{}{"type": "string"},
+					"model":       map[string]interface{}{"type": "string"},
+					"temperature": map[string]interface{}{"type": "number"},
+					"maxTokens":   map[string]interface{}{"type": "integer"},
+					"baseURL":     map[string]interface{}{"type": "string"},
+					"mode":        map[string]interface{}{"type": "string"},
+					"task":        map[string]interface{}{"type": "string"},
+					"rootDir":     map[string]interface{}{"type": "string"},
+				},
+			},
+			ResultSchema: llmResponseSchema,
+			Execute:      a.executeLLMCallJob,
+		},
+		{
+			Type:        "llm_continuation",
+			Description: "Continue a truncated LLM response and emit the stitched result",
+			ParamsSchema: map[string]interface{}{
+				"type":       "object",
+				"required":   []string{"jobId"},
+				"properties": map[string]interface{}{"jobId": map[string]interface{}{"type": "string"}},
+			},
+			ResultSchema: llmResponseSchema,
+			Execute:      a.executeContinuationJob,
+		},
+		{
+			Type:        "tool_agent",
+			Description: "Run a multi-step LLM conversation with tool calling in a project",
+			ParamsSchema: map[string]interface{}{
+				"type":     "object",
+				"required": []string{"provider", "prompt", "rootDir"},
+				"properties": map[string]interface{}{
+					"provider": map[string]interface{}{"type": "string"},
+					"apiKey":   map[string]interface{}{"type": "string"},
+					"model":    map[string]interface{}{"type": "string"},
+					"baseURL":  map[string]interface{}{"type": "string"},
+					"prompt":   map[string]interface{}{"type": "string"},
+					"rootDir":  map[string]interface{}{"type": "string"},
+					"maxSteps": map[string]interface{}{"type": "integer"},
+				},
+			},
+			ResultSchema: llmResponseSchema,
+			Execute:      a.executeToolAgentJob,
+		},
+		{
+			Type:        "project_index",
+			Description: "Index an opened project (tree, token counts, binary flags, git status) in the background",
+			ParamsSchema: map[string]interface{}{
+				"type":       "object",
+				"required":   []string{"rootDir"},
+				"properties": map[string]interface{}{"rootDir": map[string]interface{}{"type": "string"}},
+			},
+			ResultSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"rootDir":     map[string]interface{}{"type": "string"},
+					"ready":       map[string]interface{}{"type": "boolean"},
+					"fileCount":   map[string]interface{}{"type": "integer"},
+					"totalTokens": map[string]interface{}{"type": "integer"},
+					"treeCached":  map[string]interface{}{"type": "boolean"},
+					"gitStatus":   map[string]interface{}{"type": "object"},
+				},
+			},
+			Execute: a.executeProjectIndexJob,
+		},
+		{
+			Type:        "prompt_batch",
+			Description: "Run independent prompts as child jobs with shared rate limiting and combined cost",
+			ParamsSchema: map[string]interface{}{
+				"type":     "object",
+				"required": []string{"provider", "prompts"},
+				"properties": map[string]interface{}{
+					"provider":          map[string]interface{}{"type": "string", "enum": []string{"google", "openai", "anthropic", "custom"}},
+					"apiKey":            map[string]interface{}{"type": "string"},
+					"model":             map[string]interface{}{"type": "string"},
+					"baseURL":           map[string]interface{}{"type": "string"},
+					"prompts":           map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+					"concurrency":       map[string]interface{}{"type": "integer"},
+					"requestsPerMinute": map[string]interface{}{"type": "integer"},
+				},
+			},
+			ResultSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"total":      map[string]interface{}{"type": "integer"},
+					"completed":  map[string]interface{}{"type": "integer"},
+					"failed":     map[string]interface{}{"type": "integer"},
+					"tokensUsed": map[string]interface{}{"type": "integer"},
+					"cost":       map[string]interface{}{"type": "number"},
+					"items":      map[string]interface{}{"type": "array"},
+				},
+			},
+			Execute: a.executePromptBatchJob,
+		},
+		{
+			Type:        "llm_batch_item",
+			Description: "Send one prompt of a prompt_batch job (enqueued by the batch)",
+			ParamsSchema: map[string]interface{}{
+				"type":     "object",
+				"required": []string{"batchId", "request"},
+				"properties": map[string]interface{}{
+					"batchId": map[string]interface{}{"type": "string"},
+					"index":   map[string]interface{}{"type": "integer"},
+					"request": map[string]interface{}{"type": "object"},
+				},
+			},
+			ResultSchema: llmResponseSchema,
+			Execute:      a.executeBatchItemJob,
+		},
+		{
+			Type:        "code_review",
+			Description: "Review files or diff hunks one by one and collect structured comments",
+			ParamsSchema: map[string]interface{}{
+				"type":     "object",
+				"required": []string{"provider"},
+				"properties": map[string]interface{}{
+					"provider":          map[string]interface{}{"type": "string", "enum": []string{"google", "openai", "anthropic", "custom"}},
+					"apiKey":            map[string]interface{}{"type": "string"},
+					"model":             map[string]interface{}{"type": "string"},
+					"baseURL":           map[string]interface{}{"type": "string"},
+					"rootDir":           map[string]interface{}{"type": "string"},
+					"files":             map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+					"diff":              map[string]interface{}{"type": "string"},
+					"instructions":      map[string]interface{}{"type": "string"},
+					"concurrency":       map[string]interface{}{"type": "integer"},
+					"requestsPerMinute": map[string]interface{}{"type": "integer"},
+				},
+			},
+			ResultSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"units":    map[string]interface{}{"type": "integer"},
+					"reviewed": map[string]interface{}{"type": "integer"},
+					"comments": map[string]interface{}{"type": "array"},
+					"counts":   map[string]interface{}{"type": "object"},
+					"failures": map[string]interface{}{"type": "array"},
+				},
+			},
+			Execute: a.executeCodeReviewJob,
+		},
+		{
+			Type:        "directory_summary",
+			Description: "Describe a summary-only directory with an LLM and save the description",
+			ParamsSchema: map[string]interface{}{
+				"type":     "object",
+				"required": []string{"provider", "rootDir", "path"},
+				"properties": map[string]interface{}{
+					"provider": map[string]interface{}{"type": "string", "enum": []string{"google", "openai", "anthropic", "custom"}},
+					"apiKey":   map[string]interface{}{"type": "string"},
+					"model":    map[string]interface{}{"type": "string"},
+					"baseURL":  map[string]interface{}{"type": "string"},
+					"rootDir":  map[string]interface{}{"type": "string"},
+					"path":     map[string]interface{}{"type": "string"},
+				},
+			},
+			ResultSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"path":            map[string]interface{}{"type": "string"},
+					"files":           map[string]interface{}{"type": "integer"},
+					"estimatedTokens": map[string]interface{}{"type": "integer"},
+					"languages":       map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+					"keyFiles":        map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+					"llmSummary":      map[string]interface{}{"type": "string"},
+				},
+			},
+			Execute: a.executeDirectorySummaryJob,
+		},
+		{
+			Type:        "file_summary",
+			Description: "Summarize a large file with an LLM and cache the summary for generated context",
+			ParamsSchema: map[string]interface{}{
+				"type":     "object",
+				"required": []string{"provider", "rootDir", "path"},
+				"properties": map[string]interface{}{
+					"provider": map[string]interface{}{"type": "string", "enum": []string{"google", "openai", "anthropic", "custom"}},
+					"apiKey":   map[string]interface{}{"type": "string"},
+					"model":    map[string]interface{}{"type": "string"},
+					"baseURL":  map[string]interface{}{"type": "string"},
+					"rootDir":  map[string]interface{}{"type": "string"},
+					"path":     map[string]interface{}{"type": "string"},
+				},
+			},
+			ResultSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"path":           map[string]interface{}{"type": "string"},
+					"summary":        map[string]interface{}{"type": "string"},
+					"originalTokens": map[string]interface{}{"type": "integer"},
+					"summaryTokens":  map[string]interface{}{"type": "integer"},
+					"cached":         map[string]interface{}{"type": "boolean"},
+				},
+			},
+			Execute: a.executeFileSummaryJob,
+		},
+		{
+			Type:        "read_file_contents",
+			Description: "Read files of a project and emit their contents in batches",
+			ParamsSchema: map[string]interface{}{
+				"type":     "object",
+				"required": []string{"rootDir", "paths"},
+				"properties": map[string]interface{}{
+					"rootDir":       map[string]interface{}{"type": "string"},
+					"paths":         map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+					"batchSize":     map[string]interface{}{"type": "integer"},
+					"maxBatchBytes": map[string]interface{}{"type": "integer"},
+				},
+			},
+			ResultSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"files":   map[string]interface{}{"type": "integer"},
+					"bytes":   map[string]interface{}{"type": "integer"},
+					"batches": map[string]interface{}{"type": "integer"},
+					"errors":  map[string]interface{}{"type": "integer"},
+				},
+			},
+			Execute: a.executeReadFileContentsJob,
+		},
+	}
+
+	for _, handler := range handlers {
+		if err := a.jobQueue.RegisterHandler(handler); err != nil {
+			runtime.LogErrorf(a.ctx, "Failed to register job handler %s: %v", handler.Type, err)
+		}
+	}
+}
+
+// executeLLMCallJob implements the llm_call job type
+func (a *App) executeLLMCallJob(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var req LLMRequest
+	if err := json.Unmarshal(params, &req); err != nil {
+		return nil, fmt.Errorf("invalid llm_call parameters: %w", err)
+	}
+
+	// Continuations are controlled by the user setting
+	req.MaxContinuations = a.settings.MaxAutoContinuations
+	a.applyModeDefaults(&req)
+
+	// Track the call for crash recovery until it returns
+	jobID := jobIDFromContext(ctx)
+	req.RootDir = a.tagJobProject(ctx, req.RootDir)
+	a.trackLLMCall(jobID, req)
+	defer a.untrackLLMCall(jobID)
+	a.startWorkflow(jobID, req)
+
+	resp, err := NewLLMClient(a).CallLLM(ctx, req)
+	if err != nil {
+		a.failWorkflow(jobID, err)
+		return nil, err
+	}
+
+	// Emit response to frontend
+	a.emitLLMResponse(jobID, req, resp, resp)
+	return resp, nil
+}
+
+// executeContinuationJob implements the llm_continuation job type
+func (a *App) executeContinuationJob(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var p continuationParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, fmt.Errorf("invalid llm_continuation parameters: %w", err)
+	}
+
+	a.truncatedMu.Lock()
+	call, ok := a.truncatedCalls[p.JobID]
+	if ok {
+		delete(a.truncatedCalls, p.JobID)
+	}
+	a.truncatedMu.Unlock()
+
+	if !ok {
+		return nil, fmt.Errorf("no truncated response found for job: %s", p.JobID)
+	}
+
+	req := call.request
+	a.tagJobProject(ctx, req.RootDir)
+	req.AssistantPrefix = stitchContinuation(call.request.AssistantPrefix, call.response.Content)
+
+	next, err := NewLLMClient(a).CallLLM(ctx, req)
+	if err != nil {
+		a.failWorkflow(p.JobID, err)
+		return nil, err
+	}
+
+	// Stitch the continuation onto the previous response
+	resp := *call.response
+	resp.Content = stitchContinuation(call.response.Content, next.Content)
+	resp.addUsage(next)
+	resp.FinishReason = next.FinishReason
+	resp.Truncated = next.Truncated
+	resp.Continuations += next.Continuations + 1
+
+	// The previous part was recorded by its own call: only the continuation is new usage
+	a.emitLLMResponse(jobIDFromContext(ctx), call.request, &resp, next)
+	a.recordWorkflowResponse(p.JobID, &resp)
+	return &resp, nil
+}
+
+// executeToolAgentJob implements the tool_agent job type
+func (a *App) executeToolAgentJob(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var p toolAgentParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, fmt.Errorf("invalid tool_agent parameters: %w", err)
+	}
+	if a.toolRegistry == nil {
+		return nil, fmt.Errorf("tool registry not initialized")
+	}
+	if err := a.validateContentRoot(p.RootDir); err != nil {
+		return nil, err
+	}
+	if p.MaxSteps <= 0 {
+		p.MaxSteps = 10
+	}
+
+	req := LLMRequest{
+		Provider: p.Provider,
+		APIKey:   p.APIKey,
+		Prompt:   p.Prompt,
+		Model:    p.Model,
+		BaseURL:  p.BaseURL,
+	}
+
+	a.tagJobProject(ctx, p.RootDir)
+	resp, err := NewLLMClient(a).CallLLMWithTools(ctx, req, a.toolRegistry, p.RootDir, p.MaxSteps)
+	if err != nil {
+		return nil, err
+	}
+	a.recordUsage(llmCallEvent(p.RootDir, resp))
+
+	runtime.EventsEmit(a.ctx, "llmResponseReceived", resp)
+	return resp, nil
+}
+
+// ============================================================================
+// Job Handler Methods (Wails-bound)
+// ============================================================================
+
+// ListJobTypes returns the registered job types with their parameter and result schemas
+func (a *App) ListJobTypes() []JobHandler {
+	if a.jobQueue == nil {
+		return []JobHandler{}
+	}
+	return a.jobQueue.Handlers()
+}
+
+// EnqueueJob adds a job of a registered type to the queue
+//
+// Parameters:
+//   - jobType: Registered job type (see ListJobTypes)
+//   - paramsJSON: Job parameters as a JSON object
+//
+// Returns:
+//   - string: Job ID for tracking
+//   - error: Error if the job type is unknown or the parameters are not valid JSON
+func (a *App) EnqueueJob(jobType, paramsJSON string) (string, error) {
+	if a.jobQueue == nil {
+		return "", fmt.Errorf("job queue not initialized")
+	}
+	if strings.TrimSpace(paramsJSON) == "" {
+		paramsJSON = "{}"
+	}
+	if !json.Valid([]byte(paramsJSON)) {
+		return "", fmt.Errorf("job parameters are not valid JSON")
+	}
+	return a.jobQueue.Enqueue(jobType, json.RawMessage(paramsJSON))
+}
@@ -2,7 +2,9 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
@@ -19,15 +21,23 @@ import (
  * Key Features:
  * - Non-blocking job execution in goroutines
  * - Real-time progress tracking via Wails events
- * - Job cancellation support
+ * - Job cancellation support (single job or all active jobs)
+ * - Retry of failed or cancelled jobs
+ * - Registered job-type handlers, so every surface (UI, CLI, HTTP, MCP) enqueues jobs the same way
  * - Concurrent job execution with configurable limits
  * - Job history and status tracking
  * - Automatic cleanup of completed jobs
+ * - Backpressure events and refusal of low-priority jobs under load (see job_backpressure.go)
  *
  * Job Types:
  * - context_generation: Generate shotgun context from selected files
  * - diff_splitting: Split large diffs into manageable chunks
  * - llm_call: Call LLM API for code generation
+ * - llm_continuation: Continue a truncated LLM response
+ * - tool_agent: Multi-step LLM conversation with tool calling
+ * - project_index: Low-priority background index of an opened project
+ * - prompt_batch: Run many prompts as llm_batch_item child jobs with shared rate limiting
+ * - code_review: Review files or diff hunks one by one through a prompt_batch child job
  *
  * Job States:
  * - queued: Job is waiting to start
@@ -35,6 +45,11 @@ import (
  * - completed: Job finished successfully
  * - failed: Job encountered an error
  * - cancelled: Job was cancelled by user
+ * - zombie: Job was abandoned with ForceKillJob because its task ignored cancellation
+ *
+ * A cancelled job whose task has not returned yet (Exited is false) did not honor its
+ * context; ForceKillJob marks it zombie so waiting jobs and slots move on. The task's
+ * goroutine cannot be stopped from outside: if it ever returns, its outcome is discarded.
  */
 
 // Job represents a background task with status tracking
@@ -48,14 +63,53 @@ type Job struct {
 	StartedAt   time.Time          `json:"startedAt"`   // When the job started running
 	CompletedAt time.Time          `json:"completedAt"` // When the job completed
 	CancelFunc  context.CancelFunc `json:"-"`           // Function to cancel the job (not serialized)
+	RetryOf     string             `json:"retryOf"`     // ID of the job this one retries (empty if not a retry)
+	ParentID    string             `json:"parentId"`    // ID of the job that enqueued this one (empty if top-level)
+	Project     string             `json:"project"`     // Project an LLM job works on, for cost attribution (empty if none)
+	Result      interface{}        `json:"result"`      // Result returned by the job's handler (nil for ad hoc tasks)
+	ResultBytes int64              `json:"resultBytes"` // Estimated memory held by Result
+
+	ElapsedMs         int64     `json:"elapsedMs"`         // Running time so far, or until the task returned
+	CancelRequestedAt time.Time `json:"cancelRequestedAt"` // When cancellation was requested (zero if never)
+	Exited            bool      `json:"exited"`            // True once the task function returned
+
+	task func(ctx context.Context) error // Task function, kept so the job can be retried
+}
+
+// jobIDContextKey is the context key under which AddJob stores the job ID
+type jobIDContextKey struct{}
+
+// jobIDFromContext returns the ID of the job a task is running as (empty if none)
+// Tasks use this to tag events and results with their own job ID
+func jobIDFromContext(ctx context.Context) string {
+	if id, ok := ctx.Value(jobIDContextKey{}).(string); ok {
+		return id
+	}
+	return ""
+}
+
+// JobHandler executes one job type
+// Handlers receive their parameters as JSON so any surface can enqueue them uniformly
+type JobHandler struct {
+	Type         string                 `json:"type"`         // Job type name (e.g., llm_call)
+	Description  string                 `json:"description"`  // Human-readable description
+	ParamsSchema map[string]interface{} `json:"paramsSchema"` // JSON Schema of the parameters
+	ResultSchema map[string]interface{} `json:"resultSchema"` // JSON Schema of the result
+
+	// Execute runs the job and returns its result
+	Execute func(ctx context.Context, params json.RawMessage) (interface{}, error) `json:"-"`
 }
 
 // JobQueue manages background jobs with concurrent execution
 type JobQueue struct {
-	app     *App       // Reference to main app for Wails events
-	jobs    []Job      // List of all jobs (active and historical)
-	mu      sync.Mutex // Mutex for thread-safe access to jobs
-	maxJobs int        // Maximum number of concurrent jobs
+	app      *App                   // Reference to main app for Wails events
+	jobs     []Job                  // List of all jobs (active and historical)
+	handlers map[string]*JobHandler // Registered job-type handlers by type
+	mu       sync.Mutex             // Mutex for thread-safe access to jobs and handlers
+	maxJobs  int                    // Maximum number of concurrent jobs
+
+	underPressure bool // True while a backpressure threshold is exceeded
+	rejectedJobs  int  // Low-priority jobs refused under backpressure
 }
 
 // NewJobQueue creates a new job queue instance
@@ -67,12 +121,96 @@ type JobQueue struct {
 //   - *JobQueue: Initialized job queue with default settings
 func NewJobQueue(app *App) *JobQueue {
 	return &JobQueue{
-		app:     app,
-		jobs:    make([]Job, 0),
-		maxJobs: 5, // Allow up to 5 concurrent jobs
+		app:      app,
+		jobs:     make([]Job, 0),
+		handlers: make(map[string]*JobHandler),
+		maxJobs:  5, // Allow up to 5 concurrent jobs
 	}
 }
 
+// RegisterHandler registers the handler for a job type
+//
+// Parameters:
+//   - handler: Handler with a unique type name and an Execute function
+//
+// Returns:
+//   - error: Error if the handler is invalid or the type is already registered
+func (jq *JobQueue) RegisterHandler(handler JobHandler) error {
+	if handler.Type == "" || handler.Execute == nil {
+		return fmt.Errorf("job handler must have a type and an execute function")
+	}
+
+	jq.mu.Lock()
+	defer jq.mu.Unlock()
+
+	if _, exists := jq.handlers[handler.Type]; exists {
+		return fmt.Errorf("job type already registered: %s", handler.Type)
+	}
+	jq.handlers[handler.Type] = &handler
+	return nil
+}
+
+// Handlers returns the registered job handlers sorted by type
+func (jq *JobQueue) Handlers() []JobHandler {
+	jq.mu.Lock()
+	defer jq.mu.Unlock()
+
+	handlers := make([]JobHandler, 0, len(jq.handlers))
+	for _, handler := range jq.handlers {
+		handlers = append(handlers, *handler)
+	}
+	sort.Slice(handlers, func(i, j int) bool { return handlers[i].Type < handlers[j].Type })
+	return handlers
+}
+
+// Enqueue adds a job of a registered type to the queue
+//
+// The parameters are marshalled to JSON and passed to the handler, and the
+// handler's result is stored on the job when it completes.
+//
+// Parameters:
+//   - jobType: Registered job type
+//   - params: Job parameters (any JSON-marshallable value, or json.RawMessage)
+//
+// Returns:
+//   - string: Unique job ID for tracking
+//   - error: Error if the job type is unknown, the parameters cannot be encoded, or the job is
+//     low-priority and refused under backpressure
+func (jq *JobQueue) Enqueue(jobType string, params interface{}) (string, error) {
+	jq.mu.Lock()
+	handler, ok := jq.handlers[jobType]
+	var admitErr error
+	if ok {
+		admitErr = jq.admitUnsafe(jobType)
+	}
+	jq.mu.Unlock()
+	if !ok {
+		return "", fmt.Errorf("unknown job type: %s", jobType)
+	}
+	if admitErr != nil {
+		return "", admitErr
+	}
+
+	raw, ok := params.(json.RawMessage)
+	if !ok {
+		encoded, err := json.Marshal(params)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode parameters for %s: %w", jobType, err)
+		}
+		raw = encoded
+	}
+
+	jobID := jq.AddJob(jobType, func(ctx context.Context) error {
+		result, err := handler.Execute(ctx, raw)
+		if err != nil {
+			return err
+		}
+		jq.setJobResult(jobIDFromContext(ctx), result)
+		return nil
+	})
+	return jobID, nil
+}
+
 // AddJob adds a new job to the queue and starts it immediately
 //
 // This method creates a new job, adds it to the queue, and starts executing it
@@ -98,8 +236,8 @@ func (jq *JobQueue) AddJob(jobType string, task func(ctx context.Context) error)
 	// Generate unique job ID using type and timestamp
 	jobID := fmt.Sprintf("%s_%d", jobType, time.Now().UnixNano())
 
-	// Create cancellable context for this job
-	ctx, cancel := context.WithCancel(jq.app.ctx)
+	// Create cancellable context for this job, carrying the job ID for the task
+	ctx, cancel := context.WithCancel(context.WithValue(jq.app.ctx, jobIDContextKey{}, jobID))
 
 	// Create new job with initial state
 	job := Job{
@@ -109,6 +247,7 @@ func (jq *JobQueue) AddJob(jobType string, task func(ctx context.Context) error)
 		Progress:   0,
 		CreatedAt:  time.Now(),
 		CancelFunc: cancel,
+		task:       task,
 	}
 
 	// Add job to queue
@@ -116,6 +255,7 @@ func (jq *JobQueue) AddJob(jobType string, task func(ctx context.Context) error)
 
 	// Emit initial job queue update to frontend
 	runtime.EventsEmit(jq.app.ctx, "jobQueueUpdated", jq.getJobStatusesUnsafe())
+	jq.checkPressureUnsafe()
 
 	jq.mu.Unlock()
 
@@ -128,6 +268,12 @@ func (jq *JobQueue) AddJob(jobType string, task func(ctx context.Context) error)
 		// Execute the task with cancellable context
 		err := task(ctx)
 
+		// An abandoned task's outcome is discarded
+		if jq.markJobExited(jobID) {
+			runtime.LogWarningf(jq.app.ctx, "Zombie job %s exited after being abandoned (error: %v)", jobID, err)
+			return
+		}
+
 		// Update job status based on result
 		if ctx.Err() == context.Canceled {
 			// Job was cancelled by user
@@ -151,6 +297,7 @@ func (jq *JobQueue) AddJob(jobType string, task func(ctx context.Context) error)
 		// Emit final job queue update
 		jq.mu.Lock()
 		runtime.EventsEmit(jq.app.ctx, "jobQueueUpdated", jq.getJobStatusesUnsafe())
+		jq.checkPressureUnsafe()
 		jq.mu.Unlock()
 	}()
 
@@ -185,9 +332,11 @@ func (jq *JobQueue) CancelJob(jobID string) error {
 				// Update status to cancelled
 				jq.jobs[i].Status = "cancelled"
 				jq.jobs[i].CompletedAt = time.Now()
+				jq.jobs[i].CancelRequestedAt = jq.jobs[i].CompletedAt
 
 				// Emit update to frontend
 				runtime.EventsEmit(jq.app.ctx, "jobQueueUpdated", jq.getJobStatusesUnsafe())
+				jq.checkPressureUnsafe()
 
 				runtime.LogInfo(jq.app.ctx, fmt.Sprintf("Cancelled job: %s", jobID))
 				return nil
@@ -225,9 +374,88 @@ func (jq *JobQueue) getJobStatusesUnsafe() []Job {
 	// Create a copy to avoid race conditions
 	statuses := make([]Job, len(jq.jobs))
 	copy(statuses, jq.jobs)
+	now := time.Now()
+	for i := range statuses {
+		statuses[i].ElapsedMs = jobElapsed(statuses[i], now).Milliseconds()
+	}
 	return statuses
 }
 
+// jobElapsed returns how long a job's task has been running, or ran until it returned
+// A task that has not returned yet counts until now, even if the job was cancelled.
+func jobElapsed(job Job, now time.Time) time.Duration {
+	if job.StartedAt.IsZero() {
+		return 0
+	}
+	end := job.CompletedAt
+	if !job.Exited || end.IsZero() {
+		end = now
+	}
+	return end.Sub(job.StartedAt)
+}
+
+// markJobExited records that a job's task returned
+//
+// Returns:
+//   - bool: True if the job was abandoned as a zombie (its outcome must be discarded)
+func (jq *JobQueue) markJobExited(jobID string) bool {
+	jq.mu.Lock()
+	defer jq.mu.Unlock()
+
+	for i, job := range jq.jobs {
+		if job.ID == jobID {
+			jq.jobs[i].Exited = true
+			if job.Status == "zombie" {
+				runtime.EventsEmit(jq.app.ctx, "jobQueueUpdated", jq.getJobStatusesUnsafe())
+				return true
+			}
+			return false
+		}
+	}
+	return true // Cleared from the queue while running
+}
+
+// ForceKillJob abandons a job whose task does not return after cancellation
+//
+// The job's context is cancelled and the job is marked zombie, which finishes it for
+// everything waiting on it. The task keeps running until it returns on its own; its
+// result is then discarded.
+//
+// Parameters:
+//   - jobID: Unique identifier of the job to abandon
+//
+// Returns:
+//   - error: Error if job not found or its task already returned
+func (jq *JobQueue) ForceKillJob(jobID string) error {
+	jq.mu.Lock()
+	defer jq.mu.Unlock()
+
+	for i, job := range jq.jobs {
+		if job.ID != jobID {
+			continue
+		}
+		if job.Exited || job.Status == "zombie" {
+			return fmt.Errorf("job %s cannot be force-killed (status: %s)", jobID, job.Status)
+		}
+		if job.CancelFunc != nil {
+			job.CancelFunc()
+		}
+		now := time.Now()
+		if job.CancelRequestedAt.IsZero() {
+			jq.jobs[i].CancelRequestedAt = now
+		}
+		jq.jobs[i].Status = "zombie"
+		jq.jobs[i].CompletedAt = now
+		jq.jobs[i].Error = "abandoned: the task did not stop after cancellation"
+		runtime.EventsEmit(jq.app.ctx, "jobQueueUpdated", jq.getJobStatusesUnsafe())
+		jq.checkPressureUnsafe()
+		runtime.LogWarningf(jq.app.ctx, "Force-killed job %s after %s (status was %s)", jobID, jobElapsed(job, now).Round(time.Millisecond), job.Status)
+		return nil
+	}
+
+	return fmt.Errorf("job not found: %s", jobID)
+}
+
 // updateJobStatus updates the status of a job by ID
 //
 // This is a thread-safe method that updates the job's status and emits
@@ -284,6 +512,104 @@ func (jq *JobQueue) setJobProgress(jobID string, progress float64) {
 	}
 }
 
+// setJobResult stores the result returned by a job's handler
+//
+// Parameters:
+//   - jobID: Unique identifier of the job
+//   - result: Handler result
+func (jq *JobQueue) setJobResult(jobID string, result interface{}) {
+	size := resultSize(result)
+
+	jq.mu.Lock()
+	defer jq.mu.Unlock()
+
+	for i, job := range jq.jobs {
+		if job.ID == jobID {
+			jq.jobs[i].Result = result
+			jq.jobs[i].ResultBytes = size
+			jq.checkPressureUnsafe()
+			break
+		}
+	}
+}
+
+// setJobProject records the project an LLM job works on
+//
+// Parameters:
+//   - jobID: Unique identifier of the job
+//   - project: Project root directory
+func (jq *JobQueue) setJobProject(jobID string, project string) {
+	jq.mu.Lock()
+	defer jq.mu.Unlock()
+
+	for i, job := range jq.jobs {
+		if job.ID == jobID {
+			jq.jobs[i].Project = project
+			runtime.EventsEmit(jq.app.ctx, "jobQueueUpdated", jq.getJobStatusesUnsafe())
+			break
+		}
+	}
+}
+
+// setJobParent records the job that enqueued a child job
+//
+// Parameters:
+//   - jobID: Unique identifier of the child job
+//   - parentID: Unique identifier of the parent job
+func (jq *JobQueue) setJobParent(jobID string, parentID string) {
+	jq.mu.Lock()
+	defer jq.mu.Unlock()
+
+	for i, job := range jq.jobs {
+		if job.ID == jobID {
+			jq.jobs[i].ParentID = parentID
+			runtime.EventsEmit(jq.app.ctx, "jobQueueUpdated", jq.getJobStatusesUnsafe())
+			break
+		}
+	}
+}
+
+// waitForJob blocks until a job has finished (completed, failed or cancelled)
+//
+// Parameters:
+//   - ctx: Context ending the wait early
+//   - jobID: Unique identifier of the job
+//
+// Returns:
+//   - Job: Copy of the finished job
+//   - error: Error if the job is not found or ctx ends first
+func (jq *JobQueue) waitForJob(ctx context.Context, jobID string) (Job, error) {
+	ticker := time.NewTicker(200 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		job, ok := jq.getJob(jobID)
+		if !ok {
+			return Job{}, fmt.Errorf("job not found: %s", jobID)
+		}
+		if job.Status != "queued" && job.Status != "running" {
+			return job, nil
+		}
+		select {
+		case <-ctx.Done():
+			return job, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// getJob returns a copy of a job by ID
+func (jq *JobQueue) getJob(jobID string) (Job, bool) {
+	jq.mu.Lock()
+	defer jq.mu.Unlock()
+
+	for _, job := range jq.jobs {
+		if job.ID == jobID {
+			return job, true
+		}
+	}
+	return Job{}, false
+}
+
 // setJobStartTime sets the start time for a job
 //
 // Parameters:
@@ -358,8 +684,119 @@ func (jq *JobQueue) CleanupOldJobs(maxAge time.Duration) int {
 	if removed > 0 {
 		runtime.LogInfo(jq.app.ctx, fmt.Sprintf("Cleaned up %d old jobs", removed))
 		runtime.EventsEmit(jq.app.ctx, "jobQueueUpdated", jq.getJobStatusesUnsafe())
+		jq.checkPressureUnsafe()
+	}
+
+	return removed
+}
+
+// CancelAllJobs cancels every queued or running job
+//
+// Returns:
+//   - int: Number of jobs cancelled
+func (jq *JobQueue) CancelAllJobs() int {
+	jq.mu.Lock()
+	defer jq.mu.Unlock()
+
+	cancelled := 0
+	for i, job := range jq.jobs {
+		if job.Status != "queued" && job.Status != "running" {
+			continue
+		}
+		if job.CancelFunc != nil {
+			job.CancelFunc()
+		}
+		jq.jobs[i].Status = "cancelled"
+		jq.jobs[i].CompletedAt = time.Now()
+		jq.jobs[i].CancelRequestedAt = jq.jobs[i].CompletedAt
+		cancelled++
+	}
+
+	if cancelled > 0 {
+		runtime.LogInfo(jq.app.ctx, fmt.Sprintf("Cancelled %d jobs", cancelled))
+		runtime.EventsEmit(jq.app.ctx, "jobQueueUpdated", jq.getJobStatusesUnsafe())
+		jq.checkPressureUnsafe()
+	}
+
+	return cancelled
+}
+
+// ClearCompletedJobs removes all completed, failed, and cancelled jobs regardless of age
+//
+// Returns:
+//   - int: Number of jobs removed
+func (jq *JobQueue) ClearCompletedJobs() int {
+	jq.mu.Lock()
+	defer jq.mu.Unlock()
+
+	removed := 0
+	newJobs := make([]Job, 0)
+	for _, job := range jq.jobs {
+		if job.Status == "running" || job.Status == "queued" {
+			newJobs = append(newJobs, job)
+			continue
+		}
+		removed++
+	}
+	jq.jobs = newJobs
+
+	if removed > 0 {
+		runtime.LogInfo(jq.app.ctx, fmt.Sprintf("Cleared %d finished jobs", removed))
+		runtime.EventsEmit(jq.app.ctx, "jobQueueUpdated", jq.getJobStatusesUnsafe())
+		jq.checkPressureUnsafe()
 	}
 
 	return removed
 }
 
+// RetryJob re-runs the task of a failed, cancelled or zombie job as a new job
+//
+// The original job stays in the history; the new job references it via RetryOf.
+// Retries are admitted like new jobs: a low-priority job is refused under backpressure.
+//
+// Parameters:
+//   - jobID: Unique identifier of the job to retry
+//
+// Returns:
+//   - string: ID of the new job
+//   - error: Error if job not found or still active, or wrapping errJobQueueBusy if refused
+func (jq *JobQueue) RetryJob(jobID string) (string, error) {
+	jq.mu.Lock()
+	var original *Job
+	for i := range jq.jobs {
+		if jq.jobs[i].ID == jobID {
+			job := jq.jobs[i]
+			original = &job
+			break
+		}
+	}
+	var err error
+	switch {
+	case original == nil:
+		err = fmt.Errorf("job not found: %s", jobID)
+	case original.Status != "failed" && original.Status != "cancelled" && original.Status != "zombie":
+		err = fmt.Errorf("job %s cannot be retried (status: %s)", jobID, original.Status)
+	case original.task == nil:
+		err = fmt.Errorf("job %s has no task to retry", jobID)
+	default:
+		err = jq.admitUnsafe(original.Type)
+	}
+	jq.mu.Unlock()
+	if err != nil {
+		return "", err
+	}
+
+	newJobID := jq.AddJob(original.Type, original.task)
+
+	jq.mu.Lock()
+	for i := range jq.jobs {
+		if jq.jobs[i].ID == newJobID {
+			jq.jobs[i].RetryOf = jobID
+			break
+		}
+	}
+	jq.mu.Unlock()
+
+	runtime.LogInfo(jq.app.ctx, fmt.Sprintf("Retrying job %s as %s", jobID, newJobID))
+	return newJobID, nil
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- Language Detection ---
+//
+// Files are mapped to a language by well-known file name, then by extension. Files whose name
+// tells nothing (extensionless scripts in bin/, "Other") and ambiguous extensions (.h is C or
+// C++) are refined from their first bytes: a shebang line (#!/usr/bin/env python3), an editor
+// mode line (-*- mode: ruby -*-, vim: ft=lua) or a telltale first line (<?php, <?xml,
+// <!DOCTYPE html>), and C++ constructs in headers. The language is shown on FileNode, used for
+// Markdown code fences and comment stripping, and, with LanguageInFileHeaders on, added to the
+// file blocks of generated context:
+//
+//	<file path="bin/deploy" language="bash">
+
+const languageSniffBytes = 1024 // Bytes read from the start of a file for content heuristics
+
+// languageByExtension maps lowercase file extensions to language names
+var languageByExtension = map[string]string{
+	".go": "Go", ".rs": "Rust", ".py": "Python", ".rb": "Ruby", ".java": "Java", ".kt": "Kotlin",
+	".kts": "Kotlin", ".scala": "Scala", ".swift": "Swift", ".c": "C", ".h": "C", ".cc": "C++",
+	".cpp": "C++", ".cxx": "C++", ".hpp": "C++", ".cs": "C#", ".fs": "F#", ".php": "PHP",
+	".js": "JavaScript", ".mjs": "JavaScript", ".cjs": "JavaScript", ".jsx": "JavaScript",
+	".ts": "TypeScript", ".tsx": "TypeScript", ".vue": "Vue", ".svelte": "Svelte",
+	".html": "HTML", ".htm": "HTML", ".css": "CSS", ".scss": "SCSS", ".sass": "SCSS", ".less": "Less",
+	".json": "JSON", ".yaml": "YAML", ".yml": "YAML", ".toml": "TOML", ".xml": "XML",
+	".md": "Markdown", ".mdx": "Markdown", ".rst": "reStructuredText", ".sql": "SQL",
+	".sh": "Shell", ".bash": "Shell", ".zsh": "Shell", ".ps1": "PowerShell", ".lua": "Lua",
+	".dart": "Dart", ".ex": "Elixir", ".exs": "Elixir", ".erl": "Erlang", ".hs": "Haskell",
+	".clj": "Clojure", ".zig": "Zig", ".r": "R", ".m": "Objective-C", ".proto": "Protocol Buffers",
+	".graphql": "GraphQL", ".tf": "HCL",
+}
+
+// languageByFilename maps well-known file names without a telling extension to language names
+var languageByFilename = map[string]string{
+	"Dockerfile": "Dockerfile", "Makefile": "Makefile", "CMakeLists.txt": "CMake",
+	"Gemfile": "Ruby", "Rakefile": "Ruby", "go.mod": "Go Module", "go.sum": "Go Module",
+}
+
+// languageByInterpreter maps shebang interpreters (without version suffix) to language names
+var languageByInterpreter = map[string]string{
+	"sh": "Shell", "bash": "Shell", "zsh": "Shell", "dash": "Shell", "ksh": "Shell",
+	"python": "Python", "ruby": "Ruby", "node": "JavaScript", "deno": "TypeScript",
+	"ts-node": "TypeScript", "tsx": "TypeScript", "bun": "JavaScript", "php": "PHP",
+	"lua": "Lua", "pwsh": "PowerShell", "Rscript": "R", "elixir": "Elixir", "escript": "Erlang",
+	"runhaskell": "Haskell", "swift": "Swift", "kotlin": "Kotlin", "scala": "Scala",
+	"perl": "Perl", "make": "Makefile",
+}
+
+// languageByMode maps lowercase Emacs and Vim mode names to language names
+var languageByMode = map[string]string{
+	"python": "Python", "ruby": "Ruby", "sh": "Shell", "bash": "Shell", "shell-script": "Shell",
+	"javascript": "JavaScript", "js": "JavaScript", "typescript": "TypeScript", "go": "Go",
+	"c": "C", "cpp": "C++", "c++": "C++", "lua": "Lua", "perl": "Perl", "php": "PHP",
+	"yaml": "YAML", "json": "JSON", "toml": "TOML", "xml": "XML", "html": "HTML",
+	"markdown": "Markdown", "makefile": "Makefile", "dockerfile": "Dockerfile", "sql": "SQL",
+}
+
+var (
+	interpreterVersionRegex = regexp.MustCompile(`[\d.]+$`)
+	modeLineRegex           = regexp.MustCompile(`-\*-\s*(?:mode:\s*)?([\w+-]+)\s*(?:;.*)?-\*-|vim?:.*\b(?:ft|filetype|syntax)=([\w+-]+)`)
+	cppHeaderRegex          = regexp.MustCompile(`(?m)^\s*(?:namespace\s+\w+|template\s*<|class\s+\w+[^;]*\{|#include\s*<(?:iostream|string|vector|memory|map)>)`)
+)
+
+// detectLanguage returns the language of a file from its name, or "Other"
+func detectLanguage(relPath string) string {
+	name := filepath.Base(relPath)
+	if lang, ok := languageByFilename[name]; ok {
+		return lang
+	}
+	if lang, ok := languageByExtension[strings.ToLower(filepath.Ext(name))]; ok {
+		return lang
+	}
+	return "Other"
+}
+
+// needsContentDetection reports whether the language detected from a name can be refined by
+// the file's content
+func needsContentDetection(relPath, lang string) bool {
+	return lang == "Other" || strings.EqualFold(filepath.Ext(relPath), ".h")
+}
+
+// detectContentLanguage returns the language of a file from its name, refined by its content
+//
+// Parameters:
+//   - relPath: Path of the file (any separators)
+//   - content: Content of the file, or at least its first languageSniffBytes bytes
+//
+// Returns:
+//   - string: Language name ("Other" if neither the name nor the content tell)
+func detectContentLanguage(relPath string, content []byte) string {
+	lang := detectLanguage(relPath)
+	if !needsContentDetection(relPath, lang) {
+		return lang
+	}
+	if len(content) > languageSniffBytes {
+		content = content[:languageSniffBytes]
+	}
+	if lang == "C" {
+		if cppHeaderRegex.Match(content) {
+			return "C++"
+		}
+		return lang
+	}
+
+	firstLine, _, _ := bytes.Cut(content, []byte("\n"))
+	firstLine = bytes.TrimSpace(firstLine)
+	if bytes.HasPrefix(firstLine, []byte("#!")) {
+		if shebangLang := interpreterLanguage(string(firstLine[2:])); shebangLang != "" {
+			return shebangLang
+		}
+	}
+	if m := modeLineRegex.FindSubmatch(content); m != nil {
+		mode := string(m[1])
+		if mode == "" {
+			mode = string(m[2])
+		}
+		if modeLang, ok := languageByMode[strings.ToLower(mode)]; ok {
+			return modeLang
+		}
+	}
+	lower := bytes.ToLower(firstLine)
+	switch {
+	case bytes.HasPrefix(lower, []byte("<?php")):
+		return "PHP"
+	case bytes.HasPrefix(lower, []byte("<?xml")):
+		return "XML"
+	case bytes.HasPrefix(lower, []byte("<!doctype html")), bytes.HasPrefix(lower, []byte("<html")):
+		return "HTML"
+	}
+	return lang
+}
+
+// interpreterLanguage returns the language of a shebang command line ("" if unknown)
+func interpreterLanguage(command string) string {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return ""
+	}
+	interpreter := path.Base(fields[0])
+	if interpreter == "env" {
+		// #!/usr/bin/env [-S] python3 -u
+		for _, field := range fields[1:] {
+			if !strings.HasPrefix(field, "-") && !strings.Contains(field, "=") {
+				interpreter = field
+				break
+			}
+		}
+	}
+	if lang, ok := languageByInterpreter[interpreter]; ok {
+		return lang
+	}
+	return languageByInterpreter[interpreterVersionRegex.ReplaceAllString(interpreter, "")]
+}
+
+// detectFileLanguage returns the language of a file on disk, reading its first bytes only if
+// its name does not tell
+func (a *App) detectFileLanguage(absPath, relPath string) string {
+	lang := detectLanguage(relPath)
+	if !needsContentDetection(relPath, lang) {
+		return lang
+	}
+	file, err := a.projectFS.Open(absPath)
+	if err != nil {
+		return lang
+	}
+	defer file.Close()
+	head := make([]byte, languageSniffBytes)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return lang
+	}
+	return detectContentLanguage(relPath, head[:n])
+}
+
+// languageHeaderAttr returns the language attribute of a file block ("" when
+// LanguageInFileHeaders is off or the language is unknown)
+func (a *App) languageHeaderAttr(relPath string, content []byte) string {
+	if !a.settings.LanguageInFileHeaders {
+		return ""
+	}
+	hint := languageFenceHint(detectContentLanguage(relPath, content))
+	if hint == "" {
+		return ""
+	}
+	return fmt.Sprintf(" language=\"%s\"", fileBlockAttr(hint))
+}
+
+// ============================================================================
+// Language Detection Methods (Wails-bound)
+// ============================================================================
+
+// DetectFileLanguage returns the language of a project file, from its name and content
+//
+// Parameters:
+//   - rootDir: Project root directory
+//   - relPath: Path of the file relative to rootDir
+//
+// Returns:
+//   - string: Language name ("Other" if unknown)
+//   - error: Error if the project is not allowed or the path leaves it
+func (a *App) DetectFileLanguage(rootDir, relPath string) (string, error) {
+	if err := a.validateContentRoot(rootDir); err != nil {
+		return "", err
+	}
+	absPath, err := resolveProjectPath(rootDir, relPath)
+	if err != nil {
+		return "", err
+	}
+	return a.detectFileLanguage(absPath, relPath), nil
+}
+
+// GetLanguageInFileHeaders returns whether file blocks of generated context carry a language attribute
+func (a *App) GetLanguageInFileHeaders() bool {
+	return a.settings.LanguageInFileHeaders
+}
+
+// SetLanguageInFileHeaders enables or disables the language attribute of file blocks and saves the setting
+func (a *App) SetLanguageInFileHeaders(enabled bool) error {
+	a.settings.LanguageInFileHeaders = enabled
+	if err := a.saveSettings(); err != nil {
+		return fmt.Errorf("failed to save language header setting: %w", err)
+	}
+	runtime.LogInfof(a.ctx, "Language in file headers: %v", enabled)
+	return nil
+}
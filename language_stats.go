@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- Language Statistics ---
+//
+// Linguist-style breakdown of a selection by language, detected from file extensions and
+// well-known file names (see language_detect.go). Binary files are not counted.
+
+// dataLanguages are data and prose formats, left out of the tech stack summary like linguist does
+var dataLanguages = map[string]bool{
+	"JSON": true, "YAML": true, "TOML": true, "XML": true, "Markdown": true,
+	"reStructuredText": true, "Go Module": true, "Other": true,
+}
+
+// LanguageStat holds the totals of one language
+type LanguageStat struct {
+	Language        string  `json:"language"`        // Language name ("Other" for unrecognized text files)
+	Files           int     `json:"files"`           // Number of files
+	Lines           int     `json:"lines"`           // Lines of code (including blank lines and comments)
+	Bytes           int64   `json:"bytes"`           // Total size
+	EstimatedTokens int     `json:"estimatedTokens"` // Approximate tokens (bytes / 4)
+	Percent         float64 `json:"percent"`         // Share of the total bytes, 0-100
+}
+
+// LanguageStats is the language breakdown of a selection
+type LanguageStats struct {
+	RootDir         string         `json:"rootDir"`         // Project root directory
+	Languages       []LanguageStat `json:"languages"`       // Per-language totals, largest first
+	TotalFiles      int            `json:"totalFiles"`      // Text files counted
+	TotalLines      int            `json:"totalLines"`      // Lines across all counted files
+	TotalBytes      int64          `json:"totalBytes"`      // Bytes across all counted files
+	EstimatedTokens int            `json:"estimatedTokens"` // Approximate tokens (bytes / 4)
+}
+
+// computeLanguageStats breaks the selection down by language
+// Counting lines reads every file; without it only the directory walk is needed.
+func (a *App) computeLanguageStats(ctx context.Context, rootDir string, excludedPaths []string, countLines bool) (LanguageStats, error) {
+	byLanguage := make(map[string]*LanguageStat)
+	_, err := a.scanSelection(ctx, rootDir, excludedPaths, func(relPath string, size int64) {
+		absPath := filepath.Join(rootDir, relPath)
+		if binaryFilenames[filepath.Base(relPath)] || binaryExtensions[strings.ToLower(filepath.Ext(relPath))] {
+			return
+		}
+
+		lines := 0
+		if countLines {
+			if isBinary, err := a.isBinaryFile(absPath); err != nil || isBinary {
+				return
+			}
+			content, err := a.projectFS.ReadFile(absPath)
+			if err != nil {
+				return
+			}
+			lines = bytes.Count(content, []byte{'\n'})
+			if len(content) > 0 && content[len(content)-1] != '\n' {
+				lines++ // Last line without trailing newline
+			}
+		}
+
+		lang := detectLanguage(relPath)
+		stat, ok := byLanguage[lang]
+		if !ok {
+			stat = &LanguageStat{Language: lang}
+			byLanguage[lang] = stat
+		}
+		stat.Files++
+		stat.Lines += lines
+		stat.Bytes += size
+	})
+	if err != nil {
+		return LanguageStats{}, err
+	}
+
+	stats := LanguageStats{RootDir: rootDir, Languages: make([]LanguageStat, 0, len(byLanguage))}
+	for _, stat := range byLanguage {
+		stats.TotalFiles += stat.Files
+		stats.TotalLines += stat.Lines
+		stats.TotalBytes += stat.Bytes
+	}
+	for _, stat := range byLanguage {
+		stat.EstimatedTokens = int(stat.Bytes / 4)
+		if stats.TotalBytes > 0 {
+			stat.Percent = float64(stat.Bytes) * 100 / float64(stats.TotalBytes)
+		}
+		stats.Languages = append(stats.Languages, *stat)
+	}
+	sort.Slice(stats.Languages, func(i, j int) bool {
+		if stats.Languages[i].Bytes != stats.Languages[j].Bytes {
+			return stats.Languages[i].Bytes > stats.Languages[j].Bytes
+		}
+		return stats.Languages[i].Language < stats.Languages[j].Language
+	})
+	stats.EstimatedTokens = int(stats.TotalBytes / 4)
+	return stats, nil
+}
+
+// techStackSummary renders a one-line summary of the main languages, e.g.
+// "Tech stack: Go (61%), Vue (24%), TypeScript (9%)". Data formats and languages under 1% are omitted.
+func techStackSummary(stats LanguageStats) string {
+	var parts []string
+	for _, stat := range stats.Languages {
+		if len(parts) == 5 {
+			break
+		}
+		if dataLanguages[stat.Language] || stat.Percent < 1 {
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s (%.0f%%)", stat.Language, stat.Percent))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "Tech stack: " + strings.Join(parts, ", ")
+}
+
+// ============================================================================
+// Language Statistics Methods (Wails-bound)
+// ============================================================================
+
+// GetLanguageStats returns per-language file counts, lines of code, bytes and token estimates
+//
+// Parameters:
+//   - rootDir: Project root directory
+//   - excludedPaths: The selection, expressed as exclusions like RequestShotgunContextGeneration
+//
+// Returns:
+//   - LanguageStats: Per-language totals, largest first
+//   - error: Error if the directory does not exist or the walk fails
+func (a *App) GetLanguageStats(rootDir string, excludedPaths []string) (LanguageStats, error) {
+	if err := a.validateContentRoot(rootDir); err != nil {
+		return LanguageStats{}, err
+	}
+	stats, err := a.computeLanguageStats(a.ctx, rootDir, excludedPaths, true)
+	if err != nil {
+		return LanguageStats{}, fmt.Errorf("failed to compute language statistics: %w", err)
+	}
+	return stats, nil
+}
+
+// GetIncludeTechStack returns whether generated context starts with a tech stack summary
+func (a *App) GetIncludeTechStack() bool {
+	return a.settings.IncludeTechStack
+}
+
+// SetIncludeTechStack enables or disables the tech stack summary in generated context and saves the setting
+func (a *App) SetIncludeTechStack(enabled bool) error {
+	a.settings.IncludeTechStack = enabled
+	if err := a.saveSettings(); err != nil {
+		return fmt.Errorf("failed to save tech stack setting: %w", err)
+	}
+	runtime.LogInfof(a.ctx, "Tech stack summary in context: %v", enabled)
+	return nil
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- Line-Numbered Content ---
+//
+// With the LineNumbers setting on, every line of included file content is prefixed with its
+// line number, right-aligned to the width of the file's last number:
+//
+//	<file path="main.go" line-numbers="true">
+//	 9 | func main() {
+//	10 | 	run()
+//	11 | }
+//	</file>
+//
+// Models write better targeted diffs when they can see line numbers, and numbering here
+// spares the frontend a pass over huge strings. Composed prompts tell the model that the
+// numbers are not part of the files. Truncated files keep the real numbers of their lines.
+// Code review numbers the files it sends the same way, so comments can refer to lines.
+
+// lineNumberInstructions tells the model how to read line-numbered content
+const lineNumberInstructions = `File contents are shown with line numbers ("12 | code"). The numbers and the " | " separator are not part of the files: never include them in diffs or file contents.`
+
+// numberLines prefixes each line of content with its line number
+func numberLines(content string) string {
+	return numberLinesFrom(content, 1, 0)
+}
+
+// numberLinesFrom prefixes each line of content with its line number, counting from first
+// and right-aligned to width digits (0 for the width of the last number)
+func numberLinesFrom(content string, first, width int) string {
+	if content == "" {
+		return content
+	}
+	trailingNewline := strings.HasSuffix(content, "\n")
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	if width <= 0 {
+		width = len(strconv.Itoa(first + len(lines) - 1))
+	}
+
+	var b strings.Builder
+	b.Grow(len(content) + len(lines)*(width+3))
+	for i, line := range lines {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		if line == "" || line == "\r" {
+			fmt.Fprintf(&b, "%*d |%s", width, first+i, line)
+		} else {
+			fmt.Fprintf(&b, "%*d | %s", width, first+i, line)
+		}
+	}
+	if trailingNewline {
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// ============================================================================
+// Line Number Methods (Wails-bound)
+// ============================================================================
+
+// GetLineNumbers returns whether included file content is prefixed with line numbers
+func (a *App) GetLineNumbers() bool {
+	return a.settings.LineNumbers
+}
+
+// SetLineNumbers turns line numbers in included file content on or off and saves the setting
+//
+// Parameters:
+//   - enabled: True to prefix each line of file content with its line number
+//
+// Returns:
+//   - error: Error if the setting cannot be saved
+func (a *App) SetLineNumbers(enabled bool) error {
+	a.settings.LineNumbers = enabled
+	if err := a.saveSettings(); err != nil {
+		return fmt.Errorf("failed to save line numbers setting: %w", err)
+	}
+	runtime.LogInfof(a.ctx, "Line numbers in file content: %v", enabled)
+	return nil
+}
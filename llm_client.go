@@ -62,13 +62,29 @@ type LLMClient struct {
 
 // LLMRequest represents a request to an LLM API
 type LLMRequest struct {
-	Provider    string  `json:"provider"`    // Provider: google, openai, anthropic, custom
-	APIKey      string  `json:"apiKey"`      // API key for the provider (optional for custom)
-	Prompt      string  `json:"prompt"`      // The prompt to send
-	Model       string  `json:"model"`       // Model name (e.g., gemini-2.5-flash, gpt-5-mini, claude-sonnet-4-5-20250929)
-	Temperature float64 `json:"temperature"` // Temperature (0.0-1.0)
-	MaxTokens   int     `json:"maxTokens"`   // Maximum tokens to generate
-	BaseURL     string  `json:"baseURL"`     // Custom base URL (for custom provider only)
+	Provider    string   `json:"provider"`              // Provider: google, openai, anthropic, custom
+	APIKey      string   `json:"apiKey"`                // API key for the provider (optional for custom)
+	Prompt      string   `json:"prompt"`                // The prompt to send
+	Model       string   `json:"model"`                 // Model name (e.g., gemini-2.5-flash, gpt-5-mini, claude-sonnet-4-5-20250929), or auto (see model_routing.go)
+	Temperature *float64 `json:"temperature,omitempty"` // Temperature (0.0-1.0); nil uses the default, 0 is honored
+	MaxTokens   *int     `json:"maxTokens,omitempty"`   // Maximum tokens to generate; nil uses the default
+	BaseURL     string   `json:"baseURL"`               // Custom base URL (for custom provider only)
+	Mode        string   `json:"mode,omitempty"`        // Prompt mode whose defaults fill unset fields (see mode_defaults.go)
+	Task        string   `json:"task,omitempty"`        // Task the prompt was built for (recorded in the call's workflow)
+	RootDir     string   `json:"rootDir,omitempty"`     // Project the prompt is about (recorded in the call's workflow)
+
+	Stop             []string `json:"stop,omitempty"`             // Stop sequences (e.g., "```" to stop after a diff)
+	TopP             *float64 `json:"topP,omitempty"`             // Nucleus sampling (0.0-1.0)
+	TopK             *int     `json:"topK,omitempty"`             // Top-k sampling (Anthropic, Google, custom)
+	FrequencyPenalty *float64 `json:"frequencyPenalty,omitempty"` // Frequency penalty (-2.0-2.0, OpenAI, Google, custom)
+	PresencePenalty  *float64 `json:"presencePenalty,omitempty"`  // Presence penalty (-2.0-2.0, OpenAI, Google, custom)
+	Seed             *int64   `json:"seed,omitempty"`             // Sampling seed for reproducible output (OpenAI, Google, custom)
+
+	ThinkingBudget  int    `json:"thinkingBudget,omitempty"`  // Extended thinking budget in tokens (Anthropic thinking, Gemini thinkingConfig)
+	ReasoningEffort string `json:"reasoningEffort,omitempty"` // Reasoning effort: minimal, low, medium, high (OpenAI reasoning models, custom)
+
+	AssistantPrefix  string `json:"assistantPrefix,omitempty"`  // Partial answer to continue from (continuation requests)
+	MaxContinuations int    `json:"maxContinuations,omitempty"` // Automatic continuation requests when the output is truncated
 }
 
 // LLMResponse represents a response from an LLM API
@@ -78,8 +94,35 @@ type LLMResponse struct {
 	Cost       float64 `json:"cost"`       // Estimated cost in USD
 	Model      string  `json:"model"`      // Model used
 	Provider   string  `json:"provider"`   // Provider used
+
+	OutputTokens   int `json:"outputTokens"`   // Generated tokens, thinking included (estimated if the provider does not report them)
+	ThinkingTokens int `json:"thinkingTokens"` // Reasoning/thinking tokens (billed as output; estimated for Anthropic)
+
+	FinishReason  string `json:"finishReason"`  // Provider-reported stop reason (e.g., stop, length, max_tokens, MAX_TOKENS)
+	Truncated     bool   `json:"truncated"`     // True if generation stopped because of the output token limit
+	Continuations int    `json:"continuations"` // Number of automatic continuation requests stitched into Content
+
+	FirstByteMs     int64   `json:"firstByteMs"`     // Time to the first byte of the response, in milliseconds
+	LatencyMs       int64   `json:"latencyMs"`       // Time until the response was read, in milliseconds (summed over continuations and tool turns)
+	TokensPerSecond float64 `json:"tokensPerSecond"` // Output tokens per second of latency
+
+	Routing         *ModelRoute    `json:"routing,omitempty"`         // How the model was picked for an "auto" request (nil otherwise)
+	SecretsRedacted map[string]int `json:"secretsRedacted,omitempty"` // Likely secrets masked from the prompt, by kind
 }
 
+// addUsage adds the usage of a follow-up request (a continuation) to the response
+func (r *LLMResponse) addUsage(next *LLMResponse) {
+	r.TokensUsed += next.TokensUsed
+	r.Cost += next.Cost
+	r.OutputTokens += next.OutputTokens
+	r.ThinkingTokens += next.ThinkingTokens
+	r.LatencyMs += next.LatencyMs
+	r.TokensPerSecond = tokensPerSecond(r.OutputTokens, r.LatencyMs)
+}
+
+// continuationInstruction is sent after a truncated answer for providers without assistant prefill
+const continuationInstruction = "Your previous response was cut off by the output token limit. Continue exactly where it stopped. Do not repeat any text and do not add a preamble."
+
 // NewLLMClient creates a new LLM client instance
 //
 // Parameters:
@@ -91,11 +134,26 @@ func NewLLMClient(app *App) *LLMClient {
 	return &LLMClient{
 		app: app,
 		httpClient: &http.Client{
-			Timeout: 60 * time.Second, // 60-second timeout for API calls
+			Timeout:   60 * time.Second,  // 60-second timeout for API calls
+			Transport: outboundTransport, // Refuses non-local hosts in offline mode
 		},
 	}
 }
 
+// readResponseBody reads an HTTP response body into a spillBuffer
+// The caller closes the buffer, which deletes its temp file if the body was spilled to disk
+func (c *LLMClient) readResponseBody(r io.Reader) (*spillBuffer, error) {
+	body := c.app.newSpillBuffer()
+	if _, err := io.Copy(body, r); err != nil {
+		body.Close()
+		return nil, err
+	}
+	if body.Spilled() {
+		runtime.LogInfof(c.app.ctx, "LLM response of %d bytes was kept on disk while parsing", body.Len())
+	}
+	return body, nil
+}
+
 // CallLLM calls the appropriate LLM API based on the provider
 //
 // This method routes the request to the appropriate provider-specific method
@@ -116,8 +174,8 @@ func NewLLMClient(app *App) *LLMClient {
 //	    APIKey: "your-api-key",
 //	    Prompt: "Write a hello world function in Go",
 //	    Model: "gemini-1.5-pro",
-//	    Temperature: 0.7,
-//	    MaxTokens: 2048,
+//	    Temperature: float64Ptr(0.7),
+//	    MaxTokens: intPtr(2048),
 //	}
 //	resp, err := client.CallLLM(ctx, req)
 func (c *LLMClient) CallLLM(ctx context.Context, req LLMRequest) (*LLMResponse, error) {
@@ -132,22 +190,148 @@ func (c *LLMClient) CallLLM(ctx context.Context, req LLMRequest) (*LLMResponse,
 		return nil, fmt.Errorf("prompt is required")
 	}
 
+	if err := validateSamplingParams(req); err != nil {
+		return nil, err
+	}
+
+	secrets := c.app.redactPromptSecrets(&req)
+	route, err := c.routeAutoModel(&req)
+	if err != nil {
+		return nil, err
+	}
+	c.applyRequestDefaults(&req)
+
+	resp, err := c.callProvider(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Routing = route
+	resp.SecretsRedacted = secrets
+
+	// Automatically continue truncated responses, stitching the pieces together
+	generated := resp.Content
+	for resp.Truncated && resp.Continuations < req.MaxContinuations {
+		runtime.LogInfof(c.app.ctx, "Response truncated (%s), requesting continuation %d/%d", resp.FinishReason, resp.Continuations+1, req.MaxContinuations)
+
+		contReq := req
+		contReq.AssistantPrefix = stitchContinuation(req.AssistantPrefix, generated)
+		next, err := c.callProvider(ctx, contReq)
+		if err != nil {
+			return nil, fmt.Errorf("continuation request failed: %w", err)
+		}
+
+		generated = stitchContinuation(generated, next.Content)
+		resp.addUsage(next)
+		resp.FinishReason = next.FinishReason
+		resp.Truncated = next.Truncated
+		resp.Continuations++
+	}
+	resp.Content = generated
+
+	return resp, nil
+}
+
+// applyRequestDefaults fills in the model, temperature, and max tokens when they are not set
+// Temperature and MaxTokens use pointer semantics so that an explicit 0 temperature
+// (deterministic output) is honored instead of being replaced by the default
+func (c *LLMClient) applyRequestDefaults(req *LLMRequest) {
 	// Set default model if not specified
 	if req.Model == "" {
 		req.Model = c.getDefaultModel(req.Provider)
 	}
 
 	// Set default temperature if not specified
-	if req.Temperature == 0 {
-		req.Temperature = 0.7
+	if req.Temperature == nil {
+		req.Temperature = float64Ptr(0.7)
+	}
+
+	// Set default max tokens from the per-model table if not specified
+	if req.MaxTokens == nil || *req.MaxTokens <= 0 {
+		req.MaxTokens = intPtr(defaultMaxTokensForModel(req.Model))
+	}
+}
+
+// fallbackMaxTokens is used for models missing from modelOutputLimits
+const fallbackMaxTokens = 4096
+
+// modelOutputLimits maps model name prefixes to their maximum output tokens (October 2025)
+// The longest matching prefix wins, so specific entries can override family entries
+var modelOutputLimits = map[string]int{
+	// Anthropic
+	"claude-opus-4":     32000,
+	"claude-sonnet-4":   64000,
+	"claude-haiku-4":    64000,
+	"claude-3-7-sonnet": 64000,
+	"claude-3-5-sonnet": 8192,
+	"claude-3-5-haiku":  8192,
+	"claude-3":          4096,
+
+	// Google
+	"gemini-2.5": 65536,
+	"gemini-2.0": 8192,
+	"gemini-1.5": 8192,
+
+	// OpenAI
+	"gpt-5":   128000,
+	"gpt-4.1": 32768,
+	"gpt-4o":  16384,
+	"o4-mini": 100000,
+	"o3":      100000,
+	"o1":      100000,
+}
+
+// defaultMaxTokensForModel returns the output token cap for a model
+// Used when a request does not set MaxTokens, instead of a blanket limit that truncates large diffs
+//
+// Parameters:
+//   - model: Model name (e.g., claude-sonnet-4-5-20250929, models/gemini-2.5-pro)
+//
+// Returns:
+//   - int: Maximum output tokens for the model, or fallbackMaxTokens if unknown
+func defaultMaxTokensForModel(model string) int {
+	name := strings.ToLower(strings.TrimPrefix(model, "models/"))
+
+	bestPrefix := ""
+	limit := fallbackMaxTokens
+	for prefix, maxTokens := range modelOutputLimits {
+		if strings.HasPrefix(name, prefix) && len(prefix) > len(bestPrefix) {
+			bestPrefix = prefix
+			limit = maxTokens
+		}
 	}
+	return limit
+}
+
+// float64Ptr returns a pointer to v (for optional request fields)
+func float64Ptr(v float64) *float64 {
+	return &v
+}
 
-	// Set default max tokens if not specified
-	if req.MaxTokens == 0 {
-		req.MaxTokens = 4096
+// intPtr returns a pointer to v (for optional request fields)
+func intPtr(v int) *int {
+	return &v
+}
+
+// callProvider sends a single request once the provider's rate limit allows it
+func (c *LLMClient) callProvider(ctx context.Context, req LLMRequest) (*LLMResponse, error) {
+	slot, err := c.app.acquireRateLimit(ctx, req.Provider, c.app.EstimateTokens(req.Prompt+req.AssistantPrefix))
+	if err != nil {
+		return nil, err
+	}
+	timing := &callTiming{}
+	timedCtx, done := timing.start(ctx)
+	resp, err := c.routeRequest(timedCtx, req)
+	done()
+	timing.apply(c.app, resp)
+	c.app.recordLLMEgress(req, req.Prompt+req.AssistantPrefix, err)
+	if resp != nil {
+		c.app.settleRateLimit(req.Provider, slot, resp.TokensUsed)
 	}
+	return resp, err
+}
 
-	// Route to appropriate provider
+// routeRequest routes a single request to the appropriate provider
+func (c *LLMClient) routeRequest(ctx context.Context, req LLMRequest) (*LLMResponse, error) {
 	switch req.Provider {
 	case "google":
 		return c.callGoogleAI(ctx, req)
@@ -162,6 +346,225 @@ func (c *LLMClient) CallLLM(ctx context.Context, req LLMRequest) (*LLMResponse,
 	}
 }
 
+// stitchContinuation joins a partial answer with its continuation
+// If the continuation starts with whitespace, trailing whitespace of the prefix is dropped
+// so that line breaks are not duplicated
+func stitchContinuation(prefix, continuation string) string {
+	if prefix == "" {
+		return continuation
+	}
+	if continuation != "" && strings.TrimLeft(continuation[:1], " \t\r\n") == "" {
+		return strings.TrimRight(prefix, " \t\r\n") + continuation
+	}
+	return prefix + continuation
+}
+
+// buildChatMessages builds the messages array for OpenAI-compatible and Anthropic APIs
+// When AssistantPrefix is set, the partial answer is replayed so the model can continue it:
+// Anthropic continues a trailing assistant message directly (prefill), other providers
+// get an explicit instruction to continue
+func buildChatMessages(req LLMRequest) []map[string]string {
+	messages := []map[string]string{
+		{"role": "user", "content": req.Prompt},
+	}
+	if req.AssistantPrefix == "" {
+		return messages
+	}
+
+	if req.Provider == "anthropic" {
+		// Anthropic rejects a final assistant message that ends with whitespace
+		return append(messages, map[string]string{"role": "assistant", "content": strings.TrimRight(req.AssistantPrefix, " \t\r\n")})
+	}
+	return append(messages,
+		map[string]string{"role": "assistant", "content": req.AssistantPrefix},
+		map[string]string{"role": "user", "content": continuationInstruction},
+	)
+}
+
+// validateSamplingParams checks the optional sampling parameters for valid ranges
+func validateSamplingParams(req LLMRequest) error {
+	if req.TopP != nil && (*req.TopP < 0 || *req.TopP > 1) {
+		return fmt.Errorf("topP must be between 0 and 1, got %v", *req.TopP)
+	}
+	if req.TopK != nil && *req.TopK < 1 {
+		return fmt.Errorf("topK must be at least 1, got %d", *req.TopK)
+	}
+	if req.FrequencyPenalty != nil && (*req.FrequencyPenalty < -2 || *req.FrequencyPenalty > 2) {
+		return fmt.Errorf("frequencyPenalty must be between -2 and 2, got %v", *req.FrequencyPenalty)
+	}
+	if req.PresencePenalty != nil && (*req.PresencePenalty < -2 || *req.PresencePenalty > 2) {
+		return fmt.Errorf("presencePenalty must be between -2 and 2, got %v", *req.PresencePenalty)
+	}
+	if req.ThinkingBudget < 0 {
+		return fmt.Errorf("thinkingBudget must not be negative, got %d", req.ThinkingBudget)
+	}
+	if req.ThinkingBudget > 0 && req.Provider == "anthropic" && req.ThinkingBudget < 1024 {
+		return fmt.Errorf("anthropic thinkingBudget must be at least 1024 tokens, got %d", req.ThinkingBudget)
+	}
+	switch req.ReasoningEffort {
+	case "", "minimal", "low", "medium", "high":
+	default:
+		return fmt.Errorf("reasoningEffort must be one of minimal, low, medium, high, got %q", req.ReasoningEffort)
+	}
+	return nil
+}
+
+// applyReasoningParams maps ThinkingBudget and ReasoningEffort onto a provider request body
+//
+// Provider mapping:
+//   - anthropic: thinking {type: enabled, budget_tokens}; max_tokens is raised above the budget
+//     and temperature/top_k are removed as required by the API. Skipped for prefill continuations,
+//     which the API does not allow together with thinking.
+//   - openai / custom: reasoning_effort; reasoning models take max_completion_tokens and no temperature
+//   - google: generationConfig.thinkingConfig.thinkingBudget
+func (c *LLMClient) applyReasoningParams(body map[string]interface{}, req LLMRequest) {
+	switch req.Provider {
+	case "anthropic":
+		if req.ThinkingBudget <= 0 {
+			return
+		}
+		if req.AssistantPrefix != "" {
+			runtime.LogDebug(c.app.ctx, "Extended thinking is not compatible with continuation prefill; sending continuation without thinking")
+			return
+		}
+		body["thinking"] = map[string]interface{}{
+			"type":          "enabled",
+			"budget_tokens": req.ThinkingBudget,
+		}
+		// budget_tokens must be lower than max_tokens; keep the requested answer size on top of the budget
+		if req.MaxTokens != nil && *req.MaxTokens <= req.ThinkingBudget {
+			body["max_tokens"] = req.ThinkingBudget + *req.MaxTokens
+		}
+		delete(body, "temperature")
+		delete(body, "top_k")
+
+	case "openai", "custom":
+		if req.ReasoningEffort == "" {
+			return
+		}
+		body["reasoning_effort"] = req.ReasoningEffort
+		if maxTokens, ok := body["max_tokens"]; ok {
+			body["max_completion_tokens"] = maxTokens
+			delete(body, "max_tokens")
+		}
+		delete(body, "temperature")
+
+	case "google":
+		if req.ThinkingBudget <= 0 {
+			return
+		}
+		generationConfig, ok := body["generationConfig"].(map[string]interface{})
+		if !ok {
+			generationConfig = make(map[string]interface{})
+			body["generationConfig"] = generationConfig
+		}
+		generationConfig["thinkingConfig"] = map[string]interface{}{
+			"thinkingBudget": req.ThinkingBudget,
+		}
+	}
+}
+
+// applySamplingParams maps the optional sampling parameters onto a provider request body
+// Parameters a provider does not support are skipped with a debug log
+//
+// Provider mapping:
+//   - openai: stop, top_p, frequency_penalty, presence_penalty, seed
+//   - custom: same as openai plus top_k (accepted by vLLM, Ollama, LM Studio, etc.)
+//   - anthropic: stop_sequences, top_p, top_k
+//   - google: generationConfig.stopSequences, topP, topK, frequencyPenalty, presencePenalty, seed
+func (c *LLMClient) applySamplingParams(body map[string]interface{}, req LLMRequest) {
+	var unsupported []string
+
+	switch req.Provider {
+	case "openai", "custom":
+		if len(req.Stop) > 0 {
+			body["stop"] = req.Stop
+		}
+		if req.TopP != nil {
+			body["top_p"] = *req.TopP
+		}
+		if req.TopK != nil {
+			if req.Provider == "custom" {
+				body["top_k"] = *req.TopK
+			} else {
+				unsupported = append(unsupported, "topK")
+			}
+		}
+		if req.FrequencyPenalty != nil {
+			body["frequency_penalty"] = *req.FrequencyPenalty
+		}
+		if req.PresencePenalty != nil {
+			body["presence_penalty"] = *req.PresencePenalty
+		}
+		if req.Seed != nil {
+			body["seed"] = *req.Seed
+		}
+
+	case "anthropic":
+		if len(req.Stop) > 0 {
+			body["stop_sequences"] = req.Stop
+		}
+		if req.TopP != nil {
+			body["top_p"] = *req.TopP
+		}
+		if req.TopK != nil {
+			body["top_k"] = *req.TopK
+		}
+		if req.FrequencyPenalty != nil {
+			unsupported = append(unsupported, "frequencyPenalty")
+		}
+		if req.PresencePenalty != nil {
+			unsupported = append(unsupported, "presencePenalty")
+		}
+		if req.Seed != nil {
+			unsupported = append(unsupported, "seed")
+		}
+
+	case "google":
+		generationConfig, ok := body["generationConfig"].(map[string]interface{})
+		if !ok {
+			generationConfig = make(map[string]interface{})
+			body["generationConfig"] = generationConfig
+		}
+		if len(req.Stop) > 0 {
+			generationConfig["stopSequences"] = req.Stop
+		}
+		if req.TopP != nil {
+			generationConfig["topP"] = *req.TopP
+		}
+		if req.TopK != nil {
+			generationConfig["topK"] = *req.TopK
+		}
+		if req.FrequencyPenalty != nil {
+			generationConfig["frequencyPenalty"] = *req.FrequencyPenalty
+		}
+		if req.PresencePenalty != nil {
+			generationConfig["presencePenalty"] = *req.PresencePenalty
+		}
+		if req.Seed != nil {
+			generationConfig["seed"] = *req.Seed
+		}
+	}
+
+	if len(unsupported) > 0 {
+		runtime.LogDebugf(c.app.ctx, "Provider %s does not support %s; ignoring", req.Provider, strings.Join(unsupported, ", "))
+	}
+}
+
+// buildGeminiContents builds the contents array for the Gemini API
+func buildGeminiContents(req LLMRequest) []map[string]interface{} {
+	contents := []map[string]interface{}{
+		{"role": "user", "parts": []map[string]string{{"text": req.Prompt}}},
+	}
+	if req.AssistantPrefix == "" {
+		return contents
+	}
+	return append(contents,
+		map[string]interface{}{"role": "model", "parts": []map[string]string{{"text": req.AssistantPrefix}}},
+		map[string]interface{}{"role": "user", "parts": []map[string]string{{"text": continuationInstruction}}},
+	)
+}
+
 // getDefaultModel returns the default model for a provider (October 2025 latest models)
 //
 // Parameters:
@@ -198,19 +601,16 @@ func (c *LLMClient) callGoogleAI(ctx context.Context, req LLMRequest) (*LLMRespo
 
 	// Build request body
 	requestBody := map[string]interface{}{
-		"contents": []map[string]interface{}{
-			{
-				"parts": []map[string]string{
-					{"text": req.Prompt},
-				},
-			},
-		},
+		"contents": buildGeminiContents(req),
 		"generationConfig": map[string]interface{}{
-			"temperature":     req.Temperature,
-			"maxOutputTokens": req.MaxTokens,
+			"temperature":     *req.Temperature,
+			"maxOutputTokens": *req.MaxTokens,
 		},
 	}
 
+	c.applySamplingParams(requestBody, req)
+	c.applyReasoningParams(requestBody, req)
+
 	// Marshal request body
 	jsonData, err := json.Marshal(requestBody)
 	if err != nil {
@@ -232,15 +632,16 @@ func (c *LLMClient) callGoogleAI(ctx context.Context, req LLMRequest) (*LLMRespo
 	}
 	defer resp.Body.Close()
 
-	// Read response body
-	body, err := io.ReadAll(resp.Body)
+	// Read response body (very large bodies are kept on disk by the memory guard)
+	body, err := c.readResponseBody(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
+	defer body.Close()
 
 	// Check for HTTP errors
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, body.text())
 	}
 
 	// Parse response
@@ -248,18 +649,21 @@ func (c *LLMClient) callGoogleAI(ctx context.Context, req LLMRequest) (*LLMRespo
 		Candidates []struct {
 			Content struct {
 				Parts []struct {
-					Text string `json:"text"`
+					Text    string `json:"text"`
+					Thought bool   `json:"thought"`
 				} `json:"parts"`
 			} `json:"content"`
+			FinishReason string `json:"finishReason"`
 		} `json:"candidates"`
 		UsageMetadata struct {
 			PromptTokenCount     int `json:"promptTokenCount"`
 			CandidatesTokenCount int `json:"candidatesTokenCount"`
+			ThoughtsTokenCount   int `json:"thoughtsTokenCount"`
 			TotalTokenCount      int `json:"totalTokenCount"`
 		} `json:"usageMetadata"`
 	}
 
-	if err := json.Unmarshal(body, &apiResp); err != nil {
+	if err := json.NewDecoder(body.Reader()).Decode(&apiResp); err != nil {
 		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
@@ -268,7 +672,17 @@ func (c *LLMClient) callGoogleAI(ctx context.Context, req LLMRequest) (*LLMRespo
 		return nil, fmt.Errorf("no content in response")
 	}
 
-	generatedText := apiResp.Candidates[0].Content.Parts[0].Text
+	// Concatenate answer parts (thought summaries are skipped)
+	var textBuilder strings.Builder
+	for _, part := range apiResp.Candidates[0].Content.Parts {
+		if !part.Thought {
+			textBuilder.WriteString(part.Text)
+		}
+	}
+	generatedText := textBuilder.String()
+
+	// Thinking tokens are reported separately from candidates but billed as output
+	outputTokens := apiResp.UsageMetadata.CandidatesTokenCount + apiResp.UsageMetadata.ThoughtsTokenCount
 
 	// Calculate cost based on model (October 2025 pricing)
 	// Gemini 2.5 Flash: $0.075 per 1M input tokens, $0.30 per 1M output tokens
@@ -277,22 +691,28 @@ func (c *LLMClient) callGoogleAI(ctx context.Context, req LLMRequest) (*LLMRespo
 	if strings.Contains(req.Model, "flash") {
 		// Gemini 2.5 Flash pricing
 		inputCost = float64(apiResp.UsageMetadata.PromptTokenCount) / 1_000_000.0 * 0.075
-		outputCost = float64(apiResp.UsageMetadata.CandidatesTokenCount) / 1_000_000.0 * 0.30
+		outputCost = float64(outputTokens) / 1_000_000.0 * 0.30
 	} else {
 		// Gemini 2.5 Pro pricing (using lower tier for simplicity)
 		inputCost = float64(apiResp.UsageMetadata.PromptTokenCount) / 1_000_000.0 * 1.25
-		outputCost = float64(apiResp.UsageMetadata.CandidatesTokenCount) / 1_000_000.0 * 10.0
+		outputCost = float64(outputTokens) / 1_000_000.0 * 10.0
 	}
 	totalCost := inputCost + outputCost
 
 	runtime.LogInfo(c.app.ctx, fmt.Sprintf("Google AI response received: %d tokens, $%.6f", apiResp.UsageMetadata.TotalTokenCount, totalCost))
 
+	finishReason := apiResp.Candidates[0].FinishReason
+
 	return &LLMResponse{
-		Content:    generatedText,
-		TokensUsed: apiResp.UsageMetadata.TotalTokenCount,
-		Cost:       totalCost,
-		Model:      req.Model,
-		Provider:   "google",
+		Content:        generatedText,
+		TokensUsed:     apiResp.UsageMetadata.TotalTokenCount,
+		Cost:           totalCost,
+		Model:          req.Model,
+		Provider:       "google",
+		OutputTokens:   outputTokens,
+		ThinkingTokens: apiResp.UsageMetadata.ThoughtsTokenCount,
+		FinishReason:   finishReason,
+		Truncated:      finishReason == "MAX_TOKENS",
 	}, nil
 }
 
@@ -315,14 +735,15 @@ func (c *LLMClient) callOpenAI(ctx context.Context, req LLMRequest) (*LLMRespons
 
 	// Build request body
 	requestBody := map[string]interface{}{
-		"model": req.Model,
-		"messages": []map[string]string{
-			{"role": "user", "content": req.Prompt},
-		},
-		"temperature": req.Temperature,
-		"max_tokens":  req.MaxTokens,
+		"model":       req.Model,
+		"messages":    buildChatMessages(req),
+		"temperature": *req.Temperature,
+		"max_tokens":  *req.MaxTokens,
 	}
 
+	c.applySamplingParams(requestBody, req)
+	c.applyReasoningParams(requestBody, req)
+
 	// Marshal request body
 	jsonData, err := json.Marshal(requestBody)
 	if err != nil {
@@ -345,15 +766,16 @@ func (c *LLMClient) callOpenAI(ctx context.Context, req LLMRequest) (*LLMRespons
 	}
 	defer resp.Body.Close()
 
-	// Read response body
-	body, err := io.ReadAll(resp.Body)
+	// Read response body (very large bodies are kept on disk by the memory guard)
+	body, err := c.readResponseBody(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
+	defer body.Close()
 
 	// Check for HTTP errors
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, body.text())
 	}
 
 	// Parse response
@@ -362,15 +784,19 @@ func (c *LLMClient) callOpenAI(ctx context.Context, req LLMRequest) (*LLMRespons
 			Message struct {
 				Content string `json:"content"`
 			} `json:"message"`
+			FinishReason string `json:"finish_reason"`
 		} `json:"choices"`
 		Usage struct {
-			PromptTokens     int `json:"prompt_tokens"`
-			CompletionTokens int `json:"completion_tokens"`
-			TotalTokens      int `json:"total_tokens"`
+			PromptTokens            int `json:"prompt_tokens"`
+			CompletionTokens        int `json:"completion_tokens"`
+			TotalTokens             int `json:"total_tokens"`
+			CompletionTokensDetails struct {
+				ReasoningTokens int `json:"reasoning_tokens"`
+			} `json:"completion_tokens_details"`
 		} `json:"usage"`
 	}
 
-	if err := json.Unmarshal(body, &apiResp); err != nil {
+	if err := json.NewDecoder(body.Reader()).Decode(&apiResp); err != nil {
 		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
@@ -403,12 +829,18 @@ func (c *LLMClient) callOpenAI(ctx context.Context, req LLMRequest) (*LLMRespons
 
 	runtime.LogInfo(c.app.ctx, fmt.Sprintf("OpenAI response received: %d tokens, $%.6f", apiResp.Usage.TotalTokens, totalCost))
 
+	finishReason := apiResp.Choices[0].FinishReason
+
 	return &LLMResponse{
-		Content:    generatedText,
-		TokensUsed: apiResp.Usage.TotalTokens,
-		Cost:       totalCost,
-		Model:      req.Model,
-		Provider:   "openai",
+		Content:        generatedText,
+		TokensUsed:     apiResp.Usage.TotalTokens,
+		Cost:           totalCost,
+		Model:          req.Model,
+		Provider:       "openai",
+		OutputTokens:   apiResp.Usage.CompletionTokens,
+		ThinkingTokens: apiResp.Usage.CompletionTokensDetails.ReasoningTokens,
+		FinishReason:   finishReason,
+		Truncated:      finishReason == "length",
 	}, nil
 }
 
@@ -431,14 +863,15 @@ func (c *LLMClient) callAnthropic(ctx context.Context, req LLMRequest) (*LLMResp
 
 	// Build request body
 	requestBody := map[string]interface{}{
-		"model": req.Model,
-		"messages": []map[string]string{
-			{"role": "user", "content": req.Prompt},
-		},
-		"temperature": req.Temperature,
-		"max_tokens":  req.MaxTokens,
+		"model":       req.Model,
+		"messages":    buildChatMessages(req),
+		"temperature": *req.Temperature,
+		"max_tokens":  *req.MaxTokens,
 	}
 
+	c.applySamplingParams(requestBody, req)
+	c.applyReasoningParams(requestBody, req)
+
 	// Marshal request body
 	jsonData, err := json.Marshal(requestBody)
 	if err != nil {
@@ -462,29 +895,33 @@ func (c *LLMClient) callAnthropic(ctx context.Context, req LLMRequest) (*LLMResp
 	}
 	defer resp.Body.Close()
 
-	// Read response body
-	body, err := io.ReadAll(resp.Body)
+	// Read response body (very large bodies are kept on disk by the memory guard)
+	body, err := c.readResponseBody(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
+	defer body.Close()
 
 	// Check for HTTP errors
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, body.text())
 	}
 
 	// Parse response
 	var apiResp struct {
 		Content []struct {
-			Text string `json:"text"`
+			Type     string `json:"type"`
+			Text     string `json:"text"`
+			Thinking string `json:"thinking"`
 		} `json:"content"`
-		Usage struct {
+		StopReason string `json:"stop_reason"`
+		Usage      struct {
 			InputTokens  int `json:"input_tokens"`
 			OutputTokens int `json:"output_tokens"`
 		} `json:"usage"`
 	}
 
-	if err := json.Unmarshal(body, &apiResp); err != nil {
+	if err := json.NewDecoder(body.Reader()).Decode(&apiResp); err != nil {
 		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
@@ -493,24 +930,39 @@ func (c *LLMClient) callAnthropic(ctx context.Context, req LLMRequest) (*LLMResp
 		return nil, fmt.Errorf("no content in response")
 	}
 
-	generatedText := apiResp.Content[0].Text
+	// Concatenate text blocks; thinking blocks are counted but not returned as content
+	var textBuilder strings.Builder
+	thinkingChars := 0
+	for _, block := range apiResp.Content {
+		switch block.Type {
+		case "text":
+			textBuilder.WriteString(block.Text)
+		case "thinking":
+			thinkingChars += len(block.Thinking)
+		}
+	}
+	generatedText := textBuilder.String()
 
 	// Calculate cost (October 2025 pricing)
 	// Claude Sonnet 4.5: $3 per 1M input tokens, $15 per 1M output tokens
-	// This is the latest model as of September 29, 2025
-	inputCost := float64(apiResp.Usage.InputTokens) / 1_000_000.0 * 3.0
-	outputCost := float64(apiResp.Usage.OutputTokens) / 1_000_000.0 * 15.0
-	totalCost := inputCost + outputCost
+	// Claude Haiku 4.5: $1 per 1M input tokens, $5 per 1M output tokens
+	totalCost := c.app.EstimateCost("anthropic", req.Model, apiResp.Usage.InputTokens, apiResp.Usage.OutputTokens)
 	totalTokens := apiResp.Usage.InputTokens + apiResp.Usage.OutputTokens
 
 	runtime.LogInfo(c.app.ctx, fmt.Sprintf("Anthropic response received: %d tokens, $%.6f", totalTokens, totalCost))
 
 	return &LLMResponse{
-		Content:    generatedText,
-		TokensUsed: totalTokens,
-		Cost:       totalCost,
-		Model:      req.Model,
-		Provider:   "anthropic",
+		Content:      generatedText,
+		TokensUsed:   totalTokens,
+		Cost:         totalCost,
+		Model:        req.Model,
+		Provider:     "anthropic",
+		OutputTokens: apiResp.Usage.OutputTokens,
+		// Thinking tokens are included in output_tokens (and the cost); the API does not
+		// report them separately, so estimate them from the thinking text (~4 chars per token)
+		ThinkingTokens: thinkingChars / 4,
+		FinishReason:   apiResp.StopReason,
+		Truncated:      apiResp.StopReason == "max_tokens",
 	}, nil
 }
 
@@ -548,14 +1000,15 @@ func (c *LLMClient) callCustomOpenAICompatible(ctx context.Context, req LLMReque
 
 	// Build request body (OpenAI format)
 	requestBody := map[string]interface{}{
-		"model": req.Model,
-		"messages": []map[string]string{
-			{"role": "user", "content": req.Prompt},
-		},
-		"temperature": req.Temperature,
-		"max_tokens":  req.MaxTokens,
+		"model":       req.Model,
+		"messages":    buildChatMessages(req),
+		"temperature": *req.Temperature,
+		"max_tokens":  *req.MaxTokens,
 	}
 
+	c.applySamplingParams(requestBody, req)
+	c.applyReasoningParams(requestBody, req)
+
 	// Marshal request body
 	jsonData, err := json.Marshal(requestBody)
 	if err != nil {
@@ -582,15 +1035,16 @@ func (c *LLMClient) callCustomOpenAICompatible(ctx context.Context, req LLMReque
 	}
 	defer resp.Body.Close()
 
-	// Read response body
-	body, err := io.ReadAll(resp.Body)
+	// Read response body (very large bodies are kept on disk by the memory guard)
+	body, err := c.readResponseBody(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
+	defer body.Close()
 
 	// Check for HTTP errors
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, body.text())
 	}
 
 	// Parse response (OpenAI format)
@@ -599,15 +1053,19 @@ func (c *LLMClient) callCustomOpenAICompatible(ctx context.Context, req LLMReque
 			Message struct {
 				Content string `json:"content"`
 			} `json:"message"`
+			FinishReason string `json:"finish_reason"`
 		} `json:"choices"`
 		Usage struct {
-			PromptTokens     int `json:"prompt_tokens"`
-			CompletionTokens int `json:"completion_tokens"`
-			TotalTokens      int `json:"total_tokens"`
+			PromptTokens            int `json:"prompt_tokens"`
+			CompletionTokens        int `json:"completion_tokens"`
+			TotalTokens             int `json:"total_tokens"`
+			CompletionTokensDetails struct {
+				ReasoningTokens int `json:"reasoning_tokens"`
+			} `json:"completion_tokens_details"`
 		} `json:"usage"`
 	}
 
-	if err := json.Unmarshal(body, &apiResp); err != nil {
+	if err := json.NewDecoder(body.Reader()).Decode(&apiResp); err != nil {
 		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
@@ -628,11 +1086,17 @@ func (c *LLMClient) callCustomOpenAICompatible(ctx context.Context, req LLMReque
 
 	runtime.LogInfo(c.app.ctx, fmt.Sprintf("Custom API response received: %d tokens (cost not calculated for custom providers)", totalTokens))
 
+	finishReason := apiResp.Choices[0].FinishReason
+
 	return &LLMResponse{
-		Content:    generatedText,
-		TokensUsed: totalTokens,
-		Cost:       0.0, // Cost unknown for custom providers
-		Model:      req.Model,
-		Provider:   "custom",
+		Content:        generatedText,
+		TokensUsed:     totalTokens,
+		Cost:           0.0, // Cost unknown for custom providers
+		Model:          req.Model,
+		Provider:       "custom",
+		OutputTokens:   apiResp.Usage.CompletionTokens,
+		ThinkingTokens: apiResp.Usage.CompletionTokensDetails.ReasoningTokens,
+		FinishReason:   finishReason,
+		Truncated:      finishReason == "length",
 	}, nil
 }
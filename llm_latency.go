@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"net/http/httptrace"
+	"sort"
+	"time"
+)
+
+// --- LLM Call Latency ---
+//
+// Every LLM call measures how responsive its provider is, so providers and models can be
+// compared on speed and not only on price:
+//   - FirstByteMs: time from sending the request to the first byte of the response
+//   - LatencyMs: time until the whole response was read
+//   - TokensPerSecond: output tokens divided by the latency
+//
+// Calls are not streamed, so providers usually answer once generation is done and the first
+// byte comes close to the end. Rate limit waits and tool executions are not counted: a call
+// with continuations sums the latency of its requests, and an agent run sums that of its model
+// turns, both keeping the first byte of the first request. The metrics are returned on
+// LLMResponse, saved with the call's usage event, and aggregated by GetLatencyStats.
+
+// callTiming measures the HTTP requests of one LLM call
+type callTiming struct {
+	firstByte time.Duration // Time to the first byte of the first request
+	latency   time.Duration // Summed time of the requests
+}
+
+type callTimingContextKey struct{}
+
+// withCallTiming returns a context whose LLM requests are measured by timing (see postJSON)
+func withCallTiming(ctx context.Context, timing *callTiming) context.Context {
+	return context.WithValue(ctx, callTimingContextKey{}, timing)
+}
+
+// callTimingFromContext returns the timing of the LLM call a request belongs to (nil if none)
+func callTimingFromContext(ctx context.Context) *callTiming {
+	timing, _ := ctx.Value(callTimingContextKey{}).(*callTiming)
+	return timing
+}
+
+// start begins measuring one request
+//
+// Returns:
+//   - context.Context: Context to send the request with (traces its first response byte)
+//   - func(): Called once the response has been read
+func (t *callTiming) start(ctx context.Context) (context.Context, func()) {
+	begin := time.Now()
+	elapsed := t.latency
+	trace := &httptrace.ClientTrace{
+		GotFirstResponseByte: func() {
+			if t.firstByte == 0 {
+				t.firstByte = elapsed + time.Since(begin)
+			}
+		},
+	}
+	return httptrace.WithClientTrace(ctx, trace), func() {
+		t.latency += time.Since(begin)
+	}
+}
+
+// apply sets the latency metrics of a response
+// Output tokens are estimated from the content when the provider does not report them.
+func (t *callTiming) apply(a *App, resp *LLMResponse) {
+	if resp == nil {
+		return
+	}
+	if resp.OutputTokens == 0 && resp.Content != "" {
+		resp.OutputTokens = a.EstimateTokens(resp.Content)
+	}
+	resp.FirstByteMs = t.firstByte.Milliseconds()
+	resp.LatencyMs = t.latency.Milliseconds()
+	resp.TokensPerSecond = tokensPerSecond(resp.OutputTokens, resp.LatencyMs)
+}
+
+// tokensPerSecond returns the output throughput of a call (0 if it took no measurable time)
+func tokensPerSecond(outputTokens int, latencyMs int64) float64 {
+	if latencyMs <= 0 {
+		return 0
+	}
+	return float64(outputTokens) / (float64(latencyMs) / 1000)
+}
+
+// LatencyStats aggregates the latency of the LLM calls made with one provider/model
+type LatencyStats struct {
+	Provider               string  `json:"provider"`               // LLM provider
+	Model                  string  `json:"model"`                  // LLM model
+	Calls                  int     `json:"calls"`                  // Measured calls
+	AverageFirstByteMs     int64   `json:"averageFirstByteMs"`     // Average time to first byte
+	AverageLatencyMs       int64   `json:"averageLatencyMs"`       // Average total latency
+	MedianLatencyMs        int64   `json:"medianLatencyMs"`        // Median total latency
+	AverageTokensPerSecond float64 `json:"averageTokensPerSecond"` // Output tokens over the summed latency
+	CostPerCall            float64 `json:"costPerCall"`            // Average estimated cost in USD
+}
+
+// ============================================================================
+// Latency Methods (Wails-bound)
+// ============================================================================
+
+// GetLatencyStats compares the responsiveness of the providers and models used
+// Calls recorded before latency was measured are skipped. Like all usage data, latency is
+// only recorded while analytics are enabled.
+//
+// Parameters:
+//   - period: all (or empty), today, week, month, last_month, year, or a month as YYYY-MM
+//
+// Returns:
+//   - []LatencyStats: Stats per provider/model, fastest average latency first
+//   - error: Error if the period is unknown or the usage store cannot be read
+func (a *App) GetLatencyStats(period string) ([]LatencyStats, error) {
+	from, to, err := costPeriodRange(period, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	events, err := a.readUsageEvents()
+	if err != nil {
+		return nil, err
+	}
+
+	type modelLatency struct {
+		stats                   LatencyStats
+		firstByte, outputTokens int64
+		cost                    float64
+		latencies               []int64
+	}
+	byModel := make(map[string]*modelLatency)
+	for _, event := range events {
+		if event.Type != "llm_call" || event.LatencyMs <= 0 {
+			continue
+		}
+		if !from.IsZero() && (event.Timestamp.Before(from) || !event.Timestamp.Before(to)) {
+			continue
+		}
+		key := event.Provider + "/" + event.Model
+		m, ok := byModel[key]
+		if !ok {
+			m = &modelLatency{stats: LatencyStats{Provider: event.Provider, Model: event.Model}}
+			byModel[key] = m
+		}
+		m.stats.Calls++
+		m.firstByte += event.FirstByteMs
+		m.outputTokens += int64(event.OutputTokens)
+		m.cost += event.Cost
+		m.latencies = append(m.latencies, event.LatencyMs)
+	}
+
+	stats := make([]LatencyStats, 0, len(byModel))
+	for _, m := range byModel {
+		sort.Slice(m.latencies, func(i, j int) bool { return m.latencies[i] < m.latencies[j] })
+		var total int64
+		for _, latency := range m.latencies {
+			total += latency
+		}
+		calls := int64(m.stats.Calls)
+		m.stats.AverageFirstByteMs = m.firstByte / calls
+		m.stats.AverageLatencyMs = total / calls
+		m.stats.MedianLatencyMs = m.latencies[len(m.latencies)/2]
+		m.stats.AverageTokensPerSecond = tokensPerSecond(int(m.outputTokens), total)
+		m.stats.CostPerCall = m.cost / float64(m.stats.Calls)
+		stats = append(stats, m.stats)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].AverageLatencyMs != stats[j].AverageLatencyMs {
+			return stats[i].AverageLatencyMs < stats[j].AverageLatencyMs
+		}
+		return stats[i].Provider+"/"+stats[i].Model < stats[j].Provider+"/"+stats[j].Model
+	})
+	return stats, nil
+}
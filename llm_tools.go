@@ -0,0 +1,508 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+/**
+ * Tool Calling (Function Calling) for the LLM Client
+ *
+ * This module drives multi-step conversations where the model can call the tools
+ * from the ToolRegistry. Each provider has its own wire format for tool definitions,
+ * tool calls, and tool results, so every provider gets a dedicated loop:
+ *
+ * - openai / custom: "tools" + assistant "tool_calls" + "tool" role messages
+ * - anthropic: "tools" + "tool_use" content blocks + "tool_result" blocks
+ * - google: "functionDeclarations" + "functionCall" parts + "functionResponse" parts
+ *
+ * Events Emitted:
+ * - "toolAgentStep": After each tool call with the tool name, arguments, and outcome
+ */
+
+// ToolAgentStep describes one tool call made during an agent run
+type ToolAgentStep struct {
+	Step      int    `json:"step"`      // Model turn number (1-based)
+	Tool      string `json:"tool"`      // Tool name requested by the model
+	Arguments string `json:"arguments"` // JSON-encoded arguments
+	Error     string `json:"error"`     // Error message if the call failed
+}
+
+// CallLLMWithTools runs a tool-calling conversation until the model answers without tool calls
+//
+// Parameters:
+//   - ctx: Context for cancellation
+//   - req: LLM request (Prompt is the initial user message)
+//   - registry: Tool registry providing definitions, permissions, and execution
+//   - rootDir: Project root the tools are sandboxed to
+//   - maxSteps: Maximum number of model turns
+//
+// Returns:
+//   - *LLMResponse: Final model answer with tokens and cost summed over all turns
+//   - error: Error if a call fails or the step limit is reached
+func (c *LLMClient) CallLLMWithTools(ctx context.Context, req LLMRequest, registry *ToolRegistry, rootDir string, maxSteps int) (*LLMResponse, error) {
+	// Validate request
+	if req.Provider == "" {
+		return nil, fmt.Errorf("provider is required")
+	}
+	if req.APIKey == "" && req.Provider != "custom" {
+		return nil, fmt.Errorf("API key is required")
+	}
+	if req.Prompt == "" {
+		return nil, fmt.Errorf("prompt is required")
+	}
+	if err := validateSamplingParams(req); err != nil {
+		return nil, err
+	}
+
+	tools := registry.EnabledDefinitions()
+	if len(tools) == 0 {
+		runtime.LogInfo(c.app.ctx, "No tools enabled, falling back to a plain LLM call")
+		return c.CallLLM(ctx, req)
+	}
+
+	// Apply the same redaction, routing and defaults as CallLLM
+	secrets := c.app.redactPromptSecrets(&req)
+	route, err := c.routeAutoModel(&req)
+	if err != nil {
+		return nil, err
+	}
+	c.applyRequestDefaults(&req)
+
+	timing := &callTiming{}
+	ctx = withCallTiming(ctx, timing)
+	var resp *LLMResponse
+	switch req.Provider {
+	case "google":
+		resp, err = c.runGoogleToolLoop(ctx, req, tools, registry, rootDir, maxSteps)
+	case "openai":
+		resp, err = c.runOpenAIToolLoop(ctx, req, "https://api.openai.com/v1/chat/completions", tools, registry, rootDir, maxSteps)
+	case "anthropic":
+		resp, err = c.runAnthropicToolLoop(ctx, req, tools, registry, rootDir, maxSteps)
+	case "custom":
+		if req.BaseURL == "" {
+			return nil, fmt.Errorf("baseURL is required for custom provider")
+		}
+		resp, err = c.runOpenAIToolLoop(ctx, req, customChatCompletionsURL(req.BaseURL), tools, registry, rootDir, maxSteps)
+	default:
+		return nil, fmt.Errorf("unsupported provider: %s", req.Provider)
+	}
+	if resp != nil {
+		timing.apply(c.app, resp)
+		resp.Routing = route
+		resp.SecretsRedacted = secrets
+	}
+	return resp, err
+}
+
+// customChatCompletionsURL appends /v1/chat/completions to a custom base URL if needed
+func customChatCompletionsURL(baseURL string) string {
+	url := baseURL
+	if !strings.HasSuffix(url, "/chat/completions") && !strings.HasSuffix(url, "/v1/chat/completions") {
+		if !strings.HasSuffix(url, "/") {
+			url += "/"
+		}
+		url += "v1/chat/completions"
+	}
+	return url
+}
+
+// postJSON marshals body, POSTs it to url with the given headers, and returns the response body
+// The request waits for the provider's rate limit, counted with the estimated tokens of the body.
+// Non-200 responses are returned as errors including the response body
+func (c *LLMClient) postJSON(ctx context.Context, req LLMRequest, url string, headers map[string]string, body interface{}) (respBody []byte, err error) {
+	jsonData, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	slot, err := c.app.acquireRateLimit(ctx, req.Provider, len(jsonData)/4)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { c.app.recordLLMEgress(req, string(jsonData), err) }()
+	if timing := callTimingFromContext(ctx); timing != nil {
+		var done func()
+		ctx, done = timing.start(ctx)
+		defer done()
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		httpReq.Header.Set(key, value)
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err = io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(respBody))
+	}
+	c.app.settleRateLimit(req.Provider, slot, reportedTokens(respBody))
+	return respBody, nil
+}
+
+// reportedTokens returns the tokens a provider response reports as used (0 if it reports none)
+// OpenAI-compatible, Anthropic and Gemini usage fields are read.
+func reportedTokens(respBody []byte) int {
+	var usage struct {
+		Usage struct {
+			PromptTokens     int `json:"prompt_tokens"`
+			CompletionTokens int `json:"completion_tokens"`
+			InputTokens      int `json:"input_tokens"`
+			OutputTokens     int `json:"output_tokens"`
+		} `json:"usage"`
+		UsageMetadata struct {
+			PromptTokenCount     int `json:"promptTokenCount"`
+			CandidatesTokenCount int `json:"candidatesTokenCount"`
+		} `json:"usageMetadata"`
+	}
+	if err := json.Unmarshal(respBody, &usage); err != nil {
+		return 0
+	}
+	return usage.Usage.PromptTokens + usage.Usage.CompletionTokens +
+		usage.Usage.InputTokens + usage.Usage.OutputTokens +
+		usage.UsageMetadata.PromptTokenCount + usage.UsageMetadata.CandidatesTokenCount
+}
+
+// runAgentTool executes one tool call and returns the text to send back to the model
+// Tool errors are reported to the model as text so it can recover, not as loop failures
+func (c *LLMClient) runAgentTool(ctx context.Context, registry *ToolRegistry, rootDir string, step int, name string, args map[string]interface{}) string {
+	argsJSON, _ := json.Marshal(args)
+	stepInfo := ToolAgentStep{Step: step, Tool: name, Arguments: string(argsJSON)}
+
+	output, err := registry.Execute(ctx, rootDir, name, args)
+	if err != nil {
+		stepInfo.Error = err.Error()
+		output = "Error: " + err.Error()
+	}
+	// Tool output goes to the provider like the prompt (read_file gives the path of .env files)
+	output = c.app.redactSecretsIfEnabled(toolStringArg(args, "path", ""), output)
+
+	runtime.EventsEmit(c.app.ctx, "toolAgentStep", stepInfo)
+	return output
+}
+
+// decodeToolArguments parses a JSON-encoded argument string (OpenAI format)
+func decodeToolArguments(raw string) map[string]interface{} {
+	args := make(map[string]interface{})
+	if strings.TrimSpace(raw) == "" {
+		return args
+	}
+	if err := json.Unmarshal([]byte(raw), &args); err != nil {
+		return map[string]interface{}{"_raw": raw}
+	}
+	return args
+}
+
+// runOpenAIToolLoop runs the tool loop using the OpenAI chat completions format
+func (c *LLMClient) runOpenAIToolLoop(ctx context.Context, req LLMRequest, url string, tools []ToolDefinition, registry *ToolRegistry, rootDir string, maxSteps int) (*LLMResponse, error) {
+	runtime.LogInfo(c.app.ctx, fmt.Sprintf("Starting tool loop with %s model: %s (%d tools)", req.Provider, req.Model, len(tools)))
+
+	headers := map[string]string{}
+	if req.APIKey != "" {
+		headers["Authorization"] = "Bearer " + req.APIKey
+	}
+
+	openAITools := make([]map[string]interface{}, 0, len(tools))
+	for _, t := range tools {
+		openAITools = append(openAITools, map[string]interface{}{
+			"type": "function",
+			"function": map[string]interface{}{
+				"name":        t.Name,
+				"description": t.Description,
+				"parameters":  t.Parameters,
+			},
+		})
+	}
+
+	type openAIToolCall struct {
+		ID       string `json:"id"`
+		Type     string `json:"type"`
+		Function struct {
+			Name      string `json:"name"`
+			Arguments string `json:"arguments"`
+		} `json:"function"`
+	}
+
+	messages := []map[string]interface{}{
+		{"role": "user", "content": req.Prompt},
+	}
+	inputTokens, outputTokens := 0, 0
+
+	for step := 1; step <= maxSteps; step++ {
+		requestBody := map[string]interface{}{
+			"model":       req.Model,
+			"messages":    messages,
+			"tools":       openAITools,
+			"temperature": *req.Temperature,
+			"max_tokens":  *req.MaxTokens,
+		}
+
+		c.applySamplingParams(requestBody, req)
+		c.applyReasoningParams(requestBody, req)
+
+		body, err := c.postJSON(ctx, req, url, headers, requestBody)
+		if err != nil {
+			return nil, err
+		}
+
+		var apiResp struct {
+			Choices []struct {
+				Message struct {
+					Content   string           `json:"content"`
+					ToolCalls []openAIToolCall `json:"tool_calls"`
+				} `json:"message"`
+			} `json:"choices"`
+			Usage struct {
+				PromptTokens     int `json:"prompt_tokens"`
+				CompletionTokens int `json:"completion_tokens"`
+			} `json:"usage"`
+		}
+		if err := json.Unmarshal(body, &apiResp); err != nil {
+			return nil, fmt.Errorf("failed to parse response: %w", err)
+		}
+		if len(apiResp.Choices) == 0 {
+			return nil, fmt.Errorf("no content in response")
+		}
+
+		inputTokens += apiResp.Usage.PromptTokens
+		outputTokens += apiResp.Usage.CompletionTokens
+		message := apiResp.Choices[0].Message
+
+		if len(message.ToolCalls) == 0 {
+			return c.toolLoopResponse(req, message.Content, inputTokens, outputTokens), nil
+		}
+
+		messages = append(messages, map[string]interface{}{
+			"role":       "assistant",
+			"content":    message.Content,
+			"tool_calls": message.ToolCalls,
+		})
+		for _, call := range message.ToolCalls {
+			result := c.runAgentTool(ctx, registry, rootDir, step, call.Function.Name, decodeToolArguments(call.Function.Arguments))
+			messages = append(messages, map[string]interface{}{
+				"role":         "tool",
+				"tool_call_id": call.ID,
+				"content":      result,
+			})
+		}
+	}
+
+	return nil, fmt.Errorf("agent did not finish within %d steps", maxSteps)
+}
+
+// runAnthropicToolLoop runs the tool loop using the Anthropic messages format
+func (c *LLMClient) runAnthropicToolLoop(ctx context.Context, req LLMRequest, tools []ToolDefinition, registry *ToolRegistry, rootDir string, maxSteps int) (*LLMResponse, error) {
+	runtime.LogInfo(c.app.ctx, fmt.Sprintf("Starting tool loop with Anthropic model: %s (%d tools)", req.Model, len(tools)))
+
+	headers := map[string]string{
+		"x-api-key":         req.APIKey,
+		"anthropic-version": "2023-06-01",
+	}
+
+	anthropicTools := make([]map[string]interface{}, 0, len(tools))
+	for _, t := range tools {
+		anthropicTools = append(anthropicTools, map[string]interface{}{
+			"name":         t.Name,
+			"description":  t.Description,
+			"input_schema": t.Parameters,
+		})
+	}
+
+	messages := []map[string]interface{}{
+		{"role": "user", "content": req.Prompt},
+	}
+	inputTokens, outputTokens := 0, 0
+
+	for step := 1; step <= maxSteps; step++ {
+		requestBody := map[string]interface{}{
+			"model":       req.Model,
+			"messages":    messages,
+			"tools":       anthropicTools,
+			"temperature": *req.Temperature,
+			"max_tokens":  *req.MaxTokens,
+		}
+
+		c.applySamplingParams(requestBody, req)
+		c.applyReasoningParams(requestBody, req)
+
+		body, err := c.postJSON(ctx, req, "https://api.anthropic.com/v1/messages", headers, requestBody)
+		if err != nil {
+			return nil, err
+		}
+
+		var apiResp struct {
+			Content []map[string]interface{} `json:"content"`
+			Usage   struct {
+				InputTokens  int `json:"input_tokens"`
+				OutputTokens int `json:"output_tokens"`
+			} `json:"usage"`
+		}
+		if err := json.Unmarshal(body, &apiResp); err != nil {
+			return nil, fmt.Errorf("failed to parse response: %w", err)
+		}
+
+		inputTokens += apiResp.Usage.InputTokens
+		outputTokens += apiResp.Usage.OutputTokens
+
+		var text strings.Builder
+		var toolResults []map[string]interface{}
+		for _, block := range apiResp.Content {
+			switch block["type"] {
+			case "text":
+				if s, ok := block["text"].(string); ok {
+					text.WriteString(s)
+				}
+			case "tool_use":
+				name, _ := block["name"].(string)
+				id, _ := block["id"].(string)
+				args, _ := block["input"].(map[string]interface{})
+				result := c.runAgentTool(ctx, registry, rootDir, step, name, args)
+				toolResults = append(toolResults, map[string]interface{}{
+					"type":        "tool_result",
+					"tool_use_id": id,
+					"content":     result,
+				})
+			}
+		}
+
+		if len(toolResults) == 0 {
+			return c.toolLoopResponse(req, text.String(), inputTokens, outputTokens), nil
+		}
+
+		messages = append(messages,
+			map[string]interface{}{"role": "assistant", "content": apiResp.Content},
+			map[string]interface{}{"role": "user", "content": toolResults},
+		)
+	}
+
+	return nil, fmt.Errorf("agent did not finish within %d steps", maxSteps)
+}
+
+// runGoogleToolLoop runs the tool loop using the Gemini function calling format
+func (c *LLMClient) runGoogleToolLoop(ctx context.Context, req LLMRequest, tools []ToolDefinition, registry *ToolRegistry, rootDir string, maxSteps int) (*LLMResponse, error) {
+	runtime.LogInfo(c.app.ctx, fmt.Sprintf("Starting tool loop with Google AI model: %s (%d tools)", req.Model, len(tools)))
+
+	url := fmt.Sprintf("https://generativelanguage.googleapis.com/v1beta/models/%s:generateContent?key=%s", req.Model, req.APIKey)
+
+	declarations := make([]map[string]interface{}, 0, len(tools))
+	for _, t := range tools {
+		declarations = append(declarations, map[string]interface{}{
+			"name":        t.Name,
+			"description": t.Description,
+			"parameters":  t.Parameters,
+		})
+	}
+
+	contents := []map[string]interface{}{
+		{"role": "user", "parts": []map[string]interface{}{{"text": req.Prompt}}},
+	}
+	inputTokens, outputTokens := 0, 0
+
+	for step := 1; step <= maxSteps; step++ {
+		requestBody := map[string]interface{}{
+			"contents": contents,
+			"tools":    []map[string]interface{}{{"functionDeclarations": declarations}},
+			"generationConfig": map[string]interface{}{
+				"temperature":     *req.Temperature,
+				"maxOutputTokens": *req.MaxTokens,
+			},
+		}
+
+		c.applySamplingParams(requestBody, req)
+		c.applyReasoningParams(requestBody, req)
+
+		body, err := c.postJSON(ctx, req, url, nil, requestBody)
+		if err != nil {
+			return nil, err
+		}
+
+		var apiResp struct {
+			Candidates []struct {
+				Content struct {
+					Role  string                   `json:"role"`
+					Parts []map[string]interface{} `json:"parts"`
+				} `json:"content"`
+			} `json:"candidates"`
+			UsageMetadata struct {
+				PromptTokenCount     int `json:"promptTokenCount"`
+				CandidatesTokenCount int `json:"candidatesTokenCount"`
+			} `json:"usageMetadata"`
+		}
+		if err := json.Unmarshal(body, &apiResp); err != nil {
+			return nil, fmt.Errorf("failed to parse response: %w", err)
+		}
+		if len(apiResp.Candidates) == 0 {
+			return nil, fmt.Errorf("no content in response")
+		}
+
+		inputTokens += apiResp.UsageMetadata.PromptTokenCount
+		outputTokens += apiResp.UsageMetadata.CandidatesTokenCount
+		parts := apiResp.Candidates[0].Content.Parts
+
+		var text strings.Builder
+		var responses []map[string]interface{}
+		for _, part := range parts {
+			if s, ok := part["text"].(string); ok {
+				text.WriteString(s)
+			}
+			if call, ok := part["functionCall"].(map[string]interface{}); ok {
+				name, _ := call["name"].(string)
+				args, _ := call["args"].(map[string]interface{})
+				result := c.runAgentTool(ctx, registry, rootDir, step, name, args)
+				responses = append(responses, map[string]interface{}{
+					"functionResponse": map[string]interface{}{
+						"name":     name,
+						"response": map[string]interface{}{"content": result},
+					},
+				})
+			}
+		}
+
+		if len(responses) == 0 {
+			return c.toolLoopResponse(req, text.String(), inputTokens, outputTokens), nil
+		}
+
+		contents = append(contents,
+			map[string]interface{}{"role": "model", "parts": parts},
+			map[string]interface{}{"role": "user", "parts": responses},
+		)
+	}
+
+	return nil, fmt.Errorf("agent did not finish within %d steps", maxSteps)
+}
+
+// toolLoopResponse builds the final LLMResponse for a tool loop with summed usage
+func (c *LLMClient) toolLoopResponse(req LLMRequest, content string, inputTokens, outputTokens int) *LLMResponse {
+	totalCost := c.app.EstimateCost(req.Provider, req.Model, inputTokens, outputTokens)
+	totalTokens := inputTokens + outputTokens
+
+	runtime.LogInfo(c.app.ctx, fmt.Sprintf("Tool loop finished: %d tokens, $%.6f", totalTokens, totalCost))
+
+	return &LLMResponse{
+		Content:      content,
+		TokensUsed:   totalTokens,
+		Cost:         totalCost,
+		Model:        req.Model,
+		Provider:     req.Provider,
+		OutputTokens: outputTokens,
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/wailsapp/wails/v2/pkg/options"
 	"github.com/wailsapp/wails/v2/pkg/options/assetserver"
 	"github.com/wailsapp/wails/v2/pkg/options/linux"
+	"github.com/wailsapp/wails/v2/pkg/options/mac"
 	// Alias for Wails runtime package
 )
 
@@ -20,7 +21,16 @@ import (
 var assets embed.FS
 
 func main() {
+	// Installers register the file manager context menu and exit without opening a window
+	if handled, err := runContextMenuCommand(os.Args[1:]); handled {
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	app := NewApp() // Creates an instance of App from app.go
+	app.launchArgs = os.Args[1:]
 	// Load icons
 
 	iconPNG, errPNG := os.ReadFile("appicon.png") // Changed from ioutil.ReadFile
@@ -44,14 +54,28 @@ func main() {
 		},
 		BackgroundColour: &options.RGBA{R: 27, G: 38, B: 54, A: 1},
 		OnStartup:        app.startup,
+		OnDomReady:       app.domReady,
+		OnShutdown:       app.shutdown,
 		Bind: []interface{}{
 			app, // This binds all public methods of app
 		},
 		Menu: appMenu, // Set the application menu
+		DragAndDrop: &options.DragAndDrop{
+			EnableFileDrop: true, // Dropped folders are handled by App.handleFileDrop
+		},
+
+		// A second launch (CLI path or shotgun:// link) is forwarded to the running instance
+		SingleInstanceLock: &options.SingleInstanceLock{
+			UniqueId:               "8f1f6b0e-shotgun-code",
+			OnSecondInstanceLaunch: app.handleSecondInstance,
+		},
 
 		Linux: &linux.Options{
 			Icon: iconPNG,
 		},
+		Mac: &mac.Options{
+			OnUrlOpen: app.handleURLOpen, // shotgun:// links are not passed as arguments on macOS
+		},
 	})
 
 	if err != nil {
@@ -0,0 +1,254 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// --- In-Memory Filesystem ---
+//
+// memFS is a read-only fs.FS whose directory tree is held in memory. File contents are either
+// stored up front (tar archives, which are not seekable once compressed) or fetched on first
+// read by a loader (remote sources, whose listing is fetched in one go but contents per file).
+
+const memFSCacheLimit = 256 << 20 // Bytes of loaded file contents kept for later reads
+
+// memFSNode is a file or directory of a memFS; it is its own fs.FileInfo and fs.DirEntry
+type memFSNode struct {
+	name     string
+	data     []byte // Contents (nil until loaded for lazy files)
+	size     int64
+	lazy     bool // Contents come from the loader
+	mode     fs.FileMode
+	modTime  time.Time
+	children map[string]*memFSNode // nil for files
+}
+
+func (n *memFSNode) Name() string               { return n.name }
+func (n *memFSNode) Size() int64                { return n.size }
+func (n *memFSNode) Mode() fs.FileMode          { return n.mode }
+func (n *memFSNode) ModTime() time.Time         { return n.modTime }
+func (n *memFSNode) IsDir() bool                { return n.children != nil }
+func (n *memFSNode) Sys() interface{}           { return nil }
+func (n *memFSNode) Type() fs.FileMode          { return n.mode.Type() }
+func (n *memFSNode) Info() (fs.FileInfo, error) { return n, nil }
+
+// sortedChildren returns the entries of a directory node by name
+func (n *memFSNode) sortedChildren() []fs.DirEntry {
+	entries := make([]fs.DirEntry, 0, len(n.children))
+	for _, child := range n.children {
+		entries = append(entries, child)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries
+}
+
+// memFS is a read-only in-memory filesystem
+type memFS struct {
+	root   *memFSNode
+	size   int64                             // Total bytes of file data
+	load   func(name string) ([]byte, error) // Fetches lazy file contents (nil if none)
+	mu     sync.Mutex                        // Protects loaded contents and cached
+	cached int64                             // Bytes of loaded contents kept in nodes
+}
+
+// newMemFS returns an empty filesystem
+func newMemFS() *memFS {
+	return &memFS{root: &memFSNode{name: ".", mode: fs.ModeDir | 0555, children: map[string]*memFSNode{}}}
+}
+
+// newLazyMemFS returns an empty filesystem whose lazy files are read with load
+func newLazyMemFS(load func(name string) ([]byte, error)) *memFS {
+	m := newMemFS()
+	m.load = load
+	return m
+}
+
+// mkdirAll returns the directory node at name, creating missing directories
+func (m *memFS) mkdirAll(name string, modTime time.Time) *memFSNode {
+	dir := m.root
+	if name == "." || name == "" {
+		return dir
+	}
+	for _, part := range splitSlashPath(name) {
+		child, ok := dir.children[part]
+		if !ok || child.children == nil {
+			child = &memFSNode{name: part, mode: fs.ModeDir | 0555, modTime: modTime, children: map[string]*memFSNode{}}
+			dir.children[part] = child
+		}
+		dir = child
+	}
+	return dir
+}
+
+// addFile stores a file at name (a valid fs path), creating its parent directories
+func (m *memFS) addFile(name string, data []byte, modTime time.Time) {
+	dir := m.mkdirAll(path.Dir(name), modTime)
+	base := path.Base(name)
+	if existing, ok := dir.children[base]; ok && existing.children == nil {
+		m.size -= existing.Size()
+	}
+	dir.children[base] = &memFSNode{name: base, data: data, size: int64(len(data)), mode: 0444, modTime: modTime}
+	m.size += int64(len(data))
+}
+
+// addLazyFile records a file whose contents are fetched by the loader when first read
+func (m *memFS) addLazyFile(name string, size int64, modTime time.Time) {
+	dir := m.mkdirAll(path.Dir(name), modTime)
+	base := path.Base(name)
+	dir.children[base] = &memFSNode{name: base, size: size, lazy: true, mode: 0444, modTime: modTime}
+	m.size += size
+}
+
+// contents returns the data of a file node, loading lazy files
+func (m *memFS) contents(name string, node *memFSNode) ([]byte, error) {
+	if !node.lazy {
+		return node.data, nil
+	}
+	m.mu.Lock()
+	data := node.data
+	m.mu.Unlock()
+	if data != nil {
+		return data, nil
+	}
+	if m.load == nil {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrInvalid}
+	}
+	data, err := m.load(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: err}
+	}
+	m.mu.Lock()
+	if m.cached+int64(len(data)) <= memFSCacheLimit {
+		node.data = data
+		m.cached += int64(len(data))
+	}
+	m.mu.Unlock()
+	return data, nil
+}
+
+// lookup returns the node at name
+func (m *memFS) lookup(op, name string) (*memFSNode, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	node := m.root
+	if name == "." {
+		return node, nil
+	}
+	for _, part := range splitSlashPath(name) {
+		if node.children == nil {
+			return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+		}
+		child, ok := node.children[part]
+		if !ok {
+			return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+		}
+		node = child
+	}
+	return node, nil
+}
+
+// Open implements fs.FS
+func (m *memFS) Open(name string) (fs.File, error) {
+	node, err := m.lookup("open", name)
+	if err != nil {
+		return nil, err
+	}
+	if node.IsDir() {
+		return &memFSDir{node: node, entries: node.sortedChildren()}, nil
+	}
+	data, err := m.contents(name, node)
+	if err != nil {
+		return nil, err
+	}
+	return &memFSFile{node: node, reader: bytes.NewReader(data)}, nil
+}
+
+// Stat implements fs.StatFS without loading lazy files
+func (m *memFS) Stat(name string) (fs.FileInfo, error) {
+	node, err := m.lookup("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// ReadDir implements fs.ReadDirFS
+func (m *memFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	node, err := m.lookup("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	if !node.IsDir() {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+	return node.sortedChildren(), nil
+}
+
+// ReadFile implements fs.ReadFileFS
+func (m *memFS) ReadFile(name string) ([]byte, error) {
+	node, err := m.lookup("read", name)
+	if err != nil {
+		return nil, err
+	}
+	if node.IsDir() {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrInvalid}
+	}
+	data, err := m.contents(name, node)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(nil), data...), nil
+}
+
+// memFSFile is an open memFS file
+type memFSFile struct {
+	node   *memFSNode
+	reader *bytes.Reader
+}
+
+func (f *memFSFile) Stat() (fs.FileInfo, error) { return f.node, nil }
+func (f *memFSFile) Read(p []byte) (int, error) { return f.reader.Read(p) }
+func (f *memFSFile) Close() error               { return nil }
+
+// memFSDir is an open memFS directory
+type memFSDir struct {
+	node    *memFSNode
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *memFSDir) Stat() (fs.FileInfo, error) { return d.node, nil }
+func (d *memFSDir) Close() error               { return nil }
+func (d *memFSDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.node.name, Err: fs.ErrInvalid}
+}
+
+// ReadDir implements fs.ReadDirFile
+func (d *memFSDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	remaining := d.entries[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return remaining, nil
+	}
+	if len(remaining) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(remaining) {
+		n = len(remaining)
+	}
+	d.offset += n
+	return remaining[:n], nil
+}
+
+// splitSlashPath splits a slash-separated fs path into its elements
+func splitSlashPath(name string) []string {
+	return strings.Split(name, "/")
+}
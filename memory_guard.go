@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	goruntime "runtime"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- Memory Guard ---
+//
+// Generation output and LLM response bodies are accumulated in spillBuffers. A spillBuffer
+// keeps its data in memory until it crosses the spill threshold, or until the process heap
+// crosses the heap limit (the watchdog), and from then on stores it in a temp file. This keeps
+// the Wails process from being OOM-killed on low-RAM or 32-bit-constrained machines.
+
+const (
+	defaultSpillThresholdMB = 256  // Buffers larger than this move to disk
+	defaultHeapLimitMB      = 1024 // Above this heap size, buffers move to disk early
+	spillMinBytes           = 1 << 20
+	spillCheckInterval      = 1 << 20 // Bytes written between heap checks
+)
+
+// spillBuffer is an append-only buffer that moves to a temp file when memory is tight
+type spillBuffer struct {
+	thresholdBytes int64 // Spill when the buffer grows past this size (0 = never)
+	heapLimitBytes uint64
+	mem            bytes.Buffer
+	file           *os.File
+	size           int64
+	sinceCheck     int64
+}
+
+// newSpillBuffer returns a buffer using the memory guard settings
+func (a *App) newSpillBuffer() *spillBuffer {
+	return &spillBuffer{
+		thresholdBytes: int64(a.settings.SpillThresholdMB) << 20,
+		heapLimitBytes: uint64(a.settings.HeapLimitMB) << 20,
+	}
+}
+
+// Write appends p, spilling to disk first if the buffer or the heap is too large
+func (b *spillBuffer) Write(p []byte) (int, error) {
+	if b.file == nil && b.shouldSpill(len(p)) {
+		if err := b.spill(); err != nil {
+			return 0, err
+		}
+	}
+	b.size += int64(len(p))
+	if b.file != nil {
+		return b.file.Write(p)
+	}
+	return b.mem.Write(p)
+}
+
+// WriteString appends s
+func (b *spillBuffer) WriteString(s string) (int, error) {
+	return b.Write([]byte(s))
+}
+
+// Len returns the number of bytes written
+func (b *spillBuffer) Len() int {
+	return int(b.size)
+}
+
+// Spilled reports whether the data is stored on disk
+func (b *spillBuffer) Spilled() bool {
+	return b.file != nil
+}
+
+// shouldSpill reports whether writing n more bytes should move the buffer to disk
+func (b *spillBuffer) shouldSpill(n int) bool {
+	next := b.size + int64(n)
+	if b.thresholdBytes > 0 && next > b.thresholdBytes {
+		return true
+	}
+	if b.heapLimitBytes == 0 || next < spillMinBytes {
+		return false
+	}
+	// Reading memory stats briefly stops the world, so the heap is checked once per interval
+	b.sinceCheck += int64(n)
+	if b.sinceCheck < spillCheckInterval {
+		return false
+	}
+	b.sinceCheck = 0
+	var stats goruntime.MemStats
+	goruntime.ReadMemStats(&stats)
+	return stats.HeapAlloc > b.heapLimitBytes
+}
+
+// spill moves the in-memory data to a temp file
+func (b *spillBuffer) spill() error {
+	f, err := os.CreateTemp("", "shotgun-spill-*")
+	if err != nil {
+		return fmt.Errorf("failed to create spill file: %w", err)
+	}
+	if _, err := f.Write(b.mem.Bytes()); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return fmt.Errorf("failed to write spill file: %w", err)
+	}
+	b.file = f
+	b.mem = bytes.Buffer{} // Release the memory
+	return nil
+}
+
+// Reader returns a reader over the buffered data
+func (b *spillBuffer) Reader() io.Reader {
+	if b.file != nil {
+		return io.NewSectionReader(b.file, 0, b.size)
+	}
+	return bytes.NewReader(b.mem.Bytes())
+}
+
+// String returns the buffered data, reading it back from disk if it was spilled
+func (b *spillBuffer) String() (string, error) {
+	if b.file == nil {
+		return b.mem.String(), nil
+	}
+	var sb strings.Builder
+	sb.Grow(int(b.size))
+	if _, err := io.Copy(&sb, b.Reader()); err != nil {
+		return "", fmt.Errorf("failed to read spill file: %w", err)
+	}
+	return sb.String(), nil
+}
+
+// text returns the buffered data for messages, or an empty string if it cannot be read back
+func (b *spillBuffer) text() string {
+	s, _ := b.String()
+	return s
+}
+
+// Close releases the buffer and deletes its temp file
+func (b *spillBuffer) Close() error {
+	b.mem = bytes.Buffer{}
+	if b.file == nil {
+		return nil
+	}
+	name := b.file.Name()
+	b.file.Close()
+	b.file = nil
+	return os.Remove(name)
+}
+
+// ============================================================================
+// Memory Guard Methods (Wails-bound)
+// ============================================================================
+
+// GetMemoryGuard returns the memory guard settings in megabytes
+func (a *App) GetMemoryGuard() map[string]int {
+	return map[string]int{
+		"spillThresholdMB": a.settings.SpillThresholdMB,
+		"heapLimitMB":      a.settings.HeapLimitMB,
+	}
+}
+
+// SetMemoryGuard updates and saves the memory guard settings
+//
+// Parameters:
+//   - spillThresholdMB: Size above which generation output and LLM responses move to disk (0 = never)
+//   - heapLimitMB: Heap size above which they move to disk early (0 = no watchdog)
+//
+// Returns:
+//   - error: Error if the settings cannot be saved
+func (a *App) SetMemoryGuard(spillThresholdMB, heapLimitMB int) error {
+	if spillThresholdMB < 0 {
+		spillThresholdMB = 0
+	}
+	if heapLimitMB < 0 {
+		heapLimitMB = 0
+	}
+	a.settings.SpillThresholdMB = spillThresholdMB
+	a.settings.HeapLimitMB = heapLimitMB
+	if err := a.saveSettings(); err != nil {
+		return fmt.Errorf("failed to save memory guard settings: %w", err)
+	}
+	runtime.LogInfof(a.ctx, "Memory guard set to: spill above %d MB, heap limit %d MB", spillThresholdMB, heapLimitMB)
+	return nil
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- Per-Mode Model Defaults ---
+//
+// Each prompt mode (dev, architect, debug, tasks, or any custom mode name) can carry a default
+// provider, model, temperature and max tokens, so switching intent switches models too: an
+// LLM request that names its mode gets the mode's values for every field it leaves unset.
+// Explicit request values always win. The mode's model only applies when the request uses
+// the mode's provider (or the mode has none), since model names are provider-specific.
+// CallLLMAPIForMode is the binding the execution screen sends with: it leaves the temperature
+// and max tokens unset unless the user changed them. CallLLMAPI names no mode.
+
+// ModeModelDefaults are the LLM defaults of one prompt mode
+type ModeModelDefaults struct {
+	Provider    string   `json:"provider,omitempty"`    // Provider used when the request has none
+	Model       string   `json:"model,omitempty"`       // Model used when the request has none
+	Temperature *float64 `json:"temperature,omitempty"` // Temperature used when the request has none
+	MaxTokens   *int     `json:"maxTokens,omitempty"`   // Max tokens used when the request has none
+}
+
+// applyModeDefaults fills the unset fields of a request from the defaults of its mode
+func (a *App) applyModeDefaults(req *LLMRequest) {
+	defaults, ok := a.settings.ModeDefaults[req.Mode]
+	if req.Mode == "" || !ok {
+		return
+	}
+	if req.Provider == "" {
+		req.Provider = defaults.Provider
+	}
+	if req.Model == "" && (defaults.Provider == "" || defaults.Provider == req.Provider) {
+		req.Model = defaults.Model
+	}
+	if req.Temperature == nil && defaults.Temperature != nil {
+		req.Temperature = float64Ptr(*defaults.Temperature)
+	}
+	if (req.MaxTokens == nil || *req.MaxTokens <= 0) && defaults.MaxTokens != nil {
+		req.MaxTokens = intPtr(*defaults.MaxTokens)
+	}
+}
+
+// ============================================================================
+// Mode Defaults Methods (Wails-bound)
+// ============================================================================
+
+// GetModeDefaults returns the LLM defaults of every prompt mode that has some
+func (a *App) GetModeDefaults() map[string]ModeModelDefaults {
+	if a.settings.ModeDefaults == nil {
+		return map[string]ModeModelDefaults{}
+	}
+	return a.settings.ModeDefaults
+}
+
+// SetModeDefaults sets the LLM defaults of a prompt mode and saves them
+// Requests naming the mode (LLMRequest.Mode) use them for the fields they leave unset.
+//
+// Parameters:
+//   - mode: Prompt mode (dev, architect, debug, tasks, or a custom mode name)
+//   - defaults: Provider, model, temperature and max tokens (empty values clear the mode's defaults)
+//
+// Returns:
+//   - error: Error if a value is invalid or the settings cannot be saved
+func (a *App) SetModeDefaults(mode string, defaults ModeModelDefaults) error {
+	mode = strings.TrimSpace(mode)
+	if mode == "" {
+		return fmt.Errorf("mode is required")
+	}
+	switch defaults.Provider {
+	case "", "google", "openai", "anthropic", "custom":
+	default:
+		return fmt.Errorf("unknown provider: %s", defaults.Provider)
+	}
+	if defaults.Temperature != nil && (*defaults.Temperature < 0 || *defaults.Temperature > 2) {
+		return fmt.Errorf("temperature must be between 0 and 2, got %v", *defaults.Temperature)
+	}
+	if defaults.MaxTokens != nil && *defaults.MaxTokens <= 0 {
+		defaults.MaxTokens = nil
+	}
+
+	if a.settings.ModeDefaults == nil {
+		a.settings.ModeDefaults = make(map[string]ModeModelDefaults)
+	}
+	if defaults == (ModeModelDefaults{}) {
+		delete(a.settings.ModeDefaults, mode)
+	} else {
+		a.settings.ModeDefaults[mode] = defaults
+	}
+	if err := a.saveSettings(); err != nil {
+		return fmt.Errorf("failed to save mode defaults setting: %w", err)
+	}
+	runtime.LogInfof(a.ctx, "Mode defaults for %s: provider %q, model %q", mode, defaults.Provider, defaults.Model)
+	return nil
+}
+
+// CallLLMAPIForMode calls an LLM API for a prompt mode as a background job
+// Values left unset take the defaults of the mode (SetModeDefaults); values the user set win.
+//
+// Parameters:
+//   - mode: Prompt mode (dev, architect, debug, tasks, or a custom mode name)
+//   - provider: LLM provider (empty for the mode's)
+//   - apiKey: API key for the provider
+//   - prompt: The prompt to send to the LLM
+//   - model: Model name (empty for the mode's, or the provider's default)
+//   - temperature: Temperature set by the user (null for the mode's)
+//   - maxTokens: Maximum tokens set by the user (0 for the mode's)
+//
+// Returns:
+//   - string: Job ID for tracking the LLM call
+//   - error: Error if job creation fails
+func (a *App) CallLLMAPIForMode(mode, provider, apiKey, prompt, model string, temperature *float64, maxTokens int) (string, error) {
+	req := LLMRequest{
+		Mode:        mode,
+		Provider:    provider,
+		APIKey:      apiKey,
+		Prompt:      prompt,
+		Model:       model,
+		Temperature: temperature,
+	}
+	if maxTokens > 0 {
+		req.MaxTokens = &maxTokens
+	}
+	return a.CallLLMAPIWithRequest(req)
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- Automatic Model Routing ---
+//
+// A request whose model is "auto" gets the cheapest model of its provider whose context window
+// holds the composed prompt plus room for the answer (MaxTokens, or the model's default output
+// limit): small prompts go to flash, nano or haiku-class models, large ones move up to a model
+// with a larger window. The routing decision and its reason are returned in LLMResponse.Routing
+// and logged. Routing uses the estimated prompt tokens (see EstimateTokens), so a prompt close
+// to a window's edge may be routed to a model it slightly overflows. The custom provider has
+// no model table and cannot route.
+
+const autoModel = "auto"
+
+// routableModel is a model automatic routing can pick
+type routableModel struct {
+	name          string
+	contextWindow int // Input and output tokens
+}
+
+// routableModels lists the models of each provider, cheapest first (see EstimateCost)
+var routableModels = map[string][]routableModel{
+	"google": {
+		{"gemini-2.5-flash", 1_048_576},
+		{"gemini-2.5-pro", 2_097_152},
+	},
+	"openai": {
+		{"gpt-5-nano", 400_000},
+		{"gpt-5-mini", 400_000},
+		{"gpt-5", 400_000},
+	},
+	"anthropic": {
+		{"claude-haiku-4-5", 200_000},
+		{"claude-sonnet-4-5-20250929", 200_000},
+	},
+}
+
+// ModelRoute is the model automatic routing picked for a request, and why
+type ModelRoute struct {
+	Model         string  `json:"model"`         // Model picked
+	PromptTokens  int     `json:"promptTokens"`  // Estimated tokens of the prompt
+	OutputTokens  int     `json:"outputTokens"`  // Tokens reserved for the answer
+	ContextWindow int     `json:"contextWindow"` // Context window of the model
+	InputCost     float64 `json:"inputCost"`     // Estimated cost of the prompt with this model, in USD
+	Reason        string  `json:"reason"`        // Why the model was picked
+}
+
+// routeModel picks the cheapest model of a provider that fits a prompt
+//
+// Parameters:
+//   - provider: Provider name (google, openai, anthropic)
+//   - promptTokens: Estimated tokens of the prompt
+//   - maxTokens: Output tokens requested (0 for each model's default output limit)
+//
+// Returns:
+//   - ModelRoute: The model picked and the reason
+//   - error: Error if the provider cannot route or no model fits the prompt
+func (a *App) routeModel(provider string, promptTokens, maxTokens int) (ModelRoute, error) {
+	models, ok := routableModels[provider]
+	if !ok {
+		return ModelRoute{}, fmt.Errorf("automatic model routing is not available for provider %s", provider)
+	}
+	var skipped []string
+	for _, model := range models {
+		output := maxTokens
+		if output <= 0 {
+			output = defaultMaxTokensForModel(model.name)
+		}
+		if promptTokens+output > model.contextWindow {
+			skipped = append(skipped, fmt.Sprintf("%s (%d-token window)", model.name, model.contextWindow))
+			continue
+		}
+		route := ModelRoute{
+			Model:         model.name,
+			PromptTokens:  promptTokens,
+			OutputTokens:  output,
+			ContextWindow: model.contextWindow,
+			InputCost:     a.EstimateCost(provider, model.name, promptTokens, 0),
+		}
+		route.Reason = fmt.Sprintf("cheapest %s model whose %d-token window fits ~%d prompt tokens plus %d output tokens",
+			provider, model.contextWindow, promptTokens, output)
+		if len(skipped) > 0 {
+			route.Reason += "; too small: " + strings.Join(skipped, ", ")
+		}
+		return route, nil
+	}
+	largest := models[len(models)-1]
+	return ModelRoute{}, fmt.Errorf("prompt of ~%d tokens does not fit any %s model (largest window: %d tokens for %s)",
+		promptTokens, provider, largest.contextWindow, largest.name)
+}
+
+// routeAutoModel replaces the "auto" model of a request by the routed model
+//
+// Returns:
+//   - *ModelRoute: The routing decision (nil if the request names a model)
+//   - error: Error if the request cannot be routed
+func (c *LLMClient) routeAutoModel(req *LLMRequest) (*ModelRoute, error) {
+	if !strings.EqualFold(strings.TrimSpace(req.Model), autoModel) {
+		return nil, nil
+	}
+	maxTokens := 0
+	if req.MaxTokens != nil {
+		maxTokens = *req.MaxTokens
+	}
+	route, err := c.app.routeModel(req.Provider, c.app.EstimateTokens(req.Prompt+req.AssistantPrefix), maxTokens)
+	if err != nil {
+		return nil, err
+	}
+	req.Model = route.Model
+	runtime.LogInfof(c.app.ctx, "Routed %s request to %s: %s", req.Provider, route.Model, route.Reason)
+	return &route, nil
+}
+
+// ============================================================================
+// Model Routing Methods (Wails-bound)
+// ============================================================================
+
+// PreviewModelRoute returns the model an "auto" request would be routed to
+//
+// Parameters:
+//   - provider: Provider name (google, openai, anthropic)
+//   - prompt: Composed prompt
+//   - maxTokens: Output tokens requested (0 for each model's default output limit)
+//
+// Returns:
+//   - ModelRoute: The model that would be picked and why
+//   - error: Error if the provider cannot route or no model fits the prompt
+func (a *App) PreviewModelRoute(provider, prompt string, maxTokens int) (ModelRoute, error) {
+	return a.routeModel(provider, a.EstimateTokens(prompt), maxTokens)
+}
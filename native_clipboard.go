@@ -0,0 +1,51 @@
+package main
+
+import (
+	goruntime "runtime"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- Native Windows Clipboard ---
+//
+// On native Windows, copies go straight to the Win32 clipboard API (OpenClipboard,
+// SetClipboardData) instead of through PowerShell or the Wails and browser tiers, which are
+// slow or fail for multi-megabyte contexts. The whole text is converted to UTF-16 at once and
+// stored as CF_UNICODETEXT, NUL-terminated and with CRLF line endings as Windows apps expect;
+// there is no chunking and no command line length limit. It is the first clipboard tier on
+// native Windows, as WSLClipboardSetText is inside WSL; when it reports an error, the Wails
+// and browser clipboards remain as fallbacks.
+
+// toCRLF converts lone LF line endings to CRLF
+func toCRLF(text string) string {
+	if !strings.Contains(text, "\n") {
+		return text
+	}
+	return strings.ReplaceAll(strings.ReplaceAll(text, "\r\n", "\n"), "\n", "\r\n")
+}
+
+// ============================================================================
+// Native Clipboard Methods (Wails-bound)
+// ============================================================================
+
+// NativeClipboardAvailable reports whether NativeClipboardSetText can be used (native Windows)
+func (a *App) NativeClipboardAvailable() bool {
+	return goruntime.GOOS == "windows"
+}
+
+// NativeClipboardSetText copies text to the clipboard through the Win32 clipboard API
+//
+// Parameters:
+//   - text: Text to copy (any size; converted to UTF-16 with CRLF line endings)
+//
+// Returns:
+//   - error: Error if not on Windows or the clipboard cannot be written
+func (a *App) NativeClipboardSetText(text string) error {
+	if err := setNativeClipboardText(text); err != nil {
+		runtime.LogWarningf(a.ctx, "Native clipboard copy failed: %v", err)
+		return err
+	}
+	runtime.LogInfof(a.ctx, "Copied %d characters to the Windows clipboard", len(text))
+	return nil
+}
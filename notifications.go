@@ -0,0 +1,171 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	goruntime "runtime"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- Desktop Notifications ---
+//
+// Native notifications for long-running work finishing while the user is elsewhere.
+// Clicking a notification brings the window to the front:
+//   - Linux: notify-send with a default action (libnotify 0.7.9+)
+//   - Windows: a toast that opens shotgun://focus, handled by the single-instance forwarder
+//   - macOS: terminal-notifier opening shotgun://focus when installed, otherwise a plain
+//     AppleScript notification (clicking it does not focus the app)
+
+// Notification types, each of which can be turned off
+const (
+	notifyContextGenerated = "context_generated" // Context generation finished or failed
+	notifyLLMResponse      = "llm_response"      // An LLM response was received
+	notifyPatchApplied     = "patch_applied"     // A patch was applied to the project
+	notifyTests            = "tests"             // A test run passed or failed
+)
+
+// notificationTypes lists the notification types in display order
+var notificationTypes = []string{notifyContextGenerated, notifyLLMResponse, notifyPatchApplied, notifyTests}
+
+// notificationFocusURL is opened when a Windows or macOS notification is clicked
+const notificationFocusURL = deepLinkScheme + "://focus"
+
+// windowsToastAppID is the registered app ID toasts are shown under (PowerShell's)
+const windowsToastAppID = `{1AC14E77-02E7-4E5D-B744-2EB1AE5198B7}\WindowsPowerShell\v1.0\powershell.exe`
+
+// NotificationOptions controls which desktop notifications are shown
+type NotificationOptions struct {
+	Enabled bool            `json:"enabled"` // Master switch
+	Types   map[string]bool `json:"types"`   // Per-type switch (context_generated, llm_response, patch_applied, tests)
+}
+
+// defaultNotificationOptions returns the notification settings used when none are saved
+func defaultNotificationOptions() NotificationOptions {
+	opts := NotificationOptions{Enabled: true, Types: make(map[string]bool)}
+	for _, kind := range notificationTypes {
+		opts.Types[kind] = true
+	}
+	return opts
+}
+
+// notify shows a desktop notification of the given type, unless it is turned off
+// The notification is sent in the background; failures are only logged.
+func (a *App) notify(kind, title, body string) {
+	opts := a.settings.Notifications
+	if !opts.Enabled || !opts.Types[kind] {
+		return
+	}
+	go func() {
+		if err := a.sendNotification(title, body); err != nil {
+			runtime.LogWarningf(a.ctx, "Failed to show %s notification: %v", kind, err)
+		}
+	}()
+}
+
+// sendNotification shows a native notification and waits for it where the OS reports clicks
+func (a *App) sendNotification(title, body string) error {
+	switch goruntime.GOOS {
+	case "linux":
+		// With an action, notify-send waits and prints the action's name when the notification is clicked
+		out, err := exec.Command("notify-send", "--app-name=Shotgun Code", "--action=default=Open", title, body).Output()
+		if err != nil {
+			// Older notify-send without --action
+			return exec.Command("notify-send", "--app-name=Shotgun Code", title, body).Run()
+		}
+		if strings.TrimSpace(string(out)) == "default" {
+			a.focusWindow()
+		}
+		return nil
+
+	case "windows":
+		toast := fmt.Sprintf(`<toast activationType="protocol" launch="%s"><visual><binding template="ToastGeneric"><text>%s</text><text>%s</text></binding></visual></toast>`,
+			notificationFocusURL, xmlEscape(title), xmlEscape(body))
+		script := strings.Join([]string{
+			`[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] > $null`,
+			`[Windows.Data.Xml.Dom.XmlDocument, Windows.Data.Xml.Dom.XmlDocument, ContentType = WindowsRuntime] > $null`,
+			`$xml = New-Object Windows.Data.Xml.Dom.XmlDocument`,
+			`$xml.LoadXml(` + powershellQuote(toast) + `)`,
+			`[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier(` + powershellQuote(windowsToastAppID) + `).Show([Windows.UI.Notifications.ToastNotification]::new($xml))`,
+		}, "; ")
+		return exec.Command("powershell", "-NoProfile", "-NonInteractive", "-WindowStyle", "Hidden", "-Command", script).Run()
+
+	case "darwin":
+		if notifier, err := exec.LookPath("terminal-notifier"); err == nil {
+			return exec.Command(notifier, "-title", title, "-message", body, "-group", "shotgun-code", "-open", notificationFocusURL).Run()
+		}
+		script := fmt.Sprintf(`display notification %s with title %s`, appleScriptQuote(body), appleScriptQuote(title))
+		return exec.Command("osascript", "-e", script).Run()
+	}
+	return fmt.Errorf("notifications are not supported on %s", goruntime.GOOS)
+}
+
+// xmlEscape escapes text for XML content and attributes
+func xmlEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;", "'", "&apos;").Replace(s)
+}
+
+// powershellQuote quotes a string as a PowerShell single-quoted literal
+func powershellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// appleScriptQuote quotes a string as an AppleScript string literal
+func appleScriptQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// projectLabel returns the folder name of a project for notification texts
+func projectLabel(rootDir string) string {
+	if rootDir == "" {
+		return "project"
+	}
+	return filepath.Base(rootDir)
+}
+
+// ============================================================================
+// Notification Methods (Wails-bound)
+// ============================================================================
+
+// GetNotificationOptions returns the desktop notification settings
+func (a *App) GetNotificationOptions() NotificationOptions {
+	return a.settings.Notifications
+}
+
+// SetNotificationOptions updates and saves the desktop notification settings
+//
+// Parameters:
+//   - opts: Master switch and per-type switches (unknown types are rejected, missing ones turned off)
+//
+// Returns:
+//   - error: Error if a type is unknown or the settings cannot be saved
+func (a *App) SetNotificationOptions(opts NotificationOptions) error {
+	types := make(map[string]bool, len(notificationTypes))
+	for _, kind := range notificationTypes {
+		types[kind] = false
+	}
+	for kind, enabled := range opts.Types {
+		if _, ok := types[kind]; !ok {
+			return fmt.Errorf("unknown notification type: %s", kind)
+		}
+		types[kind] = enabled
+	}
+	opts.Types = types
+
+	a.settings.Notifications = opts
+	if err := a.saveSettings(); err != nil {
+		return fmt.Errorf("failed to save notification options: %w", err)
+	}
+	runtime.LogInfof(a.ctx, "Notification options updated: enabled=%v, types=%v", opts.Enabled, opts.Types)
+	return nil
+}
+
+// TestNotification shows a sample notification, ignoring the settings
+//
+// Returns:
+//   - error: Error if the OS notification could not be shown
+func (a *App) TestNotification() error {
+	return a.sendNotification("Shotgun Code", "Notifications are working.")
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- Offline Mode ---
+//
+// With the OfflineMode setting on, nothing leaves the machine: every HTTP client of the backend
+// (LLM providers, provider health checks, gist and paste uploads, template bundle fetches) sends
+// its requests through outboundTransport, which refuses any host that is not local. Only
+// loopback addresses and localhost names are allowed, so a custom provider served on the
+// machine (Ollama, llama.cpp, LM Studio at http://localhost:11434) keeps working. The check is
+// made on the request URL and again on the dialed address, proxies from the environment are
+// bypassed, and names other than localhost are never resolved, so not even a DNS query goes
+// out. Opening remote projects over SSH is refused too, and so is the run_tests tool, whose
+// test commands can reach the network on their own. New HTTP clients must use
+// outboundTransport.
+
+// errOfflineMode is wrapped by the error of every request refused in offline mode
+var errOfflineMode = errors.New("offline mode is on")
+
+// offlineMode mirrors the OfflineMode setting for the transport, which has no App
+var offlineMode atomic.Bool
+
+// outboundTransport is the transport of every HTTP client of the backend
+var outboundTransport http.RoundTripper = newOutboundTransport()
+
+// offlineGuard refuses requests to non-local hosts while offline mode is on
+type offlineGuard struct {
+	base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (g offlineGuard) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := checkOutboundHost(req.URL.Hostname()); err != nil {
+		return nil, err
+	}
+	return g.base.RoundTrip(req)
+}
+
+// newOutboundTransport returns the default transport with the offline checks
+func newOutboundTransport() http.RoundTripper {
+	base := http.DefaultTransport.(*http.Transport).Clone()
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	base.Proxy = func(req *http.Request) (*url.URL, error) {
+		if offlineMode.Load() {
+			return nil, nil // Local requests go straight to the local server
+		}
+		return http.ProxyFromEnvironment(req)
+	}
+	base.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			host = addr
+		}
+		if err := checkOutboundHost(host); err != nil {
+			return nil, err
+		}
+		return dialer.DialContext(ctx, network, addr)
+	}
+	return offlineGuard{base: base}
+}
+
+// isLocalHost reports whether a host name or address stays on the machine
+func isLocalHost(host string) bool {
+	host = strings.TrimSuffix(strings.ToLower(strings.Trim(host, "[]")), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// checkOutboundHost returns an error wrapping errOfflineMode if a host may not be contacted
+func checkOutboundHost(host string) error {
+	if offlineMode.Load() && !isLocalHost(host) {
+		return fmt.Errorf("%w: %s is not a local address", errOfflineMode, host)
+	}
+	return nil
+}
+
+// checkOnline returns an error wrapping errOfflineMode if offline mode forbids an action
+func checkOnline(action string) error {
+	if offlineMode.Load() {
+		return fmt.Errorf("%s is disabled: %w", action, errOfflineMode)
+	}
+	return nil
+}
+
+// ============================================================================
+// Offline Mode Methods (Wails-bound)
+// ============================================================================
+
+// GetOfflineMode returns whether offline mode is on
+func (a *App) GetOfflineMode() bool {
+	return a.settings.OfflineMode
+}
+
+// SetOfflineMode turns offline mode on or off and saves the setting
+// Emits "offlineModeChanged" so every view can disable its network actions.
+func (a *App) SetOfflineMode(enabled bool) error {
+	a.settings.OfflineMode = enabled
+	offlineMode.Store(enabled)
+	if err := a.saveSettings(); err != nil {
+		return fmt.Errorf("failed to save offline mode setting: %w", err)
+	}
+	runtime.LogInfof(a.ctx, "Offline mode: %v", enabled)
+	runtime.EventsEmit(a.ctx, "offlineModeChanged", enabled)
+	return nil
+}
@@ -0,0 +1,320 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- Operation Undo Stack ---
+//
+// Every backend change to project files (ApplyPatch, ApplyFiles, the apply_patch tool) runs as
+// an operation through runOperation: the files it may touch are backed up before and after
+// the change (see file_backup.go), and a failed change is rolled back. Operations form an
+// undo stack per project. Undo restores the "before" backups, Redo the "after" backups, and
+// both refuse to run if the files were edited since, so a revert never clobbers other work.
+// A new operation discards the operations that were undone (no branching history).
+
+const maxOperations = maxFileBackups / 2 // Operations kept; each one holds two backups
+
+// Kinds of operations
+const (
+	operationApplyPatch = "apply_patch" // Unified diff applied with git apply
+	operationApplyFiles = "apply_files" // Whole-file response applied
+)
+
+// diffPathRegex finds the paths named in the headers of a unified diff
+var diffPathRegex = regexp.MustCompile(`(?m)^(?:--- |\+\+\+ |rename from |rename to |copy from |copy to )(.+?)\s*$`)
+
+// Operation is one undoable change to project files
+type Operation struct {
+	ID        string    `json:"id"`        // Operation ID
+	RootDir   string    `json:"rootDir"`   // Project root
+	Kind      string    `json:"kind"`      // apply_patch or apply_files
+	Label     string    `json:"label"`     // Description shown in the history
+	Files     []string  `json:"files"`     // Files the operation may have changed
+	CreatedAt time.Time `json:"createdAt"` // When the operation ran
+	Undone    bool      `json:"undone"`    // True if the operation was undone (can be redone)
+	before    *FileBackup
+	after     *FileBackup
+}
+
+// runOperation applies a change to project files as an undoable operation
+// The files are backed up before apply runs; if apply fails, they are restored.
+//
+// Parameters:
+//   - rootDir: Project root
+//   - kind: Kind of operation
+//   - label: Description shown in the history
+//   - relPaths: Files apply may create, change or delete
+//   - apply: The change
+//
+// Returns:
+//   - *Operation: The recorded operation
+//   - error: Error if the backup fails or apply fails
+func (a *App) runOperation(rootDir, kind, label string, relPaths []string, apply func() error) (*Operation, error) {
+	if err := a.checkWritable(label); err != nil {
+		return nil, err
+	}
+	if !a.projectFS.OnDisk(rootDir) {
+		return nil, mountedPathError(rootDir)
+	}
+	before, err := a.createBackup(rootDir, label, relPaths)
+	if err != nil {
+		return nil, fmt.Errorf("failed to back up files: %w", err)
+	}
+	if err := apply(); err != nil {
+		if restoreErr := a.restoreBackup(before); restoreErr != nil {
+			return nil, fmt.Errorf("%w (rolling back also failed: %v)", err, restoreErr)
+		}
+		return nil, err
+	}
+	after, err := a.createBackup(rootDir, label+" (after)", relPaths)
+	if err != nil {
+		return nil, fmt.Errorf("change applied, but it cannot be undone: %w", err)
+	}
+
+	op := &Operation{
+		ID:        before.ID,
+		RootDir:   rootDir,
+		Kind:      kind,
+		Label:     label,
+		Files:     relPaths,
+		CreatedAt: before.CreatedAt,
+		before:    before,
+		after:     after,
+	}
+	a.operationsMu.Lock()
+	// A new change makes the undone operations of the project unreachable
+	kept := a.operations[:0]
+	for _, o := range a.operations {
+		if !(o.RootDir == rootDir && o.Undone) {
+			kept = append(kept, o)
+		}
+	}
+	a.operations = append(kept, op)
+	if len(a.operations) > maxOperations {
+		a.operations = a.operations[len(a.operations)-maxOperations:]
+	}
+	a.operationsMu.Unlock()
+
+	runtime.EventsEmit(a.ctx, "operationsChanged", rootDir)
+	return op, nil
+}
+
+// modifiedSince returns the first file whose current content differs from a backup
+// Files in skip are not compared; the files compared are added to it.
+func (a *App) modifiedSince(backup *FileBackup, skip map[string]bool) string {
+	for _, file := range backup.Files {
+		if skip[file.RelPath] {
+			continue
+		}
+		skip[file.RelPath] = true
+		absPath, err := resolveProjectPath(backup.RootDir, file.RelPath)
+		if err != nil {
+			return file.RelPath
+		}
+		current, err := os.ReadFile(absPath)
+		if !file.Existed {
+			if err == nil {
+				return file.RelPath
+			}
+			continue
+		}
+		saved, savedErr := os.ReadFile(filepath.Join(a.backupsDir(), backup.ID, "files", filepath.FromSlash(file.RelPath)))
+		if err != nil || savedErr != nil || !bytes.Equal(current, saved) {
+			return file.RelPath
+		}
+	}
+	return ""
+}
+
+// diffPaths returns the project paths named in a unified diff
+func diffPaths(patch string) []string {
+	paths := []string{}
+	seen := make(map[string]bool)
+	for _, m := range diffPathRegex.FindAllStringSubmatch(patch, -1) {
+		path := strings.Trim(m[1], `"`)
+		if i := strings.IndexByte(path, '\t'); i >= 0 {
+			path = path[:i] // Timestamp after the path
+		}
+		if path == "/dev/null" {
+			continue
+		}
+		if strings.HasPrefix(m[0], "--- ") || strings.HasPrefix(m[0], "+++ ") {
+			path = strings.TrimPrefix(strings.TrimPrefix(path, "a/"), "b/")
+		}
+		if !seen[path] {
+			seen[path] = true
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// applyPatchOperation applies a unified diff as an undoable operation
+func (a *App) applyPatchOperation(ctx context.Context, rootDir, patch, label string) (*Operation, error) {
+	paths := diffPaths(patch)
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no file paths found in patch")
+	}
+	return a.runOperation(rootDir, operationApplyPatch, label, paths, func() error {
+		return applyUnifiedDiff(ctx, rootDir, patch)
+	})
+}
+
+// ============================================================================
+// Operation Methods (Wails-bound)
+// ============================================================================
+
+// ApplyPatch applies a unified diff to a project as an undoable operation
+//
+// Parameters:
+//   - rootDir: Project root
+//   - patch: Unified diff (post-processing settings are applied first)
+//
+// Returns:
+//   - Operation: The recorded operation (see Undo)
+//   - error: Error if the patch names no files or git apply fails
+func (a *App) ApplyPatch(rootDir, patch string) (Operation, error) {
+	if err := a.validateContentRoot(rootDir); err != nil {
+		return Operation{}, err
+	}
+	patch = a.postProcess(patch)
+	if strings.TrimSpace(patch) == "" {
+		return Operation{}, fmt.Errorf("patch is required")
+	}
+	op, err := a.applyPatchOperation(a.ctx, rootDir, patch, "Apply patch")
+	if err != nil {
+		return Operation{}, err
+	}
+	runtime.LogInfof(a.ctx, "Applied patch to %s: %d files (operation %s)", rootDir, len(op.Files), op.ID)
+	a.forgetAppliedDiff(patch)
+	a.notify(notifyPatchApplied, "Patch applied", projectLabel(rootDir))
+	return *op, nil
+}
+
+// ListOperations returns the operations of a project, newest first
+// Undone operations are included (with Undone set) until a new operation replaces them.
+//
+// Parameters:
+//   - rootDir: Project root (empty for all projects)
+//
+// Returns:
+//   - []Operation: Recorded operations
+func (a *App) ListOperations(rootDir string) []Operation {
+	a.operationsMu.Lock()
+	defer a.operationsMu.Unlock()
+	ops := []Operation{}
+	for i := len(a.operations) - 1; i >= 0; i-- {
+		if rootDir == "" || a.operations[i].RootDir == rootDir {
+			ops = append(ops, *a.operations[i])
+		}
+	}
+	return ops
+}
+
+// Undo reverts an operation and every later operation of the same project
+//
+// Parameters:
+//   - opID: ID of the operation (empty for the most recent one)
+//
+// Returns:
+//   - []string: IDs of the operations undone, newest first
+//   - error: Error if the operation is unknown or already undone, or a file was edited since
+func (a *App) Undo(opID string) ([]string, error) {
+	if err := a.checkWritable("undo"); err != nil {
+		return nil, err
+	}
+	a.operationsMu.Lock()
+	defer a.operationsMu.Unlock()
+
+	target := -1
+	for i := len(a.operations) - 1; i >= 0; i-- {
+		if a.operations[i].Undone {
+			continue
+		}
+		if opID == "" || a.operations[i].ID == opID {
+			target = i
+			break
+		}
+	}
+	if target < 0 {
+		if opID == "" {
+			return nil, fmt.Errorf("nothing to undo")
+		}
+		return nil, fmt.Errorf("operation not found or already undone: %s", opID)
+	}
+
+	rootDir := a.operations[target].RootDir
+	var ops []*Operation
+	for i := len(a.operations) - 1; i >= target; i-- {
+		if op := a.operations[i]; op.RootDir == rootDir && !op.Undone {
+			ops = append(ops, op)
+		}
+	}
+	// Each file must still match the newest operation that changed it
+	checked := make(map[string]bool)
+	for _, op := range ops {
+		if file := a.modifiedSince(op.after, checked); file != "" {
+			return nil, fmt.Errorf("cannot undo %q: %s was modified since", op.Label, file)
+		}
+	}
+
+	undone := []string{}
+	for _, op := range ops {
+		if err := a.restoreBackup(op.before); err != nil {
+			return undone, fmt.Errorf("failed to undo %q: %w", op.Label, err)
+		}
+		op.Undone = true
+		undone = append(undone, op.ID)
+	}
+	runtime.LogInfof(a.ctx, "Undid %d operations in %s", len(undone), rootDir)
+	runtime.EventsEmit(a.ctx, "operationsChanged", rootDir)
+	return undone, nil
+}
+
+// Redo re-applies the most recently undone operation of a project
+//
+// Parameters:
+//   - rootDir: Project root (empty for any project)
+//
+// Returns:
+//   - Operation: The operation redone
+//   - error: Error if there is nothing to redo or a file was edited since the undo
+func (a *App) Redo(rootDir string) (Operation, error) {
+	if err := a.checkWritable("redo"); err != nil {
+		return Operation{}, err
+	}
+	a.operationsMu.Lock()
+	defer a.operationsMu.Unlock()
+
+	// The undone operation that comes first is the last one undone
+	var op *Operation
+	for _, o := range a.operations {
+		if o.Undone && (rootDir == "" || o.RootDir == rootDir) {
+			op = o
+			break
+		}
+	}
+	if op == nil {
+		return Operation{}, fmt.Errorf("nothing to redo")
+	}
+	if file := a.modifiedSince(op.before, map[string]bool{}); file != "" {
+		return Operation{}, fmt.Errorf("cannot redo %q: %s was modified since", op.Label, file)
+	}
+	if err := a.restoreBackup(op.after); err != nil {
+		return Operation{}, fmt.Errorf("failed to redo %q: %w", op.Label, err)
+	}
+	op.Undone = false
+	runtime.LogInfof(a.ctx, "Redid operation %s (%s)", op.ID, op.Label)
+	runtime.EventsEmit(a.ctx, "operationsChanged", op.RootDir)
+	return *op, nil
+}
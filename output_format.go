@@ -0,0 +1,151 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- Context Output Format ---
+//
+// Generated context uses pseudo-XML file blocks by default. With the markdown output format,
+// the tree goes in a text fence and every block becomes a heading and a fenced code block with
+// a language hint, which chat UIs render well:
+//
+//	## `src/main.go`
+//
+//	```go
+//	...content...
+//	```
+//
+// Blocks are generated as XML and converted one by one, after content filters and duplicate
+// detection, so those work the same in both formats. Snapshot diffs recognize both formats;
+// splitting at file boundaries recognizes XML blocks only and splits Markdown context at
+// paragraphs.
+
+const (
+	outputFormatXML      = "xml"      // <file path="..."> blocks (default)
+	outputFormatMarkdown = "markdown" // Headings and fenced code blocks
+)
+
+var (
+	xmlFileBlockRegex      = regexp.MustCompile(`(?s)^<file path="([^"]*)"([^>]*)>\n(.*)\n</file>\n$`)
+	xmlDuplicateBlockRegex = regexp.MustCompile(`^<file path="([^"]*)" duplicate-of="([^"]*)" />\n$`)
+	xmlDirectoryBlockRegex = regexp.MustCompile(`(?s)^<directory path="([^"]*)"[^>]*>\n(.*)\n</directory>\n$`)
+	xmlAttrRegex           = regexp.MustCompile(`([\w-]+)="([^"]*)"`)
+	backtickRunRegex       = regexp.MustCompile("`{3,}")
+)
+
+// fenceLanguageOverrides maps language names whose fence hint is not the lowercased name
+var fenceLanguageOverrides = map[string]string{
+	"C++": "cpp", "C#": "csharp", "Shell": "bash", "Objective-C": "objectivec",
+	"Protocol Buffers": "protobuf", "reStructuredText": "rst", "Go Module": "", "Other": "",
+}
+
+// outputFormat returns the format of generated context (xml or markdown)
+func (a *App) outputFormat() string {
+	if a.settings.OutputFormat == outputFormatMarkdown {
+		return outputFormatMarkdown
+	}
+	return outputFormatXML
+}
+
+// fenceLanguage returns the code fence language hint of a file ("" if unknown)
+func fenceLanguage(relPath string) string {
+	return languageFenceHint(detectLanguage(relPath))
+}
+
+// languageFenceHint returns the code fence language hint of a language name ("" if unknown)
+func languageFenceHint(lang string) string {
+	if hint, ok := fenceLanguageOverrides[lang]; ok {
+		return hint
+	}
+	return strings.ToLower(strings.ReplaceAll(lang, " ", ""))
+}
+
+// markdownFence wraps content in a code fence longer than any backtick run it contains
+// A final newline of content is dropped so the fence closes right after the last line.
+func markdownFence(content, lang string) string {
+	fence := "```"
+	for _, run := range backtickRunRegex.FindAllString(content, -1) {
+		if len(run) >= len(fence) {
+			fence = strings.Repeat("`", len(run)+1)
+		}
+	}
+	return fence + lang + "\n" + strings.TrimSuffix(content, "\n") + "\n" + fence + "\n"
+}
+
+// markdownBlock converts a generated XML block to Markdown
+// Placeholders (HTML comments) are valid Markdown and are kept as they are.
+func markdownBlock(block string) string {
+	if m := xmlDuplicateBlockRegex.FindStringSubmatch(block); m != nil {
+		return fmt.Sprintf("## `%s`\n\nSame content as `%s`.\n\n", fileBlockPath(m[1]), fileBlockPath(m[2]))
+	}
+	if m := xmlDirectoryBlockRegex.FindStringSubmatch(block); m != nil {
+		return fmt.Sprintf("## `%s/` (summary)\n\n%s\n\n", fileBlockPath(m[1]), m[2])
+	}
+	m := xmlFileBlockRegex.FindStringSubmatch(block)
+	if m == nil {
+		return block
+	}
+	relPath := fileBlockPath(m[1])
+	var b strings.Builder
+	fmt.Fprintf(&b, "## `%s`\n\n", relPath)
+	var parts []string
+	for _, attr := range xmlAttrRegex.FindAllStringSubmatch(m[2], -1) {
+		if attr[1] != "language" { // Shown as the fence language
+			parts = append(parts, attr[1]+": "+fileBlockPath(attr[2]))
+		}
+	}
+	if len(parts) > 0 {
+		b.WriteString("_" + strings.Join(parts, ", ") + "_\n\n")
+	}
+	content := fileBlockContent(m[3])
+	b.WriteString(markdownFence(content, languageFenceHint(detectContentLanguage(relPath, []byte(content[:min(len(content), languageSniffBytes)])))))
+	b.WriteString("\n")
+	return b.String()
+}
+
+// formatBlock converts a generated block to the output format
+func (a *App) formatBlock(block string) string {
+	if a.outputFormat() == outputFormatMarkdown {
+		return markdownBlock(block)
+	}
+	return block
+}
+
+// markdownEnvironment converts the environment block to a Markdown section
+func markdownEnvironment(block string) string {
+	inner := strings.TrimSuffix(strings.TrimPrefix(block, "<environment>\n"), "</environment>")
+	return "## Environment\n\n" + markdownFence(inner, "text")
+}
+
+// ============================================================================
+// Output Format Methods (Wails-bound)
+// ============================================================================
+
+// GetOutputFormat returns the format of generated context (xml or markdown)
+func (a *App) GetOutputFormat() string {
+	return a.outputFormat()
+}
+
+// SetOutputFormat sets the format of generated context and saves the setting
+//
+// Parameters:
+//   - format: xml (<file> blocks) or markdown (headings and fenced code blocks)
+//
+// Returns:
+//   - error: Error if the format is unknown or the setting cannot be saved
+func (a *App) SetOutputFormat(format string) error {
+	if format != outputFormatXML && format != outputFormatMarkdown {
+		return fmt.Errorf("unknown output format: %s", format)
+	}
+	a.settings.OutputFormat = format
+	if err := a.saveSettings(); err != nil {
+		return fmt.Errorf("failed to save output format setting: %w", err)
+	}
+	runtime.LogInfof(a.ctx, "Output format: %s", format)
+	return nil
+}
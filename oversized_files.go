@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- Oversized File Exclusion ---
+//
+// A single huge file (a generated bundle, a data dump, a lock file) can make generated context
+// too large to paste anywhere. With the MaxFileBlockBytes setting on, every file whose rendered
+// block (content with line numbers, stripping, filters and escaping applied) would exceed that
+// many bytes is left out and replaced by a placeholder:
+//
+//	<!-- File omitted (oversized, 812 KB): dist/bundle.js -->
+//
+// The files left out are collected into the "excluded oversized files" section of the
+// generation report, emitted as "oversizedFilesExcluded" after the context is generated, so
+// nothing disappears silently. Placeholders count as blocks, so resumed generations keep them.
+
+// OversizedFile is a file left out of generated context because its block was too large
+type OversizedFile struct {
+	Path       string `json:"path"`       // Path relative to the project root (forward slashes)
+	Size       int64  `json:"size"`       // Size of the file on disk in bytes
+	BlockBytes int    `json:"blockBytes"` // Size its rendered block would have had
+}
+
+// OversizedFilesReport is the "excluded oversized files" section of a generation report
+type OversizedFilesReport struct {
+	RootDir           string          `json:"rootDir"`           // Project root directory
+	MaxFileBlockBytes int             `json:"maxFileBlockBytes"` // Limit the blocks exceeded
+	Files             []OversizedFile `json:"files"`             // Files left out, in generation order
+	SkippedTokens     int             `json:"skippedTokens"`     // Estimated tokens of their blocks
+}
+
+// oversizedFileRun leaves out oversized blocks during one generation and collects them
+type oversizedFileRun struct {
+	report    OversizedFilesReport
+	projectFS ProjectFS
+}
+
+// newOversizedFileRun returns the oversized file state of a generation (nil without a limit)
+func (a *App) newOversizedFileRun(rootDir string) *oversizedFileRun {
+	if a.settings.MaxFileBlockBytes <= 0 {
+		return nil
+	}
+	return &oversizedFileRun{report: OversizedFilesReport{
+		RootDir:           rootDir,
+		MaxFileBlockBytes: a.settings.MaxFileBlockBytes,
+		Files:             []OversizedFile{},
+	}, projectFS: a.projectFS}
+}
+
+// apply returns the block of a file, or a placeholder if the block exceeds the limit
+//
+// Parameters:
+//   - path: Absolute path of the file
+//   - relPath: Path relative to the project root
+//   - block: Rendered block of the file, before output formatting
+//
+// Returns:
+//   - string: The block, or a placeholder naming the file and its size
+func (r *oversizedFileRun) apply(path, relPath, block string) string {
+	if r == nil || len(block) <= r.report.MaxFileBlockBytes {
+		return block
+	}
+	file := OversizedFile{Path: filepath.ToSlash(relPath), BlockBytes: len(block)}
+	if info, err := r.projectFS.Stat(path); err == nil {
+		file.Size = info.Size()
+	}
+	r.report.Files = append(r.report.Files, file)
+	r.report.SkippedTokens += len(block) / 4 // EstimateTokens counts 4 bytes per token
+	return fmt.Sprintf("<!-- File omitted (oversized, %d KB): %s -->\n", (len(block)+1023)/1024, file.Path)
+}
+
+// reportIfApplied returns the report of the generation (nil if no file was left out)
+func (r *oversizedFileRun) reportIfApplied() *OversizedFilesReport {
+	if r == nil || len(r.report.Files) == 0 {
+		return nil
+	}
+	return &r.report
+}
+
+// ============================================================================
+// Oversized File Methods (Wails-bound)
+// ============================================================================
+
+// GetMaxFileBlockBytes returns the size above which file blocks are left out of generated context (0 = no limit)
+func (a *App) GetMaxFileBlockBytes() int {
+	return a.settings.MaxFileBlockBytes
+}
+
+// SetMaxFileBlockBytes sets the size above which file blocks are left out of generated context and saves it
+//
+// Parameters:
+//   - maxBytes: Largest rendered block of one file, in bytes (0 = no limit)
+//
+// Returns:
+//   - error: Error if the size is negative or the setting cannot be saved
+func (a *App) SetMaxFileBlockBytes(maxBytes int) error {
+	if maxBytes < 0 {
+		return fmt.Errorf("maximum file block size must not be negative, got %d", maxBytes)
+	}
+	a.settings.MaxFileBlockBytes = maxBytes
+	if err := a.saveSettings(); err != nil {
+		return fmt.Errorf("failed to save maximum file block size setting: %w", err)
+	}
+	runtime.LogInfof(a.ctx, "Maximum file block size: %d bytes", maxBytes)
+	return nil
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	gitignore "github.com/sabhiram/go-gitignore"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- Patch Statistics and Risk ---
+//
+// PreviewPatch describes a unified diff before it is applied: added and removed lines per
+// file, which files are created, deleted or renamed, which touch critical paths, whether the
+// patch applies cleanly (git apply --check), and a simple risk score. Critical paths are
+// gitignore-style patterns (the CriticalPathPatterns setting; e.g. "migrations/" matches
+// every migrations directory, "/go.mod" only the root one).
+//
+// The risk score (0-100) adds up:
+//   - size: 1 point per 10 changed lines, at most 30
+//   - spread: 2 points per file after the first, at most 20
+//   - deleted files: 10 points each, at most 20
+//   - critical files: 25 points each, at most 50
+// Below 25 the risk is low, below 60 medium, and high from there.
+
+// defaultCriticalPathPatterns are the critical paths until the user changes them
+var defaultCriticalPathPatterns = []string{
+	"migrations/", "auth/", "security/", ".github/workflows/", "*.sql",
+	"Dockerfile", "go.mod", "package.json", ".env*", "*.pem", "*.key",
+}
+
+const (
+	patchRiskMedium = 25 // Scores from here are medium risk
+	patchRiskHigh   = 60 // Scores from here are high risk
+)
+
+// PatchFileStat is what a patch changes in one file
+type PatchFileStat struct {
+	Path            string `json:"path"`                      // Path after the change (before it, for deleted files)
+	OldPath         string `json:"oldPath,omitempty"`         // Path before a rename
+	Status          string `json:"status"`                    // modified, added, deleted or renamed
+	Added           int    `json:"added"`                     // Lines added
+	Removed         int    `json:"removed"`                   // Lines removed
+	Binary          bool   `json:"binary,omitempty"`          // True for binary changes (no line counts)
+	CriticalPattern string `json:"criticalPattern,omitempty"` // Critical path pattern the file matches (empty if none)
+}
+
+// PatchPreview describes a patch before it is applied
+type PatchPreview struct {
+	RootDir       string          `json:"rootDir"`              // Project root
+	Files         []PatchFileStat `json:"files"`                // Changed files, in patch order
+	Added         int             `json:"added"`                // Lines added in all files
+	Removed       int             `json:"removed"`              // Lines removed in all files
+	CriticalFiles []string        `json:"criticalFiles"`        // Files matching a critical path pattern
+	RiskScore     int             `json:"riskScore"`            // Risk score (0-100)
+	RiskLevel     string          `json:"riskLevel"`            // low, medium or high
+	RiskReasons   []string        `json:"riskReasons"`          // What the score is made of
+	Applies       bool            `json:"applies"`              // True if git apply --check succeeds
+	ApplyError    string          `json:"applyError,omitempty"` // Why the patch does not apply
+}
+
+// diffHeaderPath returns the project path of a ---/+++ header value ("" for /dev/null)
+func diffHeaderPath(value string) string {
+	value = strings.Trim(value, `"`)
+	if i := strings.IndexByte(value, '\t'); i >= 0 {
+		value = value[:i] // Timestamp after the path
+	}
+	value = strings.TrimSpace(value)
+	if value == "/dev/null" {
+		return ""
+	}
+	return strings.TrimPrefix(strings.TrimPrefix(value, "a/"), "b/")
+}
+
+// parsePatchStats counts the changes of every file in a unified diff
+// Hunk line counts are not trusted (models get them wrong): inside a hunk, a "--- " line
+// followed by a "+++ " line starts the next file.
+func parsePatchStats(patch string) []PatchFileStat {
+	files := []PatchFileStat{}
+	lines := strings.Split(strings.ReplaceAll(patch, "\r\n", "\n"), "\n")
+	var current *PatchFileStat
+	inHunk, sawHeader := false, false
+	start := func() {
+		files = append(files, PatchFileStat{Status: "modified"})
+		current = &files[len(files)-1]
+		inHunk, sawHeader = false, false
+	}
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			start()
+			if fields := strings.Fields(line); len(fields) == 4 {
+				current.Path = diffHeaderPath(fields[3])
+			}
+		case strings.HasPrefix(line, "--- ") && i+1 < len(lines) && strings.HasPrefix(lines[i+1], "+++ "):
+			if current == nil || inHunk || sawHeader {
+				start()
+			}
+			sawHeader = true
+			oldPath, newPath := diffHeaderPath(line[4:]), diffHeaderPath(lines[i+1][4:])
+			switch {
+			case oldPath == "" && newPath != "":
+				current.Status, current.Path = "added", newPath
+			case newPath == "" && oldPath != "":
+				current.Status, current.Path = "deleted", oldPath
+			case newPath != "":
+				current.Path = newPath
+			}
+		case current == nil:
+		case strings.HasPrefix(line, "@@"):
+			inHunk = true
+		case inHunk && strings.HasPrefix(line, "+"):
+			current.Added++
+		case inHunk && strings.HasPrefix(line, "-"):
+			current.Removed++
+		case inHunk:
+		case strings.HasPrefix(line, "new file mode"):
+			current.Status = "added"
+		case strings.HasPrefix(line, "deleted file mode"):
+			current.Status = "deleted"
+		case strings.HasPrefix(line, "rename from "):
+			current.Status, current.OldPath = "renamed", strings.TrimPrefix(line, "rename from ")
+		case strings.HasPrefix(line, "rename to "):
+			current.Path = strings.TrimPrefix(line, "rename to ")
+		case strings.HasPrefix(line, "Binary files ") || line == "GIT binary patch":
+			current.Binary = true
+		}
+	}
+	// Drop blocks that never named a file
+	kept := files[:0]
+	for _, f := range files {
+		if f.Path != "" {
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
+
+// scorePatchRisk fills in the risk score, level and reasons of a preview
+func scorePatchRisk(preview *PatchPreview) {
+	preview.RiskReasons = []string{}
+	add := func(points, limit int, reason string) {
+		if points = min(points, limit); points > 0 {
+			preview.RiskScore += points
+			preview.RiskReasons = append(preview.RiskReasons, fmt.Sprintf("%s (+%d)", reason, points))
+		}
+	}
+	deleted := 0
+	for _, f := range preview.Files {
+		if f.Status == "deleted" {
+			deleted++
+		}
+	}
+	changed := preview.Added + preview.Removed
+	add(changed/10, 30, fmt.Sprintf("%d lines changed", changed))
+	add(2*(len(preview.Files)-1), 20, fmt.Sprintf("%d files touched", len(preview.Files)))
+	add(10*deleted, 20, fmt.Sprintf("%d files deleted", deleted))
+	add(25*len(preview.CriticalFiles), 50, fmt.Sprintf("%d critical files", len(preview.CriticalFiles)))
+
+	switch {
+	case preview.RiskScore >= patchRiskHigh:
+		preview.RiskLevel = "high"
+	case preview.RiskScore >= patchRiskMedium:
+		preview.RiskLevel = "medium"
+	default:
+		preview.RiskLevel = "low"
+	}
+}
+
+// ============================================================================
+// Patch Preview Methods (Wails-bound)
+// ============================================================================
+
+// PreviewPatch describes what a unified diff would change, without applying it
+//
+// Parameters:
+//   - rootDir: Project root
+//   - patch: Unified diff (post-processing settings are applied first, as in ApplyPatch)
+//
+// Returns:
+//   - PatchPreview: Per-file line counts, critical files, risk score and whether the patch applies
+//   - error: Error if the patch names no files
+func (a *App) PreviewPatch(rootDir, patch string) (PatchPreview, error) {
+	if err := a.validateContentRoot(rootDir); err != nil {
+		return PatchPreview{}, err
+	}
+	patch = a.postProcess(patch)
+	preview := PatchPreview{RootDir: rootDir, Files: parsePatchStats(patch), CriticalFiles: []string{}}
+	if len(preview.Files) == 0 {
+		return PatchPreview{}, fmt.Errorf("no file paths found in patch")
+	}
+
+	critical := gitignore.CompileIgnoreLines(a.settings.CriticalPathPatterns...)
+	for i := range preview.Files {
+		f := &preview.Files[i]
+		preview.Added += f.Added
+		preview.Removed += f.Removed
+		matched, pattern := critical.MatchesPathHow(f.Path)
+		if !matched && f.OldPath != "" {
+			matched, pattern = critical.MatchesPathHow(f.OldPath)
+		}
+		if matched {
+			f.CriticalPattern = pattern.Line
+			preview.CriticalFiles = append(preview.CriticalFiles, f.Path)
+		}
+	}
+	scorePatchRisk(&preview)
+
+	if err := checkUnifiedDiff(a.ctx, rootDir, patch); err != nil {
+		preview.ApplyError = err.Error()
+	} else {
+		preview.Applies = true
+	}
+	runtime.LogInfof(a.ctx, "Previewed patch for %s: %d files, +%d -%d, risk %d (%s)",
+		rootDir, len(preview.Files), preview.Added, preview.Removed, preview.RiskScore, preview.RiskLevel)
+	return preview, nil
+}
+
+// GetCriticalPathPatterns returns the gitignore-style patterns of critical paths
+func (a *App) GetCriticalPathPatterns() []string {
+	return append([]string{}, a.settings.CriticalPathPatterns...)
+}
+
+// SetCriticalPathPatterns replaces the critical path patterns used by PreviewPatch and saves them
+//
+// Parameters:
+//   - patterns: Gitignore-style patterns (e.g. migrations/, auth/, *.sql); empty ones and comments are dropped
+//
+// Returns:
+//   - error: Error if the setting cannot be saved
+func (a *App) SetCriticalPathPatterns(patterns []string) error {
+	cleaned := []string{}
+	for _, pattern := range patterns {
+		if pattern = strings.TrimSpace(pattern); pattern != "" && !strings.HasPrefix(pattern, "#") {
+			cleaned = append(cleaned, pattern)
+		}
+	}
+	a.settings.CriticalPathPatterns = cleaned
+	if err := a.saveSettings(); err != nil {
+		return fmt.Errorf("failed to save critical path patterns: %w", err)
+	}
+	runtime.LogInfof(a.ctx, "Critical path patterns set to: %v", cleaned)
+	return nil
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"path/filepath"
+	goruntime "runtime"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- Profiling and Generation Benchmarks ---
+//
+// A hidden debug mode serves net/http/pprof on localhost so CPU, heap and goroutine profiles can
+// be taken from a running app (go tool pprof http://127.0.0.1:6060/debug/pprof/heap). It is off
+// by default, not persisted, and enabled with SHOTGUN_PPROF=1 (or SHOTGUN_PPROF=<host:port>)
+// or the SetProfilingEnabled binding. Only loopback addresses are accepted.
+//
+// BenchmarkGeneration times the phases of a generation (directory walk, then reading and
+// formatting the file blocks) without writing checkpoints or emitting progress.
+
+const (
+	profilingEnvVar        = "SHOTGUN_PPROF"
+	defaultProfilingAddr   = "127.0.0.1:6060"
+	profilingShutdownDelay = 2 * time.Second
+)
+
+// ProfilingStatus describes the pprof endpoint
+type ProfilingStatus struct {
+	Enabled bool   `json:"enabled"` // Whether the endpoint is running
+	Address string `json:"address"` // Listening address (host:port)
+	URL     string `json:"url"`     // Index page of the profiles
+}
+
+// GenerationBenchmark is the result of BenchmarkGeneration
+type GenerationBenchmark struct {
+	RootDir         string  `json:"rootDir"`         // Project root directory
+	Files           int     `json:"files"`           // Files included by the selection
+	Dirs            int     `json:"dirs"`            // Directories walked
+	Bytes           int64   `json:"bytes"`           // Total size of the included files
+	OutputBytes     int     `json:"outputBytes"`     // Size of the formatted file blocks
+	WalkMs          int64   `json:"walkMs"`          // Time to walk the tree and apply ignore rules
+	ReadMs          int64   `json:"readMs"`          // Time to read, check and format every file
+	FilesPerSec     float64 `json:"filesPerSec"`     // Walk throughput
+	ReadMBPerSec    float64 `json:"readMBPerSec"`    // Read throughput
+	TotalAllocBytes uint64  `json:"totalAllocBytes"` // Bytes allocated during the benchmark
+	Mallocs         uint64  `json:"mallocs"`         // Heap objects allocated during the benchmark
+	NumGC           uint32  `json:"numGC"`           // Garbage collections during the benchmark
+	HeapAllocBytes  uint64  `json:"heapAllocBytes"`  // Live heap at the end of the benchmark
+	NumCPU          int     `json:"numCPU"`          // Logical CPUs available
+	GoVersion       string  `json:"goVersion"`       // Go version the app was built with
+}
+
+// startProfiling serves pprof on a loopback address
+func (a *App) startProfiling(addr string) (ProfilingStatus, error) {
+	a.profilingMu.Lock()
+	defer a.profilingMu.Unlock()
+	if a.profilingServer != nil {
+		return a.profilingStatusLocked(), nil
+	}
+
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return ProfilingStatus{}, fmt.Errorf("invalid profiling address %s: %w", addr, err)
+	}
+	if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		return ProfilingStatus{}, fmt.Errorf("profiling is only served on localhost, not %s", host)
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return ProfilingStatus{}, fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			runtime.LogErrorf(a.ctx, "Profiling server stopped: %v", err)
+		}
+	}()
+	a.profilingServer = server
+	a.profilingAddr = listener.Addr().String()
+
+	status := a.profilingStatusLocked()
+	runtime.LogWarningf(a.ctx, "Profiling enabled at %s", status.URL)
+	return status, nil
+}
+
+// stopProfiling shuts the pprof endpoint down
+func (a *App) stopProfiling() {
+	a.profilingMu.Lock()
+	defer a.profilingMu.Unlock()
+	if a.profilingServer == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), profilingShutdownDelay)
+	defer cancel()
+	if err := a.profilingServer.Shutdown(ctx); err != nil {
+		a.profilingServer.Close()
+	}
+	a.profilingServer = nil
+	a.profilingAddr = ""
+	runtime.LogInfo(a.ctx, "Profiling disabled")
+}
+
+// profilingStatusLocked returns the endpoint status (caller holds profilingMu)
+func (a *App) profilingStatusLocked() ProfilingStatus {
+	if a.profilingServer == nil {
+		return ProfilingStatus{}
+	}
+	return ProfilingStatus{
+		Enabled: true,
+		Address: a.profilingAddr,
+		URL:     "http://" + a.profilingAddr + "/debug/pprof/",
+	}
+}
+
+// startProfilingFromEnv enables profiling at startup when SHOTGUN_PPROF is set
+func (a *App) startProfilingFromEnv() {
+	value := strings.TrimSpace(os.Getenv(profilingEnvVar))
+	switch strings.ToLower(value) {
+	case "", "0", "false":
+		return
+	case "1", "true":
+		value = defaultProfilingAddr
+	}
+	if _, err := a.startProfiling(value); err != nil {
+		runtime.LogErrorf(a.ctx, "Cannot enable profiling from %s: %v", profilingEnvVar, err)
+	}
+}
+
+// ============================================================================
+// Profiling Methods (Wails-bound)
+// ============================================================================
+
+// GetProfilingStatus returns whether the pprof endpoint is running and where
+func (a *App) GetProfilingStatus() ProfilingStatus {
+	a.profilingMu.Lock()
+	defer a.profilingMu.Unlock()
+	return a.profilingStatusLocked()
+}
+
+// SetProfilingEnabled starts or stops the pprof endpoint on 127.0.0.1:6060
+// The setting lasts until the app exits.
+//
+// Returns:
+//   - ProfilingStatus: Status after the change
+//   - error: Error if the port cannot be opened
+func (a *App) SetProfilingEnabled(enabled bool) (ProfilingStatus, error) {
+	if !enabled {
+		a.stopProfiling()
+		return ProfilingStatus{}, nil
+	}
+	return a.startProfiling(defaultProfilingAddr)
+}
+
+// BenchmarkGeneration measures how a generation of the whole project (with the current ignore
+// settings) performs: walk time, read throughput and allocations
+//
+// Parameters:
+//   - rootDir: Project root directory
+//
+// Returns:
+//   - GenerationBenchmark: Timings and allocation statistics
+//   - error: Error if the directory does not exist or the benchmark is cancelled
+func (a *App) BenchmarkGeneration(rootDir string) (GenerationBenchmark, error) {
+	if err := a.validateContentRoot(rootDir); err != nil {
+		return GenerationBenchmark{}, err
+	}
+	result := GenerationBenchmark{RootDir: rootDir, NumCPU: goruntime.NumCPU(), GoVersion: goruntime.Version()}
+
+	goruntime.GC()
+	var before goruntime.MemStats
+	goruntime.ReadMemStats(&before)
+
+	// Phase 1: walk with the generation's exclusions and ignore rules
+	var relPaths []string
+	walkStart := time.Now()
+	stats, err := a.scanSelection(a.ctx, rootDir, nil, func(relPath string, size int64) {
+		relPaths = append(relPaths, relPath)
+	})
+	if err != nil {
+		return GenerationBenchmark{}, fmt.Errorf("benchmark walk failed: %w", err)
+	}
+	walkDuration := time.Since(walkStart)
+
+	// Phase 2: binary detection, reading and formatting, as appendFileContent does for generation
+	readStart := time.Now()
+	for _, relPath := range relPaths {
+		if err := a.ctx.Err(); err != nil {
+			return GenerationBenchmark{}, err
+		}
+		var block strings.Builder
+		a.appendFileContent(&block, filepath.Join(rootDir, relPath), relPath, 0, nil)
+		result.OutputBytes += block.Len()
+	}
+	readDuration := time.Since(readStart)
+
+	var after goruntime.MemStats
+	goruntime.ReadMemStats(&after)
+
+	result.Files = stats.FileCount
+	result.Dirs = stats.DirCount
+	result.Bytes = stats.TotalBytes
+	result.WalkMs = walkDuration.Milliseconds()
+	result.ReadMs = readDuration.Milliseconds()
+	if seconds := walkDuration.Seconds(); seconds > 0 {
+		result.FilesPerSec = float64(stats.FileCount+stats.DirCount) / seconds
+	}
+	if seconds := readDuration.Seconds(); seconds > 0 {
+		result.ReadMBPerSec = float64(stats.TotalBytes) / (1 << 20) / seconds
+	}
+	result.TotalAllocBytes = after.TotalAlloc - before.TotalAlloc
+	result.Mallocs = after.Mallocs - before.Mallocs
+	result.NumGC = after.NumGC - before.NumGC
+	result.HeapAllocBytes = after.HeapAlloc
+
+	runtime.LogInfof(a.ctx, "Generation benchmark for %s: %d files, walk %d ms, read %d ms (%.1f MB/s), %d bytes allocated",
+		rootDir, result.Files, result.WalkMs, result.ReadMs, result.ReadMBPerSec, result.TotalAllocBytes)
+	return result, nil
+}
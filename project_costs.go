@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// --- Per-Project Cost Attribution ---
+//
+// Every LLM job is tagged with the project it works on: the request's root directory, or the
+// active project (the watched one, else the last generated one) when the request names none.
+// The project shows on the job (Job.Project) and on the job's usage events, so LLM spend can be
+// billed per client codebase with GetProjectCosts. Like all usage data, costs are only
+// recorded while analytics are enabled.
+
+// ModelCost is the LLM spend of one provider/model
+type ModelCost struct {
+	Calls      int     `json:"calls"`      // LLM calls
+	TokensUsed int     `json:"tokensUsed"` // Tokens used
+	Cost       float64 `json:"cost"`       // Estimated spend in USD
+}
+
+// ProjectCosts is the LLM spend of one project in a period
+type ProjectCosts struct {
+	Project    string               `json:"project"`    // Project root directory (empty for calls without a project)
+	Period     string               `json:"period"`     // Period the costs cover
+	From       *time.Time           `json:"from"`       // Start of the period (nil for all time)
+	To         *time.Time           `json:"to"`         // End of the period (nil for all time)
+	Calls      int                  `json:"calls"`      // LLM calls
+	TokensUsed int                  `json:"tokensUsed"` // Tokens used
+	Cost       float64              `json:"cost"`       // Estimated spend in USD
+	ByModel    map[string]ModelCost `json:"byModel"`    // Spend per provider/model
+}
+
+// tagJobProject records the project of an LLM job and returns it
+//
+// Parameters:
+//   - ctx: Context of the running job (identifies the job)
+//   - rootDir: Project named by the request (empty for the active project)
+//
+// Returns:
+//   - string: Project the job is attributed to (empty if none is known)
+func (a *App) tagJobProject(ctx context.Context, rootDir string) string {
+	if rootDir == "" {
+		rootDir = a.currentProjectRoot()
+	}
+	if jobID := jobIDFromContext(ctx); jobID != "" && rootDir != "" && a.jobQueue != nil {
+		a.jobQueue.setJobProject(jobID, rootDir)
+	}
+	return rootDir
+}
+
+// costPeriodRange returns the time range of a billing period
+//
+// Parameters:
+//   - period: all (or empty), today, week (last 7 days), month (this calendar month),
+//     last_month, year (this calendar year), or a month as YYYY-MM
+//   - now: Current time
+//
+// Returns:
+//   - time.Time: Start of the period (zero for all)
+//   - time.Time: End of the period, exclusive (zero for all)
+//   - error: Error if the period is unknown
+func costPeriodRange(period string, now time.Time) (time.Time, time.Time, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	switch period {
+	case "", "all":
+		return time.Time{}, time.Time{}, nil
+	case "today":
+		return today, today.AddDate(0, 0, 1), nil
+	case "week":
+		return today.AddDate(0, 0, -6), today.AddDate(0, 0, 1), nil
+	case "month":
+		return month, month.AddDate(0, 1, 0), nil
+	case "last_month":
+		return month.AddDate(0, -1, 0), month, nil
+	case "year":
+		year := time.Date(now.Year(), 1, 1, 0, 0, 0, 0, now.Location())
+		return year, year.AddDate(1, 0, 0), nil
+	}
+	if start, err := time.ParseInLocation("2006-01", period, now.Location()); err == nil {
+		return start, start.AddDate(0, 1, 0), nil
+	}
+	return time.Time{}, time.Time{}, fmt.Errorf("unknown period: %s (use all, today, week, month, last_month, year or YYYY-MM)", period)
+}
+
+// ============================================================================
+// Project Cost Methods (Wails-bound)
+// ============================================================================
+
+// GetProjectCosts returns the LLM spend per project, for billing
+//
+// Parameters:
+//   - project: Project root directory (empty for every project)
+//   - period: all (or empty), today, week, month, last_month, year, or a month as YYYY-MM
+//
+// Returns:
+//   - []ProjectCosts: Spend per project, highest first (one entry when a project is given)
+//   - error: Error if the period is unknown or the usage store cannot be read
+func (a *App) GetProjectCosts(project, period string) ([]ProjectCosts, error) {
+	from, to, err := costPeriodRange(period, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if period == "" {
+		period = "all"
+	}
+	events, err := a.readUsageEvents()
+	if err != nil {
+		return nil, err
+	}
+
+	newCosts := func(project string) *ProjectCosts {
+		costs := &ProjectCosts{Project: project, Period: period, ByModel: make(map[string]ModelCost)}
+		if !from.IsZero() {
+			costs.From, costs.To = &from, &to
+		}
+		return costs
+	}
+	byProject := make(map[string]*ProjectCosts)
+	if project != "" {
+		project = projectSettingsKey(project)
+		byProject[project] = newCosts(project)
+	}
+	for _, event := range events {
+		if event.Type != "llm_call" || !from.IsZero() && (event.Timestamp.Before(from) || !event.Timestamp.Before(to)) {
+			continue
+		}
+		key := event.Project
+		if key != "" {
+			key = projectSettingsKey(key)
+		}
+		if project != "" && key != project {
+			continue
+		}
+		costs, ok := byProject[key]
+		if !ok {
+			costs = newCosts(key)
+			byProject[key] = costs
+		}
+		costs.Calls++
+		costs.TokensUsed += event.TokensUsed
+		costs.Cost += event.Cost
+		model := costs.ByModel[event.Provider+"/"+event.Model]
+		model.Calls++
+		model.TokensUsed += event.TokensUsed
+		model.Cost += event.Cost
+		costs.ByModel[event.Provider+"/"+event.Model] = model
+	}
+
+	result := make([]ProjectCosts, 0, len(byProject))
+	for _, costs := range byProject {
+		result = append(result, *costs)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Cost != result[j].Cost {
+			return result[i].Cost > result[j].Cost
+		}
+		return result[i].Project < result[j].Project
+	})
+	return result, nil
+}
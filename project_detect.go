@@ -0,0 +1,281 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- Project Type Detection ---
+//
+// DetectProjectType inspects the manifests of a project (at the root and one directory down,
+// for monorepos such as a Go backend with a frontend/ folder) and suggests ignore presets,
+// files worth pinning in the context, and test commands.
+
+// Ecosystem is a language ecosystem detected from a manifest
+type Ecosystem struct {
+	Name       string   `json:"name"`       // Ecosystem name (Node.js, Go, Python, Rust)
+	Manifest   string   `json:"manifest"`   // Manifest path relative to the project root (forward slashes)
+	Package    string   `json:"package"`    // Package or module name from the manifest (empty if none)
+	Frameworks []string `json:"frameworks"` // Well-known frameworks found in the dependencies
+}
+
+// ProjectTypeInfo is the result of DetectProjectType
+type ProjectTypeInfo struct {
+	RootDir       string      `json:"rootDir"`       // Project root directory
+	Ecosystems    []Ecosystem `json:"ecosystems"`    // Detected ecosystems, root manifests first
+	IgnorePresets []string    `json:"ignorePresets"` // Recommended ignore patterns (.gitignore syntax)
+	PinnedFiles   []string    `json:"pinnedFiles"`   // Existing files recommended to always include
+	TestCommands  []string    `json:"testCommands"`  // Recommended test commands, run from the project root
+}
+
+// ecosystemDetector describes how one manifest type is recognized
+type ecosystemDetector struct {
+	manifest    string
+	name        string
+	ignores     []string
+	extraPinned []string
+	important   []string // Patterns of files worth selecting first, relative to the manifest directory
+	inspect     func(data []byte) (pkg string, frameworks []string, testCmd string)
+}
+
+// goModuleRegex extracts the module path of a go.mod
+var goModuleRegex = regexp.MustCompile(`(?m)^module\s+(\S+)`)
+
+// tomlNameRegex extracts the first name = "..." entry of a TOML manifest
+var tomlNameRegex = regexp.MustCompile(`(?m)^name\s*=\s*"([^"]+)"`)
+
+// knownFrameworks maps dependency names to framework names, per ecosystem
+var knownFrameworks = map[string]map[string]string{
+	"Node.js": {"react": "React", "vue": "Vue", "next": "Next.js", "svelte": "Svelte", "@angular/core": "Angular", "express": "Express", "vite": "Vite"},
+	"Go":      {"github.com/wailsapp/wails": "Wails", "github.com/gin-gonic/gin": "Gin", "github.com/labstack/echo": "Echo", "github.com/gofiber/fiber": "Fiber"},
+	"Python":  {"django": "Django", "flask": "Flask", "fastapi": "FastAPI", "pytest": "pytest"},
+	"Rust":    {"tokio": "Tokio", "actix-web": "Actix Web", "axum": "Axum", "tauri": "Tauri"},
+}
+
+var ecosystemDetectors = []ecosystemDetector{
+	{
+		manifest:    "package.json",
+		name:        "Node.js",
+		ignores:     []string{"node_modules/", "dist/", "coverage/", ".next/", "*.log"},
+		extraPinned: []string{"tsconfig.json"},
+		important:   []string{"vite.config.*", "next.config.*", "src/main.*", "src/index.*", "src/App.*", "src/router/index.*", "src/routes.*"},
+		inspect:     inspectPackageJSON,
+	},
+	{
+		manifest:  "go.mod",
+		name:      "Go",
+		ignores:   []string{"vendor/", "bin/", "*.exe", "*.test"},
+		important: []string{"main.go", "app.go", "cmd/*/main.go", "internal/config/*.go"},
+		inspect: func(data []byte) (string, []string, string) {
+			pkg := ""
+			if m := goModuleRegex.FindSubmatch(data); m != nil {
+				pkg = string(m[1])
+			}
+			return pkg, matchFrameworks("Go", string(data)), "go test ./..."
+		},
+	},
+	{
+		manifest:  "pyproject.toml",
+		name:      "Python",
+		ignores:   []string{".venv/", "__pycache__/", "*.pyc", ".pytest_cache/", "*.egg-info/", "dist/", "build/"},
+		important: []string{"manage.py", "settings.py", "*/settings.py", "src/*/__init__.py", "src/*/__main__.py", "*/__main__.py"},
+		inspect: func(data []byte) (string, []string, string) {
+			pkg := ""
+			if m := tomlNameRegex.FindSubmatch(data); m != nil {
+				pkg = string(m[1])
+			}
+			testCmd := "python -m unittest"
+			if strings.Contains(string(data), "pytest") {
+				testCmd = "pytest"
+			}
+			return pkg, matchFrameworks("Python", strings.ToLower(string(data))), testCmd
+		},
+	},
+	{
+		manifest:    "Cargo.toml",
+		name:        "Rust",
+		ignores:     []string{"target/"},
+		extraPinned: []string{"Cargo.lock"},
+		important:   []string{"src/main.rs", "src/lib.rs", "build.rs"},
+		inspect: func(data []byte) (string, []string, string) {
+			pkg := ""
+			if m := tomlNameRegex.FindSubmatch(data); m != nil {
+				pkg = string(m[1])
+			}
+			return pkg, matchFrameworks("Rust", string(data)), "cargo test"
+		},
+	},
+}
+
+// inspectPackageJSON reads the name, frameworks and test script of a package.json
+func inspectPackageJSON(data []byte) (string, []string, string) {
+	var manifest struct {
+		Name            string            `json:"name"`
+		Scripts         map[string]string `json:"scripts"`
+		Dependencies    map[string]string `json:"dependencies"`
+		DevDependencies map[string]string `json:"devDependencies"`
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return "", nil, ""
+	}
+
+	var deps []string
+	for dep := range manifest.Dependencies {
+		deps = append(deps, dep)
+	}
+	for dep := range manifest.DevDependencies {
+		deps = append(deps, dep)
+	}
+	var frameworks []string
+	for _, dep := range deps {
+		if fw, ok := knownFrameworks["Node.js"][dep]; ok {
+			frameworks = append(frameworks, fw)
+		}
+	}
+	sort.Strings(frameworks)
+
+	testCmd := ""
+	if script := manifest.Scripts["test"]; script != "" && !strings.Contains(script, "no test specified") {
+		testCmd = "npm test"
+	}
+	return manifest.Name, frameworks, testCmd
+}
+
+// matchFrameworks returns the known frameworks of an ecosystem mentioned in a manifest
+func matchFrameworks(ecosystem, manifest string) []string {
+	var frameworks []string
+	for dep, fw := range knownFrameworks[ecosystem] {
+		if strings.Contains(manifest, dep) {
+			frameworks = append(frameworks, fw)
+		}
+	}
+	sort.Strings(frameworks)
+	return frameworks
+}
+
+// nodeRunner returns the package manager a Node.js project uses, judging by its lockfile
+func (a *App) nodeRunner(dir string) string {
+	switch {
+	case a.fileExists(filepath.Join(dir, "pnpm-lock.yaml")):
+		return "pnpm"
+	case a.fileExists(filepath.Join(dir, "yarn.lock")):
+		return "yarn"
+	default:
+		return "npm"
+	}
+}
+
+// fileExists reports whether path exists and is a regular file
+func (a *App) fileExists(path string) bool {
+	info, err := a.projectFS.Stat(path)
+	return err == nil && info.Mode().IsRegular()
+}
+
+// manifestDirs returns the directories searched for manifests: the root and its direct
+// subdirectories (hidden and hard-excluded directories are skipped)
+func (a *App) manifestDirs(rootDir string) []string {
+	dirs := []string{"."}
+	entries, err := a.projectFS.ReadDir(rootDir)
+	if err != nil {
+		return dirs
+	}
+	exclude := watcherExcludeSet(a.settings.WatcherExcludeDirs)
+	for _, entry := range entries {
+		if entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") && !exclude[entry.Name()] {
+			dirs = append(dirs, entry.Name())
+		}
+	}
+	return dirs
+}
+
+// ============================================================================
+// Project Detection Methods (Wails-bound)
+// ============================================================================
+
+// DetectProjectType inspects the project's manifests (package.json, go.mod, pyproject.toml,
+// Cargo.toml) and recommends a setup for it
+//
+// Parameters:
+//   - rootDir: Project root directory
+//
+// Returns:
+//   - ProjectTypeInfo: Detected ecosystems, ignore presets, pinned files and test commands
+//   - error: Error if the directory does not exist
+func (a *App) DetectProjectType(rootDir string) (ProjectTypeInfo, error) {
+	if err := a.validateContentRoot(rootDir); err != nil {
+		return ProjectTypeInfo{}, err
+	}
+
+	result := ProjectTypeInfo{
+		RootDir:       rootDir,
+		Ecosystems:    []Ecosystem{},
+		IgnorePresets: []string{},
+		PinnedFiles:   []string{},
+		TestCommands:  []string{},
+	}
+	add := func(list *[]string, item string) {
+		for _, existing := range *list {
+			if existing == item {
+				return
+			}
+		}
+		*list = append(*list, item)
+	}
+
+	for _, readme := range []string{"README.md", "README.rst", "README"} {
+		if a.fileExists(filepath.Join(rootDir, readme)) {
+			add(&result.PinnedFiles, readme)
+			break
+		}
+	}
+
+	for _, dir := range a.manifestDirs(rootDir) {
+		absDir := filepath.Join(rootDir, dir)
+		for _, detector := range ecosystemDetectors {
+			data, err := a.projectFS.ReadFile(filepath.Join(absDir, detector.manifest))
+			if err != nil {
+				continue
+			}
+			relManifest := filepath.ToSlash(filepath.Join(dir, detector.manifest))
+			pkg, frameworks, testCmd := detector.inspect(data)
+			if frameworks == nil {
+				frameworks = []string{}
+			}
+			result.Ecosystems = append(result.Ecosystems, Ecosystem{
+				Name:       detector.name,
+				Manifest:   relManifest,
+				Package:    pkg,
+				Frameworks: frameworks,
+			})
+
+			for _, pattern := range detector.ignores {
+				add(&result.IgnorePresets, pattern)
+			}
+			add(&result.PinnedFiles, relManifest)
+			for _, extra := range detector.extraPinned {
+				if a.fileExists(filepath.Join(absDir, extra)) {
+					add(&result.PinnedFiles, filepath.ToSlash(filepath.Join(dir, extra)))
+				}
+			}
+
+			if testCmd != "" {
+				if detector.name == "Node.js" && testCmd == "npm test" {
+					testCmd = a.nodeRunner(absDir) + " test"
+				}
+				if dir != "." {
+					testCmd = fmt.Sprintf("cd %s && %s", dir, testCmd)
+				}
+				add(&result.TestCommands, testCmd)
+			}
+		}
+	}
+
+	runtime.LogInfof(a.ctx, "Detected %d ecosystems in %s", len(result.Ecosystems), rootDir)
+	return result, nil
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// --- Project Filesystems ---
+//
+// Project files are read through App.projectFS, a ProjectFS set by the constructor (NewApp
+// uses hostProjectFS, NewAppWithFS takes any other), instead of the os package. The generator,
+// the file watcher, indexing and every other reader of project content go through it, so a
+// project root can also be a read-only virtual filesystem (an fs.FS) mounted at a virtual root
+// path. Paths stay absolute OS paths everywhere: a mounted project's files live "below" its root
+// (e.g. /downloads/src.zip/cmd/main.go for an archive mounted at /downloads/src.zip).
+//
+// hostProjectFS serves the mounted projects and sends paths outside every mount to the os
+// package. A mountedProject is itself a ProjectFS serving one fs.FS, which is how tests run the
+// app on an in-memory project: NewAppWithFS(&mountedProject{root: "/project", fsys: memFS}).
+// The os package is only used directly for the app's own data under the config directory and
+// for files that are not part of a project (external attachments); writes to project files and
+// the file watcher (fsnotify needs real directories) require OnDisk.
+
+// ProjectFS reads project files by absolute path
+type ProjectFS interface {
+	Stat(path string) (fs.FileInfo, error)        // os.Stat
+	ReadDir(path string) ([]fs.DirEntry, error)   // os.ReadDir
+	ReadFile(path string) ([]byte, error)         // os.ReadFile
+	Open(path string) (fs.File, error)            // os.Open
+	WalkDir(root string, fn fs.WalkDirFunc) error // filepath.WalkDir; fn receives absolute paths
+
+	// OnDisk reports whether path is on the local disk, so it can be watched and written
+	OnDisk(path string) bool
+}
+
+// hostProjectFS is the ProjectFS of the app: mounted projects, then the local disk
+type hostProjectFS struct{}
+
+// Stat implements ProjectFS
+func (hostProjectFS) Stat(path string) (fs.FileInfo, error) {
+	if m, _ := mountFor(path); m != nil {
+		return m.Stat(path)
+	}
+	return os.Stat(path)
+}
+
+// ReadDir implements ProjectFS
+func (hostProjectFS) ReadDir(path string) ([]fs.DirEntry, error) {
+	if m, _ := mountFor(path); m != nil {
+		return m.ReadDir(path)
+	}
+	return os.ReadDir(path)
+}
+
+// ReadFile implements ProjectFS
+func (hostProjectFS) ReadFile(path string) ([]byte, error) {
+	if m, _ := mountFor(path); m != nil {
+		return m.ReadFile(path)
+	}
+	return os.ReadFile(path)
+}
+
+// Open implements ProjectFS
+func (hostProjectFS) Open(path string) (fs.File, error) {
+	if m, _ := mountFor(path); m != nil {
+		return m.Open(path)
+	}
+	return os.Open(path)
+}
+
+// WalkDir implements ProjectFS
+func (hostProjectFS) WalkDir(root string, fn fs.WalkDirFunc) error {
+	if m, _ := mountFor(root); m != nil {
+		return m.WalkDir(root, fn)
+	}
+	return filepath.WalkDir(root, fn)
+}
+
+// OnDisk implements ProjectFS
+func (hostProjectFS) OnDisk(path string) bool {
+	return !isMountedPath(path)
+}
+
+// mountedProject is a virtual filesystem serving a project root
+type mountedProject struct {
+	root   string    // Virtual root directory (absolute, clean)
+	fsys   fs.FS     // Files of the project
+	kind   string    // Kind of source (archive, ssh, docker)
+	source string    // Where the files come from, for display
+	closer io.Closer // Released on unmount (may be nil)
+}
+
+// projectMounts holds the mounted projects by virtual root
+var projectMounts = struct {
+	sync.RWMutex
+	byRoot map[string]*mountedProject
+}{byRoot: make(map[string]*mountedProject)}
+
+// mountProject serves fsys at root, replacing any previous mount there
+func mountProject(m *mountedProject) {
+	m.root = filepath.Clean(m.root)
+	projectMounts.Lock()
+	previous := projectMounts.byRoot[m.root]
+	projectMounts.byRoot[m.root] = m
+	projectMounts.Unlock()
+	if previous != nil && previous.closer != nil {
+		previous.closer.Close()
+	}
+}
+
+// unmountProject removes the mount at root and releases it
+func unmountProject(root string) bool {
+	root = filepath.Clean(root)
+	projectMounts.Lock()
+	m := projectMounts.byRoot[root]
+	delete(projectMounts.byRoot, root)
+	projectMounts.Unlock()
+	if m == nil {
+		return false
+	}
+	if m.closer != nil {
+		m.closer.Close()
+	}
+	return true
+}
+
+// closeMountedProject unmounts a project and drops its index
+func (a *App) closeMountedProject(rootDir string) bool {
+	if !unmountProject(rootDir) {
+		return false
+	}
+	a.indexMu.Lock()
+	if idx := a.index; idx != nil && idx.rootDir == rootDir {
+		idx.mu.RLock()
+		if idx.jobID != "" && idx.indexedAt.IsZero() && a.jobQueue != nil {
+			a.jobQueue.CancelJob(idx.jobID)
+		}
+		idx.mu.RUnlock()
+		a.index = nil
+	}
+	a.indexMu.Unlock()
+	return true
+}
+
+// mountFor returns the mount containing path and the path inside it ("." for its root)
+func mountFor(path string) (*mountedProject, string) {
+	projectMounts.RLock()
+	defer projectMounts.RUnlock()
+	if len(projectMounts.byRoot) == 0 {
+		return nil, ""
+	}
+	path = filepath.Clean(path)
+	for root, m := range projectMounts.byRoot {
+		if path == root {
+			return m, "."
+		}
+		if strings.HasPrefix(path, root+string(filepath.Separator)) {
+			return m, filepath.ToSlash(path[len(root)+1:])
+		}
+	}
+	return nil, ""
+}
+
+// isMountedPath reports whether path belongs to a mounted (read-only) project
+func isMountedPath(path string) bool {
+	m, _ := mountFor(path)
+	return m != nil
+}
+
+// mountedPathError is returned when a mounted project would have to be modified
+func mountedPathError(path string) error {
+	return fmt.Errorf("%s belongs to a read-only project", path)
+}
+
+// name returns the fs path of an absolute path below the mount
+func (m *mountedProject) name(op, path string) (string, error) {
+	path = filepath.Clean(path)
+	if path == m.root {
+		return ".", nil
+	}
+	if rel, ok := strings.CutPrefix(path, m.root+string(filepath.Separator)); ok {
+		return filepath.ToSlash(rel), nil
+	}
+	return "", &fs.PathError{Op: op, Path: path, Err: fs.ErrNotExist}
+}
+
+// Stat implements ProjectFS
+func (m *mountedProject) Stat(path string) (fs.FileInfo, error) {
+	name, err := m.name("stat", path)
+	if err != nil {
+		return nil, err
+	}
+	return fs.Stat(m.fsys, name)
+}
+
+// ReadDir implements ProjectFS
+func (m *mountedProject) ReadDir(path string) ([]fs.DirEntry, error) {
+	name, err := m.name("readdir", path)
+	if err != nil {
+		return nil, err
+	}
+	return fs.ReadDir(m.fsys, name)
+}
+
+// ReadFile implements ProjectFS
+func (m *mountedProject) ReadFile(path string) ([]byte, error) {
+	name, err := m.name("read", path)
+	if err != nil {
+		return nil, err
+	}
+	return fs.ReadFile(m.fsys, name)
+}
+
+// Open implements ProjectFS
+func (m *mountedProject) Open(path string) (fs.File, error) {
+	name, err := m.name("open", path)
+	if err != nil {
+		return nil, err
+	}
+	return m.fsys.Open(name)
+}
+
+// WalkDir implements ProjectFS
+func (m *mountedProject) WalkDir(root string, fn fs.WalkDirFunc) error {
+	name, err := m.name("walk", root)
+	if err != nil {
+		return fn(root, nil, err)
+	}
+	return fs.WalkDir(m.fsys, name, func(p string, d fs.DirEntry, err error) error {
+		if p == "." {
+			return fn(m.root, d, err)
+		}
+		return fn(filepath.Join(m.root, filepath.FromSlash(p)), d, err)
+	})
+}
+
+// OnDisk implements ProjectFS: mounted projects are read-only and cannot be watched
+func (m *mountedProject) OnDisk(path string) bool {
+	return false
+}
+
+// projectIsDir reports whether a project path is an existing directory
+func (a *App) projectIsDir(path string) bool {
+	info, err := a.projectFS.Stat(path)
+	return err == nil && info.IsDir()
+}
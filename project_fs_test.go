@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newMemProjectApp returns an app whose only project is an in-memory one at root
+func newMemProjectApp(t *testing.T, files map[string]string) (*App, string) {
+	t.Helper()
+	fsys := newMemFS()
+	for name, content := range files {
+		fsys.addFile(name, []byte(content), time.Unix(0, 0))
+	}
+	root := filepath.Join(string(filepath.Separator), "project")
+	a := NewAppWithFS(&mountedProject{root: root, fsys: fsys, kind: "memory", source: "test"})
+	a.ctx = context.Background()
+	return a, root
+}
+
+func TestMemProjectTree(t *testing.T) {
+	a, root := newMemProjectApp(t, map[string]string{
+		"main.go":      "package main\n\nfunc main() {}\n",
+		"pkg/util.go":  "package pkg\n",
+		"pkg/logo.png": "\x89PNG\r\n\x1a\n\x00\x00",
+		"README.md":    "# Project\n",
+	})
+
+	nodes, err := a.buildTreeRecursive(a.ctx, root, root, a.newTreeBuildOptions(root, nil), 0)
+	if err != nil {
+		t.Fatalf("buildTreeRecursive: %v", err)
+	}
+	var names []string
+	for _, node := range nodes {
+		names = append(names, node.Name)
+	}
+	if got, want := strings.Join(names, ","), "pkg,main.go,README.md"; got != want {
+		t.Fatalf("root entries = %s, want %s", got, want)
+	}
+
+	pkg := nodes[0]
+	if !pkg.IsDir || len(pkg.Children) != 2 {
+		t.Fatalf("pkg = %+v, want a directory with 2 children", pkg)
+	}
+	for _, child := range pkg.Children {
+		wantBinary := child.Name == "logo.png"
+		if child.IsBinary != wantBinary {
+			t.Errorf("%s: IsBinary = %v, want %v", child.RelPath, child.IsBinary, wantBinary)
+		}
+	}
+	if nodes[1].Size != int64(len("package main\n\nfunc main() {}\n")) {
+		t.Errorf("main.go: Size = %d", nodes[1].Size)
+	}
+}
+
+func TestMemProjectFileBlock(t *testing.T) {
+	a, root := newMemProjectApp(t, map[string]string{
+		"pkg/util.go": "package pkg\n\nconst Answer = 42\n",
+	})
+
+	var block strings.Builder
+	relPath := filepath.Join("pkg", "util.go")
+	if !a.appendFileContent(&block, filepath.Join(root, relPath), relPath, 0, nil) {
+		t.Fatalf("appendFileContent failed")
+	}
+	got := block.String()
+	if !strings.Contains(got, `<file path="pkg/util.go"`) || !strings.Contains(got, "const Answer = 42") {
+		t.Fatalf("block = %q, want the file wrapped with its path", got)
+	}
+
+	if _, err := a.projectFS.Stat(filepath.Join(root, "missing.go")); err == nil {
+		t.Fatalf("Stat of a missing file succeeded")
+	}
+	if _, err := a.projectFS.Stat(filepath.Join(string(filepath.Separator), "elsewhere", "main.go")); err == nil {
+		t.Fatalf("Stat outside the mount root succeeded")
+	}
+}
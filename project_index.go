@@ -0,0 +1,361 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- Background Project Index ---
+//
+// When a project is opened, a low-priority project_index job walks it once and caches what
+// later operations would otherwise recompute from disk:
+//   - the file tree returned by ListFiles, while the watcher covers the whole project
+//     (until a watched change or a settings change)
+//   - per-file token counts and binary flags (validated against size and modification time),
+//     also shown in the tree as token weights (see token_weight.go)
+//   - git status of the working tree
+//   - recent commit history per file (see file_churn.go)
+//
+// The index is saved to disk when the job finishes and restored when the project is listed
+// again in a later session (see index_cache.go). Embeddings are not computed; the index is
+// where they would be stored.
+
+const indexYieldEvery = 100 // Files indexed between short pauses, keeping the job low priority
+
+// indexedFile is the cached information about one file
+type indexedFile struct {
+	size     int64
+	modTime  time.Time
+	isBinary bool
+	tokens   int
+}
+
+// projectIndex caches the index of the open project
+type projectIndex struct {
+	mu        sync.RWMutex
+	rootDir   string
+	jobID     string
+	files     map[string]indexedFile // By absolute path
+	tree      []*FileNode            // Cached default ListFiles result (nil if invalid)
+	treeStamp string                 // Settings the tree was built with
+	gitStatus map[string]string      // Porcelain status code by relative path (forward slashes)
+	churn     map[string]FileChurn   // Recent history by relative path (forward slashes, nil outside git)
+	indexedAt time.Time
+}
+
+// ProjectIndexStatus describes the index of a project
+type ProjectIndexStatus struct {
+	RootDir     string            `json:"rootDir"`     // Project root directory
+	JobID       string            `json:"jobId"`       // ID of the last indexing job
+	Ready       bool              `json:"ready"`       // True once the indexing job has finished
+	FileCount   int               `json:"fileCount"`   // Number of files indexed
+	TotalTokens int               `json:"totalTokens"` // Sum of the token counts of the text files
+	TreeCached  bool              `json:"treeCached"`  // Whether a tree is cached (served while the project is watched)
+	GitStatus   map[string]string `json:"gitStatus"`   // Porcelain status code by relative path (nil outside git)
+	IndexedAt   *time.Time        `json:"indexedAt"`   // When indexing finished (nil while running)
+}
+
+// projectIndexParams are the parameters of a project_index job
+type projectIndexParams struct {
+	RootDir string `json:"rootDir"` // Project root to index
+}
+
+// treeCacheStamp captures the settings a default ListFiles tree depends on
+func (a *App) treeCacheStamp(rootDir string) string {
+	stamp, _ := json.Marshal([]interface{}{
+		a.useGitignore, a.useCustomIgnore, a.settings.CustomIgnoreRules,
+		a.settings.ShowDotfiles, a.settings.VisibleDotfiles,
+		a.settings.MaxTreeDepth, a.settings.MaxEntriesPerDir,
+		a.settings.ForceIncludePaths[projectSettingsKey(rootDir)],
+		a.summaryOnlySet(rootDir),
+	})
+	return string(stamp)
+}
+
+// currentIndex returns the index if it belongs to rootDir
+func (a *App) currentIndex(rootDir string) *projectIndex {
+	a.indexMu.Lock()
+	defer a.indexMu.Unlock()
+	if a.index == nil || a.index.rootDir != rootDir {
+		return nil
+	}
+	return a.index
+}
+
+// watcherCoversProject reports whether the file watcher watches the whole project, so that
+// every change invalidates the cached tree
+func (a *App) watcherCoversProject(rootDir string) bool {
+	if a.fileWatcher == nil {
+		return false
+	}
+	a.fileWatcher.mu.Lock()
+	defer a.fileWatcher.mu.Unlock()
+	return a.fileWatcher.rootDir == rootDir && a.fileWatcher.selection == nil
+}
+
+// cachedTree returns the cached default tree of a project (nil on a miss)
+// Without a watcher on the project, changes would go unnoticed, so the cache is not used.
+func (a *App) cachedTree(rootDir string) []*FileNode {
+	idx := a.currentIndex(rootDir)
+	if idx == nil || !a.watcherCoversProject(rootDir) {
+		return nil
+	}
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	if idx.tree == nil || idx.treeStamp != a.treeCacheStamp(rootDir) {
+		return nil
+	}
+	return idx.tree
+}
+
+// storeTree caches the default tree of the indexed project
+func (a *App) storeTree(rootDir string, tree []*FileNode) {
+	if idx := a.currentIndex(rootDir); idx != nil {
+		idx.mu.Lock()
+		idx.tree = tree
+		idx.treeStamp = a.treeCacheStamp(rootDir)
+		idx.mu.Unlock()
+	}
+}
+
+// invalidateIndexedTree drops the cached tree after a change in the project
+func (a *App) invalidateIndexedTree(rootDir string) {
+	if idx := a.currentIndex(rootDir); idx != nil {
+		idx.mu.Lock()
+		idx.tree = nil
+		idx.mu.Unlock()
+	}
+}
+
+// lookupIndexedFile returns the cached information of a file if it is still current
+func (a *App) lookupIndexedFile(absPath string) (indexedFile, bool) {
+	a.indexMu.Lock()
+	idx := a.index
+	a.indexMu.Unlock()
+	if idx == nil {
+		return indexedFile{}, false
+	}
+
+	idx.mu.RLock()
+	entry, ok := idx.files[absPath]
+	idx.mu.RUnlock()
+	if !ok {
+		return indexedFile{}, false
+	}
+	info, err := a.projectFS.Stat(absPath)
+	if err != nil || info.Size() != entry.size || !info.ModTime().Equal(entry.modTime) {
+		return indexedFile{}, false
+	}
+	return entry, true
+}
+
+// isBinaryFileCached is isBinaryFile backed by the project index
+func (a *App) isBinaryFileCached(absPath string) (bool, error) {
+	if entry, ok := a.lookupIndexedFile(absPath); ok {
+		return entry.isBinary, nil
+	}
+	return a.isBinaryFile(absPath)
+}
+
+// startProjectIndex replaces the index with a new one for rootDir and enqueues its job,
+// cancelling the indexing of a previously opened project
+func (a *App) startProjectIndex(rootDir string) (string, error) {
+	if a.jobQueue == nil {
+		return "", fmt.Errorf("job queue not initialized")
+	}
+
+	a.indexMu.Lock()
+	idx := &projectIndex{rootDir: rootDir, files: make(map[string]indexedFile)}
+	if previous := a.index; previous != nil {
+		previous.mu.RLock()
+		if previous.jobID != "" && previous.indexedAt.IsZero() {
+			a.jobQueue.CancelJob(previous.jobID)
+		}
+		if previous.rootDir == rootDir {
+			// Keep serving the previous tree and file entries while reindexing
+			idx.tree, idx.treeStamp = previous.tree, previous.treeStamp
+			for path, entry := range previous.files {
+				idx.files[path] = entry
+			}
+		}
+		previous.mu.RUnlock()
+	}
+	a.index = idx
+	a.indexMu.Unlock()
+
+	jobID, err := a.jobQueue.Enqueue("project_index", projectIndexParams{RootDir: rootDir})
+	if err != nil {
+		return "", err
+	}
+	idx.mu.Lock()
+	idx.jobID = jobID
+	idx.mu.Unlock()
+	return jobID, nil
+}
+
+// executeProjectIndexJob implements the project_index job type
+func (a *App) executeProjectIndexJob(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var p projectIndexParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, fmt.Errorf("invalid project_index parameters: %w", err)
+	}
+	if err := a.validateContentRoot(p.RootDir); err != nil {
+		return nil, err
+	}
+	idx := a.currentIndex(p.RootDir)
+	if idx == nil {
+		return nil, fmt.Errorf("project %s is no longer open", p.RootDir)
+	}
+
+	// Tree (unless ListFiles already cached it)
+	idx.mu.RLock()
+	hasTree := idx.tree != nil && idx.treeStamp == a.treeCacheStamp(p.RootDir)
+	idx.mu.RUnlock()
+	if !hasTree {
+		if _, err := a.ListFiles(p.RootDir); err != nil {
+			runtime.LogWarningf(a.ctx, "Indexing %s: tree not cached: %v", p.RootDir, err)
+		}
+	}
+
+	// Token counts and binary flags of the included files, read by a pool of workers
+	// (see background_work.go for the limits)
+	opts := a.newTreeBuildOptions(p.RootDir, a.compileProjectGitignore(p.RootDir))
+	paths := make(chan string, 256)
+	walkDone := make(chan error, 1)
+	go func() { walkDone <- a.walkProjectFilesParallel(ctx, p.RootDir, opts, paths) }()
+
+	_, readers := a.settings.BackgroundWork.effectiveWorkers()
+	var wg sync.WaitGroup
+	for i := 0; i < readers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			worker := a.startBackgroundWorker()
+			for path := range paths {
+				if ctx.Err() != nil {
+					continue // Drain the channel so the walkers can finish
+				}
+				start := time.Now()
+				info, err := a.projectFS.Stat(path)
+				if err != nil {
+					continue
+				}
+				if _, ok := a.lookupIndexedFile(path); ok {
+					continue // Unchanged since the last index
+				}
+				entry := indexedFile{size: info.Size(), modTime: info.ModTime()}
+				entry.isBinary, _ = a.isBinaryFile(path)
+				if !entry.isBinary {
+					if content, err := a.projectFS.ReadFile(path); err == nil {
+						entry.tokens = a.EstimateTokens(string(content))
+					}
+				}
+				idx.mu.Lock()
+				idx.files[path] = entry
+				idx.mu.Unlock()
+				worker.pace(ctx, start)
+			}
+		}()
+	}
+	wg.Wait()
+	if err := <-walkDone; err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	gitStatus := readGitStatus(ctx, p.RootDir)
+	churn := readGitChurn(ctx, p.RootDir)
+
+	idx.mu.Lock()
+	idx.gitStatus = gitStatus
+	idx.churn = churn
+	if churn != nil {
+		annotateChurn(idx.tree, churn)
+	}
+	annotateTokenWeights(idx.tree, idx.files)
+	idx.indexedAt = time.Now()
+	idx.mu.Unlock()
+	if err := a.saveIndexCache(idx); err != nil {
+		runtime.LogWarningf(a.ctx, "Failed to save index cache of %s: %v", p.RootDir, err)
+	}
+
+	status := a.GetProjectIndexStatus(p.RootDir)
+	runtime.LogInfof(a.ctx, "Indexed %s: %d files, ~%d tokens", p.RootDir, status.FileCount, status.TotalTokens)
+	runtime.EventsEmit(a.ctx, "projectIndexUpdated", status)
+	return status, nil
+}
+
+// readGitStatus returns the porcelain status of a git working tree (nil outside git)
+func readGitStatus(ctx context.Context, rootDir string) map[string]string {
+	cmd := exec.CommandContext(ctx, "git", "status", "--porcelain", "-z")
+	cmd.Dir = rootDir
+	out, err := cmd.Output()
+	if err != nil {
+		return nil
+	}
+
+	status := make(map[string]string)
+	entries := strings.Split(string(out), "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if len(entry) < 4 {
+			continue
+		}
+		code, path := entry[:2], entry[3:]
+		status[path] = strings.TrimSpace(code)
+		if code[0] == 'R' || code[0] == 'C' {
+			i++ // Renames and copies are followed by the original path
+		}
+	}
+	return status
+}
+
+// ============================================================================
+// Project Index Methods (Wails-bound)
+// ============================================================================
+
+// IndexProject (re)indexes a project in the background
+//
+// Returns:
+//   - string: Job ID of the project_index job
+//   - error: Error if the directory does not exist or the job cannot be enqueued
+func (a *App) IndexProject(rootDir string) (string, error) {
+	if !a.projectIsDir(rootDir) {
+		return "", fmt.Errorf("project folder does not exist: %s", rootDir)
+	}
+	return a.startProjectIndex(rootDir)
+}
+
+// GetProjectIndexStatus returns the state of the index of a project
+func (a *App) GetProjectIndexStatus(rootDir string) ProjectIndexStatus {
+	status := ProjectIndexStatus{RootDir: rootDir}
+	idx := a.currentIndex(rootDir)
+	if idx == nil {
+		return status
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	status.JobID = idx.jobID
+	status.Ready = !idx.indexedAt.IsZero()
+	status.FileCount = len(idx.files)
+	for _, entry := range idx.files {
+		status.TotalTokens += entry.tokens
+	}
+	status.TreeCached = idx.tree != nil
+	status.GitStatus = idx.gitStatus
+	if status.Ready {
+		indexedAt := idx.indexedAt
+		status.IndexedAt = &indexedAt
+	}
+	return status
+}
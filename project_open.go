@@ -0,0 +1,247 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/options"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- Opening Projects From Outside the Folder Dialog ---
+//
+// Folders dropped onto the window, passed on the command line, or opened through a
+// shotgun://open?path=...&mode=dev link are validated here and announced to the frontend with a
+// "projectOpened" event ({path, source, mode}), which sets the project root and shows its files.
+// Rejected requests emit "projectOpenRejected" with the reason.
+//
+// A second launch of the app (e.g. an editor running `shotgun-code <path>` or the OS handing
+// over a shotgun:// link) is forwarded to the running instance by the single-instance lock.
+
+// deepLinkScheme is the URL scheme registered for the app (see wails.json)
+const deepLinkScheme = "shotgun"
+
+// openRequest is a project to open once the frontend is ready
+type openRequest struct {
+	path   string
+	source string
+	mode   string
+}
+
+// validPromptModes are the modes a deep link may preselect
+var validPromptModes = map[string]bool{"dev": true, "architect": true, "debug": true, "tasks": true}
+
+// resolveProjectDir validates a path to be opened as a project
+// Archives are mounted as read-only projects (see archive_project.go).
+//
+// Parameters:
+//   - path: Path to a directory or archive (symlinks are resolved)
+//
+// Returns:
+//   - string: Absolute, symlink-free project root
+//   - error: Error if the path does not exist or is neither a directory nor an archive
+func resolveProjectDir(path string) (string, error) {
+	if path == "" {
+		return "", fmt.Errorf("no path given")
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("invalid path %s: %w", path, err)
+	}
+	resolved, err := filepath.EvalSymlinks(absPath)
+	if err != nil {
+		return "", fmt.Errorf("cannot resolve %s: %w", path, err)
+	}
+	info, err := os.Stat(resolved)
+	if err != nil {
+		return "", fmt.Errorf("cannot access %s: %w", path, err)
+	}
+	if !info.IsDir() {
+		if isArchivePath(resolved) {
+			return resolveArchiveProject(resolved, false)
+		}
+		return "", fmt.Errorf("%s is a file, not a folder", filepath.Base(path))
+	}
+	return resolved, nil
+}
+
+// openProject sets a validated folder as the project root in the frontend and starts
+// listing it in the background, so the tree is ready when the file screen asks for it
+//
+// Parameters:
+//   - path: Folder to open
+//   - source: Where the request came from (drop, cli, deeplink, ...)
+//   - mode: Prompt mode to preselect (empty to keep the current one)
+//
+// Returns:
+//   - error: Error if the folder is not valid
+func (a *App) openProject(path, source, mode string) error {
+	rootDir, err := resolveProjectDir(path)
+	if err == nil {
+		err = a.checkAllowedPath(rootDir)
+	}
+	if err != nil {
+		runtime.LogWarningf(a.ctx, "Rejected project from %s: %v", source, err)
+		runtime.EventsEmit(a.ctx, "projectOpenRejected", map[string]interface{}{
+			"path":   path,
+			"source": source,
+			"error":  err.Error(),
+		})
+		return err
+	}
+
+	runtime.LogInfof(a.ctx, "Opening project %s (from %s)", rootDir, source)
+	runtime.EventsEmit(a.ctx, "projectOpened", map[string]interface{}{
+		"path":   rootDir,
+		"source": source,
+		"mode":   mode,
+	})
+	go func() {
+		if _, err := a.ListFiles(rootDir); err != nil {
+			runtime.LogWarningf(a.ctx, "Failed to list opened project %s: %v", rootDir, err)
+		}
+	}()
+	return nil
+}
+
+// handleFileDrop is registered with runtime.OnFileDrop
+// Only one folder can be the project root: the first dropped directory or archive wins, other files are rejected.
+func (a *App) handleFileDrop(x, y int, paths []string) {
+	if len(paths) == 0 {
+		return
+	}
+	var firstErr error
+	for _, path := range paths {
+		rootDir, err := resolveProjectDir(path)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		a.openProject(rootDir, "drop", "")
+		return
+	}
+
+	runtime.LogWarningf(a.ctx, "Dropped items contain no folder: %v", firstErr)
+	runtime.EventsEmit(a.ctx, "projectOpenRejected", map[string]interface{}{
+		"path":   paths[0],
+		"source": "drop",
+		"error":  fmt.Sprintf("drop a folder to open it as a project (%v)", firstErr),
+	})
+}
+
+// parseOpenArgument interprets a launch argument as a project to open
+//
+// Parameters:
+//   - arg: A folder path (relative to workingDir) or a shotgun://open?path=...&mode=... link
+//   - workingDir: Directory relative paths are resolved against
+//
+// Returns:
+//   - *openRequest: Project to open, or nil if the argument is not one (e.g. a flag)
+//   - error: Error if the argument is a malformed deep link
+func parseOpenArgument(arg, workingDir string) (*openRequest, error) {
+	arg = strings.TrimSpace(arg)
+	if arg == "" || strings.HasPrefix(arg, "-") {
+		return nil, nil
+	}
+
+	if strings.HasPrefix(strings.ToLower(arg), deepLinkScheme+"://") {
+		link, err := url.Parse(arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid link %s: %w", arg, err)
+		}
+		action := link.Host
+		if action == "" {
+			action = strings.Trim(link.Path, "/")
+		}
+		if action == "focus" {
+			return nil, nil // Only brings the window to the front (clicked notifications)
+		}
+		if action != "open" {
+			return nil, fmt.Errorf("unsupported link action: %s", action)
+		}
+		query := link.Query()
+		path := query.Get("path")
+		if path == "" {
+			return nil, fmt.Errorf("link has no path: %s", arg)
+		}
+		mode := query.Get("mode")
+		if mode != "" && !validPromptModes[mode] {
+			return nil, fmt.Errorf("unknown mode in link: %s", mode)
+		}
+		return &openRequest{path: path, source: "deeplink", mode: mode}, nil
+	}
+
+	path := arg
+	if !filepath.IsAbs(path) && workingDir != "" {
+		path = filepath.Join(workingDir, path)
+	}
+	return &openRequest{path: path, source: "cli"}, nil
+}
+
+// handleLaunchArgs opens the first project given in launch arguments
+// Requests that arrive before the frontend is ready are kept until domReady.
+func (a *App) handleLaunchArgs(args []string, workingDir string) {
+	for _, arg := range args {
+		req, err := parseOpenArgument(arg, workingDir)
+		if err != nil {
+			runtime.LogWarningf(a.ctx, "Ignoring launch argument: %v", err)
+			continue
+		}
+		if req == nil {
+			continue
+		}
+		a.requestOpen(*req)
+		return
+	}
+}
+
+// requestOpen opens a project now, or once the frontend is ready
+func (a *App) requestOpen(req openRequest) {
+	a.openMu.Lock()
+	if !a.frontendReady {
+		a.pendingOpen = &req
+		a.openMu.Unlock()
+		return
+	}
+	a.openMu.Unlock()
+	a.openProject(req.path, req.source, req.mode)
+}
+
+// domReady is called by Wails once the frontend has loaded; pending open requests are delivered
+func (a *App) domReady(ctx context.Context) {
+	a.openMu.Lock()
+	a.frontendReady = true
+	pending := a.pendingOpen
+	a.pendingOpen = nil
+	a.openMu.Unlock()
+
+	if pending != nil {
+		a.openProject(pending.path, pending.source, pending.mode)
+	}
+}
+
+// handleSecondInstance receives the arguments of a second launch from the single-instance lock,
+// brings the window to the front and opens the project they name
+func (a *App) handleSecondInstance(data options.SecondInstanceData) {
+	runtime.LogInfof(a.ctx, "Second instance launched with args: %v", data.Args)
+	a.focusWindow()
+	a.handleLaunchArgs(data.Args, data.WorkingDirectory)
+}
+
+// handleURLOpen receives shotgun:// links on macOS, where they are not passed as arguments
+func (a *App) handleURLOpen(link string) {
+	a.focusWindow()
+	a.handleLaunchArgs([]string{link}, "")
+}
+
+// focusWindow brings the main window to the front
+func (a *App) focusWindow() {
+	runtime.WindowUnminimise(a.ctx)
+	runtime.WindowShow(a.ctx)
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- Project Statistics ---
+//
+// GetProjectStats diagnoses why a project's context is large: what is included, which files
+// dominate it, how much is binary, and what the ignore rules already keep out.
+
+const (
+	projectStatsLargestFiles = 20 // Number of largest files reported
+	projectStatsDeepestPaths = 10 // Number of deepest paths reported
+)
+
+// FileSizeEntry is one of the largest included files
+type FileSizeEntry struct {
+	Path            string `json:"path"`            // Relative path (forward slashes)
+	Bytes           int64  `json:"bytes"`           // File size
+	EstimatedTokens int    `json:"estimatedTokens"` // Approximate tokens (bytes / 4, 0 for binary files)
+	IsBinary        bool   `json:"isBinary"`        // Binary files are skipped in generated context
+}
+
+// PathDepthEntry is one of the most deeply nested included paths
+type PathDepthEntry struct {
+	Path  string `json:"path"`  // Relative path (forward slashes)
+	Depth int    `json:"depth"` // Number of path components
+}
+
+// IgnoreCoverage counts the entries kept out of the context, by rule
+// Ignored directories are counted once and not descended into.
+type IgnoreCoverage struct {
+	GitignoredFiles    int `json:"gitignoredFiles"`    // Files matched by .gitignore
+	GitignoredDirs     int `json:"gitignoredDirs"`     // Directories matched by .gitignore
+	CustomIgnoredFiles int `json:"customIgnoredFiles"` // Files matched by custom ignore rules only
+	CustomIgnoredDirs  int `json:"customIgnoredDirs"`  // Directories matched by custom ignore rules only
+	HiddenDotfiles     int `json:"hiddenDotfiles"`     // Dotfiles and dot-directories hidden by the dotfile setting
+	ForceIncluded      int `json:"forceIncluded"`      // Ignored entries included by a force-include override
+}
+
+// ProjectStats is the result of GetProjectStats
+type ProjectStats struct {
+	RootDir         string           `json:"rootDir"`         // Project root directory
+	IncludedFiles   int              `json:"includedFiles"`   // Files that generation would include
+	IncludedDirs    int              `json:"includedDirs"`    // Directories that generation would list
+	IncludedBytes   int64            `json:"includedBytes"`   // Total size of the included files
+	EstimatedTokens int              `json:"estimatedTokens"` // Approximate tokens of the included text files
+	BinaryFiles     int              `json:"binaryFiles"`     // Included files detected as binary
+	BinaryBytes     int64            `json:"binaryBytes"`     // Total size of the binary files
+	BinaryRatio     float64          `json:"binaryRatio"`     // BinaryFiles / IncludedFiles, 0-1
+	MaxDepth        int              `json:"maxDepth"`        // Depth of the most nested included path
+	LargestFiles    []FileSizeEntry  `json:"largestFiles"`    // Largest included files, largest first
+	DeepestPaths    []PathDepthEntry `json:"deepestPaths"`    // Most nested included paths, deepest first
+	IgnoreCoverage  IgnoreCoverage   `json:"ignoreCoverage"`  // Entries kept out by ignore rules
+}
+
+// ============================================================================
+// Project Statistics Methods (Wails-bound)
+// ============================================================================
+
+// GetProjectStats walks a project with the current ignore settings and reports file and
+// directory counts, the largest files, the deepest paths, the binary ratio and ignore coverage
+//
+// Parameters:
+//   - rootDir: Project root directory
+//
+// Returns:
+//   - ProjectStats: Statistics of the project
+//   - error: Error if the directory does not exist or the walk fails
+func (a *App) GetProjectStats(rootDir string) (ProjectStats, error) {
+	if err := a.validateContentRoot(rootDir); err != nil {
+		return ProjectStats{}, err
+	}
+
+	stats := ProjectStats{RootDir: rootDir}
+	opts := a.newTreeBuildOptions(rootDir, a.compileProjectGitignore(rootDir))
+	var files []FileSizeEntry
+	var paths []PathDepthEntry
+
+	err := a.projectFS.WalkDir(rootDir, func(path string, d fs.DirEntry, walkErr error) error {
+		if ctxErr := a.ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if walkErr != nil {
+			if d != nil && d.IsDir() && path != rootDir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if path == rootDir {
+			return nil
+		}
+
+		relPath, _ := filepath.Rel(rootDir, path)
+		isDir := d.IsDir()
+		skip := func() error {
+			if isDir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		isGitignored, isCustomIgnored := opts.ignoreFlags(relPath, isDir)
+		hidden := opts.hidesDotfile(d.Name())
+		parentGitignored, parentCustomIgnored, parentHidden := opts.ancestorIgnoreFlags(filepath.Dir(relPath))
+		isGitignored = isGitignored || parentGitignored
+		isCustomIgnored = isCustomIgnored || parentCustomIgnored
+		hidden = hidden || parentHidden
+		if isGitignored || isCustomIgnored || hidden {
+			if isForceIncluded(opts.forceIncludes, relPath) {
+				stats.IgnoreCoverage.ForceIncluded++
+			} else if !opts.overridesIgnore(relPath, isDir) { // Directories leading to force-included paths are walked
+				switch {
+				case isGitignored && isDir:
+					stats.IgnoreCoverage.GitignoredDirs++
+				case isGitignored:
+					stats.IgnoreCoverage.GitignoredFiles++
+				case isCustomIgnored && isDir:
+					stats.IgnoreCoverage.CustomIgnoredDirs++
+				case isCustomIgnored:
+					stats.IgnoreCoverage.CustomIgnoredFiles++
+				default:
+					stats.IgnoreCoverage.HiddenDotfiles++
+				}
+				return skip()
+			}
+		}
+
+		relSlash := filepath.ToSlash(relPath)
+		depth := strings.Count(relSlash, "/") + 1
+		if depth > stats.MaxDepth {
+			stats.MaxDepth = depth
+		}
+		paths = append(paths, PathDepthEntry{Path: relSlash, Depth: depth})
+
+		if isDir {
+			stats.IncludedDirs++
+			return nil
+		}
+
+		stats.IncludedFiles++
+		var size int64
+		if info, err := d.Info(); err == nil {
+			size = info.Size()
+		}
+		stats.IncludedBytes += size
+
+		isBinary, _ := a.isBinaryFile(path)
+		if isBinary {
+			stats.BinaryFiles++
+			stats.BinaryBytes += size
+		}
+		entry := FileSizeEntry{Path: relSlash, Bytes: size, IsBinary: isBinary}
+		if !isBinary {
+			entry.EstimatedTokens = int(size / 4)
+		}
+		files = append(files, entry)
+		return nil
+	})
+	if err != nil {
+		return ProjectStats{}, fmt.Errorf("failed to compute project statistics: %w", err)
+	}
+
+	stats.EstimatedTokens = int((stats.IncludedBytes - stats.BinaryBytes) / 4)
+	if stats.IncludedFiles > 0 {
+		stats.BinaryRatio = float64(stats.BinaryFiles) / float64(stats.IncludedFiles)
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		if files[i].Bytes != files[j].Bytes {
+			return files[i].Bytes > files[j].Bytes
+		}
+		return files[i].Path < files[j].Path
+	})
+	if len(files) > projectStatsLargestFiles {
+		files = files[:projectStatsLargestFiles]
+	}
+	stats.LargestFiles = append([]FileSizeEntry{}, files...)
+
+	sort.Slice(paths, func(i, j int) bool {
+		if paths[i].Depth != paths[j].Depth {
+			return paths[i].Depth > paths[j].Depth
+		}
+		return paths[i].Path < paths[j].Path
+	})
+	if len(paths) > projectStatsDeepestPaths {
+		paths = paths[:projectStatsDeepestPaths]
+	}
+	stats.DeepestPaths = append([]PathDepthEntry{}, paths...)
+
+	runtime.LogInfof(a.ctx, "Project stats for %s: %d files (%d bytes), %d binary, %d ignored dirs",
+		rootDir, stats.IncludedFiles, stats.IncludedBytes, stats.BinaryFiles,
+		stats.IgnoreCoverage.GitignoredDirs+stats.IgnoreCoverage.CustomIgnoredDirs)
+	return stats, nil
+}
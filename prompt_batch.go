@@ -0,0 +1,236 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- Prompt Batches ---
+//
+// A prompt_batch job sends many independent prompts (e.g. "review each of these 40 files") as
+// llm_batch_item child jobs. Children show up in the job queue with the batch as their parent
+// and can be cancelled one by one; cancelling the batch cancels the children still running.
+// The batch limits how many children run at once and how fast they start, so one sweep does
+// not trip the provider's rate limits, and it reports combined progress, tokens and cost.
+
+const (
+	defaultBatchConcurrency = 3  // Prompts in flight at once
+	maxBatchConcurrency     = 16 // Upper bound for the concurrency option
+)
+
+// PromptBatchOptions configures RunPromptBatchWithOptions (and are the prompt_batch job parameters)
+type PromptBatchOptions struct {
+	Provider          string   `json:"provider"`          // LLM provider (google, openai, anthropic, custom)
+	APIKey            string   `json:"apiKey"`            // API key for the provider
+	Model             string   `json:"model"`             // Model name (empty for provider default)
+	BaseURL           string   `json:"baseURL"`           // Base URL for the custom provider
+	Prompts           []string `json:"prompts"`           // Independent prompts, each sent as its own request
+	Concurrency       int      `json:"concurrency"`       // Prompts in flight at once (0 for the default of 3)
+	RequestsPerMinute int      `json:"requestsPerMinute"` // Maximum requests started per minute (0 = unlimited)
+}
+
+// batchItemParams are the parameters of an llm_batch_item job
+type batchItemParams struct {
+	BatchID string     `json:"batchId"` // ID of the prompt_batch job
+	Index   int        `json:"index"`   // Position of the prompt in the batch
+	Request LLMRequest `json:"request"` // Request to send
+}
+
+// PromptBatchItem is the outcome of one prompt of a batch
+type PromptBatchItem struct {
+	Index      int     `json:"index"`      // Position of the prompt in the batch
+	JobID      string  `json:"jobId"`      // ID of the llm_batch_item job (empty if never started)
+	Status     string  `json:"status"`     // completed, failed, cancelled or skipped
+	Content    string  `json:"content"`    // Response text (empty unless completed)
+	Error      string  `json:"error"`      // Failure details
+	TokensUsed int     `json:"tokensUsed"` // Tokens used by the request
+	Cost       float64 `json:"cost"`       // Estimated cost in USD
+}
+
+// PromptBatchResult is the result of a prompt_batch job, also emitted as progress
+type PromptBatchResult struct {
+	BatchID    string            `json:"batchId"`    // ID of the prompt_batch job
+	Total      int               `json:"total"`      // Number of prompts
+	Completed  int               `json:"completed"`  // Prompts answered
+	Failed     int               `json:"failed"`     // Prompts failed, cancelled or skipped
+	TokensUsed int               `json:"tokensUsed"` // Tokens used by all answered prompts
+	Cost       float64           `json:"cost"`       // Estimated cost of all answered prompts in USD
+	Items      []PromptBatchItem `json:"items"`      // Per-prompt outcomes in prompt order
+}
+
+// executePromptBatchJob implements the prompt_batch job type
+func (a *App) executePromptBatchJob(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var p PromptBatchOptions
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, fmt.Errorf("invalid prompt_batch parameters: %w", err)
+	}
+	if len(p.Prompts) == 0 {
+		return nil, fmt.Errorf("no prompts given")
+	}
+	concurrency := p.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultBatchConcurrency
+	}
+	if concurrency > maxBatchConcurrency {
+		concurrency = maxBatchConcurrency
+	}
+	var interval time.Duration
+	if p.RequestsPerMinute > 0 {
+		interval = time.Minute / time.Duration(p.RequestsPerMinute)
+	}
+
+	batchID := jobIDFromContext(ctx)
+	result := &PromptBatchResult{BatchID: batchID, Total: len(p.Prompts), Items: make([]PromptBatchItem, len(p.Prompts))}
+	for i := range result.Items {
+		result.Items[i] = PromptBatchItem{Index: i, Status: "skipped"}
+	}
+	var mu sync.Mutex // Protects result
+	report := func(index int, item PromptBatchItem) {
+		mu.Lock()
+		defer mu.Unlock()
+		result.Items[index] = item
+		if item.Status == "completed" {
+			result.Completed++
+			result.TokensUsed += item.TokensUsed
+			result.Cost += item.Cost
+		} else {
+			result.Failed++
+		}
+		a.jobQueue.setJobProgress(batchID, float64(result.Completed+result.Failed)/float64(result.Total)*100)
+		runtime.EventsEmit(a.ctx, "promptBatchProgress", *result)
+	}
+
+	slots := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	var lastStart time.Time
+	for i, prompt := range p.Prompts {
+		// Wait for a free slot, then for the rate limit
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() == nil && interval > 0 && !lastStart.IsZero() {
+			select {
+			case <-time.After(time.Until(lastStart.Add(interval))):
+			case <-ctx.Done():
+			}
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		lastStart = time.Now()
+
+		childID, err := a.jobQueue.Enqueue("llm_batch_item", batchItemParams{
+			BatchID: batchID,
+			Index:   i,
+			Request: LLMRequest{Provider: p.Provider, APIKey: p.APIKey, Model: p.Model, BaseURL: p.BaseURL, Prompt: prompt},
+		})
+		if err != nil {
+			<-slots
+			report(i, PromptBatchItem{Index: i, Status: "failed", Error: err.Error()})
+			continue
+		}
+		a.jobQueue.setJobParent(childID, batchID)
+
+		wg.Add(1)
+		go func(index int, childID string) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			item := PromptBatchItem{Index: index, JobID: childID}
+			job, err := a.jobQueue.waitForJob(ctx, childID)
+			if err != nil {
+				a.jobQueue.CancelJob(childID) // The batch was cancelled
+				item.Status, item.Error = "cancelled", err.Error()
+				report(index, item)
+				return
+			}
+			item.Status, item.Error = job.Status, job.Error
+			if resp, ok := job.Result.(*LLMResponse); ok && job.Status == "completed" {
+				item.Content, item.TokensUsed, item.Cost = resp.Content, resp.TokensUsed, resp.Cost
+			}
+			report(index, item)
+		}(i, childID)
+	}
+	wg.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	// Prompts never started count as failed
+	started := result.Completed + result.Failed
+	result.Failed += result.Total - started
+
+	runtime.LogInfof(a.ctx, "Prompt batch %s: %d/%d answered, %d tokens, $%.4f", batchID, result.Completed, result.Total, result.TokensUsed, result.Cost)
+	a.notify(notifyLLMResponse, "Prompt batch finished",
+		fmt.Sprintf("%d of %d prompts answered ($%.4f)", result.Completed, result.Total, result.Cost))
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// executeBatchItemJob implements the llm_batch_item job type
+// Unlike llm_call, the response is not emitted as llmResponseReceived: the batch collects it.
+func (a *App) executeBatchItemJob(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var p batchItemParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, fmt.Errorf("invalid llm_batch_item parameters: %w", err)
+	}
+	project := a.tagJobProject(ctx, p.Request.RootDir)
+	resp, err := NewLLMClient(a).CallLLM(ctx, p.Request)
+	if err != nil {
+		return nil, err
+	}
+	a.recordUsage(llmCallEvent(project, resp))
+	return resp, nil
+}
+
+// ============================================================================
+// Prompt Batch Methods (Wails-bound)
+// ============================================================================
+
+// RunPromptBatch sends independent prompts as a background batch with the default limits
+// Progress is emitted as "promptBatchProgress" events; the job result is a PromptBatchResult.
+//
+// Parameters:
+//   - prompts: Prompts to send, each as its own request
+//   - provider: LLM provider (google, openai, anthropic, custom)
+//   - apiKey: API key for the provider
+//   - model: Model name (empty for provider default)
+//
+// Returns:
+//   - string: Job ID of the prompt_batch job
+//   - error: Error if there are no prompts or the job cannot be enqueued
+func (a *App) RunPromptBatch(prompts []string, provider, apiKey, model string) (string, error) {
+	return a.RunPromptBatchWithOptions(PromptBatchOptions{Provider: provider, APIKey: apiKey, Model: model, Prompts: prompts})
+}
+
+// RunPromptBatchWithOptions sends independent prompts as a background batch
+//
+// Parameters:
+//   - opts: Provider settings, prompts, concurrency and requests per minute
+//
+// Returns:
+//   - string: Job ID of the prompt_batch job
+//   - error: Error if there are no prompts or the job cannot be enqueued
+func (a *App) RunPromptBatchWithOptions(opts PromptBatchOptions) (string, error) {
+	if a.jobQueue == nil {
+		return "", fmt.Errorf("job queue not initialized")
+	}
+	prompts := make([]string, 0, len(opts.Prompts))
+	for _, prompt := range opts.Prompts {
+		if strings.TrimSpace(prompt) != "" {
+			prompts = append(prompts, prompt)
+		}
+	}
+	if len(prompts) == 0 {
+		return "", fmt.Errorf("no prompts given")
+	}
+	opts.Prompts = prompts
+	return a.jobQueue.Enqueue("prompt_batch", opts)
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// --- Prompt Preview ---
+//
+// ComposePromptPreview assembles a prompt exactly as GeneratePrompt does, but returns it split
+// into labeled sections with a token estimate for each, so users can see where the budget goes
+// before sending. Previews are not recorded in the usage statistics.
+
+// PromptSection is one labeled part of an assembled prompt
+type PromptSection struct {
+	Label   string  `json:"label"`   // instructions, context, task or rules
+	Title   string  `json:"title"`   // Heading shown in the preview
+	Text    string  `json:"text"`    // Text of the section, as it appears in the prompt
+	Start   int     `json:"start"`   // Byte offset of the section in the prompt
+	End     int     `json:"end"`     // Byte offset just after the section
+	Tokens  int     `json:"tokens"`  // Estimated tokens of the section
+	Percent float64 `json:"percent"` // Share of the prompt's tokens (0-100)
+}
+
+// PromptPreview is an assembled prompt split into sections
+type PromptPreview struct {
+	Mode        string          `json:"mode"`        // Mode used
+	Sections    []PromptSection `json:"sections"`    // Sections in prompt order
+	TotalTokens int             `json:"totalTokens"` // Estimated tokens of the whole prompt
+	TotalBytes  int             `json:"totalBytes"`  // Size of the whole prompt
+}
+
+// resolveContextRef returns the generated context a reference points to
+// A reference is "" or "last" (the most recent generation) or "snapshot:<id>".
+func (a *App) resolveContextRef(contextRef string) (string, error) {
+	switch ref := strings.TrimSpace(contextRef); {
+	case ref == "" || ref == "last":
+		last := a.latestGeneration()
+		if last == nil {
+			return "", fmt.Errorf("no context has been generated yet")
+		}
+		return last.content, nil
+	case strings.HasPrefix(ref, "snapshot:"):
+		id := strings.TrimPrefix(ref, "snapshot:")
+		if id == "" || strings.ContainsAny(id, `/\.`) {
+			return "", fmt.Errorf("invalid snapshot ID: %s", id)
+		}
+		// Snapshot IDs are unique across projects
+		matches, _ := filepath.Glob(filepath.Join(filepath.Dir(a.configPath), "snapshots", "*", id, "context.txt"))
+		if len(matches) == 0 {
+			return "", fmt.Errorf("snapshot not found: %s", id)
+		}
+		content, err := os.ReadFile(matches[0])
+		if err != nil {
+			return "", fmt.Errorf("failed to read snapshot content: %w", err)
+		}
+		return string(content), nil
+	default:
+		return "", fmt.Errorf("unknown context reference: %s", contextRef)
+	}
+}
+
+// ============================================================================
+// Prompt Preview Methods (Wails-bound)
+// ============================================================================
+
+// ComposePromptPreview assembles a prompt and splits it into sections with token counts
+//
+// Parameters:
+//   - mode: Prompt mode (dev, architect, debug, review; defaults to dev)
+//   - task: Task description
+//   - rules: Additional rules (omitted from the prompt if empty)
+//   - contextRef: "" or "last" for the most recent generated context, "snapshot:<id>" for a snapshot
+//
+// Returns:
+//   - PromptPreview: Sections of the prompt in order, with token estimates
+//   - error: Error if the context reference cannot be resolved
+func (a *App) ComposePromptPreview(mode, task, rules, contextRef string) (PromptPreview, error) {
+	context, err := a.resolveContextRef(contextRef)
+	if err != nil {
+		return PromptPreview{}, err
+	}
+
+	mode, sections := a.promptSections(context, mode, task, rules)
+	preview := PromptPreview{Mode: mode, Sections: sections}
+	for i := range preview.Sections {
+		section := &preview.Sections[i]
+		section.Start = preview.TotalBytes
+		preview.TotalBytes += len(section.Text)
+		section.End = preview.TotalBytes
+		section.Tokens = a.EstimateTokens(section.Text)
+		preview.TotalTokens += section.Tokens
+	}
+	if preview.TotalTokens > 0 {
+		for i := range preview.Sections {
+			preview.Sections[i].Percent = float64(preview.Sections[i].Tokens) * 100 / float64(preview.TotalTokens)
+		}
+	}
+	return preview, nil
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// --- Prompt Split Preview ---
+//
+// PreviewPromptSplit shows how a composed prompt would be cut into parts of at most maxTokens
+// (for a multi-part paste or a map-reduce run) without sending anything. Parts end at the
+// best boundary that fits: after a </file> block, else at a blank line, else at a line break,
+// else mid-line (a worse boundary is used when the better one would leave the part less than
+// half full). Token counts use the same estimate as EstimateTokens.
+
+const splitPreviewSnippetLength = 80 // Characters of the first and last line shown per part
+
+// splitFileOpenRegex finds the file blocks of generated context
+var splitFileOpenRegex = regexp.MustCompile(`<file path="([^"]*)"`)
+
+// splitBoundaries are the places a part can end, best first
+var splitBoundaries = []struct{ sep, kind string }{
+	{"</file>\n", "file"}, {"\n\n", "paragraph"}, {"\n", "line"},
+}
+
+// PromptPart describes one part of a split prompt
+type PromptPart struct {
+	Index      int      `json:"index"`      // Position of the part (0-based)
+	Start      int      `json:"start"`      // Byte offset where the part starts
+	End        int      `json:"end"`        // Byte offset where the part ends (exclusive)
+	StartLine  int      `json:"startLine"`  // First line of the part (1-based)
+	EndLine    int      `json:"endLine"`    // Last line of the part (1-based)
+	Tokens     int      `json:"tokens"`     // Estimated tokens of the part
+	Boundary   string   `json:"boundary"`   // How the part ends: file, paragraph, line, hard or end
+	Files      []string `json:"files"`      // Paths of the file blocks starting in the part
+	SplitsFile string   `json:"splitsFile"` // File block cut at the end of the part (empty if none)
+	FirstLine  string   `json:"firstLine"`  // Start of the part's first line
+	LastLine   string   `json:"lastLine"`   // Start of the part's last line
+}
+
+// PromptSplitPreview is the result of PreviewPromptSplit
+type PromptSplitPreview struct {
+	TotalTokens int          `json:"totalTokens"` // Estimated tokens of the whole prompt
+	MaxTokens   int          `json:"maxTokens"`   // Token limit per part
+	Parts       []PromptPart `json:"parts"`       // Parts in order
+}
+
+// splitPromptOffsets returns the end offsets of the parts of text and the boundary kind of each
+func splitPromptOffsets(text string, maxBytes int) ([]int, []string) {
+	var ends []int
+	var kinds []string
+	start := 0
+	for len(text)-start > maxBytes {
+		window := text[start : start+maxBytes]
+		end, kind := -1, ""
+		// The best boundary kind wins if it keeps the part at least half full
+		for _, b := range splitBoundaries {
+			i := strings.LastIndex(window, b.sep)
+			if i < 0 {
+				continue
+			}
+			if end < 0 {
+				end, kind = start+i+len(b.sep), b.kind
+			}
+			if i+len(b.sep) >= maxBytes/2 {
+				end, kind = start+i+len(b.sep), b.kind
+				break
+			}
+		}
+		if end < 0 {
+			end, kind = start+maxBytes, "hard"
+			for end > start+1 && !utf8.RuneStart(text[end]) {
+				end--
+			}
+		}
+		ends = append(ends, end)
+		kinds = append(kinds, kind)
+		start = end
+	}
+	ends = append(ends, len(text))
+	kinds = append(kinds, "end")
+	return ends, kinds
+}
+
+// splitPromptParts cuts text into parts of at most maxBytes and describes each of them
+func (a *App) splitPromptParts(context string, maxBytes int) []PromptPart {
+	parts := []PromptPart{}
+	fileOpens := splitFileOpenRegex.FindAllStringSubmatchIndex(context, -1)
+	ends, kinds := splitPromptOffsets(context, maxBytes)
+	start, line := 0, 1
+	for i, end := range ends {
+		part := context[start:end]
+		lines := strings.Split(strings.TrimSuffix(part, "\n"), "\n")
+		p := PromptPart{
+			Index:     i,
+			Start:     start,
+			End:       end,
+			StartLine: line,
+			EndLine:   line + len(lines) - 1,
+			Tokens:    a.EstimateTokens(part),
+			Boundary:  kinds[i],
+			Files:     []string{},
+			FirstLine: splitSnippet(lines[0]),
+			LastLine:  splitSnippet(lines[len(lines)-1]),
+		}
+		for _, m := range fileOpens {
+			if m[0] >= start && m[0] < end {
+				p.Files = append(p.Files, fileBlockPath(context[m[2]:m[3]]))
+			}
+		}
+		// A cut inside a file block leaves its opening tag without a closing tag before the cut
+		if kinds[i] != "end" && kinds[i] != "file" {
+			if open := strings.LastIndex(context[:end], "<file path=\""); open >= 0 && !strings.Contains(context[open:end], "</file>") {
+				if m := splitFileOpenRegex.FindStringSubmatch(context[open:]); m != nil {
+					p.SplitsFile = fileBlockPath(m[1])
+				}
+			}
+		}
+		parts = append(parts, p)
+		line += strings.Count(part, "\n")
+		start = end
+	}
+	return parts
+}
+
+// splitSnippet returns the start of a line, shortened for display
+func splitSnippet(line string) string {
+	line = strings.TrimRight(line, "\r")
+	if utf8.RuneCountInString(line) <= splitPreviewSnippetLength {
+		return line
+	}
+	runes := []rune(line)
+	return string(runes[:splitPreviewSnippetLength]) + "…"
+}
+
+// ============================================================================
+// Prompt Split Methods (Wails-bound)
+// ============================================================================
+
+// PreviewPromptSplit shows how a prompt would be split into parts of at most maxTokens
+//
+// Parameters:
+//   - context: Composed prompt (or generated context) to split
+//   - maxTokens: Token limit per part
+//
+// Returns:
+//   - PromptSplitPreview: Part boundaries, token counts and the files in each part
+//   - error: Error if maxTokens is not positive
+func (a *App) PreviewPromptSplit(context string, maxTokens int) (PromptSplitPreview, error) {
+	if maxTokens <= 0 {
+		return PromptSplitPreview{}, fmt.Errorf("maxTokens must be positive")
+	}
+	preview := PromptSplitPreview{TotalTokens: a.EstimateTokens(context), MaxTokens: maxTokens, Parts: []PromptPart{}}
+	if context == "" {
+		return preview, nil
+	}
+
+	preview.Parts = a.splitPromptParts(context, maxTokens*4) // EstimateTokens counts 4 bytes per token
+	return preview, nil
+}
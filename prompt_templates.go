@@ -0,0 +1,350 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- Shareable Prompt Templates ---
+//
+// A prompt template is a named mode with its own instructions, default rules and model
+// defaults. Composing a prompt in that mode uses the template's instructions instead of the
+// built-in ones ({{changeFormat}} is replaced by the configured code change format), and its
+// rules when the user gives none. A template named like a built-in mode (dev, architect,
+// debug, tasks) replaces that mode's instructions.
+//
+// Teams publish their setups as a template bundle, a JSON file that can live in a repo:
+//
+//	{"format": "shotgun-templates", "version": 1, "templates": [
+//	  {"name": "review", "version": 3, "description": "...", "instructions": "...", "rules": "...",
+//	   "defaults": {"provider": "anthropic", "model": "..."}}]}
+//
+// Members import a bundle from its text or from a URL (GitHub blob URLs are read raw). Every
+// template carries a version; when a template of the same name exists, the conflict policy
+// decides: "newer" (the default) only replaces it with a higher version, "overwrite" always
+// replaces it, "skip" never does. The import result lists what was added, updated and skipped.
+
+const (
+	templateBundleFormat    = "shotgun-templates"
+	templateBundleVersion   = 1
+	templateFetchTimeout    = 30 * time.Second
+	templateFetchMaxBytes   = 1 << 20
+	templateChangeFormatVar = "{{changeFormat}}"
+)
+
+// templateNameRegex restricts template names to what works as a mode name everywhere
+var templateNameRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,39}$`)
+
+// templateHTTPClient fetches template bundles
+var templateHTTPClient = &http.Client{Timeout: templateFetchTimeout, Transport: outboundTransport}
+
+// PromptTemplate is a named prompt mode that can be shared
+type PromptTemplate struct {
+	Name         string             `json:"name"`                  // Mode name (lowercase letters, digits, - and _)
+	Version      int                `json:"version"`               // Version, raised by the author on every change
+	Description  string             `json:"description,omitempty"` // What the template is for
+	Instructions string             `json:"instructions"`          // Mode instructions ({{changeFormat}} is replaced)
+	Rules        string             `json:"rules,omitempty"`       // Rules used when the prompt has none
+	Defaults     *ModeModelDefaults `json:"defaults,omitempty"`    // LLM defaults of the mode (see mode_defaults.go)
+}
+
+// TemplateBundle is the export format of prompt templates
+type TemplateBundle struct {
+	Format     string           `json:"format"`     // Always "shotgun-templates"
+	Version    int              `json:"version"`    // Version of the bundle format
+	ExportedAt time.Time        `json:"exportedAt"` // When the bundle was exported
+	Templates  []PromptTemplate `json:"templates"`  // Templates, by name
+}
+
+// TemplateConflict is an imported template that did not replace the existing one
+type TemplateConflict struct {
+	Name            string `json:"name"`            // Template name
+	LocalVersion    int    `json:"localVersion"`    // Version of the existing template
+	ImportedVersion int    `json:"importedVersion"` // Version of the imported template
+	Reason          string `json:"reason"`          // Why it was skipped
+}
+
+// TemplateImportResult is what an import changed
+type TemplateImportResult struct {
+	Source   string             `json:"source"`   // URL or "text"
+	Imported []string           `json:"imported"` // Templates that were new
+	Updated  []string           `json:"updated"`  // Existing templates that were replaced
+	Skipped  []TemplateConflict `json:"skipped"`  // Existing templates that were kept
+}
+
+// validateTemplate checks a template before it is saved
+func validateTemplate(t PromptTemplate) error {
+	if !templateNameRegex.MatchString(t.Name) {
+		return fmt.Errorf("invalid template name %q (use up to 40 lowercase letters, digits, - and _)", t.Name)
+	}
+	if strings.TrimSpace(t.Instructions) == "" {
+		return fmt.Errorf("template %s has no instructions", t.Name)
+	}
+	if t.Version < 0 {
+		return fmt.Errorf("template %s has a negative version", t.Name)
+	}
+	return nil
+}
+
+// templateInstructions returns the instructions and rules of a mode's template ("" if it has none)
+func (a *App) templateInstructions(mode, changeFormat string) (string, string) {
+	t, ok := a.settings.PromptTemplates[mode]
+	if !ok {
+		return "", ""
+	}
+	return strings.ReplaceAll(t.Instructions, templateChangeFormatVar, changeFormat), t.Rules
+}
+
+// storeTemplate saves a template and its mode defaults in the settings (not on disk)
+func (a *App) storeTemplate(t PromptTemplate) {
+	if a.settings.PromptTemplates == nil {
+		a.settings.PromptTemplates = make(map[string]PromptTemplate)
+	}
+	a.settings.PromptTemplates[t.Name] = t
+	if t.Defaults != nil {
+		if a.settings.ModeDefaults == nil {
+			a.settings.ModeDefaults = make(map[string]ModeModelDefaults)
+		}
+		a.settings.ModeDefaults[t.Name] = *t.Defaults
+	}
+}
+
+// parseTemplateBundle reads a bundle, or a single template, from JSON
+func parseTemplateBundle(data []byte) ([]PromptTemplate, error) {
+	var bundle TemplateBundle
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return nil, fmt.Errorf("invalid template JSON: %w", err)
+	}
+	if bundle.Format == "" && len(bundle.Templates) == 0 {
+		var single PromptTemplate
+		if err := json.Unmarshal(data, &single); err == nil && single.Name != "" {
+			return []PromptTemplate{single}, nil
+		}
+		return nil, fmt.Errorf("not a template bundle (missing format %q)", templateBundleFormat)
+	}
+	if bundle.Format != templateBundleFormat {
+		return nil, fmt.Errorf("unknown template bundle format: %s", bundle.Format)
+	}
+	if bundle.Version > templateBundleVersion {
+		return nil, fmt.Errorf("template bundle version %d is newer than supported (%d), update the app", bundle.Version, templateBundleVersion)
+	}
+	if len(bundle.Templates) == 0 {
+		return nil, fmt.Errorf("template bundle contains no templates")
+	}
+	return bundle.Templates, nil
+}
+
+// importTemplates adds parsed templates following a conflict policy and saves the settings
+func (a *App) importTemplates(templates []PromptTemplate, onConflict, source string) (TemplateImportResult, error) {
+	if onConflict == "" {
+		onConflict = "newer"
+	}
+	if onConflict != "newer" && onConflict != "overwrite" && onConflict != "skip" {
+		return TemplateImportResult{}, fmt.Errorf("unknown conflict policy: %s (use newer, overwrite or skip)", onConflict)
+	}
+	for _, t := range templates {
+		if err := validateTemplate(t); err != nil {
+			return TemplateImportResult{}, err
+		}
+	}
+
+	result := TemplateImportResult{Source: source, Imported: []string{}, Updated: []string{}, Skipped: []TemplateConflict{}}
+	for _, t := range templates {
+		existing, exists := a.settings.PromptTemplates[t.Name]
+		switch {
+		case !exists:
+			result.Imported = append(result.Imported, t.Name)
+		case onConflict == "skip":
+			result.Skipped = append(result.Skipped, TemplateConflict{t.Name, existing.Version, t.Version, "a template with this name exists"})
+			continue
+		case onConflict == "newer" && t.Version <= existing.Version:
+			reason := "same version installed"
+			if t.Version < existing.Version {
+				reason = "newer version installed"
+			}
+			result.Skipped = append(result.Skipped, TemplateConflict{t.Name, existing.Version, t.Version, reason})
+			continue
+		default:
+			result.Updated = append(result.Updated, t.Name)
+		}
+		a.storeTemplate(t)
+	}
+	if len(result.Imported)+len(result.Updated) > 0 {
+		if err := a.saveSettings(); err != nil {
+			return TemplateImportResult{}, fmt.Errorf("failed to save imported templates: %w", err)
+		}
+	}
+	runtime.LogInfof(a.ctx, "Imported templates from %s: %d new, %d updated, %d skipped",
+		source, len(result.Imported), len(result.Updated), len(result.Skipped))
+	return result, nil
+}
+
+// rawTemplateURL turns a GitHub blob URL into the URL of the raw file
+func rawTemplateURL(target *url.URL) *url.URL {
+	parts := strings.Split(strings.Trim(target.Path, "/"), "/")
+	if target.Host != "github.com" || len(parts) < 5 || parts[2] != "blob" {
+		return target
+	}
+	raw := *target
+	raw.Host = "raw.githubusercontent.com"
+	raw.Path = "/" + strings.Join(append(parts[:2], parts[3:]...), "/")
+	raw.RawQuery = ""
+	return &raw
+}
+
+// ============================================================================
+// Prompt Template Methods (Wails-bound)
+// ============================================================================
+
+// GetPromptTemplates returns the saved prompt templates, by name
+func (a *App) GetPromptTemplates() []PromptTemplate {
+	templates := make([]PromptTemplate, 0, len(a.settings.PromptTemplates))
+	for _, t := range a.settings.PromptTemplates {
+		templates = append(templates, t)
+	}
+	sort.Slice(templates, func(i, j int) bool { return templates[i].Name < templates[j].Name })
+	return templates
+}
+
+// SavePromptTemplate adds or replaces a prompt template and saves it
+//
+// Parameters:
+//   - template: The template (its defaults, if any, become the mode's LLM defaults)
+//
+// Returns:
+//   - error: Error if the template is invalid or the setting cannot be saved
+func (a *App) SavePromptTemplate(template PromptTemplate) error {
+	if err := validateTemplate(template); err != nil {
+		return err
+	}
+	a.storeTemplate(template)
+	if err := a.saveSettings(); err != nil {
+		return fmt.Errorf("failed to save prompt template: %w", err)
+	}
+	runtime.LogInfof(a.ctx, "Saved prompt template %s (version %d)", template.Name, template.Version)
+	return nil
+}
+
+// DeletePromptTemplate removes a prompt template (the mode's LLM defaults are kept)
+//
+// Parameters:
+//   - name: Template name
+//
+// Returns:
+//   - error: Error if no such template exists or the setting cannot be saved
+func (a *App) DeletePromptTemplate(name string) error {
+	if _, ok := a.settings.PromptTemplates[name]; !ok {
+		return fmt.Errorf("no prompt template named %s", name)
+	}
+	delete(a.settings.PromptTemplates, name)
+	if err := a.saveSettings(); err != nil {
+		return fmt.Errorf("failed to save prompt templates: %w", err)
+	}
+	runtime.LogInfof(a.ctx, "Deleted prompt template %s", name)
+	return nil
+}
+
+// ExportPromptTemplates returns templates as a bundle to publish (e.g. in a repo)
+//
+// Parameters:
+//   - names: Templates to export (empty for all); each carries its mode's current LLM defaults
+//
+// Returns:
+//   - string: The bundle as indented JSON
+//   - error: Error if a name is unknown or nothing is exported
+func (a *App) ExportPromptTemplates(names []string) (string, error) {
+	if len(names) == 0 {
+		for name := range a.settings.PromptTemplates {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+	if len(names) == 0 {
+		return "", fmt.Errorf("no prompt templates to export")
+	}
+	bundle := TemplateBundle{Format: templateBundleFormat, Version: templateBundleVersion, ExportedAt: time.Now(), Templates: []PromptTemplate{}}
+	for _, name := range names {
+		t, ok := a.settings.PromptTemplates[name]
+		if !ok {
+			return "", fmt.Errorf("no prompt template named %s", name)
+		}
+		if defaults, ok := a.settings.ModeDefaults[name]; ok {
+			t.Defaults = &defaults
+		}
+		bundle.Templates = append(bundle.Templates, t)
+	}
+	data, err := json.MarshalIndent(bundle, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode templates: %w", err)
+	}
+	return string(data), nil
+}
+
+// ImportPromptTemplates imports a template bundle (or a single template) from its JSON text
+//
+// Parameters:
+//   - data: Bundle JSON, as written by ExportPromptTemplates
+//   - onConflict: newer (default; replace only with a higher version), overwrite or skip
+//
+// Returns:
+//   - TemplateImportResult: Templates added, updated and skipped
+//   - error: Error if the JSON is not a valid bundle or the templates cannot be saved
+func (a *App) ImportPromptTemplates(data, onConflict string) (TemplateImportResult, error) {
+	templates, err := parseTemplateBundle([]byte(data))
+	if err != nil {
+		return TemplateImportResult{}, err
+	}
+	return a.importTemplates(templates, onConflict, "text")
+}
+
+// ImportTemplateFromURL downloads a template bundle and imports it
+//
+// Parameters:
+//   - rawURL: http(s) URL of the bundle (GitHub blob URLs are read raw)
+//   - onConflict: newer (default), overwrite or skip, as for ImportPromptTemplates
+//
+// Returns:
+//   - TemplateImportResult: Templates added, updated and skipped
+//   - error: Error if the URL is invalid, the download fails or the bundle is invalid
+func (a *App) ImportTemplateFromURL(rawURL, onConflict string) (TemplateImportResult, error) {
+	target, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return TemplateImportResult{}, fmt.Errorf("invalid template URL: %s", rawURL)
+	}
+	target = rawTemplateURL(target)
+
+	req, err := http.NewRequestWithContext(a.ctx, "GET", target.String(), nil)
+	if err != nil {
+		return TemplateImportResult{}, fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := templateHTTPClient.Do(req)
+	if err != nil {
+		return TemplateImportResult{}, fmt.Errorf("failed to download templates: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return TemplateImportResult{}, fmt.Errorf("template download failed with status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, templateFetchMaxBytes+1))
+	if err != nil {
+		return TemplateImportResult{}, fmt.Errorf("failed to download templates: %w", err)
+	}
+	if len(data) > templateFetchMaxBytes {
+		return TemplateImportResult{}, fmt.Errorf("template bundle is larger than %d bytes", templateFetchMaxBytes)
+	}
+
+	templates, err := parseTemplateBundle(data)
+	if err != nil {
+		return TemplateImportResult{}, err
+	}
+	return a.importTemplates(templates, onConflict, target.String())
+}
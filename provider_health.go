@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- Provider Health Checks ---
+//
+// CheckProviderHealth sends one unauthenticated GET to a provider's models endpoint, which
+// costs no tokens, and reports whether it answered and how fast. Hosted providers answer
+// 401/403 without a key: that still proves the API is reachable. OpenAI-compatible local
+// servers (Ollama, LM Studio, vLLM) usually list their models without a key.
+
+const providerHealthTimeout = 10 * time.Second
+
+// healthHTTPClient sends the health checks (their context carries the timeout)
+var healthHTTPClient = &http.Client{Transport: outboundTransport}
+
+// providerModelsURLs are the models endpoints of the hosted providers
+var providerModelsURLs = map[string]string{
+	"google":    "https://generativelanguage.googleapis.com/v1beta/models",
+	"openai":    "https://api.openai.com/v1/models",
+	"anthropic": "https://api.anthropic.com/v1/models",
+}
+
+// ProviderHealth is the result of a provider health check
+type ProviderHealth struct {
+	Provider   string `json:"provider"`   // Provider checked
+	URL        string `json:"url"`        // Endpoint that was requested
+	Status     string `json:"status"`     // ok, auth_required, degraded or unreachable
+	Reachable  bool   `json:"reachable"`  // True if the endpoint answered with an HTTP response
+	StatusCode int    `json:"statusCode"` // HTTP status code (0 if unreachable)
+	LatencyMs  int64  `json:"latencyMs"`  // Time until the response headers arrived
+	Error      string `json:"error"`      // Failure details (empty when ok)
+}
+
+// healthCheckURL returns the endpoint checked for a provider
+// A base URL overrides the hosted endpoint (proxies, custom provider); like the chat
+// endpoint of the custom provider, /v1/models is appended unless the URL already has a path
+// ending in /models.
+func healthCheckURL(provider, baseURL string) (string, error) {
+	baseURL = strings.TrimSpace(baseURL)
+	if baseURL == "" {
+		if url, ok := providerModelsURLs[provider]; ok {
+			return url, nil
+		}
+		if provider == "custom" {
+			return "", fmt.Errorf("baseURL is required for custom provider")
+		}
+		return "", fmt.Errorf("unsupported provider: %s", provider)
+	}
+	url := strings.TrimSuffix(baseURL, "/")
+	url = strings.TrimSuffix(url, "/chat/completions")
+	if strings.HasSuffix(url, "/models") {
+		return url, nil
+	}
+	if strings.HasSuffix(url, "/v1") || strings.HasSuffix(url, "/v1beta") {
+		return url + "/models", nil
+	}
+	return url + "/v1/models", nil
+}
+
+// ============================================================================
+// Provider Health Methods (Wails-bound)
+// ============================================================================
+
+// CheckProviderHealth checks that a provider's API is reachable without consuming tokens
+//
+// Parameters:
+//   - provider: Provider name (google, openai, anthropic, custom)
+//   - baseURL: Base URL to check instead of the hosted API (required for custom)
+//
+// Returns:
+//   - ProviderHealth: Reachability, HTTP status and latency
+//   - error: Error if the provider or base URL is invalid
+func (a *App) CheckProviderHealth(provider, baseURL string) (ProviderHealth, error) {
+	url, err := healthCheckURL(provider, baseURL)
+	if err != nil {
+		return ProviderHealth{}, err
+	}
+	health := ProviderHealth{Provider: provider, URL: url}
+
+	ctx, cancel := context.WithTimeout(context.Background(), providerHealthTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return ProviderHealth{}, fmt.Errorf("invalid base URL: %w", err)
+	}
+
+	start := time.Now()
+	resp, err := healthHTTPClient.Do(req)
+	health.LatencyMs = time.Since(start).Milliseconds()
+	if err != nil {
+		health.Status = "unreachable"
+		health.Error = err.Error()
+		runtime.LogInfof(a.ctx, "Provider %s unreachable at %s: %v", provider, url, err)
+		return health, nil
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	resp.Body.Close()
+
+	health.Reachable = true
+	health.StatusCode = resp.StatusCode
+	switch {
+	case resp.StatusCode < 300:
+		health.Status = "ok"
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden ||
+		(provider == "google" && resp.StatusCode == http.StatusBadRequest): // Gemini rejects a missing key with 400
+		health.Status = "auth_required"
+	default:
+		health.Status = "degraded"
+		health.Error = resp.Status
+	}
+	runtime.LogInfof(a.ctx, "Provider %s health: %s (HTTP %d, %d ms)", provider, health.Status, resp.StatusCode, health.LatencyMs)
+	return health, nil
+}
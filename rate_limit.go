@@ -0,0 +1,227 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- Provider Rate Limits ---
+//
+// Client-side limits on requests and tokens per minute, configured per provider, keep batch
+// runs and agent loops under the provider's quotas instead of tripping 429s. Every provider
+// request (continuations included) waits for a slot in a sliding one-minute window shared by
+// all concurrent jobs. A request is counted with its estimated prompt tokens while in flight,
+// then with the tokens the provider reports. A request larger than the token limit alone
+// still runs once the window is empty, so it cannot wait forever.
+
+const rateLimitWindow = time.Minute
+
+// ProviderRateLimit is the client-side rate limit of a provider
+type ProviderRateLimit struct {
+	RequestsPerMinute int `json:"requestsPerMinute"` // Requests started per minute (0 = unlimited)
+	TokensPerMinute   int `json:"tokensPerMinute"`   // Tokens used per minute (0 = unlimited)
+}
+
+// RateLimitUsage is the use of a provider's rate limit over the last minute
+type RateLimitUsage struct {
+	Provider string            `json:"provider"` // Provider name
+	Limit    ProviderRateLimit `json:"limit"`    // Configured limit
+	Requests int               `json:"requests"` // Requests started in the last minute
+	Tokens   int               `json:"tokens"`   // Tokens counted in the last minute
+}
+
+// rateLimitSlot is a request counted in a provider's window
+type rateLimitSlot struct {
+	at     time.Time // When the request started
+	tokens int       // Estimated, then reported tokens
+}
+
+// providerLimiter is the sliding window of a provider's recent requests
+type providerLimiter struct {
+	mu    sync.Mutex
+	slots []*rateLimitSlot // Requests of the last minute, oldest first
+}
+
+// prune drops the requests older than the window
+func (l *providerLimiter) prune(now time.Time) {
+	keep := 0
+	for keep < len(l.slots) && now.Sub(l.slots[keep].at) >= rateLimitWindow {
+		keep++
+	}
+	l.slots = l.slots[keep:]
+}
+
+// wait returns how long a request of tokens must wait for the limit (0 if it can start)
+func (l *providerLimiter) wait(limit ProviderRateLimit, tokens int, now time.Time) time.Duration {
+	var until time.Time
+	if limit.RequestsPerMinute > 0 && len(l.slots) >= limit.RequestsPerMinute {
+		until = l.slots[len(l.slots)-limit.RequestsPerMinute].at.Add(rateLimitWindow)
+	}
+	if limit.TokensPerMinute > 0 {
+		used := 0
+		for _, slot := range l.slots {
+			used += slot.tokens
+		}
+		// Wait for the oldest requests to leave the window until the tokens fit
+		for i := 0; i < len(l.slots) && used+tokens > limit.TokensPerMinute; i++ {
+			used -= l.slots[i].tokens
+			if t := l.slots[i].at.Add(rateLimitWindow); t.After(until) {
+				until = t
+			}
+		}
+	}
+	if until.After(now) {
+		return until.Sub(now)
+	}
+	return 0
+}
+
+// limiterFor returns the limiter of a provider, creating it if needed
+func (a *App) limiterFor(provider string) *providerLimiter {
+	a.rateLimitersMu.Lock()
+	defer a.rateLimitersMu.Unlock()
+	if a.rateLimiters == nil {
+		a.rateLimiters = make(map[string]*providerLimiter)
+	}
+	l, ok := a.rateLimiters[provider]
+	if !ok {
+		l = &providerLimiter{}
+		a.rateLimiters[provider] = l
+	}
+	return l
+}
+
+// acquireRateLimit waits until a request of a provider fits its rate limit and counts it
+// Emits "rateLimitWaiting" when the request has to wait.
+//
+// Returns:
+//   - *rateLimitSlot: The counted request (settle it with the reported tokens)
+//   - error: Error if ctx is cancelled while waiting
+func (a *App) acquireRateLimit(ctx context.Context, provider string, tokens int) (*rateLimitSlot, error) {
+	l := a.limiterFor(provider)
+	for {
+		a.settingsMu.RLock()
+		limit := a.settings.RateLimits[provider]
+		a.settingsMu.RUnlock()
+		now := time.Now()
+		l.mu.Lock()
+		l.prune(now)
+		delay := l.wait(limit, tokens, now)
+		if delay == 0 {
+			slot := &rateLimitSlot{at: now, tokens: tokens}
+			l.slots = append(l.slots, slot)
+			l.mu.Unlock()
+			return slot, nil
+		}
+		l.mu.Unlock()
+
+		runtime.LogInfof(a.ctx, "Rate limit of %s reached, waiting %s", provider, delay.Round(time.Second))
+		runtime.EventsEmit(a.ctx, "rateLimitWaiting", map[string]interface{}{
+			"provider": provider,
+			"waitMs":   delay.Milliseconds(),
+		})
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, fmt.Errorf("cancelled while waiting for the %s rate limit: %w", provider, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// settleRateLimit replaces the estimated tokens of a request with the tokens reported
+func (a *App) settleRateLimit(provider string, slot *rateLimitSlot, tokensUsed int) {
+	if slot == nil || tokensUsed <= 0 {
+		return
+	}
+	l := a.limiterFor(provider)
+	l.mu.Lock()
+	slot.tokens = tokensUsed
+	l.mu.Unlock()
+}
+
+// ============================================================================
+// Rate Limit Methods (Wails-bound)
+// ============================================================================
+
+// GetRateLimits returns the configured rate limits, keyed by provider
+func (a *App) GetRateLimits() map[string]ProviderRateLimit {
+	a.settingsMu.RLock()
+	defer a.settingsMu.RUnlock()
+	limits := make(map[string]ProviderRateLimit, len(a.settings.RateLimits))
+	for provider, limit := range a.settings.RateLimits {
+		limits[provider] = limit
+	}
+	return limits
+}
+
+// SetRateLimit sets the client-side rate limit of a provider
+//
+// Parameters:
+//   - provider: Provider name (google, openai, anthropic, custom)
+//   - requestsPerMinute: Requests started per minute (0 = unlimited)
+//   - tokensPerMinute: Tokens used per minute (0 = unlimited)
+//
+// Returns:
+//   - error: Error if a limit is negative or the settings cannot be saved
+func (a *App) SetRateLimit(provider string, requestsPerMinute, tokensPerMinute int) error {
+	if provider == "" {
+		return fmt.Errorf("provider is required")
+	}
+	if requestsPerMinute < 0 || tokensPerMinute < 0 {
+		return fmt.Errorf("rate limits cannot be negative")
+	}
+	a.settingsMu.Lock()
+	if a.settings.RateLimits == nil {
+		a.settings.RateLimits = make(map[string]ProviderRateLimit)
+	}
+	if requestsPerMinute == 0 && tokensPerMinute == 0 {
+		delete(a.settings.RateLimits, provider)
+	} else {
+		a.settings.RateLimits[provider] = ProviderRateLimit{RequestsPerMinute: requestsPerMinute, TokensPerMinute: tokensPerMinute}
+	}
+	a.settingsMu.Unlock()
+	if err := a.saveSettings(); err != nil {
+		return fmt.Errorf("failed to save rate limit setting: %w", err)
+	}
+	runtime.LogInfof(a.ctx, "Rate limit of %s set to %d requests/min, %d tokens/min", provider, requestsPerMinute, tokensPerMinute)
+	return nil
+}
+
+// GetRateLimitUsage returns the use of each provider's rate limit over the last minute
+// Providers without a limit are included once they have been called.
+func (a *App) GetRateLimitUsage() []RateLimitUsage {
+	providers := make(map[string]bool)
+	limits := a.GetRateLimits()
+	for provider := range limits {
+		providers[provider] = true
+	}
+	a.rateLimitersMu.Lock()
+	for provider := range a.rateLimiters {
+		providers[provider] = true
+	}
+	a.rateLimitersMu.Unlock()
+
+	usage := []RateLimitUsage{}
+	now := time.Now()
+	for provider := range providers {
+		u := RateLimitUsage{Provider: provider, Limit: limits[provider]}
+		l := a.limiterFor(provider)
+		l.mu.Lock()
+		l.prune(now)
+		u.Requests = len(l.slots)
+		for _, slot := range l.slots {
+			u.Tokens += slot.tokens
+		}
+		l.mu.Unlock()
+		usage = append(usage, u)
+	}
+	sort.Slice(usage, func(i, j int) bool { return usage[i].Provider < usage[j].Provider })
+	return usage
+}
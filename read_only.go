@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- Read-Only Mode ---
+//
+// With the ReadOnly setting on, shotgun-code is strictly an analysis and prompting tool: the
+// backend refuses every change to project files (applying patches or whole-file responses,
+// undo, redo and backup restores) and hides the mutating LLM tools (apply_patch, run_tests)
+// whatever their permissions. The check happens in the backend, so no surface can bypass it.
+// Files in the config directory (settings, snapshots, exports) are still written.
+
+// checkWritable returns an error if read-only mode forbids an action on project files
+func (a *App) checkWritable(action string) error {
+	if a.settings.ReadOnly {
+		return fmt.Errorf("%s is disabled in read-only mode", action)
+	}
+	return nil
+}
+
+// ============================================================================
+// Read-Only Mode Methods (Wails-bound)
+// ============================================================================
+
+// GetReadOnly returns whether read-only mode is on
+func (a *App) GetReadOnly() bool {
+	return a.settings.ReadOnly
+}
+
+// SetReadOnly turns read-only mode on or off and saves the setting
+// Emits "readOnlyChanged" so every view can enable or disable its write actions.
+func (a *App) SetReadOnly(enabled bool) error {
+	a.settings.ReadOnly = enabled
+	if err := a.saveSettings(); err != nil {
+		return fmt.Errorf("failed to save read-only setting: %w", err)
+	}
+	runtime.LogInfof(a.ctx, "Read-only mode: %v", enabled)
+	runtime.EventsEmit(a.ctx, "readOnlyChanged", enabled)
+	return nil
+}
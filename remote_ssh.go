@@ -0,0 +1,226 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"os/exec"
+	"path"
+	"path/filepath"
+	goruntime "runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- Remote Projects over SSH ---
+//
+// A directory on a dev server or jump box can be opened as a read-only project. The system ssh
+// client is used (key or agent authentication, with the user's ssh config and known_hosts), so
+// no credentials are handled here. The tree is listed once with find when the project is
+// opened; file contents are fetched with cat when first read. On macOS and Linux, one
+// multiplexed connection (ControlMaster) is shared by all commands.
+//
+// The listing skips .git and the hard-excluded directories (see watcher_exclusions.go), and
+// requires GNU find on the remote host.
+
+const (
+	remoteListTimeout = 2 * time.Minute // Listing a large tree
+	remoteReadTimeout = time.Minute     // Fetching one file
+	sshConnectTimeout = 15              // Seconds
+	sshControlPersist = 120             // Seconds the shared connection stays open when idle
+)
+
+// SSHProjectOptions describes a remote project
+type SSHProjectOptions struct {
+	Host    string `json:"host"`    // Host name or ssh config alias
+	User    string `json:"user"`    // Login user (empty for the ssh config default)
+	Port    int    `json:"port"`    // Port (0 for the ssh config default)
+	Path    string `json:"path"`    // Project directory on the host (relative paths start at the home directory)
+	KeyFile string `json:"keyFile"` // Private key file (empty for the agent and ssh config)
+}
+
+// sshTarget runs commands on a remote host
+type sshTarget struct {
+	opts        SSHProjectOptions
+	controlPath string // Socket of the shared connection (empty when not multiplexing)
+}
+
+// destination returns the [user@]host argument
+func (s *sshTarget) destination() string {
+	if s.opts.User != "" {
+		return s.opts.User + "@" + s.opts.Host
+	}
+	return s.opts.Host
+}
+
+// args returns the ssh options shared by all commands
+func (s *sshTarget) args() []string {
+	args := []string{"-o", "BatchMode=yes", "-o", "ConnectTimeout=" + strconv.Itoa(sshConnectTimeout)}
+	if s.opts.Port > 0 {
+		args = append(args, "-p", strconv.Itoa(s.opts.Port))
+	}
+	if s.opts.KeyFile != "" {
+		args = append(args, "-i", s.opts.KeyFile)
+	}
+	if s.controlPath != "" {
+		args = append(args, "-o", "ControlMaster=auto", "-o", "ControlPath="+s.controlPath,
+			"-o", "ControlPersist="+strconv.Itoa(sshControlPersist))
+	}
+	return args
+}
+
+// run executes a shell command on the host and returns its standard output
+func (s *sshTarget) run(timeout time.Duration, remoteCmd string) ([]byte, error) {
+	if err := checkOnline("ssh to " + s.destination()); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	args := append(s.args(), "--", s.destination(), remoteCmd)
+	cmd := exec.CommandContext(ctx, "ssh", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("ssh %s: %v: %s", s.destination(), err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+// Close ends the shared connection
+func (s *sshTarget) Close() error {
+	if s.controlPath == "" {
+		return nil
+	}
+	args := append(s.args(), "-O", "exit", "--", s.destination())
+	return exec.Command("ssh", args...).Run()
+}
+
+// remoteFindCommand returns a find command listing a remote tree for parseRemoteListing
+// Entries are printed as type, size, modification time and relative path, NUL-terminated.
+func remoteFindCommand(remotePath string, pruneNames []string) string {
+	var cmd strings.Builder
+	cmd.WriteString("find " + shellQuote(remotePath) + " -mindepth 1")
+	if len(pruneNames) > 0 {
+		cmd.WriteString(" \\(")
+		for i, name := range pruneNames {
+			if i > 0 {
+				cmd.WriteString(" -o")
+			}
+			cmd.WriteString(" -name " + shellQuote(name))
+		}
+		cmd.WriteString(" \\) -prune -o")
+	}
+	cmd.WriteString(` \( -type d -o -type f \) -printf '%y\t%s\t%T@\t%P\0'`)
+	return cmd.String()
+}
+
+// parseRemoteListing builds a lazy filesystem from the output of remoteFindCommand
+func parseRemoteListing(out []byte, load func(name string) ([]byte, error)) (*memFS, error) {
+	mfs := newLazyMemFS(load)
+	for _, record := range bytes.Split(out, []byte{0}) {
+		if len(record) == 0 {
+			continue
+		}
+		fields := strings.SplitN(string(record), "\t", 4)
+		if len(fields) != 4 {
+			return nil, fmt.Errorf("unexpected listing output: %q (GNU find is required)", string(record))
+		}
+		name := path.Clean(fields[3])
+		if name == "." || !fs.ValidPath(name) {
+			continue
+		}
+		size, _ := strconv.ParseInt(fields[1], 10, 64)
+		seconds, _ := strconv.ParseFloat(fields[2], 64)
+		modTime := time.Unix(0, int64(seconds*float64(time.Second)))
+		switch fields[0] {
+		case "d":
+			mfs.mkdirAll(name, modTime)
+		case "f":
+			mfs.addLazyFile(name, size, modTime)
+		}
+	}
+	return mfs, nil
+}
+
+// remoteProjectRoot returns the virtual root a remote project is mounted at, e.g. ssh:/alice@dev/srv/app
+func remoteProjectRoot(scheme, host, remotePath string) string {
+	return filepath.Clean(scheme + "://" + host + "/" + strings.TrimPrefix(remotePath, "/"))
+}
+
+// remotePruneNames returns the directory names left out of remote listings
+func (a *App) remotePruneNames() []string {
+	return append([]string{".git"}, a.settings.WatcherExcludeDirs...)
+}
+
+// ============================================================================
+// SSH Project Methods (Wails-bound)
+// ============================================================================
+
+// OpenSSHProject lists a remote directory over SSH and mounts it as a read-only project
+//
+// Parameters:
+//   - opts: Host, user, port, remote path and optional key file
+//
+// Returns:
+//   - string: Project root to use with ListFiles and generation (e.g. ssh:/alice@dev/srv/app)
+//   - error: Error if the options are invalid or the host cannot be listed
+func (a *App) OpenSSHProject(opts SSHProjectOptions) (string, error) {
+	opts.Host = strings.TrimSpace(opts.Host)
+	opts.User = strings.TrimSpace(opts.User)
+	opts.Path = strings.TrimSpace(opts.Path)
+	if opts.Host == "" || opts.Path == "" {
+		return "", fmt.Errorf("host and path are required")
+	}
+	// Values starting with "-" would be read as ssh options
+	if strings.HasPrefix(opts.Host, "-") || strings.HasPrefix(opts.User, "-") || strings.ContainsAny(opts.Host+opts.User, " @") {
+		return "", fmt.Errorf("invalid host or user")
+	}
+	if opts.Port < 0 || opts.Port > 65535 {
+		return "", fmt.Errorf("invalid port: %d", opts.Port)
+	}
+
+	target := &sshTarget{opts: opts}
+	if goruntime.GOOS != "windows" {
+		target.controlPath = "/tmp/shotgun-ssh-%C" // Short: socket paths are limited to about 100 bytes
+	}
+
+	remoteRoot := strings.TrimSuffix(opts.Path, "/")
+	if remoteRoot == "" {
+		remoteRoot = "/"
+	}
+	out, err := target.run(remoteListTimeout, remoteFindCommand(remoteRoot, a.remotePruneNames()))
+	if err != nil {
+		target.Close()
+		return "", fmt.Errorf("failed to list %s: %w", remoteRoot, err)
+	}
+	fsys, err := parseRemoteListing(out, func(name string) ([]byte, error) {
+		return target.run(remoteReadTimeout, "cat -- "+shellQuote(path.Join(remoteRoot, name)))
+	})
+	if err != nil {
+		target.Close()
+		return "", err
+	}
+
+	hostLabel := target.destination()
+	if opts.Port > 0 {
+		hostLabel += ":" + strconv.Itoa(opts.Port)
+	}
+	rootDir := remoteProjectRoot("ssh", hostLabel, remoteRoot)
+	mountProject(&mountedProject{root: rootDir, fsys: fsys, kind: "ssh", source: hostLabel + ":" + remoteRoot, closer: target})
+	runtime.LogInfof(a.ctx, "Mounted %s:%s as a read-only project (%d bytes listed)", hostLabel, remoteRoot, fsys.size)
+	return rootDir, nil
+}
+
+// CloseSSHProject unmounts a remote project and closes its shared connection
+func (a *App) CloseSSHProject(rootDir string) error {
+	if !a.closeMountedProject(rootDir) {
+		return fmt.Errorf("no remote project is open at %s", rootDir)
+	}
+	runtime.LogInfof(a.ctx, "Closed remote project %s", rootDir)
+	return nil
+}
@@ -0,0 +1,486 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- Repo Map ---
+//
+// A generation started with GenerationOptions.RepoMap gives each file only its declarations:
+// function and method signatures without bodies, type definitions, and constants. For
+// architecture questions this map costs a fraction of the tokens of the full source:
+//
+//	<file path="internal/auth/session.go" repo-map="true">
+//	package auth
+//
+//	type Session struct {
+//		UserID  string
+//		Expires time.Time
+//	}
+//
+//	func (s *Session) Valid(now time.Time) bool
+//	</file>
+//
+// Go is parsed with go/parser and keeps exported declarations only, except in package main
+// whose declarations are all kept. Python keeps the def and class lines of modules and class
+// bodies, skipping _private names. Brace languages (JavaScript, TypeScript, Java, C#, Rust, C,
+// C++...) use declaration heuristics like ExtractSymbols: type definitions (interface, type,
+// enum, struct, record) are kept whole, classes keep their member signatures, and function
+// bodies become "{ ... }"; members marked private are skipped. Files of other languages, and
+// files that cannot be read or parsed, get a "No declarations" placeholder. The token budget,
+// file summaries and line numbers do not apply to a repo map. (tree-sitter grammars are not a
+// dependency of this project, hence the heuristics.)
+
+// repoMapTypeBlockLines is the longest type definition kept whole; longer ones keep only their
+// nested declarations, like classes
+const repoMapTypeBlockLines = 60
+
+// repoMapLanguages are the brace languages mapped with declaration heuristics
+var repoMapLanguages = map[string]bool{
+	"JavaScript": true, "TypeScript": true, "Java": true, "Kotlin": true, "Scala": true,
+	"Swift": true, "C": true, "C++": true, "C#": true, "Rust": true, "PHP": true, "Dart": true,
+}
+
+// repoMapContainers are declaration keywords whose body holds declarations
+var repoMapContainers = map[string]bool{
+	"class": true, "trait": true, "impl": true, "object": true, "module": true, "namespace": true,
+}
+
+// repoMapTypes are declaration keywords whose body is a type definition
+var repoMapTypes = map[string]bool{
+	"interface": true, "type": true, "enum": true, "struct": true, "record": true,
+}
+
+// repoMapPrinter prints Go declarations the way gofmt does
+var repoMapPrinter = &printer.Config{Mode: printer.UseSpaces | printer.TabIndent, Tabwidth: 8}
+
+// controlKeywords look like calls but never declare anything
+var controlKeywords = map[string]bool{
+	"if": true, "for": true, "while": true, "switch": true, "catch": true, "return": true,
+	"new": true, "else": true, "do": true, "try": true, "sizeof": true, "typeof": true,
+	"await": true, "throw": true, "case": true, "using": true, "lock": true, "foreach": true,
+}
+
+var (
+	// pySignatureEndRegex matches the end of a Python def or class line
+	pySignatureEndRegex = regexp.MustCompile(`:\s*(?:\.\.\.|pass)?\s*(?:#.*)?$`)
+	// typedCallableRegex matches C-like declarations with a return type (int main(, public void run()
+	typedCallableRegex = regexp.MustCompile(`^\s*(?:[\w<>\[\],.?*&:@]+\s+)+[*&]*(~?[A-Za-z_]\w*)\s*\(`)
+	// memberCallableRegex matches method declarations inside a class body, with or without type
+	memberCallableRegex = regexp.MustCompile(`^\s*(?:[\w<>\[\],.?*&:@]+\s+)*[*&]*(~?[#A-Za-z_$][\w$]*)\s*(?:<[^>]*>)?\s*\(`)
+)
+
+// repoMapRun counts the files a generation mapped and the bytes it saved
+type repoMapRun struct {
+	files       int // Files mapped
+	bytesBefore int // Bytes of their source
+	bytesAfter  int // Bytes of their maps
+}
+
+// newRepoMapRun returns the repo map state of a generation (nil if it keeps full content)
+func newRepoMapRun(opts GenerationOptions) *repoMapRun {
+	if !opts.RepoMap {
+		return nil
+	}
+	return &repoMapRun{}
+}
+
+// preamble explains the repo map to the model ("" without a repo map)
+func (r *repoMapRun) preamble() string {
+	if r == nil {
+		return ""
+	}
+	return "Files are shown as a repo map: only their declarations (signatures and type definitions) are included, function bodies are omitted.\n\n"
+}
+
+// goRepoMap returns the package clause and declarations of Go source
+func goRepoMap(content string) (string, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", content, parser.SkipObjectResolution)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse Go source: %w", err)
+	}
+	all := file.Name.Name == "main" // Nothing imports package main, so every declaration matters
+	parts := []string{"package " + file.Name.Name}
+	add := func(node ast.Node) {
+		var b strings.Builder
+		if repoMapPrinter.Fprint(&b, fset, node) == nil {
+			parts = append(parts, b.String())
+		}
+	}
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if all || d.Name.IsExported() && (d.Recv == nil || receiverExported(d.Recv)) {
+				add(&ast.FuncDecl{Recv: d.Recv, Name: d.Name, Type: d.Type})
+			}
+		case *ast.GenDecl:
+			if d.Tok == token.IMPORT {
+				continue
+			}
+			var specs []ast.Spec
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					if all || s.Name.IsExported() {
+						specs = append(specs, &ast.TypeSpec{Name: s.Name, TypeParams: s.TypeParams, Assign: s.Assign, Type: s.Type})
+					}
+				case *ast.ValueSpec:
+					if kept := goValueSpec(fset, s, all, d.Tok); kept != nil {
+						specs = append(specs, kept)
+					}
+				}
+			}
+			if len(specs) > 0 {
+				add(&ast.GenDecl{Tok: d.Tok, Lparen: d.Lparen, Specs: specs, Rparen: d.Rparen})
+			}
+		}
+	}
+	return strings.Join(parts, "\n\n"), nil
+}
+
+// receiverExported reports whether a method's receiver type is exported
+func receiverExported(recv *ast.FieldList) bool {
+	if len(recv.List) == 0 {
+		return false
+	}
+	typ := recv.List[0].Type
+	for {
+		switch t := typ.(type) {
+		case *ast.StarExpr:
+			typ = t.X
+		case *ast.IndexExpr:
+			typ = t.X
+		case *ast.IndexListExpr:
+			typ = t.X
+		case *ast.Ident:
+			return t.IsExported()
+		default:
+			return false
+		}
+	}
+}
+
+// goValueSpec returns the part of a const or var spec a repo map keeps (nil if none)
+// Constant values are kept; variable values only when short and all names are kept. A long
+// composite literal is replaced by its type.
+func goValueSpec(fset *token.FileSet, s *ast.ValueSpec, all bool, tok token.Token) *ast.ValueSpec {
+	kept := &ast.ValueSpec{Type: s.Type}
+	for _, name := range s.Names {
+		if all || name.IsExported() {
+			kept.Names = append(kept.Names, name)
+		}
+	}
+	if len(kept.Names) == 0 {
+		return nil
+	}
+	if len(kept.Names) != len(s.Names) {
+		return kept
+	}
+	if tok == token.CONST {
+		kept.Values = s.Values
+		return kept
+	}
+	var b strings.Builder
+	for _, value := range s.Values {
+		if repoMapPrinter.Fprint(&b, fset, value) != nil {
+			return kept
+		}
+	}
+	if b.Len() <= 80 && !strings.Contains(b.String(), "\n") {
+		kept.Values = s.Values
+	} else if lit, ok := s.Values[0].(*ast.CompositeLit); ok && kept.Type == nil && len(s.Values) == 1 {
+		kept.Type = lit.Type
+	}
+	return kept
+}
+
+// pythonRepoMap returns the def and class lines of Python source, with their indentation
+func pythonRepoMap(content string) string {
+	type scope struct {
+		indent  int
+		class   bool
+		private bool
+	}
+	lines := strings.Split(content, "\n")
+	var stack []scope
+	var kept []string
+	for i := 0; i < len(lines); i++ {
+		m := pyDeclRegex.FindStringSubmatch(lines[i])
+		if m == nil {
+			continue
+		}
+		indent := len(m[1])
+		for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+		name := m[3]
+		private := strings.HasPrefix(name, "_") && !(strings.HasPrefix(name, "__") && strings.HasSuffix(name, "__"))
+		hidden := private
+		for _, s := range stack {
+			hidden = hidden || !s.class || s.private // Nested in a function or a private class
+		}
+		stack = append(stack, scope{indent: indent, class: m[2] == "class", private: hidden})
+		if hidden {
+			continue
+		}
+		end := i
+		for end < len(lines)-1 && end-i < 10 && !pySignatureEndRegex.MatchString(lines[end]) {
+			end++
+		}
+		kept = append(kept, strings.Join(lines[i:end+1], "\n"))
+	}
+	return strings.Join(kept, "\n")
+}
+
+// braceRepoMap returns the declarations of brace-language source, with their indentation
+func braceRepoMap(content string) string {
+	type block struct {
+		container bool   // Declarations inside are mapped
+		indent    string // Indentation of the mapped header ("" if none was mapped)
+		mapped    bool   // The header was mapped, so the closing brace is too
+	}
+	lines := strings.Split(content, "\n")
+	var stack []block
+	var kept []string
+	pending := block{} // Block the next "{" opens
+	skipUntil := -1    // Last line of a type definition kept whole
+	inContainers := func() bool {
+		for _, b := range stack {
+			if !b.container {
+				return false
+			}
+		}
+		return true
+	}
+	var quote byte
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		isComment := strings.HasPrefix(trimmed, "//") || strings.HasPrefix(trimmed, "/*") || strings.HasPrefix(trimmed, "*") || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "@")
+		if i > skipUntil && quote == 0 && !isComment && inContainers() {
+			if keyword, ok := braceDeclaration(line, len(stack) > 0); ok {
+				indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+				header, hasBody := braceSignature(lines, i)
+				end := braceBlockEnd(lines, i)
+				switch {
+				case !hasBody:
+					kept = append(kept, header)
+				case repoMapTypes[keyword] && end-i < repoMapTypeBlockLines:
+					kept = append(kept, strings.Join(lines[i:end+1], "\n"))
+					skipUntil = end
+				case repoMapContainers[keyword] || repoMapTypes[keyword]:
+					kept = append(kept, header+" {")
+					pending = block{container: true, indent: indent, mapped: true}
+				default:
+					kept = append(kept, header+" { ... }")
+				}
+			}
+		}
+		forEachBrace(line, &quote, func(c byte) {
+			if c == '{' {
+				stack = append(stack, pending)
+				pending = block{}
+				return
+			}
+			if len(stack) == 0 {
+				return
+			}
+			if closed := stack[len(stack)-1]; closed.mapped {
+				kept = append(kept, closed.indent+"}")
+			}
+			stack = stack[:len(stack)-1]
+		})
+	}
+	return strings.Join(kept, "\n")
+}
+
+// braceDeclaration recognizes a declaration line of a brace language
+//
+// Parameters:
+//   - line: Source line
+//   - member: True inside a class body, where methods need no return type or keyword
+//
+// Returns:
+//   - string: Declaration keyword (class, interface, fn...; empty for typed functions and methods)
+//   - bool: False if the line declares nothing the map keeps
+func braceDeclaration(line string, member bool) (string, bool) {
+	if strings.Contains(" "+strings.TrimSpace(line)+" ", " private ") {
+		return "", false
+	}
+	m := braceDeclRegex.FindStringSubmatch(line)
+	if m != nil && m[1] != "" {
+		return strings.TrimSuffix(m[1], "*"), true
+	}
+	if !member && m != nil && strings.HasPrefix(strings.TrimSpace(line), "export ") {
+		return "", true // export const handler = ...
+	}
+	callable := typedCallableRegex
+	if member {
+		callable = memberCallableRegex
+	}
+	if c := callable.FindStringSubmatch(line); c != nil && !controlKeywords[c[1]] && !strings.HasPrefix(c[1], "#") {
+		return "", true
+	}
+	return "", false
+}
+
+// braceSignature returns the declaration starting at line i without its body
+// A parameter list may span lines, and the body's brace may open on the next line.
+//
+// Returns:
+//   - string: The declaration up to its body's "{" (the whole line if it has no body)
+//   - bool: True if a body follows
+func braceSignature(lines []string, i int) (string, bool) {
+	last := i
+	for depth := parenDepth(lines[i]); depth > 0 && last+1 < len(lines) && last-i < 10; {
+		last++
+		depth += parenDepth(lines[last])
+	}
+	signature := strings.Join(lines[i:last+1], "\n")
+	if cut := strings.Index(signature, "{"); cut >= 0 && !strings.Contains(signature[:cut], ";") {
+		return strings.TrimRight(signature[:cut], " \t\r\n"), true
+	}
+	signature = strings.TrimRight(signature, " \t\r")
+	if last+1 < len(lines) && strings.TrimSpace(lines[last+1]) == "{" && !strings.HasSuffix(signature, ";") {
+		return signature, true
+	}
+	return signature, false
+}
+
+// parenDepth returns the parentheses a line opens minus those it closes
+func parenDepth(line string) int {
+	return strings.Count(line, "(") - strings.Count(line, ")")
+}
+
+// forEachBrace calls fn for every brace of a line outside strings and line comments
+// quote carries an unterminated string (template literals) over to the next line.
+func forEachBrace(line string, quote *byte, fn func(c byte)) {
+	for k := 0; k < len(line); k++ {
+		c := line[k]
+		switch {
+		case *quote != 0:
+			if c == '\\' {
+				k++
+			} else if c == *quote {
+				*quote = 0
+			}
+		case c == '"' || c == '\'' || c == '`':
+			*quote = c
+			if c != '`' && !strings.ContainsRune(line[k+1:], rune(c)) {
+				*quote = 0 // A lone quote (char literal, apostrophe) does not span lines
+			}
+		case c == '/' && k+1 < len(line) && line[k+1] == '/':
+			return
+		case c == '{' || c == '}':
+			fn(c)
+		}
+	}
+}
+
+// repoMapSource returns the repo map of a file's content
+//
+// Returns:
+//   - string: The declarations of the file ("" if its language is not mapped)
+//   - error: Error if Go source cannot be parsed
+func repoMapSource(relPath, content string) (string, error) {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	switch lang := detectContentLanguage(relPath, []byte(content[:min(len(content), languageSniffBytes)])); {
+	case lang == "Go":
+		return goRepoMap(content)
+	case lang == "Python":
+		return pythonRepoMap(content), nil
+	case repoMapLanguages[lang]:
+		return braceRepoMap(content), nil
+	}
+	return "", nil
+}
+
+// appendRepoMapBlock writes the repo map block of a file, or a placeholder if it has none
+//
+// Parameters:
+//   - fileContents: Builder receiving the block
+//   - path: Absolute path of the file
+//   - relPath: Path relative to the project root
+//   - run: Repo map state of the generation
+func (a *App) appendRepoMapBlock(fileContents *strings.Builder, path, relPath string, run *repoMapRun) {
+	relPathForwardSlash := filepath.ToSlash(relPath)
+	noMap := fmt.Sprintf("<!-- No declarations (repo map): %s -->\n", relPathForwardSlash)
+	if isBinary, err := a.isBinaryFileCached(path); err != nil || isBinary {
+		fileContents.WriteString(noMap)
+		return
+	}
+	content, err := a.projectFS.ReadFile(path)
+	if err != nil || !utf8.Valid(content) {
+		fileContents.WriteString(noMap)
+		return
+	}
+	source := a.redactSecretsIfEnabled(relPathForwardSlash, string(content))
+	repoMap, err := repoMapSource(relPathForwardSlash, source)
+	if err != nil {
+		runtime.LogDebugf(a.ctx, "No repo map for %s: %v", relPath, err)
+	}
+	if strings.TrimSpace(repoMap) == "" {
+		fileContents.WriteString(noMap)
+		return
+	}
+	attrs := a.languageHeaderAttr(relPathForwardSlash, content) + ` repo-map="true"`
+	fileContents.WriteString(fileBlock(relPathForwardSlash, attrs, repoMap+"\n", a.fileBlockEscaping()))
+	run.files++
+	run.bytesBefore += len(content)
+	run.bytesAfter += len(repoMap)
+}
+
+// emitRepoMap reports the savings of a repo map generation as "repoMapApplied"
+func (a *App) emitRepoMap(rootDir string, r *repoMapRun) {
+	if r == nil {
+		return
+	}
+	tokensSaved := max(r.bytesBefore-r.bytesAfter, 0) / 4 // EstimateTokens counts 4 bytes per token
+	runtime.LogInfof(a.ctx, "Repo map of %d files in %s: ~%d tokens saved", r.files, rootDir, tokensSaved)
+	a.emitGenerationEvent("repoMapApplied", map[string]interface{}{
+		"rootDir":      rootDir,
+		"files":        r.files,
+		"tokensBefore": r.bytesBefore / 4,
+		"tokensAfter":  r.bytesAfter / 4,
+		"tokensSaved":  tokensSaved,
+	})
+}
+
+// ============================================================================
+// Repo Map Methods (Wails-bound)
+// ============================================================================
+
+// PreviewRepoMap returns the repo map a generation with RepoMap would give a file
+//
+// Parameters:
+//   - rootDir: Project root directory
+//   - relPath: File path relative to the root
+//
+// Returns:
+//   - string: The declarations of the file ("" if its language is not mapped)
+//   - error: Error if the file cannot be read or Go source cannot be parsed
+func (a *App) PreviewRepoMap(rootDir, relPath string) (string, error) {
+	if err := a.validateContentRoot(rootDir); err != nil {
+		return "", err
+	}
+	absPath, err := resolveProjectPath(rootDir, relPath)
+	if err != nil {
+		return "", err
+	}
+	data, err := a.projectFS.ReadFile(absPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", relPath, err)
+	}
+	relPath = filepath.ToSlash(relPath)
+	return repoMapSource(relPath, a.redactSecretsIfEnabled(relPath, string(data)))
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- LLM Response Export ---
+
+// CodeBlock is a fenced code block extracted from a Markdown LLM response
+type CodeBlock struct {
+	Language string `json:"language"` // Info string after the opening fence (e.g., go, diff), may be empty
+	Content  string `json:"content"`  // Code inside the fence, without the fence lines
+}
+
+// fenceOpenRegex matches an opening code fence (``` or ~~~, optionally indented) and its info string
+var fenceOpenRegex = regexp.MustCompile("^[ \t]{0,3}(`{3,}|~{3,})[ \t]*([^`\\s]*)")
+
+// extractCodeBlocks returns all fenced code blocks in a Markdown text, in order
+// An unterminated final block is returned up to the end of the text (common with truncated responses)
+func extractCodeBlocks(markdown string) []CodeBlock {
+	var blocks []CodeBlock
+	lines := strings.Split(strings.ReplaceAll(markdown, "\r\n", "\n"), "\n")
+
+	for i := 0; i < len(lines); i++ {
+		match := fenceOpenRegex.FindStringSubmatch(lines[i])
+		if match == nil {
+			continue
+		}
+		fence := match[1]
+		block := CodeBlock{Language: match[2]}
+
+		var content []string
+		j := i + 1
+		for ; j < len(lines); j++ {
+			trimmed := strings.TrimSpace(lines[j])
+			// A closing fence uses the same character and is at least as long as the opening one
+			if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+				break
+			}
+			content = append(content, lines[j])
+		}
+		block.Content = strings.Join(content, "\n")
+		blocks = append(blocks, block)
+		i = j
+	}
+	return blocks
+}
+
+// SaveResponseToFile writes an LLM response (or only its code blocks) to a file chosen
+// with a native save dialog, so long outputs don't have to go through the clipboard
+//
+// Parameters:
+//   - content: Response text to save
+//   - defaultFilename: Suggested file name (empty for response.md / response.txt)
+//   - codeBlocksOnly: Save only the fenced code blocks, separated by blank lines
+//
+// Returns:
+//   - string: Path the response was written to, or empty string if the dialog was cancelled
+//   - error: Error if there is nothing to save or the file cannot be written
+func (a *App) SaveResponseToFile(content, defaultFilename string, codeBlocksOnly bool) (string, error) {
+	if strings.TrimSpace(content) == "" {
+		return "", fmt.Errorf("response is empty, nothing to save")
+	}
+
+	data := content
+	if codeBlocksOnly {
+		blocks := extractCodeBlocks(content)
+		if len(blocks) == 0 {
+			return "", fmt.Errorf("response contains no code blocks")
+		}
+		parts := make([]string, 0, len(blocks))
+		for _, block := range blocks {
+			parts = append(parts, block.Content)
+		}
+		data = strings.Join(parts, "\n\n") + "\n"
+	}
+
+	if strings.TrimSpace(defaultFilename) == "" {
+		defaultFilename = "response.md"
+		if codeBlocksOnly {
+			defaultFilename = "response.txt"
+		}
+	}
+
+	path, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:                "Save LLM Response",
+		DefaultFilename:      defaultFilename,
+		CanCreateDirectories: true,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to open save dialog: %w", err)
+	}
+	if path == "" {
+		runtime.LogInfo(a.ctx, "SaveResponseToFile: dialog cancelled")
+		return "", nil
+	}
+
+	if err := writeResponseFile(path, data); err != nil {
+		runtime.LogErrorf(a.ctx, "SaveResponseToFile: %v", err)
+		return "", err
+	}
+
+	runtime.LogInfof(a.ctx, "Saved LLM response (%d bytes) to %s", len(data), path)
+	return path, nil
+}
+
+// SaveCodeBlockToFile writes a single code block of an LLM response to a file chosen
+// with a native save dialog (e.g., one generated module out of a multi-file answer)
+//
+// Parameters:
+//   - content: Response text containing the code block
+//   - index: Zero-based index of the code block
+//   - defaultFilename: Suggested file name
+//
+// Returns:
+//   - string: Path the code block was written to, or empty string if the dialog was cancelled
+//   - error: Error if the block does not exist or the file cannot be written
+func (a *App) SaveCodeBlockToFile(content string, index int, defaultFilename string) (string, error) {
+	blocks := extractCodeBlocks(content)
+	if index < 0 || index >= len(blocks) {
+		return "", fmt.Errorf("code block %d not found (response has %d code blocks)", index, len(blocks))
+	}
+
+	path, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:                "Save Code Block",
+		DefaultFilename:      defaultFilename,
+		CanCreateDirectories: true,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to open save dialog: %w", err)
+	}
+	if path == "" {
+		return "", nil
+	}
+
+	data := blocks[index].Content
+	if !strings.HasSuffix(data, "\n") {
+		data += "\n"
+	}
+	if err := writeResponseFile(path, data); err != nil {
+		return "", err
+	}
+
+	runtime.LogInfof(a.ctx, "Saved code block %d (%d bytes) to %s", index, len(data), path)
+	return path, nil
+}
+
+// ExtractCodeBlocks returns the fenced code blocks of an LLM response
+func (a *App) ExtractCodeBlocks(content string) []CodeBlock {
+	blocks := extractCodeBlocks(content)
+	if blocks == nil {
+		return []CodeBlock{}
+	}
+	return blocks
+}
+
+// writeResponseFile writes data to path, creating the parent directory if needed
+func writeResponseFile(path, data string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", path, err)
+	}
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- LLM Response Post-Processing ---
+
+// PostProcessingOptions controls how LLM responses are cleaned up before diff extraction
+// and apply steps. Models routinely wrap diffs in Markdown fences and prose, which breaks parsers.
+type PostProcessingOptions struct {
+	StripFences      bool     `json:"stripFences"`      // Keep only the contents of fenced code blocks (diff blocks preferred)
+	TrimPreamble     bool     `json:"trimPreamble"`     // Drop leading prose such as "Here is the diff:"
+	PreamblePatterns []string `json:"preamblePatterns"` // Additional regexes for leading lines to drop
+}
+
+// defaultPostProcessingOptions returns the post-processing used when no settings are saved
+func defaultPostProcessingOptions() PostProcessingOptions {
+	return PostProcessingOptions{
+		StripFences:      true,
+		TrimPreamble:     true,
+		PreamblePatterns: []string{},
+	}
+}
+
+// defaultPreambleRegexes match typical introductory lines models put before their output
+var defaultPreambleRegexes = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)^(sure|certainly|of course|okay|ok)\b.*$`),
+	regexp.MustCompile(`(?i)^(here is|here's|here are|below is|below are|the following is)\b.*$`),
+	regexp.MustCompile(`(?i)^.*\b(diff|patch|changes|code|implementation)\b.*:\s*$`),
+}
+
+// diffStartRegex matches the first line of a unified or git diff
+var diffStartRegex = regexp.MustCompile(`(?m)^(diff --git |--- \S|Index: )`)
+
+// postProcessResponse applies the configured post-processing steps to an LLM response
+//
+// Parameters:
+//   - text: Raw LLM response
+//   - opts: Post-processing options
+//
+// Returns:
+//   - string: Cleaned response (the original text if no step applies)
+func postProcessResponse(text string, opts PostProcessingOptions) string {
+	result := text
+	if opts.StripFences {
+		result = stripMarkdownFences(result)
+	}
+	if opts.TrimPreamble {
+		result = trimPreamble(result, opts.PreamblePatterns)
+	}
+	return result
+}
+
+// responseFenceRegex matches an opening code fence at column 0 and its info string
+var responseFenceRegex = regexp.MustCompile("^(`{3,}|~{3,})[ \t]*([^`\\s]*)")
+
+// stripMarkdownFences replaces a response containing fenced code blocks with the block contents
+// If any block is a diff (by info string or content), only diff blocks are kept. A response
+// with a diff outside any fence is a raw diff and is returned unchanged.
+func stripMarkdownFences(text string) string {
+	blocks, rawDiff := responseCodeBlocks(text)
+	if rawDiff || len(blocks) == 0 {
+		return text
+	}
+
+	var diffBlocks []string
+	var allBlocks []string
+	for _, block := range blocks {
+		allBlocks = append(allBlocks, block.Content)
+		lang := strings.ToLower(block.Language)
+		if lang == "diff" || lang == "patch" || diffStartRegex.MatchString(block.Content) {
+			diffBlocks = append(diffBlocks, block.Content)
+		}
+	}
+
+	kept := allBlocks
+	if len(diffBlocks) > 0 {
+		kept = diffBlocks
+	}
+	return strings.Join(kept, "\n") + "\n"
+}
+
+// responseCodeBlocks returns the fenced code blocks of an LLM response
+// Unlike extractCodeBlocks, only fences at column 0 count, so the context lines of a diff
+// (" ```bash") are never taken for fences. A diff header outside any fence stops the scan:
+// the response is then a raw diff.
+//
+// Returns:
+//   - []CodeBlock: Fenced blocks, in order (nil for a raw diff)
+//   - bool: True if the response contains a diff outside any fence
+func responseCodeBlocks(text string) ([]CodeBlock, bool) {
+	var blocks []CodeBlock
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		if diffStartRegex.MatchString(lines[i]) {
+			return nil, true
+		}
+		match := responseFenceRegex.FindStringSubmatch(lines[i])
+		if match == nil {
+			continue
+		}
+		fence := match[1]
+		block := CodeBlock{Language: match[2]}
+		var content []string
+		j := i + 1
+		for ; j < len(lines); j++ {
+			// A closing fence is at column 0, uses the same character and is at least as long
+			// as the opening one; hunk lines of a fenced diff never are (they start with a
+			// space, + or -)
+			line := strings.TrimRight(lines[j], " \t")
+			if strings.HasPrefix(line, fence) && strings.Trim(line, fence[:1]) == "" {
+				break
+			}
+			content = append(content, lines[j])
+		}
+		block.Content = strings.Join(content, "\n")
+		blocks = append(blocks, block)
+		i = j
+	}
+	return blocks, false
+}
+
+// trimPreamble drops introductory prose at the start of a response
+// If the response contains a diff, everything before the first diff line is dropped;
+// otherwise leading blank lines and lines matching a preamble pattern are removed.
+func trimPreamble(text string, extraPatterns []string) string {
+	if loc := diffStartRegex.FindStringIndex(text); loc != nil {
+		return text[loc[0]:]
+	}
+
+	regexes := append([]*regexp.Regexp{}, defaultPreambleRegexes...)
+	for _, pattern := range extraPatterns {
+		if re, err := regexp.Compile(pattern); err == nil {
+			regexes = append(regexes, re)
+		}
+	}
+
+	lines := strings.Split(text, "\n")
+	start := 0
+	for start < len(lines) {
+		line := strings.TrimSpace(lines[start])
+		if line == "" {
+			start++
+			continue
+		}
+		matched := false
+		for _, re := range regexes {
+			if re.MatchString(line) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			break
+		}
+		start++
+	}
+	return strings.Join(lines[start:], "\n")
+}
+
+// postProcess applies the user's post-processing settings to an LLM response
+func (a *App) postProcess(text string) string {
+	return postProcessResponse(text, a.settings.PostProcessing)
+}
+
+// PostProcessResponse returns an LLM response cleaned up with the current post-processing settings
+func (a *App) PostProcessResponse(content string) string {
+	return a.postProcess(content)
+}
+
+// GetPostProcessingOptions returns the current response post-processing settings
+func (a *App) GetPostProcessingOptions() PostProcessingOptions {
+	return a.settings.PostProcessing
+}
+
+// SetPostProcessingOptions updates the response post-processing settings and saves them.
+// Custom preamble patterns must be valid regular expressions.
+func (a *App) SetPostProcessingOptions(opts PostProcessingOptions) error {
+	for _, pattern := range opts.PreamblePatterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid preamble pattern %q: %w", pattern, err)
+		}
+	}
+	if opts.PreamblePatterns == nil {
+		opts.PreamblePatterns = []string{}
+	}
+
+	a.settings.PostProcessing = opts
+	if err := a.saveSettings(); err != nil {
+		return fmt.Errorf("failed to save post-processing options: %w", err)
+	}
+	runtime.LogInfof(a.ctx, "Post-processing options updated: stripFences=%v, trimPreamble=%v, %d custom patterns",
+		opts.StripFences, opts.TrimPreamble, len(opts.PreamblePatterns))
+	return nil
+}
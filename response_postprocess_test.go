@@ -0,0 +1,34 @@
+package main
+
+import "testing"
+
+func TestStripMarkdownFencesKeepsRawDiff(t *testing.T) {
+	diff := "diff --git a/README.md b/README.md\n" +
+		"--- a/README.md\n" +
+		"+++ b/README.md\n" +
+		"@@ -1,4 +1,4 @@\n" +
+		" ```bash\n" +
+		"-make\n" +
+		"+make all\n" +
+		" ```\n"
+	if got := stripMarkdownFences(diff); got != diff {
+		t.Fatalf("raw diff changed:\n%s", got)
+	}
+	if got := postProcessResponse(diff, defaultPostProcessingOptions()); got != diff {
+		t.Fatalf("post-processing changed the raw diff:\n%s", got)
+	}
+}
+
+func TestStripMarkdownFencesFencedDiff(t *testing.T) {
+	diff := "--- a/README.md\n" +
+		"+++ b/README.md\n" +
+		"@@ -1,3 +1,3 @@\n" +
+		" ```bash\n" +
+		"-make\n" +
+		"+make all\n" +
+		" ```"
+	response := "Here is the change:\n\n```diff\n" + diff + "\n```\n\nRun the tests after applying it.\n"
+	if got, want := stripMarkdownFences(response), diff+"\n"; got != want {
+		t.Fatalf("stripMarkdownFences = %q, want %q", got, want)
+	}
+}
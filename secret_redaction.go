@@ -0,0 +1,260 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- Secrets Redaction ---
+//
+// Credentials committed by mistake must not end up in generated context or in a request to an
+// LLM provider. With RedactSecrets on (the default), likely secrets are replaced by a redaction
+// marker before file content is rendered into a block, attached, returned by an agent tool or
+// sent as a prompt:
+//   - private_key: PEM private key blocks, from the BEGIN line to the END line (or the end of
+//     the content if the block is cut)
+//   - aws_access_key: AWS access key IDs (AKIA..., ASIA...)
+//   - aws_secret_key: 40-character values assigned to aws_secret_access_key and similar names
+//   - github_token: GitHub personal access, OAuth and app tokens
+//   - jwt: JSON Web Tokens
+//   - env_value: every value of a .env file (.env, .env.local, prod.env; not .env.example)
+//
+// Only the secret is masked, so an .env line keeps its name: DB_PASSWORD=[REDACTED:env_value].
+// Markers use the [REDACTED:<kind>] form of content filters, so the egress audit counts them
+// too. Redactions of a generation are reported with "secretsRedacted" (file, kind and count,
+// never the value), and LLM responses report those of their prompt.
+
+// secretDetector finds one kind of secret
+type secretDetector struct {
+	kind  string
+	regex *regexp.Regexp
+	group int // Submatch holding the secret (0 for the whole match)
+}
+
+// secretDetectors run in order; private keys go first so no other detector matches inside one
+var secretDetectors = []secretDetector{
+	{"private_key", regexp.MustCompile(`(?s)-----BEGIN [A-Z0-9 ]*PRIVATE KEY-----(?:.*?-----END [A-Z0-9 ]*PRIVATE KEY-----|.*)`), 0},
+	{"aws_access_key", regexp.MustCompile(`\b(?:AKIA|ASIA|ABIA|ACCA)[0-9A-Z]{16}\b`), 0},
+	{"aws_secret_key", regexp.MustCompile(`(?i)aws_?secret_?(?:access_?)?key\w*["']?\s*[:=]\s*["']?([A-Za-z0-9/+]{40})`), 1},
+	{"github_token", regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,255}|github_pat_[A-Za-z0-9_]{22,255})\b`), 0},
+	{"jwt", regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]*`), 0},
+}
+
+// envValueDetector masks the values of .env files, after the other detectors
+var envValueDetector = secretDetector{"env_value", regexp.MustCompile(`(?m)^[ \t]*(?:export[ \t]+)?[A-Za-z_][A-Za-z0-9_.]*[ \t]*=[ \t]*(.*?)[ \t]*\r?$`), 1}
+
+// envTemplateSuffixes mark .env files holding placeholders, which are kept
+var envTemplateSuffixes = []string{".example", ".sample", ".template", ".dist", ".defaults"}
+
+// SecretFinding reports the secrets masked in one file
+type SecretFinding struct {
+	File    string `json:"file"`    // Path relative to the project root (absolute for attachments, empty for prompts)
+	Kind    string `json:"kind"`    // Kind of secret (private_key, aws_access_key, aws_secret_key, github_token, jwt, env_value)
+	Matches int    `json:"matches"` // Number of values masked
+}
+
+// secretRedactionRun collects the secrets masked during one generation
+type secretRedactionRun struct {
+	findings []SecretFinding
+}
+
+// isEnvFile reports whether a path names a .env file with real values
+func isEnvFile(relPath string) bool {
+	name := strings.ToLower(filepath.Base(filepath.FromSlash(relPath)))
+	if name != ".env" && !strings.HasPrefix(name, ".env.") && !strings.HasSuffix(name, ".env") {
+		return false
+	}
+	for _, suffix := range envTemplateSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return false
+		}
+	}
+	return true
+}
+
+// isMaskedValue reports whether a matched value is empty or already a redaction marker
+func isMaskedValue(value string) bool {
+	value = strings.Trim(value, `"'`)
+	return value == "" || redactionMarkerRegex.FindString(value) == value
+}
+
+// maskSecrets replaces the secrets one detector finds in content by its redaction marker
+func maskSecrets(content string, d secretDetector) (string, int) {
+	matches := d.regex.FindAllStringSubmatchIndex(content, -1)
+	if len(matches) == 0 {
+		return content, 0
+	}
+	var b strings.Builder
+	last, masked := 0, 0
+	for _, m := range matches {
+		start, end := m[2*d.group], m[2*d.group+1]
+		if start < 0 || isMaskedValue(content[start:end]) {
+			continue
+		}
+		b.WriteString(content[last:start])
+		b.WriteString("[REDACTED:" + d.kind + "]")
+		last = end
+		masked++
+	}
+	b.WriteString(content[last:])
+	return b.String(), masked
+}
+
+// redactSecrets masks the likely secrets of a file's content
+//
+// Parameters:
+//   - relPath: Path of the file (decides whether .env values are masked; empty for a prompt)
+//   - content: Content to scan
+//
+// Returns:
+//   - string: The content with secrets replaced by redaction markers
+//   - map[string]int: Values masked by kind (nil if there are none)
+func redactSecrets(relPath, content string) (string, map[string]int) {
+	detectors := secretDetectors
+	if isEnvFile(relPath) {
+		detectors = append(slices.Clip(detectors), envValueDetector)
+	}
+	var counts map[string]int
+	for _, d := range detectors {
+		var masked int
+		if content, masked = maskSecrets(content, d); masked > 0 {
+			if counts == nil {
+				counts = make(map[string]int)
+			}
+			counts[d.kind] += masked
+		}
+	}
+	return content, counts
+}
+
+// redactSecretsIfEnabled masks the likely secrets of content when RedactSecrets is on
+func (a *App) redactSecretsIfEnabled(relPath, content string) string {
+	if !a.settings.RedactSecrets {
+		return content
+	}
+	content, _ = redactSecrets(relPath, content)
+	return content
+}
+
+// secretFindings turns counts by kind into findings for one file, in detector order
+func secretFindings(file string, counts map[string]int) []SecretFinding {
+	var findings []SecretFinding
+	for _, d := range append(slices.Clip(secretDetectors), envValueDetector) {
+		if counts[d.kind] > 0 {
+			findings = append(findings, SecretFinding{File: file, Kind: d.kind, Matches: counts[d.kind]})
+		}
+	}
+	return findings
+}
+
+// newSecretRedactionRun returns the redaction state of a generation (nil if RedactSecrets is off)
+func (a *App) newSecretRedactionRun() *secretRedactionRun {
+	if !a.settings.RedactSecrets {
+		return nil
+	}
+	return &secretRedactionRun{}
+}
+
+// redact masks the secrets of content that is not rendered through a cached file block
+func (r *secretRedactionRun) redact(relPath, content string) string {
+	if r == nil {
+		return content
+	}
+	content, counts := redactSecrets(relPath, content)
+	r.findings = append(r.findings, secretFindings(relPath, counts)...)
+	return content
+}
+
+// record counts the secrets masked in a rendered file block
+// Blocks are redacted when rendered (see appendFileContent) and may come from the content
+// cache, so the findings are read back from their markers.
+func (r *secretRedactionRun) record(relPath, block string) {
+	if r == nil {
+		return
+	}
+	r.findings = append(r.findings, secretFindings(relPath, redactionCounts(block))...)
+}
+
+// emitSecretsRedacted logs and emits the secrets masked during a generation
+func (a *App) emitSecretsRedacted(rootDir string, r *secretRedactionRun) {
+	if r == nil || len(r.findings) == 0 {
+		return
+	}
+	total := 0
+	for _, finding := range r.findings {
+		total += finding.Matches
+	}
+	runtime.LogWarningf(a.ctx, "Redacted %d likely secrets from the context of %s", total, rootDir)
+	a.emitGenerationEvent("secretsRedacted", map[string]interface{}{
+		"rootDir":  rootDir,
+		"findings": r.findings,
+	})
+}
+
+// redactPromptSecrets masks the likely secrets of an LLM request's prompt and prefill
+//
+// Returns:
+//   - map[string]int: Values masked by kind (nil if there are none or RedactSecrets is off)
+func (a *App) redactPromptSecrets(req *LLMRequest) map[string]int {
+	if !a.settings.RedactSecrets {
+		return nil
+	}
+	var counts map[string]int
+	for _, text := range []*string{&req.Prompt, &req.AssistantPrefix} {
+		var masked map[string]int
+		*text, masked = redactSecrets("", *text)
+		for kind, n := range masked {
+			if counts == nil {
+				counts = make(map[string]int)
+			}
+			counts[kind] += n
+		}
+	}
+	if counts != nil {
+		runtime.LogWarningf(a.ctx, "Redacted likely secrets from the %s prompt: %v", req.Provider, counts)
+	}
+	return counts
+}
+
+// ============================================================================
+// Secrets Redaction Methods (Wails-bound)
+// ============================================================================
+
+// GetRedactSecrets returns whether likely secrets are masked before content leaves the app
+func (a *App) GetRedactSecrets() bool {
+	return a.settings.RedactSecrets
+}
+
+// SetRedactSecrets enables or disables secrets redaction and saves the setting
+func (a *App) SetRedactSecrets(enabled bool) error {
+	a.settings.RedactSecrets = enabled
+	if err := a.saveSettings(); err != nil {
+		return fmt.Errorf("failed to save secrets redaction setting: %w", err)
+	}
+	runtime.LogInfof(a.ctx, "Secrets redaction: %v", enabled)
+	return nil
+}
+
+// TestSecretRedaction masks the likely secrets of a text, for trying the detectors out
+// It applies regardless of the RedactSecrets setting.
+//
+// Parameters:
+//   - relPath: File name the text would have (e.g. ".env" to mask every value; may be empty)
+//   - text: Sample content
+//
+// Returns:
+//   - string: The text with secrets replaced by redaction markers
+//   - []SecretFinding: Values masked by kind, in detector order
+func (a *App) TestSecretRedaction(relPath, text string) (string, []SecretFinding) {
+	text, counts := redactSecrets(relPath, text)
+	findings := secretFindings(relPath, counts)
+	if findings == nil {
+		return text, []SecretFinding{}
+	}
+	return text, findings
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- Zip Export of the Selection ---
+//
+// Packages the selected files into a zip archive with their paths relative to the project root,
+// for chat UIs that accept archive uploads. Files are filtered like generated context: ignore
+// rules (per the current toggles) apply inside selected directories, and binary files are left out.
+
+// SelectionZipResult summarizes a zip export
+type SelectionZipResult struct {
+	Path          string   `json:"path"`          // Archive written, empty if the dialog was cancelled
+	Files         int      `json:"files"`         // Files added to the archive
+	Bytes         int64    `json:"bytes"`         // Uncompressed size of the added files
+	SkippedBinary []string `json:"skippedBinary"` // Selected files left out as binary (relative, forward slashes)
+}
+
+// collectSelectionFiles expands the selected paths into the files generation would include
+// Explicitly selected files bypass the ignore rules, like files ticked in the tree.
+func (a *App) collectSelectionFiles(rootDir string, includedPaths []string) ([]string, error) {
+	if err := a.validateContentRoot(rootDir); err != nil {
+		return nil, err
+	}
+	opts := a.newTreeBuildOptions(rootDir, a.compileProjectGitignore(rootDir))
+	seen := make(map[string]bool)
+	var files []string
+
+	for _, included := range includedPaths {
+		relPath := filepath.Clean(filepath.FromSlash(included))
+		if filepath.IsAbs(relPath) || relPath == ".." || strings.HasPrefix(relPath, ".."+string(filepath.Separator)) {
+			return nil, fmt.Errorf("path is outside the project: %s", included)
+		}
+		absPath := filepath.Join(rootDir, relPath)
+		info, err := a.projectFS.Stat(absPath)
+		if err != nil {
+			return nil, fmt.Errorf("cannot access %s: %w", included, err)
+		}
+		if !info.IsDir() {
+			if !seen[relPath] {
+				seen[relPath] = true
+				files = append(files, relPath)
+			}
+			continue
+		}
+
+		err = a.projectFS.WalkDir(absPath, func(path string, d fs.DirEntry, walkErr error) error {
+			if ctxErr := a.ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+			if walkErr != nil {
+				if d != nil && d.IsDir() && path != absPath {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			rel, _ := filepath.Rel(rootDir, path)
+			if path != absPath && opts.excludes(rel, d.IsDir()) {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !d.Type().IsRegular() || seen[rel] {
+				return nil
+			}
+			seen[rel] = true
+			files = append(files, rel)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+// writeSelectionZip writes the files into a zip archive at outPath
+func (a *App) writeSelectionZip(rootDir string, files []string, outPath string) (SelectionZipResult, error) {
+	result := SelectionZipResult{Path: outPath, SkippedBinary: []string{}}
+	if err := os.MkdirAll(filepath.Dir(outPath), 0755); err != nil {
+		return result, fmt.Errorf("failed to create directory for %s: %w", outPath, err)
+	}
+	out, err := os.Create(outPath)
+	if err != nil {
+		return result, fmt.Errorf("failed to create %s: %w", outPath, err)
+	}
+
+	zw := zip.NewWriter(out)
+	addFile := func(relPath string) error {
+		absPath := filepath.Join(rootDir, relPath)
+		if isBinary, err := a.isBinaryFileCached(absPath); err != nil || isBinary {
+			result.SkippedBinary = append(result.SkippedBinary, filepath.ToSlash(relPath))
+			return nil
+		}
+		f, err := a.projectFS.Open(absPath)
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %w", relPath, err)
+		}
+		defer f.Close()
+		info, err := f.Stat()
+		if err != nil {
+			return fmt.Errorf("failed to stat %s: %w", relPath, err)
+		}
+		header, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return fmt.Errorf("failed to add %s: %w", relPath, err)
+		}
+		header.Name = filepath.ToSlash(relPath)
+		header.Method = zip.Deflate
+		w, err := zw.CreateHeader(header)
+		if err != nil {
+			return fmt.Errorf("failed to add %s: %w", relPath, err)
+		}
+		n, err := io.Copy(w, f)
+		if err != nil {
+			return fmt.Errorf("failed to compress %s: %w", relPath, err)
+		}
+		result.Files++
+		result.Bytes += n
+		return nil
+	}
+
+	for _, relPath := range files {
+		if err = a.ctx.Err(); err != nil {
+			break
+		}
+		if err = addFile(relPath); err != nil {
+			break
+		}
+	}
+	if closeErr := zw.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to finish archive: %w", closeErr)
+	}
+	if closeErr := out.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to write %s: %w", outPath, closeErr)
+	}
+	if err != nil {
+		os.Remove(outPath) // Don't leave a partial archive behind
+		return result, err
+	}
+	return result, nil
+}
+
+// ============================================================================
+// Zip Export Methods (Wails-bound)
+// ============================================================================
+
+// ExportSelectionZip packages the selected files into a zip archive
+//
+// Parameters:
+//   - rootDir: Project root directory
+//   - includedPaths: Selected files and directories, relative to rootDir
+//   - outPath: Archive to write (empty to choose it with a save dialog)
+//
+// Returns:
+//   - SelectionZipResult: Archive path and what was added or skipped
+//   - error: Error if a path is invalid, nothing can be exported or the archive cannot be written
+func (a *App) ExportSelectionZip(rootDir string, includedPaths []string, outPath string) (SelectionZipResult, error) {
+	if !a.projectIsDir(rootDir) {
+		return SelectionZipResult{}, fmt.Errorf("project folder does not exist: %s", rootDir)
+	}
+	files, err := a.collectSelectionFiles(rootDir, includedPaths)
+	if err != nil {
+		return SelectionZipResult{}, err
+	}
+	if len(files) == 0 {
+		return SelectionZipResult{}, fmt.Errorf("the selection contains no files")
+	}
+
+	if strings.TrimSpace(outPath) == "" {
+		outPath, err = runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+			Title:                "Export Selection as Zip",
+			DefaultFilename:      filepath.Base(rootDir) + ".zip",
+			CanCreateDirectories: true,
+			Filters:              []runtime.FileFilter{{DisplayName: "Zip archives (*.zip)", Pattern: "*.zip"}},
+		})
+		if err != nil {
+			return SelectionZipResult{}, fmt.Errorf("failed to open save dialog: %w", err)
+		}
+		if outPath == "" {
+			return SelectionZipResult{SkippedBinary: []string{}}, nil
+		}
+	}
+
+	result, err := a.writeSelectionZip(rootDir, files, outPath)
+	if err != nil {
+		runtime.LogErrorf(a.ctx, "ExportSelectionZip: %v", err)
+		return SelectionZipResult{}, err
+	}
+	runtime.LogInfof(a.ctx, "Exported %d files (%d bytes) to %s, %d binary files skipped",
+		result.Files, result.Bytes, outPath, len(result.SkippedBinary))
+	return result, nil
+}
@@ -0,0 +1,385 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- Crash Recovery ---
+//
+// Minimal in-flight state (active generation, pending LLM calls, unapplied diffs) is kept in
+// <config dir>/session.json while the app runs. A clean shutdown marks the file as such; if the
+// next startup finds it unmarked, the previous session crashed and its state is offered for
+// recovery via GetRecoveredSession / ResumeRecoveredSession / DiscardRecoveredSession.
+// The file only keeps a redacted preview of each pending prompt; the full request is read back
+// from the call's workflow record. Unapplied diffs are capped by count and age.
+
+const (
+	maxSessionPromptPreview = 500                // Characters of a pending prompt kept in session.json
+	maxUnappliedDiffs       = 20                 // Newest unapplied diffs kept
+	maxUnappliedDiffAge     = 7 * 24 * time.Hour // Unapplied diffs older than this are dropped
+)
+
+// InFlightGeneration is a context generation that was running
+type InFlightGeneration struct {
+	RootDir       string            `json:"rootDir"`       // Project root directory
+	ExcludedPaths []string          `json:"excludedPaths"` // Exclusions of the generation
+	Options       GenerationOptions `json:"options"`       // Options of the generation
+	StartedAt     time.Time         `json:"startedAt"`     // When the generation started
+}
+
+// InFlightLLMCall is an LLM call that had not returned (the API key is never persisted)
+type InFlightLLMCall struct {
+	JobID     string     `json:"jobId"`     // Job ID of the call
+	Request   LLMRequest `json:"request"`   // Request without API key; the prompt is a redacted preview
+	StartedAt time.Time  `json:"startedAt"` // When the call started
+}
+
+// UnappliedDiff is a diff extracted from an LLM response that was not marked as applied
+type UnappliedDiff struct {
+	ID        string    `json:"id"`        // Identifier (the job ID of the response)
+	Diff      string    `json:"diff"`      // Extracted diff
+	CreatedAt time.Time `json:"createdAt"` // When the response was received
+}
+
+// SessionState is the in-flight state persisted for crash recovery
+type SessionState struct {
+	CleanShutdown    bool                `json:"cleanShutdown"`    // True once the app shut down normally
+	StartedAt        time.Time           `json:"startedAt"`        // When the session started
+	ActiveGeneration *InFlightGeneration `json:"activeGeneration"` // Running context generation, if any
+	PendingLLMCalls  []InFlightLLMCall   `json:"pendingLLMCalls"`  // LLM calls that had not returned
+	UnappliedDiffs   []UnappliedDiff     `json:"unappliedDiffs"`   // Extracted diffs not yet applied
+}
+
+// hasInFlightWork reports whether a session left anything worth recovering
+func (s *SessionState) hasInFlightWork() bool {
+	return s.ActiveGeneration != nil || len(s.PendingLLMCalls) > 0 || len(s.UnappliedDiffs) > 0
+}
+
+// sessionStatePath returns the path of session.json
+func (a *App) sessionStatePath() string {
+	return filepath.Join(filepath.Dir(a.configPath), "session.json")
+}
+
+// initSessionState detects an unclean shutdown of the previous session and starts a new one
+// Called during startup, after the config path is known
+func (a *App) initSessionState() {
+	if a.configPath == "" {
+		return
+	}
+
+	if data, err := os.ReadFile(a.sessionStatePath()); err == nil {
+		var previous SessionState
+		if err := json.Unmarshal(data, &previous); err != nil {
+			runtime.LogWarningf(a.ctx, "Ignoring unreadable session state: %v", err)
+		} else if !previous.CleanShutdown && previous.hasInFlightWork() {
+			runtime.LogWarningf(a.ctx, "Previous session (started %s) did not shut down cleanly; recovery available", previous.StartedAt.Format(time.RFC3339))
+			a.recoveredSession = &previous
+		} else {
+			// Unapplied diffs survive clean restarts too
+			a.session.UnappliedDiffs = pruneUnappliedDiffs(previous.UnappliedDiffs)
+		}
+	}
+
+	a.session.StartedAt = time.Now()
+	a.saveSessionStateLocked()
+}
+
+// saveSessionStateLocked writes session.json (callers hold sessionMu, or run before concurrency starts)
+func (a *App) saveSessionStateLocked() {
+	if a.configPath == "" {
+		return
+	}
+	data, err := json.MarshalIndent(a.session, "", "  ")
+	if err != nil {
+		runtime.LogWarningf(a.ctx, "Failed to encode session state: %v", err)
+		return
+	}
+	tmpPath := a.sessionStatePath() + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		runtime.LogWarningf(a.ctx, "Failed to write session state: %v", err)
+		return
+	}
+	if err := os.Rename(tmpPath, a.sessionStatePath()); err != nil {
+		runtime.LogWarningf(a.ctx, "Failed to replace session state: %v", err)
+	}
+}
+
+// updateSessionState applies a change to the in-flight state and persists it
+func (a *App) updateSessionState(update func(s *SessionState)) {
+	a.sessionMu.Lock()
+	defer a.sessionMu.Unlock()
+	update(&a.session)
+	a.saveSessionStateLocked()
+}
+
+// trackGeneration records the running context generation (nil rootDir clears it); the
+// summary API key is stripped
+func (a *App) trackGeneration(rootDir string, excludedPaths []string, opts GenerationOptions) {
+	opts.SummaryAPIKey = ""
+	a.updateSessionState(func(s *SessionState) {
+		if rootDir == "" {
+			s.ActiveGeneration = nil
+			return
+		}
+		s.ActiveGeneration = &InFlightGeneration{
+			RootDir:       rootDir,
+			ExcludedPaths: append([]string{}, excludedPaths...),
+			Options:       opts,
+			StartedAt:     time.Now(),
+		}
+	})
+}
+
+// sessionPromptPreview cuts a prompt to the preview kept in session.json, with secrets redacted
+func sessionPromptPreview(prompt string) string {
+	// Redact with some room past the cut so a secret straddling it is still recognized
+	runes := []rune(prompt)
+	if len(runes) > 2*maxSessionPromptPreview {
+		runes = runes[:2*maxSessionPromptPreview]
+	}
+	redacted, _ := redactSecrets("", string(runes))
+	if runes = []rune(redacted); len(runes) > maxSessionPromptPreview {
+		return string(runes[:maxSessionPromptPreview]) + "..."
+	}
+	return redacted
+}
+
+// trackLLMCall records an LLM call that is in progress; the API key is stripped and the
+// prompt cut to a redacted preview
+func (a *App) trackLLMCall(jobID string, req LLMRequest) {
+	req.APIKey = ""
+	req.Prompt = sessionPromptPreview(req.Prompt)
+	a.updateSessionState(func(s *SessionState) {
+		s.PendingLLMCalls = append(s.PendingLLMCalls, InFlightLLMCall{JobID: jobID, Request: req, StartedAt: time.Now()})
+	})
+}
+
+// untrackLLMCall removes a finished LLM call
+func (a *App) untrackLLMCall(jobID string) {
+	a.updateSessionState(func(s *SessionState) {
+		for i, call := range s.PendingLLMCalls {
+			if call.JobID == jobID {
+				s.PendingLLMCalls = append(s.PendingLLMCalls[:i], s.PendingLLMCalls[i+1:]...)
+				return
+			}
+		}
+	})
+}
+
+// trackUnappliedDiff remembers the diff in an LLM response until it is marked as applied
+func (a *App) trackUnappliedDiff(jobID, content string) {
+	processed := a.postProcess(content)
+	if !diffStartRegex.MatchString(processed) {
+		return
+	}
+	a.updateSessionState(func(s *SessionState) {
+		s.UnappliedDiffs = pruneUnappliedDiffs(append(s.UnappliedDiffs, UnappliedDiff{ID: jobID, Diff: processed, CreatedAt: time.Now()}))
+	})
+}
+
+// pruneUnappliedDiffs drops unapplied diffs older than maxUnappliedDiffAge and keeps the
+// newest maxUnappliedDiffs of the rest
+func pruneUnappliedDiffs(diffs []UnappliedDiff) []UnappliedDiff {
+	cutoff := time.Now().Add(-maxUnappliedDiffAge)
+	kept := make([]UnappliedDiff, 0, len(diffs))
+	for _, diff := range diffs {
+		if diff.CreatedAt.After(cutoff) {
+			kept = append(kept, diff)
+		}
+	}
+	sort.SliceStable(kept, func(i, j int) bool { return kept[i].CreatedAt.Before(kept[j].CreatedAt) })
+	if len(kept) > maxUnappliedDiffs {
+		kept = kept[len(kept)-maxUnappliedDiffs:]
+	}
+	return kept
+}
+
+// forgetAppliedDiff drops the unapplied diffs matching a patch that was applied cleanly
+func (a *App) forgetAppliedDiff(patch string) {
+	patch = strings.TrimSpace(patch)
+	a.updateSessionState(func(s *SessionState) {
+		kept := s.UnappliedDiffs[:0]
+		for _, diff := range s.UnappliedDiffs {
+			if strings.TrimSpace(diff.Diff) != patch {
+				kept = append(kept, diff)
+			}
+		}
+		s.UnappliedDiffs = kept
+	})
+}
+
+// markCleanShutdown records that the app is shutting down normally
+func (a *App) markCleanShutdown() {
+	a.updateSessionState(func(s *SessionState) {
+		s.CleanShutdown = true
+		s.ActiveGeneration = nil
+		s.PendingLLMCalls = nil
+	})
+}
+
+// shutdown is called by Wails when the application is closing
+func (a *App) shutdown(ctx context.Context) {
+	if a.fileWatcher != nil {
+		a.fileWatcher.Stop()
+	}
+	if a.jobQueue != nil {
+		a.jobQueue.CancelAllJobs()
+	}
+	a.stopProfiling()
+	a.markCleanShutdown()
+}
+
+// ============================================================================
+// Crash Recovery Methods (Wails-bound)
+// ============================================================================
+
+// GetRecoveredSession returns the in-flight state of a previous session that crashed
+//
+// Returns:
+//   - *SessionState: State of the crashed session, or nil if the last shutdown was clean
+func (a *App) GetRecoveredSession() *SessionState {
+	a.sessionMu.Lock()
+	defer a.sessionMu.Unlock()
+	return a.recoveredSession
+}
+
+// ResumeRecoveredSession restarts the interrupted context generation of a crashed session
+// (from its checkpoint if one exists) and keeps its unapplied diffs. Interrupted LLM calls
+// need an API key again and are resumed one by one with ResumeRecoveredLLMCall.
+func (a *App) ResumeRecoveredSession() error {
+	a.sessionMu.Lock()
+	recovered := a.recoveredSession
+	a.sessionMu.Unlock()
+	if recovered == nil {
+		return fmt.Errorf("no crashed session to recover")
+	}
+
+	a.updateSessionState(func(s *SessionState) {
+		s.UnappliedDiffs = pruneUnappliedDiffs(append(s.UnappliedDiffs, recovered.UnappliedDiffs...))
+	})
+
+	if gen := recovered.ActiveGeneration; gen != nil {
+		if a.GetGenerationCheckpoint(gen.RootDir) != nil {
+			if err := a.ResumeShotgunContextGeneration(gen.RootDir); err != nil {
+				return err
+			}
+		} else {
+			a.requestShotgunContextGeneration(gen.RootDir, gen.ExcludedPaths, false, gen.Options)
+		}
+	}
+
+	if len(recovered.PendingLLMCalls) == 0 {
+		a.sessionMu.Lock()
+		a.recoveredSession = nil
+		a.sessionMu.Unlock()
+	} else {
+		a.sessionMu.Lock()
+		recovered.ActiveGeneration = nil
+		recovered.UnappliedDiffs = nil
+		a.sessionMu.Unlock()
+	}
+	runtime.LogInfo(a.ctx, "Resumed crashed session")
+	return nil
+}
+
+// ResumeRecoveredLLMCall re-sends an LLM call that was interrupted by a crash
+// The full request is read from the call's workflow record.
+//
+// Parameters:
+//   - jobID: Job ID of the interrupted call (from GetRecoveredSession)
+//   - apiKey: API key for the provider (never persisted)
+//
+// Returns:
+//   - string: Job ID of the new call
+//   - error: Error if the call is not part of the crashed session or its workflow is gone
+func (a *App) ResumeRecoveredLLMCall(jobID, apiKey string) (string, error) {
+	a.sessionMu.Lock()
+	found := false
+	if a.recoveredSession != nil {
+		for _, call := range a.recoveredSession.PendingLLMCalls {
+			if call.JobID == jobID {
+				found = true
+				break
+			}
+		}
+	}
+	a.sessionMu.Unlock()
+	if !found {
+		return "", fmt.Errorf("no interrupted LLM call found for job: %s", jobID)
+	}
+
+	a.workflowMu.Lock()
+	w, err := a.loadWorkflowLocked(jobID)
+	a.workflowMu.Unlock()
+	if err != nil {
+		return "", fmt.Errorf("the prompt of interrupted call %s is no longer stored: %w", jobID, err)
+	}
+
+	a.sessionMu.Lock()
+	if a.recoveredSession != nil {
+		calls := a.recoveredSession.PendingLLMCalls
+		for i, call := range calls {
+			if call.JobID == jobID {
+				a.recoveredSession.PendingLLMCalls = append(calls[:i], calls[i+1:]...)
+				break
+			}
+		}
+		if !a.recoveredSession.hasInFlightWork() {
+			a.recoveredSession = nil
+		}
+	}
+	a.sessionMu.Unlock()
+
+	req := w.Request
+	req.APIKey = apiKey
+	return a.CallLLMAPIWithRequest(req)
+}
+
+// DiscardRecoveredSession drops the state of a crashed session, including its generation checkpoint
+func (a *App) DiscardRecoveredSession() error {
+	a.sessionMu.Lock()
+	recovered := a.recoveredSession
+	a.recoveredSession = nil
+	a.sessionMu.Unlock()
+
+	if recovered != nil && recovered.ActiveGeneration != nil {
+		return a.DiscardGenerationCheckpoint(recovered.ActiveGeneration.RootDir)
+	}
+	return nil
+}
+
+// GetUnappliedDiffs returns the diffs extracted from LLM responses that were not marked as applied
+func (a *App) GetUnappliedDiffs() []UnappliedDiff {
+	a.sessionMu.Lock()
+	defer a.sessionMu.Unlock()
+	diffs := append([]UnappliedDiff{}, a.session.UnappliedDiffs...)
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].CreatedAt.After(diffs[j].CreatedAt) })
+	return diffs
+}
+
+// MarkDiffApplied forgets an unapplied diff once it has been applied or dismissed
+func (a *App) MarkDiffApplied(id string) error {
+	found := false
+	a.updateSessionState(func(s *SessionState) {
+		for i, diff := range s.UnappliedDiffs {
+			if diff.ID == id {
+				s.UnappliedDiffs = append(s.UnappliedDiffs[:i], s.UnappliedDiffs[i+1:]...)
+				found = true
+				return
+			}
+		}
+	})
+	if !found {
+		return fmt.Errorf("unapplied diff not found: %s", id)
+	}
+	return nil
+}
@@ -17,6 +17,9 @@ import (
 func (a *App) SplitShotgunDiff(gitDiffText string, approxLineLimit int) ([]string, error) {
 	runtime.LogInfof(a.ctx, "SplitShotgunDiff called with line limit: %d for git diff text", approxLineLimit)
 
+	// Strip Markdown fences and prose the model may have wrapped around the diff
+	gitDiffText = a.postProcess(gitDiffText)
+
 	if strings.TrimSpace(gitDiffText) == "" {
 		return []string{}, nil
 	}
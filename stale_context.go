@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// --- Stale Context Detection ---
+//
+// Generated context is a copy of the project at one moment; files edited afterwards make it
+// stale, and an LLM answering from it works on outdated code. GetStaleContextStatus compares
+// the project's most recent generation with the changes seen since (watcher history and git,
+// as for the selection delta): every changed, created or deleted file inside that
+// generation's selection makes the context one file more out of date. Files the selection
+// excluded or ignore rules hide do not count. The frontend checks the status before an LLM
+// call and warns when the context is stale.
+
+// StaleFile is a file that changed after the context was generated
+type StaleFile struct {
+	Path    string `json:"path"`    // Path relative to the project root (forward slashes)
+	Deleted bool   `json:"deleted"` // True if the file no longer exists
+}
+
+// StaleContextStatus tells how out of date the last generated context of a project is
+type StaleContextStatus struct {
+	RootDir      string      `json:"rootDir"`      // Project root directory
+	HasContext   bool        `json:"hasContext"`   // False if the project has not been generated yet
+	GeneratedAt  time.Time   `json:"generatedAt"`  // When the context was generated
+	Stale        bool        `json:"stale"`        // True if any selected file changed since
+	ChangedCount int         `json:"changedCount"` // Number of selected files changed since
+	Changed      []StaleFile `json:"changed"`      // Those files, by path
+	Sources      []string    `json:"sources"`      // Where changes were found (watcher, git)
+	Message      string      `json:"message"`      // Summary shown to the user
+}
+
+// ============================================================================
+// Stale Context Methods (Wails-bound)
+// ============================================================================
+
+// GetStaleContextStatus reports the files of the last generated context of a project that
+// changed since it was generated
+//
+// Parameters:
+//   - rootDir: Project root directory
+//
+// Returns:
+//   - StaleContextStatus: Changed files within the generation's selection (HasContext is false
+//     if the project has no generated context)
+func (a *App) GetStaleContextStatus(rootDir string) StaleContextStatus {
+	status := StaleContextStatus{RootDir: rootDir, Changed: []StaleFile{}, Sources: []string{}}
+	last := a.latestGeneration()
+	if last == nil || last.rootDir != rootDir {
+		status.Message = "No context generated for this project yet"
+		return status
+	}
+	status.HasContext = true
+	status.GeneratedAt = last.generatedAt
+
+	excluded := make(map[string]bool, len(last.excludedPaths))
+	for _, p := range last.excludedPaths {
+		excluded[p] = true
+	}
+	ignoreOpts := a.newTreeBuildOptions(rootDir, a.compileProjectGitignore(rootDir))
+	candidates, sources := a.changedFilesSince(rootDir, "", last.generatedAt)
+	status.Sources = sources
+	for _, relPath := range candidates {
+		if excludedBySelection(excluded, relPath) || ignoreOpts.excludes(relPath, false) {
+			continue
+		}
+		info, err := a.projectFS.Stat(filepath.Join(rootDir, relPath))
+		if err == nil && info.IsDir() {
+			continue
+		}
+		status.Changed = append(status.Changed, StaleFile{Path: filepath.ToSlash(relPath), Deleted: err != nil})
+	}
+	sort.Slice(status.Changed, func(i, j int) bool { return status.Changed[i].Path < status.Changed[j].Path })
+	status.ChangedCount = len(status.Changed)
+	status.Stale = status.ChangedCount > 0
+
+	switch status.ChangedCount {
+	case 0:
+		status.Message = "Your context is up to date"
+	case 1:
+		status.Message = "Your context is 1 file out of date"
+	default:
+		status.Message = fmt.Sprintf("Your context is %d files out of date", status.ChangedCount)
+	}
+	return status
+}
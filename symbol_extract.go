@@ -0,0 +1,350 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- Symbol-Level Extraction ---
+//
+// ExtractSymbols cuts named functions, methods and types out of a file, with their doc
+// comments and the file's imports, so a prompt can carry "only HandleLogin and its helpers"
+// instead of the whole file. Go is parsed with go/parser (methods match by name or as
+// Type.Method). Python blocks end where the indentation returns to the declaration's level.
+// Other languages (JavaScript, TypeScript, Java, C-like, Rust...) are matched with declaration
+// heuristics and end at the brace closing the declaration's body.
+
+// ExtractedSymbol is the source of one symbol
+type ExtractedSymbol struct {
+	Name      string `json:"name"`      // Name as requested
+	Kind      string `json:"kind"`      // func, method, type, var, const, class or symbol
+	StartLine int    `json:"startLine"` // First line, doc comment included (1-based)
+	EndLine   int    `json:"endLine"`   // Last line (1-based, inclusive)
+	Source    string `json:"source"`    // Source text, doc comment included
+}
+
+// SymbolExtraction is the result of ExtractSymbols
+type SymbolExtraction struct {
+	RelPath string            `json:"relPath"` // Path relative to the project root (forward slashes)
+	Imports string            `json:"imports"` // Import statements of the file
+	Symbols []ExtractedSymbol `json:"symbols"` // Symbols found, in file order
+	Missing []string          `json:"missing"` // Requested names not found
+	Content string            `json:"content"` // File block ready for the context (imports, then symbols)
+	Tokens  int               `json:"tokens"`  // Estimated tokens of Content
+}
+
+// pyDeclRegex matches Python function and class declarations
+var pyDeclRegex = regexp.MustCompile(`^(\s*)(?:async\s+)?(def|class)\s+(\w+)`)
+
+// braceDeclRegex matches declarations of brace languages, capturing the declared name
+var braceDeclRegex = regexp.MustCompile(`^\s*(?:(?:export|default|public|private|protected|internal|static|abstract|final|async|pub(?:\([^)]*\))?|unsafe|extern|override|virtual|inline|declare|readonly)\s+)*(?:(function\*?|class|interface|type|enum|struct|trait|impl|fn|func|record|object|module|namespace)\s+|(?:const|let|var|val)\s+)?([A-Za-z_$][\w$]*)`)
+
+// importLineRegex matches import lines of the languages handled heuristically
+var importLineRegex = regexp.MustCompile(`^\s*(?:import\s|from\s+\S+\s+import\s|use\s|#include\s|require\s|(?:const|let|var)\s+.*=\s*require\()`)
+
+// extractGoSymbols extracts symbols from Go source using the parser
+func extractGoSymbols(content string, wanted map[string]bool) (string, []ExtractedSymbol, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", content, parser.ParseComments)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to parse Go source: %w", err)
+	}
+	source := func(from, to token.Pos) (string, int, int) {
+		start, end := fset.Position(from), fset.Position(to)
+		return content[start.Offset:end.Offset], start.Line, end.Line
+	}
+
+	var imports []string
+	var symbols []ExtractedSymbol
+	add := func(name, kind string, doc *ast.CommentGroup, from, to token.Pos) {
+		if doc != nil {
+			from = doc.Pos()
+		}
+		text, startLine, endLine := source(from, to)
+		symbols = append(symbols, ExtractedSymbol{Name: name, Kind: kind, StartLine: startLine, EndLine: endLine, Source: text})
+	}
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			name, kind := d.Name.Name, "func"
+			qualified := name
+			if d.Recv != nil && len(d.Recv.List) > 0 {
+				kind = "method"
+				recv := d.Recv.List[0].Type
+				if star, ok := recv.(*ast.StarExpr); ok {
+					recv = star.X
+				}
+				if index, ok := recv.(*ast.IndexExpr); ok {
+					recv = index.X // Generic receiver
+				}
+				if ident, ok := recv.(*ast.Ident); ok {
+					qualified = ident.Name + "." + name
+				}
+			}
+			if wanted[qualified] {
+				add(qualified, kind, d.Doc, d.Pos(), d.End())
+			} else if wanted[name] {
+				add(name, kind, d.Doc, d.Pos(), d.End())
+			}
+		case *ast.GenDecl:
+			if d.Tok == token.IMPORT {
+				text, _, _ := source(d.Pos(), d.End())
+				imports = append(imports, text)
+				continue
+			}
+			for _, spec := range d.Specs {
+				var names []*ast.Ident
+				var doc *ast.CommentGroup
+				kind := strings.ToLower(d.Tok.String())
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					names, doc = []*ast.Ident{s.Name}, s.Doc
+				case *ast.ValueSpec:
+					names, doc = s.Names, s.Doc
+				}
+				for _, ident := range names {
+					if !wanted[ident.Name] {
+						continue
+					}
+					if len(d.Specs) == 1 {
+						// A lone spec is extracted with its keyword and the declaration's doc
+						if doc == nil {
+							doc = d.Doc
+						}
+						add(ident.Name, kind, doc, d.Pos(), d.End())
+					} else {
+						text, _, _ := source(spec.Pos(), spec.End())
+						from := spec.Pos()
+						if doc != nil {
+							from = doc.Pos()
+						}
+						_, startLine, endLine := source(from, spec.End())
+						prefix := ""
+						if doc != nil {
+							prefix = doc.Text()
+							prefix = "// " + strings.ReplaceAll(strings.TrimSpace(prefix), "\n", "\n// ") + "\n"
+						}
+						symbols = append(symbols, ExtractedSymbol{Name: ident.Name, Kind: kind, StartLine: startLine, EndLine: endLine, Source: prefix + d.Tok.String() + " " + text})
+					}
+					break
+				}
+			}
+		}
+	}
+	return strings.Join(imports, "\n"), symbols, nil
+}
+
+// docStart returns the first line of the comments and decorators directly above line i
+func docStart(lines []string, i int, python bool) int {
+	for i > 0 {
+		prev := strings.TrimSpace(lines[i-1])
+		isDoc := strings.HasPrefix(prev, "@") || strings.HasPrefix(prev, "#")
+		if !python {
+			isDoc = isDoc || strings.HasPrefix(prev, "//") || strings.HasPrefix(prev, "/*") ||
+				strings.HasPrefix(prev, "*") || strings.HasPrefix(prev, "#[")
+		}
+		if !isDoc || prev == "" {
+			break
+		}
+		i--
+	}
+	return i
+}
+
+// pythonBlockEnd returns the last line of the Python block declared at line i
+func pythonBlockEnd(lines []string, i int, indent string) int {
+	end := i
+	for j := i + 1; j < len(lines); j++ {
+		trimmed := strings.TrimSpace(lines[j])
+		if trimmed == "" {
+			continue
+		}
+		if len(lines[j])-len(strings.TrimLeft(lines[j], " \t")) <= len(indent) {
+			break
+		}
+		end = j
+	}
+	return end
+}
+
+// braceBlockEnd returns the last line of the declaration starting at line i: the line of the
+// brace closing its body, or the line ending it with a semicolon if it has no body
+func braceBlockEnd(lines []string, i int) int {
+	depth := 0
+	opened := false
+	var quote byte
+	for j := i; j < len(lines); j++ {
+		line := lines[j]
+		for k := 0; k < len(line); k++ {
+			c := line[k]
+			switch {
+			case quote != 0:
+				if c == '\\' {
+					k++
+				} else if c == quote {
+					quote = 0
+				}
+			case c == '"' || c == '\'' || c == '`':
+				quote = c
+			case c == '/' && k+1 < len(line) && line[k+1] == '/':
+				k = len(line)
+			case c == '{':
+				depth++
+				opened = true
+			case c == '}':
+				depth--
+				if opened && depth == 0 {
+					return j
+				}
+			}
+		}
+		if !opened && strings.HasSuffix(strings.TrimSpace(line), ";") {
+			return j
+		}
+		if quote != '`' {
+			quote = 0 // Only template literals span lines
+		}
+	}
+	return len(lines) - 1
+}
+
+// extractHeuristicSymbols extracts symbols from Python or brace-language source
+func extractHeuristicSymbols(content string, wanted map[string]bool, python bool) (string, []ExtractedSymbol) {
+	lines := strings.Split(content, "\n")
+	var imports []string
+	var symbols []ExtractedSymbol
+	found := make(map[string]bool)
+	for i, line := range lines {
+		if importLineRegex.MatchString(line) && (!python || !strings.HasPrefix(line, " ")) {
+			imports = append(imports, line)
+			continue
+		}
+		var name, kind string
+		end := i
+		if python {
+			m := pyDeclRegex.FindStringSubmatch(line)
+			if m == nil || !wanted[m[3]] || found[m[3]] {
+				continue
+			}
+			name, kind = m[3], map[string]string{"def": "func", "class": "class"}[m[2]]
+			end = pythonBlockEnd(lines, i, m[1])
+		} else {
+			m := braceDeclRegex.FindStringSubmatch(line)
+			if m == nil || !wanted[m[2]] || found[m[2]] {
+				continue
+			}
+			rest := strings.TrimSpace(line[len(m[0]):])
+			// A bare name only declares something when followed by a parameter list or type
+			if m[1] == "" && !strings.HasPrefix(rest, "(") && !strings.HasPrefix(rest, "=") && !strings.HasPrefix(rest, ":") && !strings.HasPrefix(rest, "<") {
+				continue
+			}
+			name, kind = m[2], "symbol"
+			switch m[1] {
+			case "class", "struct", "trait", "impl", "record", "object":
+				kind = "class"
+			case "interface", "type", "enum":
+				kind = "type"
+			case "function", "function*", "fn", "func":
+				kind = "func"
+			}
+			end = braceBlockEnd(lines, i)
+		}
+		found[name] = true
+		start := docStart(lines, i, python)
+		symbols = append(symbols, ExtractedSymbol{
+			Name:      name,
+			Kind:      kind,
+			StartLine: start + 1,
+			EndLine:   end + 1,
+			Source:    strings.Join(lines[start:end+1], "\n"),
+		})
+	}
+	return strings.Join(imports, "\n"), symbols
+}
+
+// ============================================================================
+// Symbol Extraction Methods (Wails-bound)
+// ============================================================================
+
+// ExtractSymbols returns the source of named functions, methods and types of a file
+//
+// Parameters:
+//   - rootDir: Project root directory
+//   - relPath: File path relative to the root
+//   - symbolNames: Names to extract (Go methods also as Type.Method)
+//
+// Returns:
+//   - SymbolExtraction: Imports, symbols found, names missing and a context block
+//   - error: Error if the file cannot be read or parsed, or no name is given
+func (a *App) ExtractSymbols(rootDir, relPath string, symbolNames []string) (SymbolExtraction, error) {
+	wanted := make(map[string]bool)
+	for _, name := range symbolNames {
+		if name = strings.TrimSpace(name); name != "" {
+			wanted[name] = true
+		}
+	}
+	if len(wanted) == 0 {
+		return SymbolExtraction{}, fmt.Errorf("no symbol names given")
+	}
+	if err := a.validateContentRoot(rootDir); err != nil {
+		return SymbolExtraction{}, err
+	}
+	absPath, err := resolveProjectPath(rootDir, relPath)
+	if err != nil {
+		return SymbolExtraction{}, err
+	}
+	data, err := a.projectFS.ReadFile(absPath)
+	if err != nil {
+		return SymbolExtraction{}, fmt.Errorf("failed to read %s: %w", relPath, err)
+	}
+	content := a.redactSecretsIfEnabled(relPath, strings.ReplaceAll(string(data), "\r\n", "\n"))
+
+	result := SymbolExtraction{RelPath: filepath.ToSlash(relPath), Symbols: []ExtractedSymbol{}, Missing: []string{}}
+	switch strings.ToLower(filepath.Ext(relPath)) {
+	case ".go":
+		if result.Imports, result.Symbols, err = extractGoSymbols(content, wanted); err != nil {
+			return SymbolExtraction{}, err
+		}
+	case ".py", ".pyi":
+		result.Imports, result.Symbols = extractHeuristicSymbols(content, wanted, true)
+	default:
+		result.Imports, result.Symbols = extractHeuristicSymbols(content, wanted, false)
+	}
+	if result.Symbols == nil {
+		result.Symbols = []ExtractedSymbol{}
+	}
+
+	found := make(map[string]bool)
+	parts := []string{}
+	if result.Imports != "" {
+		parts = append(parts, result.Imports)
+	}
+	for _, symbol := range result.Symbols {
+		found[symbol.Name] = true
+		parts = append(parts, symbol.Source)
+	}
+	for name := range wanted {
+		if !found[name] {
+			result.Missing = append(result.Missing, name)
+		}
+	}
+	sort.Strings(result.Missing)
+
+	var names []string
+	for _, symbol := range result.Symbols {
+		names = append(names, symbol.Name)
+	}
+	result.Content = fileBlock(result.RelPath, fmt.Sprintf(" symbols=\"%s\"", fileBlockAttr(strings.Join(names, ", "))), strings.Join(parts, "\n\n"), a.fileBlockEscaping())
+	result.Tokens = a.EstimateTokens(result.Content)
+
+	runtime.LogInfof(a.ctx, "Extracted %d symbols from %s (%d missing)", len(result.Symbols), relPath, len(result.Missing))
+	return result, nil
+}
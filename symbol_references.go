@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- Symbol References ---
+//
+// FindReferences searches the project for a symbol as a whole word, like ripgrep would, over
+// the files ignore rules do not hide (binary and very large files are skipped). Each hit is
+// classified with language heuristics:
+//   - definition: the declaration of the symbol (func, def, class, const...)
+//   - call: the symbol followed by an argument list
+//   - import: an import or use statement naming the symbol
+//   - comment: the hit is in a comment
+//   - reference: any other use (passed as a value, type annotation...)
+//
+// Callers lists the files with calls or references, for adding everything that calls the
+// function under investigation to a debug-mode selection with ApplySelectionDelta.
+
+const maxReferenceResults = 1000 // Hits returned at most
+
+// SymbolReference is a line referencing a symbol
+type SymbolReference struct {
+	RelPath string `json:"relPath"` // Path relative to the project root (forward slashes)
+	Line    int    `json:"line"`    // Line number (1-based)
+	Column  int    `json:"column"`  // Column of the first hit on the line (1-based, in bytes)
+	Text    string `json:"text"`    // The line, trimmed
+	Kind    string `json:"kind"`    // definition, call, import, comment or reference
+}
+
+// ReferenceSearch is the result of FindReferences
+type ReferenceSearch struct {
+	Symbol     string            `json:"symbol"`     // Symbol searched
+	References []SymbolReference `json:"references"` // Hits, by file then line
+	Callers    []string          `json:"callers"`    // Files with calls or references, sorted
+	Truncated  bool              `json:"truncated"`  // True if hits beyond maxReferenceResults were dropped
+}
+
+// symbolNameRegex restricts searched symbols to identifiers, optionally qualified
+var symbolNameRegex = regexp.MustCompile(`^[A-Za-z_$][\w$]*(?:\.[A-Za-z_$][\w$]*)*$`)
+
+// classifyReference tells how a line uses a symbol found at column col
+func classifyReference(line, name string, col int, definition *regexp.Regexp) string {
+	trimmed := strings.TrimSpace(line)
+	before := line[:col]
+	if strings.HasPrefix(trimmed, "//") || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "*") ||
+		strings.HasPrefix(trimmed, "/*") || strings.Contains(before, "//") || strings.Contains(before, " # ") {
+		return "comment"
+	}
+	if definition.MatchString(line) {
+		return "definition"
+	}
+	if importLineRegex.MatchString(line) {
+		return "import"
+	}
+	after := strings.TrimLeft(line[col+len(name):], " \t")
+	if strings.HasPrefix(after, "(") || strings.HasPrefix(after, "[") && strings.Contains(after, "](") ||
+		strings.HasPrefix(after, "<") && strings.Contains(after, ">(") {
+		return "call"
+	}
+	return "reference"
+}
+
+// ============================================================================
+// Symbol Reference Methods (Wails-bound)
+// ============================================================================
+
+// FindReferences finds the lines of a project that reference a symbol
+//
+// Parameters:
+//   - rootDir: Project root directory
+//   - symbol: Identifier to search (for Type.Method, the method name is searched)
+//
+// Returns:
+//   - ReferenceSearch: Classified hits and the files calling the symbol
+//   - error: Error if the symbol is not an identifier or the project cannot be walked
+func (a *App) FindReferences(rootDir, symbol string) (ReferenceSearch, error) {
+	symbol = strings.TrimSpace(symbol)
+	if !symbolNameRegex.MatchString(symbol) {
+		return ReferenceSearch{}, fmt.Errorf("not an identifier: %q", symbol)
+	}
+	if err := a.validateContentRoot(rootDir); err != nil {
+		return ReferenceSearch{}, err
+	}
+	name := symbol[strings.LastIndex(symbol, ".")+1:]
+	quoted := regexp.QuoteMeta(name)
+	word := regexp.MustCompile(`(?:^|[^\w$])(` + quoted + `)(?:[^\w$]|$)`)
+	definition := regexp.MustCompile(`(?:\b(?:func|def|class|interface|type|enum|struct|trait|fn|function\*?|const|let|var|val)\s+(?:\([^)]*\)\s*)?` + quoted + `\b)|^\s*` + quoted + `\s*[:=]`)
+
+	_, files, err := a.walkSelectionEntries(rootDir)
+	if err != nil {
+		return ReferenceSearch{}, err
+	}
+	paths := make([]string, 0, len(files))
+	for relPath := range files {
+		paths = append(paths, relPath)
+	}
+	sort.Strings(paths)
+
+	result := ReferenceSearch{Symbol: symbol, References: []SymbolReference{}, Callers: []string{}}
+	callers := make(map[string]bool)
+	for _, relPath := range paths {
+		entry := files[relPath]
+		if entry.size > maxImportScanSize {
+			continue
+		}
+		if isBinary, err := a.isBinaryFileCached(entry.absPath); err != nil || isBinary {
+			continue
+		}
+		data, err := a.projectFS.ReadFile(entry.absPath)
+		if err != nil || !bytes.Contains(data, []byte(name)) {
+			continue
+		}
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		scanner.Buffer(make([]byte, 64*1024), maxImportScanSize)
+		for lineNum := 1; scanner.Scan(); lineNum++ {
+			line := scanner.Text()
+			loc := word.FindStringSubmatchIndex(line)
+			if loc == nil {
+				continue
+			}
+			if len(result.References) >= maxReferenceResults {
+				result.Truncated = true
+				break
+			}
+			kind := classifyReference(line, name, loc[2], definition)
+			result.References = append(result.References, SymbolReference{
+				RelPath: relPath,
+				Line:    lineNum,
+				Column:  loc[2] + 1,
+				Text:    strings.TrimSpace(line),
+				Kind:    kind,
+			})
+			if kind == "call" || kind == "reference" {
+				callers[relPath] = true
+			}
+		}
+		if result.Truncated {
+			break
+		}
+	}
+	for relPath := range callers {
+		result.Callers = append(result.Callers, relPath)
+	}
+	sort.Strings(result.Callers)
+
+	runtime.LogInfof(a.ctx, "Found %d references to %s in %s (%d calling files)", len(result.References), symbol, rootDir, len(result.Callers))
+	return result, nil
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- Token Budget Generation ---
+//
+// With a generation token budget set, generation fits the selection to it instead of emitting
+// everything. The budget is planned before the walk from the token estimates of the selected
+// files (index counts when available, size / 4 before), minus an allowance for the tree and
+// the block headers:
+//   - priority files first (pinned, entrypoints, ecosystem files, recent changes; see
+//     priorityFiles), then the other files from the smallest up, so as many files as
+//     possible make it in
+//   - a file that does not fit while at least minBudgetTruncationTokens are left is cut at a
+//     line boundary to what is left, and its block gets a truncated="true" attribute
+//   - every other file that does not fit is dropped: the tree still lists it, and its block
+//     is replaced by a one-line placeholder
+//
+// What was cut and dropped is emitted as "tokenBudgetApplied"; PreviewTokenBudget shows the
+// same plan before generating.
+
+const minBudgetTruncationTokens = 256 // Files are only cut if at least this much of the budget is left
+
+// BudgetFile is a file cut or dropped to fit the token budget
+type BudgetFile struct {
+	RelPath string `json:"relPath"` // Path relative to the project root (forward slashes)
+	Tokens  int    `json:"tokens"`  // Estimated tokens of the whole file
+	Kept    int    `json:"kept"`    // Estimated tokens kept (0 if dropped)
+}
+
+// BudgetReport describes how a selection was fitted to a token budget
+type BudgetReport struct {
+	RootDir     string       `json:"rootDir"`     // Project root directory
+	TokenBudget int          `json:"tokenBudget"` // Budget the selection was fitted to
+	TotalTokens int          `json:"totalTokens"` // Estimated tokens of the selection without a budget
+	UsedTokens  int          `json:"usedTokens"`  // Estimated tokens of the fitted context
+	Included    int          `json:"included"`    // Files included in full
+	Truncated   []BudgetFile `json:"truncated"`   // Files cut to fit, by path
+	Dropped     []BudgetFile `json:"dropped"`     // Files left out, by path
+}
+
+// budgetPlan is the fitting of one generation to its token budget
+type budgetPlan struct {
+	maxBytes map[string]int  // Content bytes kept of cut files, by relative OS path
+	dropped  map[string]bool // Files left out, by relative OS path
+	report   BudgetReport
+}
+
+// drops reports whether a file is left out (false without a plan)
+func (p *budgetPlan) drops(relPath string) bool {
+	return p != nil && p.dropped[relPath]
+}
+
+// contentLimit returns the content bytes kept of a file (0 for the whole file)
+func (p *budgetPlan) contentLimit(relPath string) int {
+	if p == nil {
+		return 0
+	}
+	return p.maxBytes[relPath]
+}
+
+// planTokenBudget fits a selection to a token budget
+//
+// Parameters:
+//   - rootDir: Project root directory
+//   - excluded: The selection, expressed as exclusions (relative OS paths)
+//   - summaryOnly: Directories generated as a summary (their files take no budget)
+//   - changedOnly: Changed-files restriction (files it skips take no budget; nil for none)
+//   - budget: Maximum estimated tokens of the context
+//
+// Returns:
+//   - *budgetPlan: Files to cut and drop
+//   - error: Error if the project cannot be walked
+func (a *App) planTokenBudget(rootDir string, excluded, summaryOnly map[string]bool, changedOnly *changedOnlyRun, budget int) (*budgetPlan, error) {
+	entries, files, err := a.walkSelectionEntries(rootDir)
+	if err != nil {
+		return nil, err
+	}
+	plan := &budgetPlan{
+		maxBytes: make(map[string]int),
+		dropped:  make(map[string]bool),
+		report:   BudgetReport{RootDir: rootDir, TokenBudget: budget, Truncated: []BudgetFile{}, Dropped: []BudgetFile{}},
+	}
+
+	// Selected text files with their estimates; the tree and headers are paid for up front
+	type budgetEntry struct {
+		relPath string
+		tokens  int
+	}
+	var selected []budgetEntry
+	tokensOf := make(map[string]int)
+	overhead := 0
+	for _, entry := range entries {
+		osRelPath := filepath.FromSlash(entry.relPath)
+		if excludedBySelection(excluded, osRelPath) || excludedBySelection(summaryOnly, filepath.Dir(osRelPath)) {
+			continue
+		}
+		overhead += (len(path.Base(entry.relPath)) + 4*strings.Count(entry.relPath, "/") + 5) / 4
+		if entry.isDir || changedOnly.skips(osRelPath) {
+			continue
+		}
+		overhead += (len(entry.relPath) + 40) / 4 // Block tags or placeholder
+		tokens := int(entry.size / 4)
+		if indexed, ok := a.lookupIndexedFile(entry.absPath); ok {
+			if indexed.isBinary {
+				continue
+			}
+			tokens = indexed.tokens
+		} else if isBinary, err := a.isBinaryFileCached(entry.absPath); err != nil || isBinary {
+			continue
+		}
+		if a.settings.MaxFileBytes > 0 {
+			tokens = min(tokens, a.settings.MaxFileBytes/4) // Capped files never take more
+		}
+		selected = append(selected, budgetEntry{entry.relPath, tokens})
+		tokensOf[entry.relPath] = tokens
+		plan.report.TotalTokens += tokens
+	}
+	plan.report.TotalTokens += overhead
+
+	// Priority files first, then the others from the smallest up
+	var ordered []budgetEntry
+	placed := make(map[string]bool)
+	for _, f := range a.priorityFiles(rootDir, entries, files) {
+		if tokens, ok := tokensOf[f.relPath]; ok && !placed[f.relPath] {
+			placed[f.relPath] = true
+			ordered = append(ordered, budgetEntry{f.relPath, tokens})
+		}
+	}
+	var others []budgetEntry
+	for _, e := range selected {
+		if !placed[e.relPath] {
+			others = append(others, e)
+		}
+	}
+	sort.SliceStable(others, func(i, j int) bool { return others[i].tokens < others[j].tokens })
+	ordered = append(ordered, others...)
+
+	remaining := budget - overhead
+	plan.report.UsedTokens = overhead
+	for _, e := range ordered {
+		osRelPath := filepath.FromSlash(e.relPath)
+		switch {
+		case e.tokens <= remaining:
+			remaining -= e.tokens
+			plan.report.UsedTokens += e.tokens
+			plan.report.Included++
+		case remaining >= minBudgetTruncationTokens:
+			plan.maxBytes[osRelPath] = remaining * 4
+			plan.report.Truncated = append(plan.report.Truncated, BudgetFile{RelPath: e.relPath, Tokens: e.tokens, Kept: remaining})
+			plan.report.UsedTokens += remaining
+			remaining = 0
+		default:
+			plan.dropped[osRelPath] = true
+			plan.report.Dropped = append(plan.report.Dropped, BudgetFile{RelPath: e.relPath, Tokens: e.tokens})
+		}
+	}
+	sort.Slice(plan.report.Dropped, func(i, j int) bool { return plan.report.Dropped[i].RelPath < plan.report.Dropped[j].RelPath })
+	return plan, nil
+}
+
+// reportIfApplied returns the report of a plan that cut or dropped files (nil otherwise)
+func (p *budgetPlan) reportIfApplied() *BudgetReport {
+	if p == nil || len(p.report.Truncated) == 0 && len(p.report.Dropped) == 0 {
+		return nil
+	}
+	return &p.report
+}
+
+// truncateFileContent cuts file content to at most maxBytes, at a line boundary when possible
+func truncateFileContent(content string, maxBytes int) string {
+	if maxBytes <= 0 || len(content) <= maxBytes {
+		return content
+	}
+	return content[:previewCut(content, maxBytes)]
+}
+
+// ============================================================================
+// Token Budget Methods (Wails-bound)
+// ============================================================================
+
+// GetGenerationTokenBudget returns the token budget of generated context (0 = unlimited)
+func (a *App) GetGenerationTokenBudget() int {
+	return a.settings.GenerationTokenBudget
+}
+
+// SetGenerationTokenBudget sets the token budget of generated context and saves it
+//
+// Parameters:
+//   - budget: Maximum estimated tokens of generated context (0 = unlimited)
+//
+// Returns:
+//   - error: Error if the budget is negative or the setting cannot be saved
+func (a *App) SetGenerationTokenBudget(budget int) error {
+	if budget < 0 {
+		return fmt.Errorf("token budget must not be negative, got %d", budget)
+	}
+	a.settings.GenerationTokenBudget = budget
+	if err := a.saveSettings(); err != nil {
+		return fmt.Errorf("failed to save generation token budget setting: %w", err)
+	}
+	runtime.LogInfof(a.ctx, "Generation token budget: %d", budget)
+	return nil
+}
+
+// PreviewTokenBudget shows how a selection would be fitted to a token budget
+//
+// Parameters:
+//   - rootDir: Project root directory
+//   - excludedPaths: The selection, expressed as exclusions
+//   - budget: Maximum estimated tokens (0 for the saved budget)
+//
+// Returns:
+//   - BudgetReport: Files that would be cut and dropped
+//   - error: Error if no budget is set or the project cannot be walked
+func (a *App) PreviewTokenBudget(rootDir string, excludedPaths []string, budget int) (BudgetReport, error) {
+	if budget <= 0 {
+		budget = a.settings.GenerationTokenBudget
+	}
+	if budget <= 0 {
+		return BudgetReport{}, fmt.Errorf("no token budget set")
+	}
+	if err := a.validateContentRoot(rootDir); err != nil {
+		return BudgetReport{}, err
+	}
+	excluded := make(map[string]bool, len(excludedPaths))
+	for _, p := range excludedPaths {
+		excluded[p] = true
+	}
+	opts := a.newTreeBuildOptions(rootDir, a.compileProjectGitignore(rootDir))
+	plan, err := a.planTokenBudget(rootDir, excluded, opts.summaryOnly, nil, budget)
+	if err != nil {
+		return BudgetReport{}, err
+	}
+	return plan.report, nil
+}
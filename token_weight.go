@@ -0,0 +1,50 @@
+package main
+
+// --- Token Weight of Tree Nodes ---
+//
+// Once the project index job has counted the tokens of every included file, tree nodes carry
+// their token count (directories: the sum of their files) and their token weight, the share of
+// the tree's total tokens between 0 and 1. The frontend uses the weight to color the tree as a
+// heatmap of what takes up the context budget. Nodes the index has not counted (ignored,
+// binary, or not indexed yet) weigh 0.
+
+// annotateTokens sets the token counts of nodes from the indexed files and returns their sum
+func annotateTokens(nodes []*FileNode, files map[string]indexedFile) int {
+	total := 0
+	for _, node := range nodes {
+		if node.IsDir {
+			node.Tokens = annotateTokens(node.Children, files)
+		} else if entry, ok := files[node.Path]; ok && !entry.isBinary {
+			node.Tokens = entry.tokens
+		} else {
+			node.Tokens = 0
+		}
+		total += node.Tokens
+	}
+	return total
+}
+
+// weighTokens sets the token weights of nodes relative to total
+func weighTokens(nodes []*FileNode, total int) {
+	for _, node := range nodes {
+		node.TokenWeight = 0
+		if total > 0 {
+			node.TokenWeight = float64(node.Tokens) / float64(total)
+		}
+		weighTokens(node.Children, total)
+	}
+}
+
+// annotateTokenWeights sets the token counts and weights of a tree from the indexed files
+func annotateTokenWeights(nodes []*FileNode, files map[string]indexedFile) {
+	weighTokens(nodes, annotateTokens(nodes, files))
+}
+
+// annotateTreeTokens annotates a tree of rootDir with the token counts of the index, if any
+func (a *App) annotateTreeTokens(rootDir string, nodes []*FileNode) {
+	if idx := a.currentIndex(rootDir); idx != nil {
+		idx.mu.RLock()
+		defer idx.mu.RUnlock()
+		annotateTokenWeights(nodes, idx.files)
+	}
+}
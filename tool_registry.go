@@ -0,0 +1,887 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+/**
+ * Tool Registry for Shotgun Code
+ *
+ * This module implements the backend tools that LLM providers can invoke through
+ * function calling. Every tool operates inside the selected project root and is
+ * gated by a per-tool permission that the user controls from the settings.
+ *
+ * Key Features:
+ * - Built-in tools: read_file, search_code, list_dir, run_tests, apply_patch
+ * - JSON-schema tool definitions shared by all providers
+ * - Per-tool permissions persisted in settings (write/exec tools are off by default)
+ * - Path sandboxing (tools can never escape the project root)
+ * - Audit log of every tool invocation (kept in memory and appended to disk)
+ *
+ * Events Emitted:
+ * - "toolAuditUpdated": When a new audit entry is recorded
+ */
+
+// Tool output limits keep a single tool result from flooding the model context
+const (
+	maxToolOutputBytes    = 64 * 1024        // Maximum bytes returned by a single tool call
+	maxToolReadFileBytes  = 256 * 1024       // Maximum file size read_file will load
+	maxToolSearchMatches  = 200              // Maximum matches returned by search_code
+	maxToolAuditEntries   = 500              // Audit entries kept in memory
+	defaultToolRunTimeout = 10 * time.Minute // Timeout for run_tests commands
+)
+
+// ToolDefinition describes a tool in the provider-neutral format sent to LLMs
+type ToolDefinition struct {
+	Name        string                 `json:"name"`        // Unique tool name (e.g., read_file)
+	Description string                 `json:"description"` // Human and model readable description
+	Parameters  map[string]interface{} `json:"parameters"`  // JSON schema of the tool arguments
+	Mutating    bool                   `json:"mutating"`    // True if the tool can modify the project or run commands
+}
+
+// ToolAuditEntry records a single tool invocation for later review
+type ToolAuditEntry struct {
+	Timestamp   time.Time `json:"timestamp"`   // When the tool was invoked
+	Tool        string    `json:"tool"`        // Tool name
+	Arguments   string    `json:"arguments"`   // JSON-encoded arguments supplied by the model
+	ProjectRoot string    `json:"projectRoot"` // Project root the tool operated in
+	Allowed     bool      `json:"allowed"`     // False if the call was blocked by permissions
+	Error       string    `json:"error"`       // Error message if the call failed
+	OutputBytes int       `json:"outputBytes"` // Size of the result returned to the model
+	DurationMs  int64     `json:"durationMs"`  // Execution time in milliseconds
+}
+
+// toolHandler executes a tool inside rootDir with the model-supplied arguments
+type toolHandler func(ctx context.Context, rootDir string, args map[string]interface{}) (string, error)
+
+// registeredTool pairs a definition with its implementation
+type registeredTool struct {
+	definition ToolDefinition
+	handler    toolHandler
+}
+
+// ToolRegistry holds the available tools, their permissions, and the audit log
+type ToolRegistry struct {
+	app      *App                       // Reference to main app for settings and logging
+	mu       sync.Mutex                 // Protects tools and audit log
+	tools    map[string]*registeredTool // Registered tools keyed by name
+	auditLog []ToolAuditEntry           // Recent audit entries (bounded)
+}
+
+// NewToolRegistry creates a registry with all built-in tools registered
+//
+// Parameters:
+//   - app: Reference to the main App for settings and logging
+//
+// Returns:
+//   - *ToolRegistry: Registry containing the built-in tools
+func NewToolRegistry(app *App) *ToolRegistry {
+	tr := &ToolRegistry{
+		app:      app,
+		tools:    make(map[string]*registeredTool),
+		auditLog: make([]ToolAuditEntry, 0),
+	}
+	tr.registerBuiltinTools()
+	return tr
+}
+
+// Register adds a tool to the registry, replacing any tool with the same name
+func (tr *ToolRegistry) Register(def ToolDefinition, handler toolHandler) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	tr.tools[def.Name] = &registeredTool{definition: def, handler: handler}
+}
+
+// Definitions returns all tool definitions sorted by name
+func (tr *ToolRegistry) Definitions() []ToolDefinition {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	defs := make([]ToolDefinition, 0, len(tr.tools))
+	for _, t := range tr.tools {
+		defs = append(defs, t.definition)
+	}
+	sort.Slice(defs, func(i, j int) bool { return defs[i].Name < defs[j].Name })
+	return defs
+}
+
+// EnabledDefinitions returns only the tools the user has allowed
+// These are the definitions actually advertised to the model
+func (tr *ToolRegistry) EnabledDefinitions() []ToolDefinition {
+	var enabled []ToolDefinition
+	for _, def := range tr.Definitions() {
+		if tr.IsAllowed(def.Name) {
+			enabled = append(enabled, def)
+		}
+	}
+	return enabled
+}
+
+// IsAllowed reports whether the user permits the given tool
+// Read-only tools are allowed by default, mutating tools must be enabled explicitly
+// (and are never allowed in read-only mode)
+func (tr *ToolRegistry) IsAllowed(name string) bool {
+	tr.mu.Lock()
+	t, exists := tr.tools[name]
+	tr.mu.Unlock()
+	if exists && t.definition.Mutating && tr.app.settings.ReadOnly {
+		return false
+	}
+
+	tr.app.settingsMu.RLock()
+	allowed, ok := tr.app.settings.ToolPermissions[name]
+	tr.app.settingsMu.RUnlock()
+	if ok {
+		return allowed
+	}
+	return exists && !t.definition.Mutating
+}
+
+// Execute runs a tool by name after checking permissions, recording an audit entry
+//
+// Parameters:
+//   - ctx: Context for cancellation
+//   - rootDir: Project root the tool is sandboxed to
+//   - name: Tool name
+//   - args: Arguments decoded from the model's tool call
+//
+// Returns:
+//   - string: Tool output (truncated to maxToolOutputBytes)
+//   - error: Error if the tool is unknown, not permitted, or fails
+func (tr *ToolRegistry) Execute(ctx context.Context, rootDir, name string, args map[string]interface{}) (string, error) {
+	argsJSON, _ := json.Marshal(args)
+	entry := ToolAuditEntry{
+		Timestamp:   time.Now(),
+		Tool:        name,
+		Arguments:   string(argsJSON),
+		ProjectRoot: rootDir,
+	}
+
+	tr.mu.Lock()
+	t, exists := tr.tools[name]
+	tr.mu.Unlock()
+
+	if !exists {
+		entry.Error = "unknown tool"
+		tr.recordAudit(entry)
+		return "", fmt.Errorf("unknown tool: %s", name)
+	}
+
+	if !tr.IsAllowed(name) {
+		if t.definition.Mutating && tr.app.settings.ReadOnly {
+			entry.Error = "tool is disabled in read-only mode"
+			tr.recordAudit(entry)
+			return "", fmt.Errorf("tool %s is disabled in read-only mode", name)
+		}
+		entry.Error = "tool is disabled in settings"
+		tr.recordAudit(entry)
+		return "", fmt.Errorf("tool %s is not permitted (enable it in settings)", name)
+	}
+	entry.Allowed = true
+
+	start := time.Now()
+	output, err := t.handler(ctx, rootDir, args)
+	entry.DurationMs = time.Since(start).Milliseconds()
+
+	if len(output) > maxToolOutputBytes {
+		output = truncateUTF8(output, maxToolOutputBytes) + "\n... [output truncated]"
+	}
+	entry.OutputBytes = len(output)
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	tr.recordAudit(entry)
+
+	return output, err
+}
+
+// AuditLog returns a copy of the in-memory audit log (oldest first)
+func (tr *ToolRegistry) AuditLog() []ToolAuditEntry {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	entries := make([]ToolAuditEntry, len(tr.auditLog))
+	copy(entries, tr.auditLog)
+	return entries
+}
+
+// recordAudit appends an entry to the in-memory log and to the on-disk audit file
+func (tr *ToolRegistry) recordAudit(entry ToolAuditEntry) {
+	tr.mu.Lock()
+	tr.auditLog = append(tr.auditLog, entry)
+	if len(tr.auditLog) > maxToolAuditEntries {
+		tr.auditLog = tr.auditLog[len(tr.auditLog)-maxToolAuditEntries:]
+	}
+	tr.mu.Unlock()
+
+	runtime.LogInfof(tr.app.ctx, "Tool call: %s (allowed: %v, %d ms, error: %q)", entry.Tool, entry.Allowed, entry.DurationMs, entry.Error)
+	runtime.EventsEmit(tr.app.ctx, "toolAuditUpdated", entry)
+
+	// Append to the persistent audit file next to settings.json
+	if tr.app.configPath == "" {
+		return
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	auditPath := filepath.Join(filepath.Dir(tr.app.configPath), "tool_audit.jsonl")
+	f, err := os.OpenFile(auditPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		runtime.LogWarningf(tr.app.ctx, "Failed to open tool audit log %s: %v", auditPath, err)
+		return
+	}
+	defer f.Close()
+	f.Write(append(line, '\n'))
+}
+
+// ============================================================================
+// Path Sandboxing Helpers
+// ============================================================================
+
+// resolveProjectPath joins relPath onto rootDir and verifies the result stays inside rootDir
+//
+// Parameters:
+//   - rootDir: Project root directory
+//   - relPath: Path relative to the root (absolute paths are rejected)
+//
+// Returns:
+//   - string: Cleaned absolute path inside rootDir
+//   - error: Error if the path escapes the project root
+func resolveProjectPath(rootDir, relPath string) (string, error) {
+	if rootDir == "" {
+		return "", fmt.Errorf("project root is empty")
+	}
+	if filepath.IsAbs(relPath) {
+		return "", fmt.Errorf("absolute paths are not allowed: %s", relPath)
+	}
+
+	cleanRoot := filepath.Clean(rootDir)
+	absPath := filepath.Clean(filepath.Join(cleanRoot, filepath.FromSlash(relPath)))
+
+	rel, err := filepath.Rel(cleanRoot, absPath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(os.PathSeparator)) {
+		return "", fmt.Errorf("path is outside the project root: %s", relPath)
+	}
+	// A symlink inside the project must not lead out of it either
+	if !pathWithin(canonicalPath(absPath), canonicalPath(cleanRoot)) {
+		return "", fmt.Errorf("path leads outside the project root through a symlink: %s", relPath)
+	}
+	return absPath, nil
+}
+
+// truncateUTF8 cuts s to at most maxBytes without splitting a multi-byte rune
+func truncateUTF8(s string, maxBytes int) string {
+	if len(s) <= maxBytes {
+		return s
+	}
+	cut := maxBytes
+	for cut > 0 && !isRuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut]
+}
+
+// isRuneStart reports whether b can begin a UTF-8 encoded rune
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
+
+// toolStringArg extracts a string argument, returning def if missing
+func toolStringArg(args map[string]interface{}, key, def string) string {
+	if v, ok := args[key].(string); ok {
+		return v
+	}
+	return def
+}
+
+// toolIntArg extracts a numeric argument (JSON numbers decode as float64)
+func toolIntArg(args map[string]interface{}, key string, def int) int {
+	switch v := args[key].(type) {
+	case float64:
+		return int(v)
+	case int:
+		return v
+	}
+	return def
+}
+
+// toolBoolArg extracts a boolean argument, returning def if missing
+func toolBoolArg(args map[string]interface{}, key string, def bool) bool {
+	if v, ok := args[key].(bool); ok {
+		return v
+	}
+	return def
+}
+
+// ============================================================================
+// Built-in Tools
+// ============================================================================
+
+// registerBuiltinTools registers read_file, search_code, list_dir, run_tests, and apply_patch
+func (tr *ToolRegistry) registerBuiltinTools() {
+	tr.Register(ToolDefinition{
+		Name:        "read_file",
+		Description: "Read a text file from the project. Optionally restrict to a 1-based inclusive line range.",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"path":      map[string]interface{}{"type": "string", "description": "File path relative to the project root"},
+				"startLine": map[string]interface{}{"type": "integer", "description": "First line to return (1-based, optional)"},
+				"endLine":   map[string]interface{}{"type": "integer", "description": "Last line to return (inclusive, optional)"},
+			},
+			"required": []string{"path"},
+		},
+	}, tr.toolReadFile)
+
+	tr.Register(ToolDefinition{
+		Name:        "search_code",
+		Description: "Search text files in the project for a string or regular expression. Returns path:line: text matches.",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"query": map[string]interface{}{"type": "string", "description": "Text or regular expression to search for"},
+				"regex": map[string]interface{}{"type": "boolean", "description": "Treat query as a regular expression"},
+				"path":  map[string]interface{}{"type": "string", "description": "Subdirectory to limit the search to (optional)"},
+			},
+			"required": []string{"query"},
+		},
+	}, tr.toolSearchCode)
+
+	tr.Register(ToolDefinition{
+		Name:        "list_dir",
+		Description: "List the entries of a directory in the project. Directories are suffixed with '/'.",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"path": map[string]interface{}{"type": "string", "description": "Directory path relative to the project root (default '.')"},
+			},
+		},
+	}, tr.toolListDir)
+
+	tr.Register(ToolDefinition{
+		Name:        "run_tests",
+		Description: "Run the project's test command (set by the user or detected from its manifests) and return its combined output.",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"target": map[string]interface{}{"type": "string", "description": "Optional package, file or test name appended to the test command as one argument (e.g. './pkg/parser'); no shell syntax"},
+			},
+		},
+		Mutating: true,
+	}, tr.toolRunTests)
+
+	tr.Register(ToolDefinition{
+		Name:        "apply_patch",
+		Description: "Apply a unified diff (git diff format) to the project files.",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"patch": map[string]interface{}{"type": "string", "description": "Unified diff to apply, paths relative to the project root"},
+			},
+			"required": []string{"patch"},
+		},
+		Mutating: true,
+	}, tr.toolApplyPatch)
+}
+
+// toolReadFile implements the read_file tool
+func (tr *ToolRegistry) toolReadFile(ctx context.Context, rootDir string, args map[string]interface{}) (string, error) {
+	relPath := toolStringArg(args, "path", "")
+	if relPath == "" {
+		return "", fmt.Errorf("path is required")
+	}
+	absPath, err := resolveProjectPath(rootDir, relPath)
+	if err != nil {
+		return "", err
+	}
+
+	// Ignored files (.env, build secrets) stay out of reach, as in search_code and list_dir
+	cleanRel, _ := filepath.Rel(filepath.Clean(rootDir), absPath)
+	for p, isDir := cleanRel, false; p != "." && p != string(filepath.Separator); p, isDir = filepath.Dir(p), true {
+		if tr.app.isIgnoredRelPath(p, isDir) {
+			return "", fmt.Errorf("%s is excluded by the project's ignore rules", relPath)
+		}
+	}
+
+	info, err := tr.app.projectFS.Stat(absPath)
+	if err != nil {
+		return "", fmt.Errorf("cannot stat %s: %w", relPath, err)
+	}
+	if info.IsDir() {
+		return "", fmt.Errorf("%s is a directory", relPath)
+	}
+	if info.Size() > maxToolReadFileBytes {
+		return "", fmt.Errorf("%s is too large (%d bytes); use a line range or search_code", relPath, info.Size())
+	}
+
+	isBinary, err := tr.app.isBinaryFile(absPath)
+	if err != nil {
+		return "", err
+	}
+	if isBinary {
+		return "", fmt.Errorf("%s is a binary file", relPath)
+	}
+
+	content, err := tr.app.projectFS.ReadFile(absPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", relPath, err)
+	}
+
+	startLine := toolIntArg(args, "startLine", 0)
+	endLine := toolIntArg(args, "endLine", 0)
+	if startLine <= 0 && endLine <= 0 {
+		return string(content), nil
+	}
+
+	lines := strings.Split(string(content), "\n")
+	if startLine <= 0 {
+		startLine = 1
+	}
+	if endLine <= 0 || endLine > len(lines) {
+		endLine = len(lines)
+	}
+	if startLine > endLine {
+		return "", fmt.Errorf("invalid line range %d-%d (file has %d lines)", startLine, endLine, len(lines))
+	}
+	return strings.Join(lines[startLine-1:endLine], "\n"), nil
+}
+
+// toolSearchCode implements the search_code tool
+func (tr *ToolRegistry) toolSearchCode(ctx context.Context, rootDir string, args map[string]interface{}) (string, error) {
+	query := toolStringArg(args, "query", "")
+	if query == "" {
+		return "", fmt.Errorf("query is required")
+	}
+
+	var re *regexp.Regexp
+	if toolBoolArg(args, "regex", false) {
+		var err error
+		re, err = regexp.Compile(query)
+		if err != nil {
+			return "", fmt.Errorf("invalid regular expression: %w", err)
+		}
+	}
+
+	searchRoot, err := resolveProjectPath(rootDir, toolStringArg(args, "path", "."))
+	if err != nil {
+		return "", err
+	}
+
+	var results strings.Builder
+	matches := 0
+	walkErr := tr.app.projectFS.WalkDir(searchRoot, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // Skip unreadable entries
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+
+		relPath, _ := filepath.Rel(rootDir, path)
+		if relPath != "." && tr.app.isIgnoredRelPath(relPath, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		if d.Type()&fs.ModeSymlink != 0 {
+			if _, err := resolveProjectPath(rootDir, relPath); err != nil {
+				return nil // Links out of the project are not followed
+			}
+		}
+
+		if isBinary, err := tr.app.isBinaryFile(path); err != nil || isBinary {
+			return nil
+		}
+
+		f, err := tr.app.projectFS.Open(path)
+		if err != nil {
+			return nil
+		}
+		defer f.Close()
+
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		lineNo := 0
+		for scanner.Scan() {
+			lineNo++
+			line := scanner.Text()
+			found := false
+			if re != nil {
+				found = re.MatchString(line)
+			} else {
+				found = strings.Contains(line, query)
+			}
+			if found {
+				fmt.Fprintf(&results, "%s:%d: %s\n", filepath.ToSlash(relPath), lineNo, strings.TrimSpace(line))
+				matches++
+				if matches >= maxToolSearchMatches {
+					return fs.SkipAll
+				}
+			}
+		}
+		return nil
+	})
+	if walkErr != nil {
+		return "", walkErr
+	}
+
+	if matches == 0 {
+		return "No matches found.", nil
+	}
+	if matches >= maxToolSearchMatches {
+		results.WriteString(fmt.Sprintf("... [stopped after %d matches]\n", maxToolSearchMatches))
+	}
+	return results.String(), nil
+}
+
+// toolListDir implements the list_dir tool
+func (tr *ToolRegistry) toolListDir(ctx context.Context, rootDir string, args map[string]interface{}) (string, error) {
+	relDir := toolStringArg(args, "path", ".")
+	absDir, err := resolveProjectPath(rootDir, relDir)
+	if err != nil {
+		return "", err
+	}
+
+	entries, err := tr.app.projectFS.ReadDir(absDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to list %s: %w", relDir, err)
+	}
+
+	var out strings.Builder
+	for _, entry := range entries {
+		relPath, _ := filepath.Rel(rootDir, filepath.Join(absDir, entry.Name()))
+		if tr.app.isIgnoredRelPath(relPath, entry.IsDir()) {
+			continue
+		}
+		if entry.IsDir() {
+			out.WriteString(entry.Name() + "/\n")
+		} else {
+			out.WriteString(entry.Name() + "\n")
+		}
+	}
+	if out.Len() == 0 {
+		return "(empty directory)", nil
+	}
+	return out.String(), nil
+}
+
+// testTargetRegex matches a run_tests target: a package, file or test name, never shell syntax
+var testTargetRegex = regexp.MustCompile(`^[A-Za-z0-9_./:@*+,=^$\[\]-]+$`)
+
+// checkTestTarget refuses a run_tests target that is not a plain package, file or test name
+func checkTestTarget(target string) error {
+	if strings.HasPrefix(target, "-") || !testTargetRegex.MatchString(target) {
+		return fmt.Errorf("invalid test target %q: only a package, file or test name is accepted", target)
+	}
+	for _, part := range strings.Split(target, "/") {
+		if part == ".." {
+			return fmt.Errorf("test target leads outside the project: %s", target)
+		}
+	}
+	if filepath.IsAbs(target) {
+		return fmt.Errorf("absolute test targets are not allowed: %s", target)
+	}
+	return nil
+}
+
+// testCommandArgs splits a test command into its working directory and arguments
+// Test commands run without a shell: a leading "cd <dir> &&" (as DetectProjectType writes
+// for nested manifests) sets the directory, and the rest is split on whitespace.
+//
+// Returns:
+//   - string: Directory to run the command in
+//   - []string: Program and arguments
+//   - error: Error if the command is empty, uses shell syntax or leaves the project
+func testCommandArgs(rootDir, command string) (string, []string, error) {
+	dir := rootDir
+	fields := strings.Fields(command)
+	if len(fields) >= 3 && fields[0] == "cd" && fields[2] == "&&" {
+		sub, err := resolveProjectPath(rootDir, fields[1])
+		if err != nil {
+			return "", nil, err
+		}
+		dir, fields = sub, fields[3:]
+	}
+	if len(fields) == 0 {
+		return "", nil, fmt.Errorf("test command is empty")
+	}
+	for _, field := range fields {
+		if strings.ContainsAny(field, "|&;<>`$()") {
+			return "", nil, fmt.Errorf("test commands run without a shell, %q is not supported", field)
+		}
+	}
+	return dir, fields, nil
+}
+
+// projectTestCommand returns the test command of a project: the one set with SetTestCommand,
+// or else the first one DetectProjectType finds
+func (a *App) projectTestCommand(rootDir string) (string, error) {
+	a.settingsMu.RLock()
+	command := a.settings.TestCommands[projectSettingsKey(rootDir)]
+	a.settingsMu.RUnlock()
+	if strings.TrimSpace(command) != "" {
+		return command, nil
+	}
+	info, err := a.DetectProjectType(rootDir)
+	if err != nil {
+		return "", err
+	}
+	if len(info.TestCommands) == 0 {
+		return "", fmt.Errorf("no test command set or detected for %s", rootDir)
+	}
+	return info.TestCommands[0], nil
+}
+
+// toolRunTests implements the run_tests tool
+// Only the project's test command runs; the model may add one target argument to it.
+func (tr *ToolRegistry) toolRunTests(ctx context.Context, rootDir string, args map[string]interface{}) (string, error) {
+	// Test commands can reach the network on their own, outside outboundTransport
+	if err := checkOnline("run_tests"); err != nil {
+		return "", err
+	}
+	command, err := tr.app.projectTestCommand(rootDir)
+	if err != nil {
+		return "", err
+	}
+	dir, argv, err := testCommandArgs(rootDir, command)
+	if err != nil {
+		return "", err
+	}
+	if target := strings.TrimSpace(toolStringArg(args, "target", "")); target != "" {
+		if err := checkTestTarget(target); err != nil {
+			return "", err
+		}
+		argv = append(argv, target)
+	}
+	command = strings.Join(argv, " ")
+
+	runCtx, cancel := context.WithTimeout(ctx, defaultToolRunTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(runCtx, argv[0], argv[1:]...)
+	cmd.Dir = dir
+
+	output, err := cmd.CombinedOutput()
+	result := string(output)
+	if err != nil {
+		// A failing test run is a valid result for the model, not a tool error
+		if _, ok := err.(*exec.ExitError); ok {
+			tr.app.notify(notifyTests, "Tests failed", fmt.Sprintf("%s: %s (%v)", projectLabel(rootDir), command, err))
+			return fmt.Sprintf("$ %s\n%s\n[exit status: %v]", command, result, err), nil
+		}
+		return result, fmt.Errorf("failed to run %s: %w", command, err)
+	}
+	tr.app.notify(notifyTests, "Tests passed", fmt.Sprintf("%s: %s", projectLabel(rootDir), command))
+	return fmt.Sprintf("$ %s\n%s\n[exit status: 0]", command, result), nil
+}
+
+// toolApplyPatch implements the apply_patch tool
+func (tr *ToolRegistry) toolApplyPatch(ctx context.Context, rootDir string, args map[string]interface{}) (string, error) {
+	patch := tr.app.postProcess(toolStringArg(args, "patch", ""))
+	if strings.TrimSpace(patch) == "" {
+		return "", fmt.Errorf("patch is required")
+	}
+	if _, err := tr.app.applyPatchOperation(ctx, rootDir, patch, "Apply patch (tool call)"); err != nil {
+		return "", err
+	}
+	tr.app.notify(notifyPatchApplied, "Patch applied", projectLabel(rootDir))
+	return "Patch applied successfully.", nil
+}
+
+// applyUnifiedDiff applies a unified diff to rootDir using git apply
+// git apply refuses paths that escape the working directory, which keeps patches sandboxed
+func applyUnifiedDiff(ctx context.Context, rootDir, patch string) error {
+	return runGitApply(ctx, rootDir, patch)
+}
+
+// checkUnifiedDiff reports whether a unified diff would apply to rootDir, without changing it
+func checkUnifiedDiff(ctx context.Context, rootDir, patch string) error {
+	return runGitApply(ctx, rootDir, patch, "--check")
+}
+
+// runGitApply runs git apply on a patch in rootDir with extra flags
+func runGitApply(ctx context.Context, rootDir, patch string, flags ...string) error {
+	if !strings.HasSuffix(patch, "\n") {
+		patch += "\n"
+	}
+
+	args := append([]string{"apply", "--recount", "--whitespace=nowarn"}, flags...)
+	cmd := exec.CommandContext(ctx, "git", append(args, "-")...)
+	cmd.Dir = rootDir
+	cmd.Stdin = strings.NewReader(patch)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("git apply failed: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// ============================================================================
+// Tool Registry Methods (Wails-bound)
+// ============================================================================
+
+// ListTools returns all registered tools with their definitions
+func (a *App) ListTools() []ToolDefinition {
+	if a.toolRegistry == nil {
+		return []ToolDefinition{}
+	}
+	return a.toolRegistry.Definitions()
+}
+
+// GetToolPermissions returns the effective permission for every registered tool
+func (a *App) GetToolPermissions() map[string]bool {
+	permissions := make(map[string]bool)
+	if a.toolRegistry == nil {
+		return permissions
+	}
+	for _, def := range a.toolRegistry.Definitions() {
+		permissions[def.Name] = a.toolRegistry.IsAllowed(def.Name)
+	}
+	return permissions
+}
+
+// SetToolPermission enables or disables a tool and saves the setting
+//
+// Parameters:
+//   - name: Tool name
+//   - allowed: Whether models may invoke the tool
+//
+// Returns:
+//   - error: Error if the tool is unknown or settings cannot be saved
+func (a *App) SetToolPermission(name string, allowed bool) error {
+	if a.toolRegistry == nil {
+		return fmt.Errorf("tool registry not initialized")
+	}
+
+	known := false
+	for _, def := range a.toolRegistry.Definitions() {
+		if def.Name == name {
+			known = true
+			break
+		}
+	}
+	if !known {
+		return fmt.Errorf("unknown tool: %s", name)
+	}
+
+	a.settingsMu.Lock()
+	if a.settings.ToolPermissions == nil {
+		a.settings.ToolPermissions = make(map[string]bool)
+	}
+	a.settings.ToolPermissions[name] = allowed
+	a.settingsMu.Unlock()
+	runtime.LogInfof(a.ctx, "Tool permission for %s set to: %v", name, allowed)
+
+	if err := a.saveSettings(); err != nil {
+		return fmt.Errorf("failed to save tool permissions: %w", err)
+	}
+	return nil
+}
+
+// GetTestCommand returns the test command set for a project (empty if the detected one is used)
+func (a *App) GetTestCommand(rootDir string) string {
+	a.settingsMu.RLock()
+	defer a.settingsMu.RUnlock()
+	return a.settings.TestCommands[projectSettingsKey(rootDir)]
+}
+
+// SetTestCommand sets the command the run_tests tool runs for a project and saves it
+// The command runs without a shell; a leading "cd <dir> &&" sets its directory.
+//
+// Parameters:
+//   - rootDir: Project root directory
+//   - command: Test command (e.g. "go test ./..."; empty to use the detected one)
+//
+// Returns:
+//   - error: Error if the command uses shell syntax or the settings cannot be saved
+func (a *App) SetTestCommand(rootDir, command string) error {
+	command = strings.TrimSpace(command)
+	if command != "" {
+		if _, _, err := testCommandArgs(rootDir, command); err != nil {
+			return err
+		}
+	}
+	key := projectSettingsKey(rootDir)
+	a.settingsMu.Lock()
+	if command == "" {
+		delete(a.settings.TestCommands, key)
+	} else {
+		if a.settings.TestCommands == nil {
+			a.settings.TestCommands = make(map[string]string)
+		}
+		a.settings.TestCommands[key] = command
+	}
+	a.settingsMu.Unlock()
+	if err := a.saveSettings(); err != nil {
+		return fmt.Errorf("failed to save test command: %w", err)
+	}
+	runtime.LogInfof(a.ctx, "Test command for %s set to %q", key, command)
+	return nil
+}
+
+// GetToolAuditLog returns the recent tool invocations (oldest first)
+func (a *App) GetToolAuditLog() []ToolAuditEntry {
+	if a.toolRegistry == nil {
+		return []ToolAuditEntry{}
+	}
+	return a.toolRegistry.AuditLog()
+}
+
+// RunToolAgent runs a multi-step tool-calling conversation as a background job
+//
+// The model can call the enabled tools to inspect and modify the project until it
+// produces a final answer or maxSteps is reached. The final answer is emitted as
+// "llmResponseReceived", and each tool call as "toolAgentStep".
+//
+// Parameters:
+//   - provider: LLM provider (google, openai, anthropic, custom)
+//   - apiKey: API key for the provider
+//   - model: Model name (empty for provider default)
+//   - baseURL: Base URL for the custom provider (ignored otherwise)
+//   - prompt: The task for the agent
+//   - rootDir: Project root the tools are sandboxed to
+//   - maxSteps: Maximum number of model turns (defaults to 10)
+//
+// Returns:
+//   - string: Job ID for tracking the agent run
+//   - error: Error if validation fails or the job cannot be created
+func (a *App) RunToolAgent(provider, apiKey, model, baseURL, prompt, rootDir string, maxSteps int) (string, error) {
+	if a.jobQueue == nil || a.toolRegistry == nil {
+		return "", fmt.Errorf("job queue or tool registry not initialized")
+	}
+	if err := a.validateContentRoot(rootDir); err != nil {
+		return "", err
+	}
+
+	return a.jobQueue.Enqueue("tool_agent", toolAgentParams{
+		Provider: provider,
+		APIKey:   apiKey,
+		Model:    model,
+		BaseURL:  baseURL,
+		Prompt:   prompt,
+		RootDir:  rootDir,
+		MaxSteps: maxSteps,
+	})
+}
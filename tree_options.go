@@ -0,0 +1,386 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	gitignore "github.com/sabhiram/go-gitignore"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- File Tree Options ---
+
+// treeBuildOptions carries the rules applied while building the file tree
+type treeBuildOptions struct {
+	gitIgn        *gitignore.GitIgnore // Compiled project .gitignore (nil if none)
+	customIgn     *gitignore.GitIgnore // Compiled custom ignore patterns (nil if none)
+	forceIncludes map[string]bool      // Relative paths included even though they match an ignore rule
+	summaryOnly   map[string]bool      // Directories generated as a summary instead of their files
+
+	showDotfiles    bool     // Show and include all dotfiles and dot-directories
+	visibleDotfiles []string // Dotfile name patterns shown even when showDotfiles is off
+
+	maxDepth         int // Maximum directory depth listed (0 = unlimited)
+	maxEntriesPerDir int // Maximum entries listed per directory (0 = unlimited)
+}
+
+// ListFilesOptions are per-call overrides for ListFilesWithOptions
+// Nil or zero fields fall back to the saved settings
+type ListFilesOptions struct {
+	ShowDotfiles     *bool `json:"showDotfiles,omitempty"`     // Show all dotfiles and dot-directories
+	MaxDepth         int   `json:"maxDepth,omitempty"`         // Maximum directory depth listed (negative = unlimited)
+	MaxEntriesPerDir int   `json:"maxEntriesPerDir,omitempty"` // Maximum entries listed per directory (negative = unlimited)
+}
+
+// defaultVisibleDotfiles are the dotfiles that are useful context and stay visible by default
+// Everything else starting with a dot (.cache, .idea, .venv, ...) is hidden unless ShowDotfiles is on
+var defaultVisibleDotfiles = []string{
+	".github",
+	".gitlab-ci.yml",
+	".circleci",
+	".gitignore",
+	".gitattributes",
+	".editorconfig",
+	".dockerignore",
+	".env.example",
+	".eslintrc*",
+	".prettierrc*",
+	".babelrc*",
+	".golangci.y*ml",
+	".nvmrc",
+	".tool-versions",
+}
+
+// newTreeBuildOptions returns the tree rules for a project, honoring the useGitignore and
+// useCustomIgnore toggles (a disabled rule set is not applied at all)
+//
+// Parameters:
+//   - rootDir: Project root directory
+//   - gitIgn: Compiled .gitignore of the project (nil if none)
+func (a *App) newTreeBuildOptions(rootDir string, gitIgn *gitignore.GitIgnore) *treeBuildOptions {
+	opts := &treeBuildOptions{forceIncludes: a.forceIncludeSet(rootDir), summaryOnly: a.summaryOnlySet(rootDir)}
+	if a.useGitignore {
+		opts.gitIgn = gitIgn
+	}
+	if a.useCustomIgnore {
+		opts.customIgn = a.currentCustomIgnorePatterns
+	}
+	opts.showDotfiles = a.settings.ShowDotfiles
+	opts.visibleDotfiles = a.settings.VisibleDotfiles
+	opts.maxDepth = a.settings.MaxTreeDepth
+	opts.maxEntriesPerDir = a.settings.MaxEntriesPerDir
+	return opts
+}
+
+// ignoreFlags reports which rule sets match a project-relative path
+func (o *treeBuildOptions) ignoreFlags(relPath string, isDir bool) (isGitignored, isCustomIgnored bool) {
+	pathToMatch := relPath
+	if isDir && !strings.HasSuffix(pathToMatch, string(os.PathSeparator)) {
+		pathToMatch += string(os.PathSeparator)
+	}
+	if o.gitIgn != nil {
+		isGitignored = o.gitIgn.MatchesPath(pathToMatch)
+	}
+	if o.customIgn != nil {
+		isCustomIgnored = o.customIgn.MatchesPath(pathToMatch)
+	}
+	return isGitignored, isCustomIgnored
+}
+
+// excludes reports whether a path is dropped by the ignore or dotfile rules (force-included
+// paths and the directories leading to them never are)
+func (o *treeBuildOptions) excludes(relPath string, isDir bool) bool {
+	if o.overridesIgnore(relPath, isDir) {
+		return false
+	}
+	isGitignored, isCustomIgnored := o.ignoreFlags(relPath, isDir)
+	if isGitignored || isCustomIgnored || o.hidesDotfile(filepath.Base(relPath)) {
+		return true
+	}
+	parentGitignored, parentCustomIgnored, parentHidden := o.ancestorIgnoreFlags(filepath.Dir(relPath))
+	return parentGitignored || parentCustomIgnored || parentHidden
+}
+
+// ancestorIgnoreFlags reports which rules ignore or hide the directory relDir or one of its parents
+// Walks only enter such a directory to reach a force-included path inside it (see
+// overridesIgnore); everything else in it stays ignored.
+func (o *treeBuildOptions) ancestorIgnoreFlags(relDir string) (isGitignored, isCustomIgnored, hidden bool) {
+	if len(o.forceIncludes) == 0 {
+		return false, false, false
+	}
+	for dir := filepath.Clean(relDir); dir != "." && dir != string(filepath.Separator); dir = filepath.Dir(dir) {
+		dirGitignored, dirCustomIgnored := o.ignoreFlags(dir, true)
+		isGitignored = isGitignored || dirGitignored
+		isCustomIgnored = isCustomIgnored || dirCustomIgnored
+		hidden = hidden || o.hidesDotfile(filepath.Base(dir))
+		if filepath.Dir(dir) == dir {
+			break
+		}
+	}
+	return isGitignored, isCustomIgnored, hidden
+}
+
+// overridesIgnore reports whether a path is kept despite the ignore and dotfile rules: it is
+// force-included, or it is a directory with a force-included path inside (walked to reach it)
+func (o *treeBuildOptions) overridesIgnore(relPath string, isDir bool) bool {
+	return isForceIncluded(o.forceIncludes, relPath) || (isDir && leadsToForceIncluded(o.forceIncludes, relPath))
+}
+
+// hidesDotfile reports whether an entry name is a hidden dotfile under the current settings
+func (o *treeBuildOptions) hidesDotfile(name string) bool {
+	if o.showDotfiles || !strings.HasPrefix(name, ".") || name == "." || name == ".." {
+		return false
+	}
+	for _, pattern := range o.visibleDotfiles {
+		if matched, err := filepath.Match(pattern, name); err == nil && matched {
+			return false
+		}
+	}
+	return true
+}
+
+// capDirEntries sorts directory entries like the tree (directories first, then by name)
+// and keeps the first maxEntriesPerDir visible ones
+//
+// Returns:
+//   - []fs.DirEntry: Entries to list
+//   - int: Number of visible entries left out
+func (o *treeBuildOptions) capDirEntries(entries []fs.DirEntry, currentPath, rootPath string) ([]fs.DirEntry, int) {
+	relDir, _ := filepath.Rel(rootPath, currentPath)
+	_, _, parentHidden := o.ancestorIgnoreFlags(relDir)
+	visible := make([]fs.DirEntry, 0, len(entries))
+	for _, entry := range entries {
+		relPath, _ := filepath.Rel(rootPath, filepath.Join(currentPath, entry.Name()))
+		if (parentHidden || o.hidesDotfile(entry.Name())) && !o.overridesIgnore(relPath, entry.IsDir()) {
+			continue
+		}
+		visible = append(visible, entry)
+	}
+	if len(visible) <= o.maxEntriesPerDir {
+		return visible, 0
+	}
+
+	sort.SliceStable(visible, func(i, j int) bool {
+		if visible[i].IsDir() != visible[j].IsDir() {
+			return visible[i].IsDir()
+		}
+		return strings.ToLower(visible[i].Name()) < strings.ToLower(visible[j].Name())
+	})
+	return visible[:o.maxEntriesPerDir], len(visible) - o.maxEntriesPerDir
+}
+
+// newMoreEntriesNode returns the "N more entries" placeholder of a capped directory
+func newMoreEntriesNode(currentPath, rootPath string, count int) *FileNode {
+	relDir, _ := filepath.Rel(rootPath, currentPath)
+	return &FileNode{
+		Name:      fmt.Sprintf("%d more entries", count),
+		Path:      filepath.Join(currentPath, "..."),
+		RelPath:   filepath.Join(relDir, "..."),
+		IsSummary: true,
+		MoreCount: count,
+	}
+}
+
+// projectSettingsKey returns the key under which per-project settings are stored
+func projectSettingsKey(rootDir string) string {
+	if abs, err := filepath.Abs(rootDir); err == nil {
+		return filepath.Clean(abs)
+	}
+	return filepath.Clean(rootDir)
+}
+
+// normalizeRelPath cleans a project-relative path and converts it to OS separators
+func normalizeRelPath(relPath string) string {
+	return filepath.Clean(filepath.FromSlash(strings.TrimSpace(relPath)))
+}
+
+// forceIncludeSet returns the force-included relative paths of a project as a set
+func (a *App) forceIncludeSet(rootDir string) map[string]bool {
+	set := make(map[string]bool)
+	for _, relPath := range a.settings.ForceIncludePaths[projectSettingsKey(rootDir)] {
+		set[normalizeRelPath(relPath)] = true
+	}
+	return set
+}
+
+// isForceIncluded reports whether relPath or one of its parent directories is force-included
+func isForceIncluded(forceIncludes map[string]bool, relPath string) bool {
+	if len(forceIncludes) == 0 {
+		return false
+	}
+	for p := filepath.Clean(relPath); p != "." && p != string(filepath.Separator); p = filepath.Dir(p) {
+		if forceIncludes[p] {
+			return true
+		}
+		if filepath.Dir(p) == p {
+			break
+		}
+	}
+	return false
+}
+
+// leadsToForceIncluded reports whether a force-included path lies inside the directory relPath
+func leadsToForceIncluded(forceIncludes map[string]bool, relPath string) bool {
+	prefix := filepath.Clean(relPath) + string(filepath.Separator)
+	for p := range forceIncludes {
+		if strings.HasPrefix(p, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetForceIncludedPaths returns the paths of a project that are included despite ignore rules
+//
+// Parameters:
+//   - rootDir: Project root directory
+//
+// Returns:
+//   - []string: Force-included paths, relative to the root (forward slashes)
+func (a *App) GetForceIncludedPaths(rootDir string) []string {
+	paths := []string{}
+	for relPath := range a.forceIncludeSet(rootDir) {
+		paths = append(paths, filepath.ToSlash(relPath))
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// SetForceIncluded adds or removes a force-include override for an ignored path
+// A force-included path stays flagged as ignored in the tree but is selectable and
+// is never dropped from generated context because of ignore rules. Ignored directories
+// leading to it are walked to reach it; their other entries stay ignored.
+//
+// Parameters:
+//   - rootDir: Project root directory
+//   - relPath: File or directory path relative to the root
+//   - included: True to force-include, false to remove the override
+//
+// Returns:
+//   - error: Error if the path is invalid or settings cannot be saved
+func (a *App) SetForceIncluded(rootDir, relPath string, included bool) error {
+	if strings.TrimSpace(rootDir) == "" {
+		return fmt.Errorf("no project folder specified")
+	}
+	normalized := normalizeRelPath(relPath)
+	if normalized == "." || filepath.IsAbs(normalized) || strings.HasPrefix(normalized, "..") {
+		return fmt.Errorf("invalid project-relative path: %s", relPath)
+	}
+
+	set := a.forceIncludeSet(rootDir)
+	if included {
+		set[normalized] = true
+	} else {
+		delete(set, normalized)
+	}
+
+	paths := make([]string, 0, len(set))
+	for p := range set {
+		paths = append(paths, filepath.ToSlash(p))
+	}
+	sort.Strings(paths)
+
+	key := projectSettingsKey(rootDir)
+	if a.settings.ForceIncludePaths == nil {
+		a.settings.ForceIncludePaths = make(map[string][]string)
+	}
+	if len(paths) == 0 {
+		delete(a.settings.ForceIncludePaths, key)
+	} else {
+		a.settings.ForceIncludePaths[key] = paths
+	}
+
+	if err := a.saveSettings(); err != nil {
+		return fmt.Errorf("failed to save force-included paths: %w", err)
+	}
+	runtime.LogInfof(a.ctx, "Force-include for %s in %s set to %v", filepath.ToSlash(normalized), key, included)
+	a.notifyFileChange(rootDir)
+	return nil
+}
+
+// GetShowDotfiles returns whether all dotfiles and dot-directories are shown and included
+func (a *App) GetShowDotfiles() bool {
+	return a.settings.ShowDotfiles
+}
+
+// SetShowDotfiles toggles showing all dotfiles and dot-directories and saves the setting.
+// When off, only dotfiles matching the visible dotfile patterns (e.g. .github) are shown.
+func (a *App) SetShowDotfiles(enabled bool) error {
+	a.settings.ShowDotfiles = enabled
+	if err := a.saveSettings(); err != nil {
+		return fmt.Errorf("failed to save dotfile visibility: %w", err)
+	}
+	runtime.LogInfof(a.ctx, "Show dotfiles set to: %v", enabled)
+	if a.fileWatcher != nil && a.fileWatcher.rootDir != "" {
+		a.notifyFileChange(a.fileWatcher.rootDir)
+	}
+	return nil
+}
+
+// GetVisibleDotfiles returns the dotfile name patterns that are shown even when dotfiles are hidden
+func (a *App) GetVisibleDotfiles() []string {
+	return a.settings.VisibleDotfiles
+}
+
+// SetVisibleDotfiles updates the dotfile name patterns (glob syntax, e.g. .eslintrc*) that are
+// shown even when dotfiles are hidden, and saves them. An empty list hides all dotfiles.
+func (a *App) SetVisibleDotfiles(patterns []string) error {
+	cleaned := []string{}
+	for _, pattern := range patterns {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid dotfile pattern %q: %w", pattern, err)
+		}
+		cleaned = append(cleaned, pattern)
+	}
+
+	a.settings.VisibleDotfiles = cleaned
+	if err := a.saveSettings(); err != nil {
+		return fmt.Errorf("failed to save visible dotfiles: %w", err)
+	}
+	runtime.LogInfof(a.ctx, "Visible dotfiles set to: %v", cleaned)
+	if a.fileWatcher != nil && a.fileWatcher.rootDir != "" {
+		a.notifyFileChange(a.fileWatcher.rootDir)
+	}
+	return nil
+}
+
+// GetTreeLimits returns the maximum tree depth and entries per directory (0 = unlimited)
+func (a *App) GetTreeLimits() map[string]int {
+	return map[string]int{
+		"maxTreeDepth":     a.settings.MaxTreeDepth,
+		"maxEntriesPerDir": a.settings.MaxEntriesPerDir,
+	}
+}
+
+// SetTreeLimits updates the file tree limits and saves them.
+// The limits only affect the listed tree, not generated context.
+//
+// Parameters:
+//   - maxTreeDepth: Maximum directory depth listed (0 = unlimited)
+//   - maxEntriesPerDir: Maximum entries listed per directory (0 = unlimited)
+//
+// Returns:
+//   - error: Error if a limit is negative or settings cannot be saved
+func (a *App) SetTreeLimits(maxTreeDepth, maxEntriesPerDir int) error {
+	if maxTreeDepth < 0 || maxEntriesPerDir < 0 {
+		return fmt.Errorf("tree limits must not be negative (depth %d, entries %d)", maxTreeDepth, maxEntriesPerDir)
+	}
+	a.settings.MaxTreeDepth = maxTreeDepth
+	a.settings.MaxEntriesPerDir = maxEntriesPerDir
+	if err := a.saveSettings(); err != nil {
+		return fmt.Errorf("failed to save tree limits: %w", err)
+	}
+	runtime.LogInfof(a.ctx, "Tree limits set to: depth %d, entries per directory %d", maxTreeDepth, maxEntriesPerDir)
+	if a.fileWatcher != nil && a.fileWatcher.rootDir != "" {
+		a.notifyFileChange(a.fileWatcher.rootDir)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- Watcher Hard Exclusions ---
+//
+// Registering tens of thousands of directories with fsnotify can hang Watchman.Start on large
+// JavaScript or Rust repositories. Directories whose name is on this list are never watched,
+// even when the .gitignore and custom ignore toggles are off.
+
+// defaultWatcherExcludeDirs are the directory names the watcher skips by default
+var defaultWatcherExcludeDirs = []string{"node_modules", ".venv", "target", "build"}
+
+// watcherExcludeSet builds a lookup set from the configured directory names
+func watcherExcludeSet(names []string) map[string]bool {
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		name = strings.Trim(strings.TrimSpace(name), `/\`)
+		if name != "" {
+			set[name] = true
+		}
+	}
+	return set
+}
+
+// pathInExcludedDir reports whether relPath lies inside a hard-excluded directory
+// The path itself is not checked, so creating such a directory is still reported once.
+func pathInExcludedDir(exclude map[string]bool, relPath string) bool {
+	if len(exclude) == 0 {
+		return false
+	}
+	parts := strings.Split(filepath.ToSlash(filepath.Clean(relPath)), "/")
+	for _, part := range parts[:len(parts)-1] {
+		if exclude[part] {
+			return true
+		}
+	}
+	return false
+}
+
+// ============================================================================
+// Watcher Exclusion Methods (Wails-bound)
+// ============================================================================
+
+// GetWatcherExcludeDirs returns the directory names the file watcher never descends into
+func (a *App) GetWatcherExcludeDirs() []string {
+	return append([]string{}, a.settings.WatcherExcludeDirs...)
+}
+
+// SetWatcherExcludeDirs replaces the watcher's always-skip list, saves it and restarts the
+// watcher so the new list applies
+//
+// Parameters:
+//   - names: Directory names (not paths), e.g. "node_modules"
+//
+// Returns:
+//   - error: Error if a name contains a path separator or the setting cannot be saved
+func (a *App) SetWatcherExcludeDirs(names []string) error {
+	cleaned := make([]string, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		name = strings.Trim(strings.TrimSpace(name), `/\`)
+		if name == "" || seen[name] {
+			continue
+		}
+		if strings.ContainsAny(name, `/\`) {
+			return fmt.Errorf("watcher exclusions must be directory names, not paths: %s", name)
+		}
+		seen[name] = true
+		cleaned = append(cleaned, name)
+	}
+
+	a.settings.WatcherExcludeDirs = cleaned
+	if err := a.saveSettings(); err != nil {
+		return fmt.Errorf("failed to save watcher exclusions: %w", err)
+	}
+	runtime.LogInfof(a.ctx, "Watcher exclusions set to: %v", cleaned)
+
+	if a.fileWatcher != nil {
+		return a.fileWatcher.RefreshIgnoresAndRescan()
+	}
+	return nil
+}
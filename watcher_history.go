@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// --- File Change History ---
+//
+// The watcher records the relevant events it sees (those not ignored and not chmod-only) in a
+// bounded history, oldest first. After returning to the app, users can list what changed in
+// the project since they last generated context. The history is kept in memory only and
+// covers the time the watcher was running.
+
+const maxFileChangeEvents = 1000 // Events kept in the history
+
+// FileChangeEvent is a file change seen by the watcher
+type FileChangeEvent struct {
+	RootDir string    `json:"rootDir"` // Project root directory
+	Path    string    `json:"path"`    // Path relative to the root (slash-separated)
+	Op      string    `json:"op"`      // create, write, remove or rename
+	Time    time.Time `json:"time"`    // When the event was seen
+}
+
+// fileChangeOp names the operation of an fsnotify event
+func fileChangeOp(op fsnotify.Op) string {
+	switch {
+	case op&fsnotify.Create != 0:
+		return "create"
+	case op&fsnotify.Remove != 0:
+		return "remove"
+	case op&fsnotify.Rename != 0:
+		return "rename"
+	default:
+		return "write"
+	}
+}
+
+// recordFileChange adds a watcher event to the change history
+func (a *App) recordFileChange(rootDir, relPath string, op fsnotify.Op) {
+	a.changeHistoryMu.Lock()
+	defer a.changeHistoryMu.Unlock()
+	a.changeHistory = append(a.changeHistory, FileChangeEvent{
+		RootDir: rootDir,
+		Path:    filepath.ToSlash(relPath),
+		Op:      fileChangeOp(op),
+		Time:    time.Now(),
+	})
+	if len(a.changeHistory) > maxFileChangeEvents {
+		a.changeHistory = append([]FileChangeEvent(nil), a.changeHistory[len(a.changeHistory)-maxFileChangeEvents:]...)
+	}
+}
+
+// fileChangesSince returns the recorded events of rootDir after since, oldest first
+func (a *App) fileChangesSince(rootDir string, since time.Time) []FileChangeEvent {
+	a.changeHistoryMu.Lock()
+	defer a.changeHistoryMu.Unlock()
+	events := []FileChangeEvent{}
+	for _, event := range a.changeHistory {
+		if event.RootDir == rootDir && event.Time.After(since) {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// ============================================================================
+// File Change History Methods (Wails-bound)
+// ============================================================================
+
+// GetRecentChanges returns the file changes the watcher saw in the watched project
+//
+// Parameters:
+//   - since: RFC 3339 time; empty for the changes since the project's last context generation
+//     (all recorded changes if it has not been generated yet)
+//
+// Returns:
+//   - []FileChangeEvent: Changes after since, oldest first
+//   - error: Error if since cannot be parsed
+func (a *App) GetRecentChanges(since string) ([]FileChangeEvent, error) {
+	rootDir := ""
+	if a.fileWatcher != nil {
+		a.fileWatcher.mu.Lock()
+		rootDir = a.fileWatcher.rootDir
+		a.fileWatcher.mu.Unlock()
+	}
+	if rootDir == "" {
+		return []FileChangeEvent{}, nil
+	}
+
+	var from time.Time
+	if strings.TrimSpace(since) != "" {
+		t, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time %q: %w", since, err)
+		}
+		from = t
+	} else if last := a.latestGeneration(); last != nil && last.rootDir == rootDir {
+		from = last.generatedAt
+	}
+	return a.fileChangesSince(rootDir, from), nil
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- Whole-File Response Format ---
+//
+// Some models are unreliable at producing diffs that apply cleanly. With the whole_file
+// response format, prompts ask for complete updated files between strict delimiters instead:
+//
+//	=== FILE: path/to/file.go ===
+//	...complete new content...
+//	=== END FILE ===
+//	=== DELETE FILE: path/to/old.go ===
+//
+// ApplyFiles parses such a response and writes the files as an undoable operation (see
+// operations.go).
+
+const (
+	responseFormatDiff      = "diff"       // Unified diffs, applied with git apply (default)
+	responseFormatWholeFile = "whole_file" // Complete files between === FILE === delimiters
+)
+
+var (
+	wholeFileStartRegex  = regexp.MustCompile(`^=== FILE: (.+?) ===\s*$`)
+	wholeFileDeleteRegex = regexp.MustCompile(`^=== DELETE FILE: (.+?) ===\s*$`)
+	wholeFileEndRegex    = regexp.MustCompile(`^=== END FILE ===\s*$`)
+)
+
+// wholeFileInstructions tells the model how to format whole-file responses
+const wholeFileInstructions = `Return every changed file in full, using exactly this format and nothing else for file content:
+=== FILE: relative/path/to/file ===
+<complete new content of the file>
+=== END FILE ===
+To delete a file, write one line: === DELETE FILE: relative/path/to/file ===
+Do not use diffs, do not abbreviate unchanged parts, and do not wrap file content in code fences.`
+
+// FileReplacement is one file of a whole-file response
+type FileReplacement struct {
+	Path    string `json:"path"`    // Path relative to the project root
+	Content string `json:"content"` // Complete new content (empty for deletions)
+	Delete  bool   `json:"delete"`  // True if the file is deleted
+}
+
+// ApplyFilesResult is the result of ApplyFiles
+type ApplyFilesResult struct {
+	OperationID string   `json:"operationId"` // Operation that can be undone (see Undo)
+	Written     []string `json:"written"`     // Existing files overwritten
+	Created     []string `json:"created"`     // New files
+	Deleted     []string `json:"deleted"`     // Files deleted
+}
+
+// parseFileReplacements extracts the files of a whole-file response
+// Text outside the delimiters is ignored. A code fence directly inside a file block is removed,
+// since models add one despite the instructions. A block without END FILE runs to the end of
+// the response (a truncated response).
+func parseFileReplacements(content string) []FileReplacement {
+	files := []FileReplacement{}
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		if m := wholeFileDeleteRegex.FindStringSubmatch(lines[i]); m != nil {
+			files = append(files, FileReplacement{Path: strings.TrimSpace(m[1]), Delete: true})
+			continue
+		}
+		m := wholeFileStartRegex.FindStringSubmatch(lines[i])
+		if m == nil {
+			continue
+		}
+		start := i + 1
+		end := start
+		for end < len(lines) && !wholeFileEndRegex.MatchString(lines[end]) {
+			end++
+		}
+		body := lines[start:end]
+		if len(body) >= 2 && strings.HasPrefix(strings.TrimSpace(body[0]), "```") && strings.TrimSpace(body[len(body)-1]) == "```" {
+			body = body[1 : len(body)-1]
+		}
+		text := strings.Join(body, "\n")
+		if len(body) > 0 {
+			text += "\n"
+		}
+		files = append(files, FileReplacement{Path: strings.TrimSpace(m[1]), Content: text})
+		i = end
+	}
+	return files
+}
+
+// responseFormat returns the response format requested in prompts
+func (a *App) responseFormat() string {
+	if a.settings.ResponseFormat == responseFormatWholeFile {
+		return responseFormatWholeFile
+	}
+	return responseFormatDiff
+}
+
+// ============================================================================
+// Whole-File Response Methods (Wails-bound)
+// ============================================================================
+
+// ParseFileReplacements returns the files of a whole-file response without applying them
+//
+// Parameters:
+//   - content: LLM response in the whole-file format
+//
+// Returns:
+//   - []FileReplacement: Files to write or delete, in response order
+func (a *App) ParseFileReplacements(content string) []FileReplacement {
+	return parseFileReplacements(a.postProcess(content))
+}
+
+// ApplyFiles writes the files of a whole-file response to a project as an undoable operation
+// If any write fails, the files are rolled back.
+//
+// Parameters:
+//   - rootDir: Project root
+//   - content: LLM response in the whole-file format
+//
+// Returns:
+//   - ApplyFilesResult: Operation ID and the files written, created and deleted
+//   - error: Error if the response has no files, a path is invalid or a write fails
+func (a *App) ApplyFiles(rootDir, content string) (ApplyFilesResult, error) {
+	if err := a.validateContentRoot(rootDir); err != nil {
+		return ApplyFilesResult{}, err
+	}
+	if !a.projectFS.OnDisk(rootDir) {
+		return ApplyFilesResult{}, mountedPathError(rootDir)
+	}
+	files := parseFileReplacements(a.postProcess(content))
+	if len(files) == 0 {
+		return ApplyFilesResult{}, fmt.Errorf("no files found in response")
+	}
+	paths := make([]string, len(files))
+	for i, file := range files {
+		if _, err := resolveProjectPath(rootDir, file.Path); err != nil {
+			return ApplyFilesResult{}, err
+		}
+		paths[i] = file.Path
+	}
+
+	result := ApplyFilesResult{Written: []string{}, Created: []string{}, Deleted: []string{}}
+	op, err := a.runOperation(rootDir, operationApplyFiles, "Apply files", paths, func() error {
+		for _, file := range files {
+			absPath, _ := resolveProjectPath(rootDir, file.Path)
+			_, statErr := os.Stat(absPath)
+			if file.Delete {
+				if err := os.Remove(absPath); err == nil {
+					result.Deleted = append(result.Deleted, file.Path)
+				} else if !os.IsNotExist(err) {
+					return fmt.Errorf("failed to delete %s: %w", file.Path, err)
+				}
+				continue
+			}
+			if err := writeProjectFile(absPath, []byte(file.Content)); err != nil {
+				return fmt.Errorf("failed to write %s: %w", file.Path, err)
+			}
+			if statErr == nil {
+				result.Written = append(result.Written, file.Path)
+			} else {
+				result.Created = append(result.Created, file.Path)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return ApplyFilesResult{}, err
+	}
+	result.OperationID = op.ID
+
+	runtime.LogInfof(a.ctx, "Applied files to %s: %d written, %d created, %d deleted (operation %s)",
+		rootDir, len(result.Written), len(result.Created), len(result.Deleted), op.ID)
+	a.notify(notifyPatchApplied, "Files applied", projectLabel(rootDir))
+	return result, nil
+}
+
+// GetResponseFormat returns the response format requested in prompts (diff or whole_file)
+func (a *App) GetResponseFormat() string {
+	return a.responseFormat()
+}
+
+// SetResponseFormat sets the response format requested in prompts and saves the setting
+//
+// Parameters:
+//   - format: diff (unified diffs) or whole_file (complete files between delimiters)
+//
+// Returns:
+//   - error: Error if the format is unknown or the setting cannot be saved
+func (a *App) SetResponseFormat(format string) error {
+	if format != responseFormatDiff && format != responseFormatWholeFile {
+		return fmt.Errorf("unknown response format: %s", format)
+	}
+	a.settings.ResponseFormat = format
+	if err := a.saveSettings(); err != nil {
+		return fmt.Errorf("failed to save response format setting: %w", err)
+	}
+	runtime.LogInfof(a.ctx, "Response format: %s", format)
+	return nil
+}
@@ -0,0 +1,402 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- Workflow Records ---
+//
+// Every llm_call job is recorded as a workflow linking the steps of one change: the task and
+// prompt that were sent, the response, the changes extracted from it (a unified diff or
+// whole-file blocks), and the result of applying them. Records are keyed by the job ID of the
+// call and stored one per file under <config dir>/workflows, so a workflow left behind (the
+// app closed while waiting, or changes never applied) can be picked up later:
+//   - pending: the call has not returned (interrupted once its job is gone; ResumeWorkflow
+//     re-sends it)
+//   - responded: the response contains no changes
+//   - changes_ready: changes were extracted and not applied yet (ApplyWorkflow applies them)
+//   - applied: the changes were applied as an undoable operation
+//   - failed: the call failed (ResumeWorkflow re-sends it)
+//   - resumed: the call was re-sent as another workflow
+// Continuations update the workflow of the call they continue. The API key is never stored.
+
+const maxWorkflows = 200 // Oldest records are dropped beyond this
+
+const (
+	workflowPending      = "pending"
+	workflowInterrupted  = "interrupted"
+	workflowResponded    = "responded"
+	workflowChangesReady = "changes_ready"
+	workflowApplied      = "applied"
+	workflowFailed       = "failed"
+	workflowResumed      = "resumed"
+)
+
+// WorkflowRecord links an LLM call to the changes it produced and their application
+type WorkflowRecord struct {
+	ID            string       `json:"id"`                      // Job ID of the LLM call
+	RootDir       string       `json:"rootDir,omitempty"`       // Project the prompt is about
+	Task          string       `json:"task,omitempty"`          // Task the prompt was built for
+	Mode          string       `json:"mode,omitempty"`          // Prompt mode of the request
+	Status        string       `json:"status"`                  // pending, interrupted, responded, changes_ready, applied, failed or resumed
+	Request       LLMRequest   `json:"request"`                 // Request without API key
+	Response      *LLMResponse `json:"response,omitempty"`      // Response, once received
+	Changes       string       `json:"changes,omitempty"`       // Changes extracted from the response (post-processed)
+	ChangesFormat string       `json:"changesFormat,omitempty"` // Format of the changes: diff or whole_file
+	OperationID   string       `json:"operationId,omitempty"`   // Operation that applied the changes (see Undo)
+	Error         string       `json:"error,omitempty"`         // Why the call or the last apply failed
+	ResumedAs     string       `json:"resumedAs,omitempty"`     // Workflow that re-sent this call
+	CreatedAt     time.Time    `json:"createdAt"`               // When the call started
+	UpdatedAt     time.Time    `json:"updatedAt"`               // When the workflow last changed
+}
+
+// workflowsDir returns the directory holding the workflow records
+func (a *App) workflowsDir() string {
+	return filepath.Join(filepath.Dir(a.configPath), "workflows")
+}
+
+// workflowPath returns the path of a workflow record
+func (a *App) workflowPath(id string) (string, error) {
+	if id == "" || id != filepath.Base(id) || strings.HasPrefix(id, ".") {
+		return "", fmt.Errorf("invalid workflow ID: %q", id)
+	}
+	return filepath.Join(a.workflowsDir(), id+".json"), nil
+}
+
+// loadWorkflowLocked reads a workflow record; the caller holds workflowMu
+func (a *App) loadWorkflowLocked(id string) (*WorkflowRecord, error) {
+	path, err := a.workflowPath(id)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("workflow not found: %s", id)
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read workflow %s: %w", id, err)
+	}
+	var w WorkflowRecord
+	if err := json.Unmarshal(data, &w); err != nil {
+		return nil, fmt.Errorf("failed to decode workflow %s: %w", id, err)
+	}
+	return &w, nil
+}
+
+// saveWorkflowLocked writes a workflow record; the caller holds workflowMu
+func (a *App) saveWorkflowLocked(w *WorkflowRecord) error {
+	path, err := a.workflowPath(w.ID)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(a.workflowsDir(), 0755); err != nil {
+		return fmt.Errorf("failed to create workflows directory: %w", err)
+	}
+	data, err := json.MarshalIndent(w, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode workflow: %w", err)
+	}
+	if err := os.WriteFile(path+".tmp", data, 0644); err != nil {
+		return fmt.Errorf("failed to write workflow: %w", err)
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// updateWorkflow applies a change to a stored workflow and emits it as "workflowUpdated"
+// Calls without a workflow (other job types, or no config directory) are ignored.
+func (a *App) updateWorkflow(id string, update func(w *WorkflowRecord)) {
+	if a.configPath == "" {
+		return
+	}
+	a.workflowMu.Lock()
+	w, err := a.loadWorkflowLocked(id)
+	if err == nil {
+		update(w)
+		w.UpdatedAt = time.Now()
+		err = a.saveWorkflowLocked(w)
+	}
+	a.workflowMu.Unlock()
+	if err != nil {
+		return
+	}
+	runtime.EventsEmit(a.ctx, "workflowUpdated", workflowSummary(*w))
+}
+
+// startWorkflow records an LLM call that is about to be sent; the API key is stripped
+func (a *App) startWorkflow(jobID string, req LLMRequest) {
+	if a.configPath == "" {
+		return
+	}
+	req.APIKey = ""
+	now := time.Now()
+	w := &WorkflowRecord{
+		ID:        jobID,
+		RootDir:   req.RootDir,
+		Task:      req.Task,
+		Mode:      req.Mode,
+		Status:    workflowPending,
+		Request:   req,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	a.workflowMu.Lock()
+	err := a.saveWorkflowLocked(w)
+	if err == nil {
+		a.pruneWorkflowsLocked()
+	}
+	a.workflowMu.Unlock()
+	if err != nil {
+		runtime.LogWarningf(a.ctx, "Failed to record workflow %s: %v", jobID, err)
+		return
+	}
+	runtime.EventsEmit(a.ctx, "workflowUpdated", workflowSummary(*w))
+}
+
+// recordWorkflowResponse links a response, and the changes extracted from it, to its workflow
+func (a *App) recordWorkflowResponse(jobID string, resp *LLMResponse) {
+	processed := a.postProcess(resp.Content)
+	format := ""
+	switch {
+	case diffStartRegex.MatchString(processed):
+		format = responseFormatDiff
+	case len(parseFileReplacements(processed)) > 0:
+		format = responseFormatWholeFile
+	}
+	a.updateWorkflow(jobID, func(w *WorkflowRecord) {
+		w.Response = resp
+		w.Error = ""
+		w.Status, w.Changes, w.ChangesFormat = workflowResponded, "", format
+		if format != "" {
+			w.Status, w.Changes = workflowChangesReady, processed
+		}
+	})
+}
+
+// failWorkflow records that the call of a workflow failed
+func (a *App) failWorkflow(jobID string, err error) {
+	a.updateWorkflow(jobID, func(w *WorkflowRecord) {
+		if w.Response == nil {
+			w.Status = workflowFailed
+		}
+		w.Error = err.Error()
+	})
+}
+
+// pruneWorkflowsLocked drops the oldest records beyond maxWorkflows; the caller holds workflowMu
+func (a *App) pruneWorkflowsLocked() {
+	entries, err := os.ReadDir(a.workflowsDir())
+	if err != nil || len(entries) <= maxWorkflows {
+		return
+	}
+	type record struct {
+		name    string
+		modTime time.Time
+	}
+	var records []record
+	for _, entry := range entries {
+		if info, err := entry.Info(); err == nil && strings.HasSuffix(entry.Name(), ".json") {
+			records = append(records, record{entry.Name(), info.ModTime()})
+		}
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].modTime.Before(records[j].modTime) })
+	for i := 0; i < len(records)-maxWorkflows; i++ {
+		os.Remove(filepath.Join(a.workflowsDir(), records[i].name))
+	}
+}
+
+// withLiveStatus marks a pending workflow whose job is no longer running as interrupted
+func (a *App) withLiveStatus(w *WorkflowRecord) *WorkflowRecord {
+	if w.Status != workflowPending {
+		return w
+	}
+	if a.jobQueue != nil {
+		if job, ok := a.jobQueue.getJob(w.ID); ok && (job.Status == "queued" || job.Status == "running") {
+			return w
+		}
+	}
+	w.Status = workflowInterrupted
+	return w
+}
+
+// workflowSummary drops the prompt and response text of a workflow, for lists and events
+func workflowSummary(w WorkflowRecord) WorkflowRecord {
+	w.Request.Prompt = ""
+	if w.Response != nil {
+		resp := *w.Response
+		resp.Content = ""
+		w.Response = &resp
+	}
+	w.Changes = ""
+	return w
+}
+
+// ============================================================================
+// Workflow Methods (Wails-bound)
+// ============================================================================
+
+// GetWorkflow returns the workflow of an LLM call: its task, prompt, response, extracted
+// changes and apply result
+//
+// Parameters:
+//   - jobID: Job ID of the LLM call
+//
+// Returns:
+//   - *WorkflowRecord: The workflow
+//   - error: Error if no workflow exists for the job
+func (a *App) GetWorkflow(jobID string) (*WorkflowRecord, error) {
+	if a.configPath == "" {
+		return nil, fmt.Errorf("config directory not available")
+	}
+	a.workflowMu.Lock()
+	w, err := a.loadWorkflowLocked(jobID)
+	a.workflowMu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	return a.withLiveStatus(w), nil
+}
+
+// ListWorkflows returns the workflows of a project, newest first
+// The prompt, response and changes text is left out; GetWorkflow returns it.
+//
+// Parameters:
+//   - rootDir: Project root (empty for all projects)
+//
+// Returns:
+//   - []WorkflowRecord: Recorded workflows
+func (a *App) ListWorkflows(rootDir string) []WorkflowRecord {
+	workflows := []WorkflowRecord{}
+	if a.configPath == "" {
+		return workflows
+	}
+	a.workflowMu.Lock()
+	entries, _ := os.ReadDir(a.workflowsDir())
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok {
+			continue
+		}
+		w, err := a.loadWorkflowLocked(id)
+		if err != nil || (rootDir != "" && w.RootDir != rootDir) {
+			continue
+		}
+		workflows = append(workflows, workflowSummary(*a.withLiveStatus(w)))
+	}
+	a.workflowMu.Unlock()
+	sort.Slice(workflows, func(i, j int) bool { return workflows[i].CreatedAt.After(workflows[j].CreatedAt) })
+	return workflows
+}
+
+// ApplyWorkflow applies the changes extracted from the response of a workflow as an undoable
+// operation, and records the result in the workflow
+//
+// Parameters:
+//   - jobID: Job ID of the LLM call
+//   - rootDir: Project root (empty for the workflow's project)
+//
+// Returns:
+//   - *WorkflowRecord: The updated workflow
+//   - error: Error if the workflow has no changes or applying them fails
+func (a *App) ApplyWorkflow(jobID, rootDir string) (*WorkflowRecord, error) {
+	w, err := a.GetWorkflow(jobID)
+	if err != nil {
+		return nil, err
+	}
+	if w.Changes == "" {
+		return nil, fmt.Errorf("workflow %s has no changes to apply", jobID)
+	}
+	if rootDir == "" {
+		rootDir = w.RootDir
+	}
+	if rootDir == "" {
+		return nil, fmt.Errorf("workflow %s has no project; rootDir is required", jobID)
+	}
+
+	var operationID string
+	if w.ChangesFormat == responseFormatWholeFile {
+		var result ApplyFilesResult
+		if result, err = a.ApplyFiles(rootDir, w.Changes); err == nil {
+			operationID = result.OperationID
+		}
+	} else {
+		var op Operation
+		if op, err = a.ApplyPatch(rootDir, w.Changes); err == nil {
+			operationID = op.ID
+		}
+	}
+	if err != nil {
+		a.updateWorkflow(jobID, func(w *WorkflowRecord) { w.Error = err.Error() })
+		return nil, err
+	}
+
+	a.MarkDiffApplied(jobID)
+	a.updateWorkflow(jobID, func(w *WorkflowRecord) {
+		w.RootDir = rootDir
+		w.Status = workflowApplied
+		w.OperationID = operationID
+		w.Error = ""
+	})
+	runtime.LogInfof(a.ctx, "Applied workflow %s to %s (operation %s)", jobID, rootDir, operationID)
+	return a.GetWorkflow(jobID)
+}
+
+// ResumeWorkflow re-sends the call of a workflow that failed or was interrupted
+// The new call gets a workflow of its own; this one is marked as resumed and links to it.
+// Workflows with changes are resumed with ApplyWorkflow instead.
+//
+// Parameters:
+//   - jobID: Job ID of the LLM call
+//   - apiKey: API key for the provider (never persisted)
+//
+// Returns:
+//   - string: Job ID of the new call
+//   - error: Error if the workflow is still running or already has a response
+func (a *App) ResumeWorkflow(jobID, apiKey string) (string, error) {
+	w, err := a.GetWorkflow(jobID)
+	if err != nil {
+		return "", err
+	}
+	if w.Status != workflowFailed && w.Status != workflowInterrupted {
+		return "", fmt.Errorf("workflow %s cannot be resumed (status %s)", jobID, w.Status)
+	}
+	req := w.Request
+	req.APIKey = apiKey
+	newJobID, err := a.CallLLMAPIWithRequest(req)
+	if err != nil {
+		return "", err
+	}
+	a.updateWorkflow(jobID, func(w *WorkflowRecord) {
+		w.Status = workflowResumed
+		w.ResumedAs = newJobID
+	})
+	return newJobID, nil
+}
+
+// DeleteWorkflow discards a workflow record and dismisses its unapplied diff
+//
+// Parameters:
+//   - jobID: Job ID of the LLM call
+//
+// Returns:
+//   - error: Error if the record cannot be removed
+func (a *App) DeleteWorkflow(jobID string) error {
+	path, err := a.workflowPath(jobID)
+	if err != nil {
+		return err
+	}
+	a.workflowMu.Lock()
+	defer a.workflowMu.Unlock()
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete workflow %s: %w", jobID, err)
+	}
+	// Discarding the workflow dismisses its unapplied diff
+	a.MarkDiffApplied(jobID)
+	return nil
+}
@@ -0,0 +1,402 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- Monorepo Workspace Packages ---
+//
+// GetWorkspacePackages finds the packages of a monorepo from its workspace configuration:
+//   - npm and yarn workspaces (package.json "workspaces")
+//   - pnpm workspaces (pnpm-workspace.yaml)
+//   - Go workspaces (go.work "use" directives)
+//   - Cargo workspaces (Cargo.toml [workspace] members)
+//   - Nx projects (project.json files) and Turborepo (turbo.json, on top of the npm workspaces)
+//
+// Each package lists the other packages of the workspace it depends on, from its manifest.
+// ScopeToPackage turns a package, optionally with its internal dependencies, into a selection:
+// the package directories plus the workspace configuration files at the root. Glob patterns
+// in workspace configs are matched one directory level per segment; "**" counts as one level.
+
+// WorkspacePackage is a package of a monorepo workspace
+type WorkspacePackage struct {
+	Name         string   `json:"name"`         // Package, module or crate name (the directory if unnamed)
+	Path         string   `json:"path"`         // Directory relative to the project root (forward slashes)
+	Ecosystem    string   `json:"ecosystem"`    // Node.js, Go, Rust
+	Manager      string   `json:"manager"`      // npm, yarn, pnpm, go.work, cargo or nx
+	Dependencies []string `json:"dependencies"` // Paths of the workspace packages it depends on
+}
+
+// WorkspaceInfo is the result of GetWorkspacePackages
+type WorkspaceInfo struct {
+	RootDir     string             `json:"rootDir"`     // Project root directory
+	Managers    []string           `json:"managers"`    // Workspace tools found (npm, yarn, pnpm, go.work, cargo, nx, turbo)
+	ConfigFiles []string           `json:"configFiles"` // Workspace configuration files at the root
+	Packages    []WorkspacePackage `json:"packages"`    // Packages, sorted by path
+}
+
+// WorkspaceScope is a selection scoped to workspace packages
+type WorkspaceScope struct {
+	Packages      []string `json:"packages"`      // Paths of the packages included
+	ExcludedPaths []string `json:"excludedPaths"` // The selection expressed as exclusions, sorted
+}
+
+// goWorkUseRegex extracts the directories of go.work use directives, single or in a block
+var goWorkUseRegex = regexp.MustCompile(`(?m)^\s*(?:use\s+)?(\.[^\s()]*)\s*$`)
+
+// cargoMembersRegex extracts the members array of a Cargo [workspace] section
+var cargoMembersRegex = regexp.MustCompile(`(?s)\[workspace\].*?members\s*=\s*\[(.*?)\]`)
+
+// quotedRegex extracts quoted strings
+var quotedRegex = regexp.MustCompile(`"([^"]*)"|'([^']*)'`)
+
+// goRequireRegex extracts the module paths of go.mod require directives
+var goRequireRegex = regexp.MustCompile(`(?m)^\s*(?:require\s+)?([^\s()]+)\s+v\S+`)
+
+// expandWorkspacePattern returns the directories of rootDir matching a workspace pattern
+func (a *App) expandWorkspacePattern(rootDir, pattern string) []string {
+	pattern = strings.Trim(path.Clean(strings.TrimPrefix(filepath.ToSlash(pattern), "./")), "/")
+	dirs := []string{"."}
+	for _, segment := range strings.Split(pattern, "/") {
+		if segment == "**" {
+			segment = "*"
+		}
+		var next []string
+		for _, dir := range dirs {
+			if !strings.ContainsAny(segment, "*?[") {
+				if a.projectIsDir(filepath.Join(rootDir, dir, segment)) {
+					next = append(next, path.Join(dir, segment))
+				}
+				continue
+			}
+			entries, err := a.projectFS.ReadDir(filepath.Join(rootDir, dir))
+			if err != nil {
+				continue
+			}
+			for _, entry := range entries {
+				if ok, _ := path.Match(segment, entry.Name()); ok && entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") && entry.Name() != "node_modules" {
+					next = append(next, path.Join(dir, entry.Name()))
+				}
+			}
+		}
+		dirs = next
+	}
+	return dirs
+}
+
+// expandWorkspacePatterns expands patterns, dropping the directories matched by "!" patterns
+func (a *App) expandWorkspacePatterns(rootDir string, patterns []string) []string {
+	excluded := make(map[string]bool)
+	for _, pattern := range patterns {
+		if strings.HasPrefix(pattern, "!") {
+			for _, dir := range a.expandWorkspacePattern(rootDir, pattern[1:]) {
+				excluded[dir] = true
+			}
+		}
+	}
+	var dirs []string
+	for _, pattern := range patterns {
+		if strings.HasPrefix(pattern, "!") {
+			continue
+		}
+		for _, dir := range a.expandWorkspacePattern(rootDir, pattern) {
+			if !excluded[dir] && dir != "." {
+				dirs = append(dirs, dir)
+			}
+		}
+	}
+	return dirs
+}
+
+// npmWorkspacePatterns reads the workspaces of a package.json (array or {packages: [...]})
+func npmWorkspacePatterns(data []byte) []string {
+	var manifest struct {
+		Workspaces json.RawMessage `json:"workspaces"`
+	}
+	if json.Unmarshal(data, &manifest) != nil || len(manifest.Workspaces) == 0 {
+		return nil
+	}
+	var patterns []string
+	if json.Unmarshal(manifest.Workspaces, &patterns) == nil {
+		return patterns
+	}
+	var object struct {
+		Packages []string `json:"packages"`
+	}
+	json.Unmarshal(manifest.Workspaces, &object)
+	return object.Packages
+}
+
+// pnpmWorkspacePatterns reads the packages list of a pnpm-workspace.yaml
+func pnpmWorkspacePatterns(data []byte) []string {
+	var patterns []string
+	inPackages := false
+	for _, line := range strings.Split(string(data), "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "packages:"):
+			inPackages = true
+		case inPackages && strings.HasPrefix(trimmed, "- "):
+			item := strings.TrimSpace(strings.TrimPrefix(trimmed, "- "))
+			if i := strings.Index(item, " #"); i >= 0 {
+				item = strings.TrimSpace(item[:i])
+			}
+			patterns = append(patterns, strings.Trim(item, `"'`))
+		case trimmed != "" && !strings.HasPrefix(trimmed, "#") && !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t"):
+			inPackages = false
+		}
+	}
+	return patterns
+}
+
+// quotedStrings returns the quoted strings of a TOML array body
+func quotedStrings(s string) []string {
+	var values []string
+	for _, m := range quotedRegex.FindAllStringSubmatch(s, -1) {
+		values = append(values, m[1]+m[2])
+	}
+	return values
+}
+
+// workspacePackage reads the name and dependency names of a package directory
+func (a *App) workspacePackage(rootDir, dir, ecosystem, manager string) (WorkspacePackage, []string) {
+	pkg := WorkspacePackage{Name: path.Base(dir), Path: dir, Ecosystem: ecosystem, Manager: manager, Dependencies: []string{}}
+	absDir := filepath.Join(rootDir, filepath.FromSlash(dir))
+	var deps []string
+	switch ecosystem {
+	case "Node.js":
+		var manifest struct {
+			Name                 string            `json:"name"`
+			Dependencies         map[string]string `json:"dependencies"`
+			DevDependencies      map[string]string `json:"devDependencies"`
+			PeerDependencies     map[string]string `json:"peerDependencies"`
+			ImplicitDependencies []string          `json:"implicitDependencies"`
+		}
+		data, err := a.projectFS.ReadFile(filepath.Join(absDir, "package.json"))
+		if err != nil {
+			data, err = a.projectFS.ReadFile(filepath.Join(absDir, "project.json")) // Nx project without package.json
+		}
+		if err == nil && json.Unmarshal(data, &manifest) == nil {
+			if manifest.Name != "" {
+				pkg.Name = manifest.Name
+			}
+			for _, m := range []map[string]string{manifest.Dependencies, manifest.DevDependencies, manifest.PeerDependencies} {
+				for name := range m {
+					deps = append(deps, name)
+				}
+			}
+			deps = append(deps, manifest.ImplicitDependencies...)
+		}
+	case "Go":
+		if data, err := a.projectFS.ReadFile(filepath.Join(absDir, "go.mod")); err == nil {
+			if m := goModuleRegex.FindSubmatch(data); m != nil {
+				pkg.Name = string(m[1])
+			}
+			for _, m := range goRequireRegex.FindAllSubmatch(data, -1) {
+				deps = append(deps, string(m[1]))
+			}
+		}
+	case "Rust":
+		if data, err := a.projectFS.ReadFile(filepath.Join(absDir, "Cargo.toml")); err == nil {
+			if m := tomlNameRegex.FindSubmatch(data); m != nil {
+				pkg.Name = string(m[1])
+			}
+			inDeps := false
+			for _, line := range strings.Split(string(data), "\n") {
+				line = strings.TrimSpace(line)
+				if strings.HasPrefix(line, "[") {
+					inDeps = strings.Contains(line, "dependencies")
+					continue
+				}
+				if name, _, found := strings.Cut(line, "="); found && inDeps {
+					deps = append(deps, strings.TrimSpace(name))
+				}
+			}
+		}
+	}
+	return pkg, deps
+}
+
+// detectWorkspace finds the workspace packages of a project
+func (a *App) detectWorkspace(rootDir string) WorkspaceInfo {
+	info := WorkspaceInfo{RootDir: rootDir, Managers: []string{}, ConfigFiles: []string{}, Packages: []WorkspacePackage{}}
+	type found struct {
+		ecosystem, manager string
+	}
+	dirs := make(map[string]found)
+	addDirs := func(list []string, ecosystem, manager string) {
+		for _, dir := range list {
+			if _, ok := dirs[dir]; !ok {
+				dirs[dir] = found{ecosystem, manager}
+			}
+		}
+	}
+	addManager := func(manager, configFile string) {
+		info.Managers = append(info.Managers, manager)
+		info.ConfigFiles = append(info.ConfigFiles, configFile)
+	}
+
+	if data, err := a.projectFS.ReadFile(filepath.Join(rootDir, "pnpm-workspace.yaml")); err == nil {
+		addManager("pnpm", "pnpm-workspace.yaml")
+		addDirs(a.expandWorkspacePatterns(rootDir, pnpmWorkspacePatterns(data)), "Node.js", "pnpm")
+	}
+	if data, err := a.projectFS.ReadFile(filepath.Join(rootDir, "package.json")); err == nil {
+		if patterns := npmWorkspacePatterns(data); len(patterns) > 0 {
+			manager := a.nodeRunner(rootDir)
+			if manager == "pnpm" {
+				manager = "npm" // pnpm ignores package.json workspaces
+			}
+			addManager(manager, "package.json")
+			addDirs(a.expandWorkspacePatterns(rootDir, patterns), "Node.js", manager)
+		}
+	}
+	if data, err := a.projectFS.ReadFile(filepath.Join(rootDir, "go.work")); err == nil {
+		addManager("go.work", "go.work")
+		var use []string
+		for _, m := range goWorkUseRegex.FindAllStringSubmatch(string(data), -1) {
+			use = append(use, m[1])
+		}
+		addDirs(a.expandWorkspacePatterns(rootDir, use), "Go", "go.work")
+	}
+	if data, err := a.projectFS.ReadFile(filepath.Join(rootDir, "Cargo.toml")); err == nil {
+		if m := cargoMembersRegex.FindSubmatch(data); m != nil {
+			addManager("cargo", "Cargo.toml")
+			addDirs(a.expandWorkspacePatterns(rootDir, quotedStrings(string(m[1]))), "Rust", "cargo")
+		}
+	}
+	if a.fileExists(filepath.Join(rootDir, "nx.json")) {
+		addManager("nx", "nx.json")
+		var projects []string
+		for _, pattern := range []string{"*", "*/*", "*/*/*"} {
+			for _, dir := range a.expandWorkspacePattern(rootDir, pattern) {
+				if a.fileExists(filepath.Join(rootDir, filepath.FromSlash(dir), "project.json")) {
+					projects = append(projects, dir)
+				}
+			}
+		}
+		addDirs(projects, "Node.js", "nx")
+	}
+	if a.fileExists(filepath.Join(rootDir, "turbo.json")) {
+		addManager("turbo", "turbo.json")
+	}
+
+	// Resolve dependency names to workspace packages
+	deps := make(map[string][]string)
+	byName := make(map[string]string)
+	for dir, f := range dirs {
+		pkg, names := a.workspacePackage(rootDir, dir, f.ecosystem, f.manager)
+		deps[dir] = names
+		byName[pkg.Name] = dir
+		info.Packages = append(info.Packages, pkg)
+	}
+	sort.Slice(info.Packages, func(i, j int) bool { return info.Packages[i].Path < info.Packages[j].Path })
+	for i := range info.Packages {
+		pkg := &info.Packages[i]
+		seen := make(map[string]bool)
+		for _, name := range deps[pkg.Path] {
+			if dir, ok := byName[name]; ok && dir != pkg.Path && !seen[dir] {
+				seen[dir] = true
+				pkg.Dependencies = append(pkg.Dependencies, dir)
+			}
+		}
+		sort.Strings(pkg.Dependencies)
+	}
+	return info
+}
+
+// ============================================================================
+// Workspace Package Methods (Wails-bound)
+// ============================================================================
+
+// GetWorkspacePackages detects the packages of a monorepo
+//
+// Parameters:
+//   - rootDir: Project root directory
+//
+// Returns:
+//   - WorkspaceInfo: Workspace tools and packages (no packages outside a monorepo)
+//   - error: Error if the directory does not exist or is not allowed
+func (a *App) GetWorkspacePackages(rootDir string) (WorkspaceInfo, error) {
+	if !a.projectIsDir(rootDir) {
+		return WorkspaceInfo{}, fmt.Errorf("project folder does not exist: %s", rootDir)
+	}
+	if err := a.validateContentRoot(rootDir); err != nil {
+		return WorkspaceInfo{}, err
+	}
+	info := a.detectWorkspace(rootDir)
+	runtime.LogInfof(a.ctx, "Detected %d workspace packages in %s (%v)", len(info.Packages), rootDir, info.Managers)
+	return info, nil
+}
+
+// ScopeToPackage builds a selection limited to a workspace package
+//
+// Parameters:
+//   - rootDir: Project root directory
+//   - packagePath: Package directory relative to the root (as in WorkspacePackage.Path)
+//   - withDependencies: Also include the workspace packages it depends on, transitively
+//
+// Returns:
+//   - WorkspaceScope: Packages included and the matching exclusions
+//   - error: Error if the package is not part of the workspace or the project cannot be walked
+func (a *App) ScopeToPackage(rootDir, packagePath string, withDependencies bool) (WorkspaceScope, error) {
+	info, err := a.GetWorkspacePackages(rootDir)
+	if err != nil {
+		return WorkspaceScope{}, err
+	}
+	packages := make(map[string]WorkspacePackage, len(info.Packages))
+	for _, pkg := range info.Packages {
+		packages[pkg.Path] = pkg
+	}
+	packagePath = strings.Trim(filepath.ToSlash(packagePath), "/")
+	if _, ok := packages[packagePath]; !ok {
+		return WorkspaceScope{}, fmt.Errorf("%s is not a workspace package", packagePath)
+	}
+
+	included := map[string]bool{packagePath: true}
+	queue := []string{packagePath}
+	for withDependencies && len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, dep := range packages[current].Dependencies {
+			if !included[dep] {
+				included[dep] = true
+				queue = append(queue, dep)
+			}
+		}
+	}
+
+	entries, files, err := a.walkSelectionEntries(rootDir)
+	if err != nil {
+		return WorkspaceScope{}, err
+	}
+	selected := make(map[string]bool)
+	for _, configFile := range info.ConfigFiles {
+		if _, ok := files[configFile]; ok {
+			selected[configFile] = true
+		}
+	}
+	for relPath := range files {
+		for dir := path.Dir(relPath); dir != "."; dir = path.Dir(dir) {
+			if included[dir] {
+				selected[relPath] = true
+				break
+			}
+		}
+	}
+
+	scope := WorkspaceScope{Packages: []string{}, ExcludedPaths: selectionExclusions(entries, selected)}
+	for dir := range included {
+		scope.Packages = append(scope.Packages, dir)
+	}
+	sort.Strings(scope.Packages)
+	runtime.LogInfof(a.ctx, "Scoped selection of %s to %v", rootDir, scope.Packages)
+	return scope, nil
+}
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- WSL Path Translation ---
+//
+// When the backend runs inside WSL, Windows apps cannot use its Linux paths as they are.
+// Paths translate both ways:
+//   - Windows drives are mounted under the automount root: /mnt/c/src/app <-> C:\src\app
+//     (the root is read from the [automount] section of /etc/wsl.conf, /mnt/ by default)
+//   - everything else is reached through the distro's share: /home/me <-> \\wsl$\Ubuntu\home\me
+//     (\\wsl.localhost\ paths are accepted as well)
+//
+// OpenInWindows opens a translated path in Explorer, the default Windows app, Notepad or
+// VS Code; OpenGeneratedContextInWindows writes the last generated context to a temp file and
+// opens it the same way. Translation works everywhere, opening only inside WSL.
+
+const defaultWSLAutomountRoot = "/mnt/"
+
+var (
+	windowsDrivePathRegex = regexp.MustCompile(`^([A-Za-z]):(?:[\\/](.*))?$`)
+	wslSharePathRegex     = regexp.MustCompile(`(?i)^[\\/]{2}(?:wsl\$|wsl\.localhost)[\\/]([^\\/]+)(?:[\\/](.*))?$`)
+)
+
+// windowsApps are the Windows apps OpenInWindows can use, by name
+// Each gets the Windows path of the file or folder to open as its last argument.
+var windowsApps = map[string][]string{
+	"default":  {"explorer.exe"}, // Explorer opens files in their default app
+	"explorer": {"explorer.exe"},
+	"notepad":  {"notepad.exe"},
+	"vscode":   {"cmd.exe", "/c", "code"},
+}
+
+// isWSL reports whether the backend runs inside WSL
+func isWSL() bool {
+	if os.Getenv("WSL_DISTRO_NAME") != "" {
+		return true
+	}
+	version, err := os.ReadFile("/proc/version")
+	return err == nil && strings.Contains(strings.ToLower(string(version)), "microsoft")
+}
+
+// wslDistroName returns the name of the WSL distro the backend runs in ("" if unknown)
+func wslDistroName() string {
+	return os.Getenv("WSL_DISTRO_NAME")
+}
+
+// wslAutomountRoot returns the directory Windows drives are mounted under, ending with a slash
+func wslAutomountRoot() string {
+	file, err := os.Open("/etc/wsl.conf")
+	if err != nil {
+		return defaultWSLAutomountRoot
+	}
+	defer file.Close()
+
+	section := ""
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case strings.HasPrefix(line, "["):
+			section = strings.ToLower(strings.Trim(line, "[] "))
+		case section == "automount":
+			key, value, ok := strings.Cut(line, "=")
+			if ok && strings.TrimSpace(key) == "root" {
+				if root := strings.Trim(strings.TrimSpace(value), `"`); strings.HasPrefix(root, "/") {
+					return strings.TrimSuffix(root, "/") + "/"
+				}
+			}
+		}
+	}
+	return defaultWSLAutomountRoot
+}
+
+// wslToWindowsPath translates an absolute Linux path of a WSL distro to a Windows path
+//
+// Parameters:
+//   - linuxPath: Absolute Linux path
+//   - distro: Name of the distro (needed for paths outside the automount root)
+//   - mountRoot: Directory Windows drives are mounted under, ending with a slash
+//
+// Returns:
+//   - string: Windows path (C:\... for mounted drives, \\wsl$\<distro>\... otherwise)
+//   - error: Error if the path is relative, or the distro is unknown for a distro path
+func wslToWindowsPath(linuxPath, distro, mountRoot string) (string, error) {
+	if !strings.HasPrefix(linuxPath, "/") {
+		return "", fmt.Errorf("not an absolute Linux path: %s", linuxPath)
+	}
+	cleaned := path.Clean(linuxPath)
+	if rest, ok := strings.CutPrefix(cleaned+"/", mountRoot); ok {
+		drive, inner, _ := strings.Cut(strings.TrimSuffix(rest, "/"), "/")
+		if len(drive) == 1 && (drive[0] >= 'a' && drive[0] <= 'z' || drive[0] >= 'A' && drive[0] <= 'Z') {
+			return strings.ToUpper(drive) + `:\` + strings.ReplaceAll(inner, "/", `\`), nil
+		}
+	}
+	if distro == "" {
+		return "", fmt.Errorf("WSL distro name unknown, cannot translate %s", linuxPath)
+	}
+	return `\\wsl$\` + distro + strings.ReplaceAll(strings.TrimSuffix(cleaned, "/"), "/", `\`), nil
+}
+
+// windowsToWSLPath translates a Windows path to the Linux path of a WSL distro
+//
+// Parameters:
+//   - windowsPath: Drive path (C:\... or C:/...) or distro share path (\\wsl$\... or \\wsl.localhost\...)
+//   - distro: Name of the distro (share paths of other distros are rejected; "" accepts any)
+//   - mountRoot: Directory Windows drives are mounted under, ending with a slash
+//
+// Returns:
+//   - string: Linux path
+//   - error: Error if the path is neither, or is on another distro's share
+func windowsToWSLPath(windowsPath, distro, mountRoot string) (string, error) {
+	windowsPath = strings.TrimSpace(windowsPath)
+	if m := windowsDrivePathRegex.FindStringSubmatch(windowsPath); m != nil {
+		inner := strings.Trim(strings.ReplaceAll(m[2], `\`, "/"), "/")
+		return path.Clean(mountRoot + strings.ToLower(m[1]) + "/" + inner), nil
+	}
+	if m := wslSharePathRegex.FindStringSubmatch(windowsPath); m != nil {
+		if distro != "" && !strings.EqualFold(m[1], distro) {
+			return "", fmt.Errorf("path is in WSL distro %s, not %s: %s", m[1], distro, windowsPath)
+		}
+		return path.Clean("/" + strings.ReplaceAll(m[2], `\`, "/")), nil
+	}
+	return "", fmt.Errorf("not a Windows drive or WSL share path: %s", windowsPath)
+}
+
+// openInWindows starts a Windows app on a Linux path of the distro
+func (a *App) openInWindows(linuxPath, app string) (string, error) {
+	if !isWSL() {
+		return "", fmt.Errorf("not running in WSL environment")
+	}
+	if app == "" {
+		app = "default"
+	}
+	command, ok := windowsApps[app]
+	if !ok {
+		return "", fmt.Errorf("unknown Windows app: %s (use default, explorer, notepad or vscode)", app)
+	}
+	info, err := os.Stat(linuxPath)
+	if err != nil {
+		return "", fmt.Errorf("cannot open %s: %w", linuxPath, err)
+	}
+	windowsPath, err := wslToWindowsPath(linuxPath, wslDistroName(), wslAutomountRoot())
+	if err != nil {
+		return "", err
+	}
+
+	args := append(append([]string{}, command[1:]...), windowsPath)
+	if app == "explorer" && !info.IsDir() {
+		args = []string{"/select," + windowsPath} // Show the file selected in its folder
+	}
+	cmd := exec.Command(command[0], args...)
+	// cmd.exe warns about (and falls back from) Linux working directories
+	if drive := wslAutomountRoot() + "c"; dirExists(drive) {
+		cmd.Dir = drive
+	}
+	if err := cmd.Start(); err != nil {
+		return "", fmt.Errorf("failed to start %s: %w", command[0], err)
+	}
+	go cmd.Wait() // Explorer exits with status 1 even on success; the result is not used
+	runtime.LogInfof(a.ctx, "Opened %s in Windows (%s)", windowsPath, app)
+	return windowsPath, nil
+}
+
+// dirExists reports whether a directory exists
+func dirExists(dir string) bool {
+	info, err := os.Stat(dir)
+	return err == nil && info.IsDir()
+}
+
+// ============================================================================
+// WSL Path Methods (Wails-bound)
+// ============================================================================
+
+// IsWSL reports whether the backend runs inside WSL
+func (a *App) IsWSL() bool {
+	return isWSL()
+}
+
+// WSLToWindowsPath translates a Linux path of this WSL distro to a Windows path
+//
+// Parameters:
+//   - linuxPath: Absolute Linux path (e.g. /mnt/c/src/app or /home/me/app)
+//
+// Returns:
+//   - string: Windows path (e.g. C:\src\app or \\wsl$\Ubuntu\home\me\app)
+//   - error: Error if the path is relative, or the distro is unknown for a distro path
+func (a *App) WSLToWindowsPath(linuxPath string) (string, error) {
+	return wslToWindowsPath(linuxPath, wslDistroName(), wslAutomountRoot())
+}
+
+// WindowsToWSLPath translates a Windows path to a Linux path of this WSL distro
+//
+// Parameters:
+//   - windowsPath: Drive path (e.g. C:\src\app) or share path (e.g. \\wsl$\Ubuntu\home\me\app)
+//
+// Returns:
+//   - string: Linux path (e.g. /mnt/c/src/app or /home/me/app)
+//   - error: Error if the path cannot be translated
+func (a *App) WindowsToWSLPath(windowsPath string) (string, error) {
+	return windowsToWSLPath(windowsPath, wslDistroName(), wslAutomountRoot())
+}
+
+// OpenInWindows opens a file or folder of this WSL distro in a Windows app
+//
+// Parameters:
+//   - linuxPath: Absolute Linux path of the file or folder (e.g. a project root)
+//   - app: default (the Windows default app), explorer (folders open, files are shown
+//     selected), notepad or vscode; empty for default
+//
+// Returns:
+//   - string: Windows path that was opened
+//   - error: Error if not in WSL, the path does not exist, or the app cannot be started
+func (a *App) OpenInWindows(linuxPath, app string) (string, error) {
+	if err := a.checkAllowedPath(linuxPath); err != nil {
+		return "", err
+	}
+	return a.openInWindows(linuxPath, app)
+}
+
+// OpenGeneratedContextInWindows writes the last generated context to a temp file and opens it
+// in a Windows app
+//
+// Parameters:
+//   - app: Windows app, as for OpenInWindows (empty for default)
+//
+// Returns:
+//   - string: Windows path of the temp file
+//   - error: Error if not in WSL, no context was generated, or the file cannot be written or opened
+func (a *App) OpenGeneratedContextInWindows(app string) (string, error) {
+	if !isWSL() {
+		return "", fmt.Errorf("not running in WSL environment")
+	}
+	last := a.latestGeneration()
+	if last == nil {
+		return "", fmt.Errorf("no context generated yet")
+	}
+	name := fmt.Sprintf("shotgun_context_%s.txt", time.Now().Format("20060102_150405"))
+	if a.outputFormat() == outputFormatMarkdown {
+		name = strings.TrimSuffix(name, ".txt") + ".md"
+	}
+	contextPath := filepath.Join(os.TempDir(), name)
+	if err := os.WriteFile(contextPath, []byte(last.content), 0644); err != nil {
+		return "", fmt.Errorf("failed to write context file: %w", err)
+	}
+	return a.openInWindows(contextPath, app)
+}